@@ -0,0 +1,17 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound is returned by a BlobStore when no content is stored under the key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files, such as photos, under keys chosen by the caller
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,10 @@
+package gateway
+
+import "mecanica_xpto/internal/domain/model/entities"
+
+// DocumentRenderer lays out a printable document, returning its content and content type
+type DocumentRenderer interface {
+	Render(document entities.Document) ([]byte, error)
+	ContentType() string
+	Extension() string
+}
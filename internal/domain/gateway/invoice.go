@@ -0,0 +1,23 @@
+package gateway
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/entities"
+)
+
+// InvoiceEncoder builds the XML of an invoice in the layout of its type (NFS-e or NF-e)
+type InvoiceEncoder interface {
+	Encode(invoice entities.Invoice) ([]byte, error)
+}
+
+// InvoiceSigner signs an invoice XML with the digital certificate of the issuer
+type InvoiceSigner interface {
+	Sign(xml []byte) ([]byte, error)
+}
+
+// InvoiceProvider submits invoices to the tax authority: the city hall for NFS-e and SEFAZ for NF-e.
+// A rejection is not an error: it is reported through InvoiceSubmission.Accepted.
+type InvoiceProvider interface {
+	Submit(ctx context.Context, invoice entities.Invoice, signedXML []byte) (*entities.InvoiceSubmission, error)
+	Cancel(ctx context.Context, invoice entities.Invoice, reason string) (*entities.InvoiceCancellation, error)
+}
@@ -0,0 +1,13 @@
+package gateway
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+// NotificationSender delivers messages to customers through one channel (e-mail, SMS or WhatsApp)
+type NotificationSender interface {
+	Channel() valueobject.NotificationChannel
+	Send(ctx context.Context, message entities.NotificationMessage) error
+}
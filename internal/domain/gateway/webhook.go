@@ -0,0 +1,8 @@
+package gateway
+
+import "context"
+
+// WebhookClient posts a signed webhook body to a subscriber and returns the HTTP status it answered with
+type WebhookClient interface {
+	Post(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	dto "mecanica_xpto/internal/domain/model/dto"
 	entities "mecanica_xpto/internal/domain/model/entities"
+	valueobject "mecanica_xpto/internal/domain/model/valueobject"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -101,3 +102,17 @@ func (mr *MockIPaymentRepoMockRecorder) List(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIPaymentRepo)(nil).List), ctx)
 }
+
+// UpdateMethod mocks base method.
+func (m *MockIPaymentRepo) UpdateMethod(ctx context.Context, id uint, method valueobject.PaymentMethod) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMethod", ctx, id, method)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMethod indicates an expected call of UpdateMethod.
+func (mr *MockIPaymentRepoMockRecorder) UpdateMethod(ctx, id, method any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMethod", reflect.TypeOf((*MockIPaymentRepo)(nil).UpdateMethod), ctx, id, method)
+}
@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	entities "mecanica_xpto/internal/domain/model/entities"
+	valueobject "mecanica_xpto/internal/domain/model/valueobject"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -85,3 +86,18 @@ func (mr *MockIPaymentUseCaseMockRecorder) ListPayments(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPayments", reflect.TypeOf((*MockIPaymentUseCase)(nil).ListPayments), ctx)
 }
+
+// UpdatePaymentMethod mocks base method.
+func (m *MockIPaymentUseCase) UpdatePaymentMethod(ctx context.Context, id uint, method valueobject.PaymentMethod) (*entities.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentMethod", ctx, id, method)
+	ret0, _ := ret[0].(*entities.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentMethod indicates an expected call of UpdatePaymentMethod.
+func (mr *MockIPaymentUseCaseMockRecorder) UpdatePaymentMethod(ctx, id, method any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentMethod", reflect.TypeOf((*MockIPaymentUseCase)(nil).UpdatePaymentMethod), ctx, id, method)
+}
@@ -8,14 +8,19 @@ import (
 
 // N:N relationship between PartsSupply and AdditionalRepair
 type PartsSupplyAdditionalRepairDTO struct {
-	PartsSupplyID      uint `gorm:"primaryKey"`
-	AdditionalRepairID uint `gorm:"primaryKey"`
+	PartsSupplyID      uint `gorm:"column:parts_supply_id;primaryKey"`
+	AdditionalRepairID uint `gorm:"column:additional_repair_id;primaryKey"`
+	Quantity           int  `gorm:"column:quantity;not null;default:1"`
+	// UnitPrice is the price the parts supply was added at, averaged when units are added later at
+	// another price, so removing units takes off what they added to the estimate
+	UnitPrice float64 `gorm:"column:unit_price;type:decimal(12,4);not null;default:0"`
 }
 
 // N:N relationship between Service and AdditionalRepair
 type ServiceAdditionalRepairDTO struct {
-	ServiceID          uint `gorm:"primaryKey"`
-	AdditionalRepairID uint `gorm:"primaryKey"`
+	ServiceID          uint    `gorm:"column:service_id;primaryKey"`
+	AdditionalRepairID uint    `gorm:"column:additional_repair_id;primaryKey"`
+	UnitPrice          float64 `gorm:"column:unit_price;type:decimal(10,2);not null;default:0"`
 }
 
 // 1:N relationship between AdditionalRepair and AdditionalRepairStatus
@@ -39,24 +44,104 @@ type AdditionalRepairDTO struct {
 	Estimate       float64                   `gorm:"type:decimal(10,2)"`
 	CreatedAt      time.Time                 `gorm:"autoCreateTime"`
 	UpdatedAt      time.Time                 `gorm:"autoUpdateTime"`
-	PartsSupplies  []PartsSupplyDTO          `gorm:"many2many:parts_supply_additional_repair"`
-	Services       []ServiceDTO              `gorm:"many2many:service_additional_repair"`
+	PartsSupplies  []PartsSupplyDTO          `gorm:"many2many:parts_supply_additional_repair_dtos;joinForeignKey:additional_repair_id;joinReferences:parts_supply_id"`
+	Services       []ServiceDTO              `gorm:"many2many:service_additional_repair_dtos;joinForeignKey:additional_repair_id;joinReferences:service_id"`
+	// PartsSupplyItems are the rows of the parts supplies join table, carrying the quantity of each part
+	PartsSupplyItems []PartsSupplyAdditionalRepairDTO `gorm:"foreignKey:AdditionalRepairID"`
+	// ServiceItems are the rows of the services join table, carrying the price of each service
+	ServiceItems []ServiceAdditionalRepairDTO `gorm:"foreignKey:AdditionalRepairID"`
+	History      []AdditionalRepairHistoryDTO `gorm:"foreignKey:AdditionalRepairID"`
+}
+
+// AdditionalRepairHistoryDTO is an entry of the change log of an additional repair
+type AdditionalRepairHistoryDTO struct {
+	ID                 uint      `gorm:"primaryKey"`
+	AdditionalRepairID uint      `gorm:"column:additional_repair_id;not null;index"`
+	Action             string    `gorm:"size:30;not null"`
+	Description        string    `gorm:"type:text"`
+	EstimateChange     float64   `gorm:"type:decimal(10,2);default:0"`
+	CreatedAt          time.Time `gorm:"autoCreateTime"`
+}
+
+func (h *AdditionalRepairHistoryDTO) ToDomain() entities.AdditionalRepairHistory {
+	return entities.AdditionalRepairHistory{
+		ID:             h.ID,
+		Action:         h.Action,
+		Description:    h.Description,
+		EstimateChange: h.EstimateChange,
+		CreatedAt:      h.CreatedAt,
+	}
+}
+
+// PartsSupplyQuantity returns how many units of the parts supply the additional repair uses
+func (arm *AdditionalRepairDTO) PartsSupplyQuantity(partsSupplyID uint) int {
+	for _, item := range arm.PartsSupplyItems {
+		if item.PartsSupplyID == partsSupplyID {
+			return item.Quantity
+		}
+	}
+	return 0
+}
+
+// PartsSupplyUnitPrice returns the price the parts supply was added to the additional repair at.
+// Parts added before the price was stored fall back to the price of the catalog.
+func (arm *AdditionalRepairDTO) PartsSupplyUnitPrice(partsSupplyID uint) float64 {
+	for _, item := range arm.PartsSupplyItems {
+		if item.PartsSupplyID == partsSupplyID && item.UnitPrice > 0 {
+			return item.UnitPrice
+		}
+	}
+	for _, ps := range arm.PartsSupplies {
+		if ps.ID == partsSupplyID {
+			return ps.Price
+		}
+	}
+	return 0
+}
+
+// ServiceUnitPrice returns the price the service was added to the additional repair at, falling
+// back to the price of the catalog like PartsSupplyUnitPrice
+func (arm *AdditionalRepairDTO) ServiceUnitPrice(serviceID uint) float64 {
+	for _, item := range arm.ServiceItems {
+		if item.ServiceID == serviceID && item.UnitPrice > 0 {
+			return item.UnitPrice
+		}
+	}
+	for _, s := range arm.Services {
+		if s.ID == serviceID {
+			return s.Price
+		}
+	}
+	return 0
 }
 
 func (arm *AdditionalRepairDTO) ToDomain() entities.AdditionalRepair {
 	var partsSupplies []entities.PartsSupply
 	var services []entities.Service
+	var history []entities.AdditionalRepairHistory
 
+	// the reserved quantity of each part is the quantity used by this additional repair, and the
+	// prices are the ones the items were added at
 	for _, ps := range arm.PartsSupplies {
-		partsSupplies = append(partsSupplies, ps.ToDomain())
+		partsSupply := ps.ToDomain()
+		partsSupply.QuantityReserve = arm.PartsSupplyQuantity(ps.ID)
+		partsSupply.Price = arm.PartsSupplyUnitPrice(ps.ID)
+		partsSupplies = append(partsSupplies, partsSupply)
 	}
 
 	for _, s := range arm.Services {
-		services = append(services, s.ToDomain())
+		svc := s.ToDomain()
+		svc.Price = arm.ServiceUnitPrice(s.ID)
+		services = append(services, svc)
+	}
+
+	for _, h := range arm.History {
+		history = append(history, h.ToDomain())
 	}
 
 	return entities.AdditionalRepair{
 		ID:             arm.ID,
+		Description:    arm.Description,
 		ARStatus:       arm.ARStatus.ToDomain(),
 		Estimate:       arm.Estimate,
 		CreatedAt:      arm.CreatedAt,
@@ -65,5 +150,6 @@ func (arm *AdditionalRepairDTO) ToDomain() entities.AdditionalRepair {
 		ServiceOrder:   arm.ServiceOrder.ToDomain(),
 		PartsSupplies:  partsSupplies,
 		Services:       services,
+		History:        history,
 	}
 }
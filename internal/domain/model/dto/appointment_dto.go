@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BayDTO struct {
+	ID        uint           `gorm:"primaryKey"`
+	Name      string         `gorm:"size:50;not null"`
+	Active    bool           `gorm:"not null;default:true"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (m *BayDTO) ToDomain() entities.Bay {
+	return entities.Bay{
+		ID:        m.ID,
+		Name:      m.Name,
+		Active:    m.Active,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// N:1 relationship between Appointment and Bay
+// N:N relationship between Appointment and Service, the services the customer asked for
+type AppointmentDTO struct {
+	ID             uint         `gorm:"primaryKey"`
+	CustomerID     uint         `gorm:"not null;index"`
+	VehicleID      uint         `gorm:"not null;index"`
+	BayID          uint         `gorm:"not null;index:idx_appointment_bay_period"`
+	Bay            BayDTO       `gorm:"foreignKey:BayID"`
+	Services       []ServiceDTO `gorm:"many2many:appointment_services;"`
+	StartsAt       time.Time    `gorm:"not null;index:idx_appointment_bay_period"`
+	EndsAt         time.Time    `gorm:"not null"`
+	Status         string       `gorm:"size:20;not null;index"`
+	Notes          string       `gorm:"size:500"`
+	ServiceOrderID *uint
+	CreatedBy      string    `gorm:"size:100"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (m *AppointmentDTO) ToDomain() entities.Appointment {
+	appointment := entities.Appointment{
+		ID:             m.ID,
+		CustomerID:     m.CustomerID,
+		VehicleID:      m.VehicleID,
+		BayID:          m.BayID,
+		StartsAt:       m.StartsAt,
+		EndsAt:         m.EndsAt,
+		Status:         valueobject.ParseAppointmentStatus(m.Status),
+		Notes:          m.Notes,
+		ServiceOrderID: m.ServiceOrderID,
+		CreatedBy:      m.CreatedBy,
+		CreatedAt:      m.CreatedAt,
+	}
+	if m.Bay.ID != 0 {
+		bay := m.Bay.ToDomain()
+		appointment.Bay = &bay
+	}
+	for _, s := range m.Services {
+		appointment.Services = append(appointment.Services, s.ToDomain())
+	}
+	return appointment
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// N:1 relationship between ApprovalLink and ServiceOrder
+// N:1 relationship between ApprovalLink and AdditionalRepair
+type ApprovalLinkDTO struct {
+	ID                 uint   `gorm:"primaryKey"`
+	TokenHash          string `gorm:"size:64;not null;uniqueIndex"`
+	Subject            string `gorm:"size:20;not null"`
+	ServiceOrderID     uint   `gorm:"column:service_order_id;not null;index"`
+	AdditionalRepairID *uint  `gorm:"column:additional_repair_id;index"`
+	ApproverContactID  *uint  `gorm:"column:approver_contact_id"`
+	ExpiresAt          time.Time
+	CreatedBy          string `gorm:"size:100"`
+	Decision           string `gorm:"size:10"`
+	DecidedAt          *time.Time
+	IP                 string    `gorm:"column:ip;size:45"`
+	UserAgent          string    `gorm:"size:255"`
+	CreatedAt          time.Time `gorm:"autoCreateTime"`
+}
+
+func (a *ApprovalLinkDTO) ToDomain() entities.ApprovalLink {
+	link := entities.ApprovalLink{
+		ID:                 a.ID,
+		Subject:            valueobject.ParseApprovalSubject(a.Subject),
+		ServiceOrderID:     a.ServiceOrderID,
+		AdditionalRepairID: a.AdditionalRepairID,
+		ApproverContactID:  a.ApproverContactID,
+		ExpiresAt:          a.ExpiresAt,
+		CreatedBy:          a.CreatedBy,
+		Decision:           a.Decision,
+		DecidedAt:          a.DecidedAt,
+		CreatedAt:          a.CreatedAt,
+	}
+	if a.DecidedAt != nil {
+		link.Consent = &entities.ApprovalConsent{IP: a.IP, UserAgent: a.UserAgent}
+	}
+	return link
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// AttachmentDTO keeps the metadata of an attached file; StorageKey locates it on the blob store.
+// ServiceOrderID is kept for the attachments of additional repairs too, so the evidence of an order
+// can be found in one query.
+type AttachmentDTO struct {
+	ID             uint      `gorm:"primaryKey"`
+	OwnerType      string    `gorm:"size:20;not null;index:idx_attachment_owner"`
+	OwnerID        uint      `gorm:"not null;index:idx_attachment_owner"`
+	ServiceOrderID uint      `gorm:"not null;index"`
+	StorageKey     string    `gorm:"size:255;not null;uniqueIndex"`
+	FileName       string    `gorm:"size:255;not null"`
+	ContentType    string    `gorm:"size:50;not null"`
+	Size           int64     `gorm:"not null"`
+	Checksum       string    `gorm:"size:64;not null"`
+	UploadedBy     string    `gorm:"size:100"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (m *AttachmentDTO) ToDomain() entities.Attachment {
+	return entities.Attachment{
+		ID:             m.ID,
+		OwnerType:      valueobject.ParseAttachmentOwner(m.OwnerType),
+		OwnerID:        m.OwnerID,
+		ServiceOrderID: m.ServiceOrderID,
+		FileName:       m.FileName,
+		ContentType:    m.ContentType,
+		Size:           m.Size,
+		Checksum:       m.Checksum,
+		UploadedBy:     m.UploadedBy,
+		CreatedAt:      m.CreatedAt,
+	}
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+type CashClosingDTO struct {
+	ID           uint                 `gorm:"primaryKey"`
+	Date         time.Time            `gorm:"type:date;uniqueIndex;not null"`
+	ClosedBy     string               `gorm:"size:100;not null"`
+	Notes        string               `gorm:"type:text"`
+	PaymentCount int64                `gorm:"not null"`
+	Total        float64              `gorm:"type:decimal(10,2);not null"`
+	Lines        []CashClosingLineDTO `gorm:"foreignKey:CashClosingID"`
+	CreatedAt    time.Time            `gorm:"autoCreateTime"`
+}
+
+type CashClosingLineDTO struct {
+	ID            uint    `gorm:"primaryKey"`
+	CashClosingID uint    `gorm:"column:cash_closing_id;not null;index"`
+	Method        string  `gorm:"size:20;not null"`
+	Operator      string  `gorm:"size:100"`
+	Count         int64   `gorm:"not null"`
+	Amount        float64 `gorm:"type:decimal(10,2);not null"`
+}
+
+func (c *CashClosingDTO) ToDomain() entities.CashClosing {
+	closing := entities.CashClosing{
+		ID:           c.ID,
+		Date:         c.Date,
+		ClosedBy:     c.ClosedBy,
+		Notes:        c.Notes,
+		PaymentCount: c.PaymentCount,
+		Total:        c.Total,
+		ByMethod:     make(map[valueobject.PaymentMethod]float64),
+		Lines:        make([]entities.PaymentReportLine, 0, len(c.Lines)),
+		CreatedAt:    c.CreatedAt,
+	}
+	for _, l := range c.Lines {
+		line := l.ToDomain()
+		line.Date = c.Date.Format("2006-01-02")
+		closing.Lines = append(closing.Lines, line)
+		closing.ByMethod[line.Method] += line.Amount
+	}
+	return closing
+}
+
+func (l *CashClosingLineDTO) ToDomain() entities.PaymentReportLine {
+	return entities.PaymentReportLine{
+		Method:   valueobject.ParsePaymentMethod(l.Method),
+		Operator: l.Operator,
+		Count:    l.Count,
+		Amount:   l.Amount,
+	}
+}
@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// 1:1 relationship between CheckIn and ServiceOrder
+// 1:N relationship between CheckIn and its checklist items and photos
+type CheckInDTO struct {
+	ID             uint              `gorm:"primaryKey"`
+	ServiceOrderID uint              `gorm:"not null;uniqueIndex"`
+	Odometer       int               `gorm:"not null"`
+	FuelLevel      string            `gorm:"size:10;not null"`
+	Notes          string            `gorm:"size:1000"`
+	InspectedBy    string            `gorm:"size:100"`
+	Items          []CheckInItemDTO  `gorm:"foreignKey:CheckInID;constraint:OnDelete:CASCADE"`
+	Photos         []CheckInPhotoDTO `gorm:"foreignKey:CheckInID"`
+	CreatedAt      time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time         `gorm:"autoUpdateTime"`
+}
+
+func (m *CheckInDTO) ToDomain() entities.CheckIn {
+	checkIn := entities.CheckIn{
+		ID:             m.ID,
+		ServiceOrderID: m.ServiceOrderID,
+		Odometer:       m.Odometer,
+		FuelLevel:      valueobject.ParseFuelLevel(m.FuelLevel),
+		Checklist:      []entities.CheckInItem{},
+		Notes:          m.Notes,
+		InspectedBy:    m.InspectedBy,
+		Photos:         []entities.CheckInPhoto{},
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+	for _, item := range m.Items {
+		checkIn.Checklist = append(checkIn.Checklist, item.ToDomain())
+	}
+	for _, photo := range m.Photos {
+		checkIn.Photos = append(checkIn.Photos, photo.ToDomain())
+	}
+	return checkIn
+}
+
+type CheckInItemDTO struct {
+	ID        uint   `gorm:"primaryKey"`
+	CheckInID uint   `gorm:"not null;index"`
+	Name      string `gorm:"size:100;not null"`
+	Condition string `gorm:"size:10;not null"`
+	Notes     string `gorm:"size:500"`
+}
+
+func (m *CheckInItemDTO) ToDomain() entities.CheckInItem {
+	return entities.CheckInItem{
+		Name:      m.Name,
+		Condition: valueobject.ParseChecklistCondition(m.Condition),
+		Notes:     m.Notes,
+	}
+}
+
+// CheckInPhotoDTO keeps the metadata of a photo; StorageKey locates the file on the blob store
+type CheckInPhotoDTO struct {
+	ID          uint      `gorm:"primaryKey"`
+	CheckInID   uint      `gorm:"not null;index"`
+	StorageKey  string    `gorm:"size:255;not null;uniqueIndex"`
+	FileName    string    `gorm:"size:255;not null"`
+	ContentType string    `gorm:"size:50;not null"`
+	Size        int64     `gorm:"not null"`
+	Caption     string    `gorm:"size:255"`
+	UploadedBy  string    `gorm:"size:100"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+func (m *CheckInPhotoDTO) ToDomain() entities.CheckInPhoto {
+	return entities.CheckInPhoto{
+		ID:          m.ID,
+		FileName:    m.FileName,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		Caption:     m.Caption,
+		UploadedBy:  m.UploadedBy,
+		CreatedAt:   m.CreatedAt,
+	}
+}
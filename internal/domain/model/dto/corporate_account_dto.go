@@ -0,0 +1,110 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// 1:1 relationship between CorporateAccount and Customer
+// 1:N relationship between CorporateAccount and CorporateContact
+type CorporateAccountDTO struct {
+	ID          uint                  `gorm:"primaryKey"`
+	CustomerID  uint                  `gorm:"column:customer_id;not null;uniqueIndex"`
+	Customer    *CustomerDTO          `gorm:"foreignKey:CustomerID"`
+	CreditLimit float64               `gorm:"type:decimal(10,2);not null"`
+	Contacts    []CorporateContactDTO `gorm:"foreignKey:AccountID"`
+	CreatedAt   time.Time             `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time             `gorm:"autoUpdateTime"`
+}
+
+func (a *CorporateAccountDTO) ToDomain() entities.CorporateAccount {
+	account := entities.CorporateAccount{
+		ID:          a.ID,
+		CustomerID:  a.CustomerID,
+		CreditLimit: a.CreditLimit,
+		Contacts:    make([]entities.CorporateContact, 0, len(a.Contacts)),
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+	if a.Customer != nil {
+		account.CustomerName = a.Customer.FullName
+	}
+	for i := range a.Contacts {
+		account.Contacts = append(account.Contacts, a.Contacts[i].ToDomain())
+	}
+	return account
+}
+
+type CorporateContactDTO struct {
+	ID                  uint   `gorm:"primaryKey"`
+	AccountID           uint   `gorm:"column:account_id;not null;index"`
+	Name                string `gorm:"size:100;not null"`
+	Email               string `gorm:"size:100"`
+	Phone               string `gorm:"size:20"`
+	Role                string `gorm:"size:20;not null"`
+	CanApproveEstimates bool   `gorm:"not null;default:false"`
+}
+
+func (c *CorporateContactDTO) ToDomain() entities.CorporateContact {
+	return entities.CorporateContact{
+		ID:                  c.ID,
+		AccountID:           c.AccountID,
+		Name:                c.Name,
+		Email:               c.Email,
+		Phone:               c.Phone,
+		Role:                valueobject.ParseCorporateContactRole(c.Role),
+		CanApproveEstimates: c.CanApproveEstimates,
+	}
+}
+
+// 1:N relationship between CorporateStatement and CorporateStatementItem
+type CorporateStatementDTO struct {
+	ID        uint                        `gorm:"primaryKey"`
+	AccountID uint                        `gorm:"column:account_id;not null;uniqueIndex:idx_corporate_statement"`
+	Account   *CorporateAccountDTO        `gorm:"foreignKey:AccountID"`
+	Month     string                      `gorm:"size:7;not null;uniqueIndex:idx_corporate_statement"`
+	Total     float64                     `gorm:"type:decimal(10,2);not null"`
+	Items     []CorporateStatementItemDTO `gorm:"foreignKey:StatementID"`
+	ClosedAt  time.Time                   `gorm:"not null"`
+	PaidAt    *time.Time
+	PaidBy    string `gorm:"size:100"`
+}
+
+func (s *CorporateStatementDTO) ToDomain() entities.CorporateStatement {
+	statement := entities.CorporateStatement{
+		ID:        s.ID,
+		AccountID: s.AccountID,
+		Month:     s.Month,
+		Total:     s.Total,
+		ClosedAt:  s.ClosedAt,
+		PaidAt:    s.PaidAt,
+		PaidBy:    s.PaidBy,
+	}
+	if s.Account != nil {
+		statement.CustomerID = s.Account.CustomerID
+	}
+	for _, item := range s.Items {
+		statement.Items = append(statement.Items, item.ToDomain())
+	}
+	return statement
+}
+
+// CorporateStatementItemDTO is an order billed in a statement; an order is billed only once
+type CorporateStatementItemDTO struct {
+	ID             uint            `gorm:"primaryKey"`
+	StatementID    uint            `gorm:"column:statement_id;not null;index"`
+	ServiceOrderID uint            `gorm:"column:service_order_id;not null;uniqueIndex"`
+	ServiceOrder   ServiceOrderDTO `gorm:"foreignKey:ServiceOrderID"`
+	Amount         float64         `gorm:"type:decimal(10,2);not null"`
+}
+
+func (i *CorporateStatementItemDTO) ToDomain() entities.CorporateStatementItem {
+	return entities.CorporateStatementItem{
+		ServiceOrderID: i.ServiceOrderID,
+		VehicleID:      i.ServiceOrder.VehicleID,
+		Plate:          i.ServiceOrder.Vehicle.Plate,
+		DeliveredAt:    i.ServiceOrder.DeliveredAt,
+		Amount:         i.Amount,
+	}
+}
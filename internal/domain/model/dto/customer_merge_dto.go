@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+)
+
+// CustomerMergeDTO is the audit record of a customer merged into another one
+type CustomerMergeDTO struct {
+	ID                 uint   `gorm:"primaryKey"`
+	SurvivorID         uint   `gorm:"not null;index"`
+	MergedCustomerID   uint   `gorm:"not null;index"`
+	MergedFullName     string `gorm:"size:100"`
+	MergedDocument     string `gorm:"size:20"`
+	MergedEmail        string `gorm:"size:100"`
+	MergedPhoneNumber  string `gorm:"size:20"`
+	VehiclesMoved      int64
+	ServiceOrdersMoved int64
+	PaymentsMoved      int64
+	AppointmentsMoved  int64
+	MergedBy           string    `gorm:"size:100"`
+	MergedAt           time.Time `gorm:"not null"`
+}
+
+func (m *CustomerMergeDTO) ToDomain() entities.CustomerMerge {
+	return entities.CustomerMerge{
+		ID:                 m.ID,
+		SurvivorID:         m.SurvivorID,
+		MergedCustomerID:   m.MergedCustomerID,
+		MergedFullName:     m.MergedFullName,
+		MergedDocument:     m.MergedDocument,
+		MergedEmail:        m.MergedEmail,
+		MergedPhoneNumber:  m.MergedPhoneNumber,
+		VehiclesMoved:      m.VehiclesMoved,
+		ServiceOrdersMoved: m.ServiceOrdersMoved,
+		PaymentsMoved:      m.PaymentsMoved,
+		AppointmentsMoved:  m.AppointmentsMoved,
+		MergedBy:           m.MergedBy,
+		MergedAt:           m.MergedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+type CouponDTO struct {
+	ID           uint       `gorm:"primaryKey"`
+	Code         string     `gorm:"size:50;not null;unique"`
+	Description  string     `gorm:"type:text"`
+	DiscountType string     `gorm:"size:20;not null"`
+	Value        float64    `gorm:"type:decimal(10,2);not null"`
+	ValidFrom    *time.Time `gorm:"column:valid_from"`
+	ValidUntil   *time.Time `gorm:"column:valid_until"`
+	MaxUses      int        `gorm:"not null;default:0"`
+	UsedCount    int        `gorm:"not null;default:0"`
+	Active       bool       `gorm:"not null;default:true"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (c *CouponDTO) ToDomain() entities.Coupon {
+	return entities.Coupon{
+		ID:           c.ID,
+		Code:         c.Code,
+		Description:  c.Description,
+		DiscountType: valueobject.ParseDiscountType(c.DiscountType),
+		Value:        c.Value,
+		ValidFrom:    c.ValidFrom,
+		ValidUntil:   c.ValidUntil,
+		MaxUses:      c.MaxUses,
+		UsedCount:    c.UsedCount,
+		Active:       c.Active,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+	}
+}
+
+// N:1 relationship between PriceAgreement and Customer
+type PriceAgreementDTO struct {
+	ID            uint        `gorm:"primaryKey"`
+	CustomerID    uint        `gorm:"column:customer_id;not null;index"`
+	Customer      CustomerDTO `gorm:"foreignKey:CustomerID"`
+	ServiceID     *uint       `gorm:"column:service_id"`
+	PartsSupplyID *uint       `gorm:"column:parts_supply_id"`
+	Price         float64     `gorm:"type:decimal(10,2);not null"`
+	ValidFrom     *time.Time  `gorm:"column:valid_from"`
+	ValidUntil    *time.Time  `gorm:"column:valid_until"`
+	CreatedAt     time.Time   `gorm:"autoCreateTime"`
+}
+
+func (p *PriceAgreementDTO) ToDomain() entities.PriceAgreement {
+	return entities.PriceAgreement{
+		ID:            p.ID,
+		CustomerID:    p.CustomerID,
+		ServiceID:     p.ServiceID,
+		PartsSupplyID: p.PartsSupplyID,
+		Price:         p.Price,
+		ValidFrom:     p.ValidFrom,
+		ValidUntil:    p.ValidUntil,
+		CreatedAt:     p.CreatedAt,
+	}
+}
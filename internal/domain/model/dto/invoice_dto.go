@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// N:1 relationship between Invoice and ServiceOrder
+// 1:N relationship between Invoice and InvoiceItem
+type InvoiceDTO struct {
+	ID                   uint             `gorm:"primaryKey"`
+	ServiceOrderID       uint             `gorm:"column:service_order_id;not null;index"`
+	ServiceOrder         ServiceOrderDTO  `gorm:"foreignKey:ServiceOrderID"`
+	Type                 string           `gorm:"size:10;not null"`
+	Status               string           `gorm:"size:20;not null"`
+	Series               string           `gorm:"size:5"`
+	Number               string           `gorm:"size:20"`
+	Protocol             string           `gorm:"size:50"`
+	CustomerName         string           `gorm:"size:100;not null"`
+	CustomerDocument     string           `gorm:"size:20;not null"`
+	Items                []InvoiceItemDTO `gorm:"foreignKey:InvoiceID"`
+	Total                float64          `gorm:"type:decimal(10,2);not null"`
+	TaxTotal             float64          `gorm:"type:decimal(10,2);not null"`
+	XML                  string           `gorm:"column:xml;type:text"`
+	RejectionReason      string           `gorm:"type:text"`
+	IssuedAt             *time.Time
+	CancellationReason   string `gorm:"type:text"`
+	CancellationProtocol string `gorm:"size:50"`
+	CancelledAt          *time.Time
+	CreatedAt            time.Time `gorm:"autoCreateTime"`
+	UpdatedAt            time.Time `gorm:"autoUpdateTime"`
+}
+
+func (i *InvoiceDTO) ToDomain() entities.Invoice {
+	items := make([]entities.InvoiceItem, 0, len(i.Items))
+	for _, item := range i.Items {
+		items = append(items, item.ToDomain())
+	}
+	return entities.Invoice{
+		ID:                   i.ID,
+		ServiceOrderID:       i.ServiceOrderID,
+		Type:                 valueobject.ParseInvoiceType(i.Type),
+		Status:               valueobject.ParseInvoiceStatus(i.Status),
+		Series:               i.Series,
+		Number:               i.Number,
+		Protocol:             i.Protocol,
+		CustomerName:         i.CustomerName,
+		CustomerDocument:     valueobject.CpfCnpj(i.CustomerDocument),
+		Items:                items,
+		Total:                i.Total,
+		TaxTotal:             i.TaxTotal,
+		XML:                  i.XML,
+		RejectionReason:      i.RejectionReason,
+		IssuedAt:             i.IssuedAt,
+		CancellationReason:   i.CancellationReason,
+		CancellationProtocol: i.CancellationProtocol,
+		CancelledAt:          i.CancelledAt,
+		CreatedAt:            i.CreatedAt,
+	}
+}
+
+type InvoiceItemDTO struct {
+	ID          uint    `gorm:"primaryKey"`
+	InvoiceID   uint    `gorm:"column:invoice_id;not null;index"`
+	Code        string  `gorm:"size:20;not null"`
+	Description string  `gorm:"size:120;not null"`
+	Quantity    int     `gorm:"not null"`
+	UnitPrice   float64 `gorm:"type:decimal(10,2);not null"`
+	Total       float64 `gorm:"type:decimal(10,2);not null"`
+	TaxRate     float64 `gorm:"type:decimal(5,2);not null"`
+	TaxAmount   float64 `gorm:"type:decimal(10,2);not null"`
+}
+
+func (i *InvoiceItemDTO) ToDomain() entities.InvoiceItem {
+	return entities.InvoiceItem{
+		Code:        i.Code,
+		Description: i.Description,
+		Quantity:    i.Quantity,
+		UnitPrice:   i.UnitPrice,
+		Total:       i.Total,
+		TaxRate:     i.TaxRate,
+		TaxAmount:   i.TaxAmount,
+	}
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+)
+
+// 1:1 relationship between MaintenancePlan and Service
+type MaintenancePlanDTO struct {
+	ID             uint       `gorm:"primaryKey"`
+	ServiceID      uint       `gorm:"column:service_id;not null;uniqueIndex"`
+	Service        ServiceDTO `gorm:"foreignKey:ServiceID"`
+	IntervalKm     int        `gorm:"column:interval_km;not null;default:0"`
+	IntervalMonths int        `gorm:"column:interval_months;not null;default:0"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (m *MaintenancePlanDTO) ToDomain() entities.MaintenancePlan {
+	return entities.MaintenancePlan{
+		ID:             m.ID,
+		ServiceID:      m.ServiceID,
+		Service:        m.Service.Name,
+		IntervalKm:     m.IntervalKm,
+		IntervalMonths: m.IntervalMonths,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// MaintenanceReminderDTO records that the customer was reminded of a maintenance. A reminder is
+// sent once for each time the service was performed, the order it counts from being
+// ServiceOrderID.
+type MaintenanceReminderDTO struct {
+	ID                uint       `gorm:"primaryKey"`
+	VehicleID         uint       `gorm:"column:vehicle_id;not null;uniqueIndex:idx_maintenance_reminder"`
+	MaintenancePlanID uint       `gorm:"column:maintenance_plan_id;not null;uniqueIndex:idx_maintenance_reminder"`
+	ServiceOrderID    uint       `gorm:"column:service_order_id;not null;uniqueIndex:idx_maintenance_reminder"`
+	DueDate           *time.Time `gorm:"column:due_date"`
+	DueOdometer       *int       `gorm:"column:due_odometer"`
+	Channels          string     `gorm:"size:50;not null"`
+	SentAt            time.Time  `gorm:"not null"`
+}
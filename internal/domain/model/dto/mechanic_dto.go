@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MechanicDTO struct {
+	ID          uint           `gorm:"primaryKey"`
+	Name        string         `gorm:"size:100;not null"`
+	Email       string         `gorm:"size:100"`
+	PhoneNumber string         `gorm:"size:20"`
+	Specialty   string         `gorm:"size:50"`
+	Active      bool           `gorm:"not null;default:true"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (m *MechanicDTO) ToDomain() entities.Mechanic {
+	return entities.Mechanic{
+		ID:          m.ID,
+		Name:        m.Name,
+		Email:       m.Email,
+		PhoneNumber: m.PhoneNumber,
+		Specialty:   m.Specialty,
+		Active:      m.Active,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// N:1 relationship between MechanicAssignment and ServiceOrder
+type MechanicAssignmentDTO struct {
+	ID             uint        `gorm:"primaryKey"`
+	ServiceOrderID uint        `gorm:"not null;index"`
+	MechanicID     uint        `gorm:"not null;index"`
+	Mechanic       MechanicDTO `gorm:"foreignKey:MechanicID"`
+	ServiceID      *uint
+	AssignedBy     string    `gorm:"size:100"`
+	AssignedAt     time.Time `gorm:"not null"`
+}
+
+func (m *MechanicAssignmentDTO) ToDomain() entities.MechanicAssignment {
+	assignment := entities.MechanicAssignment{
+		ID:             m.ID,
+		ServiceOrderID: m.ServiceOrderID,
+		MechanicID:     m.MechanicID,
+		ServiceID:      m.ServiceID,
+		AssignedBy:     m.AssignedBy,
+		AssignedAt:     m.AssignedAt,
+	}
+	if m.Mechanic.ID != 0 {
+		mechanic := m.Mechanic.ToDomain()
+		assignment.Mechanic = &mechanic
+	}
+	return assignment
+}
+
+// LabourEntryDTO is a clock-in on a service of a service order. A mechanic can only have one open
+// entry at a time, which the partial unique index enforces.
+type LabourEntryDTO struct {
+	ID             uint        `gorm:"primaryKey"`
+	ServiceOrderID uint        `gorm:"not null;index"`
+	ServiceID      uint        `gorm:"not null"`
+	Service        ServiceDTO  `gorm:"foreignKey:ServiceID"`
+	MechanicID     uint        `gorm:"not null;index;uniqueIndex:idx_labour_open_entry,where:clock_out IS NULL"`
+	Mechanic       MechanicDTO `gorm:"foreignKey:MechanicID"`
+	ClockIn        time.Time   `gorm:"not null;index"`
+	ClockOut       *time.Time
+	Minutes        int       `gorm:"not null;default:0"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (m *LabourEntryDTO) ToDomain() entities.LabourEntry {
+	return entities.LabourEntry{
+		ID:             m.ID,
+		ServiceOrderID: m.ServiceOrderID,
+		ServiceID:      m.ServiceID,
+		MechanicID:     m.MechanicID,
+		ClockIn:        m.ClockIn,
+		ClockOut:       m.ClockOut,
+		Minutes:        m.Minutes,
+	}
+}
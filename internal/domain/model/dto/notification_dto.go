@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// N:1 relationship between NotificationDelivery and ServiceOrder. The message of a domain event
+// is queued once per channel.
+type NotificationDeliveryDTO struct {
+	ID             uint       `gorm:"primaryKey"`
+	EventID        *uint      `gorm:"uniqueIndex:idx_notification_delivery_event"`
+	ServiceOrderID uint       `gorm:"column:service_order_id;not null;index"`
+	Event          string     `gorm:"size:50;not null"`
+	Channel        string     `gorm:"size:10;not null;uniqueIndex:idx_notification_delivery_event"`
+	Recipient      string     `gorm:"size:100;not null"`
+	Subject        string     `gorm:"size:150"`
+	Body           string     `gorm:"type:text;not null"`
+	Status         string     `gorm:"size:10;not null;index"`
+	Attempts       int        `gorm:"not null;default:0"`
+	LastError      string     `gorm:"type:text"`
+	NextAttemptAt  *time.Time `gorm:"index"`
+	SentAt         *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (n *NotificationDeliveryDTO) ToDomain() entities.NotificationDelivery {
+	return entities.NotificationDelivery{
+		ID:             n.ID,
+		ServiceOrderID: n.ServiceOrderID,
+		Event:          n.Event,
+		Channel:        valueobject.ParseNotificationChannel(n.Channel),
+		Recipient:      n.Recipient,
+		Subject:        n.Subject,
+		Body:           n.Body,
+		Status:         valueobject.ParseNotificationStatus(n.Status),
+		Attempts:       n.Attempts,
+		LastError:      n.LastError,
+		NextAttemptAt:  n.NextAttemptAt,
+		SentAt:         n.SentAt,
+		CreatedAt:      n.CreatedAt,
+	}
+}
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"encoding/json"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// OutboxEventDTO is a domain event waiting to be, or already, delivered to its subscribers.
+// DispatchedAt stays empty until every subscriber handled the event.
+type OutboxEventDTO struct {
+	ID             uint       `gorm:"primaryKey"`
+	EventType      string     `gorm:"size:50;not null;index"`
+	AggregateType  string     `gorm:"size:30;not null"`
+	AggregateID    uint       `gorm:"not null"`
+	ServiceOrderID uint       `gorm:"column:service_order_id;index"`
+	Payload        string     `gorm:"type:text"`
+	OccurredAt     time.Time  `gorm:"not null"`
+	Attempts       int        `gorm:"not null;default:0"`
+	LastError      string     `gorm:"type:text"`
+	NextAttemptAt  *time.Time `gorm:"index"`
+	DispatchedAt   *time.Time `gorm:"index"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+}
+
+func (o *OutboxEventDTO) ToDomain() entities.DomainEvent {
+	var payload map[string]interface{}
+	if o.Payload != "" {
+		// The payload is written by NewOutboxEventDTO, a malformed one is left out rather than failing the event
+		_ = json.Unmarshal([]byte(o.Payload), &payload)
+	}
+	return entities.DomainEvent{
+		ID:             o.ID,
+		Type:           valueobject.ParseDomainEventType(o.EventType),
+		AggregateType:  o.AggregateType,
+		AggregateID:    o.AggregateID,
+		ServiceOrderID: o.ServiceOrderID,
+		Payload:        payload,
+		OccurredAt:     o.OccurredAt,
+	}
+}
+
+// NewOutboxEventDTO prepares an event to be stored, ready for its first delivery
+func NewOutboxEventDTO(event entities.DomainEvent) (*OutboxEventDTO, error) {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return nil, err
+	}
+	occurredAt := event.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	return &OutboxEventDTO{
+		EventType:      event.Type.String(),
+		AggregateType:  event.AggregateType,
+		AggregateID:    event.AggregateID,
+		ServiceOrderID: event.ServiceOrderID,
+		Payload:        string(payload),
+		OccurredAt:     occurredAt,
+		NextAttemptAt:  &occurredAt,
+	}, nil
+}
@@ -14,12 +14,13 @@ type PartsSupplyDTO struct {
 	Name              string                `gorm:"size:100;not null"`
 	Description       string                `gorm:"type:text"`
 	Price             float64               `gorm:"type:decimal(10,2);not null"`
+	Category          string                `gorm:"size:50;index"`
 	QuantityTotal     int                   `gorm:"not null;default:0"`
 	QuantityReserve   int                   `gorm:"not null;default:0"`
 	CreatedAt         time.Time             `gorm:"autoCreateTime"`
 	UpdatedAt         time.Time             `gorm:"autoUpdateTime"`
 	DeletedAt         gorm.DeletedAt        `gorm:"index"`
-	AdditionalRepairs []AdditionalRepairDTO `gorm:"many2many:parts_supply_additional_repair"`
+	AdditionalRepairs []AdditionalRepairDTO `gorm:"many2many:parts_supply_additional_repair_dtos;joinForeignKey:parts_supply_id;joinReferences:additional_repair_id"`
 	ServiceOrders     []ServiceOrderDTO     `gorm:"many2many:parts_supply_service_order_dtos;joinForeignKey:parts_supply_id;joinReferences:service_order_id"`
 }
 
@@ -29,6 +30,7 @@ func (m *PartsSupplyDTO) ToDomain() entities.PartsSupply {
 		Name:            m.Name,
 		Description:     m.Description,
 		Price:           m.Price,
+		Category:        m.Category,
 		QuantityTotal:   m.QuantityTotal,
 		QuantityReserve: m.QuantityReserve,
 		CreatedAt:       m.CreatedAt,
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"time"
 )
 
@@ -11,6 +12,12 @@ type PaymentDTO struct {
 	ServiceOrder   ServiceOrderDTO `gorm:"foreignKey:ServiceOrderID;references:ID"`
 	PaymentDate    time.Time       `gorm:"not null"`
 	Amount         float64         `gorm:"not null"`
+	Method         string          `gorm:"size:20;not null;default:DINHEIRO"`
+	Operator       string          `gorm:"size:100"`
+	ISSAmount      float64         `gorm:"column:iss_amount;type:decimal(10,2);default:0"`
+	ICMSAmount     float64         `gorm:"column:icms_amount;type:decimal(10,2);default:0"`
+	TaxTotal       float64         `gorm:"column:tax_total;type:decimal(10,2);default:0"`
+	CashClosingID  *uint           `gorm:"column:cash_closing_id;index"`
 }
 
 func (pm *PaymentDTO) ToDomain() *entities.Payment {
@@ -19,5 +26,11 @@ func (pm *PaymentDTO) ToDomain() *entities.Payment {
 		ServiceOrderID: pm.ServiceOrder.ToDomain().ID,
 		PaymentDate:    pm.PaymentDate,
 		Amount:         pm.Amount,
+		Method:         valueobject.ParsePaymentMethod(pm.Method),
+		Operator:       pm.Operator,
+		ISSAmount:      pm.ISSAmount,
+		ICMSAmount:     pm.ICMSAmount,
+		TaxTotal:       pm.TaxTotal,
+		CashClosingID:  pm.CashClosingID,
 	}
 }
@@ -12,21 +12,25 @@ type ServiceDTO struct {
 	Name              string                `gorm:"size:100;not null"`
 	Description       string                `gorm:"type:text"`
 	Price             float64               `gorm:"type:decimal(10,2);not null"`
+	Category          string                `gorm:"size:50;index"`
+	StandardMinutes   int                   `gorm:"not null;default:0"`
 	CreatedAt         time.Time             `gorm:"autoCreateTime"`
 	UpdatedAt         time.Time             `gorm:"autoUpdateTime"`
 	DeletedAt         gorm.DeletedAt        `gorm:"index"`
-	AdditionalRepairs []AdditionalRepairDTO `gorm:"many2many:service_additional_repair"`
+	AdditionalRepairs []AdditionalRepairDTO `gorm:"many2many:service_additional_repair_dtos;joinForeignKey:service_id;joinReferences:additional_repair_id"`
 	ServiceOrders     []ServiceOrderDTO     `gorm:"many2many:service_service_order_dtos;joinForeignKey:service_id;joinReferences:service_order_id"`
 }
 
 func (m *ServiceDTO) ToDomain() entities.Service {
 	return entities.Service{
-		ID:          m.ID,
-		Name:        m.Name,
-		Description: m.Description,
-		Price:       m.Price,
-		CreatedAt:   m.CreatedAt,
-		UpdatedAt:   m.UpdatedAt,
+		ID:              m.ID,
+		Name:            m.Name,
+		Description:     m.Description,
+		Price:           m.Price,
+		Category:        m.Category,
+		StandardMinutes: m.StandardMinutes,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
 		DeletedAt: func() *time.Time {
 			if m.DeletedAt.Valid {
 				return &m.DeletedAt.Time
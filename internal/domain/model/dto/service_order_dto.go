@@ -8,15 +8,17 @@ import (
 
 // N:N relationship between PartsSupply and ServiceOrder
 type PartsSupplyServiceOrderDTO struct {
-	PartsSupplyID  uint `gorm:"column:parts_supply_id;primaryKey"`
-	ServiceOrderID uint `gorm:"column:service_order_id;primaryKey"`
-	Quantity       int  `gorm:"column:quantity"`
+	PartsSupplyID  uint    `gorm:"column:parts_supply_id;primaryKey"`
+	ServiceOrderID uint    `gorm:"column:service_order_id;primaryKey"`
+	Quantity       int     `gorm:"column:quantity"`
+	Discount       float64 `gorm:"column:discount;type:decimal(10,2);default:0"`
 }
 
 // N:N relationship between Service and ServiceOrder
 type ServiceServiceOrderDTO struct {
-	ServiceID      uint `gorm:"primaryKey"`
-	ServiceOrderID uint `gorm:"primaryKey"`
+	ServiceID      uint    `gorm:"primaryKey"`
+	ServiceOrderID uint    `gorm:"primaryKey"`
+	Discount       float64 `gorm:"column:discount;type:decimal(10,2);default:0"`
 }
 
 type ServiceOrderStatusDTO struct {
@@ -37,14 +39,39 @@ type ServiceOrderDTO struct {
 	OSStatusID           uint                  `gorm:"not null"`
 	ServiceOrderStatus   ServiceOrderStatusDTO `gorm:"foreignKey:OSStatusID"`
 	Estimate             float64               `gorm:"type:decimal(10,2)"`
+	GrossEstimate        float64               `gorm:"type:decimal(10,2);default:0"`
+	DiscountTotal        float64               `gorm:"type:decimal(10,2);default:0"`
+	CouponID             *uint                 `gorm:"column:coupon_id"`
+	Coupon               *CouponDTO            `gorm:"foreignKey:CouponID"`
+	DiscountPending      bool                  `gorm:"column:discount_pending;not null;default:false"`
+	DiscountApprovedBy   string                `gorm:"column:discount_approved_by;size:100"`
+	DiscountApprovedAt   *time.Time            `gorm:"column:discount_approved_at"`
+	ISSTotal             float64               `gorm:"column:iss_total;type:decimal(10,2);default:0"`
+	ICMSTotal            float64               `gorm:"column:icms_total;type:decimal(10,2);default:0"`
+	TaxTotal             float64               `gorm:"column:tax_total;type:decimal(10,2);default:0"`
+	Taxes                []ServiceOrderTaxDTO  `gorm:"foreignKey:ServiceOrderID"`
 	StartedExecutionDate *time.Time
 	FinalExecutionDate   *time.Time
+	DeliveredAt          *time.Time
+	ApproverContactID    *uint                 `gorm:"column:approver_contact_id"`
 	CreatedAt            *time.Time            `gorm:"autoCreateTime"`
 	UpdatedAt            *time.Time            `gorm:"autoUpdateTime"`
 	AdditionalRepairs    []AdditionalRepairDTO `gorm:"foreignKey:ServiceOrderID"`
 	Payment              *PaymentDTO           `gorm:"foreignKey:ServiceOrderID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	PartsSupplies        []PartsSupplyDTO      `gorm:"many2many:parts_supply_service_order_dtos;"`
 	Services             []ServiceDTO          `gorm:"many2many:service_service_order_dtos;"`
+	// PartsSupplyItems are the rows of the parts supplies join table, carrying the quantity of each part
+	PartsSupplyItems []PartsSupplyServiceOrderDTO `gorm:"foreignKey:ServiceOrderID"`
+}
+
+// PartsSupplyQuantity returns how many units of the parts supply the service order uses
+func (m *ServiceOrderDTO) PartsSupplyQuantity(partsSupplyID uint) int {
+	for _, item := range m.PartsSupplyItems {
+		if item.PartsSupplyID == partsSupplyID {
+			return item.Quantity
+		}
+	}
+	return 0
 }
 
 func (m *ServiceOrderDTO) ToDomain() *entities.ServiceOrder {
@@ -74,6 +101,26 @@ func (m *ServiceOrderDTO) ToDomain() *entities.ServiceOrder {
 		payment = p
 	}
 
+	// Convert discount approval if the order had discounts above the approval threshold
+	var discountApproval *entities.DiscountApproval
+	if m.DiscountPending || m.DiscountApprovedBy != "" {
+		discountApproval = &entities.DiscountApproval{
+			Pending:    m.DiscountPending,
+			ApprovedBy: m.DiscountApprovedBy,
+			ApprovedAt: m.DiscountApprovedAt,
+		}
+	}
+
+	var taxes []entities.TaxLine
+	for _, t := range m.Taxes {
+		taxes = append(taxes, t.ToDomain())
+	}
+
+	var couponCode string
+	if m.Coupon != nil {
+		couponCode = m.Coupon.Code
+	}
+
 	// Convert Customer and Vehicle if they are loaded
 	var customer entities.Customer
 	var vehicle entities.Vehicle
@@ -92,8 +139,18 @@ func (m *ServiceOrderDTO) ToDomain() *entities.ServiceOrder {
 		Vehicle:              &vehicle,
 		ServiceOrderStatus:   m.ServiceOrderStatus.ToDomain(),
 		Estimate:             m.Estimate,
+		GrossEstimate:        m.GrossEstimate,
+		DiscountTotal:        m.DiscountTotal,
+		CouponCode:           couponCode,
+		DiscountApproval:     discountApproval,
+		ISSTotal:             m.ISSTotal,
+		ICMSTotal:            m.ICMSTotal,
+		TaxTotal:             m.TaxTotal,
+		Taxes:                taxes,
 		StartedExecutionDate: m.StartedExecutionDate,
 		FinalExecutionDate:   m.FinalExecutionDate,
+		DeliveredAt:          m.DeliveredAt,
+		ApproverContactID:    m.ApproverContactID,
 		CreatedAt:            m.CreatedAt,
 		UpdatedAt:            m.UpdatedAt,
 		AdditionalRepairs:    additionalRepairs,
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 1:1 relationship between Staff and User, whose type is the role of the employee
+type StaffDTO struct {
+	ID          uint           `gorm:"primaryKey"`
+	UserID      uint           `gorm:"unique;not null"`
+	User        *UserDTO       `gorm:"foreignKey:UserID;references:ID"`
+	FullName    string         `gorm:"size:100;not null"`
+	PhoneNumber string         `gorm:"size:20"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (m *StaffDTO) ToDomain() entities.Staff {
+	staff := entities.Staff{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		FullName:    m.FullName,
+		PhoneNumber: m.PhoneNumber,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+	if m.User != nil {
+		staff.Email = m.User.Email
+		staff.Role = m.User.UserType
+		staff.Permissions = m.User.UserType.Permissions()
+	}
+	return staff
+}
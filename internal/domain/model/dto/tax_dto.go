@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+type TaxRuleDTO struct {
+	ID          uint      `gorm:"primaryKey"`
+	TaxType     string    `gorm:"size:10;not null;uniqueIndex:idx_tax_rule_type_category"`
+	Category    string    `gorm:"size:50;not null;default:'';uniqueIndex:idx_tax_rule_type_category"`
+	Rate        float64   `gorm:"type:decimal(5,2);not null"`
+	Description string    `gorm:"type:text"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (t *TaxRuleDTO) ToDomain() entities.TaxRule {
+	return entities.TaxRule{
+		ID:          t.ID,
+		TaxType:     valueobject.ParseTaxType(t.TaxType),
+		Category:    t.Category,
+		Rate:        t.Rate,
+		Description: t.Description,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// 1:N relationship between ServiceOrder and its tax lines
+type ServiceOrderTaxDTO struct {
+	ID             uint    `gorm:"primaryKey"`
+	ServiceOrderID uint    `gorm:"column:service_order_id;not null;index"`
+	TaxType        string  `gorm:"size:10;not null"`
+	Category       string  `gorm:"size:50"`
+	ServiceID      *uint   `gorm:"column:service_id"`
+	PartsSupplyID  *uint   `gorm:"column:parts_supply_id"`
+	Base           float64 `gorm:"type:decimal(10,2);not null"`
+	Rate           float64 `gorm:"type:decimal(5,2);not null"`
+	Amount         float64 `gorm:"type:decimal(10,2);not null"`
+}
+
+func (t *ServiceOrderTaxDTO) ToDomain() entities.TaxLine {
+	line := entities.TaxLine{
+		TaxType:  valueobject.ParseTaxType(t.TaxType),
+		Category: t.Category,
+		Base:     t.Base,
+		Rate:     t.Rate,
+		Amount:   t.Amount,
+	}
+	if t.ServiceID != nil {
+		line.ServiceID = *t.ServiceID
+	}
+	if t.PartsSupplyID != nil {
+		line.PartsSupplyID = *t.PartsSupplyID
+	}
+	return line
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+)
+
+type VehicleModelDTO struct {
+	ID        uint      `gorm:"primaryKey"`
+	FipeCode  string    `gorm:"size:10;index"`
+	Brand     string    `gorm:"size:50;not null;uniqueIndex:idx_vehicle_model"`
+	Model     string    `gorm:"size:100;not null;uniqueIndex:idx_vehicle_model"`
+	Year      int       `gorm:"not null;uniqueIndex:idx_vehicle_model"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (m *VehicleModelDTO) ToDomain() entities.VehicleModel {
+	return entities.VehicleModel{
+		ID:       m.ID,
+		FipeCode: m.FipeCode,
+		Brand:    m.Brand,
+		Model:    m.Model,
+		Year:     m.Year,
+	}
+}
@@ -16,6 +16,7 @@ type VehicleDTO struct {
 	Model         string            `gorm:"size:50;not null"`
 	Year          string            `gorm:"size:4"`
 	Brand         string            `gorm:"size:50;not null"`
+	VIN           string            `gorm:"size:17;index"`
 	CreatedAt     time.Time         `gorm:"autoCreateTime"`
 	UpdatedAt     *time.Time        `gorm:"autoUpdateTime"`
 	DeletedAt     gorm.DeletedAt    `gorm:"index"`
@@ -37,6 +38,7 @@ func (v *VehicleDTO) ToDomain() *entities.Vehicle {
 		Model:      v.Model,
 		Year:       v.Year,
 		Brand:      v.Brand,
+		VIN:        valueobject.ParseVIN(v.VIN),
 		CreatedAt:  v.CreatedAt,
 		UpdatedAt:  v.UpdatedAt,
 		DeletedAt: func() *time.Time {
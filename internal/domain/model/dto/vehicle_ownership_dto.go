@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+)
+
+// N:1 relationship between VehicleOwnership and Vehicle
+type VehicleOwnershipDTO struct {
+	ID            uint         `gorm:"primaryKey"`
+	VehicleID     uint         `gorm:"column:vehicle_id;not null;index"`
+	CustomerID    uint         `gorm:"column:customer_id;not null;index"`
+	Customer      *CustomerDTO `gorm:"foreignKey:CustomerID"`
+	StartedAt     time.Time    `gorm:"not null"`
+	EndedAt       *time.Time
+	TransferredBy string    `gorm:"size:100"`
+	Notes         string    `gorm:"type:text"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (o *VehicleOwnershipDTO) ToDomain() entities.VehicleOwnership {
+	ownership := entities.VehicleOwnership{
+		ID:            o.ID,
+		VehicleID:     o.VehicleID,
+		CustomerID:    o.CustomerID,
+		StartedAt:     o.StartedAt,
+		EndedAt:       o.EndedAt,
+		TransferredBy: o.TransferredBy,
+		Notes:         o.Notes,
+	}
+	if o.Customer != nil {
+		ownership.CustomerName = o.Customer.FullName
+	}
+	return ownership
+}
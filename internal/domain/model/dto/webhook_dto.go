@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookSubscriptionDTO struct {
+	ID     uint   `gorm:"primaryKey"`
+	URL    string `gorm:"size:500;not null"`
+	Secret string `gorm:"size:128;not null"`
+	// EventTypes is a comma separated list, empty for every event
+	EventTypes string         `gorm:"size:255"`
+	CustomerID *uint          `gorm:"index"`
+	Active     bool           `gorm:"not null;default:true"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+// ToDomain converts the subscription leaving the secret out, as it is only shown when created
+func (w *WebhookSubscriptionDTO) ToDomain() entities.WebhookSubscription {
+	eventTypes := []valueobject.DomainEventType{}
+	for _, eventType := range strings.Split(w.EventTypes, ",") {
+		if eventType != "" {
+			eventTypes = append(eventTypes, valueobject.ParseDomainEventType(eventType))
+		}
+	}
+	return entities.WebhookSubscription{
+		ID:         w.ID,
+		URL:        w.URL,
+		EventTypes: eventTypes,
+		CustomerID: w.CustomerID,
+		Active:     w.Active,
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}
+}
+
+// Accepts tells whether the subscription wants events of the type
+func (w *WebhookSubscriptionDTO) Accepts(eventType valueobject.DomainEventType) bool {
+	if w.EventTypes == "" {
+		return true
+	}
+	for _, accepted := range strings.Split(w.EventTypes, ",") {
+		if accepted == eventType.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// N:1 relationship between WebhookDelivery and WebhookSubscription. An event is delivered once per subscription.
+type WebhookDeliveryDTO struct {
+	ID             uint                   `gorm:"primaryKey"`
+	SubscriptionID uint                   `gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	Subscription   WebhookSubscriptionDTO `gorm:"foreignKey:SubscriptionID"`
+	EventID        uint                   `gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	EventType      string                 `gorm:"size:50;not null"`
+	Payload        string                 `gorm:"type:text;not null"`
+	Status         string                 `gorm:"size:10;not null;index"`
+	Attempts       int                    `gorm:"not null;default:0"`
+	ResponseStatus int
+	LastError      string     `gorm:"type:text"`
+	NextAttemptAt  *time.Time `gorm:"index"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (w *WebhookDeliveryDTO) ToDomain() entities.WebhookDelivery {
+	return entities.WebhookDelivery{
+		ID:             w.ID,
+		SubscriptionID: w.SubscriptionID,
+		EventID:        w.EventID,
+		EventType:      valueobject.ParseDomainEventType(w.EventType),
+		Payload:        w.Payload,
+		Status:         valueobject.ParseWebhookDeliveryStatus(w.Status),
+		Attempts:       w.Attempts,
+		ResponseStatus: w.ResponseStatus,
+		LastError:      w.LastError,
+		NextAttemptAt:  w.NextAttemptAt,
+		DeliveredAt:    w.DeliveredAt,
+		CreatedAt:      w.CreatedAt,
+	}
+}
@@ -16,6 +16,24 @@ type AdditionalRepair struct {
 	UpdatedAt      time.Time                          `json:"updated_at"`
 	PartsSupplies  []PartsSupply                      `json:"parts_supplies,omitempty"`
 	Services       []Service                          `json:"services,omitempty"`
+	History        []AdditionalRepairHistory          `json:"history,omitempty"`
+}
+
+// actions recorded in the history of an additional repair
+const (
+	AdditionalRepairCreated       = "CREATED"
+	AdditionalRepairItemsAdded    = "ITEMS_ADDED"
+	AdditionalRepairItemsRemoved  = "ITEMS_REMOVED"
+	AdditionalRepairStatusChanged = "STATUS_CHANGED"
+)
+
+// AdditionalRepairHistory is a change made to an additional repair, with its effect on the estimate
+type AdditionalRepairHistory struct {
+	ID             uint      `json:"id"`
+	Action         string    `json:"action"`
+	Description    string    `json:"description"`
+	EstimateChange float64   `json:"estimate_change"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type AdditionalRepairStatusDTO struct {
@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// Bay is a workshop bay or lift. Each one holds a single vehicle at a time, so the number of active
+// bays is the capacity of every time slot.
+type Bay struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name" binding:"required"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Appointment is a visit booked by a customer, holding a bay from StartsAt until EndsAt
+type Appointment struct {
+	ID             uint                          `json:"id"`
+	CustomerID     uint                          `json:"customer_id"`
+	VehicleID      uint                          `json:"vehicle_id"`
+	BayID          uint                          `json:"bay_id"`
+	Bay            *Bay                          `json:"bay,omitempty"`
+	Services       []Service                     `json:"services,omitempty"`
+	StartsAt       time.Time                     `json:"starts_at"`
+	EndsAt         time.Time                     `json:"ends_at"`
+	Status         valueobject.AppointmentStatus `json:"status"`
+	Notes          string                        `json:"notes,omitempty"`
+	ServiceOrderID *uint                         `json:"service_order_id,omitempty"`
+	CreatedBy      string                        `json:"created_by"`
+	CreatedAt      time.Time                     `json:"created_at"`
+}
+
+// AppointmentRequest books a visit. The length of the visit is the standard time of the desired
+// services; without a bay, the first one free in that interval is taken.
+type AppointmentRequest struct {
+	CustomerID uint      `json:"customer_id" binding:"required"`
+	VehicleID  uint      `json:"vehicle_id" binding:"required"`
+	ServiceIDs []uint    `json:"service_ids"`
+	StartsAt   time.Time `json:"starts_at" binding:"required"`
+	BayID      *uint     `json:"bay_id,omitempty"`
+	Notes      string    `json:"notes,omitempty"`
+	CreatedBy  string    `json:"-"`
+}
+
+// RescheduleRequest moves an appointment to another start and, optionally, another bay
+type RescheduleRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	BayID    *uint     `json:"bay_id,omitempty"`
+}
+
+// AvailableSlot is an interval in which the listed bays are free
+type AvailableSlot struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	BayIDs   []uint    `json:"bay_ids"`
+}
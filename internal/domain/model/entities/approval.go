@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// ApprovalLink is a signed, single-use link sent to the customer to approve or reject an
+// estimate or an additional repair without logging in
+type ApprovalLink struct {
+	ID                 uint                        `json:"id"`
+	Subject            valueobject.ApprovalSubject `json:"subject"`
+	ServiceOrderID     uint                        `json:"service_order_id"`
+	AdditionalRepairID *uint                       `json:"additional_repair_id,omitempty"`
+	ApproverContactID  *uint                       `json:"approver_contact_id,omitempty"`
+	// Token and URL are only returned when the link is created: the token itself is not stored
+	Token     string           `json:"token,omitempty"`
+	URL       string           `json:"url,omitempty"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	CreatedBy string           `json:"created_by,omitempty"`
+	Decision  string           `json:"decision,omitempty"`
+	DecidedAt *time.Time       `json:"decided_at,omitempty"`
+	Consent   *ApprovalConsent `json:"consent,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// EstimateLinkRequest names the contact of a corporate account the estimate link is sent to, who
+// must be allowed to approve its estimates
+type EstimateLinkRequest struct {
+	ApproverContactID *uint `json:"approver_contact_id"`
+}
+
+// ApprovalConsent identifies where a customer decision came from, kept as proof of consent
+type ApprovalConsent struct {
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+}
+
+// ApprovalRequest is what the customer sees when opening an approval link
+type ApprovalRequest struct {
+	Subject          valueobject.ApprovalSubject `json:"subject"`
+	ExpiresAt        time.Time                   `json:"expires_at"`
+	ServiceOrder     *ServiceOrder               `json:"service_order,omitempty"`
+	AdditionalRepair *AdditionalRepair           `json:"additional_repair,omitempty"`
+}
+
+type ApprovalDecision struct {
+	Decision string `json:"decision" binding:"required,oneof=APPROVED DENIED"`
+}
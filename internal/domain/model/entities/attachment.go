@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// Attachment is a file kept as evidence of a service order or additional repair, such as a photo of
+// a worn part or a scanner report. The file itself is kept on the blob store.
+type Attachment struct {
+	ID             uint                        `json:"id"`
+	OwnerType      valueobject.AttachmentOwner `json:"owner_type"`
+	OwnerID        uint                        `json:"owner_id"`
+	ServiceOrderID uint                        `json:"service_order_id"`
+	FileName       string                      `json:"file_name"`
+	ContentType    string                      `json:"content_type"`
+	Size           int64                       `json:"size"`
+	// Checksum is the hex SHA-256 of the content, so a downloaded copy can be checked
+	Checksum   string    `json:"checksum"`
+	UploadedBy string    `json:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AttachmentUpload is a file being attached to a service order or additional repair
+type AttachmentUpload struct {
+	FileName   string
+	Size       int64
+	UploadedBy string
+}
+
+// AttachmentDownloadURL is a signed link that downloads an attachment without logging in, until it
+// expires
+type AttachmentDownloadURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// CheckIn is the inspection of a vehicle when it is received, recorded while the service order is
+// RECEBIDA so the workshop and the customer agree on the state the vehicle arrived in
+type CheckIn struct {
+	ID             uint                  `json:"id"`
+	ServiceOrderID uint                  `json:"service_order_id"`
+	Odometer       int                   `json:"odometer"`
+	FuelLevel      valueobject.FuelLevel `json:"fuel_level"`
+	Checklist      []CheckInItem         `json:"checklist"`
+	Notes          string                `json:"notes,omitempty"`
+	InspectedBy    string                `json:"inspected_by"`
+	Photos         []CheckInPhoto        `json:"photos"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// CheckInItem is a point of the checklist, such as the spare tyre or a scratch on a door
+type CheckInItem struct {
+	Name      string                         `json:"name" binding:"required"`
+	Condition valueobject.ChecklistCondition `json:"condition" binding:"required"`
+	Notes     string                         `json:"notes,omitempty"`
+}
+
+// CheckInPhoto describes a photo taken at the check-in; the file itself is kept on the blob store
+type CheckInPhoto struct {
+	ID          uint      `json:"id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Caption     string    `json:"caption,omitempty"`
+	UploadedBy  string    `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CheckInRequest records, or replaces, the inspection of a service order. Photos are sent apart.
+type CheckInRequest struct {
+	Odometer    int                   `json:"odometer" binding:"min=0"`
+	FuelLevel   valueobject.FuelLevel `json:"fuel_level" binding:"required"`
+	Checklist   []CheckInItem         `json:"checklist" binding:"dive"`
+	Notes       string                `json:"notes,omitempty"`
+	InspectedBy string                `json:"-"`
+}
+
+// CheckInPhotoUpload is a photo being attached to the check-in of a service order
+type CheckInPhotoUpload struct {
+	FileName   string
+	Caption    string
+	Size       int64
+	UploadedBy string
+}
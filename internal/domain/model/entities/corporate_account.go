@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// CorporateAccount is the account of a company customer, billed monthly for the orders delivered
+// to it up to its credit limit
+type CorporateAccount struct {
+	ID           uint    `json:"id"`
+	CustomerID   uint    `json:"customer_id"`
+	CustomerName string  `json:"customer_name,omitempty"`
+	CreditLimit  float64 `json:"credit_limit"`
+	// OpenBalance is what the company owes, counting the estimates of the orders still open
+	OpenBalance     float64            `json:"open_balance"`
+	AvailableCredit float64            `json:"available_credit"`
+	Contacts        []CorporateContact `json:"contacts"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+type CorporateAccountRequest struct {
+	CreditLimit float64 `json:"credit_limit" binding:"gt=0"`
+}
+
+// CorporateContact is a person the workshop deals with on behalf of the company. Only the
+// contacts allowed to approve estimates may approve those of the company's orders.
+type CorporateContact struct {
+	ID                  uint                             `json:"id"`
+	AccountID           uint                             `json:"account_id"`
+	Name                string                           `json:"name"`
+	Email               string                           `json:"email,omitempty"`
+	Phone               string                           `json:"phone,omitempty"`
+	Role                valueobject.CorporateContactRole `json:"role"`
+	CanApproveEstimates bool                             `json:"can_approve_estimates"`
+}
+
+type CorporateContactRequest struct {
+	Name                string `json:"name" binding:"required"`
+	Email               string `json:"email" binding:"omitempty,email"`
+	Phone               string `json:"phone"`
+	Role                string `json:"role" binding:"required"`
+	CanApproveEstimates bool   `json:"can_approve_estimates"`
+}
+
+// CorporateStatement consolidates the orders delivered to a company in a month into one bill
+type CorporateStatement struct {
+	ID         uint                     `json:"id"`
+	AccountID  uint                     `json:"account_id"`
+	CustomerID uint                     `json:"customer_id"`
+	Month      string                   `json:"month"`
+	Total      float64                  `json:"total"`
+	Items      []CorporateStatementItem `json:"items,omitempty"`
+	ClosedAt   time.Time                `json:"closed_at"`
+	PaidAt     *time.Time               `json:"paid_at,omitempty"`
+	PaidBy     string                   `json:"paid_by,omitempty"`
+}
+
+type CorporateStatementItem struct {
+	ServiceOrderID uint       `json:"service_order_id"`
+	VehicleID      uint       `json:"vehicle_id"`
+	Plate          string     `json:"plate,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	Amount         float64    `json:"amount"`
+}
+
+type CorporateStatementPaymentRequest struct {
+	Method valueobject.PaymentMethod `json:"method" binding:"required"`
+}
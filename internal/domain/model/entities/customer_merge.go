@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// CustomerDuplicateGroup is a set of customers that look like the same person or company
+type CustomerDuplicateGroup struct {
+	Reason    valueobject.CustomerDuplicateReason `json:"reason"`
+	Customers []Customer                          `json:"customers"`
+}
+
+type CustomerMergeRequest struct {
+	DuplicateID uint `json:"duplicate_id" binding:"required"`
+}
+
+// CustomerMerge records a duplicate customer merged into the surviving one, with what the duplicate
+// was registered as, since it no longer exists
+type CustomerMerge struct {
+	ID                 uint      `json:"id"`
+	SurvivorID         uint      `json:"survivor_id"`
+	MergedCustomerID   uint      `json:"merged_customer_id"`
+	MergedFullName     string    `json:"merged_full_name"`
+	MergedDocument     string    `json:"merged_document"`
+	MergedEmail        string    `json:"merged_email,omitempty"`
+	MergedPhoneNumber  string    `json:"merged_phone_number,omitempty"`
+	VehiclesMoved      int64     `json:"vehicles_moved"`
+	ServiceOrdersMoved int64     `json:"service_orders_moved"`
+	PaymentsMoved      int64     `json:"payments_moved"`
+	AppointmentsMoved  int64     `json:"appointments_moved"`
+	MergedBy           string    `json:"merged_by,omitempty"`
+	MergedAt           time.Time `json:"merged_at"`
+}
@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// Discount is a manual discount granted by a service advisor, either on a line item or on the whole order
+type Discount struct {
+	Type   valueobject.DiscountType `json:"type" binding:"omitempty,oneof=PERCENTAGE FIXED"`
+	Value  float64                  `json:"value"`
+	Amount float64                  `json:"amount,omitempty"`
+	Reason string                   `json:"reason,omitempty"`
+}
+
+type Coupon struct {
+	ID           uint                     `json:"id"`
+	Code         string                   `json:"code"`
+	Description  string                   `json:"description,omitempty"`
+	DiscountType valueobject.DiscountType `json:"discount_type"`
+	Value        float64                  `json:"value"`
+	ValidFrom    *time.Time               `json:"valid_from,omitempty"`
+	ValidUntil   *time.Time               `json:"valid_until,omitempty"`
+	MaxUses      int                      `json:"max_uses"`
+	UsedCount    int                      `json:"used_count"`
+	Active       bool                     `json:"active"`
+	CreatedAt    time.Time                `json:"created_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+}
+
+// PriceAgreement is a negotiated unit price for a fleet customer on a Service or a PartsSupply
+type PriceAgreement struct {
+	ID            uint       `json:"id"`
+	CustomerID    uint       `json:"customer_id"`
+	ServiceID     *uint      `json:"service_id,omitempty"`
+	PartsSupplyID *uint      `json:"parts_supply_id,omitempty"`
+	Price         float64    `json:"price"`
+	ValidFrom     *time.Time `json:"valid_from,omitempty"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type EstimateLine struct {
+	ServiceID     uint    `json:"service_id,omitempty"`
+	PartsSupplyID uint    `json:"parts_supply_id,omitempty"`
+	Category      string  `json:"category,omitempty"`
+	Quantity      int     `json:"quantity"`
+	UnitPrice     float64 `json:"unit_price"`
+	Gross         float64 `json:"gross"`
+	Discount      float64 `json:"discount"`
+	Net           float64 `json:"net"`
+}
+
+// EstimateBreakdown details how a service order estimate was priced
+type EstimateBreakdown struct {
+	Lines            []EstimateLine `json:"lines"`
+	Gross            float64        `json:"gross"`
+	LineDiscounts    float64        `json:"line_discounts"`
+	OrderDiscount    float64        `json:"order_discount"`
+	CouponDiscount   float64        `json:"coupon_discount"`
+	CouponID         *uint          `json:"coupon_id,omitempty"`
+	DiscountTotal    float64        `json:"discount_total"`
+	Net              float64        `json:"net"`
+	ApprovalRequired bool           `json:"approval_required"`
+	Taxes            *TaxSummary    `json:"taxes,omitempty"`
+}
+
+// DiscountApproval tracks the admin sign-off required when manual discounts exceed the approval threshold
+type DiscountApproval struct {
+	Pending    bool       `json:"pending"`
+	ApprovedBy string     `json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+}
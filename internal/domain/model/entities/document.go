@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// Document is the layout-agnostic content of a printable document: the use cases fill it
+// with service order data and a DocumentRenderer lays it out
+type Document struct {
+	Type     valueobject.DocumentType
+	Title    string
+	Number   string
+	IssuedAt time.Time
+	Sections []DocumentSection
+	Lines    []DocumentLine
+	Totals   []DocumentField
+	Notes    []string
+}
+
+type DocumentSection struct {
+	Title  string
+	Fields []DocumentField
+}
+
+type DocumentField struct {
+	Label string
+	Value string
+}
+
+type DocumentLine struct {
+	Description string
+	Quantity    int
+	UnitPrice   string
+	Total       string
+}
+
+// RenderedDocument is a document ready to be downloaded
+type RenderedDocument struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
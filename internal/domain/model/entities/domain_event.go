@@ -0,0 +1,105 @@
+package entities
+
+import (
+	"time"
+
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+const (
+	AggregateServiceOrder     = "SERVICE_ORDER"
+	AggregateAdditionalRepair = "ADDITIONAL_REPAIR"
+	AggregatePartsSupply      = "PARTS_SUPPLY"
+)
+
+// DomainEvent is a change to an aggregate. It is written to the outbox in the same transaction as
+// the change and delivered at least once to the subscribers of its type, so handlers must cope
+// with receiving the same event twice.
+type DomainEvent struct {
+	ID            uint                        `json:"id"`
+	Type          valueobject.DomainEventType `json:"type"`
+	AggregateType string                      `json:"aggregate_type"`
+	// AggregateID is filled in by the repository when the event is recorded on a new aggregate
+	AggregateID uint `json:"aggregate_id"`
+	// ServiceOrderID is the order the event concerns, so subscribers can follow a single order
+	ServiceOrderID uint                   `json:"service_order_id,omitempty"`
+	Payload        map[string]interface{} `json:"payload,omitempty"`
+	OccurredAt     time.Time              `json:"occurred_at"`
+}
+
+func NewServiceOrderCreatedEvent(customerID, vehicleID uint) DomainEvent {
+	return DomainEvent{
+		Type:          valueobject.EventServiceOrderCreated,
+		AggregateType: AggregateServiceOrder,
+		Payload: map[string]interface{}{
+			"customer_id": customerID,
+			"vehicle_id":  vehicleID,
+			"status":      valueobject.StatusRecebida.String(),
+		},
+	}
+}
+
+func NewServiceOrderStatusChangedEvent(serviceOrderID uint, from, to valueobject.ServiceOrderStatus) DomainEvent {
+	return DomainEvent{
+		Type:           valueobject.EventServiceOrderStatusChanged,
+		AggregateType:  AggregateServiceOrder,
+		AggregateID:    serviceOrderID,
+		ServiceOrderID: serviceOrderID,
+		Payload: map[string]interface{}{
+			"from": from.String(),
+			"to":   to.String(),
+		},
+	}
+}
+
+func NewPaymentReceivedEvent(serviceOrderID uint, amount float64, method valueobject.PaymentMethod) DomainEvent {
+	return DomainEvent{
+		Type:           valueobject.EventPaymentReceived,
+		AggregateType:  AggregateServiceOrder,
+		AggregateID:    serviceOrderID,
+		ServiceOrderID: serviceOrderID,
+		Payload: map[string]interface{}{
+			"amount": amount,
+			"method": method.String(),
+		},
+	}
+}
+
+// NewAdditionalRepairCreatedEvent is recorded before the additional repair is stored; its ID is
+// filled in by the repository
+func NewAdditionalRepairCreatedEvent(serviceOrderID uint, description string, estimate float64) DomainEvent {
+	return DomainEvent{
+		Type:           valueobject.EventAdditionalRepairCreated,
+		AggregateType:  AggregateAdditionalRepair,
+		ServiceOrderID: serviceOrderID,
+		Payload: map[string]interface{}{
+			"description": description,
+			"estimate":    estimate,
+		},
+	}
+}
+
+func NewAdditionalRepairApprovedEvent(additionalRepairID, serviceOrderID uint, estimate float64) DomainEvent {
+	return DomainEvent{
+		Type:           valueobject.EventAdditionalRepairApproved,
+		AggregateType:  AggregateAdditionalRepair,
+		AggregateID:    additionalRepairID,
+		ServiceOrderID: serviceOrderID,
+		Payload: map[string]interface{}{
+			"estimate": estimate,
+		},
+	}
+}
+
+func NewStockReservedEvent(partsSupplyID, serviceOrderID uint, quantity, quantityReserve int) DomainEvent {
+	return DomainEvent{
+		Type:           valueobject.EventStockReserved,
+		AggregateType:  AggregatePartsSupply,
+		AggregateID:    partsSupplyID,
+		ServiceOrderID: serviceOrderID,
+		Payload: map[string]interface{}{
+			"quantity":         quantity,
+			"quantity_reserve": quantityReserve,
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// PaymentReportLine totals the payments received on a day with a method by an operator
+type PaymentReportLine struct {
+	Date     string                    `json:"date"`
+	Method   valueobject.PaymentMethod `json:"method"`
+	Operator string                    `json:"operator"`
+	Count    int64                     `json:"count"`
+	Amount   float64                   `json:"amount"`
+}
+
+type PaymentReport struct {
+	From     time.Time                             `json:"from"`
+	To       time.Time                             `json:"to"`
+	Lines    []PaymentReportLine                   `json:"lines"`
+	ByMethod map[valueobject.PaymentMethod]float64 `json:"by_method"`
+	Count    int64                                 `json:"count"`
+	Total    float64                               `json:"total"`
+}
+
+// Receivable is a finished service order not fully paid yet
+type Receivable struct {
+	ServiceOrderID uint       `json:"service_order_id"`
+	CustomerID     uint       `json:"customer_id"`
+	CustomerName   string     `json:"customer_name"`
+	VehiclePlate   string     `json:"vehicle_plate"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	Estimate       float64    `json:"estimate"`
+	Paid           float64    `json:"paid"`
+	Outstanding    float64    `json:"outstanding"`
+}
+
+type ReceivablesReport struct {
+	Receivables []Receivable `json:"receivables"`
+	Total       float64      `json:"total"`
+}
+
+// RevenueReport splits the revenue of the paid service orders between services and parts.
+// Orders priced before line items were recorded cannot be split and are reported as unallocated.
+type RevenueReport struct {
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	ServiceOrders int64     `json:"service_orders"`
+	Services      float64   `json:"services"`
+	Parts         float64   `json:"parts"`
+	Unallocated   float64   `json:"unallocated"`
+	Total         float64   `json:"total"`
+}
+
+// CashClosing is the record of a closed cash register day. Payments of a closed day can no longer be edited.
+type CashClosing struct {
+	ID           uint                                  `json:"id"`
+	Date         time.Time                             `json:"date"`
+	ClosedBy     string                                `json:"closed_by"`
+	Notes        string                                `json:"notes,omitempty"`
+	PaymentCount int64                                 `json:"payment_count"`
+	Total        float64                               `json:"total"`
+	ByMethod     map[valueobject.PaymentMethod]float64 `json:"by_method"`
+	Lines        []PaymentReportLine                   `json:"lines"`
+	Payments     []Payment                             `json:"payments,omitempty"`
+	CreatedAt    time.Time                             `json:"created_at"`
+}
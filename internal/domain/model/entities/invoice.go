@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// Invoice is an electronic invoice of a delivered service order:
+// an NFS-e for its services or an NF-e for its parts supplies
+type Invoice struct {
+	ID                   uint                      `json:"id"`
+	ServiceOrderID       uint                      `json:"service_order_id"`
+	Type                 valueobject.InvoiceType   `json:"type"`
+	Status               valueobject.InvoiceStatus `json:"status"`
+	Series               string                    `json:"series"`
+	Number               string                    `json:"number,omitempty"`
+	Protocol             string                    `json:"protocol,omitempty"`
+	CustomerName         string                    `json:"customer_name"`
+	CustomerDocument     valueobject.CpfCnpj       `json:"customer_document"`
+	Items                []InvoiceItem             `json:"items"`
+	Total                float64                   `json:"total"`
+	TaxTotal             float64                   `json:"tax_total"`
+	XML                  string                    `json:"-"`
+	RejectionReason      string                    `json:"rejection_reason,omitempty"`
+	IssuedAt             *time.Time                `json:"issued_at,omitempty"`
+	CancellationReason   string                    `json:"cancellation_reason,omitempty"`
+	CancellationProtocol string                    `json:"cancellation_protocol,omitempty"`
+	CancelledAt          *time.Time                `json:"cancelled_at,omitempty"`
+	CreatedAt            time.Time                 `json:"created_at"`
+}
+
+type InvoiceItem struct {
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	Total       float64 `json:"total"`
+	TaxRate     float64 `json:"tax_rate"`
+	TaxAmount   float64 `json:"tax_amount"`
+}
+
+// InvoiceIssuer identifies the workshop issuing the invoices
+type InvoiceIssuer struct {
+	CNPJ                  string
+	Name                  string
+	MunicipalRegistration string
+	StateRegistration     string
+	CityCode              string
+	State                 string
+}
+
+// InvoiceSubmission is the answer of the tax authority to a submitted invoice
+type InvoiceSubmission struct {
+	Accepted        bool
+	Number          string
+	Protocol        string
+	IssuedAt        time.Time
+	RejectionReason string
+}
+
+// InvoiceCancellation is the answer of the tax authority to a cancellation request
+type InvoiceCancellation struct {
+	Protocol    string
+	CancelledAt time.Time
+}
@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// MaintenancePlan is how often a service of the catalog should be repeated, by mileage, by time or
+// by whichever comes first when both are set
+type MaintenancePlan struct {
+	ID             uint      `json:"id"`
+	ServiceID      uint      `json:"service_id"`
+	Service        string    `json:"service,omitempty"`
+	IntervalKm     int       `json:"interval_km,omitempty"`
+	IntervalMonths int       `json:"interval_months,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type MaintenancePlanRequest struct {
+	IntervalKm     int `json:"interval_km"`
+	IntervalMonths int `json:"interval_months"`
+}
+
+// VehicleMaintenance is when each planned maintenance of a vehicle is next due
+type VehicleMaintenance struct {
+	Vehicle Vehicle `json:"vehicle"`
+	// CurrentOdometer is the last reading taken at a check-in, since the workshop only sees the
+	// odometer when the vehicle comes in
+	CurrentOdometer *int             `json:"current_odometer,omitempty"`
+	Items           []MaintenanceDue `json:"items"`
+}
+
+type MaintenanceDue struct {
+	PlanID         uint   `json:"plan_id"`
+	ServiceID      uint   `json:"service_id"`
+	Service        string `json:"service"`
+	IntervalKm     int    `json:"interval_km,omitempty"`
+	IntervalMonths int    `json:"interval_months,omitempty"`
+	// LastPerformed is the last finished or delivered order with the service
+	LastPerformed   *MaintenanceRecord            `json:"last_performed,omitempty"`
+	NextDueDate     *time.Time                    `json:"next_due_date,omitempty"`
+	NextDueOdometer *int                          `json:"next_due_odometer,omitempty"`
+	Status          valueobject.MaintenanceStatus `json:"status"`
+}
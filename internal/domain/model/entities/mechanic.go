@@ -0,0 +1,86 @@
+package entities
+
+import "time"
+
+// Mechanic is an employee who works on the services of the service orders
+type Mechanic struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name" binding:"required"`
+	Email       string    `json:"email,omitempty"`
+	PhoneNumber string    `json:"phone_number,omitempty"`
+	Specialty   string    `json:"specialty,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MechanicAssignment puts a mechanic on a service order, either on the whole order or on one of its services
+type MechanicAssignment struct {
+	ID             uint      `json:"id"`
+	ServiceOrderID uint      `json:"service_order_id"`
+	MechanicID     uint      `json:"mechanic_id" binding:"required"`
+	Mechanic       *Mechanic `json:"mechanic,omitempty"`
+	// ServiceID limits the assignment to one service of the order; empty means every service
+	ServiceID  *uint     `json:"service_id,omitempty"`
+	AssignedBy string    `json:"assigned_by"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+// LabourEntry is the time a mechanic spent on a service of a service order, from clock-in to clock-out
+type LabourEntry struct {
+	ID             uint       `json:"id"`
+	ServiceOrderID uint       `json:"service_order_id"`
+	ServiceID      uint       `json:"service_id"`
+	MechanicID     uint       `json:"mechanic_id"`
+	ClockIn        time.Time  `json:"clock_in"`
+	ClockOut       *time.Time `json:"clock_out,omitempty"`
+	// Minutes is filled in on clock-out
+	Minutes int `json:"minutes"`
+}
+
+// ClockRequest is the body of a clock-in or clock-out on a service of a service order
+type ClockRequest struct {
+	MechanicID uint `json:"mechanic_id" binding:"required"`
+}
+
+// LabourTask compares the standard time of a service of a service order with the time actually spent on it
+type LabourTask struct {
+	ServiceID       uint   `json:"service_id"`
+	ServiceName     string `json:"service_name"`
+	StandardMinutes int    `json:"standard_minutes"`
+	ActualMinutes   int    `json:"actual_minutes"`
+	// VarianceMinutes is the actual minus the standard time; positive means the service took longer
+	VarianceMinutes int `json:"variance_minutes"`
+	// InProgress tells whether a mechanic is still clocked in on the service
+	InProgress bool `json:"in_progress"`
+}
+
+// ServiceOrderLabour is who works on a service order and the time spent on each of its services
+type ServiceOrderLabour struct {
+	ServiceOrderID uint                 `json:"service_order_id"`
+	Assignments    []MechanicAssignment `json:"assignments"`
+	Tasks          []LabourTask         `json:"tasks"`
+	Entries        []LabourEntry        `json:"entries"`
+}
+
+// MechanicProductivity sums the work of a mechanic within a period. Efficiency is the standard time
+// credited to the mechanic over the time spent on services that have a standard time, in percent.
+type MechanicProductivity struct {
+	MechanicID    uint   `json:"mechanic_id"`
+	MechanicName  string `json:"mechanic_name"`
+	ServiceOrders int    `json:"service_orders"`
+	Tasks         int    `json:"tasks"`
+	WorkedMinutes int    `json:"worked_minutes"`
+	// MeasuredMinutes is the part of WorkedMinutes spent on services with a standard time
+	MeasuredMinutes int `json:"measured_minutes"`
+	// StandardMinutes is the standard time credited, split between the mechanics of a service by the time each spent on it
+	StandardMinutes float64 `json:"standard_minutes"`
+	Efficiency      float64 `json:"efficiency"`
+}
+
+// ProductivityReport is the productivity of every mechanic who clocked time within a period
+type ProductivityReport struct {
+	From      time.Time              `json:"from"`
+	To        time.Time              `json:"to"`
+	Mechanics []MechanicProductivity `json:"mechanics"`
+}
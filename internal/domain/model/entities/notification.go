@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// events customers are notified about
+const (
+	NotificationServiceOrderStatusChanged = "SERVICE_ORDER_STATUS_CHANGED"
+	NotificationAdditionalRepairCreated   = "ADDITIONAL_REPAIR_CREATED"
+)
+
+// NotificationEvent is something that happened to a service order the customer should hear about
+type NotificationEvent struct {
+	// EventID is the domain event the notification comes from, if any
+	EventID        *uint
+	Type           string
+	ServiceOrderID uint
+	// Status is the new status of the service order on SERVICE_ORDER_STATUS_CHANGED
+	Status valueobject.ServiceOrderStatus
+	// AdditionalRepairID, Description and Estimate describe the additional repair on ADDITIONAL_REPAIR_CREATED
+	AdditionalRepairID uint
+	Description        string
+	Estimate           float64
+}
+
+// NotificationMessage is a message ready to be sent through one channel
+type NotificationMessage struct {
+	Channel   valueobject.NotificationChannel
+	Recipient string
+	Subject   string
+	Body      string
+}
+
+// NotificationDelivery is the delivery log of a message, retried until it is sent or gives up
+type NotificationDelivery struct {
+	ID             uint                            `json:"id"`
+	ServiceOrderID uint                            `json:"service_order_id"`
+	Event          string                          `json:"event"`
+	Channel        valueobject.NotificationChannel `json:"channel"`
+	Recipient      string                          `json:"recipient"`
+	Subject        string                          `json:"subject,omitempty"`
+	Body           string                          `json:"body"`
+	Status         valueobject.NotificationStatus  `json:"status"`
+	Attempts       int                             `json:"attempts"`
+	LastError      string                          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time                      `json:"next_attempt_at,omitempty"`
+	SentAt         *time.Time                      `json:"sent_at,omitempty"`
+	CreatedAt      time.Time                       `json:"created_at"`
+}
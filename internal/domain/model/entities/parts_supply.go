@@ -9,6 +9,7 @@ type PartsSupply struct {
 	Name              string             `json:"name"`
 	Description       string             `json:"description"`
 	Price             float64            `json:"price"`
+	Category          string             `json:"category,omitempty"`
 	QuantityTotal     int                `json:"quantity_total"`
 	QuantityReserve   int                `json:"quantity_reserve"`
 	CreatedAt         time.Time          `json:"created_at"`
@@ -16,4 +17,7 @@ type PartsSupply struct {
 	DeletedAt         *time.Time         `json:"deleted_at,omitempty"`
 	AdditionalRepairs []AdditionalRepair `json:"additional_repairs,omitempty"`
 	ServiceOrders     []ServiceOrder     `json:"service_orders,omitempty"`
+	Discount          *Discount          `json:"discount,omitempty"`
+	// Events are written to the outbox with the parts supply when it is stored
+	Events []DomainEvent `json:"-"`
 }
@@ -1,13 +1,23 @@
 package entities
 
 import (
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"time"
 )
 
 type Payment struct {
-	ID             uint          `json:"id"`
-	ServiceOrderID uint          `json:"service_order_id"`
-	ServiceOrder   *ServiceOrder `json:"service_order,omitempty"`
-	PaymentDate    time.Time     `json:"payment_date"`
-	Amount         float64       `json:"amount"`
+	ID             uint                      `json:"id"`
+	ServiceOrderID uint                      `json:"service_order_id"`
+	ServiceOrder   *ServiceOrder             `json:"service_order,omitempty"`
+	PaymentDate    time.Time                 `json:"payment_date"`
+	Amount         float64                   `json:"amount"`
+	Method         valueobject.PaymentMethod `json:"method"`
+	Operator       string                    `json:"operator,omitempty"`
+	ISSAmount      float64                   `json:"iss_amount"`
+	ICMSAmount     float64                   `json:"icms_amount"`
+	TaxTotal       float64                   `json:"tax_total"`
+	// CashClosingID is set once the cash register of the payment day is closed, locking the payment
+	CashClosingID *uint `json:"cash_closing_id,omitempty"`
+	// Events are written to the outbox with the payment when it is stored
+	Events []DomainEvent `json:"-"`
 }
@@ -9,9 +9,12 @@ type Service struct {
 	Name              string             `json:"name"`
 	Description       string             `json:"description"`
 	Price             float64            `json:"price"`
+	Category          string             `json:"category,omitempty"`
+	StandardMinutes   int                `json:"standard_minutes,omitempty"`
 	CreatedAt         time.Time          `json:"created_at"`
 	UpdatedAt         time.Time          `json:"updated_at"`
 	DeletedAt         *time.Time         `json:"deleted_at,omitempty"`
 	AdditionalRepairs []AdditionalRepair `json:"additional_repairs,omitempty"`
 	ServiceOrders     []ServiceOrder     `json:"service_orders,omitempty"`
+	Discount          *Discount          `json:"discount,omitempty"`
 }
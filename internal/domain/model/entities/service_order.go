@@ -13,12 +13,30 @@ type ServiceOrder struct {
 	Vehicle              *Vehicle                       `json:"vehicle,omitempty"`
 	ServiceOrderStatus   valueobject.ServiceOrderStatus `json:"service_order_status"`
 	Estimate             float64                        `json:"estimate,omitempty"`
+	GrossEstimate        float64                        `json:"gross_estimate,omitempty"`
+	DiscountTotal        float64                        `json:"discount_total,omitempty"`
+	Discount             *Discount                      `json:"discount,omitempty"`
+	CouponCode           string                         `json:"coupon_code,omitempty"`
+	DiscountApproval     *DiscountApproval              `json:"discount_approval,omitempty"`
+	ISSTotal             float64                        `json:"iss_total,omitempty"`
+	ICMSTotal            float64                        `json:"icms_total,omitempty"`
+	TaxTotal             float64                        `json:"tax_total,omitempty"`
+	Taxes                []TaxLine                      `json:"taxes,omitempty"`
 	StartedExecutionDate *time.Time                     `json:"started_execution_date,omitempty"`
 	FinalExecutionDate   *time.Time                     `json:"final_execution_date,omitempty"`
+	DeliveredAt          *time.Time                     `json:"delivered_at,omitempty"`
+	ApproverContactID    *uint                          `json:"approver_contact_id,omitempty"`
 	CreatedAt            *time.Time                     `json:"created_at,omitempty"`
 	UpdatedAt            *time.Time                     `json:"updated_at,omitempty"`
 	Payment              *Payment                       `json:"payment,omitempty"`
 	AdditionalRepairs    []AdditionalRepair             `json:"additional_repairs,omitempty"`
 	PartsSupplies        []PartsSupply                  `json:"parts_supplies,omitempty"`
 	Services             []Service                      `json:"services,omitempty"`
+	// Pricing is stored with the order when the estimate was priced, redeeming its coupon
+	Pricing *EstimateBreakdown `json:"-"`
+	// ReleaseCoupon gives the use of the order's coupon back when it is stored, as rejected and
+	// cancelled orders are never paid
+	ReleaseCoupon bool `json:"-"`
+	// Events are written to the outbox with the order when it is stored
+	Events []DomainEvent `json:"-"`
 }
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"time"
+
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+// ServiceOrderUpdate is a change to a service order pushed to the clients following it live
+type ServiceOrderUpdate struct {
+	EventID        uint                        `json:"event_id"`
+	Type           valueobject.DomainEventType `json:"type"`
+	ServiceOrderID uint                        `json:"service_order_id"`
+	CustomerID     uint                        `json:"customer_id"`
+	// Status is the status of the service order when the update is sent
+	Status     string                 `json:"status"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	// CustomerUserID is the user of the customer, so customers only receive their own updates
+	CustomerUserID uint `json:"-"`
+}
+
+// ServiceOrderUpdateFilter selects the updates a client follows; zero values match every order
+type ServiceOrderUpdateFilter struct {
+	ServiceOrderID uint
+	CustomerID     uint
+}
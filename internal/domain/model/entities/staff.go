@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// Staff is an employee of the workshop, who logs in with their user and is authorized by its role
+type Staff struct {
+	ID          uint                     `json:"id"`
+	UserID      uint                     `json:"user_id"`
+	Email       string                   `json:"email"`
+	FullName    string                   `json:"full_name"`
+	PhoneNumber string                   `json:"phone_number,omitempty"`
+	Role        valueobject.UserType     `json:"role"`
+	Permissions []valueobject.Permission `json:"permissions"`
+	CreatedAt   time.Time                `json:"created_at"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+}
+
+// StaffInput creates or updates an employee. On update, an empty password keeps the current one.
+type StaffInput struct {
+	Email       string               `json:"email" binding:"required"`
+	Password    string               `json:"password,omitempty"`
+	FullName    string               `json:"full_name" binding:"required"`
+	PhoneNumber string               `json:"phone_number,omitempty"`
+	Role        valueobject.UserType `json:"role" binding:"required"`
+}
+
+// RolePermissions is a role that can be given to an employee and what it allows them to do
+type RolePermissions struct {
+	Role        valueobject.UserType     `json:"role"`
+	Permissions []valueobject.Permission `json:"permissions"`
+}
@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// TaxRule is the rate of a tax for a Service or PartsSupply category.
+// A rule with an empty category is the fallback rate of its tax type.
+type TaxRule struct {
+	ID          uint                `json:"id"`
+	TaxType     valueobject.TaxType `json:"tax_type" binding:"required,oneof=ISS ICMS"`
+	Category    string              `json:"category"`
+	Rate        float64             `json:"rate" binding:"gte=0,lte=100"`
+	Description string              `json:"description,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// TaxLine is the tax levied on one line of a service order
+type TaxLine struct {
+	TaxType       valueobject.TaxType `json:"tax_type"`
+	Category      string              `json:"category,omitempty"`
+	ServiceID     uint                `json:"service_id,omitempty"`
+	PartsSupplyID uint                `json:"parts_supply_id,omitempty"`
+	Base          float64             `json:"base"`
+	Rate          float64             `json:"rate"`
+	Amount        float64             `json:"amount"`
+}
+
+// TaxSummary holds the tax lines of a service order and their totals.
+// Taxes are included in the prices, so they are broken out but never added to the estimate.
+type TaxSummary struct {
+	Lines     []TaxLine `json:"lines"`
+	ISSTotal  float64   `json:"iss_total"`
+	ICMSTotal float64   `json:"icms_total"`
+	TaxTotal  float64   `json:"tax_total"`
+}
+
+type TaxReportLine struct {
+	TaxType  valueobject.TaxType `json:"tax_type"`
+	Category string              `json:"category"`
+	Base     float64             `json:"base"`
+	Amount   float64             `json:"amount"`
+}
+
+// TaxReport summarizes the taxes of the service orders paid within a period
+type TaxReport struct {
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	ServiceOrders int64           `json:"service_orders"`
+	Revenue       float64         `json:"revenue"`
+	ISSTotal      float64         `json:"iss_total"`
+	ICMSTotal     float64         `json:"icms_total"`
+	TaxTotal      float64         `json:"tax_total"`
+	Categories    []TaxReportLine `json:"categories"`
+}
@@ -13,6 +13,7 @@ type Vehicle struct {
 	Model      string            `json:"model"`
 	Year       string            `json:"year"`
 	Brand      string            `json:"brand"`
+	VIN        valueobject.VIN   `json:"vin,omitempty"`
 	CreatedAt  time.Time         `json:"created_at"`
 	UpdatedAt  *time.Time        `json:"updated_at,"`
 	DeletedAt  *time.Time        `json:"deleted_at,omitempty"`
@@ -0,0 +1,22 @@
+package entities
+
+// VehicleModel is a model year of the vehicle catalog, as listed by the FIPE table
+type VehicleModel struct {
+	ID       uint   `json:"id"`
+	FipeCode string `json:"fipe_code,omitempty"`
+	Brand    string `json:"brand"`
+	Model    string `json:"model"`
+	Year     int    `json:"year"`
+}
+
+// VehicleCatalogImport is the outcome of importing a FIPE dump into the catalog
+type VehicleCatalogImport struct {
+	Imported int                         `json:"imported"`
+	Skipped  int                         `json:"skipped"`
+	Errors   []VehicleCatalogImportError `json:"errors,omitempty"`
+}
+
+type VehicleCatalogImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+)
+
+// VehicleHistory is what the workshop did to a vehicle, so an advisor can see it before a new visit
+type VehicleHistory struct {
+	Vehicle Vehicle                   `json:"vehicle"`
+	Summary VehicleMaintenanceSummary `json:"summary"`
+	// Visits are the service orders of the vehicle, oldest first
+	Visits []VehicleVisit `json:"visits"`
+	// OdometerTimeline are the readings taken at the check-in of the visits, oldest first
+	OdometerTimeline []OdometerReading `json:"odometer_timeline"`
+}
+
+// VehicleVisit is a service order of the vehicle with the services performed and the parts replaced,
+// including those of its approved additional repairs
+type VehicleVisit struct {
+	ServiceOrderID uint                           `json:"service_order_id"`
+	Status         valueobject.ServiceOrderStatus `json:"status"`
+	ReceivedAt     time.Time                      `json:"received_at"`
+	CompletedAt    *time.Time                     `json:"completed_at,omitempty"`
+	Odometer       *int                           `json:"odometer,omitempty"`
+	Services       []VisitService                 `json:"services"`
+	Parts          []VisitPart                    `json:"parts"`
+	Total          float64                        `json:"total"`
+}
+
+type VisitService struct {
+	ServiceID uint   `json:"service_id"`
+	Name      string `json:"name"`
+	Category  string `json:"category,omitempty"`
+	// AdditionalRepairID is set when the service was added by an additional repair
+	AdditionalRepairID *uint `json:"additional_repair_id,omitempty"`
+}
+
+type VisitPart struct {
+	PartsSupplyID      uint   `json:"parts_supply_id"`
+	Name               string `json:"name"`
+	Quantity           int    `json:"quantity"`
+	AdditionalRepairID *uint  `json:"additional_repair_id,omitempty"`
+}
+
+type OdometerReading struct {
+	ServiceOrderID uint      `json:"service_order_id"`
+	Date           time.Time `json:"date"`
+	Odometer       int       `json:"odometer"`
+}
+
+// VehicleMaintenanceSummary highlights the last visit and the last time the usual maintenance was
+// done. Only finished or delivered orders count as work done.
+type VehicleMaintenanceSummary struct {
+	TotalVisits      int                `json:"total_visits"`
+	LastVisitAt      *time.Time         `json:"last_visit_at,omitempty"`
+	LastOdometer     *int               `json:"last_odometer,omitempty"`
+	LastOilChange    *MaintenanceRecord `json:"last_oil_change,omitempty"`
+	LastBrakeService *MaintenanceRecord `json:"last_brake_service,omitempty"`
+}
+
+// MaintenanceRecord is a service performed on the vehicle
+type MaintenanceRecord struct {
+	ServiceOrderID uint      `json:"service_order_id"`
+	Date           time.Time `json:"date"`
+	Odometer       *int      `json:"odometer,omitempty"`
+	Service        string    `json:"service"`
+}
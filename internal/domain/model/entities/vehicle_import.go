@@ -0,0 +1,17 @@
+package entities
+
+// VehicleImport is the outcome of importing a file of vehicles. The vehicles are only registered
+// when every line is valid, and never on a dry run.
+type VehicleImport struct {
+	DryRun   bool                 `json:"dry_run"`
+	Total    int                  `json:"total"`
+	Valid    int                  `json:"valid"`
+	Imported int                  `json:"imported"`
+	Errors   []VehicleImportError `json:"errors,omitempty"`
+}
+
+type VehicleImportError struct {
+	Line    int    `json:"line"`
+	Plate   string `json:"plate,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// VehicleOwnership is a period a customer owned a vehicle; the current owner has no EndedAt
+type VehicleOwnership struct {
+	ID           uint       `json:"id"`
+	VehicleID    uint       `json:"vehicle_id"`
+	CustomerID   uint       `json:"customer_id"`
+	CustomerName string     `json:"customer_name,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	// TransferredBy is the staff member who transferred the vehicle to the customer
+	TransferredBy string `json:"transferred_by,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+}
+
+type VehicleTransferRequest struct {
+	CustomerID uint   `json:"customer_id" binding:"required"`
+	Notes      string `json:"notes"`
+}
@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+// WebhookSubscription is a partner or fleet client endpoint that is pushed service order and payment events
+type WebhookSubscription struct {
+	ID  uint   `json:"id"`
+	URL string `json:"url" binding:"required"`
+	// Secret signs the deliveries. It is generated when not informed and only returned on creation.
+	Secret string `json:"secret,omitempty"`
+	// EventTypes lists the events sent to the subscription; empty means all of them
+	EventTypes []valueobject.DomainEventType `json:"event_types"`
+	// CustomerID limits the subscription to the service orders of one customer
+	CustomerID *uint     `json:"customer_id,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent to a subscription, with the outcome of its attempts
+type WebhookDelivery struct {
+	ID             uint                              `json:"id"`
+	SubscriptionID uint                              `json:"subscription_id"`
+	EventID        uint                              `json:"event_id"`
+	EventType      valueobject.DomainEventType       `json:"event_type"`
+	Payload        string                            `json:"payload"`
+	Status         valueobject.WebhookDeliveryStatus `json:"status"`
+	Attempts       int                               `json:"attempts"`
+	ResponseStatus int                               `json:"response_status,omitempty"`
+	LastError      string                            `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time                        `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time                        `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time                         `json:"created_at"`
+}
+
+// WebhookPayload is the JSON body posted to the subscriptions
+type WebhookPayload struct {
+	EventID        uint                        `json:"event_id"`
+	Type           valueobject.DomainEventType `json:"type"`
+	OccurredAt     time.Time                   `json:"occurred_at"`
+	ServiceOrderID uint                        `json:"service_order_id"`
+	CustomerID     uint                        `json:"customer_id"`
+	VehicleID      uint                        `json:"vehicle_id"`
+	Data           map[string]interface{}      `json:"data,omitempty"`
+}
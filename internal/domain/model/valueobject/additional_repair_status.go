@@ -24,6 +24,33 @@ func ParseAdditionalRepairStatus(status string) AdditionalRepairStatus {
 	}
 }
 
+func (s AdditionalRepairStatus) IsValid() bool {
+	switch s {
+	case StatusARAberta, StatusARAguardandoAprovacao, StatusAAprovada, StatusARRejeitada:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s AdditionalRepairStatus) IsAberta() bool {
+	return s == StatusARAberta
+}
+func (s AdditionalRepairStatus) IsAguardandoAprovacao() bool {
+	return s == StatusARAguardandoAprovacao
+}
+func (s AdditionalRepairStatus) IsAprovada() bool {
+	return s == StatusAAprovada
+}
+func (s AdditionalRepairStatus) IsRejeitada() bool {
+	return s == StatusARRejeitada
+}
+
+// IsPending tells whether the additional repair still waits for a customer decision
+func (s AdditionalRepairStatus) IsPending() bool {
+	return s.IsAberta() || s.IsAguardandoAprovacao()
+}
+
 func (s AdditionalRepairStatus) String() string {
 	return string(s)
 }
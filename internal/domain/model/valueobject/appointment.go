@@ -0,0 +1,31 @@
+package valueobject
+
+// AppointmentStatus is the state of a visit booked by a customer
+type AppointmentStatus string
+
+const (
+	AppointmentScheduled AppointmentStatus = "AGENDADO"
+	AppointmentConverted AppointmentStatus = "CONVERTIDO"
+	AppointmentCancelled AppointmentStatus = "CANCELADO"
+)
+
+func ParseAppointmentStatus(value string) AppointmentStatus {
+	switch value {
+	case "AGENDADO":
+		return AppointmentScheduled
+	case "CONVERTIDO":
+		return AppointmentConverted
+	case "CANCELADO":
+		return AppointmentCancelled
+	default:
+		return AppointmentStatus(value)
+	}
+}
+
+func (s AppointmentStatus) IsValid() bool {
+	return s == AppointmentScheduled || s == AppointmentConverted || s == AppointmentCancelled
+}
+
+func (s AppointmentStatus) String() string {
+	return string(s)
+}
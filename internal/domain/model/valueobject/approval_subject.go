@@ -0,0 +1,41 @@
+package valueobject
+
+// ApprovalSubject is what a customer approval link decides on
+type ApprovalSubject string
+
+const (
+	ApprovalSubjectEstimate         ApprovalSubject = "ESTIMATE"
+	ApprovalSubjectAdditionalRepair ApprovalSubject = "ADDITIONAL_REPAIR"
+)
+
+func ParseApprovalSubject(subject string) ApprovalSubject {
+	switch subject {
+	case "ESTIMATE":
+		return ApprovalSubjectEstimate
+	case "ADDITIONAL_REPAIR":
+		return ApprovalSubjectAdditionalRepair
+	default:
+		return ApprovalSubject(subject)
+	}
+}
+
+func (s ApprovalSubject) IsValid() bool {
+	switch s {
+	case ApprovalSubjectEstimate, ApprovalSubjectAdditionalRepair:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s ApprovalSubject) IsEstimate() bool {
+	return s == ApprovalSubjectEstimate
+}
+
+func (s ApprovalSubject) IsAdditionalRepair() bool {
+	return s == ApprovalSubjectAdditionalRepair
+}
+
+func (s ApprovalSubject) String() string {
+	return string(s)
+}
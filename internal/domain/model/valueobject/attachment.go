@@ -0,0 +1,21 @@
+package valueobject
+
+// AttachmentOwner is the kind of record a file is attached to
+type AttachmentOwner string
+
+const (
+	AttachmentOwnerServiceOrder     AttachmentOwner = "SERVICE_ORDER"
+	AttachmentOwnerAdditionalRepair AttachmentOwner = "ADDITIONAL_REPAIR"
+)
+
+func ParseAttachmentOwner(value string) AttachmentOwner {
+	return AttachmentOwner(value)
+}
+
+func (o AttachmentOwner) IsValid() bool {
+	return o == AttachmentOwnerServiceOrder || o == AttachmentOwnerAdditionalRepair
+}
+
+func (o AttachmentOwner) String() string {
+	return string(o)
+}
@@ -0,0 +1,50 @@
+package valueobject
+
+// FuelLevel is the reading of the fuel gauge when the vehicle is received
+type FuelLevel string
+
+const (
+	FuelReserve      FuelLevel = "RESERVA"
+	FuelQuarter      FuelLevel = "1/4"
+	FuelHalf         FuelLevel = "1/2"
+	FuelThreeQuarter FuelLevel = "3/4"
+	FuelFull         FuelLevel = "CHEIO"
+)
+
+func ParseFuelLevel(value string) FuelLevel {
+	return FuelLevel(value)
+}
+
+func (f FuelLevel) IsValid() bool {
+	switch f {
+	case FuelReserve, FuelQuarter, FuelHalf, FuelThreeQuarter, FuelFull:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f FuelLevel) String() string {
+	return string(f)
+}
+
+// ChecklistCondition is the state an item of the check-in checklist was found in
+type ChecklistCondition string
+
+const (
+	ConditionOK      ChecklistCondition = "OK"
+	ConditionDamaged ChecklistCondition = "AVARIA"
+	ConditionMissing ChecklistCondition = "AUSENTE"
+)
+
+func ParseChecklistCondition(value string) ChecklistCondition {
+	return ChecklistCondition(value)
+}
+
+func (c ChecklistCondition) IsValid() bool {
+	return c == ConditionOK || c == ConditionDamaged || c == ConditionMissing
+}
+
+func (c ChecklistCondition) String() string {
+	return string(c)
+}
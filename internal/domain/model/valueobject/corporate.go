@@ -0,0 +1,28 @@
+package valueobject
+
+// CorporateContactRole is what a contact does for the company owning a corporate account
+type CorporateContactRole string
+
+const (
+	CorporateRolePurchasing CorporateContactRole = "COMPRAS"
+	CorporateRoleFinance    CorporateContactRole = "FINANCEIRO"
+	CorporateRoleFleet      CorporateContactRole = "GESTOR_FROTA"
+	CorporateRoleDriver     CorporateContactRole = "MOTORISTA"
+)
+
+func ParseCorporateContactRole(value string) CorporateContactRole {
+	return CorporateContactRole(value)
+}
+
+func (r CorporateContactRole) IsValid() bool {
+	switch r {
+	case CorporateRolePurchasing, CorporateRoleFinance, CorporateRoleFleet, CorporateRoleDriver:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r CorporateContactRole) String() string {
+	return string(r)
+}
@@ -40,6 +40,16 @@ func (c CpfCnpj) IsValid() error {
 	return validators.CnpjIsValid(c.String())
 }
 
+// IsCNPJ tells whether the document is that of a company
+func (c CpfCnpj) IsCNPJ() bool {
+	return len(clean(c.String())) == 14
+}
+
+// Normalized returns the document without its mask, the way it is stored
+func (c CpfCnpj) Normalized() CpfCnpj {
+	return CpfCnpj(clean(c.String()))
+}
+
 func (c CpfCnpj) Mask() string {
 	if len(c) == 11 {
 		return utils.MaskCPF(c.String())
@@ -0,0 +1,27 @@
+package valueobject
+
+// CustomerDuplicateReason is what makes two customers look like the same person or company
+type CustomerDuplicateReason string
+
+const (
+	DuplicateByDocument CustomerDuplicateReason = "DOCUMENT"
+	DuplicateByPhone    CustomerDuplicateReason = "PHONE"
+	DuplicateByName     CustomerDuplicateReason = "NAME"
+)
+
+func ParseCustomerDuplicateReason(value string) CustomerDuplicateReason {
+	return CustomerDuplicateReason(value)
+}
+
+func (r CustomerDuplicateReason) IsValid() bool {
+	switch r {
+	case DuplicateByDocument, DuplicateByPhone, DuplicateByName:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r CustomerDuplicateReason) String() string {
+	return string(r)
+}
@@ -0,0 +1,50 @@
+package valueobject
+
+import "math"
+
+type DiscountType string
+
+const (
+	DiscountPercentage DiscountType = "PERCENTAGE"
+	DiscountFixed      DiscountType = "FIXED"
+)
+
+func ParseDiscountType(value string) DiscountType {
+	switch value {
+	case "PERCENTAGE":
+		return DiscountPercentage
+	case "FIXED":
+		return DiscountFixed
+	default:
+		return DiscountType(value)
+	}
+}
+
+func (d DiscountType) IsValid() bool {
+	switch d {
+	case DiscountPercentage, DiscountFixed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Amount returns how much should be discounted from base, never exceeding base
+func (d DiscountType) Amount(base, value float64) float64 {
+	if base <= 0 || value <= 0 {
+		return 0
+	}
+	var amount float64
+	switch d {
+	case DiscountPercentage:
+		amount = base * value / 100
+	case DiscountFixed:
+		amount = value
+	}
+	amount = math.Round(amount*100) / 100
+	return math.Min(amount, base)
+}
+
+func (d DiscountType) String() string {
+	return string(d)
+}
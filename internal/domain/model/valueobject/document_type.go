@@ -0,0 +1,36 @@
+package valueobject
+
+// DocumentType is a printable document of a service order
+type DocumentType string
+
+const (
+	DocumentEstimate     DocumentType = "estimate"
+	DocumentServiceOrder DocumentType = "service-order"
+	DocumentReceipt      DocumentType = "receipt"
+)
+
+func ParseDocumentType(documentType string) DocumentType {
+	switch documentType {
+	case "estimate":
+		return DocumentEstimate
+	case "service-order":
+		return DocumentServiceOrder
+	case "receipt":
+		return DocumentReceipt
+	default:
+		return DocumentType(documentType)
+	}
+}
+
+func (d DocumentType) IsValid() bool {
+	switch d {
+	case DocumentEstimate, DocumentServiceOrder, DocumentReceipt:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d DocumentType) String() string {
+	return string(d)
+}
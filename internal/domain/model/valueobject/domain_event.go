@@ -0,0 +1,40 @@
+package valueobject
+
+// DomainEventType names something that happened in the workshop that other parts of the system react to
+type DomainEventType string
+
+const (
+	EventServiceOrderCreated       DomainEventType = "SERVICE_ORDER_CREATED"
+	EventServiceOrderStatusChanged DomainEventType = "SERVICE_ORDER_STATUS_CHANGED"
+	EventPaymentReceived           DomainEventType = "PAYMENT_RECEIVED"
+	EventAdditionalRepairCreated   DomainEventType = "ADDITIONAL_REPAIR_CREATED"
+	EventAdditionalRepairApproved  DomainEventType = "ADDITIONAL_REPAIR_APPROVED"
+	EventStockReserved             DomainEventType = "STOCK_RESERVED"
+)
+
+// DomainEventTypes lists every event type, in the order they are documented
+var DomainEventTypes = []DomainEventType{
+	EventServiceOrderCreated,
+	EventServiceOrderStatusChanged,
+	EventPaymentReceived,
+	EventAdditionalRepairCreated,
+	EventAdditionalRepairApproved,
+	EventStockReserved,
+}
+
+func ParseDomainEventType(value string) DomainEventType {
+	return DomainEventType(value)
+}
+
+func (t DomainEventType) IsValid() bool {
+	for _, eventType := range DomainEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (t DomainEventType) String() string {
+	return string(t)
+}
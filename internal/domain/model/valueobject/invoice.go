@@ -0,0 +1,65 @@
+package valueobject
+
+// InvoiceType is the kind of electronic invoice: NFS-e for services and NF-e for parts
+type InvoiceType string
+
+const (
+	InvoiceNFSe InvoiceType = "NFSE"
+	InvoiceNFe  InvoiceType = "NFE"
+)
+
+func ParseInvoiceType(value string) InvoiceType {
+	switch value {
+	case "NFSE":
+		return InvoiceNFSe
+	case "NFE":
+		return InvoiceNFe
+	default:
+		return InvoiceType(value)
+	}
+}
+
+func (t InvoiceType) IsValid() bool {
+	return t == InvoiceNFSe || t == InvoiceNFe
+}
+
+func (t InvoiceType) String() string {
+	return string(t)
+}
+
+type InvoiceStatus string
+
+const (
+	InvoicePending   InvoiceStatus = "PENDENTE"
+	InvoiceIssued    InvoiceStatus = "EMITIDA"
+	InvoiceRejected  InvoiceStatus = "REJEITADA"
+	InvoiceCancelled InvoiceStatus = "CANCELADA"
+)
+
+func ParseInvoiceStatus(value string) InvoiceStatus {
+	switch value {
+	case "PENDENTE":
+		return InvoicePending
+	case "EMITIDA":
+		return InvoiceIssued
+	case "REJEITADA":
+		return InvoiceRejected
+	case "CANCELADA":
+		return InvoiceCancelled
+	default:
+		return InvoiceStatus(value)
+	}
+}
+
+func (s InvoiceStatus) IsIssued() bool {
+	return s == InvoiceIssued
+}
+
+// IsActive tells whether the invoice still counts as the order's invoice, blocking a new issuance
+func (s InvoiceStatus) IsActive() bool {
+	return s == InvoicePending || s == InvoiceIssued
+}
+
+func (s InvoiceStatus) String() string {
+	return string(s)
+}
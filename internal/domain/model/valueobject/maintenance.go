@@ -0,0 +1,36 @@
+package valueobject
+
+// MaintenanceStatus tells whether a preventive maintenance of a vehicle is due
+type MaintenanceStatus string
+
+const (
+	MaintenanceUpToDate MaintenanceStatus = "EM_DIA"
+	MaintenanceDueSoon  MaintenanceStatus = "PROXIMA"
+	MaintenanceOverdue  MaintenanceStatus = "VENCIDA"
+	// MaintenanceNoHistory is a maintenance with nothing to count the interval from: the workshop
+	// never performed it on the vehicle, or did without reading the odometer when only a mileage
+	// is planned
+	MaintenanceNoHistory MaintenanceStatus = "SEM_HISTORICO"
+)
+
+func ParseMaintenanceStatus(value string) MaintenanceStatus {
+	return MaintenanceStatus(value)
+}
+
+func (s MaintenanceStatus) IsValid() bool {
+	switch s {
+	case MaintenanceUpToDate, MaintenanceDueSoon, MaintenanceOverdue, MaintenanceNoHistory:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDue tells whether the customer should be reminded of the maintenance
+func (s MaintenanceStatus) IsDue() bool {
+	return s == MaintenanceDueSoon || s == MaintenanceOverdue
+}
+
+func (s MaintenanceStatus) String() string {
+	return string(s)
+}
@@ -0,0 +1,61 @@
+package valueobject
+
+// NotificationChannel is the way a message reaches the customer
+type NotificationChannel string
+
+const (
+	NotificationEmail    NotificationChannel = "EMAIL"
+	NotificationSMS      NotificationChannel = "SMS"
+	NotificationWhatsApp NotificationChannel = "WHATSAPP"
+)
+
+func ParseNotificationChannel(value string) NotificationChannel {
+	switch value {
+	case "EMAIL":
+		return NotificationEmail
+	case "SMS":
+		return NotificationSMS
+	case "WHATSAPP":
+		return NotificationWhatsApp
+	default:
+		return NotificationChannel(value)
+	}
+}
+
+func (c NotificationChannel) IsValid() bool {
+	return c == NotificationEmail || c == NotificationSMS || c == NotificationWhatsApp
+}
+
+func (c NotificationChannel) String() string {
+	return string(c)
+}
+
+// NotificationStatus is the state of the delivery of a message
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "PENDENTE"
+	NotificationSent    NotificationStatus = "ENVIADA"
+	NotificationFailed  NotificationStatus = "FALHOU"
+)
+
+func ParseNotificationStatus(value string) NotificationStatus {
+	switch value {
+	case "PENDENTE":
+		return NotificationPending
+	case "ENVIADA":
+		return NotificationSent
+	case "FALHOU":
+		return NotificationFailed
+	default:
+		return NotificationStatus(value)
+	}
+}
+
+func (s NotificationStatus) IsValid() bool {
+	return s == NotificationPending || s == NotificationSent || s == NotificationFailed
+}
+
+func (s NotificationStatus) String() string {
+	return string(s)
+}
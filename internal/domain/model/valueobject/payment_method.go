@@ -0,0 +1,44 @@
+package valueobject
+
+type PaymentMethod string
+
+const (
+	PaymentCash         PaymentMethod = "DINHEIRO"
+	PaymentPix          PaymentMethod = "PIX"
+	PaymentCreditCard   PaymentMethod = "CARTAO_CREDITO"
+	PaymentDebitCard    PaymentMethod = "CARTAO_DEBITO"
+	PaymentBankSlip     PaymentMethod = "BOLETO"
+	PaymentBankTransfer PaymentMethod = "TRANSFERENCIA"
+)
+
+func ParsePaymentMethod(value string) PaymentMethod {
+	switch value {
+	case "DINHEIRO":
+		return PaymentCash
+	case "PIX":
+		return PaymentPix
+	case "CARTAO_CREDITO":
+		return PaymentCreditCard
+	case "CARTAO_DEBITO":
+		return PaymentDebitCard
+	case "BOLETO":
+		return PaymentBankSlip
+	case "TRANSFERENCIA":
+		return PaymentBankTransfer
+	default:
+		return PaymentMethod(value)
+	}
+}
+
+func (p PaymentMethod) IsValid() bool {
+	switch p {
+	case PaymentCash, PaymentPix, PaymentCreditCard, PaymentDebitCard, PaymentBankSlip, PaymentBankTransfer:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p PaymentMethod) String() string {
+	return string(p)
+}
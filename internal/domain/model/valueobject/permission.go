@@ -0,0 +1,91 @@
+package valueobject
+
+// Permission is an action on a part of the system, granted to users through their role
+type Permission string
+
+const (
+	PermissionManageStaff         Permission = "staff:manage"
+	PermissionManageCustomers     Permission = "customers:manage"
+	PermissionViewCatalog         Permission = "catalog:view"
+	PermissionManageCatalog       Permission = "catalog:manage"
+	PermissionManageStock         Permission = "stock:manage"
+	PermissionViewServiceOrders   Permission = "service_orders:view"
+	PermissionManageServiceOrders Permission = "service_orders:manage"
+	PermissionManageAppointments  Permission = "appointments:manage"
+	PermissionManageMechanics     Permission = "mechanics:manage"
+	PermissionRecordLabour        Permission = "labour:record"
+	PermissionManageDiscounts     Permission = "discounts:manage"
+	PermissionApproveDiscounts    Permission = "discounts:approve"
+	PermissionManagePayments      Permission = "payments:manage"
+	PermissionViewReports         Permission = "reports:view"
+	PermissionManageSettings      Permission = "settings:manage"
+)
+
+// Permissions lists every permission, in the order they are documented
+var Permissions = []Permission{
+	PermissionManageStaff,
+	PermissionManageCustomers,
+	PermissionViewCatalog,
+	PermissionManageCatalog,
+	PermissionManageStock,
+	PermissionViewServiceOrders,
+	PermissionManageServiceOrders,
+	PermissionManageAppointments,
+	PermissionManageMechanics,
+	PermissionRecordLabour,
+	PermissionManageDiscounts,
+	PermissionApproveDiscounts,
+	PermissionManagePayments,
+	PermissionViewReports,
+	PermissionManageSettings,
+}
+
+// rolePermissions maps each role to what it is allowed to do. Admins and managers can do
+// everything; customers follow their orders through the approval links and the live updates,
+// which are authorized on their own.
+var rolePermissions = map[UserType][]Permission{
+	Admin:   Permissions,
+	Manager: Permissions,
+	Receptionist: {
+		PermissionManageCustomers,
+		PermissionViewCatalog,
+		PermissionViewServiceOrders,
+		PermissionManageServiceOrders,
+		PermissionManageAppointments,
+		PermissionManageMechanics,
+	},
+	Mechanic: {
+		PermissionViewCatalog,
+		PermissionViewServiceOrders,
+		PermissionManageServiceOrders,
+		PermissionRecordLabour,
+	},
+	StockKeeper: {
+		PermissionViewCatalog,
+		PermissionManageStock,
+		PermissionViewServiceOrders,
+	},
+	Cashier: {
+		PermissionViewCatalog,
+		PermissionViewServiceOrders,
+		PermissionManagePayments,
+	},
+}
+
+// Permissions returns what the role is allowed to do
+func (u UserType) Permissions() []Permission {
+	return rolePermissions[u]
+}
+
+func (u UserType) HasPermission(permission Permission) bool {
+	for _, granted := range rolePermissions[u] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Permission) String() string {
+	return string(p)
+}
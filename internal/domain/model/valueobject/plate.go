@@ -1,6 +1,9 @@
 package valueobject
 
-import "regexp"
+import (
+	"regexp"
+	"strings"
+)
 
 type Plate string
 
@@ -8,14 +11,51 @@ var (
 	plateRegex = regexp.MustCompile(`^[A-Z]{3}(?:[0-9]{4}|[0-9][A-Z][0-9]{2})$`)
 )
 
+// mercosulLetters maps the second digit of an old plate to the letter that replaced it in the
+// Mercosul format: ABC1234 became ABC1C34
+const mercosulLetters = "ABCDEFGHIJ"
+
+// ParsePlate reads a plate as it is usually written, in upper case and without the dash
 func ParsePlate(value string) Plate {
-	return Plate(value)
+	value = strings.ToUpper(strings.TrimSpace(value))
+	return Plate(strings.NewReplacer("-", "", " ", "").Replace(value))
 }
 
 func (v Plate) IsValidFormat() bool {
 	return plateRegex.MatchString(string(v))
 }
 
+// IsMercosul tells whether the plate is in the Mercosul format, with a letter in the fifth position
+func (v Plate) IsMercosul() bool {
+	return v.IsValidFormat() && v[4] >= 'A' && v[4] <= 'Z'
+}
+
+// Mercosul returns the plate in the Mercosul format, converting an old plate to the one the
+// vehicle received when it was replaced
+func (v Plate) Mercosul() Plate {
+	if !v.IsValidFormat() || v.IsMercosul() {
+		return v
+	}
+	return v[:4] + Plate(mercosulLetters[v[4]-'0']) + v[5:]
+}
+
+// Equivalents returns the plates the same vehicle may be registered with: the plate itself and its
+// old or Mercosul counterpart. Mercosul plates issued to new vehicles, with a letter past J, have
+// no old counterpart.
+func (v Plate) Equivalents() []Plate {
+	if !v.IsValidFormat() {
+		return []Plate{v}
+	}
+	if !v.IsMercosul() {
+		return []Plate{v, v.Mercosul()}
+	}
+	index := strings.IndexByte(mercosulLetters, byte(v[4]))
+	if index < 0 {
+		return []Plate{v}
+	}
+	return []Plate{v, v[:4] + Plate('0'+byte(index)) + v[5:]}
+}
+
 func (v Plate) String() string {
 	return string(v)
 }
@@ -83,6 +83,12 @@ func (s ServiceOrderStatus) IsCancelada() bool {
 	return s == StatusCancelada
 }
 
+// IsClosed tells whether the order has left the workshop's hands: the vehicle was delivered or the
+// order was cancelled, or its estimate was rejected
+func (s ServiceOrderStatus) IsClosed() bool {
+	return s == StatusEntregue || s == StatusCancelada || s == StatusRejeitada
+}
+
 func (s ServiceOrderStatus) String() string {
 	return string(s)
 }
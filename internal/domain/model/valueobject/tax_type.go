@@ -0,0 +1,44 @@
+package valueobject
+
+import "math"
+
+// TaxType is a Brazilian tax levied on a service order line:
+// ISS (municipal) on services and ICMS (state) on parts
+type TaxType string
+
+const (
+	TaxISS  TaxType = "ISS"
+	TaxICMS TaxType = "ICMS"
+)
+
+func ParseTaxType(value string) TaxType {
+	switch value {
+	case "ISS":
+		return TaxISS
+	case "ICMS":
+		return TaxICMS
+	default:
+		return TaxType(value)
+	}
+}
+
+func (t TaxType) IsValid() bool {
+	switch t {
+	case TaxISS, TaxICMS:
+		return true
+	default:
+		return false
+	}
+}
+
+// Amount returns the tax over base at the given rate (percentage), rounded to cents
+func (t TaxType) Amount(base, rate float64) float64 {
+	if base <= 0 || rate <= 0 {
+		return 0
+	}
+	return math.Round(base*rate) / 100
+}
+
+func (t TaxType) String() string {
+	return string(t)
+}
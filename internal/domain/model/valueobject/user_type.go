@@ -5,18 +5,59 @@ type UserType string
 const (
 	Admin    UserType = "admin"
 	Customer UserType = "customer"
+
+	// Staff roles, given to the employees of the workshop
+	Manager      UserType = "manager"
+	Receptionist UserType = "receptionist"
+	Mechanic     UserType = "mechanic"
+	StockKeeper  UserType = "stock_keeper"
+	Cashier      UserType = "cashier"
 )
 
+// StaffRoles lists the roles that can be given to an employee through the staff endpoints
+var StaffRoles = []UserType{Manager, Receptionist, Mechanic, StockKeeper, Cashier}
+
 func ParseUserType(value string) UserType {
 	switch value {
 	case "admin":
 		return Admin
 	case "customer":
 		return Customer
+	case "manager":
+		return Manager
+	case "receptionist":
+		return Receptionist
+	case "mechanic":
+		return Mechanic
+	case "stock_keeper":
+		return StockKeeper
+	case "cashier":
+		return Cashier
 	default:
 		return UserType(value)
 	}
 }
+
+func (u UserType) IsValid() bool {
+	switch u {
+	case Admin, Customer, Manager, Receptionist, Mechanic, StockKeeper, Cashier:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsStaffRole tells whether the role can be given through the staff endpoints. Admins are only
+// created by the seed, so no staff manager can grant more than their own permissions.
+func (u UserType) IsStaffRole() bool {
+	for _, role := range StaffRoles {
+		if u == role {
+			return true
+		}
+	}
+	return false
+}
+
 func (u UserType) String() string {
 	return string(u)
 }
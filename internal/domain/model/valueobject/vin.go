@@ -0,0 +1,21 @@
+package valueobject
+
+import (
+	"mecanica_xpto/pkg/validators"
+	"strings"
+)
+
+// VIN is the chassis number of the vehicle
+type VIN string
+
+func ParseVIN(value string) VIN {
+	return VIN(strings.ToUpper(strings.TrimSpace(value)))
+}
+
+func (v VIN) IsValid() bool {
+	return validators.VinIsValid(string(v)) == nil
+}
+
+func (v VIN) String() string {
+	return string(v)
+}
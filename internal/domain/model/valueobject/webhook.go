@@ -0,0 +1,31 @@
+package valueobject
+
+// WebhookDeliveryStatus is the state of the delivery of an event to a webhook subscription
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending   WebhookDeliveryStatus = "PENDENTE"
+	WebhookDelivered WebhookDeliveryStatus = "ENTREGUE"
+	WebhookFailed    WebhookDeliveryStatus = "FALHOU"
+)
+
+func ParseWebhookDeliveryStatus(value string) WebhookDeliveryStatus {
+	switch value {
+	case "PENDENTE":
+		return WebhookPending
+	case "ENTREGUE":
+		return WebhookDelivered
+	case "FALHOU":
+		return WebhookFailed
+	default:
+		return WebhookDeliveryStatus(value)
+	}
+}
+
+func (s WebhookDeliveryStatus) IsValid() bool {
+	return s == WebhookPending || s == WebhookDelivered || s == WebhookFailed
+}
+
+func (s WebhookDeliveryStatus) String() string {
+	return string(s)
+}
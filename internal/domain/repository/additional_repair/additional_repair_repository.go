@@ -1,19 +1,35 @@
 package additional_repair
 
 import (
+	"errors"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"mecanica_xpto/internal/domain/model/dto"
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/outbox"
+)
+
+var (
+	// ErrStatusChanged is returned when the additional repair left the status it was read in before the update
+	ErrStatusChanged = errors.New("the status of the additional repair changed in the meantime")
+	// ErrPartsSupplyNotReserved is returned when a parts supply no longer has the units of the additional repair reserved
+	ErrPartsSupplyNotReserved = errors.New("the parts supply is no longer reserved for the additional repair")
+	// ErrServiceOrderPaid is returned when the service order was paid before the additional repair was approved
+	ErrServiceOrderPaid = errors.New("the service order was paid in the meantime")
 )
 
 type IAdditionalRepairRepository interface {
-	Create(additionalRepair *dto.AdditionalRepairDTO) error
+	Create(additionalRepair *dto.AdditionalRepairDTO, events ...entities.DomainEvent) error
 	GetByID(id uint) (*dto.AdditionalRepairDTO, error)
 	AddPartSupplyAndService(additionalRepair, updatedAdditionalRepair *dto.AdditionalRepairDTO) error
 	RemovePartSupplyAndService(additionalRepair, updatedAdditionalRepair *dto.AdditionalRepairDTO) error
 	GetByServiceOrder(serviceOrderId uint) ([]dto.AdditionalRepairDTO, error)
 	GetStatus(status string) (*dto.AdditionalRepairStatusDTO, error)
-	CustomerApprovalStatus(id uint, status entities.AdditionalRepairStatusDTO) error
+	UpdateStatus(id uint, status valueobject.AdditionalRepairStatus, events ...entities.DomainEvent) error
+	Approve(additionalRepair *dto.AdditionalRepairDTO, events ...entities.DomainEvent) error
+	AddHistory(entry *dto.AdditionalRepairHistoryDTO) error
 }
 
 // AdditionalRepairRepository implements IAdditionalRepairRepository interface
@@ -25,117 +41,215 @@ func NewAdditionalRepairRepository(db *gorm.DB) IAdditionalRepairRepository {
 	return &AdditionalRepairRepository{db: db}
 }
 
-func (r *AdditionalRepairRepository) Create(additionalRepair *dto.AdditionalRepairDTO) error {
+// Create stores the additional repair, writing the events raised by its creation to the outbox in
+// the same transaction
+func (r *AdditionalRepairRepository) Create(additionalRepair *dto.AdditionalRepairDTO, events ...entities.DomainEvent) error {
 	dtoStatus, err := r.GetStatus(additionalRepair.ARStatus.Description)
 	if err != nil {
 		return gorm.ErrInvalidData
 	}
 	additionalRepair.ARStatus = *dtoStatus
-	tx := r.db.Begin()
-	if err := tx.Create(&additionalRepair).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	return tx.Commit().Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&additionalRepair).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, additionalRepair.ID, events)
+	})
 }
 
 func (r *AdditionalRepairRepository) GetByID(id uint) (*dto.AdditionalRepairDTO, error) {
 	var additionalRepair dto.AdditionalRepairDTO
 	err := r.db.Preload("ARStatus").
 		Preload("PartsSupplies").
+		Preload("PartsSupplyItems").
 		Preload("Services").
+		Preload("ServiceItems").
+		Preload("History", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
 		First(&additionalRepair, id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &additionalRepair, nil
 }
 
+// AddPartSupplyAndService adds the services and parts supplies of updatedAdditionalRepair to the
+// additional repair. A part already in the additional repair has its quantity increased and its
+// unit price averaged over all its units, and the estimate of updatedAdditionalRepair is added to
+// the current one. Nothing is stored when the additional repair is no longer in the status it was
+// read in.
 func (r *AdditionalRepairRepository) AddPartSupplyAndService(additionalRepair, updatedAdditionalRepair *dto.AdditionalRepairDTO) error {
-	tx := r.db.Begin()
-
-	for _, ps := range updatedAdditionalRepair.PartsSupplies {
-		relation := dto.PartsSupplyAdditionalRepairDTO{
-			PartsSupplyID:      ps.ID,
-			AdditionalRepairID: additionalRepair.ID,
-		}
-		if err := tx.Create(&relation).Error; err != nil {
-			tx.Rollback()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Updated first, so concurrent changes of the additional repair wait for this one
+		if err := updateEstimate(tx, additionalRepair, gorm.Expr("estimate + ?", updatedAdditionalRepair.Estimate)); err != nil {
 			return err
 		}
-	}
 
-	for _, svc := range updatedAdditionalRepair.Services {
-		relation := dto.ServiceAdditionalRepairDTO{
-			ServiceID:          svc.ID,
-			AdditionalRepairID: additionalRepair.ID,
+		for _, item := range updatedAdditionalRepair.PartsSupplyItems {
+			relation := dto.PartsSupplyAdditionalRepairDTO{
+				PartsSupplyID:      item.PartsSupplyID,
+				AdditionalRepairID: additionalRepair.ID,
+				Quantity:           item.Quantity,
+				UnitPrice:          item.UnitPrice,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "parts_supply_id"}, {Name: "additional_repair_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"quantity": gorm.Expr("parts_supply_additional_repair_dtos.quantity + EXCLUDED.quantity"),
+					"unit_price": gorm.Expr("(parts_supply_additional_repair_dtos.quantity * parts_supply_additional_repair_dtos.unit_price + EXCLUDED.quantity * EXCLUDED.unit_price) / " +
+						"(parts_supply_additional_repair_dtos.quantity + EXCLUDED.quantity)"),
+				}),
+			}).Create(&relation).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, svc := range updatedAdditionalRepair.ServiceItems {
+			relation := dto.ServiceAdditionalRepairDTO{
+				ServiceID:          svc.ServiceID,
+				AdditionalRepairID: additionalRepair.ID,
+				UnitPrice:          svc.UnitPrice,
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&relation).Error; err != nil {
+				return err
+			}
 		}
-		if err := tx.Create(&relation).Error; err != nil {
-			tx.Rollback()
+		return nil
+	})
+}
+
+// RemovePartSupplyAndService takes the services and parts supply quantities of removedAdditionalRepair
+// out of the additional repair, deleting the parts whose quantity reaches zero, and subtracts the
+// estimate of removedAdditionalRepair from the current one, down to zero. Nothing is stored when
+// the additional repair is no longer in the status it was read in.
+func (r *AdditionalRepairRepository) RemovePartSupplyAndService(additionalRepair, removedAdditionalRepair *dto.AdditionalRepairDTO) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Updated first, so concurrent changes of the additional repair wait for this one
+		if err := updateEstimate(tx, additionalRepair, gorm.Expr("GREATEST(estimate - ?, 0)", removedAdditionalRepair.Estimate)); err != nil {
 			return err
 		}
-	}
-	newEstimate := calculateEstimate(updatedAdditionalRepair.Services, updatedAdditionalRepair.PartsSupplies)
-
-	// Update only the Estimate field
-	if err := tx.Model(&dto.AdditionalRepairDTO{}).
-		Where("id = ?", additionalRepair.ID).
-		Update("estimate", newEstimate).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
 
-	return tx.Commit().Error
-}
+		for _, item := range removedAdditionalRepair.PartsSupplyItems {
+			if err := tx.Model(&dto.PartsSupplyAdditionalRepairDTO{}).
+				Where("additional_repair_id = ? AND parts_supply_id = ?", additionalRepair.ID, item.PartsSupplyID).
+				Update("quantity", gorm.Expr("GREATEST(quantity - ?, 0)", item.Quantity)).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("additional_repair_id = ? AND parts_supply_id = ? AND quantity = 0", additionalRepair.ID, item.PartsSupplyID).
+				Delete(&dto.PartsSupplyAdditionalRepairDTO{}).Error; err != nil {
+				return err
+			}
+		}
 
-func (r *AdditionalRepairRepository) RemovePartSupplyAndService(additionalRepair, updatedAdditionalRepair *dto.AdditionalRepairDTO) error {
-	tx := r.db.Begin()
+		for _, svc := range removedAdditionalRepair.ServiceItems {
+			if err := tx.Where("additional_repair_id = ? AND service_id = ?", additionalRepair.ID, svc.ServiceID).
+				Delete(&dto.ServiceAdditionalRepairDTO{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
-	if err := tx.Where("additional_repair_id = ?", additionalRepair.ID).
-		Delete(&dto.PartsSupplyAdditionalRepairDTO{}).Error; err != nil {
-		tx.Rollback()
-		return err
+// updateEstimate sets the estimate of the additional repair to the given expression, returning
+// ErrStatusChanged when it is no longer in the status it was read in
+func updateEstimate(tx *gorm.DB, additionalRepair *dto.AdditionalRepairDTO, estimate clause.Expr) error {
+	result := tx.Model(&dto.AdditionalRepairDTO{}).
+		Where("id = ? AND ar_status_id = ?", additionalRepair.ID, additionalRepair.ARStatusID).
+		Update("estimate", estimate)
+	if result.Error != nil {
+		return result.Error
 	}
-
-	if err := tx.Where("additional_repair_id = ?", additionalRepair.ID).
-		Delete(&dto.ServiceAdditionalRepairDTO{}).Error; err != nil {
-		tx.Rollback()
-		return err
+	if result.RowsAffected == 0 {
+		return ErrStatusChanged
 	}
-	newEstimate := recalculateEstimateAfterRemoval(additionalRepair, updatedAdditionalRepair.PartsSupplies, updatedAdditionalRepair.Services)
-
-	// Update only the Estimate field
-	if err := tx.Model(&dto.AdditionalRepairDTO{}).
-		Where("id = ?", additionalRepair.ID).
-		Update("estimate", newEstimate).Error; err != nil {
-		tx.Rollback()
-		return err
+	return nil
+}
+
+// UpdateStatus moves the additional repair to the status row matching the given status, writing
+// the events raised by the change to the outbox in the same transaction
+func (r *AdditionalRepairRepository) UpdateStatus(id uint, status valueobject.AdditionalRepairStatus, events ...entities.DomainEvent) error {
+	dtoStatus, err := r.GetStatus(status.String())
+	if err != nil {
+		return gorm.ErrInvalidData
 	}
 
-	return tx.Commit().Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&dto.AdditionalRepairDTO{}).
+			Where("id = ?", id).
+			Update("ar_status_id", dtoStatus.ID).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, id, events)
+	})
 }
 
-func (r *AdditionalRepairRepository) CustomerApprovalStatus(id uint, status entities.AdditionalRepairStatusDTO) error {
-	var dtoDB dto.AdditionalRepairDTO
-	if err := r.db.First(&dtoDB, id).Error; err != nil {
-		return err
-	}
-	dtoStatus, err := r.GetStatus(status.ApprovalStatus)
+// Approve marks the additional repair as approved, writes its reserved parts supplies off the stock
+// and adds its estimate to the one of the service order, writing the events to the outbox in the same
+// transaction. Nothing is stored when the additional repair is no longer in the status it was read
+// in, a parts supply is no longer reserved or the service order was paid.
+func (r *AdditionalRepairRepository) Approve(additionalRepair *dto.AdditionalRepairDTO, events ...entities.DomainEvent) error {
+	dtoStatus, err := r.GetStatus(valueobject.StatusAAprovada.String())
 	if err != nil {
 		return gorm.ErrInvalidData
 	}
 
-	return r.db.Model(&dto.AdditionalRepairDTO{}).
-		Where("id = ?", id).
-		Update("ar_status_id", dtoStatus.ID).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dto.AdditionalRepairDTO{}).
+			Where("id = ? AND ar_status_id = ?", additionalRepair.ID, additionalRepair.ARStatusID).
+			Update("ar_status_id", dtoStatus.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+
+		for _, item := range additionalRepair.PartsSupplyItems {
+			result = tx.Model(&dto.PartsSupplyDTO{}).
+				Where("id = ? AND quantity_reserve >= ?", item.PartsSupplyID, item.Quantity).
+				Updates(map[string]interface{}{
+					"quantity_reserve": gorm.Expr("quantity_reserve - ?", item.Quantity),
+					"quantity_total":   gorm.Expr("quantity_total - ?", item.Quantity),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrPartsSupplyNotReserved
+			}
+		}
+
+		result = tx.Model(&dto.ServiceOrderDTO{}).
+			Where("id = ? AND NOT EXISTS (SELECT 1 FROM payment_dtos WHERE service_order_id = ?)", additionalRepair.ServiceOrderID, additionalRepair.ServiceOrderID).
+			Update("estimate", gorm.Expr("estimate + ?", additionalRepair.Estimate))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrServiceOrderPaid
+		}
+
+		return outbox.Append(tx, additionalRepair.ID, events)
+	})
+}
+
+func (r *AdditionalRepairRepository) AddHistory(entry *dto.AdditionalRepairHistoryDTO) error {
+	return r.db.Create(entry).Error
 }
 
 func (r *AdditionalRepairRepository) GetByServiceOrder(serviceOrderId uint) ([]dto.AdditionalRepairDTO, error) {
 	var additionalRepairs []dto.AdditionalRepairDTO
 	err := r.db.Preload("ARStatus").
 		Preload("PartsSupplies").
+		Preload("PartsSupplyItems").
 		Preload("Services").
+		Preload("ServiceItems").
 		Where("service_order_id = ?", serviceOrderId).
 		Find(&additionalRepairs).Error
 	if err != nil {
@@ -152,28 +266,3 @@ func (r *AdditionalRepairRepository) GetStatus(status string) (*dto.AdditionalRe
 	}
 	return &additionalRepairStatus, nil
 }
-
-func calculateEstimate(services []dto.ServiceDTO, partsSupplies []dto.PartsSupplyDTO) float64 {
-	var total float64
-	for _, svc := range services {
-		total += svc.Price
-	}
-	for _, ps := range partsSupplies {
-		total += ps.Price
-	}
-	return total
-}
-
-func recalculateEstimateAfterRemoval(additionalRepair *dto.AdditionalRepairDTO, removedPartsSupplies []dto.PartsSupplyDTO, removedServices []dto.ServiceDTO) float64 {
-	estimate := additionalRepair.Estimate
-	for _, svc := range removedServices {
-		estimate -= svc.Price
-	}
-	for _, ps := range removedPartsSupplies {
-		estimate -= ps.Price
-	}
-	if estimate < 0 {
-		estimate = 0
-	}
-	return estimate
-}
@@ -0,0 +1,146 @@
+package appointment
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IAppointmentRepository interface {
+	CreateBay(ctx context.Context, bay *dto.BayDTO) error
+	GetBay(ctx context.Context, id uint) (*dto.BayDTO, error)
+	ListBays(ctx context.Context, activeOnly bool) ([]dto.BayDTO, error)
+	UpdateBay(ctx context.Context, bay *dto.BayDTO) error
+	Save(ctx context.Context, appointment *dto.AppointmentDTO) (bool, error)
+	GetByID(ctx context.Context, id uint) (*dto.AppointmentDTO, error)
+	List(ctx context.Context, from, to time.Time) ([]dto.AppointmentDTO, error)
+	ListScheduled(ctx context.Context, from, to time.Time) ([]dto.AppointmentDTO, error)
+	UpdateStatus(ctx context.Context, id uint, status valueobject.AppointmentStatus, serviceOrderID *uint) error
+}
+
+type AppointmentRepository struct {
+	db *gorm.DB
+}
+
+var _ IAppointmentRepository = (*AppointmentRepository)(nil)
+
+func NewAppointmentRepository(db *gorm.DB) *AppointmentRepository {
+	return &AppointmentRepository{db: db}
+}
+
+func (r *AppointmentRepository) CreateBay(ctx context.Context, bay *dto.BayDTO) error {
+	return r.db.WithContext(ctx).Create(bay).Error
+}
+
+func (r *AppointmentRepository) GetBay(ctx context.Context, id uint) (*dto.BayDTO, error) {
+	var bay dto.BayDTO
+	if err := r.db.WithContext(ctx).First(&bay, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &bay, nil
+}
+
+func (r *AppointmentRepository) ListBays(ctx context.Context, activeOnly bool) ([]dto.BayDTO, error) {
+	query := r.db.WithContext(ctx).Order("id")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	var bays []dto.BayDTO
+	if err := query.Find(&bays).Error; err != nil {
+		return nil, err
+	}
+	return bays, nil
+}
+
+func (r *AppointmentRepository) UpdateBay(ctx context.Context, bay *dto.BayDTO) error {
+	return r.db.WithContext(ctx).Save(bay).Error
+}
+
+// Save creates or reschedules the appointment unless another scheduled appointment holds its bay in
+// an overlapping interval, in which case it returns false. The bay row is locked for the check, so
+// two bookings of the same bay cannot both succeed.
+func (r *AppointmentRepository) Save(ctx context.Context, appointment *dto.AppointmentDTO) (bool, error) {
+	saved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var bay dto.BayDTO
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bay, appointment.BayID).Error; err != nil {
+			return err
+		}
+
+		var overlapping int64
+		if err := tx.Model(&dto.AppointmentDTO{}).
+			Where("bay_id = ? AND status = ? AND id <> ?", appointment.BayID, valueobject.AppointmentScheduled.String(), appointment.ID).
+			Where("starts_at < ? AND ends_at > ?", appointment.EndsAt, appointment.StartsAt).
+			Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return nil
+		}
+
+		if appointment.ID == 0 {
+			if err := tx.Create(appointment).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(&dto.AppointmentDTO{}).Where("id = ?", appointment.ID).Updates(map[string]interface{}{
+			"bay_id":    appointment.BayID,
+			"starts_at": appointment.StartsAt,
+			"ends_at":   appointment.EndsAt,
+		}).Error; err != nil {
+			return err
+		}
+		saved = true
+		return nil
+	})
+	return saved, err
+}
+
+func (r *AppointmentRepository) GetByID(ctx context.Context, id uint) (*dto.AppointmentDTO, error) {
+	var appointment dto.AppointmentDTO
+	if err := r.db.WithContext(ctx).Preload("Bay").Preload("Services").First(&appointment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+// List returns the appointments starting within [from, to), whatever their status
+func (r *AppointmentRepository) List(ctx context.Context, from, to time.Time) ([]dto.AppointmentDTO, error) {
+	var appointments []dto.AppointmentDTO
+	if err := r.db.WithContext(ctx).Preload("Bay").Preload("Services").
+		Where("starts_at >= ? AND starts_at < ?", from, to).
+		Order("starts_at, bay_id").
+		Find(&appointments).Error; err != nil {
+		return nil, err
+	}
+	return appointments, nil
+}
+
+// ListScheduled returns the scheduled appointments holding a bay at some point of [from, to)
+func (r *AppointmentRepository) ListScheduled(ctx context.Context, from, to time.Time) ([]dto.AppointmentDTO, error) {
+	var appointments []dto.AppointmentDTO
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND starts_at < ? AND ends_at > ?", valueobject.AppointmentScheduled.String(), to, from).
+		Order("starts_at").
+		Find(&appointments).Error; err != nil {
+		return nil, err
+	}
+	return appointments, nil
+}
+
+func (r *AppointmentRepository) UpdateStatus(ctx context.Context, id uint, status valueobject.AppointmentStatus, serviceOrderID *uint) error {
+	return r.db.WithContext(ctx).Model(&dto.AppointmentDTO{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":           status.String(),
+		"service_order_id": serviceOrderID,
+	}).Error
+}
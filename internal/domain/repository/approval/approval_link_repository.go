@@ -0,0 +1,88 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IApprovalLinkRepository interface {
+	Create(ctx context.Context, link *dto.ApprovalLinkDTO) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*dto.ApprovalLinkDTO, error)
+	ListByServiceOrderID(ctx context.Context, serviceOrderID uint) ([]dto.ApprovalLinkDTO, error)
+	MarkUsed(ctx context.Context, id uint, decision string, consent entities.ApprovalConsent, decidedAt time.Time) (bool, error)
+	ReleaseUse(ctx context.Context, id uint) error
+}
+
+type ApprovalLinkRepository struct {
+	db *gorm.DB
+}
+
+var _ IApprovalLinkRepository = (*ApprovalLinkRepository)(nil)
+
+func NewApprovalLinkRepository(db *gorm.DB) *ApprovalLinkRepository {
+	return &ApprovalLinkRepository{db: db}
+}
+
+func (r *ApprovalLinkRepository) Create(ctx context.Context, link *dto.ApprovalLinkDTO) error {
+	return r.db.WithContext(ctx).Create(link).Error
+}
+
+func (r *ApprovalLinkRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*dto.ApprovalLinkDTO, error) {
+	var link dto.ApprovalLinkDTO
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ApprovalLinkRepository) ListByServiceOrderID(ctx context.Context, serviceOrderID uint) ([]dto.ApprovalLinkDTO, error) {
+	var links []dto.ApprovalLinkDTO
+	err := r.db.WithContext(ctx).
+		Where("service_order_id = ?", serviceOrderID).
+		Order("created_at, id").
+		Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// MarkUsed records the decision and the consent of the customer on a link that was not used yet.
+// It reports false when another request already used the link.
+func (r *ApprovalLinkRepository) MarkUsed(ctx context.Context, id uint, decision string, consent entities.ApprovalConsent, decidedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&dto.ApprovalLinkDTO{}).
+		Where("id = ? AND decided_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"decision":   decision,
+			"decided_at": decidedAt,
+			"ip":         consent.IP,
+			"user_agent": consent.UserAgent,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseUse makes a link usable again when its decision could not be applied
+func (r *ApprovalLinkRepository) ReleaseUse(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.ApprovalLinkDTO{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"decision":   "",
+			"decided_at": nil,
+			"ip":         "",
+			"user_agent": "",
+		}).Error
+}
@@ -0,0 +1,58 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/valueobject"
+
+	"gorm.io/gorm"
+)
+
+type IAttachmentRepository interface {
+	Create(ctx context.Context, attachment *dto.AttachmentDTO) error
+	GetByID(ctx context.Context, id uint) (*dto.AttachmentDTO, error)
+	ListByOwner(ctx context.Context, ownerType valueobject.AttachmentOwner, ownerID uint) ([]dto.AttachmentDTO, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+var _ IAttachmentRepository = (*AttachmentRepository)(nil)
+
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *dto.AttachmentDTO) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+func (r *AttachmentRepository) GetByID(ctx context.Context, id uint) (*dto.AttachmentDTO, error) {
+	var attachment dto.AttachmentDTO
+	if err := r.db.WithContext(ctx).First(&attachment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepository) ListByOwner(ctx context.Context, ownerType valueobject.AttachmentOwner, ownerID uint) ([]dto.AttachmentDTO, error) {
+	var attachments []dto.AttachmentDTO
+	err := r.db.WithContext(ctx).
+		Where("owner_type = ? AND owner_id = ?", ownerType.String(), ownerID).
+		Order("id").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&dto.AttachmentDTO{}, id).Error
+}
@@ -0,0 +1,109 @@
+package checkin
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"gorm.io/gorm"
+)
+
+type ICheckInRepository interface {
+	GetByServiceOrder(ctx context.Context, serviceOrderID uint) (*dto.CheckInDTO, error)
+	ListByServiceOrders(ctx context.Context, serviceOrderIDs []uint) ([]dto.CheckInDTO, error)
+	Save(ctx context.Context, checkIn *dto.CheckInDTO) error
+	AddPhoto(ctx context.Context, photo *dto.CheckInPhotoDTO) error
+	GetPhoto(ctx context.Context, checkInID, photoID uint) (*dto.CheckInPhotoDTO, error)
+	DeletePhoto(ctx context.Context, id uint) error
+}
+
+type CheckInRepository struct {
+	db *gorm.DB
+}
+
+var _ ICheckInRepository = (*CheckInRepository)(nil)
+
+func NewCheckInRepository(db *gorm.DB) *CheckInRepository {
+	return &CheckInRepository{db: db}
+}
+
+func (r *CheckInRepository) GetByServiceOrder(ctx context.Context, serviceOrderID uint) (*dto.CheckInDTO, error) {
+	var checkIn dto.CheckInDTO
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("Photos", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Where("service_order_id = ?", serviceOrderID).
+		First(&checkIn).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &checkIn, nil
+}
+
+// ListByServiceOrders returns the check-ins of the service orders, without their checklist and photos
+func (r *CheckInRepository) ListByServiceOrders(ctx context.Context, serviceOrderIDs []uint) ([]dto.CheckInDTO, error) {
+	var checkIns []dto.CheckInDTO
+	if len(serviceOrderIDs) == 0 {
+		return checkIns, nil
+	}
+	if err := r.db.WithContext(ctx).Where("service_order_id IN ?", serviceOrderIDs).Find(&checkIns).Error; err != nil {
+		return nil, err
+	}
+	return checkIns, nil
+}
+
+// Save creates the check-in or, when it already exists, updates it and replaces its checklist.
+// The photos are kept.
+func (r *CheckInRepository) Save(ctx context.Context, checkIn *dto.CheckInDTO) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if checkIn.ID == 0 {
+			return tx.Omit("Photos").Create(checkIn).Error
+		}
+
+		if err := tx.Model(&dto.CheckInDTO{ID: checkIn.ID}).Updates(map[string]interface{}{
+			"odometer":     checkIn.Odometer,
+			"fuel_level":   checkIn.FuelLevel,
+			"notes":        checkIn.Notes,
+			"inspected_by": checkIn.InspectedBy,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("check_in_id = ?", checkIn.ID).Delete(&dto.CheckInItemDTO{}).Error; err != nil {
+			return err
+		}
+		for i := range checkIn.Items {
+			checkIn.Items[i].ID = 0
+			checkIn.Items[i].CheckInID = checkIn.ID
+		}
+		if len(checkIn.Items) == 0 {
+			return nil
+		}
+		return tx.Create(&checkIn.Items).Error
+	})
+}
+
+func (r *CheckInRepository) AddPhoto(ctx context.Context, photo *dto.CheckInPhotoDTO) error {
+	return r.db.WithContext(ctx).Create(photo).Error
+}
+
+func (r *CheckInRepository) GetPhoto(ctx context.Context, checkInID, photoID uint) (*dto.CheckInPhotoDTO, error) {
+	var photo dto.CheckInPhotoDTO
+	if err := r.db.WithContext(ctx).Where("check_in_id = ?", checkInID).First(&photo, photoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &photo, nil
+}
+
+func (r *CheckInRepository) DeletePhoto(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&dto.CheckInPhotoDTO{}, id).Error
+}
@@ -0,0 +1,185 @@
+package corporateaccount
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ICorporateAccountRepository interface {
+	GetByCustomer(ctx context.Context, customerID uint) (*dto.CorporateAccountDTO, error)
+	ListAccounts(ctx context.Context) ([]dto.CorporateAccountDTO, error)
+	SaveAccount(ctx context.Context, account *dto.CorporateAccountDTO) error
+	GetContact(ctx context.Context, id uint) (*dto.CorporateContactDTO, error)
+	SaveContact(ctx context.Context, contact *dto.CorporateContactDTO) error
+	DeleteContact(ctx context.Context, id uint) error
+	OpenBalance(ctx context.Context, customerID uint) (float64, error)
+	ListUnbilledOrders(ctx context.Context, customerID uint, deliveredBefore time.Time) ([]dto.ServiceOrderDTO, error)
+	HasStatement(ctx context.Context, accountID uint, month string) (bool, error)
+	CreateStatement(ctx context.Context, statement *dto.CorporateStatementDTO) error
+	GetStatement(ctx context.Context, id uint) (*dto.CorporateStatementDTO, error)
+	ListStatements(ctx context.Context, accountID uint) ([]dto.CorporateStatementDTO, error)
+	MarkStatementPaid(ctx context.Context, id uint, paidBy string, paidAt time.Time) (bool, error)
+}
+
+type CorporateAccountRepository struct {
+	db *gorm.DB
+}
+
+var _ ICorporateAccountRepository = (*CorporateAccountRepository)(nil)
+
+func NewCorporateAccountRepository(db *gorm.DB) *CorporateAccountRepository {
+	return &CorporateAccountRepository{db: db}
+}
+
+func (r *CorporateAccountRepository) GetByCustomer(ctx context.Context, customerID uint) (*dto.CorporateAccountDTO, error) {
+	var account dto.CorporateAccountDTO
+	err := r.db.WithContext(ctx).
+		Preload("Customer").
+		Preload("Contacts", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("customer_id = ?", customerID).
+		First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *CorporateAccountRepository) ListAccounts(ctx context.Context) ([]dto.CorporateAccountDTO, error) {
+	var accounts []dto.CorporateAccountDTO
+	if err := r.db.WithContext(ctx).Order("id").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *CorporateAccountRepository) SaveAccount(ctx context.Context, account *dto.CorporateAccountDTO) error {
+	return r.db.WithContext(ctx).Omit("Customer", "Contacts").Save(account).Error
+}
+
+func (r *CorporateAccountRepository) GetContact(ctx context.Context, id uint) (*dto.CorporateContactDTO, error) {
+	var contact dto.CorporateContactDTO
+	if err := r.db.WithContext(ctx).First(&contact, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &contact, nil
+}
+
+func (r *CorporateAccountRepository) SaveContact(ctx context.Context, contact *dto.CorporateContactDTO) error {
+	return r.db.WithContext(ctx).Save(contact).Error
+}
+
+func (r *CorporateAccountRepository) DeleteContact(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&dto.CorporateContactDTO{}, id).Error
+}
+
+// OpenBalance sums the estimates of the orders of the customer that are not paid yet, leaving out
+// the cancelled and rejected ones. The orders still being diagnosed count for nothing until they
+// are estimated.
+func (r *CorporateAccountRepository) OpenBalance(ctx context.Context, customerID uint) (float64, error) {
+	var balance float64
+	err := r.db.WithContext(ctx).
+		Model(&dto.ServiceOrderDTO{}).
+		Select("COALESCE(SUM(service_order_dtos.estimate), 0)").
+		Joins("JOIN service_order_status_dtos ON service_order_status_dtos.id = service_order_dtos.os_status_id").
+		Joins("LEFT JOIN payment_dtos ON payment_dtos.service_order_id = service_order_dtos.id").
+		Where("service_order_dtos.customer_id = ?", customerID).
+		Where("service_order_status_dtos.description NOT IN ?", []string{valueobject.StatusCancelada.String(), valueobject.StatusRejeitada.String()}).
+		Where("payment_dtos.id IS NULL").
+		Scan(&balance).Error
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// ListUnbilledOrders lists the orders delivered to the customer before the given time that are
+// neither paid nor billed in a statement yet
+func (r *CorporateAccountRepository) ListUnbilledOrders(ctx context.Context, customerID uint, deliveredBefore time.Time) ([]dto.ServiceOrderDTO, error) {
+	var serviceOrders []dto.ServiceOrderDTO
+	err := r.db.WithContext(ctx).
+		Joins("JOIN service_order_status_dtos ON service_order_status_dtos.id = service_order_dtos.os_status_id").
+		Joins("LEFT JOIN payment_dtos ON payment_dtos.service_order_id = service_order_dtos.id").
+		Joins("LEFT JOIN corporate_statement_item_dtos ON corporate_statement_item_dtos.service_order_id = service_order_dtos.id").
+		Where("service_order_dtos.customer_id = ?", customerID).
+		Where("service_order_status_dtos.description = ?", valueobject.StatusEntregue.String()).
+		Where("service_order_dtos.delivered_at < ?", deliveredBefore).
+		Where("payment_dtos.id IS NULL AND corporate_statement_item_dtos.id IS NULL").
+		Order("service_order_dtos.delivered_at, service_order_dtos.id").
+		Find(&serviceOrders).Error
+	if err != nil {
+		return nil, err
+	}
+	return serviceOrders, nil
+}
+
+func (r *CorporateAccountRepository) HasStatement(ctx context.Context, accountID uint, month string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&dto.CorporateStatementDTO{}).
+		Where("account_id = ? AND month = ?", accountID, month).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateStatement stores the statement with its items; an order already billed in another
+// statement makes it fail as a whole
+func (r *CorporateAccountRepository) CreateStatement(ctx context.Context, statement *dto.CorporateStatementDTO) error {
+	return r.db.WithContext(ctx).Omit("Account", "Items.ServiceOrder").Create(statement).Error
+}
+
+func (r *CorporateAccountRepository) GetStatement(ctx context.Context, id uint) (*dto.CorporateStatementDTO, error) {
+	var statement dto.CorporateStatementDTO
+	err := r.db.WithContext(ctx).
+		Preload("Account").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.ServiceOrder.Vehicle").
+		First(&statement, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// ListStatements lists the statements of the account, the latest month first, without their items
+func (r *CorporateAccountRepository) ListStatements(ctx context.Context, accountID uint) ([]dto.CorporateStatementDTO, error) {
+	var statements []dto.CorporateStatementDTO
+	err := r.db.WithContext(ctx).
+		Preload("Account").
+		Where("account_id = ?", accountID).
+		Order("month DESC").
+		Find(&statements).Error
+	if err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
+
+// MarkStatementPaid records the payment of the statement unless it was already paid, telling
+// whether it was this call that did
+func (r *CorporateAccountRepository) MarkStatementPaid(ctx context.Context, id uint, paidBy string, paidAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&dto.CorporateStatementDTO{}).
+		Where("id = ? AND paid_at IS NULL", id).
+		Updates(map[string]interface{}{"paid_at": paidAt, "paid_by": paidBy})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
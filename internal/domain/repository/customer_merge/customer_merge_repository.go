@@ -0,0 +1,94 @@
+package customermerge
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"gorm.io/gorm"
+)
+
+// ErrCustomerGone is returned when the duplicate customer was merged or deleted by someone else in the meantime
+var ErrCustomerGone = errors.New("the duplicate customer no longer exists")
+
+type ICustomerMergeRepository interface {
+	Merge(ctx context.Context, survivorID uint, duplicate *dto.CustomerDTO, merge *dto.CustomerMergeDTO) error
+	List(ctx context.Context, customerID uint) ([]dto.CustomerMergeDTO, error)
+}
+
+type CustomerMergeRepository struct {
+	db *gorm.DB
+}
+
+var _ ICustomerMergeRepository = (*CustomerMergeRepository)(nil)
+
+func NewCustomerMergeRepository(db *gorm.DB) *CustomerMergeRepository {
+	return &CustomerMergeRepository{db: db}
+}
+
+// Merge moves everything of the duplicate customer onto the survivor, then deletes the duplicate
+// with its user and records the merge. The payments belong to the service orders, so they follow
+// them and are only counted.
+func (r *CustomerMergeRepository) Merge(ctx context.Context, survivorID uint, duplicate *dto.CustomerDTO, merge *dto.CustomerMergeDTO) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&dto.PaymentDTO{}).
+			Where("service_order_id IN (?)", tx.Model(&dto.ServiceOrderDTO{}).Select("id").Where("customer_id = ?", duplicate.ID)).
+			Count(&merge.PaymentsMoved).Error
+		if err != nil {
+			return err
+		}
+
+		if merge.VehiclesMoved, err = moveCustomer(tx.Unscoped().Model(&dto.VehicleDTO{}), duplicate.ID, survivorID); err != nil {
+			return err
+		}
+		if merge.ServiceOrdersMoved, err = moveCustomer(tx.Model(&dto.ServiceOrderDTO{}), duplicate.ID, survivorID); err != nil {
+			return err
+		}
+		if merge.AppointmentsMoved, err = moveCustomer(tx.Model(&dto.AppointmentDTO{}), duplicate.ID, survivorID); err != nil {
+			return err
+		}
+		if _, err = moveCustomer(tx.Model(&dto.VehicleOwnershipDTO{}), duplicate.ID, survivorID); err != nil {
+			return err
+		}
+		if _, err = moveCustomer(tx.Model(&dto.PriceAgreementDTO{}), duplicate.ID, survivorID); err != nil {
+			return err
+		}
+		if _, err = moveCustomer(tx.Unscoped().Model(&dto.WebhookSubscriptionDTO{}), duplicate.ID, survivorID); err != nil {
+			return err
+		}
+		if _, err = moveCustomer(tx.Model(&dto.CorporateAccountDTO{}), duplicate.ID, survivorID); err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ?", duplicate.ID).Delete(&dto.CustomerDTO{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCustomerGone
+		}
+		if err := tx.Delete(&dto.UserDTO{}, duplicate.UserID).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(merge).Error
+	})
+}
+
+// List returns the merges into the customer, or all of them when customerID is 0, newest first
+func (r *CustomerMergeRepository) List(ctx context.Context, customerID uint) ([]dto.CustomerMergeDTO, error) {
+	var merges []dto.CustomerMergeDTO
+	query := r.db.WithContext(ctx)
+	if customerID != 0 {
+		query = query.Where("survivor_id = ?", customerID)
+	}
+	if err := query.Order("merged_at DESC, id DESC").Find(&merges).Error; err != nil {
+		return nil, err
+	}
+	return merges, nil
+}
+
+func moveCustomer(query *gorm.DB, fromID, toID uint) (int64, error) {
+	result := query.Where("customer_id = ?", fromID).Update("customer_id", toID)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,102 @@
+package discount
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"gorm.io/gorm"
+)
+
+type IDiscountRepository interface {
+	CreateCoupon(ctx context.Context, coupon *entities.Coupon) (entities.Coupon, error)
+	GetCouponByCode(ctx context.Context, code string) (*dto.CouponDTO, error)
+	ListCoupons(ctx context.Context) ([]dto.CouponDTO, error)
+	UpdateCoupon(ctx context.Context, coupon *entities.Coupon) error
+	CreatePriceAgreement(ctx context.Context, agreement *entities.PriceAgreement) (entities.PriceAgreement, error)
+	ListPriceAgreementsByCustomer(ctx context.Context, customerID uint) ([]dto.PriceAgreementDTO, error)
+	DeletePriceAgreement(ctx context.Context, id uint) error
+}
+
+type DiscountRepository struct {
+	db *gorm.DB
+}
+
+var _ IDiscountRepository = (*DiscountRepository)(nil)
+
+func NewDiscountRepository(db *gorm.DB) *DiscountRepository {
+	return &DiscountRepository{db: db}
+}
+
+func (r *DiscountRepository) CreateCoupon(ctx context.Context, coupon *entities.Coupon) (entities.Coupon, error) {
+	couponDTO := dto.CouponDTO{
+		Code:         coupon.Code,
+		Description:  coupon.Description,
+		DiscountType: coupon.DiscountType.String(),
+		Value:        coupon.Value,
+		ValidFrom:    coupon.ValidFrom,
+		ValidUntil:   coupon.ValidUntil,
+		MaxUses:      coupon.MaxUses,
+		Active:       coupon.Active,
+	}
+	if err := r.db.WithContext(ctx).Create(&couponDTO).Error; err != nil {
+		return entities.Coupon{}, err
+	}
+	return couponDTO.ToDomain(), nil
+}
+
+func (r *DiscountRepository) GetCouponByCode(ctx context.Context, code string) (*dto.CouponDTO, error) {
+	var couponDTO dto.CouponDTO
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&couponDTO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &couponDTO, nil
+}
+
+func (r *DiscountRepository) ListCoupons(ctx context.Context) ([]dto.CouponDTO, error) {
+	var coupons []dto.CouponDTO
+	err := r.db.WithContext(ctx).Order("id").Find(&coupons).Error
+	return coupons, err
+}
+
+func (r *DiscountRepository) UpdateCoupon(ctx context.Context, coupon *entities.Coupon) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.CouponDTO{}).
+		Where("id = ?", coupon.ID).
+		Updates(map[string]interface{}{
+			"description": coupon.Description,
+			"valid_from":  coupon.ValidFrom,
+			"valid_until": coupon.ValidUntil,
+			"max_uses":    coupon.MaxUses,
+			"active":      coupon.Active,
+		}).Error
+}
+
+func (r *DiscountRepository) CreatePriceAgreement(ctx context.Context, agreement *entities.PriceAgreement) (entities.PriceAgreement, error) {
+	agreementDTO := dto.PriceAgreementDTO{
+		CustomerID:    agreement.CustomerID,
+		ServiceID:     agreement.ServiceID,
+		PartsSupplyID: agreement.PartsSupplyID,
+		Price:         agreement.Price,
+		ValidFrom:     agreement.ValidFrom,
+		ValidUntil:    agreement.ValidUntil,
+	}
+	if err := r.db.WithContext(ctx).Create(&agreementDTO).Error; err != nil {
+		return entities.PriceAgreement{}, err
+	}
+	return agreementDTO.ToDomain(), nil
+}
+
+func (r *DiscountRepository) ListPriceAgreementsByCustomer(ctx context.Context, customerID uint) ([]dto.PriceAgreementDTO, error) {
+	var agreements []dto.PriceAgreementDTO
+	err := r.db.WithContext(ctx).Where("customer_id = ?", customerID).Find(&agreements).Error
+	return agreements, err
+}
+
+func (r *DiscountRepository) DeletePriceAgreement(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&dto.PriceAgreementDTO{}, id).Error
+}
@@ -0,0 +1,210 @@
+package financial
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IFinancialRepository interface {
+	SummarizePayments(ctx context.Context, from, to time.Time) ([]entities.PaymentReportLine, error)
+	ListReceivables(ctx context.Context) ([]entities.Receivable, error)
+	SummarizeRevenue(ctx context.Context, from, to time.Time) (*entities.RevenueReport, error)
+	CreateClosing(ctx context.Context, closing *entities.CashClosing, from, to time.Time) (entities.CashClosing, error)
+	GetClosingByDate(ctx context.Context, date time.Time) (*dto.CashClosingDTO, error)
+	ListClosings(ctx context.Context, from, to time.Time) ([]dto.CashClosingDTO, error)
+	ListPaymentsByClosing(ctx context.Context, closingID uint) ([]dto.PaymentDTO, error)
+}
+
+type FinancialRepository struct {
+	db *gorm.DB
+}
+
+var _ IFinancialRepository = (*FinancialRepository)(nil)
+
+func NewFinancialRepository(db *gorm.DB) *FinancialRepository {
+	return &FinancialRepository{db: db}
+}
+
+func (r *FinancialRepository) SummarizePayments(ctx context.Context, from, to time.Time) ([]entities.PaymentReportLine, error) {
+	var rows []struct {
+		Day      time.Time
+		Method   string
+		Operator string
+		Count    int64
+		Amount   float64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&dto.PaymentDTO{}).
+		Select("DATE(payment_date) AS day, method, operator, COUNT(*) AS count, SUM(amount) AS amount").
+		Where("payment_date BETWEEN ? AND ?", from, to).
+		Group("DATE(payment_date), method, operator").
+		Order("day, method, operator").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]entities.PaymentReportLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, entities.PaymentReportLine{
+			Date:     row.Day.Format("2006-01-02"),
+			Method:   valueobject.ParsePaymentMethod(row.Method),
+			Operator: row.Operator,
+			Count:    row.Count,
+			Amount:   row.Amount,
+		})
+	}
+	return lines, nil
+}
+
+// ListReceivables lists the FINALIZADA service orders whose payment is missing or lower than the estimate
+func (r *FinancialRepository) ListReceivables(ctx context.Context) ([]entities.Receivable, error) {
+	var rows []struct {
+		ServiceOrderID     uint
+		CustomerID         uint
+		CustomerName       string
+		VehiclePlate       string
+		FinalExecutionDate *time.Time
+		Estimate           float64
+		Paid               float64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&dto.ServiceOrderDTO{}).
+		Select("service_order_dtos.id AS service_order_id, service_order_dtos.customer_id, tb_customer.fullname AS customer_name, "+
+			"tb_vehicle.plate AS vehicle_plate, service_order_dtos.final_execution_date, service_order_dtos.estimate, "+
+			"COALESCE(payment_dtos.amount, 0) AS paid").
+		Joins("JOIN service_order_status_dtos ON service_order_status_dtos.id = service_order_dtos.os_status_id").
+		Joins("JOIN tb_customer ON tb_customer.id = service_order_dtos.customer_id").
+		Joins("JOIN tb_vehicle ON tb_vehicle.id = service_order_dtos.vehicle_id").
+		Joins("LEFT JOIN payment_dtos ON payment_dtos.service_order_id = service_order_dtos.id").
+		Where("service_order_status_dtos.description = ?", valueobject.StatusFinalizada.String()).
+		Where("COALESCE(payment_dtos.amount, 0) < service_order_dtos.estimate").
+		Order("service_order_dtos.final_execution_date").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	receivables := make([]entities.Receivable, 0, len(rows))
+	for _, row := range rows {
+		receivables = append(receivables, entities.Receivable{
+			ServiceOrderID: row.ServiceOrderID,
+			CustomerID:     row.CustomerID,
+			CustomerName:   row.CustomerName,
+			VehiclePlate:   row.VehiclePlate,
+			FinishedAt:     row.FinalExecutionDate,
+			Estimate:       row.Estimate,
+			Paid:           row.Paid,
+		})
+	}
+	return receivables, nil
+}
+
+// SummarizeRevenue splits the paid amount using the priced line items stored with the estimate:
+// tax lines referencing a service are service revenue, the ones referencing a parts supply are parts revenue
+func (r *FinancialRepository) SummarizeRevenue(ctx context.Context, from, to time.Time) (*entities.RevenueReport, error) {
+	var paid struct {
+		Count int64
+		Total float64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&dto.PaymentDTO{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS total").
+		Where("payment_date BETWEEN ? AND ?", from, to).
+		Scan(&paid).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var split struct {
+		Services float64
+		Parts    float64
+	}
+	err = r.db.WithContext(ctx).
+		Model(&dto.ServiceOrderTaxDTO{}).
+		Select("COALESCE(SUM(CASE WHEN service_order_tax_dtos.service_id IS NOT NULL THEN service_order_tax_dtos.base ELSE 0 END), 0) AS services, "+
+			"COALESCE(SUM(CASE WHEN service_order_tax_dtos.parts_supply_id IS NOT NULL THEN service_order_tax_dtos.base ELSE 0 END), 0) AS parts").
+		Joins("JOIN payment_dtos ON payment_dtos.service_order_id = service_order_tax_dtos.service_order_id").
+		Where("payment_dtos.payment_date BETWEEN ? AND ?", from, to).
+		Scan(&split).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.RevenueReport{
+		From:          from,
+		To:            to,
+		ServiceOrders: paid.Count,
+		Services:      split.Services,
+		Parts:         split.Parts,
+		Total:         paid.Total,
+	}, nil
+}
+
+// CreateClosing stores the closing record and locks the payments received between from and to
+func (r *FinancialRepository) CreateClosing(ctx context.Context, closing *entities.CashClosing, from, to time.Time) (entities.CashClosing, error) {
+	closingDTO := dto.CashClosingDTO{
+		Date:         closing.Date,
+		ClosedBy:     closing.ClosedBy,
+		Notes:        closing.Notes,
+		PaymentCount: closing.PaymentCount,
+		Total:        closing.Total,
+	}
+	for _, line := range closing.Lines {
+		closingDTO.Lines = append(closingDTO.Lines, dto.CashClosingLineDTO{
+			Method:   line.Method.String(),
+			Operator: line.Operator,
+			Count:    line.Count,
+			Amount:   line.Amount,
+		})
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&closingDTO).Error; err != nil {
+			return err
+		}
+		return tx.Model(&dto.PaymentDTO{}).
+			Where("payment_date BETWEEN ? AND ? AND cash_closing_id IS NULL", from, to).
+			Update("cash_closing_id", closingDTO.ID).Error
+	})
+	if err != nil {
+		return entities.CashClosing{}, err
+	}
+	return closingDTO.ToDomain(), nil
+}
+
+func (r *FinancialRepository) GetClosingByDate(ctx context.Context, date time.Time) (*dto.CashClosingDTO, error) {
+	var closing dto.CashClosingDTO
+	err := r.db.WithContext(ctx).Preload("Lines").Where("date = ?", date.Format("2006-01-02")).First(&closing).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &closing, nil
+}
+
+func (r *FinancialRepository) ListClosings(ctx context.Context, from, to time.Time) ([]dto.CashClosingDTO, error) {
+	var closings []dto.CashClosingDTO
+	err := r.db.WithContext(ctx).Preload("Lines").
+		Where("date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("date").
+		Find(&closings).Error
+	return closings, err
+}
+
+func (r *FinancialRepository) ListPaymentsByClosing(ctx context.Context, closingID uint) ([]dto.PaymentDTO, error) {
+	var payments []dto.PaymentDTO
+	err := r.db.WithContext(ctx).Preload("ServiceOrder").
+		Where("cash_closing_id = ?", closingID).
+		Order("payment_date").
+		Find(&payments).Error
+	return payments, err
+}
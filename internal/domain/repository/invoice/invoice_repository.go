@@ -0,0 +1,94 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"gorm.io/gorm"
+)
+
+type IInvoiceRepository interface {
+	Create(ctx context.Context, invoice *entities.Invoice) (entities.Invoice, error)
+	Update(ctx context.Context, invoice *entities.Invoice) error
+	GetByID(ctx context.Context, id uint) (*dto.InvoiceDTO, error)
+	ListByServiceOrderID(ctx context.Context, serviceOrderID uint) ([]dto.InvoiceDTO, error)
+}
+
+type InvoiceRepository struct {
+	db *gorm.DB
+}
+
+var _ IInvoiceRepository = (*InvoiceRepository)(nil)
+
+func NewInvoiceRepository(db *gorm.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *entities.Invoice) (entities.Invoice, error) {
+	invoiceDTO := dto.InvoiceDTO{
+		ServiceOrderID:   invoice.ServiceOrderID,
+		Type:             invoice.Type.String(),
+		Status:           invoice.Status.String(),
+		Series:           invoice.Series,
+		CustomerName:     invoice.CustomerName,
+		CustomerDocument: invoice.CustomerDocument.String(),
+		Total:            invoice.Total,
+		TaxTotal:         invoice.TaxTotal,
+	}
+	for _, item := range invoice.Items {
+		invoiceDTO.Items = append(invoiceDTO.Items, dto.InvoiceItemDTO{
+			Code:        item.Code,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Total:       item.Total,
+			TaxRate:     item.TaxRate,
+			TaxAmount:   item.TaxAmount,
+		})
+	}
+	if err := r.db.WithContext(ctx).Create(&invoiceDTO).Error; err != nil {
+		return entities.Invoice{}, err
+	}
+	return invoiceDTO.ToDomain(), nil
+}
+
+// Update records the outcome of the submission or the cancellation of an invoice
+func (r *InvoiceRepository) Update(ctx context.Context, invoice *entities.Invoice) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.InvoiceDTO{}).
+		Where("id = ?", invoice.ID).
+		Updates(map[string]interface{}{
+			"status":                invoice.Status.String(),
+			"number":                invoice.Number,
+			"protocol":              invoice.Protocol,
+			"xml":                   invoice.XML,
+			"rejection_reason":      invoice.RejectionReason,
+			"issued_at":             invoice.IssuedAt,
+			"cancellation_reason":   invoice.CancellationReason,
+			"cancellation_protocol": invoice.CancellationProtocol,
+			"cancelled_at":          invoice.CancelledAt,
+		}).Error
+}
+
+func (r *InvoiceRepository) GetByID(ctx context.Context, id uint) (*dto.InvoiceDTO, error) {
+	var invoiceDTO dto.InvoiceDTO
+	if err := r.db.WithContext(ctx).Preload("Items").First(&invoiceDTO, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invoiceDTO, nil
+}
+
+func (r *InvoiceRepository) ListByServiceOrderID(ctx context.Context, serviceOrderID uint) ([]dto.InvoiceDTO, error) {
+	var invoices []dto.InvoiceDTO
+	err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("service_order_id = ?", serviceOrderID).
+		Order("id").
+		Find(&invoices).Error
+	return invoices, err
+}
@@ -0,0 +1,110 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"gorm.io/gorm"
+)
+
+type IMaintenanceRepository interface {
+	GetPlanByService(ctx context.Context, serviceID uint) (*dto.MaintenancePlanDTO, error)
+	ListPlans(ctx context.Context) ([]dto.MaintenancePlanDTO, error)
+	SavePlan(ctx context.Context, plan *dto.MaintenancePlanDTO) error
+	DeletePlan(ctx context.Context, id uint) error
+	ListVehiclesWithServices(ctx context.Context, serviceIDs []uint) ([]uint, error)
+	HasReminder(ctx context.Context, vehicleID, planID, serviceOrderID uint) (bool, error)
+	CreateReminder(ctx context.Context, reminder *dto.MaintenanceReminderDTO) error
+}
+
+type MaintenanceRepository struct {
+	db *gorm.DB
+}
+
+var _ IMaintenanceRepository = (*MaintenanceRepository)(nil)
+
+func NewMaintenanceRepository(db *gorm.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+func (r *MaintenanceRepository) GetPlanByService(ctx context.Context, serviceID uint) (*dto.MaintenancePlanDTO, error) {
+	var plan dto.MaintenancePlanDTO
+	err := r.db.WithContext(ctx).Preload("Service").Where("service_id = ?", serviceID).First(&plan).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *MaintenanceRepository) ListPlans(ctx context.Context) ([]dto.MaintenancePlanDTO, error) {
+	var plans []dto.MaintenancePlanDTO
+	if err := r.db.WithContext(ctx).Preload("Service").Order("service_id").Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// SavePlan creates the plan or, when it already exists, updates its intervals
+func (r *MaintenanceRepository) SavePlan(ctx context.Context, plan *dto.MaintenancePlanDTO) error {
+	if plan.ID == 0 {
+		return r.db.WithContext(ctx).Omit("Service").Create(plan).Error
+	}
+	return r.db.WithContext(ctx).Model(&dto.MaintenancePlanDTO{ID: plan.ID}).Updates(map[string]interface{}{
+		"interval_km":     plan.IntervalKm,
+		"interval_months": plan.IntervalMonths,
+	}).Error
+}
+
+// DeletePlan removes the plan together with the record of the reminders sent for it
+func (r *MaintenanceRepository) DeletePlan(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("maintenance_plan_id = ?", id).Delete(&dto.MaintenanceReminderDTO{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&dto.MaintenancePlanDTO{}, id).Error
+	})
+}
+
+// ListVehiclesWithServices returns the vehicles that had any of the services in a service order or
+// in one of its additional repairs, whatever the status of the order
+func (r *MaintenanceRepository) ListVehiclesWithServices(ctx context.Context, serviceIDs []uint) ([]uint, error) {
+	var vehicleIDs []uint
+	if len(serviceIDs) == 0 {
+		return vehicleIDs, nil
+	}
+	err := r.db.WithContext(ctx).
+		Model(&dto.ServiceOrderDTO{}).
+		Distinct("vehicle_id").
+		Where("id IN (?) OR id IN (?)",
+			r.db.Model(&dto.ServiceServiceOrderDTO{}).Select("service_order_id").Where("service_id IN ?", serviceIDs),
+			r.db.Model(&dto.AdditionalRepairDTO{}).
+				Select("additional_repair_dtos.service_order_id").
+				Joins("JOIN service_additional_repair_dtos ON service_additional_repair_dtos.additional_repair_id = additional_repair_dtos.id").
+				Where("service_additional_repair_dtos.service_id IN ?", serviceIDs)).
+		Order("vehicle_id").
+		Pluck("vehicle_id", &vehicleIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return vehicleIDs, nil
+}
+
+func (r *MaintenanceRepository) HasReminder(ctx context.Context, vehicleID, planID, serviceOrderID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&dto.MaintenanceReminderDTO{}).
+		Where("vehicle_id = ? AND maintenance_plan_id = ? AND service_order_id = ?", vehicleID, planID, serviceOrderID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MaintenanceRepository) CreateReminder(ctx context.Context, reminder *dto.MaintenanceReminderDTO) error {
+	return r.db.WithContext(ctx).Create(reminder).Error
+}
@@ -0,0 +1,168 @@
+package mechanic
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IMechanicRepository interface {
+	Create(ctx context.Context, mechanic *dto.MechanicDTO) error
+	GetByID(ctx context.Context, id uint) (*dto.MechanicDTO, error)
+	List(ctx context.Context) ([]dto.MechanicDTO, error)
+	Update(ctx context.Context, mechanic *dto.MechanicDTO) error
+	Delete(ctx context.Context, id uint) error
+	CreateAssignment(ctx context.Context, assignment *dto.MechanicAssignmentDTO) error
+	GetAssignment(ctx context.Context, id uint) (*dto.MechanicAssignmentDTO, error)
+	ListAssignments(ctx context.Context, serviceOrderID uint) ([]dto.MechanicAssignmentDTO, error)
+	DeleteAssignment(ctx context.Context, id uint) error
+	CreateEntry(ctx context.Context, entry *dto.LabourEntryDTO) error
+	GetOpenEntry(ctx context.Context, mechanicID uint) (*dto.LabourEntryDTO, error)
+	CloseEntry(ctx context.Context, entry *dto.LabourEntryDTO) error
+	ListEntries(ctx context.Context, serviceOrderID uint) ([]dto.LabourEntryDTO, error)
+	ListClosedEntries(ctx context.Context, from, to time.Time) ([]dto.LabourEntryDTO, error)
+}
+
+type MechanicRepository struct {
+	db *gorm.DB
+}
+
+var _ IMechanicRepository = (*MechanicRepository)(nil)
+
+func NewMechanicRepository(db *gorm.DB) *MechanicRepository {
+	return &MechanicRepository{db: db}
+}
+
+func (r *MechanicRepository) Create(ctx context.Context, mechanic *dto.MechanicDTO) error {
+	return r.db.WithContext(ctx).Create(mechanic).Error
+}
+
+func (r *MechanicRepository) GetByID(ctx context.Context, id uint) (*dto.MechanicDTO, error) {
+	var mechanic dto.MechanicDTO
+	if err := r.db.WithContext(ctx).First(&mechanic, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &mechanic, nil
+}
+
+func (r *MechanicRepository) List(ctx context.Context) ([]dto.MechanicDTO, error) {
+	var mechanics []dto.MechanicDTO
+	if err := r.db.WithContext(ctx).Order("name").Find(&mechanics).Error; err != nil {
+		return nil, err
+	}
+	return mechanics, nil
+}
+
+func (r *MechanicRepository) Update(ctx context.Context, mechanic *dto.MechanicDTO) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.MechanicDTO{}).
+		Where("id = ?", mechanic.ID).
+		Updates(map[string]interface{}{
+			"name":         mechanic.Name,
+			"email":        mechanic.Email,
+			"phone_number": mechanic.PhoneNumber,
+			"specialty":    mechanic.Specialty,
+			"active":       mechanic.Active,
+		}).Error
+}
+
+// Delete soft deletes the mechanic, keeping the assignments and labour entries of past orders
+func (r *MechanicRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&dto.MechanicDTO{}, id).Error
+}
+
+func (r *MechanicRepository) CreateAssignment(ctx context.Context, assignment *dto.MechanicAssignmentDTO) error {
+	return r.db.WithContext(ctx).Omit("Mechanic").Create(assignment).Error
+}
+
+func (r *MechanicRepository) GetAssignment(ctx context.Context, id uint) (*dto.MechanicAssignmentDTO, error) {
+	var assignment dto.MechanicAssignmentDTO
+	if err := r.db.WithContext(ctx).First(&assignment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+func (r *MechanicRepository) ListAssignments(ctx context.Context, serviceOrderID uint) ([]dto.MechanicAssignmentDTO, error) {
+	var assignments []dto.MechanicAssignmentDTO
+	err := r.db.WithContext(ctx).
+		Preload("Mechanic", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("service_order_id = ?", serviceOrderID).
+		Order("assigned_at, id").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *MechanicRepository) DeleteAssignment(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&dto.MechanicAssignmentDTO{}, id).Error
+}
+
+func (r *MechanicRepository) CreateEntry(ctx context.Context, entry *dto.LabourEntryDTO) error {
+	return r.db.WithContext(ctx).Omit("Service", "Mechanic").Create(entry).Error
+}
+
+// GetOpenEntry returns the entry the mechanic is clocked in on, nil when there is none
+func (r *MechanicRepository) GetOpenEntry(ctx context.Context, mechanicID uint) (*dto.LabourEntryDTO, error) {
+	var entry dto.LabourEntryDTO
+	err := r.db.WithContext(ctx).
+		Where("mechanic_id = ? AND clock_out IS NULL", mechanicID).
+		First(&entry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// CloseEntry records the clock-out of an open entry
+func (r *MechanicRepository) CloseEntry(ctx context.Context, entry *dto.LabourEntryDTO) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.LabourEntryDTO{}).
+		Where("id = ? AND clock_out IS NULL", entry.ID).
+		Updates(map[string]interface{}{
+			"clock_out": entry.ClockOut,
+			"minutes":   entry.Minutes,
+		}).Error
+}
+
+func (r *MechanicRepository) ListEntries(ctx context.Context, serviceOrderID uint) ([]dto.LabourEntryDTO, error) {
+	var entries []dto.LabourEntryDTO
+	err := r.db.WithContext(ctx).
+		Where("service_order_id = ?", serviceOrderID).
+		Order("clock_in, id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ListClosedEntries lists the entries clocked in within the period and already clocked out, with
+// their mechanic and service, deleted ones included
+func (r *MechanicRepository) ListClosedEntries(ctx context.Context, from, to time.Time) ([]dto.LabourEntryDTO, error) {
+	var entries []dto.LabourEntryDTO
+	err := r.db.WithContext(ctx).
+		Preload("Mechanic", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Service", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("clock_in BETWEEN ? AND ? AND clock_out IS NOT NULL", from, to).
+		Order("mechanic_id, clock_in").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type INotificationRepository interface {
+	Create(ctx context.Context, delivery *dto.NotificationDeliveryDTO) error
+	Update(ctx context.Context, delivery *dto.NotificationDeliveryDTO) error
+	ListDue(ctx context.Context, now time.Time, limit int) ([]dto.NotificationDeliveryDTO, error)
+	ListByServiceOrderID(ctx context.Context, serviceOrderID uint) ([]dto.NotificationDeliveryDTO, error)
+}
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+var _ INotificationRepository = (*NotificationRepository)(nil)
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create queues a delivery. A message already queued on the channel for the same domain event,
+// which happens when the dispatcher delivers the event again, is left as it is.
+func (r *NotificationRepository) Create(ctx context.Context, delivery *dto.NotificationDeliveryDTO) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(delivery).Error
+}
+
+// Update records the outcome of a delivery attempt
+func (r *NotificationRepository) Update(ctx context.Context, delivery *dto.NotificationDeliveryDTO) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.NotificationDeliveryDTO{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"last_error":      delivery.LastError,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"sent_at":         delivery.SentAt,
+		}).Error
+}
+
+// ListDue lists the pending deliveries whose next attempt is due, oldest first
+func (r *NotificationRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]dto.NotificationDeliveryDTO, error) {
+	var deliveries []dto.NotificationDeliveryDTO
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", valueobject.NotificationPending.String(), now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *NotificationRepository) ListByServiceOrderID(ctx context.Context, serviceOrderID uint) ([]dto.NotificationDeliveryDTO, error) {
+	var deliveries []dto.NotificationDeliveryDTO
+	err := r.db.WithContext(ctx).
+		Where("service_order_id = ?", serviceOrderID).
+		Order("created_at, id").
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
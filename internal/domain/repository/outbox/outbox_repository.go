@@ -0,0 +1,85 @@
+package outbox
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IOutboxRepository interface {
+	ListDue(ctx context.Context, now time.Time, limit int) ([]dto.OutboxEventDTO, error)
+	MarkDispatched(ctx context.Context, id uint, attempts int, dispatchedAt time.Time) error
+	MarkFailed(ctx context.Context, id uint, attempts int, lastError string, nextAttemptAt time.Time) error
+}
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+var _ IOutboxRepository = (*OutboxRepository)(nil)
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Append writes events to the outbox using the transaction of the change that raised them, so
+// either both are stored or neither is. Events without an aggregate get aggregateID, the ID of
+// the aggregate the transaction has just created.
+func Append(tx *gorm.DB, aggregateID uint, events []entities.DomainEvent) error {
+	for _, event := range events {
+		if event.AggregateID == 0 {
+			event.AggregateID = aggregateID
+			if event.AggregateType == entities.AggregateServiceOrder {
+				event.ServiceOrderID = aggregateID
+			}
+		}
+		outboxEvent, err := dto.NewOutboxEventDTO(event)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(outboxEvent).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListDue lists the events not yet delivered whose next attempt is due, in the order they were stored
+func (r *OutboxRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]dto.OutboxEventDTO, error) {
+	var events []dto.OutboxEventDTO
+	err := r.db.WithContext(ctx).
+		Where("dispatched_at IS NULL AND next_attempt_at <= ?", now).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkDispatched(ctx context.Context, id uint, attempts int, dispatchedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.OutboxEventDTO{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      "",
+			"next_attempt_at": nil,
+			"dispatched_at":   dispatchedAt,
+		}).Error
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint, attempts int, lastError string, nextAttemptAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.OutboxEventDTO{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      lastError,
+			"next_attempt_at": nextAttemptAt,
+		}).Error
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"mecanica_xpto/internal/domain/model/dto"
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/repository/outbox"
 
 	"gorm.io/gorm"
 )
@@ -36,6 +37,7 @@ func (s *PartsSupplyRepository) Create(ctx context.Context, ps *entities.PartsSu
 		Name:            ps.Name,
 		Description:     ps.Description,
 		Price:           ps.Price,
+		Category:        ps.Category,
 		QuantityTotal:   ps.QuantityTotal,
 		QuantityReserve: ps.QuantityReserve,
 	}
@@ -83,6 +85,9 @@ func (s *PartsSupplyRepository) Update(ctx context.Context, ps *entities.PartsSu
 	if ps.Price != 0 {
 		updates["price"] = ps.Price
 	}
+	if ps.Category != "" {
+		updates["category"] = ps.Category
+	}
 	if ps.QuantityTotal != 0 {
 		updates["quantity_total"] = ps.QuantityTotal
 	}
@@ -90,14 +95,18 @@ func (s *PartsSupplyRepository) Update(ctx context.Context, ps *entities.PartsSu
 		updates["quantity_reserve"] = ps.QuantityReserve
 	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && len(ps.Events) == 0 {
 		return nil
 	}
 
-	return s.db.WithContext(ctx).
-		Model(&dto.PartsSupplyDTO{}).
-		Where("id = ?", ps.ID).
-		Updates(updates).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&dto.PartsSupplyDTO{}).Where("id = ?", ps.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return outbox.Append(tx, ps.ID, ps.Events)
+	})
 }
 
 func (s *PartsSupplyRepository) Delete(ctx context.Context, id uint) error {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"mecanica_xpto/internal/domain/model/dto"
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/outbox"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,6 +16,7 @@ type IPaymentRepo interface {
 	GetByID(ctx context.Context, id uint) (*dto.PaymentDTO, error)
 	GetByServiceOrderID(ctx context.Context, serviceOrderID uint) (*dto.PaymentDTO, error)
 	List(ctx context.Context) ([]dto.PaymentDTO, error)
+	UpdateMethod(ctx context.Context, id uint, method valueobject.PaymentMethod) error
 }
 
 type PaymentRepository struct {
@@ -31,8 +34,19 @@ func (p *PaymentRepository) Create(ctx context.Context, payment *entities.Paymen
 		ServiceOrderID: payment.ServiceOrderID,
 		PaymentDate:    time.Now(),
 		Amount:         payment.Amount,
+		Method:         payment.Method.String(),
+		Operator:       payment.Operator,
+		ISSAmount:      payment.ISSAmount,
+		ICMSAmount:     payment.ICMSAmount,
+		TaxTotal:       payment.TaxTotal,
 	}
-	if err := p.db.Create(&dto).Error; err != nil {
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&dto).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, dto.ID, payment.Events)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &dto, nil
@@ -61,3 +75,7 @@ func (p *PaymentRepository) List(ctx context.Context) ([]dto.PaymentDTO, error)
 	}
 	return dtos, nil
 }
+
+func (p *PaymentRepository) UpdateMethod(ctx context.Context, id uint, method valueobject.PaymentMethod) error {
+	return p.db.WithContext(ctx).Model(&dto.PaymentDTO{}).Where("id = ?", id).Update("method", method.String()).Error
+}
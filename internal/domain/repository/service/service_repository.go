@@ -29,9 +29,11 @@ func NewServiceRepository(db *gorm.DB) *ServiceRepository {
 
 func (s *ServiceRepository) Create(ctx context.Context, service *entities.Service) (entities.Service, error) {
 	dto := dto.ServiceDTO{
-		Name:        service.Name,
-		Description: service.Description,
-		Price:       service.Price,
+		Name:            service.Name,
+		Description:     service.Description,
+		Price:           service.Price,
+		Category:        service.Category,
+		StandardMinutes: service.StandardMinutes,
 	}
 
 	if err := s.db.Create(&dto).Error; err != nil {
@@ -77,6 +79,12 @@ func (s *ServiceRepository) Update(ctx context.Context, service *entities.Servic
 	if service.Price != 0 {
 		updates["price"] = service.Price
 	}
+	if service.Category != "" {
+		updates["category"] = service.Category
+	}
+	if service.StandardMinutes != 0 {
+		updates["standard_minutes"] = service.StandardMinutes
+	}
 
 	return s.db.WithContext(ctx).
 		Model(&dto.ServiceDTO{}).
@@ -1,23 +1,33 @@
 package serviceorder
 
 import (
+	"errors"
 	"mecanica_xpto/internal/domain/model/dto"
 	"mecanica_xpto/internal/domain/model/entities"
 	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/outbox"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrCouponUsedUp is returned when the coupon applied to the estimate reached its usage limit
+var ErrCouponUsedUp = errors.New("the coupon reached its usage limit")
+
 type IServiceOrderRepository interface {
 	Create(serviceOrder *entities.ServiceOrder) (*entities.ServiceOrder, error)
 	GetByID(id uint) (*dto.ServiceOrderDTO, error)
+	GetByIDWithItems(id uint) (*dto.ServiceOrderDTO, error)
 	Update(serviceOrder *entities.ServiceOrder) error
 	List() ([]dto.ServiceOrderDTO, error)
+	ListByVehicle(vehicleID uint) ([]dto.ServiceOrderDTO, error)
 	GetStatus(status valueobject.ServiceOrderStatus) (*dto.ServiceOrderStatusDTO, error)
 	GetPartsSupplyServiceOrder(partsSupplyID uint, serviceOrderID uint) (*dto.PartsSupplyServiceOrderDTO, error)
 	UpdateEstimate(id uint, estimate float64) error
+	ApproveDiscount(id uint, approvedBy string, approvedAt time.Time) error
 }
 
 // ServiceOrderRepository implements IServiceOrderRepository interface
@@ -63,6 +73,11 @@ func (r *ServiceOrderRepository) Create(serviceOrder *entities.ServiceOrder) (*e
 		return nil, err
 	}
 
+	if err := outbox.Append(tx, serviceOrderDto.ID, serviceOrder.Events); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
@@ -79,8 +94,10 @@ func (r *ServiceOrderRepository) GetByID(id uint) (*dto.ServiceOrderDTO, error)
 		Preload("Customer.User").
 		Preload("Vehicle").
 		Preload("ServiceOrderStatus").
-		Preload("AdditionalRepairs").
+		Preload("AdditionalRepairs.ARStatus").
 		Preload("Payment").
+		Preload("Coupon").
+		Preload("Taxes").
 		//Preload("PartsSupplies").
 		//Preload("Services").
 		// Preloading "PartsSupplies" and "Services" is intentionally omitted for now; see TODO above for evaluation.
@@ -95,6 +112,36 @@ func (r *ServiceOrderRepository) GetByID(id uint) (*dto.ServiceOrderDTO, error)
 	return &serviceOrder, nil
 }
 
+// GetByIDWithItems loads the service order along with its services and parts supplies, and its
+// additional repairs with theirs, for the use cases that need the line items (e.g. invoices and documents)
+func (r *ServiceOrderRepository) GetByIDWithItems(id uint) (*dto.ServiceOrderDTO, error) {
+	var serviceOrder dto.ServiceOrderDTO
+	err := r.db.Preload("Customer").
+		Preload("Customer.User").
+		Preload("Vehicle").
+		Preload("ServiceOrderStatus").
+		Preload("Payment").
+		Preload("Coupon").
+		Preload("Taxes").
+		Preload("PartsSupplies").
+		Preload("Services").
+		Preload("AdditionalRepairs", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("AdditionalRepairs.ARStatus").
+		Preload("AdditionalRepairs.PartsSupplies").
+		Preload("AdditionalRepairs.PartsSupplyItems").
+		Preload("AdditionalRepairs.Services").
+		Preload("AdditionalRepairs.ServiceItems").
+		First(&serviceOrder, id).Error
+	if err != nil {
+		log.Error().Msgf("Error finding service order with id %d: %v", id, err)
+		if strings.EqualFold(err.Error(), gorm.ErrRecordNotFound.Error()) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &serviceOrder, nil
+}
+
 func (r *ServiceOrderRepository) UpdateEstimate(id uint, estimate float64) error {
 	var dtoDB dto.ServiceOrderDTO
 	if err := r.db.First(&dtoDB, id).Error; err != nil {
@@ -107,6 +154,123 @@ func (r *ServiceOrderRepository) UpdateEstimate(id uint, estimate float64) error
 		Update("estimate", newEstimate).Error
 }
 
+// savePricing stores the discount totals of a priced estimate, resetting any previous discount
+// approval. A coupon newly applied to the order consumes one of its uses and the one it replaces
+// gets its use back.
+func savePricing(tx *gorm.DB, id uint, pricing entities.EstimateBreakdown) error {
+	var current dto.ServiceOrderDTO
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "coupon_id").First(&current, id).Error; err != nil {
+		return err
+	}
+	if !sameCoupon(current.CouponID, pricing.CouponID) {
+		if current.CouponID != nil {
+			if err := giveCouponUseBack(tx, *current.CouponID); err != nil {
+				return err
+			}
+		}
+		if pricing.CouponID != nil {
+			result := tx.Model(&dto.CouponDTO{}).
+				Where("id = ? AND (max_uses = 0 OR used_count < max_uses)", *pricing.CouponID).
+				Update("used_count", gorm.Expr("used_count + 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrCouponUsedUp
+			}
+		}
+	}
+
+	updates := map[string]interface{}{
+		"gross_estimate":       pricing.Gross,
+		"discount_total":       pricing.DiscountTotal,
+		"coupon_id":            pricing.CouponID,
+		"discount_pending":     pricing.ApprovalRequired,
+		"discount_approved_by": "",
+		"discount_approved_at": nil,
+		"iss_total":            0,
+		"icms_total":           0,
+		"tax_total":            0,
+	}
+	if pricing.Taxes != nil {
+		updates["iss_total"] = pricing.Taxes.ISSTotal
+		updates["icms_total"] = pricing.Taxes.ICMSTotal
+		updates["tax_total"] = pricing.Taxes.TaxTotal
+	}
+	if err := tx.Model(&dto.ServiceOrderDTO{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// Tax lines are recalculated with every estimate, so the previous ones are replaced
+	if err := tx.Where("service_order_id = ?", id).Delete(&dto.ServiceOrderTaxDTO{}).Error; err != nil {
+		return err
+	}
+	if pricing.Taxes == nil || len(pricing.Taxes.Lines) == 0 {
+		return nil
+	}
+	taxes := make([]dto.ServiceOrderTaxDTO, 0, len(pricing.Taxes.Lines))
+	for _, line := range pricing.Taxes.Lines {
+		tax := dto.ServiceOrderTaxDTO{
+			ServiceOrderID: id,
+			TaxType:        line.TaxType.String(),
+			Category:       line.Category,
+			Base:           line.Base,
+			Rate:           line.Rate,
+			Amount:         line.Amount,
+		}
+		if line.ServiceID != 0 {
+			serviceID := line.ServiceID
+			tax.ServiceID = &serviceID
+		}
+		if line.PartsSupplyID != 0 {
+			partsSupplyID := line.PartsSupplyID
+			tax.PartsSupplyID = &partsSupplyID
+		}
+		taxes = append(taxes, tax)
+	}
+	return tx.Create(&taxes).Error
+}
+
+// releaseCoupon takes the coupon off the order and gives its use back
+func releaseCoupon(tx *gorm.DB, id uint) error {
+	var current dto.ServiceOrderDTO
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "coupon_id").First(&current, id).Error; err != nil {
+		return err
+	}
+	if current.CouponID == nil {
+		return nil
+	}
+	if err := giveCouponUseBack(tx, *current.CouponID); err != nil {
+		return err
+	}
+	return tx.Model(&dto.ServiceOrderDTO{}).Where("id = ?", id).Update("coupon_id", nil).Error
+}
+
+func giveCouponUseBack(tx *gorm.DB, couponID uint) error {
+	return tx.Model(&dto.CouponDTO{}).
+		Where("id = ? AND used_count > 0", couponID).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+func sameCoupon(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (r *ServiceOrderRepository) ApproveDiscount(id uint, approvedBy string, approvedAt time.Time) error {
+	return r.db.Model(&dto.ServiceOrderDTO{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"discount_pending":     false,
+			"discount_approved_by": approvedBy,
+			"discount_approved_at": approvedAt,
+		}).Error
+}
+
+// Update stores the order, along with its services and parts supplies and the pricing of the
+// estimate when they are set, the release of its coupon and the events, in one transaction
 func (r *ServiceOrderRepository) Update(serviceOrder *entities.ServiceOrder) error {
 	if serviceOrder == nil {
 		return gorm.ErrInvalidData
@@ -127,6 +291,8 @@ func (r *ServiceOrderRepository) Update(serviceOrder *entities.ServiceOrder) err
 		Estimate:             serviceOrder.Estimate,
 		StartedExecutionDate: serviceOrder.StartedExecutionDate,
 		FinalExecutionDate:   serviceOrder.FinalExecutionDate,
+		DeliveredAt:          serviceOrder.DeliveredAt,
+		ApproverContactID:    serviceOrder.ApproverContactID,
 	}
 
 	if err := tx.Model(&dto.ServiceOrderDTO{}).Where("id = ?", serviceOrder.ID).Updates(&serviceOrderDto).Error; err != nil {
@@ -134,41 +300,70 @@ func (r *ServiceOrderRepository) Update(serviceOrder *entities.ServiceOrder) err
 		return err
 	}
 
-	// Update PartsSupplies relationships
-	if err := tx.Where("service_order_id = ?", serviceOrder.ID).Delete(&dto.PartsSupplyServiceOrderDTO{}).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+	// The services and parts supplies, with their discounts, are only replaced when the order
+	// carries them, as the diagnosis does. A status change leaves them as they are.
+	if serviceOrder.PartsSupplies != nil {
+		if err := tx.Where("service_order_id = ?", serviceOrder.ID).Delete(&dto.PartsSupplyServiceOrderDTO{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
 
-	for _, partsSupply := range serviceOrder.PartsSupplies {
-		relation := dto.PartsSupplyServiceOrderDTO{
-			PartsSupplyID:  partsSupply.ID,
-			ServiceOrderID: serviceOrder.ID,
-			Quantity:       partsSupply.QuantityReserve,
+		for _, partsSupply := range serviceOrder.PartsSupplies {
+			relation := dto.PartsSupplyServiceOrderDTO{
+				PartsSupplyID:  partsSupply.ID,
+				ServiceOrderID: serviceOrder.ID,
+				Quantity:       partsSupply.QuantityReserve,
+			}
+			if partsSupply.Discount != nil {
+				relation.Discount = partsSupply.Discount.Amount
+			}
+			if err := tx.Create(&relation).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
 		}
-		if err := tx.Create(&relation).Error; err != nil {
+	}
+
+	if serviceOrder.Services != nil {
+		if err := tx.Where("service_order_id = ?", serviceOrder.ID).Delete(&dto.ServiceServiceOrderDTO{}).Error; err != nil {
 			tx.Rollback()
 			return err
 		}
-	}
 
-	// Update Services relationships
-	if err := tx.Where("service_order_id = ?", serviceOrder.ID).Delete(&dto.ServiceServiceOrderDTO{}).Error; err != nil {
-		tx.Rollback()
-		return err
+		for _, service := range serviceOrder.Services {
+			relation := dto.ServiceServiceOrderDTO{
+				ServiceID:      service.ID,
+				ServiceOrderID: serviceOrder.ID,
+			}
+			if service.Discount != nil {
+				relation.Discount = service.Discount.Amount
+			}
+			if err := tx.Create(&relation).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
 	}
 
-	for _, service := range serviceOrder.Services {
-		relation := dto.ServiceServiceOrderDTO{
-			ServiceID:      service.ID,
-			ServiceOrderID: serviceOrder.ID,
+	if serviceOrder.Pricing != nil {
+		if err := savePricing(tx, serviceOrder.ID, *serviceOrder.Pricing); err != nil {
+			tx.Rollback()
+			return err
 		}
-		if err := tx.Create(&relation).Error; err != nil {
+	}
+
+	if serviceOrder.ReleaseCoupon {
+		if err := releaseCoupon(tx, serviceOrder.ID); err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
 
+	if err := outbox.Append(tx, serviceOrder.ID, serviceOrder.Events); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
@@ -180,8 +375,10 @@ func (r *ServiceOrderRepository) List() ([]dto.ServiceOrderDTO, error) {
 		Preload("Customer.User").
 		Preload("Vehicle").
 		Preload("ServiceOrderStatus").
-		Preload("AdditionalRepairs").
+		Preload("AdditionalRepairs.ARStatus").
 		Preload("Payment").
+		Preload("Coupon").
+		Preload("Taxes").
 		// Preload("PartsSupplies").
 		// Preload("Services").
 		// Preloading "PartsSupplies" and "Services" is intentionally omitted for now; see TODO above for evaluation.
@@ -189,6 +386,25 @@ func (r *ServiceOrderRepository) List() ([]dto.ServiceOrderDTO, error) {
 	return serviceOrders, err
 }
 
+// ListByVehicle returns the service orders of a vehicle, oldest first, with the services and parts
+// of the orders and of their additional repairs
+func (r *ServiceOrderRepository) ListByVehicle(vehicleID uint) ([]dto.ServiceOrderDTO, error) {
+	var serviceOrders []dto.ServiceOrderDTO
+	err := r.db.
+		Preload("ServiceOrderStatus").
+		Preload("Services").
+		Preload("PartsSupplies").
+		Preload("PartsSupplyItems").
+		Preload("AdditionalRepairs.ARStatus").
+		Preload("AdditionalRepairs.Services").
+		Preload("AdditionalRepairs.PartsSupplies").
+		Preload("AdditionalRepairs.PartsSupplyItems").
+		Where("vehicle_id = ?", vehicleID).
+		Order("created_at, id").
+		Find(&serviceOrders).Error
+	return serviceOrders, err
+}
+
 func (r *ServiceOrderRepository) GetStatus(status valueobject.ServiceOrderStatus) (*dto.ServiceOrderStatusDTO, error) {
 	var serviceOrderStatuses dto.ServiceOrderStatusDTO
 	err := r.db.Where("description = ?", status.String()).First(&serviceOrderStatuses).Error
@@ -0,0 +1,81 @@
+package staff
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"gorm.io/gorm"
+)
+
+type IStaffRepository interface {
+	Create(ctx context.Context, staff *dto.StaffDTO) error
+	GetByID(ctx context.Context, id uint) (*dto.StaffDTO, error)
+	List(ctx context.Context) ([]dto.StaffDTO, error)
+	Update(ctx context.Context, staff *dto.StaffDTO) error
+	Delete(ctx context.Context, staff *dto.StaffDTO) error
+	EmailExists(ctx context.Context, email string) (bool, error)
+}
+
+type StaffRepository struct {
+	db *gorm.DB
+}
+
+var _ IStaffRepository = (*StaffRepository)(nil)
+
+func NewStaffRepository(db *gorm.DB) *StaffRepository {
+	return &StaffRepository{db: db}
+}
+
+// Create stores the employee together with their user
+func (r *StaffRepository) Create(ctx context.Context, staff *dto.StaffDTO) error {
+	return r.db.WithContext(ctx).Create(staff).Error
+}
+
+func (r *StaffRepository) GetByID(ctx context.Context, id uint) (*dto.StaffDTO, error) {
+	var staff dto.StaffDTO
+	if err := r.db.WithContext(ctx).Preload("User").First(&staff, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &staff, nil
+}
+
+func (r *StaffRepository) List(ctx context.Context) ([]dto.StaffDTO, error) {
+	var staff []dto.StaffDTO
+	if err := r.db.WithContext(ctx).Preload("User").Order("full_name").Find(&staff).Error; err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// Update saves the employee and their user in the same transaction
+func (r *StaffRepository) Update(ctx context.Context, staff *dto.StaffDTO) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(staff.User).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User").Save(staff).Error
+	})
+}
+
+// Delete removes the employee and their user, so they can no longer log in
+func (r *StaffRepository) Delete(ctx context.Context, staff *dto.StaffDTO) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&dto.StaffDTO{}, staff.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&dto.UserDTO{}, staff.UserID).Error
+	})
+}
+
+// EmailExists tells whether a user, deleted or not, already has the e-mail, since the column is unique
+func (r *StaffRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Unscoped().Model(&dto.UserDTO{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,137 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ITaxRepository interface {
+	CreateRule(ctx context.Context, rule *entities.TaxRule) (entities.TaxRule, error)
+	GetRuleByID(ctx context.Context, id uint) (*dto.TaxRuleDTO, error)
+	GetRule(ctx context.Context, taxType valueobject.TaxType, category string) (*dto.TaxRuleDTO, error)
+	ListRules(ctx context.Context) ([]dto.TaxRuleDTO, error)
+	UpdateRule(ctx context.Context, rule *entities.TaxRule) error
+	DeleteRule(ctx context.Context, id uint) error
+	SummarizeByPaymentPeriod(ctx context.Context, from, to time.Time) ([]entities.TaxReportLine, error)
+	GetPaidRevenue(ctx context.Context, from, to time.Time) (int64, float64, error)
+}
+
+type TaxRepository struct {
+	db *gorm.DB
+}
+
+var _ ITaxRepository = (*TaxRepository)(nil)
+
+func NewTaxRepository(db *gorm.DB) *TaxRepository {
+	return &TaxRepository{db: db}
+}
+
+func (r *TaxRepository) CreateRule(ctx context.Context, rule *entities.TaxRule) (entities.TaxRule, error) {
+	ruleDTO := dto.TaxRuleDTO{
+		TaxType:     rule.TaxType.String(),
+		Category:    rule.Category,
+		Rate:        rule.Rate,
+		Description: rule.Description,
+	}
+	if err := r.db.WithContext(ctx).Create(&ruleDTO).Error; err != nil {
+		return entities.TaxRule{}, err
+	}
+	return ruleDTO.ToDomain(), nil
+}
+
+func (r *TaxRepository) GetRuleByID(ctx context.Context, id uint) (*dto.TaxRuleDTO, error) {
+	var ruleDTO dto.TaxRuleDTO
+	if err := r.db.WithContext(ctx).First(&ruleDTO, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ruleDTO, nil
+}
+
+func (r *TaxRepository) GetRule(ctx context.Context, taxType valueobject.TaxType, category string) (*dto.TaxRuleDTO, error) {
+	var ruleDTO dto.TaxRuleDTO
+	err := r.db.WithContext(ctx).
+		Where("tax_type = ? AND category = ?", taxType.String(), category).
+		First(&ruleDTO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ruleDTO, nil
+}
+
+func (r *TaxRepository) ListRules(ctx context.Context) ([]dto.TaxRuleDTO, error) {
+	var rules []dto.TaxRuleDTO
+	err := r.db.WithContext(ctx).Order("tax_type, category").Find(&rules).Error
+	return rules, err
+}
+
+func (r *TaxRepository) UpdateRule(ctx context.Context, rule *entities.TaxRule) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.TaxRuleDTO{}).
+		Where("id = ?", rule.ID).
+		Updates(map[string]interface{}{
+			"rate":        rule.Rate,
+			"description": rule.Description,
+		}).Error
+}
+
+func (r *TaxRepository) DeleteRule(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&dto.TaxRuleDTO{}, id).Error
+}
+
+// SummarizeByPaymentPeriod adds up the tax lines of the service orders paid within the period, by tax type and category
+func (r *TaxRepository) SummarizeByPaymentPeriod(ctx context.Context, from, to time.Time) ([]entities.TaxReportLine, error) {
+	var rows []struct {
+		TaxType  string
+		Category string
+		Base     float64
+		Amount   float64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&dto.ServiceOrderTaxDTO{}).
+		Select("service_order_tax_dtos.tax_type, service_order_tax_dtos.category, SUM(service_order_tax_dtos.base) AS base, SUM(service_order_tax_dtos.amount) AS amount").
+		Joins("JOIN payment_dtos ON payment_dtos.service_order_id = service_order_tax_dtos.service_order_id").
+		Where("payment_dtos.payment_date BETWEEN ? AND ?", from, to).
+		Group("service_order_tax_dtos.tax_type, service_order_tax_dtos.category").
+		Order("service_order_tax_dtos.tax_type, service_order_tax_dtos.category").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]entities.TaxReportLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, entities.TaxReportLine{
+			TaxType:  valueobject.ParseTaxType(row.TaxType),
+			Category: row.Category,
+			Base:     row.Base,
+			Amount:   row.Amount,
+		})
+	}
+	return lines, nil
+}
+
+// GetPaidRevenue returns how many service orders were paid within the period and the amount received
+func (r *TaxRepository) GetPaidRevenue(ctx context.Context, from, to time.Time) (int64, float64, error) {
+	var row struct {
+		Count   int64
+		Revenue float64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&dto.PaymentDTO{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS revenue").
+		Where("payment_date BETWEEN ? AND ?", from, to).
+		Scan(&row).Error
+	return row.Count, row.Revenue, err
+}
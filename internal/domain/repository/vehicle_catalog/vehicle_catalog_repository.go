@@ -0,0 +1,97 @@
+package vehiclecatalog
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// importBatchSize keeps the statements of a FIPE dump, with tens of thousands of model years,
+// under the parameter limit of the database
+const importBatchSize = 500
+
+type IVehicleCatalogRepository interface {
+	Import(ctx context.Context, models []dto.VehicleModelDTO) error
+	Count(ctx context.Context) (int64, error)
+	ListBrands(ctx context.Context) ([]string, error)
+	ListModels(ctx context.Context, brand, model string) ([]dto.VehicleModelDTO, error)
+	FindModel(ctx context.Context, brand, model string, year int) (*dto.VehicleModelDTO, error)
+}
+
+type VehicleCatalogRepository struct {
+	db *gorm.DB
+}
+
+var _ IVehicleCatalogRepository = (*VehicleCatalogRepository)(nil)
+
+func NewVehicleCatalogRepository(db *gorm.DB) *VehicleCatalogRepository {
+	return &VehicleCatalogRepository{db: db}
+}
+
+// Import adds the model years to the catalog. A model year already in the catalog only has its
+// FIPE code updated, so a newer dump can be imported over an older one.
+func (r *VehicleCatalogRepository) Import(ctx context.Context, models []dto.VehicleModelDTO) error {
+	if len(models) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "brand"}, {Name: "model"}, {Name: "year"}},
+			DoUpdates: clause.AssignmentColumns([]string{"fipe_code", "updated_at"}),
+		}).
+		CreateInBatches(&models, importBatchSize).Error
+}
+
+func (r *VehicleCatalogRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&dto.VehicleModelDTO{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *VehicleCatalogRepository) ListBrands(ctx context.Context) ([]string, error) {
+	var brands []string
+	err := r.db.WithContext(ctx).
+		Model(&dto.VehicleModelDTO{}).
+		Distinct("brand").
+		Order("brand").
+		Pluck("brand", &brands).Error
+	if err != nil {
+		return nil, err
+	}
+	return brands, nil
+}
+
+// ListModels lists the model years of the brand, narrowed down to a model when one is given. Both
+// are matched regardless of case.
+func (r *VehicleCatalogRepository) ListModels(ctx context.Context, brand, model string) ([]dto.VehicleModelDTO, error) {
+	query := r.db.WithContext(ctx).Where("LOWER(brand) = LOWER(?)", brand)
+	if model != "" {
+		query = query.Where("LOWER(model) = LOWER(?)", model)
+	}
+
+	var models []dto.VehicleModelDTO
+	if err := query.Order("model, year").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// FindModel finds the model year regardless of the case of the brand and the model
+func (r *VehicleCatalogRepository) FindModel(ctx context.Context, brand, model string, year int) (*dto.VehicleModelDTO, error) {
+	var vehicleModel dto.VehicleModelDTO
+	err := r.db.WithContext(ctx).
+		Where("LOWER(brand) = LOWER(?) AND LOWER(model) = LOWER(?) AND year = ?", brand, model, year).
+		First(&vehicleModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &vehicleModel, nil
+}
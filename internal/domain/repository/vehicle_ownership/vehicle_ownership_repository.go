@@ -0,0 +1,80 @@
+package vehicleownership
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"gorm.io/gorm"
+)
+
+// ErrOwnerChanged is returned when the vehicle was transferred by someone else in the meantime
+var ErrOwnerChanged = errors.New("the owner of the vehicle changed during the transfer")
+
+type IVehicleOwnershipRepository interface {
+	ListByVehicle(ctx context.Context, vehicleID uint) ([]dto.VehicleOwnershipDTO, error)
+	Transfer(ctx context.Context, vehicle *dto.VehicleDTO, ownership *dto.VehicleOwnershipDTO) error
+}
+
+type VehicleOwnershipRepository struct {
+	db *gorm.DB
+}
+
+var _ IVehicleOwnershipRepository = (*VehicleOwnershipRepository)(nil)
+
+func NewVehicleOwnershipRepository(db *gorm.DB) *VehicleOwnershipRepository {
+	return &VehicleOwnershipRepository{db: db}
+}
+
+// ListByVehicle returns the owners of the vehicle since its ownership was first recorded, oldest first
+func (r *VehicleOwnershipRepository) ListByVehicle(ctx context.Context, vehicleID uint) ([]dto.VehicleOwnershipDTO, error) {
+	var ownerships []dto.VehicleOwnershipDTO
+	err := r.db.WithContext(ctx).
+		Preload("Customer").
+		Where("vehicle_id = ?", vehicleID).
+		Order("started_at, id").
+		Find(&ownerships).Error
+	if err != nil {
+		return nil, err
+	}
+	return ownerships, nil
+}
+
+// Transfer hands the vehicle over to the owner of the new ownership, which starts when the current
+// one ends. The current owner of a vehicle registered before ownerships were recorded gets a
+// record starting when the vehicle was registered. The service orders keep the customer they were
+// opened for.
+func (r *VehicleOwnershipRepository) Transfer(ctx context.Context, vehicle *dto.VehicleDTO, ownership *dto.VehicleOwnershipDTO) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dto.VehicleOwnershipDTO{}).
+			Where("vehicle_id = ? AND ended_at IS NULL", vehicle.ID).
+			Update("ended_at", ownership.StartedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			previous := dto.VehicleOwnershipDTO{
+				VehicleID:  vehicle.ID,
+				CustomerID: vehicle.CustomerID,
+				StartedAt:  vehicle.CreatedAt,
+				EndedAt:    &ownership.StartedAt,
+			}
+			if err := tx.Create(&previous).Error; err != nil {
+				return err
+			}
+		}
+
+		result = tx.Model(&dto.VehicleDTO{}).
+			Where("id = ? AND customer_id = ?", vehicle.ID, vehicle.CustomerID).
+			Update("customer_id", ownership.CustomerID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOwnerChanged
+		}
+
+		ownership.VehicleID = vehicle.ID
+		return tx.Omit("Customer").Create(ownership).Error
+	})
+}
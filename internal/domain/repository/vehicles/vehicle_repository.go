@@ -15,6 +15,7 @@ type VehicleRepositoryInterface interface {
 	FindByPlate(plate valueobject.Plate) (*dto.VehicleDTO, error)
 	FindByCustomerID(customerID uint) ([]dto.VehicleDTO, error)
 	Create(vehicle entities.Vehicle) error
+	CreateBatch(vehicles []entities.Vehicle) error
 	Update(vehicle entities.Vehicle) error
 	Delete(id uint) error
 }
@@ -50,9 +51,16 @@ func (r *VehicleRepository) FindByID(id uint) (*dto.VehicleDTO, error) {
 	return &vehicleDTO, nil
 }
 
+// FindByPlate finds the vehicle by its plate in either format, so a vehicle registered with the old
+// plate is found by the Mercosul one and the other way around
 func (r *VehicleRepository) FindByPlate(plate valueobject.Plate) (*dto.VehicleDTO, error) {
+	var plates []string
+	for _, equivalent := range plate.Equivalents() {
+		plates = append(plates, equivalent.String())
+	}
+
 	var vehicleDTO dto.VehicleDTO
-	if err := r.db.Preload("Customer").Where("plate = ?", plate.String()).First(&vehicleDTO).Error; err != nil {
+	if err := r.db.Preload("Customer").Where("plate IN ?", plates).First(&vehicleDTO).Error; err != nil {
 		if strings.EqualFold(err.Error(), gorm.ErrRecordNotFound.Error()) {
 			return nil, nil
 		}
@@ -81,6 +89,7 @@ func (r *VehicleRepository) Create(vehicle entities.Vehicle) error {
 		Model:      vehicle.Model,
 		Year:       vehicle.Year,
 		Brand:      vehicle.Brand,
+		VIN:        vehicle.VIN.String(),
 	}
 
 	if err := r.db.Create(&vehicleDTO).Error; err != nil {
@@ -90,17 +99,40 @@ func (r *VehicleRepository) Create(vehicle entities.Vehicle) error {
 	return nil
 }
 
-func (r *VehicleRepository) Update(vehicle entities.Vehicle) error {
-	vehicleDTO := dto.VehicleDTO{
-		ID:         vehicle.ID,
-		Plate:      string(vehicle.Plate),
-		CustomerID: vehicle.Customer.ID,
-		Model:      vehicle.Model,
-		Year:       vehicle.Year,
-		Brand:      vehicle.Brand,
+// CreateBatch registers the vehicles in a single statement, so if any of them fails none is registered
+func (r *VehicleRepository) CreateBatch(vehicles []entities.Vehicle) error {
+	if len(vehicles) == 0 {
+		return nil
+	}
+
+	vehicleDTOs := make([]dto.VehicleDTO, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		vehicleDTOs = append(vehicleDTOs, dto.VehicleDTO{
+			Plate:      string(vehicle.Plate),
+			CustomerID: vehicle.CustomerID,
+			Model:      vehicle.Model,
+			Year:       vehicle.Year,
+			Brand:      vehicle.Brand,
+			VIN:        vehicle.VIN.String(),
+		})
 	}
 
-	if err := r.db.Save(&vehicleDTO).Error; err != nil {
+	return r.db.Create(&vehicleDTOs).Error
+}
+
+// Update changes the registration data of the vehicle. The owner is kept: it only changes when the
+// vehicle is transferred, so the ownership history stays complete.
+func (r *VehicleRepository) Update(vehicle entities.Vehicle) error {
+	err := r.db.Model(&dto.VehicleDTO{}).
+		Where("id = ?", vehicle.ID).
+		Updates(map[string]interface{}{
+			"plate": string(vehicle.Plate),
+			"model": vehicle.Model,
+			"year":  vehicle.Year,
+			"brand": vehicle.Brand,
+			"vin":   vehicle.VIN.String(),
+		}).Error
+	if err != nil {
 		return err
 	}
 
@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IWebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *dto.WebhookSubscriptionDTO) error
+	GetSubscription(ctx context.Context, id uint) (*dto.WebhookSubscriptionDTO, error)
+	ListSubscriptions(ctx context.Context) ([]dto.WebhookSubscriptionDTO, error)
+	ListActiveSubscriptions(ctx context.Context) ([]dto.WebhookSubscriptionDTO, error)
+	UpdateSubscription(ctx context.Context, subscription *dto.WebhookSubscriptionDTO) error
+	DeleteSubscription(ctx context.Context, id uint) error
+	CreateDelivery(ctx context.Context, delivery *dto.WebhookDeliveryDTO) (bool, error)
+	GetDelivery(ctx context.Context, id uint) (*dto.WebhookDeliveryDTO, error)
+	UpdateDelivery(ctx context.Context, delivery *dto.WebhookDeliveryDTO) error
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]dto.WebhookDeliveryDTO, error)
+	ListDeliveries(ctx context.Context, subscriptionID uint) ([]dto.WebhookDeliveryDTO, error)
+}
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+var _ IWebhookRepository = (*WebhookRepository)(nil)
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription *dto.WebhookSubscriptionDTO) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id uint) (*dto.WebhookSubscriptionDTO, error) {
+	var subscription dto.WebhookSubscriptionDTO
+	if err := r.db.WithContext(ctx).First(&subscription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]dto.WebhookSubscriptionDTO, error) {
+	var subscriptions []dto.WebhookSubscriptionDTO
+	if err := r.db.WithContext(ctx).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) ListActiveSubscriptions(ctx context.Context) ([]dto.WebhookSubscriptionDTO, error) {
+	var subscriptions []dto.WebhookSubscriptionDTO
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription changes the target, the filters and the activation; the secret is kept
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, subscription *dto.WebhookSubscriptionDTO) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.WebhookSubscriptionDTO{}).
+		Where("id = ?", subscription.ID).
+		Updates(map[string]interface{}{
+			"url":         subscription.URL,
+			"event_types": subscription.EventTypes,
+			"customer_id": subscription.CustomerID,
+			"active":      subscription.Active,
+		}).Error
+}
+
+// DeleteSubscription soft deletes the subscription, keeping its delivery history
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&dto.WebhookSubscriptionDTO{}, id).Error
+}
+
+// CreateDelivery queues a delivery, returning false when the event was already queued for the
+// subscription, which happens when the dispatcher delivers an event again
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *dto.WebhookDeliveryDTO) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uint) (*dto.WebhookDeliveryDTO, error) {
+	var delivery dto.WebhookDeliveryDTO
+	if err := r.db.WithContext(ctx).First(&delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// UpdateDelivery records the outcome of a delivery attempt
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *dto.WebhookDeliveryDTO) error {
+	return r.db.WithContext(ctx).
+		Model(&dto.WebhookDeliveryDTO{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"response_status": delivery.ResponseStatus,
+			"last_error":      delivery.LastError,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+}
+
+// ListDueDeliveries lists the pending deliveries whose next attempt is due, with their subscription.
+// The subscription is left empty when it was deleted.
+func (r *WebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]dto.WebhookDeliveryDTO, error) {
+	var deliveries []dto.WebhookDeliveryDTO
+	err := r.db.WithContext(ctx).
+		Preload("Subscription").
+		Where("status = ? AND next_attempt_at <= ?", valueobject.WebhookPending.String(), now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint) ([]dto.WebhookDeliveryDTO, error) {
+	var deliveries []dto.WebhookDeliveryDTO
+	err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("created_at DESC, id DESC").
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -3,20 +3,36 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"mecanica_xpto/internal/domain/model/dto"
 	"mecanica_xpto/internal/domain/repository/additional_repair"
 	"mecanica_xpto/internal/domain/repository/parts_supply"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/domain/repository/service"
 	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
 )
 
+// customer decisions on an additional repair
+const (
+	ApprovalApproved = "APPROVED"
+	ApprovalDenied   = "DENIED"
+)
+
 var (
 	ErrAdditionalRepairNotFound = errors.New("additional repair not found")
 	ErrStatusNotPermitted       = errors.New("additional repair status not permitted")
+	ErrAdditionalRepairEmpty    = errors.New("additional repair has no services or parts supplies")
+	ErrInvalidApprovalStatus    = errors.New("approval status must be APPROVED or DENIED")
+	ErrServiceOrderClosed       = errors.New("service order no longer accepts additional repairs")
+	ErrInvalidPartsQuantity     = errors.New("parts supply quantity must be positive")
+	ErrNothingToRemove          = errors.New("no services or parts supplies to remove")
+	ErrItemNotInRepair          = errors.New("service or parts supply is not part of the additional repair")
+	ErrServiceOrderAlreadyPaid  = errors.New("service order is already paid")
 )
 
 type IAdditionalRepairUseCase interface {
@@ -24,6 +40,7 @@ type IAdditionalRepairUseCase interface {
 	AddPartSupplyAndService(ctx context.Context, adrId uint, adr entities.AdditionalRepair) error
 	RemovePartSupplyAndService(ctx context.Context, adrId uint, adr entities.AdditionalRepair) error
 	GetAdditionalRepair(ctx context.Context, additionalRepairId uint) (entities.AdditionalRepair, error)
+	SubmitForApproval(ctx context.Context, additionalRepairId uint) error
 	CustomerApprovalStatus(ctx context.Context, additionalRepairId uint, status entities.AdditionalRepairStatusDTO) error
 }
 
@@ -32,213 +49,490 @@ type AdditionalRepairUseCase struct {
 	repoOS          serviceorder.IServiceOrderRepository
 	serviceRepo     service.IServiceRepo
 	partsSupplyRepo parts_supply.IPartsSupplyRepo
+	notifier        INotificationUseCase
 }
 
 var _ IAdditionalRepairUseCase = (*AdditionalRepairUseCase)(nil)
 
-func NewSOAdditionalRepairUseCase(repo additional_repair.IAdditionalRepairRepository, repoOS serviceorder.IServiceOrderRepository, serviceRepo service.IServiceRepo, partsSupplyRepo parts_supply.IPartsSupplyRepo) *AdditionalRepairUseCase {
+func NewSOAdditionalRepairUseCase(repo additional_repair.IAdditionalRepairRepository, repoOS serviceorder.IServiceOrderRepository, serviceRepo service.IServiceRepo, partsSupplyRepo parts_supply.IPartsSupplyRepo, notifier INotificationUseCase) *AdditionalRepairUseCase {
 	return &AdditionalRepairUseCase{
 		repo:            repo,
 		repoOS:          repoOS,
 		serviceRepo:     serviceRepo,
 		partsSupplyRepo: partsSupplyRepo,
+		notifier:        notifier,
 	}
 }
 
+// CreateAdditionalRepair opens an additional repair on a service order. It starts as
+// ABERTA, so services and parts supplies can still be changed before it is submitted.
 func (u *AdditionalRepairUseCase) CreateAdditionalRepair(ctx context.Context, adr entities.AdditionalRepair) error {
-	_, err := u.repoOS.GetByID(adr.ServiceOrderID)
+	serviceOrderDto, err := u.repoOS.GetByID(adr.ServiceOrderID)
 	if err != nil {
 		log.Error().Msgf("error finding service order with id %d: %v", adr.ServiceOrderID, err)
 		return err
 	}
+	if serviceOrderDto == nil {
+		log.Error().Msgf("service order with id %d not found", adr.ServiceOrderID)
+		return ErrServiceOrderNotFound
+	}
+	status := serviceOrderDto.ServiceOrderStatus.ToDomain()
+	if status.IsEntregue() || status.IsCancelada() || status.IsRejeitada() {
+		log.Error().Msgf("service order with id %d is %s", adr.ServiceOrderID, status)
+		return ErrServiceOrderClosed
+	}
 	var estimatedPartsSupply float64
 	var estimatedService float64
 
 	arStatus := dto.AdditionalRepairStatusDTO{
-		Description: "IN_ANALYSIS",
+		Description: valueobject.StatusARAberta.String(),
 	}
-	var listServices []dto.ServiceDTO
-	var listPartsSupply []dto.PartsSupplyDTO
-	listServices, estimatedService, err = u.addServiceToAdditionalRepair(ctx, adr.Services)
+	var listServices []dto.ServiceAdditionalRepairDTO
+	var listPartsSupply []dto.PartsSupplyAdditionalRepairDTO
+	listServices, estimatedService, err = u.addServiceToAdditionalRepair(ctx, nil, adr.Services)
 	if err != nil {
 		return err
 	}
-	listPartsSupply, estimatedPartsSupply, err = u.addPartsSupplyToAdditionalRepair(ctx, adr.PartsSupplies)
+	listPartsSupply, estimatedPartsSupply, err = u.addPartsSupplyToAdditionalRepair(ctx, nil, adr.PartsSupplies)
 	if err != nil {
 		return err
 	}
 
 	additionalRepair := dto.AdditionalRepairDTO{
-		ServiceOrderID: adr.ServiceOrderID,
-		Description:    adr.Description,
-		ARStatus:       arStatus,
-		Estimate:       estimatedService + estimatedPartsSupply,
-		Services:       listServices,
-		PartsSupplies:  listPartsSupply,
+		ServiceOrderID:   adr.ServiceOrderID,
+		Description:      adr.Description,
+		ARStatus:         arStatus,
+		Estimate:         roundCurrency(estimatedService + estimatedPartsSupply),
+		ServiceItems:     listServices,
+		PartsSupplyItems: listPartsSupply,
 	}
 
-	err = u.repo.Create(&additionalRepair)
+	err = u.repo.Create(&additionalRepair,
+		entities.NewAdditionalRepairCreatedEvent(adr.ServiceOrderID, adr.Description, additionalRepair.Estimate))
 	if err != nil {
 		log.Error().Msgf("Error creating additional repair: %v", err)
 		return err
 	}
+	u.recordHistory(additionalRepair.ID, entities.AdditionalRepairCreated, adr.Description, additionalRepair.Estimate)
+	// The additional repair is already stored, so a message that cannot be queued is only logged
+	err = u.notifier.Notify(ctx, entities.NotificationEvent{
+		Type:               entities.NotificationAdditionalRepairCreated,
+		ServiceOrderID:     adr.ServiceOrderID,
+		AdditionalRepairID: additionalRepair.ID,
+		Description:        adr.Description,
+		Estimate:           additionalRepair.Estimate,
+	})
+	if err != nil {
+		log.Error().Msgf("Error notifying additional repair %d: %v", additionalRepair.ID, err)
+	}
 	return nil
 }
 
 func (u *AdditionalRepairUseCase) AddPartSupplyAndService(ctx context.Context, additionalRepairId uint, adr entities.AdditionalRepair) error {
-	additionalRepairDto, err := u.repo.GetByID(additionalRepairId)
+	additionalRepairDto, err := u.getAdditionalRepair(additionalRepairId)
 	if err != nil {
-		log.Error().Msgf("error finding additional repair with id %d: %v", additionalRepairId, err)
 		return err
 	}
-	if err := u.ValidateAdditionalRepairStatus(additionalRepairDto.ARStatus.Description); err != nil {
+	if err := validateAdditionalRepairStatus(additionalRepairDto, valueobject.StatusARAberta); err != nil {
 		return err
 	}
 	var estimatedPartsSupply float64
 	var estimatedService float64
 
-	var listServices []dto.ServiceDTO
-	var listPartsSupply []dto.PartsSupplyDTO
-	listServices, estimatedService, err = u.addServiceToAdditionalRepair(ctx, adr.Services)
+	var listServices []dto.ServiceAdditionalRepairDTO
+	var listPartsSupply []dto.PartsSupplyAdditionalRepairDTO
+	listServices, estimatedService, err = u.addServiceToAdditionalRepair(ctx, additionalRepairDto, adr.Services)
 	if err != nil {
 		return err
 	}
-	listPartsSupply, estimatedPartsSupply, err = u.addPartsSupplyToAdditionalRepair(ctx, adr.PartsSupplies)
+	listPartsSupply, estimatedPartsSupply, err = u.addPartsSupplyToAdditionalRepair(ctx, additionalRepairDto, adr.PartsSupplies)
 	if err != nil {
 		return err
 	}
 
 	updated := dto.AdditionalRepairDTO{
-		ServiceOrderID: adr.ServiceOrderID,
-		Description:    adr.Description,
-		Estimate:       estimatedService + estimatedPartsSupply,
-		Services:       listServices,
-		PartsSupplies:  listPartsSupply,
+		ServiceOrderID:   adr.ServiceOrderID,
+		Description:      adr.Description,
+		Estimate:         roundCurrency(estimatedService + estimatedPartsSupply),
+		ServiceItems:     listServices,
+		PartsSupplyItems: listPartsSupply,
 	}
 	err = u.repo.AddPartSupplyAndService(additionalRepairDto, &updated)
 	if err != nil {
 		log.Error().Msgf("Error adding part suplly and services for additional repair: %v", err)
+		if errors.Is(err, additional_repair.ErrStatusChanged) {
+			return ErrStatusNotPermitted
+		}
 		return err
 	}
+	u.recordHistory(additionalRepairId, entities.AdditionalRepairItemsAdded, describeItems(listServices, listPartsSupply, nil), updated.Estimate)
 	return nil
 }
 
+// RemovePartSupplyAndService takes services and parts supplies out of an additional repair that
+// is still pending. The quantity of each parts supply to remove is read from quantity_reserve, and
+// all of its units are removed when it is not set. Parts already reserved for an additional repair
+// awaiting approval are given back to the stock.
 func (u *AdditionalRepairUseCase) RemovePartSupplyAndService(ctx context.Context, additionalRepairId uint, adr entities.AdditionalRepair) error {
-	additionalRepairDto, err := u.repo.GetByID(additionalRepairId)
+	additionalRepairDto, err := u.getAdditionalRepair(additionalRepairId)
 	if err != nil {
-		log.Error().Msgf("error finding additional repair with id %d: %v", additionalRepairId, err)
 		return err
 	}
-	if err := u.ValidateAdditionalRepairStatus(additionalRepairDto.ARStatus.Description); err != nil {
-		return err
+	status := additionalRepairDto.ARStatus.ToDomain()
+	if !status.IsPending() {
+		log.Error().Msgf("invalid additional repair status: %s", status)
+		return ErrStatusNotPermitted
 	}
-	var estimatedPartsSupply float64
-	var estimatedService float64
 
-	var listServices []dto.ServiceDTO
-	var listPartsSupply []dto.PartsSupplyDTO
-	listServices, estimatedService, err = u.addServiceToAdditionalRepair(ctx, adr.Services)
+	removed, err := removedItems(additionalRepairDto, adr)
 	if err != nil {
 		return err
 	}
-	listPartsSupply, estimatedPartsSupply, err = u.addPartsSupplyToAdditionalRepair(ctx, adr.PartsSupplies)
+
+	err = u.repo.RemovePartSupplyAndService(additionalRepairDto, removed)
 	if err != nil {
+		log.Error().Msgf("Error removing parts supply and services from additional repair: %v", err)
+		if errors.Is(err, additional_repair.ErrStatusChanged) {
+			return ErrStatusNotPermitted
+		}
 		return err
 	}
 
-	updated := dto.AdditionalRepairDTO{
-		ServiceOrderID: adr.ServiceOrderID,
-		Description:    adr.Description,
-		Estimate:       estimatedService + estimatedPartsSupply,
-		Services:       listServices,
-		PartsSupplies:  listPartsSupply,
-	}
-	err = u.repo.AddPartSupplyAndService(additionalRepairDto, &updated)
-	if err != nil {
-		log.Error().Msgf("Error adding part suplly and services for additional repair: %v", err)
-		return err
+	if status.IsAguardandoAprovacao() {
+		u.unreserveParts(ctx, additionalRepairParts(removed))
 	}
+	u.recordHistory(additionalRepairId, entities.AdditionalRepairItemsRemoved,
+		describeItems(removed.ServiceItems, removed.PartsSupplyItems, additionalRepairDto), -removed.Estimate)
 	return nil
 }
 
+// removedItems matches the requested removal against the items of the additional repair and
+// prices it with the prices the items were added at, so the estimate loses what they added to it
+// even when the catalog prices changed since.
+func removedItems(additionalRepairDto *dto.AdditionalRepairDTO, adr entities.AdditionalRepair) (*dto.AdditionalRepairDTO, error) {
+	if len(adr.Services) == 0 && len(adr.PartsSupplies) == 0 {
+		return nil, ErrNothingToRemove
+	}
+	removed := &dto.AdditionalRepairDTO{ID: additionalRepairDto.ID}
+
+	inRepair := make(map[uint]bool)
+	for _, s := range additionalRepairDto.Services {
+		inRepair[s.ID] = true
+	}
+	seen := make(map[uint]bool)
+	for _, s := range adr.Services {
+		if !inRepair[s.ID] {
+			log.Error().Msgf("service with id %d is not part of additional repair %d", s.ID, additionalRepairDto.ID)
+			return nil, ErrItemNotInRepair
+		}
+		if seen[s.ID] {
+			continue
+		}
+		seen[s.ID] = true
+		price := additionalRepairDto.ServiceUnitPrice(s.ID)
+		removed.ServiceItems = append(removed.ServiceItems, dto.ServiceAdditionalRepairDTO{ServiceID: s.ID, AdditionalRepairID: additionalRepairDto.ID, UnitPrice: price})
+		removed.Estimate += price
+	}
+
+	quantities := make(map[uint]int)
+	for _, ps := range adr.PartsSupplies {
+		inRepair := additionalRepairDto.PartsSupplyQuantity(ps.ID)
+		if inRepair == 0 {
+			log.Error().Msgf("parts supply with id %d is not part of additional repair %d", ps.ID, additionalRepairDto.ID)
+			return nil, ErrItemNotInRepair
+		}
+		quantity := ps.QuantityReserve
+		if quantity == 0 {
+			quantity = inRepair
+		}
+		if _, ok := quantities[ps.ID]; !ok {
+			removed.PartsSupplyItems = append(removed.PartsSupplyItems, dto.PartsSupplyAdditionalRepairDTO{PartsSupplyID: ps.ID})
+		}
+		quantities[ps.ID] += quantity
+		if quantity < 0 || quantities[ps.ID] > inRepair {
+			log.Error().Msgf("cannot remove %d of the %d units of parts supply %d", quantities[ps.ID], inRepair, ps.ID)
+			return nil, ErrInvalidPartsQuantity
+		}
+	}
+	for i, item := range removed.PartsSupplyItems {
+		price := additionalRepairDto.PartsSupplyUnitPrice(item.PartsSupplyID)
+		removed.PartsSupplyItems[i].Quantity = quantities[item.PartsSupplyID]
+		removed.PartsSupplyItems[i].UnitPrice = price
+		removed.Estimate += price * float64(quantities[item.PartsSupplyID])
+	}
+	removed.Estimate = roundCurrency(removed.Estimate)
+	return removed, nil
+}
+
 func (u *AdditionalRepairUseCase) GetAdditionalRepair(ctx context.Context, additionalRepairId uint) (entities.AdditionalRepair, error) {
-	additionalRepairDto, err := u.repo.GetByID(additionalRepairId)
+	additionalRepairDto, err := u.getAdditionalRepair(additionalRepairId)
 	if err != nil {
-		log.Error().Msgf("error finding additional repair with id %d: %v", additionalRepairId, err)
 		return entities.AdditionalRepair{}, err
 	}
-	if additionalRepairDto == nil {
-		log.Error().Msgf("additional repair with id %d not found", additionalRepairId)
-		return entities.AdditionalRepair{}, ErrServiceOrderNotFound
-	}
 	return additionalRepairDto.ToDomain(), nil
 }
 
-func (u *AdditionalRepairUseCase) CustomerApprovalStatus(ctx context.Context, additionalRepairId uint, status entities.AdditionalRepairStatusDTO) error {
-	additionalRepairDto, err := u.repo.GetByID(additionalRepairId)
+// SubmitForApproval sends an open additional repair to the customer, reserving its parts
+// supplies the same way the main estimate does while it waits for approval.
+func (u *AdditionalRepairUseCase) SubmitForApproval(ctx context.Context, additionalRepairId uint) error {
+	additionalRepairDto, err := u.getAdditionalRepair(additionalRepairId)
 	if err != nil {
-		log.Error().Msgf("error finding additional repair with id %d: %v", additionalRepairId, err)
 		return err
 	}
-	if err := u.ValidateAdditionalRepairStatus(additionalRepairDto.ARStatus.Description); err != nil {
+	if err := validateAdditionalRepairStatus(additionalRepairDto, valueobject.StatusARAberta); err != nil {
+		return err
+	}
+	if len(additionalRepairDto.Services) == 0 && len(additionalRepairDto.PartsSupplies) == 0 {
+		log.Error().Msgf("additional repair with id %d has nothing to approve", additionalRepairId)
+		return ErrAdditionalRepairEmpty
+	}
+
+	parts := additionalRepairParts(additionalRepairDto)
+	for _, ps := range parts {
+		if err := validateQttPartsSupply(ctx, ps, u.partsSupplyRepo); err != nil {
+			log.Error().Msgf("Error validating parts supply %d for additional repair %d: %v", ps.ID, additionalRepairId, err)
+			return err
+		}
+	}
+
+	var reserved []entities.PartsSupply
+	for _, ps := range parts {
+		if err := reservePartsSupply(ctx, additionalRepairDto.ServiceOrderID, ps, u.partsSupplyRepo); err != nil {
+			log.Error().Msgf("Error reserving parts supply %d for additional repair %d: %v", ps.ID, additionalRepairId, err)
+			u.unreserveParts(ctx, reserved)
+			return err
+		}
+		reserved = append(reserved, ps)
+	}
+
+	if err := u.repo.UpdateStatus(additionalRepairId, valueobject.StatusARAguardandoAprovacao); err != nil {
+		log.Error().Msgf("error submitting additional repair with id %d: %v", additionalRepairId, err)
+		u.unreserveParts(ctx, reserved)
 		return err
 	}
-	err = u.repo.CustomerApprovalStatus(additionalRepairId, status)
+	u.recordStatusChange(additionalRepairDto, valueobject.StatusARAguardandoAprovacao)
+	return nil
+}
+
+// CustomerApprovalStatus records the customer decision on a submitted additional repair.
+// An approval writes off the reserved parts supplies and adds the estimate to the service
+// order; a denial only gives the reserved parts supplies back to the stock.
+func (u *AdditionalRepairUseCase) CustomerApprovalStatus(ctx context.Context, additionalRepairId uint, status entities.AdditionalRepairStatusDTO) error {
+	if status.ApprovalStatus != ApprovalApproved && status.ApprovalStatus != ApprovalDenied {
+		return ErrInvalidApprovalStatus
+	}
+	additionalRepairDto, err := u.getAdditionalRepair(additionalRepairId)
 	if err != nil {
-		log.Error().Msgf("error updating customer approval with id %d: %v", additionalRepairId, err)
 		return err
 	}
+	if err := validateAdditionalRepairStatus(additionalRepairDto, valueobject.StatusARAguardandoAprovacao); err != nil {
+		return err
+	}
+
+	if status.ApprovalStatus == ApprovalDenied {
+		u.unreserveParts(ctx, additionalRepairParts(additionalRepairDto))
+		if err := u.repo.UpdateStatus(additionalRepairId, valueobject.StatusARRejeitada); err != nil {
+			log.Error().Msgf("error updating customer approval with id %d: %v", additionalRepairId, err)
+			return err
+		}
+		u.recordStatusChange(additionalRepairDto, valueobject.StatusARRejeitada)
+		return nil
+	}
 
-	err = u.repoOS.UpdateEstimate(additionalRepairDto.ServiceOrderID, additionalRepairDto.Estimate)
+	serviceOrderDto, err := u.repoOS.GetByID(additionalRepairDto.ServiceOrderID)
 	if err != nil {
-		log.Error().Msgf("error updating service order estimate with id %d: %v", additionalRepairDto.ServiceOrderID, err)
+		log.Error().Msgf("error finding service order with id %d: %v", additionalRepairDto.ServiceOrderID, err)
 		return err
 	}
+	if serviceOrderDto == nil {
+		log.Error().Msgf("service order with id %d not found", additionalRepairDto.ServiceOrderID)
+		return ErrServiceOrderNotFound
+	}
+	if serviceOrderDto.Payment != nil {
+		log.Error().Msgf("service order with id %d is already paid", additionalRepairDto.ServiceOrderID)
+		return ErrServiceOrderAlreadyPaid
+	}
+
+	// The stock write-off, the status and the estimate of the service order are stored together,
+	// so a failure leaves neither the parts supplies taken nor the estimate short of the repair
+	approved := entities.NewAdditionalRepairApprovedEvent(additionalRepairId, additionalRepairDto.ServiceOrderID, additionalRepairDto.Estimate)
+	if err := u.repo.Approve(additionalRepairDto, approved); err != nil {
+		log.Error().Msgf("error approving additional repair with id %d: %v", additionalRepairId, err)
+		switch {
+		case errors.Is(err, additional_repair.ErrStatusChanged):
+			return ErrStatusNotPermitted
+		case errors.Is(err, additional_repair.ErrPartsSupplyNotReserved):
+			return ErrInsufficientPartsSupply
+		case errors.Is(err, additional_repair.ErrServiceOrderPaid):
+			return ErrServiceOrderAlreadyPaid
+		}
+		return err
+	}
+	u.recordStatusChange(additionalRepairDto, valueobject.StatusAAprovada)
 
 	return nil
 }
 
-func (u *AdditionalRepairUseCase) addPartsSupplyToAdditionalRepair(ctx context.Context, partsSupplyId []entities.PartsSupply) ([]dto.PartsSupplyDTO, float64, error) {
-	var listPartsSupply []dto.PartsSupplyDTO
+func (u *AdditionalRepairUseCase) getAdditionalRepair(additionalRepairId uint) (*dto.AdditionalRepairDTO, error) {
+	additionalRepairDto, err := u.repo.GetByID(additionalRepairId)
+	if err != nil {
+		log.Error().Msgf("error finding additional repair with id %d: %v", additionalRepairId, err)
+		return nil, err
+	}
+	if additionalRepairDto == nil {
+		log.Error().Msgf("additional repair with id %d not found", additionalRepairId)
+		return nil, ErrAdditionalRepairNotFound
+	}
+	return additionalRepairDto, nil
+}
+
+// recordHistory appends an entry to the history of the additional repair. The change itself is
+// already stored at this point, so a failure is only logged.
+func (u *AdditionalRepairUseCase) recordHistory(additionalRepairId uint, action, description string, estimateChange float64) {
+	entry := dto.AdditionalRepairHistoryDTO{
+		AdditionalRepairID: additionalRepairId,
+		Action:             action,
+		Description:        description,
+		EstimateChange:     roundCurrency(estimateChange),
+	}
+	if err := u.repo.AddHistory(&entry); err != nil {
+		log.Error().Msgf("error recording history of additional repair %d: %v", additionalRepairId, err)
+	}
+}
+
+func (u *AdditionalRepairUseCase) recordStatusChange(additionalRepairDto *dto.AdditionalRepairDTO, status valueobject.AdditionalRepairStatus) {
+	u.recordHistory(additionalRepairDto.ID, entities.AdditionalRepairStatusChanged,
+		fmt.Sprintf("%s -> %s", additionalRepairDto.ARStatus.Description, status), 0)
+}
+
+// describeItems summarizes services and parts supplies for the history, by name when current
+// has them loaded and by id otherwise.
+func describeItems(services []dto.ServiceAdditionalRepairDTO, partsSupplies []dto.PartsSupplyAdditionalRepairDTO, current *dto.AdditionalRepairDTO) string {
+	serviceNames := make(map[uint]string)
+	partsSupplyNames := make(map[uint]string)
+	if current != nil {
+		for _, s := range current.Services {
+			serviceNames[s.ID] = s.Name
+		}
+		for _, ps := range current.PartsSupplies {
+			partsSupplyNames[ps.ID] = ps.Name
+		}
+	}
+
+	var items []string
+	for _, s := range services {
+		name := serviceNames[s.ServiceID]
+		if name == "" {
+			name = fmt.Sprintf("service %d", s.ServiceID)
+		}
+		items = append(items, name)
+	}
+	for _, ps := range partsSupplies {
+		name := partsSupplyNames[ps.PartsSupplyID]
+		if name == "" {
+			name = fmt.Sprintf("parts supply %d", ps.PartsSupplyID)
+		}
+		items = append(items, fmt.Sprintf("%dx %s", ps.Quantity, name))
+	}
+	return strings.Join(items, ", ")
+}
+
+// unreserveParts gives reserved parts supplies back to the stock. Failures are only logged,
+// as the caller is already handling an error or a denial that must not be blocked.
+func (u *AdditionalRepairUseCase) unreserveParts(ctx context.Context, parts []entities.PartsSupply) {
+	for _, ps := range parts {
+		if err := unreservePartsSupply(ctx, ps, u.partsSupplyRepo); err != nil {
+			log.Error().Msgf("Error unreserving parts supply %d: %v", ps.ID, err)
+		}
+	}
+}
+
+// additionalRepairParts lists the parts supplies of an additional repair with the quantity
+// to reserve for each of them.
+func additionalRepairParts(additionalRepairDto *dto.AdditionalRepairDTO) []entities.PartsSupply {
+	var parts []entities.PartsSupply
+	for _, item := range additionalRepairDto.PartsSupplyItems {
+		parts = append(parts, entities.PartsSupply{ID: item.PartsSupplyID, QuantityReserve: item.Quantity})
+	}
+	return parts
+}
+
+// addPartsSupplyToAdditionalRepair prices the requested parts supplies by quantity, the same way
+// the diagnosis reads it from quantity_reserve (one unit when it is not set), and checks that the
+// stock available can cover them along with what the additional repair already uses. The unit
+// price is kept on each item.
+func (u *AdditionalRepairUseCase) addPartsSupplyToAdditionalRepair(ctx context.Context, current *dto.AdditionalRepairDTO, partsSupplies []entities.PartsSupply) ([]dto.PartsSupplyAdditionalRepairDTO, float64, error) {
+	var items []dto.PartsSupplyAdditionalRepairDTO
 	var estimatedPrice float64
 
-	for _, ps := range partsSupplyId {
-		psDto, err := u.partsSupplyRepo.GetByID(ctx, ps.ID)
+	quantities := make(map[uint]int)
+	for _, ps := range partsSupplies {
+		quantity := ps.QuantityReserve
+		if quantity == 0 {
+			quantity = 1
+		}
+		if quantity < 0 {
+			log.Error().Msgf("invalid quantity %d for parts supply with id %d", quantity, ps.ID)
+			return nil, 0, ErrInvalidPartsQuantity
+		}
+		if _, ok := quantities[ps.ID]; !ok {
+			items = append(items, dto.PartsSupplyAdditionalRepairDTO{PartsSupplyID: ps.ID})
+		}
+		quantities[ps.ID] += quantity
+	}
+
+	for i, item := range items {
+		partsSupply, err := getPartsSupplyByID(ctx, item.PartsSupplyID, u.partsSupplyRepo)
 		if err != nil {
-			log.Error().Msgf("error finding parts supply with id %d: %v", ps.ID, err)
+			return nil, 0, err
 		}
-		if psDto.ID == 0 {
-			log.Error().Msgf("parts supply with id %d not found", ps.ID)
-			return listPartsSupply, estimatedPrice, ErrServiceNotFound
+		quantity := quantities[item.PartsSupplyID]
+		inUse := 0
+		if current != nil {
+			inUse = current.PartsSupplyQuantity(item.PartsSupplyID)
 		}
-		estimatedPrice += psDto.Price
-		listPartsSupply = append(listPartsSupply, dto.PartsSupplyDTO{ID: ps.ID})
+		err = validateQttPartsSupply(ctx, entities.PartsSupply{ID: item.PartsSupplyID, QuantityReserve: quantity + inUse}, u.partsSupplyRepo)
+		if err != nil {
+			return nil, 0, err
+		}
+		estimatedPrice += partsSupply.Price * float64(quantity)
+		items[i].Quantity = quantity
+		items[i].UnitPrice = partsSupply.Price
 	}
-	return listPartsSupply, estimatedPrice, nil
+	return items, estimatedPrice, nil
 }
 
-func (u *AdditionalRepairUseCase) addServiceToAdditionalRepair(ctx context.Context, services []entities.Service) ([]dto.ServiceDTO, float64, error) {
-	var listServices []dto.ServiceDTO
+// addServiceToAdditionalRepair prices the requested services, skipping the ones the additional
+// repair already has.
+func (u *AdditionalRepairUseCase) addServiceToAdditionalRepair(ctx context.Context, current *dto.AdditionalRepairDTO, services []entities.Service) ([]dto.ServiceAdditionalRepairDTO, float64, error) {
+	var listServices []dto.ServiceAdditionalRepairDTO
 	var estimatedPrice float64
 
+	added := make(map[uint]bool)
+	if current != nil {
+		for _, s := range current.Services {
+			added[s.ID] = true
+		}
+	}
 	for _, s := range services {
-		serviceDto, err := u.serviceRepo.GetByID(ctx, s.ID)
-		if err != nil {
-			log.Error().Msgf("error finding service with id %d: %v", s.ID, err)
+		if added[s.ID] {
+			continue
 		}
-		if serviceDto.ID == 0 {
-			log.Error().Msgf("service with id %d not found", s.ID)
-			return listServices, estimatedPrice, ErrServiceNotFound
+		result, err := getSeviceById(ctx, s, u.serviceRepo)
+		if err != nil {
+			return nil, 0, err
 		}
-		estimatedPrice += serviceDto.Price
-		listServices = append(listServices, dto.ServiceDTO{ID: s.ID})
+		added[s.ID] = true
+		estimatedPrice += result.Price
+		listServices = append(listServices, dto.ServiceAdditionalRepairDTO{ServiceID: s.ID, UnitPrice: result.Price})
 	}
 	return listServices, estimatedPrice, nil
 }
 
-func (u *AdditionalRepairUseCase) ValidateAdditionalRepairStatus(status string) error {
-	if status != "IN_ANALYSIS" {
-		log.Error().Msgf("invalid additional repair status: %s", status)
+func validateAdditionalRepairStatus(additionalRepairDto *dto.AdditionalRepairDTO, expected valueobject.AdditionalRepairStatus) error {
+	status := additionalRepairDto.ARStatus.ToDomain()
+	if status != expected {
+		log.Error().Msgf("invalid additional repair status: %s, expected %s", status, expected)
 		return ErrStatusNotPermitted
 	}
 	return nil
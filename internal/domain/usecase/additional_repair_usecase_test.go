@@ -0,0 +1,495 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/additional_repair"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type additionalRepairDeps struct {
+	repo             *mocks.MockIAdditionalRepairRepository
+	serviceOrderRepo *MockServiceOrderRepository
+	serviceRepo      *MockServiceRepository
+	partsSupplyRepo  *MockPartsSupplyRepository
+	notifier         *mocks.MockNotificationUseCase
+	history          *[]dto.AdditionalRepairHistoryDTO
+}
+
+func newAdditionalRepairTestUseCase(t *testing.T) (*AdditionalRepairUseCase, additionalRepairDeps) {
+	ctrl := gomock.NewController(t)
+	deps := additionalRepairDeps{
+		repo:             mocks.NewMockIAdditionalRepairRepository(ctrl),
+		serviceOrderRepo: new(MockServiceOrderRepository),
+		serviceRepo:      new(MockServiceRepository),
+		partsSupplyRepo:  new(MockPartsSupplyRepository),
+		notifier:         newNotifierMock(),
+		history:          &[]dto.AdditionalRepairHistoryDTO{},
+	}
+	deps.repo.EXPECT().AddHistory(gomock.Any()).DoAndReturn(func(entry *dto.AdditionalRepairHistoryDTO) error {
+		*deps.history = append(*deps.history, *entry)
+		return nil
+	}).AnyTimes()
+	return NewSOAdditionalRepairUseCase(deps.repo, deps.serviceOrderRepo, deps.serviceRepo, deps.partsSupplyRepo, deps.notifier), deps
+}
+
+func additionalRepairWithStatus(status valueobject.AdditionalRepairStatus) *dto.AdditionalRepairDTO {
+	return &dto.AdditionalRepairDTO{
+		ID:             1,
+		ServiceOrderID: 10,
+		ARStatus:       dto.AdditionalRepairStatusDTO{Description: status.String()},
+		Estimate:       250,
+		Services:       []dto.ServiceDTO{{ID: 3, Price: 200}},
+		PartsSupplies:  []dto.PartsSupplyDTO{{ID: 2, Price: 25}},
+		PartsSupplyItems: []dto.PartsSupplyAdditionalRepairDTO{
+			{PartsSupplyID: 2, AdditionalRepairID: 1, Quantity: 2},
+		},
+	}
+}
+
+func TestAdditionalRepairUseCase_CreateAdditionalRepair(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("opens the additional repair", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusEmExecucao.String()},
+		}, nil)
+		deps.serviceRepo.On("GetByID", ctx, uint(3)).Return(entities.Service{ID: 3, Price: 200}, nil)
+		deps.repo.EXPECT().Create(gomock.Any(), entities.NewAdditionalRepairCreatedEvent(10, "Troca da correia", 200)).DoAndReturn(func(ar *dto.AdditionalRepairDTO, _ ...entities.DomainEvent) error {
+			assert.Equal(t, valueobject.StatusARAberta.String(), ar.ARStatus.Description)
+			assert.Equal(t, 200.0, ar.Estimate)
+			return nil
+		})
+
+		err := uc.CreateAdditionalRepair(ctx, entities.AdditionalRepair{
+			ServiceOrderID: 10,
+			Description:    "Troca da correia",
+			Services:       []entities.Service{{ID: 3}},
+		})
+		assert.NoError(t, err)
+		deps.notifier.AssertCalled(t, "Notify", ctx, mock.MatchedBy(func(event entities.NotificationEvent) bool {
+			return event.Type == entities.NotificationAdditionalRepairCreated && event.ServiceOrderID == 10 && event.Estimate == 200
+		}))
+	})
+
+	t.Run("prices parts supplies by quantity", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusEmExecucao.String()},
+		}, nil)
+		deps.partsSupplyRepo.On("GetByID", ctx, uint(2)).Return(entities.PartsSupply{ID: 2, Price: 25.5, QuantityTotal: 10, QuantityReserve: 4}, nil)
+		deps.repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ar *dto.AdditionalRepairDTO, _ ...entities.DomainEvent) error {
+			assert.Equal(t, 102.0, ar.Estimate)
+			assert.Equal(t, []dto.PartsSupplyAdditionalRepairDTO{{PartsSupplyID: 2, Quantity: 4, UnitPrice: 25.5}}, ar.PartsSupplyItems)
+			return nil
+		})
+
+		err := uc.CreateAdditionalRepair(ctx, entities.AdditionalRepair{
+			ServiceOrderID: 10,
+			PartsSupplies:  []entities.PartsSupply{{ID: 2, QuantityReserve: 3}, {ID: 2}},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("insufficient parts supply", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusEmExecucao.String()},
+		}, nil)
+		deps.partsSupplyRepo.On("GetByID", ctx, uint(2)).Return(entities.PartsSupply{ID: 2, Price: 25, QuantityTotal: 3, QuantityReserve: 1}, nil)
+
+		err := uc.CreateAdditionalRepair(ctx, entities.AdditionalRepair{
+			ServiceOrderID: 10,
+			PartsSupplies:  []entities.PartsSupply{{ID: 2, QuantityReserve: 3}},
+		})
+		assert.ErrorIs(t, err, ErrInsufficientPartsSupply)
+	})
+
+	t.Run("negative quantity", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusEmExecucao.String()},
+		}, nil)
+
+		err := uc.CreateAdditionalRepair(ctx, entities.AdditionalRepair{
+			ServiceOrderID: 10,
+			PartsSupplies:  []entities.PartsSupply{{ID: 2, QuantityReserve: -1}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidPartsQuantity)
+	})
+
+	t.Run("service order not found", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(nil, nil)
+
+		err := uc.CreateAdditionalRepair(ctx, entities.AdditionalRepair{ServiceOrderID: 10})
+		assert.ErrorIs(t, err, ErrServiceOrderNotFound)
+	})
+
+	t.Run("delivered service order", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusEntregue.String()},
+		}, nil)
+
+		err := uc.CreateAdditionalRepair(ctx, entities.AdditionalRepair{ServiceOrderID: 10})
+		assert.ErrorIs(t, err, ErrServiceOrderClosed)
+	})
+
+	t.Run("rejected service order", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusRejeitada.String()},
+		}, nil)
+
+		err := uc.CreateAdditionalRepair(ctx, entities.AdditionalRepair{ServiceOrderID: 10})
+		assert.ErrorIs(t, err, ErrServiceOrderClosed)
+	})
+}
+
+func TestAdditionalRepairUseCase_AddPartSupplyAndService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("counts the quantity already in the additional repair", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		current := additionalRepairWithStatus(valueobject.StatusARAberta)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(current, nil)
+		deps.partsSupplyRepo.On("GetByID", ctx, uint(2)).Return(entities.PartsSupply{ID: 2, Price: 25, QuantityTotal: 4, QuantityReserve: 1}, nil)
+
+		err := uc.AddPartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			PartsSupplies: []entities.PartsSupply{{ID: 2, QuantityReserve: 2}},
+		})
+		assert.ErrorIs(t, err, ErrInsufficientPartsSupply)
+	})
+
+	t.Run("adds new items to the estimate", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		current := additionalRepairWithStatus(valueobject.StatusARAberta)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(current, nil)
+		deps.partsSupplyRepo.On("GetByID", ctx, uint(2)).Return(entities.PartsSupply{ID: 2, Price: 25, QuantityTotal: 10}, nil)
+		deps.serviceRepo.On("GetByID", ctx, uint(4)).Return(entities.Service{ID: 4, Price: 80}, nil)
+		deps.repo.EXPECT().AddPartSupplyAndService(current, gomock.Any()).DoAndReturn(func(_, updated *dto.AdditionalRepairDTO) error {
+			assert.Equal(t, 130.0, updated.Estimate)
+			assert.Equal(t, []dto.ServiceAdditionalRepairDTO{{ServiceID: 4, UnitPrice: 80}}, updated.ServiceItems)
+			assert.Equal(t, []dto.PartsSupplyAdditionalRepairDTO{{PartsSupplyID: 2, Quantity: 2, UnitPrice: 25}}, updated.PartsSupplyItems)
+			return nil
+		})
+
+		err := uc.AddPartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			Services:      []entities.Service{{ID: 3}, {ID: 4}},
+			PartsSupplies: []entities.PartsSupply{{ID: 2, QuantityReserve: 2}},
+		})
+		assert.NoError(t, err)
+		deps.serviceRepo.AssertNotCalled(t, "GetByID", ctx, uint(3))
+	})
+
+	t.Run("status changed in the meantime", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+		deps.serviceRepo.On("GetByID", ctx, uint(4)).Return(entities.Service{ID: 4, Price: 80}, nil)
+		deps.repo.EXPECT().AddPartSupplyAndService(gomock.Any(), gomock.Any()).Return(additional_repair.ErrStatusChanged)
+
+		err := uc.AddPartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			Services: []entities.Service{{ID: 4}},
+		})
+		assert.ErrorIs(t, err, ErrStatusNotPermitted)
+		assert.Empty(t, *deps.history)
+	})
+}
+
+func TestAdditionalRepairUseCase_RemovePartSupplyAndService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("removes some units of a parts supply", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+		deps.repo.EXPECT().RemovePartSupplyAndService(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(current *dto.AdditionalRepairDTO, removed *dto.AdditionalRepairDTO) error {
+				assert.Empty(t, removed.ServiceItems)
+				assert.Equal(t, []dto.PartsSupplyAdditionalRepairDTO{{PartsSupplyID: 2, Quantity: 1, UnitPrice: 25}}, removed.PartsSupplyItems)
+				assert.Equal(t, 25.0, removed.Estimate)
+				return nil
+			})
+
+		err := uc.RemovePartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			PartsSupplies: []entities.PartsSupply{{ID: 2, QuantityReserve: 1}},
+		})
+		assert.NoError(t, err)
+		deps.partsSupplyRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		if assert.Len(t, *deps.history, 1) {
+			assert.Equal(t, entities.AdditionalRepairItemsRemoved, (*deps.history)[0].Action)
+			assert.Equal(t, -25.0, (*deps.history)[0].EstimateChange)
+		}
+	})
+
+	t.Run("removes a service and every unit of a parts supply", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+		deps.repo.EXPECT().RemovePartSupplyAndService(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(current *dto.AdditionalRepairDTO, removed *dto.AdditionalRepairDTO) error {
+				assert.Len(t, removed.ServiceItems, 1)
+				assert.Equal(t, []dto.PartsSupplyAdditionalRepairDTO{{PartsSupplyID: 2, Quantity: 2, UnitPrice: 25}}, removed.PartsSupplyItems)
+				assert.Equal(t, 250.0, removed.Estimate)
+				return nil
+			})
+
+		err := uc.RemovePartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			Services:      []entities.Service{{ID: 3}},
+			PartsSupplies: []entities.PartsSupply{{ID: 2}},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("subtracts the prices the items were added at", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		current := additionalRepairWithStatus(valueobject.StatusARAberta)
+		// the catalog prices went up to 200 and 25 after the items were added at 180 and 20
+		current.Estimate = 220
+		current.ServiceItems = []dto.ServiceAdditionalRepairDTO{{ServiceID: 3, AdditionalRepairID: 1, UnitPrice: 180}}
+		current.PartsSupplyItems[0].UnitPrice = 20
+		deps.repo.EXPECT().GetByID(uint(1)).Return(current, nil)
+		deps.repo.EXPECT().RemovePartSupplyAndService(current, gomock.Any()).DoAndReturn(
+			func(_ *dto.AdditionalRepairDTO, removed *dto.AdditionalRepairDTO) error {
+				assert.Equal(t, 220.0, removed.Estimate)
+				return nil
+			})
+
+		err := uc.RemovePartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			Services:      []entities.Service{{ID: 3}},
+			PartsSupplies: []entities.PartsSupply{{ID: 2}},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("gives reserved units back while awaiting approval", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAguardandoAprovacao), nil)
+		deps.repo.EXPECT().RemovePartSupplyAndService(gomock.Any(), gomock.Any()).Return(nil)
+		deps.partsSupplyRepo.On("GetByID", ctx, uint(2)).Return(entities.PartsSupply{ID: 2, QuantityTotal: 5, QuantityReserve: 2}, nil)
+		deps.partsSupplyRepo.On("Update", ctx, mock.MatchedBy(func(ps *entities.PartsSupply) bool {
+			return ps.ID == 2 && ps.QuantityReserve == 1 && ps.QuantityTotal == 5
+		})).Return(nil).Once()
+
+		err := uc.RemovePartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			PartsSupplies: []entities.PartsSupply{{ID: 2, QuantityReserve: 1}},
+		})
+		assert.NoError(t, err)
+		deps.partsSupplyRepo.AssertExpectations(t)
+	})
+
+	t.Run("more units than the additional repair has", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+
+		err := uc.RemovePartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			PartsSupplies: []entities.PartsSupply{{ID: 2, QuantityReserve: 3}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidPartsQuantity)
+	})
+
+	t.Run("item not in the additional repair", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+
+		err := uc.RemovePartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			Services: []entities.Service{{ID: 99}},
+		})
+		assert.ErrorIs(t, err, ErrItemNotInRepair)
+	})
+
+	t.Run("nothing to remove", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+
+		err := uc.RemovePartSupplyAndService(ctx, 1, entities.AdditionalRepair{})
+		assert.ErrorIs(t, err, ErrNothingToRemove)
+	})
+
+	t.Run("already approved", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusAAprovada), nil)
+
+		err := uc.RemovePartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			Services: []entities.Service{{ID: 3}},
+		})
+		assert.ErrorIs(t, err, ErrStatusNotPermitted)
+	})
+
+	t.Run("approved in the meantime", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAguardandoAprovacao), nil)
+		deps.repo.EXPECT().RemovePartSupplyAndService(gomock.Any(), gomock.Any()).Return(additional_repair.ErrStatusChanged)
+
+		err := uc.RemovePartSupplyAndService(ctx, 1, entities.AdditionalRepair{
+			PartsSupplies: []entities.PartsSupply{{ID: 2, QuantityReserve: 1}},
+		})
+		assert.ErrorIs(t, err, ErrStatusNotPermitted)
+		deps.partsSupplyRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAdditionalRepairUseCase_SubmitForApproval(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reserves the parts supplies", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+		deps.partsSupplyRepo.On("GetByID", ctx, uint(2)).Return(entities.PartsSupply{ID: 2, QuantityTotal: 5, QuantityReserve: 1}, nil)
+		deps.partsSupplyRepo.On("Update", ctx, mock.MatchedBy(func(ps *entities.PartsSupply) bool {
+			return ps.ID == 2 && ps.QuantityReserve == 3 && ps.QuantityTotal == 5
+		})).Return(nil).Once()
+		deps.repo.EXPECT().UpdateStatus(uint(1), valueobject.StatusARAguardandoAprovacao).Return(nil)
+
+		assert.NoError(t, uc.SubmitForApproval(ctx, 1))
+		deps.partsSupplyRepo.AssertExpectations(t)
+		if assert.Len(t, *deps.history, 1) {
+			assert.Equal(t, entities.AdditionalRepairStatusChanged, (*deps.history)[0].Action)
+			assert.Equal(t, "ABERTA -> AGUARDANDO_APROVACAO", (*deps.history)[0].Description)
+		}
+	})
+
+	t.Run("insufficient parts supply", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+		deps.partsSupplyRepo.On("GetByID", ctx, uint(2)).Return(entities.PartsSupply{ID: 2, QuantityTotal: 2, QuantityReserve: 1}, nil)
+
+		err := uc.SubmitForApproval(ctx, 1)
+		assert.ErrorIs(t, err, ErrInsufficientPartsSupply)
+		deps.partsSupplyRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("already submitted", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAguardandoAprovacao), nil)
+
+		assert.ErrorIs(t, uc.SubmitForApproval(ctx, 1), ErrStatusNotPermitted)
+	})
+
+	t.Run("nothing to approve", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(&dto.AdditionalRepairDTO{
+			ID:       1,
+			ARStatus: dto.AdditionalRepairStatusDTO{Description: valueobject.StatusARAberta.String()},
+		}, nil)
+
+		assert.ErrorIs(t, uc.SubmitForApproval(ctx, 1), ErrAdditionalRepairEmpty)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(nil, nil)
+
+		assert.ErrorIs(t, uc.SubmitForApproval(ctx, 1), ErrAdditionalRepairNotFound)
+	})
+}
+
+func TestAdditionalRepairUseCase_CustomerApprovalStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("approval writes off the parts and adds the estimate", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		additionalRepair := additionalRepairWithStatus(valueobject.StatusARAguardandoAprovacao)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepair, nil)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{ID: 10}, nil)
+		deps.repo.EXPECT().Approve(additionalRepair, entities.NewAdditionalRepairApprovedEvent(1, 10, 250)).Return(nil)
+
+		err := uc.CustomerApprovalStatus(ctx, 1, entities.AdditionalRepairStatusDTO{ApprovalStatus: ApprovalApproved})
+		assert.NoError(t, err)
+		deps.partsSupplyRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		deps.serviceOrderRepo.AssertNotCalled(t, "UpdateEstimate", mock.Anything, mock.Anything)
+		require.Len(t, *deps.history, 1)
+		assert.Equal(t, entities.AdditionalRepairStatusChanged, (*deps.history)[0].Action)
+	})
+
+	t.Run("service order already paid", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAguardandoAprovacao), nil)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{ID: 10, Payment: &dto.PaymentDTO{ID: 4, ServiceOrderID: 10}}, nil)
+
+		err := uc.CustomerApprovalStatus(ctx, 1, entities.AdditionalRepairStatusDTO{ApprovalStatus: ApprovalApproved})
+		assert.ErrorIs(t, err, ErrServiceOrderAlreadyPaid)
+	})
+
+	t.Run("service order paid in the meantime", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAguardandoAprovacao), nil)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{ID: 10}, nil)
+		deps.repo.EXPECT().Approve(gomock.Any(), gomock.Any()).Return(additional_repair.ErrServiceOrderPaid)
+
+		err := uc.CustomerApprovalStatus(ctx, 1, entities.AdditionalRepairStatusDTO{ApprovalStatus: ApprovalApproved})
+		assert.ErrorIs(t, err, ErrServiceOrderAlreadyPaid)
+		assert.Empty(t, *deps.history)
+	})
+
+	t.Run("parts supply no longer reserved", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAguardandoAprovacao), nil)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{ID: 10}, nil)
+		deps.repo.EXPECT().Approve(gomock.Any(), gomock.Any()).Return(additional_repair.ErrPartsSupplyNotReserved)
+
+		err := uc.CustomerApprovalStatus(ctx, 1, entities.AdditionalRepairStatusDTO{ApprovalStatus: ApprovalApproved})
+		assert.ErrorIs(t, err, ErrInsufficientPartsSupply)
+	})
+
+	t.Run("denial releases the reservation and keeps the estimate", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAguardandoAprovacao), nil)
+		deps.partsSupplyRepo.On("GetByID", ctx, uint(2)).Return(entities.PartsSupply{ID: 2, QuantityTotal: 5, QuantityReserve: 2}, nil)
+		deps.partsSupplyRepo.On("Update", ctx, mock.MatchedBy(func(ps *entities.PartsSupply) bool {
+			return ps.QuantityReserve == 0 && ps.QuantityTotal == 5
+		})).Return(nil).Once()
+		deps.repo.EXPECT().UpdateStatus(uint(1), valueobject.StatusARRejeitada).Return(nil)
+
+		err := uc.CustomerApprovalStatus(ctx, 1, entities.AdditionalRepairStatusDTO{ApprovalStatus: ApprovalDenied})
+		assert.NoError(t, err)
+		deps.partsSupplyRepo.AssertExpectations(t)
+		deps.serviceOrderRepo.AssertNotCalled(t, "UpdateEstimate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("not submitted yet", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+
+		err := uc.CustomerApprovalStatus(ctx, 1, entities.AdditionalRepairStatusDTO{ApprovalStatus: ApprovalApproved})
+		assert.ErrorIs(t, err, ErrStatusNotPermitted)
+	})
+
+	t.Run("invalid decision", func(t *testing.T) {
+		uc, _ := newAdditionalRepairTestUseCase(t)
+
+		err := uc.CustomerApprovalStatus(ctx, 1, entities.AdditionalRepairStatusDTO{ApprovalStatus: "MAYBE"})
+		assert.ErrorIs(t, err, ErrInvalidApprovalStatus)
+	})
+
+	t.Run("approval fails", func(t *testing.T) {
+		uc, deps := newAdditionalRepairTestUseCase(t)
+		deps.repo.EXPECT().GetByID(uint(1)).Return(&dto.AdditionalRepairDTO{
+			ID:             1,
+			ServiceOrderID: 10,
+			ARStatus:       dto.AdditionalRepairStatusDTO{Description: valueobject.StatusARAguardandoAprovacao.String()},
+			Services:       []dto.ServiceDTO{{ID: 3}},
+		}, nil)
+		deps.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{ID: 10}, nil)
+		deps.repo.EXPECT().Approve(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+		err := uc.CustomerApprovalStatus(ctx, 1, entities.AdditionalRepairStatusDTO{ApprovalStatus: ApprovalApproved})
+		assert.EqualError(t, err, "db error")
+		assert.Empty(t, *deps.history)
+	})
+}
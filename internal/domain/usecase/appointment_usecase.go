@@ -0,0 +1,495 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/appointment"
+	customerRepo "mecanica_xpto/internal/domain/repository/customers"
+	"mecanica_xpto/internal/domain/repository/service"
+	"mecanica_xpto/internal/domain/repository/vehicles"
+	"mecanica_xpto/pkg/utils"
+)
+
+const (
+	defaultAvailabilityLimit = 5
+	maxAvailabilityLimit     = 50
+)
+
+var (
+	ErrBayNotFound               = errors.New("bay not found")
+	ErrInvalidBay                = errors.New("bay name is required")
+	ErrBayInactive               = errors.New("bay is inactive")
+	ErrAppointmentNotFound       = errors.New("appointment not found")
+	ErrAppointmentNotScheduled   = errors.New("appointment is not scheduled")
+	ErrAppointmentInPast         = errors.New("appointment must start in the future")
+	ErrAppointmentOutsideHours   = errors.New("appointment must fit within the working hours of a working day")
+	ErrAppointmentConflict       = errors.New("bay is already booked in this interval")
+	ErrNoBayAvailable            = errors.New("no bay is available in this interval")
+	ErrVehicleNotOwnedByCustomer = errors.New("vehicle does not belong to the customer")
+	ErrInvalidAvailabilitySearch = errors.New("availability search needs a start date")
+)
+
+type IAppointmentUseCase interface {
+	CreateBay(ctx context.Context, bay entities.Bay) (*entities.Bay, error)
+	ListBays(ctx context.Context) ([]entities.Bay, error)
+	UpdateBay(ctx context.Context, bay entities.Bay) (*entities.Bay, error)
+	CreateAppointment(ctx context.Context, request entities.AppointmentRequest) (*entities.Appointment, error)
+	GetAppointment(ctx context.Context, id uint) (*entities.Appointment, error)
+	ListAppointments(ctx context.Context, from, to time.Time) ([]entities.Appointment, error)
+	RescheduleAppointment(ctx context.Context, id uint, request entities.RescheduleRequest) (*entities.Appointment, error)
+	CancelAppointment(ctx context.Context, id uint) (*entities.Appointment, error)
+	ConvertAppointment(ctx context.Context, id uint) (*entities.ServiceOrder, error)
+	FindAvailability(ctx context.Context, from time.Time, serviceIDs []uint, limit int) ([]entities.AvailableSlot, error)
+}
+
+type AppointmentUseCase struct {
+	repo                appointment.IAppointmentRepository
+	customerRepo        customerRepo.ICustomerRepository
+	vehicleRepo         vehicles.VehicleRepositoryInterface
+	serviceRepo         service.IServiceRepo
+	serviceOrderUseCase IServiceOrderUseCase
+	schedule            utils.ScheduleConfig
+	now                 func() time.Time
+}
+
+var _ IAppointmentUseCase = (*AppointmentUseCase)(nil)
+
+func NewAppointmentUseCase(
+	repo appointment.IAppointmentRepository,
+	customerRepo customerRepo.ICustomerRepository,
+	vehicleRepo vehicles.VehicleRepositoryInterface,
+	serviceRepo service.IServiceRepo,
+	serviceOrderUseCase IServiceOrderUseCase,
+	schedule utils.ScheduleConfig,
+) *AppointmentUseCase {
+	return &AppointmentUseCase{
+		repo:                repo,
+		customerRepo:        customerRepo,
+		vehicleRepo:         vehicleRepo,
+		serviceRepo:         serviceRepo,
+		serviceOrderUseCase: serviceOrderUseCase,
+		schedule:            schedule,
+		now:                 time.Now,
+	}
+}
+
+func (u *AppointmentUseCase) CreateBay(ctx context.Context, bay entities.Bay) (*entities.Bay, error) {
+	if strings.TrimSpace(bay.Name) == "" {
+		return nil, ErrInvalidBay
+	}
+	bayDto := dto.BayDTO{Name: strings.TrimSpace(bay.Name), Active: true}
+	if err := u.repo.CreateBay(ctx, &bayDto); err != nil {
+		log.Error().Msgf("Error creating bay: %v", err)
+		return nil, err
+	}
+	result := bayDto.ToDomain()
+	return &result, nil
+}
+
+func (u *AppointmentUseCase) ListBays(ctx context.Context) ([]entities.Bay, error) {
+	bays, err := u.repo.ListBays(ctx, false)
+	if err != nil {
+		log.Error().Msgf("Error listing bays: %v", err)
+		return nil, err
+	}
+	result := make([]entities.Bay, 0, len(bays))
+	for _, bay := range bays {
+		result = append(result, bay.ToDomain())
+	}
+	return result, nil
+}
+
+// UpdateBay renames a bay or takes it in and out of service. Deactivating a bay keeps the visits
+// already booked on it.
+func (u *AppointmentUseCase) UpdateBay(ctx context.Context, bay entities.Bay) (*entities.Bay, error) {
+	if strings.TrimSpace(bay.Name) == "" {
+		return nil, ErrInvalidBay
+	}
+	bayDto, err := u.repo.GetBay(ctx, bay.ID)
+	if err != nil {
+		log.Error().Msgf("Error getting bay %d: %v", bay.ID, err)
+		return nil, err
+	}
+	if bayDto == nil {
+		return nil, ErrBayNotFound
+	}
+	bayDto.Name = strings.TrimSpace(bay.Name)
+	bayDto.Active = bay.Active
+	if err := u.repo.UpdateBay(ctx, bayDto); err != nil {
+		log.Error().Msgf("Error updating bay %d: %v", bay.ID, err)
+		return nil, err
+	}
+	result := bayDto.ToDomain()
+	return &result, nil
+}
+
+// CreateAppointment books a visit of the customer's vehicle. It lasts the standard time of the
+// desired services, rounded up to whole slots, and must fit within the working hours.
+func (u *AppointmentUseCase) CreateAppointment(ctx context.Context, request entities.AppointmentRequest) (*entities.Appointment, error) {
+	if err := u.validateCustomerVehicle(request.CustomerID, request.VehicleID); err != nil {
+		return nil, err
+	}
+	services, duration, err := u.loadServices(ctx, request.ServiceIDs)
+	if err != nil {
+		return nil, err
+	}
+	startsAt := request.StartsAt.In(u.schedule.Location)
+	endsAt := startsAt.Add(duration)
+	if err := u.validatePeriod(startsAt, endsAt); err != nil {
+		return nil, err
+	}
+	bayID, err := u.chooseBay(ctx, request.BayID, startsAt, endsAt, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	appointmentDto := dto.AppointmentDTO{
+		CustomerID: request.CustomerID,
+		VehicleID:  request.VehicleID,
+		BayID:      bayID,
+		Services:   services,
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
+		Status:     valueobject.AppointmentScheduled.String(),
+		Notes:      request.Notes,
+		CreatedBy:  request.CreatedBy,
+	}
+	saved, err := u.repo.Save(ctx, &appointmentDto)
+	if err != nil {
+		log.Error().Msgf("Error creating appointment: %v", err)
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrAppointmentConflict
+	}
+	return u.GetAppointment(ctx, appointmentDto.ID)
+}
+
+func (u *AppointmentUseCase) GetAppointment(ctx context.Context, id uint) (*entities.Appointment, error) {
+	appointmentDto, err := u.getAppointment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := appointmentDto.ToDomain()
+	return &result, nil
+}
+
+// ListAppointments returns the agenda of the appointments starting within [from, to)
+func (u *AppointmentUseCase) ListAppointments(ctx context.Context, from, to time.Time) ([]entities.Appointment, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidReportPeriod
+	}
+	appointments, err := u.repo.List(ctx, from, to)
+	if err != nil {
+		log.Error().Msgf("Error listing appointments: %v", err)
+		return nil, err
+	}
+	result := make([]entities.Appointment, 0, len(appointments))
+	for _, appointmentDto := range appointments {
+		result = append(result, appointmentDto.ToDomain())
+	}
+	return result, nil
+}
+
+// RescheduleAppointment moves a scheduled appointment, keeping its length. Without a bay, the
+// current one is kept when it is free in the new interval.
+func (u *AppointmentUseCase) RescheduleAppointment(ctx context.Context, id uint, request entities.RescheduleRequest) (*entities.Appointment, error) {
+	appointmentDto, err := u.getAppointment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if valueobject.ParseAppointmentStatus(appointmentDto.Status) != valueobject.AppointmentScheduled {
+		return nil, ErrAppointmentNotScheduled
+	}
+	startsAt := request.StartsAt.In(u.schedule.Location)
+	endsAt := startsAt.Add(appointmentDto.EndsAt.Sub(appointmentDto.StartsAt))
+	if err := u.validatePeriod(startsAt, endsAt); err != nil {
+		return nil, err
+	}
+	preferred := request.BayID
+	if preferred == nil {
+		if free, err := u.freeBays(ctx, startsAt, endsAt, id); err == nil && containsUint(free, appointmentDto.BayID) {
+			preferred = &appointmentDto.BayID
+		}
+	}
+	bayID, err := u.chooseBay(ctx, preferred, startsAt, endsAt, id)
+	if err != nil {
+		return nil, err
+	}
+
+	appointmentDto.BayID = bayID
+	appointmentDto.StartsAt = startsAt
+	appointmentDto.EndsAt = endsAt
+	saved, err := u.repo.Save(ctx, appointmentDto)
+	if err != nil {
+		log.Error().Msgf("Error rescheduling appointment %d: %v", id, err)
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrAppointmentConflict
+	}
+	return u.GetAppointment(ctx, id)
+}
+
+// CancelAppointment frees the bay of a scheduled appointment
+func (u *AppointmentUseCase) CancelAppointment(ctx context.Context, id uint) (*entities.Appointment, error) {
+	appointmentDto, err := u.getAppointment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if valueobject.ParseAppointmentStatus(appointmentDto.Status) != valueobject.AppointmentScheduled {
+		return nil, ErrAppointmentNotScheduled
+	}
+	if err := u.repo.UpdateStatus(ctx, id, valueobject.AppointmentCancelled, nil); err != nil {
+		log.Error().Msgf("Error cancelling appointment %d: %v", id, err)
+		return nil, err
+	}
+	appointmentDto.Status = valueobject.AppointmentCancelled.String()
+	result := appointmentDto.ToDomain()
+	return &result, nil
+}
+
+// ConvertAppointment opens the service order of a customer who arrived for their appointment. The
+// order starts as RECEBIDA, like one opened at the counter, and the appointment keeps a reference to it.
+func (u *AppointmentUseCase) ConvertAppointment(ctx context.Context, id uint) (*entities.ServiceOrder, error) {
+	appointmentDto, err := u.getAppointment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if valueobject.ParseAppointmentStatus(appointmentDto.Status) != valueobject.AppointmentScheduled {
+		return nil, ErrAppointmentNotScheduled
+	}
+
+	serviceOrder, err := u.serviceOrderUseCase.CreateServiceOrder(ctx, entities.ServiceOrder{
+		CustomerID: appointmentDto.CustomerID,
+		VehicleID:  appointmentDto.VehicleID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.UpdateStatus(ctx, id, valueobject.AppointmentConverted, &serviceOrder.ID); err != nil {
+		log.Error().Msgf("Error marking appointment %d as converted into service order %d: %v", id, serviceOrder.ID, err)
+		return nil, err
+	}
+	return serviceOrder, nil
+}
+
+// FindAvailability returns up to limit slots, from the first one at or after from, in which a visit
+// for the given services fits within the working hours and at least one bay is free
+func (u *AppointmentUseCase) FindAvailability(ctx context.Context, from time.Time, serviceIDs []uint, limit int) ([]entities.AvailableSlot, error) {
+	if from.IsZero() {
+		return nil, ErrInvalidAvailabilitySearch
+	}
+	if limit <= 0 {
+		limit = defaultAvailabilityLimit
+	}
+	if limit > maxAvailabilityLimit {
+		limit = maxAvailabilityLimit
+	}
+	_, duration, err := u.loadServices(ctx, serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if now := u.now(); from.Before(now) {
+		from = now
+	}
+	start := u.alignToSlot(from.In(u.schedule.Location))
+	until := time.Date(start.Year(), start.Month(), start.Day()+u.schedule.SearchDays, 0, 0, 0, 0, u.schedule.Location)
+
+	bays, err := u.repo.ListBays(ctx, true)
+	if err != nil {
+		log.Error().Msgf("Error listing bays: %v", err)
+		return nil, err
+	}
+	scheduled, err := u.repo.ListScheduled(ctx, start, until)
+	if err != nil {
+		log.Error().Msgf("Error listing scheduled appointments: %v", err)
+		return nil, err
+	}
+
+	slots := []entities.AvailableSlot{}
+	for slotStart := start; slotStart.Before(until) && len(slots) < limit; slotStart = slotStart.Add(u.schedule.SlotDuration) {
+		slotEnd := slotStart.Add(duration)
+		if !u.withinWorkingHours(slotStart, slotEnd) {
+			continue
+		}
+		if free := freeBayIDs(bays, scheduled, slotStart, slotEnd, 0); len(free) > 0 {
+			slots = append(slots, entities.AvailableSlot{StartsAt: slotStart, EndsAt: slotEnd, BayIDs: free})
+		}
+	}
+	return slots, nil
+}
+
+func (u *AppointmentUseCase) getAppointment(ctx context.Context, id uint) (*dto.AppointmentDTO, error) {
+	appointmentDto, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Error().Msgf("Error getting appointment %d: %v", id, err)
+		return nil, err
+	}
+	if appointmentDto == nil {
+		return nil, ErrAppointmentNotFound
+	}
+	return appointmentDto, nil
+}
+
+func (u *AppointmentUseCase) validateCustomerVehicle(customerID, vehicleID uint) error {
+	customer, err := u.customerRepo.GetByID(customerID)
+	if err != nil {
+		log.Error().Msgf("Error finding customer with id %d: %v", customerID, err)
+		return err
+	}
+	if customer == nil {
+		return ErrCustomerNotFound
+	}
+	vehicle, err := u.vehicleRepo.FindByID(vehicleID)
+	if err != nil {
+		log.Error().Msgf("Error finding vehicle with id %d: %v", vehicleID, err)
+		return err
+	}
+	if vehicle == nil {
+		return ErrVehicleNotFound
+	}
+	if vehicle.CustomerID != customerID {
+		return ErrVehicleNotOwnedByCustomer
+	}
+	return nil
+}
+
+// loadServices returns the desired services and the length of a visit for them: their standard
+// time rounded up to whole slots, and a single slot when none of them has a standard time
+func (u *AppointmentUseCase) loadServices(ctx context.Context, serviceIDs []uint) ([]dto.ServiceDTO, time.Duration, error) {
+	var services []dto.ServiceDTO
+	minutes := 0
+	for _, id := range serviceIDs {
+		svc, err := u.serviceRepo.GetByID(ctx, id)
+		if err != nil {
+			log.Error().Msgf("Error finding service with id %d: %v", id, err)
+			return nil, 0, err
+		}
+		if svc.ID == 0 {
+			return nil, 0, ErrServiceNotFound
+		}
+		services = append(services, dto.ServiceDTO{ID: svc.ID})
+		minutes += svc.StandardMinutes
+	}
+
+	slot := u.schedule.SlotDuration
+	slots := (time.Duration(minutes)*time.Minute + slot - 1) / slot
+	if slots < 1 {
+		slots = 1
+	}
+	return services, slots * slot, nil
+}
+
+func (u *AppointmentUseCase) validatePeriod(startsAt, endsAt time.Time) error {
+	if !startsAt.After(u.now()) {
+		return ErrAppointmentInPast
+	}
+	if !u.withinWorkingHours(startsAt, endsAt) {
+		return ErrAppointmentOutsideHours
+	}
+	return nil
+}
+
+// withinWorkingHours tells whether [startsAt, endsAt) is on a working day, between the opening and
+// the closing hour of that day
+func (u *AppointmentUseCase) withinWorkingHours(startsAt, endsAt time.Time) bool {
+	local := startsAt.In(u.schedule.Location)
+	working := false
+	for _, day := range u.schedule.WorkingDays {
+		if local.Weekday() == day {
+			working = true
+			break
+		}
+	}
+	if !working {
+		return false
+	}
+	opening := time.Date(local.Year(), local.Month(), local.Day(), u.schedule.OpeningHour, 0, 0, 0, u.schedule.Location)
+	closing := time.Date(local.Year(), local.Month(), local.Day(), u.schedule.ClosingHour, 0, 0, 0, u.schedule.Location)
+	return !local.Before(opening) && !endsAt.After(closing)
+}
+
+// alignToSlot rounds t up to the next slot boundary, counting slots from midnight
+func (u *AppointmentUseCase) alignToSlot(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	slot := u.schedule.SlotDuration
+	return midnight.Add((t.Sub(midnight) + slot - 1) / slot * slot)
+}
+
+// chooseBay returns the preferred bay when it is active, or else the first active bay free in
+// [startsAt, endsAt). ignoreID is the appointment being rescheduled, which does not block itself.
+func (u *AppointmentUseCase) chooseBay(ctx context.Context, preferred *uint, startsAt, endsAt time.Time, ignoreID uint) (uint, error) {
+	if preferred != nil {
+		bay, err := u.repo.GetBay(ctx, *preferred)
+		if err != nil {
+			log.Error().Msgf("Error getting bay %d: %v", *preferred, err)
+			return 0, err
+		}
+		if bay == nil {
+			return 0, ErrBayNotFound
+		}
+		if !bay.Active {
+			return 0, ErrBayInactive
+		}
+		return bay.ID, nil
+	}
+
+	free, err := u.freeBays(ctx, startsAt, endsAt, ignoreID)
+	if err != nil {
+		return 0, err
+	}
+	if len(free) == 0 {
+		return 0, ErrNoBayAvailable
+	}
+	return free[0], nil
+}
+
+func (u *AppointmentUseCase) freeBays(ctx context.Context, startsAt, endsAt time.Time, ignoreID uint) ([]uint, error) {
+	bays, err := u.repo.ListBays(ctx, true)
+	if err != nil {
+		log.Error().Msgf("Error listing bays: %v", err)
+		return nil, err
+	}
+	scheduled, err := u.repo.ListScheduled(ctx, startsAt, endsAt)
+	if err != nil {
+		log.Error().Msgf("Error listing scheduled appointments: %v", err)
+		return nil, err
+	}
+	return freeBayIDs(bays, scheduled, startsAt, endsAt, ignoreID), nil
+}
+
+func freeBayIDs(bays []dto.BayDTO, scheduled []dto.AppointmentDTO, startsAt, endsAt time.Time, ignoreID uint) []uint {
+	var free []uint
+	for _, bay := range bays {
+		busy := false
+		for _, a := range scheduled {
+			if a.ID != ignoreID && a.BayID == bay.ID && a.StartsAt.Before(endsAt) && a.EndsAt.After(startsAt) {
+				busy = true
+				break
+			}
+		}
+		if !busy {
+			free = append(free, bay.ID)
+		}
+	}
+	return free
+}
+
+func containsUint(values []uint, value uint) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
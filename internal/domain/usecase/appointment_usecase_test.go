@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+	"mecanica_xpto/pkg/utils"
+)
+
+var (
+	appointmentTestZone = time.FixedZone("BRT", -3*60*60)
+	// Monday, 9h in the workshop
+	appointmentTestNow = time.Date(2024, 5, 13, 9, 0, 0, 0, appointmentTestZone)
+)
+
+type appointmentTestDeps struct {
+	repo                *mocks.MockAppointmentRepository
+	customerRepo        *MockCustomerRepository
+	vehicleRepo         *MockVehicleRepository
+	serviceRepo         *MockServiceRepository
+	serviceOrderUseCase *mocks.MockServiceOrderUseCase
+}
+
+func newAppointmentTestUseCase() (*AppointmentUseCase, appointmentTestDeps) {
+	deps := appointmentTestDeps{
+		repo:                new(mocks.MockAppointmentRepository),
+		customerRepo:        new(MockCustomerRepository),
+		vehicleRepo:         new(MockVehicleRepository),
+		serviceRepo:         new(MockServiceRepository),
+		serviceOrderUseCase: new(mocks.MockServiceOrderUseCase),
+	}
+	uc := NewAppointmentUseCase(deps.repo, deps.customerRepo, deps.vehicleRepo, deps.serviceRepo, deps.serviceOrderUseCase, utils.ScheduleConfig{
+		SlotDuration: 30 * time.Minute,
+		OpeningHour:  8,
+		ClosingHour:  18,
+		WorkingDays:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		SearchDays:   7,
+		Location:     appointmentTestZone,
+	})
+	uc.now = func() time.Time { return appointmentTestNow }
+	return uc, deps
+}
+
+func (d appointmentTestDeps) expectCustomerVehicle() {
+	d.customerRepo.On("GetByID", uint(1)).Return(&dto.CustomerDTO{ID: 1}, nil)
+	d.vehicleRepo.On("FindByID", uint(5)).Return(&dto.VehicleDTO{ID: 5, CustomerID: 1}, nil)
+}
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, 5, day, hour, minute, 0, 0, appointmentTestZone)
+}
+
+func TestAppointmentUseCase_CreateAppointment(t *testing.T) {
+	ctx := context.Background()
+	bays := []dto.BayDTO{{ID: 1, Name: "Elevador 1", Active: true}, {ID: 2, Name: "Elevador 2", Active: true}}
+
+	t.Run("books the first free bay for the standard time of the services, rounded up to slots", func(t *testing.T) {
+		uc, deps := newAppointmentTestUseCase()
+		deps.expectCustomerVehicle()
+		deps.serviceRepo.On("GetByID", ctx, uint(3)).Return(entities.Service{ID: 3, StandardMinutes: 45}, nil)
+		deps.repo.On("ListBays", ctx, true).Return(bays, nil)
+		deps.repo.On("ListScheduled", ctx, mock.Anything, mock.Anything).Return([]dto.AppointmentDTO{
+			{ID: 8, BayID: 1, StartsAt: at(14, 10, 30), EndsAt: at(14, 11, 30)},
+		}, nil)
+		deps.repo.On("Save", ctx, mock.MatchedBy(func(a *dto.AppointmentDTO) bool {
+			return a.BayID == 2 && a.StartsAt.Equal(at(14, 10, 0)) && a.EndsAt.Equal(at(14, 11, 0)) &&
+				a.Status == valueobject.AppointmentScheduled.String() && len(a.Services) == 1
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*dto.AppointmentDTO).ID = 20
+		}).Return(true, nil)
+		deps.repo.On("GetByID", ctx, uint(20)).Return(&dto.AppointmentDTO{ID: 20, BayID: 2, Status: "AGENDADO"}, nil)
+
+		result, err := uc.CreateAppointment(ctx, entities.AppointmentRequest{
+			CustomerID: 1, VehicleID: 5, ServiceIDs: []uint{3}, StartsAt: at(14, 10, 0), CreatedBy: "recepcao@xpto.com",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), result.BayID)
+		deps.repo.AssertExpectations(t)
+	})
+
+	t.Run("returns no bay available when every bay is booked", func(t *testing.T) {
+		uc, deps := newAppointmentTestUseCase()
+		deps.expectCustomerVehicle()
+		deps.repo.On("ListBays", ctx, true).Return(bays, nil)
+		deps.repo.On("ListScheduled", ctx, mock.Anything, mock.Anything).Return([]dto.AppointmentDTO{
+			{ID: 8, BayID: 1, StartsAt: at(14, 10, 0), EndsAt: at(14, 11, 0)},
+			{ID: 9, BayID: 2, StartsAt: at(14, 9, 30), EndsAt: at(14, 10, 30)},
+		}, nil)
+
+		_, err := uc.CreateAppointment(ctx, entities.AppointmentRequest{CustomerID: 1, VehicleID: 5, StartsAt: at(14, 10, 0)})
+
+		assert.ErrorIs(t, err, ErrNoBayAvailable)
+		deps.repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns a conflict when the bay is taken while booking", func(t *testing.T) {
+		uc, deps := newAppointmentTestUseCase()
+		deps.expectCustomerVehicle()
+		deps.repo.On("GetBay", ctx, uint(1)).Return(&bays[0], nil)
+		deps.repo.On("Save", ctx, mock.Anything).Return(false, nil)
+
+		_, err := uc.CreateAppointment(ctx, entities.AppointmentRequest{CustomerID: 1, VehicleID: 5, StartsAt: at(14, 10, 0), BayID: uintPtr(1)})
+
+		assert.ErrorIs(t, err, ErrAppointmentConflict)
+	})
+
+	t.Run("rejects visits outside the working hours", func(t *testing.T) {
+		uc, deps := newAppointmentTestUseCase()
+		deps.expectCustomerVehicle()
+		deps.serviceRepo.On("GetByID", ctx, uint(3)).Return(entities.Service{ID: 3, StandardMinutes: 60}, nil)
+
+		for _, startsAt := range []time.Time{at(14, 17, 30), at(14, 7, 30), at(19, 10, 0)} {
+			_, err := uc.CreateAppointment(ctx, entities.AppointmentRequest{CustomerID: 1, VehicleID: 5, ServiceIDs: []uint{3}, StartsAt: startsAt})
+			assert.ErrorIs(t, err, ErrAppointmentOutsideHours, startsAt)
+		}
+	})
+
+	t.Run("rejects visits in the past", func(t *testing.T) {
+		uc, deps := newAppointmentTestUseCase()
+		deps.expectCustomerVehicle()
+
+		_, err := uc.CreateAppointment(ctx, entities.AppointmentRequest{CustomerID: 1, VehicleID: 5, StartsAt: at(13, 8, 30)})
+
+		assert.ErrorIs(t, err, ErrAppointmentInPast)
+	})
+
+	t.Run("rejects a vehicle of another customer", func(t *testing.T) {
+		uc, deps := newAppointmentTestUseCase()
+		deps.customerRepo.On("GetByID", uint(1)).Return(&dto.CustomerDTO{ID: 1}, nil)
+		deps.vehicleRepo.On("FindByID", uint(5)).Return(&dto.VehicleDTO{ID: 5, CustomerID: 2}, nil)
+
+		_, err := uc.CreateAppointment(ctx, entities.AppointmentRequest{CustomerID: 1, VehicleID: 5, StartsAt: at(14, 10, 0)})
+
+		assert.ErrorIs(t, err, ErrVehicleNotOwnedByCustomer)
+	})
+}
+
+func TestAppointmentUseCase_ConvertAppointment(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("opens a service order and marks the appointment as converted", func(t *testing.T) {
+		uc, deps := newAppointmentTestUseCase()
+		deps.repo.On("GetByID", ctx, uint(20)).Return(&dto.AppointmentDTO{ID: 20, CustomerID: 1, VehicleID: 5, Status: "AGENDADO"}, nil)
+		deps.serviceOrderUseCase.On("CreateServiceOrder", ctx, entities.ServiceOrder{CustomerID: 1, VehicleID: 5}).
+			Return(&entities.ServiceOrder{ID: 40, ServiceOrderStatus: valueobject.StatusRecebida}, nil)
+		deps.repo.On("UpdateStatus", ctx, uint(20), valueobject.AppointmentConverted, mock.MatchedBy(func(id *uint) bool {
+			return id != nil && *id == 40
+		})).Return(nil)
+
+		serviceOrder, err := uc.ConvertAppointment(ctx, 20)
+
+		require.NoError(t, err)
+		assert.Equal(t, valueobject.StatusRecebida, serviceOrder.ServiceOrderStatus)
+		deps.repo.AssertExpectations(t)
+	})
+
+	t.Run("does not convert a cancelled appointment", func(t *testing.T) {
+		uc, deps := newAppointmentTestUseCase()
+		deps.repo.On("GetByID", ctx, uint(20)).Return(&dto.AppointmentDTO{ID: 20, Status: "CANCELADO"}, nil)
+
+		_, err := uc.ConvertAppointment(ctx, 20)
+
+		assert.ErrorIs(t, err, ErrAppointmentNotScheduled)
+		deps.serviceOrderUseCase.AssertNotCalled(t, "CreateServiceOrder", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAppointmentUseCase_FindAvailability(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("skips booked slots and the hours the workshop is closed", func(t *testing.T) {
+		uc, deps := newAppointmentTestUseCase()
+		deps.serviceRepo.On("GetByID", ctx, uint(3)).Return(entities.Service{ID: 3, StandardMinutes: 60}, nil)
+		deps.repo.On("ListBays", ctx, true).Return([]dto.BayDTO{{ID: 1, Active: true}}, nil)
+		deps.repo.On("ListScheduled", ctx, at(17, 17, 0), mock.Anything).Return([]dto.AppointmentDTO{
+			{ID: 8, BayID: 1, StartsAt: at(17, 16, 30), EndsAt: at(17, 17, 30)},
+			{ID: 9, BayID: 1, StartsAt: at(20, 8, 0), EndsAt: at(20, 9, 0)},
+		}, nil)
+
+		// Friday 16h40: 17h is taken until 17h30 and 17h30 would end after closing; the weekend is skipped
+		slots, err := uc.FindAvailability(ctx, at(17, 16, 40), []uint{3}, 3)
+
+		require.NoError(t, err)
+		require.Len(t, slots, 3)
+		assert.True(t, slots[0].StartsAt.Equal(at(20, 9, 0)), slots[0].StartsAt)
+		assert.True(t, slots[0].EndsAt.Equal(at(20, 10, 0)))
+		assert.True(t, slots[1].StartsAt.Equal(at(20, 9, 30)))
+		assert.True(t, slots[2].StartsAt.Equal(at(20, 10, 0)))
+		assert.Equal(t, []uint{1}, slots[0].BayIDs)
+	})
+}
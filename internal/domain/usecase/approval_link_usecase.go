@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/additional_repair"
+	"mecanica_xpto/internal/domain/repository/approval"
+	corporateaccount "mecanica_xpto/internal/domain/repository/corporate_account"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/pkg/utils"
+)
+
+// maxConsentUserAgentLength is the size of the user agent column of the approval links
+const maxConsentUserAgentLength = 255
+
+var (
+	ErrApprovalLinkNotFound = errors.New("approval link not found")
+	ErrApprovalLinkExpired  = errors.New("approval link expired")
+	ErrApprovalLinkUsed     = errors.New("approval link was already used")
+	ErrNotAwaitingApproval  = errors.New("estimate or additional repair is not awaiting customer approval")
+)
+
+type IApprovalLinkUseCase interface {
+	CreateEstimateLink(ctx context.Context, serviceOrderID uint, approverContactID *uint, createdBy string) (*entities.ApprovalLink, error)
+	CreateAdditionalRepairLink(ctx context.Context, additionalRepairID uint, createdBy string) (*entities.ApprovalLink, error)
+	ListApprovalLinks(ctx context.Context, serviceOrderID uint) ([]entities.ApprovalLink, error)
+	GetApprovalRequest(ctx context.Context, token string) (*entities.ApprovalRequest, error)
+	Decide(ctx context.Context, token string, decision string, consent entities.ApprovalConsent) (*entities.ApprovalLink, error)
+}
+
+type ApprovalLinkUseCase struct {
+	repo                    approval.IApprovalLinkRepository
+	serviceOrderRepo        serviceorder.IServiceOrderRepository
+	additionalRepairRepo    additional_repair.IAdditionalRepairRepository
+	corporateRepo           corporateaccount.ICorporateAccountRepository
+	serviceOrderUseCase     IServiceOrderUseCase
+	additionalRepairUseCase IAdditionalRepairUseCase
+	tokens                  *utils.ApprovalTokenService
+	ttl                     time.Duration
+	baseURL                 string
+	now                     func() time.Time
+}
+
+var _ IApprovalLinkUseCase = (*ApprovalLinkUseCase)(nil)
+
+func NewApprovalLinkUseCase(repo approval.IApprovalLinkRepository, serviceOrderRepo serviceorder.IServiceOrderRepository, additionalRepairRepo additional_repair.IAdditionalRepairRepository, corporateRepo corporateaccount.ICorporateAccountRepository, serviceOrderUseCase IServiceOrderUseCase, additionalRepairUseCase IAdditionalRepairUseCase, tokens *utils.ApprovalTokenService, ttl time.Duration, baseURL string) *ApprovalLinkUseCase {
+	return &ApprovalLinkUseCase{
+		repo:                    repo,
+		serviceOrderRepo:        serviceOrderRepo,
+		additionalRepairRepo:    additionalRepairRepo,
+		corporateRepo:           corporateRepo,
+		serviceOrderUseCase:     serviceOrderUseCase,
+		additionalRepairUseCase: additionalRepairUseCase,
+		tokens:                  tokens,
+		ttl:                     ttl,
+		baseURL:                 strings.TrimRight(baseURL, "/"),
+		now:                     time.Now,
+	}
+}
+
+// CreateEstimateLink creates the link the customer uses to decide on the estimate of a service order.
+// The link of a company naming estimate approvers is sent to one of them, who the decision is
+// recorded for.
+func (u *ApprovalLinkUseCase) CreateEstimateLink(ctx context.Context, serviceOrderID uint, approverContactID *uint, createdBy string) (*entities.ApprovalLink, error) {
+	serviceOrderDto, err := u.serviceOrderRepo.GetByID(serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error finding service order with id %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	if serviceOrderDto == nil {
+		return nil, ErrServiceOrderNotFound
+	}
+	if !serviceOrderDto.ServiceOrderStatus.ToDomain().IsAguardandoAprovacao() {
+		return nil, ErrNotAwaitingApproval
+	}
+	if err := checkEstimateApprover(ctx, u.corporateRepo, serviceOrderDto.CustomerID, approverContactID); err != nil {
+		return nil, err
+	}
+	return u.createLink(ctx, valueobject.ApprovalSubjectEstimate, serviceOrderID, nil, approverContactID, createdBy)
+}
+
+// CreateAdditionalRepairLink creates the link the customer uses to decide on a submitted additional repair
+func (u *ApprovalLinkUseCase) CreateAdditionalRepairLink(ctx context.Context, additionalRepairID uint, createdBy string) (*entities.ApprovalLink, error) {
+	additionalRepairDto, err := u.additionalRepairRepo.GetByID(additionalRepairID)
+	if err != nil {
+		log.Error().Msgf("Error finding additional repair with id %d: %v", additionalRepairID, err)
+		return nil, err
+	}
+	if additionalRepairDto == nil {
+		return nil, ErrAdditionalRepairNotFound
+	}
+	if !additionalRepairDto.ARStatus.ToDomain().IsAguardandoAprovacao() {
+		return nil, ErrNotAwaitingApproval
+	}
+	return u.createLink(ctx, valueobject.ApprovalSubjectAdditionalRepair, additionalRepairDto.ServiceOrderID, &additionalRepairID, nil, createdBy)
+}
+
+// ListApprovalLinks lists the links of a service order with the decisions and the consent recorded on them
+func (u *ApprovalLinkUseCase) ListApprovalLinks(ctx context.Context, serviceOrderID uint) ([]entities.ApprovalLink, error) {
+	links, err := u.repo.ListByServiceOrderID(ctx, serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error listing approval links of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	result := make([]entities.ApprovalLink, 0, len(links))
+	for _, link := range links {
+		result = append(result, link.ToDomain())
+	}
+	return result, nil
+}
+
+// GetApprovalRequest returns what the customer is asked to approve. Customer and payment data are
+// left out, as the link is opened without authentication.
+func (u *ApprovalLinkUseCase) GetApprovalRequest(ctx context.Context, token string) (*entities.ApprovalRequest, error) {
+	link, err := u.validLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	request := &entities.ApprovalRequest{
+		Subject:   valueobject.ParseApprovalSubject(link.Subject),
+		ExpiresAt: link.ExpiresAt,
+	}
+
+	if request.Subject.IsAdditionalRepair() {
+		additionalRepairDto, err := u.additionalRepairRepo.GetByID(*link.AdditionalRepairID)
+		if err != nil {
+			return nil, err
+		}
+		if additionalRepairDto == nil {
+			return nil, ErrAdditionalRepairNotFound
+		}
+		additionalRepair := additionalRepairDto.ToDomain()
+		additionalRepair.History = nil
+		request.AdditionalRepair = &additionalRepair
+		return request, nil
+	}
+
+	serviceOrderDto, err := u.serviceOrderRepo.GetByIDWithItems(link.ServiceOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if serviceOrderDto == nil {
+		return nil, ErrServiceOrderNotFound
+	}
+	serviceOrder := serviceOrderDto.ToDomain()
+	serviceOrder.Customer = nil
+	serviceOrder.Payment = nil
+	serviceOrder.DiscountApproval = nil
+	serviceOrder.AdditionalRepairs = nil
+	request.ServiceOrder = serviceOrder
+	return request, nil
+}
+
+// Decide applies the customer decision of an approval link and records the IP address and user
+// agent it came from. The link is claimed before the decision is applied, so concurrent requests
+// cannot both use it, and released again when the decision fails.
+func (u *ApprovalLinkUseCase) Decide(ctx context.Context, token string, decision string, consent entities.ApprovalConsent) (*entities.ApprovalLink, error) {
+	if decision != ApprovalApproved && decision != ApprovalDenied {
+		return nil, ErrInvalidApprovalStatus
+	}
+	link, err := u.validLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(consent.UserAgent) > maxConsentUserAgentLength {
+		consent.UserAgent = consent.UserAgent[:maxConsentUserAgentLength]
+	}
+	decidedAt := u.now()
+	claimed, err := u.repo.MarkUsed(ctx, link.ID, decision, consent, decidedAt)
+	if err != nil {
+		log.Error().Msgf("Error recording decision of approval link %d: %v", link.ID, err)
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrApprovalLinkUsed
+	}
+
+	if err := u.applyDecision(ctx, link, decision); err != nil {
+		log.Error().Msgf("Error applying decision of approval link %d: %v", link.ID, err)
+		if releaseErr := u.repo.ReleaseUse(ctx, link.ID); releaseErr != nil {
+			log.Error().Msgf("Error releasing approval link %d: %v", link.ID, releaseErr)
+		}
+		return nil, err
+	}
+
+	link.Decision = decision
+	link.DecidedAt = &decidedAt
+	link.IP = consent.IP
+	link.UserAgent = consent.UserAgent
+	result := link.ToDomain()
+	return &result, nil
+}
+
+func (u *ApprovalLinkUseCase) applyDecision(ctx context.Context, link *dto.ApprovalLinkDTO, decision string) error {
+	if valueobject.ParseApprovalSubject(link.Subject).IsAdditionalRepair() {
+		return u.additionalRepairUseCase.CustomerApprovalStatus(ctx, *link.AdditionalRepairID,
+			entities.AdditionalRepairStatusDTO{ApprovalStatus: decision})
+	}
+
+	status := valueobject.StatusAprovada
+	if decision == ApprovalDenied {
+		status = valueobject.StatusRejeitada
+	}
+	_, err := u.serviceOrderUseCase.UpdateServiceOrder(ctx, entities.ServiceOrder{
+		ID:                 link.ServiceOrderID,
+		ServiceOrderStatus: status,
+		ApproverContactID:  link.ApproverContactID,
+	}, ESTIMATE)
+	return err
+}
+
+func (u *ApprovalLinkUseCase) createLink(ctx context.Context, subject valueobject.ApprovalSubject, serviceOrderID uint, additionalRepairID, approverContactID *uint, createdBy string) (*entities.ApprovalLink, error) {
+	expiresAt := u.now().Add(u.ttl)
+	token, err := u.tokens.GenerateToken(expiresAt)
+	if err != nil {
+		log.Error().Msgf("Error generating approval token: %v", err)
+		return nil, err
+	}
+
+	linkDto := dto.ApprovalLinkDTO{
+		TokenHash:          utils.HashToken(token),
+		Subject:            subject.String(),
+		ServiceOrderID:     serviceOrderID,
+		AdditionalRepairID: additionalRepairID,
+		ApproverContactID:  approverContactID,
+		ExpiresAt:          expiresAt,
+		CreatedBy:          createdBy,
+	}
+	if err := u.repo.Create(ctx, &linkDto); err != nil {
+		log.Error().Msgf("Error creating approval link: %v", err)
+		return nil, err
+	}
+
+	link := linkDto.ToDomain()
+	link.Token = token
+	link.URL = u.baseURL + "/" + token
+	return &link, nil
+}
+
+// validLink checks the signature and expiry of the token before looking up the unused link it belongs to
+func (u *ApprovalLinkUseCase) validLink(ctx context.Context, token string) (*dto.ApprovalLinkDTO, error) {
+	if err := u.tokens.ValidateToken(token, u.now()); err != nil {
+		if errors.Is(err, utils.ErrApprovalTokenExpired) {
+			return nil, ErrApprovalLinkExpired
+		}
+		return nil, ErrApprovalLinkNotFound
+	}
+
+	link, err := u.repo.GetByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		log.Error().Msgf("Error finding approval link: %v", err)
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrApprovalLinkNotFound
+	}
+	if link.DecidedAt != nil {
+		return nil, ErrApprovalLinkUsed
+	}
+	return link, nil
+}
@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+	"mecanica_xpto/pkg/utils"
+)
+
+type approvalLinkMocks struct {
+	repo                    *mocks.MockApprovalLinkRepository
+	serviceOrderRepo        *mocks.MockServiceOrderRepository
+	additionalRepairRepo    *mocks.MockIAdditionalRepairRepository
+	corporateRepo           *mocks.MockCorporateAccountRepository
+	serviceOrderUseCase     *mocks.MockServiceOrderUseCase
+	additionalRepairUseCase *mocks.MockAdditionalRepairUseCase
+	tokens                  *utils.ApprovalTokenService
+}
+
+var approvalNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func newApprovalLinkUseCaseWithMocks(t *testing.T) (*ApprovalLinkUseCase, approvalLinkMocks) {
+	m := approvalLinkMocks{
+		repo:                    new(mocks.MockApprovalLinkRepository),
+		serviceOrderRepo:        new(mocks.MockServiceOrderRepository),
+		additionalRepairRepo:    mocks.NewMockIAdditionalRepairRepository(gomock.NewController(t)),
+		corporateRepo:           new(mocks.MockCorporateAccountRepository),
+		serviceOrderUseCase:     new(mocks.MockServiceOrderUseCase),
+		additionalRepairUseCase: new(mocks.MockAdditionalRepairUseCase),
+		tokens:                  utils.NewApprovalTokenService(&utils.ApprovalConfig{SecretKey: "test_secret"}),
+	}
+	u := NewApprovalLinkUseCase(m.repo, m.serviceOrderRepo, m.additionalRepairRepo, m.corporateRepo,
+		m.serviceOrderUseCase, m.additionalRepairUseCase, m.tokens, 72*time.Hour, "https://oficina.example.com/aprovar/")
+	u.now = func() time.Time { return approvalNow }
+	return u, m
+}
+
+// issuedToken returns a valid token and the stored link it belongs to
+func issuedToken(t *testing.T, m approvalLinkMocks, link dto.ApprovalLinkDTO) string {
+	token, err := m.tokens.GenerateToken(approvalNow.Add(time.Hour))
+	assert.NoError(t, err)
+	link.TokenHash = utils.HashToken(token)
+	m.repo.On("GetByTokenHash", mock.Anything, link.TokenHash).Return(&link, nil)
+	return token
+}
+
+func TestApprovalLinkUseCase_CreateEstimateLink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		m.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			CustomerID:         3,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusAguardandoAprovacao.String()},
+		}, nil)
+		m.corporateRepo.On("GetByCustomer", ctx, uint(3)).Return(nil, nil)
+		var stored *dto.ApprovalLinkDTO
+		m.repo.On("Create", ctx, mock.AnythingOfType("*dto.ApprovalLinkDTO")).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*dto.ApprovalLinkDTO)
+			stored.ID = 1
+		}).Return(nil)
+
+		link, err := u.CreateEstimateLink(ctx, 10, nil, "atendente@xpto.com")
+		assert.NoError(t, err)
+		assert.Equal(t, valueobject.ApprovalSubjectEstimate, link.Subject)
+		assert.Equal(t, approvalNow.Add(72*time.Hour), link.ExpiresAt)
+		assert.Equal(t, "https://oficina.example.com/aprovar/"+link.Token, link.URL)
+		assert.Equal(t, utils.HashToken(link.Token), stored.TokenHash)
+		assert.Equal(t, "atendente@xpto.com", stored.CreatedBy)
+		assert.NoError(t, m.tokens.ValidateToken(link.Token, approvalNow))
+	})
+
+	t.Run("Success - sent to an approver of the corporate account", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		m.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			CustomerID:         3,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusAguardandoAprovacao.String()},
+		}, nil)
+		m.corporateRepo.On("GetByCustomer", ctx, uint(3)).Return(&dto.CorporateAccountDTO{ID: 1, CustomerID: 3, Contacts: []dto.CorporateContactDTO{
+			{ID: 7, AccountID: 1, Name: "Ana", CanApproveEstimates: true},
+		}}, nil)
+		m.repo.On("Create", ctx, mock.MatchedBy(func(link *dto.ApprovalLinkDTO) bool {
+			return link.ApproverContactID != nil && *link.ApproverContactID == 7
+		})).Return(nil)
+
+		approverContactID := uint(7)
+		link, err := u.CreateEstimateLink(ctx, 10, &approverContactID, "atendente@xpto.com")
+		assert.NoError(t, err)
+		assert.Equal(t, &approverContactID, link.ApproverContactID)
+	})
+
+	t.Run("Error - corporate account requires an approver", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		m.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			CustomerID:         3,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusAguardandoAprovacao.String()},
+		}, nil)
+		m.corporateRepo.On("GetByCustomer", ctx, uint(3)).Return(&dto.CorporateAccountDTO{ID: 1, CustomerID: 3, Contacts: []dto.CorporateContactDTO{
+			{ID: 7, AccountID: 1, Name: "Ana", CanApproveEstimates: true},
+			{ID: 8, AccountID: 1, Name: "Bruno"},
+		}}, nil)
+
+		_, err := u.CreateEstimateLink(ctx, 10, nil, "atendente@xpto.com")
+		assert.ErrorIs(t, err, ErrEstimateApproverRequired)
+
+		driverContactID := uint(8)
+		_, err = u.CreateEstimateLink(ctx, 10, &driverContactID, "atendente@xpto.com")
+		assert.ErrorIs(t, err, ErrNotEstimateApprover)
+		m.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - estimate not awaiting approval", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		m.serviceOrderRepo.On("GetByID", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusAprovada.String()},
+		}, nil)
+
+		_, err := u.CreateEstimateLink(ctx, 10, nil, "atendente@xpto.com")
+		assert.ErrorIs(t, err, ErrNotAwaitingApproval)
+		m.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - service order not found", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		m.serviceOrderRepo.On("GetByID", uint(10)).Return(nil, nil)
+
+		_, err := u.CreateEstimateLink(ctx, 10, nil, "atendente@xpto.com")
+		assert.ErrorIs(t, err, ErrServiceOrderNotFound)
+	})
+}
+
+func TestApprovalLinkUseCase_CreateAdditionalRepairLink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		m.additionalRepairRepo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAguardandoAprovacao), nil)
+		m.repo.On("Create", ctx, mock.MatchedBy(func(link *dto.ApprovalLinkDTO) bool {
+			return link.Subject == "ADDITIONAL_REPAIR" && link.ServiceOrderID == 10 && *link.AdditionalRepairID == 1
+		})).Return(nil)
+
+		link, err := u.CreateAdditionalRepairLink(ctx, 1, "atendente@xpto.com")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, link.Token)
+	})
+
+	t.Run("Error - additional repair not submitted", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		m.additionalRepairRepo.EXPECT().GetByID(uint(1)).Return(additionalRepairWithStatus(valueobject.StatusARAberta), nil)
+
+		_, err := u.CreateAdditionalRepairLink(ctx, 1, "atendente@xpto.com")
+		assert.ErrorIs(t, err, ErrNotAwaitingApproval)
+	})
+}
+
+func TestApprovalLinkUseCase_GetApprovalRequest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - estimate without customer data", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		token := issuedToken(t, m, dto.ApprovalLinkDTO{ID: 1, Subject: "ESTIMATE", ServiceOrderID: 10})
+		m.serviceOrderRepo.On("GetByIDWithItems", uint(10)).Return(&dto.ServiceOrderDTO{
+			ID:                 10,
+			Customer:           dto.CustomerDTO{ID: 3, FullName: "Joao da Silva", CpfCnpj: "52998224725"},
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusAguardandoAprovacao.String()},
+			Estimate:           130,
+			Services:           []dto.ServiceDTO{{ID: 1, Name: "Troca de oleo", Price: 100}},
+		}, nil)
+
+		request, err := u.GetApprovalRequest(ctx, token)
+		assert.NoError(t, err)
+		assert.Equal(t, valueobject.ApprovalSubjectEstimate, request.Subject)
+		assert.Equal(t, 130.0, request.ServiceOrder.Estimate)
+		assert.Nil(t, request.ServiceOrder.Customer)
+		assert.Nil(t, request.AdditionalRepair)
+	})
+
+	t.Run("Error - expired token", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		token, _ := m.tokens.GenerateToken(approvalNow.Add(-time.Minute))
+
+		_, err := u.GetApprovalRequest(ctx, token)
+		assert.ErrorIs(t, err, ErrApprovalLinkExpired)
+		m.repo.AssertNotCalled(t, "GetByTokenHash", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - forged token", func(t *testing.T) {
+		u, _ := newApprovalLinkUseCaseWithMocks(t)
+		forger := utils.NewApprovalTokenService(&utils.ApprovalConfig{SecretKey: "guessed"})
+		token, _ := forger.GenerateToken(approvalNow.Add(time.Hour))
+
+		_, err := u.GetApprovalRequest(ctx, token)
+		assert.ErrorIs(t, err, ErrApprovalLinkNotFound)
+	})
+
+	t.Run("Error - link already used", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		decidedAt := approvalNow.Add(-time.Hour)
+		token := issuedToken(t, m, dto.ApprovalLinkDTO{ID: 1, Subject: "ESTIMATE", ServiceOrderID: 10, DecidedAt: &decidedAt})
+
+		_, err := u.GetApprovalRequest(ctx, token)
+		assert.ErrorIs(t, err, ErrApprovalLinkUsed)
+	})
+}
+
+func TestApprovalLinkUseCase_Decide(t *testing.T) {
+	ctx := context.Background()
+	consent := entities.ApprovalConsent{IP: "200.100.50.25", UserAgent: "Mozilla/5.0"}
+
+	t.Run("Success - approves the estimate and records the consent", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		approverContactID := uint(7)
+		token := issuedToken(t, m, dto.ApprovalLinkDTO{ID: 1, Subject: "ESTIMATE", ServiceOrderID: 10, ApproverContactID: &approverContactID})
+		m.repo.On("MarkUsed", ctx, uint(1), ApprovalApproved, consent, approvalNow).Return(true, nil)
+		m.serviceOrderUseCase.On("UpdateServiceOrder", ctx, entities.ServiceOrder{
+			ID:                 10,
+			ServiceOrderStatus: valueobject.StatusAprovada,
+			ApproverContactID:  &approverContactID,
+		}, ESTIMATE).Return(&entities.ServiceOrder{ID: 10}, nil)
+
+		link, err := u.Decide(ctx, token, ApprovalApproved, consent)
+		assert.NoError(t, err)
+		assert.Equal(t, ApprovalApproved, link.Decision)
+		assert.Equal(t, &consent, link.Consent)
+		assert.Equal(t, approvalNow, *link.DecidedAt)
+		m.serviceOrderUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Success - denies an additional repair", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		additionalRepairID := uint(5)
+		token := issuedToken(t, m, dto.ApprovalLinkDTO{ID: 2, Subject: "ADDITIONAL_REPAIR", ServiceOrderID: 10, AdditionalRepairID: &additionalRepairID})
+		m.repo.On("MarkUsed", ctx, uint(2), ApprovalDenied, consent, approvalNow).Return(true, nil)
+		m.additionalRepairUseCase.On("CustomerApprovalStatus", ctx, uint(5),
+			entities.AdditionalRepairStatusDTO{ApprovalStatus: ApprovalDenied}).Return(nil)
+
+		_, err := u.Decide(ctx, token, ApprovalDenied, consent)
+		assert.NoError(t, err)
+		m.additionalRepairUseCase.AssertExpectations(t)
+		m.serviceOrderUseCase.AssertNotCalled(t, "UpdateServiceOrder", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - used concurrently", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		token := issuedToken(t, m, dto.ApprovalLinkDTO{ID: 1, Subject: "ESTIMATE", ServiceOrderID: 10})
+		m.repo.On("MarkUsed", ctx, uint(1), ApprovalApproved, consent, approvalNow).Return(false, nil)
+
+		_, err := u.Decide(ctx, token, ApprovalApproved, consent)
+		assert.ErrorIs(t, err, ErrApprovalLinkUsed)
+		m.serviceOrderUseCase.AssertNotCalled(t, "UpdateServiceOrder", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - decision fails and the link is released", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		token := issuedToken(t, m, dto.ApprovalLinkDTO{ID: 1, Subject: "ESTIMATE", ServiceOrderID: 10})
+		m.repo.On("MarkUsed", ctx, uint(1), ApprovalApproved, consent, approvalNow).Return(true, nil)
+		m.serviceOrderUseCase.On("UpdateServiceOrder", ctx, mock.Anything, ESTIMATE).Return(nil, ErrDiscountApprovalPending)
+		m.repo.On("ReleaseUse", ctx, uint(1)).Return(nil).Once()
+
+		_, err := u.Decide(ctx, token, ApprovalApproved, consent)
+		assert.ErrorIs(t, err, ErrDiscountApprovalPending)
+		m.repo.AssertExpectations(t)
+	})
+
+	t.Run("Error - invalid decision", func(t *testing.T) {
+		u, _ := newApprovalLinkUseCaseWithMocks(t)
+
+		_, err := u.Decide(ctx, "any", "MAYBE", consent)
+		assert.ErrorIs(t, err, ErrInvalidApprovalStatus)
+	})
+
+	t.Run("Long user agent is truncated", func(t *testing.T) {
+		u, m := newApprovalLinkUseCaseWithMocks(t)
+		token := issuedToken(t, m, dto.ApprovalLinkDTO{ID: 1, Subject: "ESTIMATE", ServiceOrderID: 10})
+		long := entities.ApprovalConsent{IP: consent.IP, UserAgent: strings.Repeat("a", 300)}
+		m.repo.On("MarkUsed", ctx, uint(1), ApprovalApproved, mock.MatchedBy(func(c entities.ApprovalConsent) bool {
+			return len(c.UserAgent) == maxConsentUserAgentLength
+		}), approvalNow).Return(false, errors.New("db error"))
+
+		_, err := u.Decide(ctx, token, ApprovalApproved, long)
+		assert.Error(t, err)
+		m.repo.AssertExpectations(t)
+	})
+}
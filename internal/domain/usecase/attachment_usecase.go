@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/additional_repair"
+	"mecanica_xpto/internal/domain/repository/attachment"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/pkg/utils"
+)
+
+var (
+	ErrAttachmentNotFound        = errors.New("attachment not found")
+	ErrAttachmentTooLarge        = errors.New("attachment exceeds the maximum size")
+	ErrInvalidAttachmentType     = errors.New("attachment must be a JPEG, PNG or WebP image, a PDF or a plain text file")
+	ErrEmptyAttachment           = errors.New("attachment is empty")
+	ErrInvalidAttachmentOwner    = errors.New("attachments belong to a service order or an additional repair")
+	ErrAttachmentDownloadInvalid = errors.New("invalid download link")
+	ErrAttachmentDownloadExpired = errors.New("download link expired")
+)
+
+// attachmentTypes are the types accepted as attachments, as sniffed from their content, with the
+// extension the file is stored under
+var attachmentTypes = map[string]string{
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+	"image/webp":      "webp",
+	"application/pdf": "pdf",
+	"text/plain":      "txt",
+}
+
+type IAttachmentUseCase interface {
+	Upload(ctx context.Context, ownerType valueobject.AttachmentOwner, ownerID uint, upload entities.AttachmentUpload, content io.Reader) (*entities.Attachment, error)
+	List(ctx context.Context, ownerType valueobject.AttachmentOwner, ownerID uint) ([]entities.Attachment, error)
+	Get(ctx context.Context, id uint) (*entities.Attachment, error)
+	Open(ctx context.Context, id uint) (*entities.Attachment, io.ReadCloser, error)
+	CreateDownloadURL(ctx context.Context, id uint) (*entities.AttachmentDownloadURL, error)
+	OpenSigned(ctx context.Context, id uint, expires, signature string) (*entities.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type AttachmentUseCase struct {
+	repo                 attachment.IAttachmentRepository
+	serviceOrderRepo     serviceorder.IServiceOrderRepository
+	additionalRepairRepo additional_repair.IAdditionalRepairRepository
+	blobStore            gateway.BlobStore
+	signer               *utils.DownloadURLSigner
+	cfg                  utils.StorageConfig
+	now                  func() time.Time
+}
+
+var _ IAttachmentUseCase = (*AttachmentUseCase)(nil)
+
+func NewAttachmentUseCase(repo attachment.IAttachmentRepository, serviceOrderRepo serviceorder.IServiceOrderRepository, additionalRepairRepo additional_repair.IAdditionalRepairRepository, blobStore gateway.BlobStore, cfg utils.StorageConfig) *AttachmentUseCase {
+	return &AttachmentUseCase{
+		repo:                 repo,
+		serviceOrderRepo:     serviceOrderRepo,
+		additionalRepairRepo: additionalRepairRepo,
+		blobStore:            blobStore,
+		signer:               utils.NewDownloadURLSigner(cfg.DownloadURLSecret),
+		cfg:                  cfg,
+		now:                  time.Now,
+	}
+}
+
+// Upload stores a file attached to a service order or additional repair. The type is sniffed from
+// the content, the upload is cut off once it exceeds the maximum size and the SHA-256 of the content
+// is recorded as its checksum.
+func (u *AttachmentUseCase) Upload(ctx context.Context, ownerType valueobject.AttachmentOwner, ownerID uint, upload entities.AttachmentUpload, content io.Reader) (*entities.Attachment, error) {
+	if upload.Size > u.cfg.MaxUploadSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	serviceOrderID, err := u.findOwner(ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, content, err := sniffContentType(content)
+	if err != nil {
+		return nil, err
+	}
+	extension, ok := attachmentTypes[contentType]
+	if !ok {
+		return nil, ErrInvalidAttachmentType
+	}
+
+	key, err := newBlobKey(fmt.Sprintf("attachments/%d", serviceOrderID), extension)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	counter := &countingReader{reader: io.TeeReader(io.LimitReader(content, u.cfg.MaxUploadSize+1), hash)}
+	if err := u.blobStore.Put(ctx, key, counter); err != nil {
+		log.Error().Msgf("Error storing attachment of %s %d: %v", ownerType, ownerID, err)
+		return nil, err
+	}
+	if counter.count > u.cfg.MaxUploadSize {
+		deleteBlob(ctx, u.blobStore, key)
+		return nil, ErrAttachmentTooLarge
+	}
+	if counter.count == 0 {
+		deleteBlob(ctx, u.blobStore, key)
+		return nil, ErrEmptyAttachment
+	}
+
+	attachmentDto := dto.AttachmentDTO{
+		OwnerType:      ownerType.String(),
+		OwnerID:        ownerID,
+		ServiceOrderID: serviceOrderID,
+		StorageKey:     key,
+		FileName:       uploadFileName(upload.FileName),
+		ContentType:    contentType,
+		Size:           counter.count,
+		Checksum:       hex.EncodeToString(hash.Sum(nil)),
+		UploadedBy:     upload.UploadedBy,
+	}
+	if err := u.repo.Create(ctx, &attachmentDto); err != nil {
+		log.Error().Msgf("Error saving attachment of %s %d: %v", ownerType, ownerID, err)
+		deleteBlob(ctx, u.blobStore, key)
+		return nil, err
+	}
+	result := attachmentDto.ToDomain()
+	return &result, nil
+}
+
+func (u *AttachmentUseCase) List(ctx context.Context, ownerType valueobject.AttachmentOwner, ownerID uint) ([]entities.Attachment, error) {
+	if _, err := u.findOwner(ownerType, ownerID); err != nil {
+		return nil, err
+	}
+	attachmentDtos, err := u.repo.ListByOwner(ctx, ownerType, ownerID)
+	if err != nil {
+		log.Error().Msgf("Error listing attachments of %s %d: %v", ownerType, ownerID, err)
+		return nil, err
+	}
+	attachments := make([]entities.Attachment, 0, len(attachmentDtos))
+	for i := range attachmentDtos {
+		attachments = append(attachments, attachmentDtos[i].ToDomain())
+	}
+	return attachments, nil
+}
+
+func (u *AttachmentUseCase) Get(ctx context.Context, id uint) (*entities.Attachment, error) {
+	attachmentDto, err := u.findAttachment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := attachmentDto.ToDomain()
+	return &result, nil
+}
+
+// Open returns an attachment and its content, which the caller must close
+func (u *AttachmentUseCase) Open(ctx context.Context, id uint) (*entities.Attachment, io.ReadCloser, error) {
+	attachmentDto, err := u.findAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := u.blobStore.Open(ctx, attachmentDto.StorageKey)
+	if err != nil {
+		if errors.Is(err, gateway.ErrBlobNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		log.Error().Msgf("Error opening attachment %d: %v", id, err)
+		return nil, nil, err
+	}
+	result := attachmentDto.ToDomain()
+	return &result, content, nil
+}
+
+// CreateDownloadURL signs a link that downloads the attachment without logging in, valid for the
+// configured time, so it can be handed to a customer or an insurer
+func (u *AttachmentUseCase) CreateDownloadURL(ctx context.Context, id uint) (*entities.AttachmentDownloadURL, error) {
+	if _, err := u.findAttachment(ctx, id); err != nil {
+		return nil, err
+	}
+	expiresAt := u.now().Add(u.cfg.DownloadURLTTL).Truncate(time.Second)
+	expires, signature := u.signer.Sign(id, expiresAt)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", signature)
+	return &entities.AttachmentDownloadURL{
+		URL:       strings.TrimSuffix(u.cfg.DownloadBaseURL, "/") + "/" + strconv.FormatUint(uint64(id), 10) + "?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// OpenSigned opens an attachment from a signed download link
+func (u *AttachmentUseCase) OpenSigned(ctx context.Context, id uint, expires, signature string) (*entities.Attachment, io.ReadCloser, error) {
+	if err := u.signer.Verify(id, expires, signature, u.now()); err != nil {
+		if errors.Is(err, utils.ErrDownloadURLExpired) {
+			return nil, nil, ErrAttachmentDownloadExpired
+		}
+		return nil, nil, ErrAttachmentDownloadInvalid
+	}
+	return u.Open(ctx, id)
+}
+
+func (u *AttachmentUseCase) Delete(ctx context.Context, id uint) error {
+	attachmentDto, err := u.findAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.Delete(ctx, id); err != nil {
+		log.Error().Msgf("Error deleting attachment %d: %v", id, err)
+		return err
+	}
+	deleteBlob(ctx, u.blobStore, attachmentDto.StorageKey)
+	return nil
+}
+
+// findOwner checks that the record the files are attached to exists and returns the service order
+// it belongs to
+func (u *AttachmentUseCase) findOwner(ownerType valueobject.AttachmentOwner, ownerID uint) (uint, error) {
+	switch ownerType {
+	case valueobject.AttachmentOwnerServiceOrder:
+		serviceOrderDto, err := u.serviceOrderRepo.GetByID(ownerID)
+		if err != nil {
+			log.Error().Msgf("Error finding service order with id %d: %v", ownerID, err)
+			return 0, err
+		}
+		if serviceOrderDto == nil {
+			return 0, ErrServiceOrderNotFound
+		}
+		return serviceOrderDto.ID, nil
+	case valueobject.AttachmentOwnerAdditionalRepair:
+		additionalRepairDto, err := u.additionalRepairRepo.GetByID(ownerID)
+		if err != nil {
+			log.Error().Msgf("Error finding additional repair with id %d: %v", ownerID, err)
+			return 0, err
+		}
+		if additionalRepairDto == nil {
+			return 0, ErrAdditionalRepairNotFound
+		}
+		return additionalRepairDto.ServiceOrderID, nil
+	default:
+		return 0, ErrInvalidAttachmentOwner
+	}
+}
+
+func (u *AttachmentUseCase) findAttachment(ctx context.Context, id uint) (*dto.AttachmentDTO, error) {
+	attachmentDto, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Error().Msgf("Error finding attachment %d: %v", id, err)
+		return nil, err
+	}
+	if attachmentDto == nil {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachmentDto, nil
+}
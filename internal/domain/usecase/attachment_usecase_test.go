@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+	"mecanica_xpto/pkg/utils"
+)
+
+var attachmentTestNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+type attachmentTestDeps struct {
+	repo                 *mocks.MockAttachmentRepository
+	serviceOrderRepo     *mocks.MockServiceOrderRepository
+	additionalRepairRepo *mocks.MockIAdditionalRepairRepository
+	blobStore            *mocks.MockBlobStore
+}
+
+func newAttachmentTestUseCase(t *testing.T) (*AttachmentUseCase, attachmentTestDeps) {
+	deps := attachmentTestDeps{
+		repo:                 new(mocks.MockAttachmentRepository),
+		serviceOrderRepo:     new(mocks.MockServiceOrderRepository),
+		additionalRepairRepo: mocks.NewMockIAdditionalRepairRepository(gomock.NewController(t)),
+		blobStore:            new(mocks.MockBlobStore),
+	}
+	uc := NewAttachmentUseCase(deps.repo, deps.serviceOrderRepo, deps.additionalRepairRepo, deps.blobStore, utils.StorageConfig{
+		MaxUploadSize:     1024,
+		DownloadURLSecret: "test_secret",
+		DownloadURLTTL:    15 * time.Minute,
+		DownloadBaseURL:   "https://oficina.example/v1/public/attachments/",
+	})
+	uc.now = func() time.Time { return attachmentTestNow }
+	return uc, deps
+}
+
+// drainBlob reads the content given to the blob store, as a real store would
+func drainBlob(args mock.Arguments) {
+	io.Copy(io.Discard, args.Get(2).(io.Reader))
+}
+
+func TestAttachmentUseCase_Upload(t *testing.T) {
+	ctx := context.Background()
+	report := []byte("%PDF-1.4\nrelatorio do scanner: P0301 falha de ignicao no cilindro 1")
+	upload := entities.AttachmentUpload{FileName: "scanner.pdf", UploadedBy: "joao@xpto.com"}
+
+	t.Run("stores a file of a service order with its checksum", func(t *testing.T) {
+		uc, deps := newAttachmentTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(7)).Return(&dto.ServiceOrderDTO{ID: 7}, nil)
+		var stored []byte
+		deps.blobStore.On("Put", ctx, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "attachments/7/") && strings.HasSuffix(key, ".pdf")
+		}), mock.Anything).Run(func(args mock.Arguments) {
+			stored, _ = io.ReadAll(args.Get(2).(io.Reader))
+		}).Return(nil)
+		sum := sha256.Sum256(report)
+		deps.repo.On("Create", ctx, mock.MatchedBy(func(a *dto.AttachmentDTO) bool {
+			return a.OwnerType == "SERVICE_ORDER" && a.OwnerID == 7 && a.ServiceOrderID == 7 &&
+				a.ContentType == "application/pdf" && a.Size == int64(len(report)) && a.Checksum == hex.EncodeToString(sum[:])
+		})).Return(nil)
+
+		attachment, err := uc.Upload(ctx, valueobject.AttachmentOwnerServiceOrder, 7, upload, bytes.NewReader(report))
+
+		require.NoError(t, err)
+		assert.Equal(t, report, stored)
+		assert.Equal(t, "scanner.pdf", attachment.FileName)
+		deps.repo.AssertExpectations(t)
+	})
+
+	t.Run("keeps the service order of an additional repair", func(t *testing.T) {
+		uc, deps := newAttachmentTestUseCase(t)
+		deps.additionalRepairRepo.EXPECT().GetByID(uint(4)).Return(&dto.AdditionalRepairDTO{ID: 4, ServiceOrderID: 7}, nil)
+		deps.blobStore.On("Put", ctx, mock.Anything, mock.Anything).Run(drainBlob).Return(nil)
+		deps.repo.On("Create", ctx, mock.MatchedBy(func(a *dto.AttachmentDTO) bool {
+			return a.OwnerType == "ADDITIONAL_REPAIR" && a.OwnerID == 4 && a.ServiceOrderID == 7 && a.ContentType == "text/plain"
+		})).Return(nil)
+
+		_, err := uc.Upload(ctx, valueobject.AttachmentOwnerAdditionalRepair, 4, upload, strings.NewReader("pastilhas com 2mm"))
+
+		require.NoError(t, err)
+		deps.repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a type outside the allowed list", func(t *testing.T) {
+		uc, deps := newAttachmentTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(7)).Return(&dto.ServiceOrderDTO{ID: 7}, nil)
+
+		_, err := uc.Upload(ctx, valueobject.AttachmentOwnerServiceOrder, 7, upload, bytes.NewReader([]byte("PK\x03\x04 arquivo zip")))
+
+		assert.ErrorIs(t, err, ErrInvalidAttachmentType)
+		deps.blobStore.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes the stored file when the content exceeds the maximum size", func(t *testing.T) {
+		uc, deps := newAttachmentTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(7)).Return(&dto.ServiceOrderDTO{ID: 7}, nil)
+		deps.blobStore.On("Put", ctx, mock.Anything, mock.Anything).Run(drainBlob).Return(nil)
+		deps.blobStore.On("Delete", ctx, mock.Anything).Return(nil)
+
+		_, err := uc.Upload(ctx, valueobject.AttachmentOwnerServiceOrder, 7, upload, strings.NewReader(strings.Repeat("a", 2048)))
+
+		assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+		deps.blobStore.AssertCalled(t, "Delete", ctx, mock.Anything)
+		deps.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes the stored file when the attachment cannot be recorded", func(t *testing.T) {
+		uc, deps := newAttachmentTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(7)).Return(&dto.ServiceOrderDTO{ID: 7}, nil)
+		deps.blobStore.On("Put", ctx, mock.Anything, mock.Anything).Run(drainBlob).Return(nil)
+		deps.blobStore.On("Delete", ctx, mock.Anything).Return(nil)
+		deps.repo.On("Create", ctx, mock.Anything).Return(errors.New("db down"))
+
+		_, err := uc.Upload(ctx, valueobject.AttachmentOwnerServiceOrder, 7, upload, bytes.NewReader(report))
+
+		assert.Error(t, err)
+		deps.blobStore.AssertCalled(t, "Delete", ctx, mock.Anything)
+	})
+
+	t.Run("rejects an empty file", func(t *testing.T) {
+		uc, deps := newAttachmentTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(7)).Return(&dto.ServiceOrderDTO{ID: 7}, nil)
+		deps.blobStore.On("Put", ctx, mock.Anything, mock.Anything).Run(drainBlob).Return(nil)
+		deps.blobStore.On("Delete", ctx, mock.Anything).Return(nil)
+
+		_, err := uc.Upload(ctx, valueobject.AttachmentOwnerServiceOrder, 7, upload, strings.NewReader(""))
+
+		assert.ErrorIs(t, err, ErrEmptyAttachment)
+	})
+
+	t.Run("fails for an unknown owner", func(t *testing.T) {
+		uc, deps := newAttachmentTestUseCase(t)
+		deps.serviceOrderRepo.On("GetByID", uint(7)).Return(nil, nil)
+		deps.additionalRepairRepo.EXPECT().GetByID(uint(4)).Return(nil, nil)
+
+		_, err := uc.Upload(ctx, valueobject.AttachmentOwnerServiceOrder, 7, upload, bytes.NewReader(report))
+		assert.ErrorIs(t, err, ErrServiceOrderNotFound)
+
+		_, err = uc.Upload(ctx, valueobject.AttachmentOwnerAdditionalRepair, 4, upload, bytes.NewReader(report))
+		assert.ErrorIs(t, err, ErrAdditionalRepairNotFound)
+	})
+}
+
+func TestAttachmentUseCase_DownloadURL(t *testing.T) {
+	ctx := context.Background()
+	attachmentDto := &dto.AttachmentDTO{ID: 12, StorageKey: "attachments/7/abc.pdf", FileName: "scanner.pdf", ContentType: "application/pdf"}
+
+	t.Run("signs a link that opens the attachment until it expires", func(t *testing.T) {
+		uc, deps := newAttachmentTestUseCase(t)
+		deps.repo.On("GetByID", ctx, uint(12)).Return(attachmentDto, nil)
+		deps.blobStore.On("Open", ctx, "attachments/7/abc.pdf").Return(io.NopCloser(strings.NewReader("%PDF")), nil)
+
+		link, err := uc.CreateDownloadURL(ctx, 12)
+		require.NoError(t, err)
+		assert.Equal(t, attachmentTestNow.Add(15*time.Minute), link.ExpiresAt)
+		require.True(t, strings.HasPrefix(link.URL, "https://oficina.example/v1/public/attachments/12?"), link.URL)
+
+		parsed, err := url.Parse(link.URL)
+		require.NoError(t, err)
+		query := parsed.Query()
+
+		attachment, content, err := uc.OpenSigned(ctx, 12, query.Get("expires"), query.Get("signature"))
+		require.NoError(t, err)
+		content.Close()
+		assert.Equal(t, "scanner.pdf", attachment.FileName)
+
+		_, _, err = uc.OpenSigned(ctx, 13, query.Get("expires"), query.Get("signature"))
+		assert.ErrorIs(t, err, ErrAttachmentDownloadInvalid)
+
+		uc.now = func() time.Time { return attachmentTestNow.Add(time.Hour) }
+		_, _, err = uc.OpenSigned(ctx, 12, query.Get("expires"), query.Get("signature"))
+		assert.ErrorIs(t, err, ErrAttachmentDownloadExpired)
+	})
+
+	t.Run("fails for an unknown attachment", func(t *testing.T) {
+		uc, deps := newAttachmentTestUseCase(t)
+		deps.repo.On("GetByID", ctx, uint(12)).Return(nil, nil)
+
+		_, err := uc.CreateDownloadURL(ctx, 12)
+
+		assert.ErrorIs(t, err, ErrAttachmentNotFound)
+	})
+}
+
+func TestAttachmentUseCase_Delete(t *testing.T) {
+	ctx := context.Background()
+	uc, deps := newAttachmentTestUseCase(t)
+	deps.repo.On("GetByID", ctx, uint(12)).Return(&dto.AttachmentDTO{ID: 12, StorageKey: "attachments/7/abc.pdf"}, nil)
+	deps.repo.On("Delete", ctx, uint(12)).Return(nil)
+	deps.blobStore.On("Delete", ctx, "attachments/7/abc.pdf").Return(nil)
+
+	require.NoError(t, uc.Delete(ctx, 12))
+	deps.blobStore.AssertExpectations(t)
+}
@@ -45,7 +45,7 @@ func (a *authUseCase) Login(authDTO dto.AuthDTO) (string, *pkg.AppError) {
 		return "", pkg.NewDomainErrorSimple(ErrCodeInvalidCredential, ErrMsgInvalidCredential, http.StatusUnauthorized)
 	}
 
-	token, err := a.jwtService.GenerateToken(userFromDB.Email)
+	token, err := a.jwtService.GenerateToken(userFromDB.Email, userFromDB.UserType.String())
 	if err != nil {
 		return "", pkg.NewInfraError(ErrCodeTokenGeneration, ErrMsgTokenGeneration, err, http.StatusInternalServerError)
 	}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	checkin "mecanica_xpto/internal/domain/repository/check_in"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+)
+
+var (
+	ErrCheckInNotFound         = errors.New("check-in not found")
+	ErrCheckInClosed           = errors.New("check-in can only be changed while the service order is RECEBIDA")
+	ErrInvalidCheckIn          = errors.New("invalid check-in: odometer must not be negative, fuel level and checklist conditions must be valid")
+	ErrCheckInForbidden        = errors.New("not allowed to view this check-in")
+	ErrCheckInPhotoNotFound    = errors.New("check-in photo not found")
+	ErrCheckInPhotoTooLarge    = errors.New("check-in photo exceeds the maximum size")
+	ErrInvalidCheckInPhotoType = errors.New("check-in photo must be a JPEG, PNG or WebP image")
+)
+
+// checkInPhotoTypes are the image types accepted as check-in photos, as sniffed from their content,
+// with the extension the photo is stored under
+var checkInPhotoTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+type ICheckInUseCase interface {
+	SaveCheckIn(ctx context.Context, serviceOrderID uint, request entities.CheckInRequest) (*entities.CheckIn, error)
+	GetCheckIn(ctx context.Context, serviceOrderID uint, viewerEmail string, viewerRole valueobject.UserType) (*entities.CheckIn, error)
+	AddPhoto(ctx context.Context, serviceOrderID uint, upload entities.CheckInPhotoUpload, content io.Reader) (*entities.CheckInPhoto, error)
+	OpenPhoto(ctx context.Context, serviceOrderID, photoID uint, viewerEmail string, viewerRole valueobject.UserType) (*entities.CheckInPhoto, io.ReadCloser, error)
+	DeletePhoto(ctx context.Context, serviceOrderID, photoID uint) error
+}
+
+type CheckInUseCase struct {
+	repo             checkin.ICheckInRepository
+	serviceOrderRepo serviceorder.IServiceOrderRepository
+	blobStore        gateway.BlobStore
+	maxPhotoSize     int64
+}
+
+var _ ICheckInUseCase = (*CheckInUseCase)(nil)
+
+func NewCheckInUseCase(repo checkin.ICheckInRepository, serviceOrderRepo serviceorder.IServiceOrderRepository, blobStore gateway.BlobStore, maxPhotoSize int64) *CheckInUseCase {
+	return &CheckInUseCase{
+		repo:             repo,
+		serviceOrderRepo: serviceOrderRepo,
+		blobStore:        blobStore,
+		maxPhotoSize:     maxPhotoSize,
+	}
+}
+
+// SaveCheckIn records the inspection of the vehicle of a service order, replacing the one recorded
+// before. It is only accepted while the order is RECEBIDA.
+func (u *CheckInUseCase) SaveCheckIn(ctx context.Context, serviceOrderID uint, request entities.CheckInRequest) (*entities.CheckIn, error) {
+	if request.Odometer < 0 || !request.FuelLevel.IsValid() {
+		return nil, ErrInvalidCheckIn
+	}
+	for _, item := range request.Checklist {
+		if strings.TrimSpace(item.Name) == "" || !item.Condition.IsValid() {
+			return nil, ErrInvalidCheckIn
+		}
+	}
+
+	if _, err := u.findReceivedServiceOrder(serviceOrderID); err != nil {
+		return nil, err
+	}
+
+	checkInDto, err := u.repo.GetByServiceOrder(ctx, serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error finding check-in of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	if checkInDto == nil {
+		checkInDto = &dto.CheckInDTO{ServiceOrderID: serviceOrderID}
+	}
+	checkInDto.Odometer = request.Odometer
+	checkInDto.FuelLevel = request.FuelLevel.String()
+	checkInDto.Notes = request.Notes
+	checkInDto.InspectedBy = request.InspectedBy
+	checkInDto.Items = nil
+	for _, item := range request.Checklist {
+		checkInDto.Items = append(checkInDto.Items, dto.CheckInItemDTO{
+			Name:      strings.TrimSpace(item.Name),
+			Condition: item.Condition.String(),
+			Notes:     item.Notes,
+		})
+	}
+
+	if err := u.repo.Save(ctx, checkInDto); err != nil {
+		log.Error().Msgf("Error saving check-in of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	result := checkInDto.ToDomain()
+	return &result, nil
+}
+
+// GetCheckIn returns the check-in to staff allowed to view service orders and to the customer who
+// owns the order
+func (u *CheckInUseCase) GetCheckIn(ctx context.Context, serviceOrderID uint, viewerEmail string, viewerRole valueobject.UserType) (*entities.CheckIn, error) {
+	checkInDto, err := u.findViewableCheckIn(ctx, serviceOrderID, viewerEmail, viewerRole)
+	if err != nil {
+		return nil, err
+	}
+	result := checkInDto.ToDomain()
+	return &result, nil
+}
+
+// AddPhoto stores a photo of the check-in on the blob store. The type is sniffed from the content
+// rather than trusted from the client, and the upload is cut off once it exceeds the maximum size.
+func (u *CheckInUseCase) AddPhoto(ctx context.Context, serviceOrderID uint, upload entities.CheckInPhotoUpload, content io.Reader) (*entities.CheckInPhoto, error) {
+	if upload.Size > u.maxPhotoSize {
+		return nil, ErrCheckInPhotoTooLarge
+	}
+	if _, err := u.findReceivedServiceOrder(serviceOrderID); err != nil {
+		return nil, err
+	}
+	checkInDto, err := u.findCheckIn(ctx, serviceOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, content, err := sniffContentType(content)
+	if err != nil {
+		return nil, err
+	}
+	extension, ok := checkInPhotoTypes[contentType]
+	if !ok {
+		return nil, ErrInvalidCheckInPhotoType
+	}
+
+	key, err := newBlobKey(fmt.Sprintf("check-ins/%d", serviceOrderID), extension)
+	if err != nil {
+		return nil, err
+	}
+	counter := &countingReader{reader: io.LimitReader(content, u.maxPhotoSize+1)}
+	if err := u.blobStore.Put(ctx, key, counter); err != nil {
+		log.Error().Msgf("Error storing check-in photo of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	if counter.count > u.maxPhotoSize {
+		deleteBlob(ctx, u.blobStore, key)
+		return nil, ErrCheckInPhotoTooLarge
+	}
+
+	photoDto := dto.CheckInPhotoDTO{
+		CheckInID:   checkInDto.ID,
+		StorageKey:  key,
+		FileName:    uploadFileName(upload.FileName),
+		ContentType: contentType,
+		Size:        counter.count,
+		Caption:     upload.Caption,
+		UploadedBy:  upload.UploadedBy,
+	}
+	if err := u.repo.AddPhoto(ctx, &photoDto); err != nil {
+		log.Error().Msgf("Error saving check-in photo of service order %d: %v", serviceOrderID, err)
+		deleteBlob(ctx, u.blobStore, key)
+		return nil, err
+	}
+	result := photoDto.ToDomain()
+	return &result, nil
+}
+
+// OpenPhoto returns a photo of the check-in and its content, which the caller must close. Access
+// follows GetCheckIn.
+func (u *CheckInUseCase) OpenPhoto(ctx context.Context, serviceOrderID, photoID uint, viewerEmail string, viewerRole valueobject.UserType) (*entities.CheckInPhoto, io.ReadCloser, error) {
+	checkInDto, err := u.findViewableCheckIn(ctx, serviceOrderID, viewerEmail, viewerRole)
+	if err != nil {
+		return nil, nil, err
+	}
+	photoDto, err := u.findPhoto(ctx, checkInDto.ID, photoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := u.blobStore.Open(ctx, photoDto.StorageKey)
+	if err != nil {
+		if errors.Is(err, gateway.ErrBlobNotFound) {
+			return nil, nil, ErrCheckInPhotoNotFound
+		}
+		log.Error().Msgf("Error opening check-in photo %d: %v", photoID, err)
+		return nil, nil, err
+	}
+	photo := photoDto.ToDomain()
+	return &photo, content, nil
+}
+
+// DeletePhoto removes a photo of the check-in while the service order is RECEBIDA
+func (u *CheckInUseCase) DeletePhoto(ctx context.Context, serviceOrderID, photoID uint) error {
+	if _, err := u.findReceivedServiceOrder(serviceOrderID); err != nil {
+		return err
+	}
+	checkInDto, err := u.findCheckIn(ctx, serviceOrderID)
+	if err != nil {
+		return err
+	}
+	photoDto, err := u.findPhoto(ctx, checkInDto.ID, photoID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.repo.DeletePhoto(ctx, photoDto.ID); err != nil {
+		log.Error().Msgf("Error deleting check-in photo %d: %v", photoID, err)
+		return err
+	}
+	deleteBlob(ctx, u.blobStore, photoDto.StorageKey)
+	return nil
+}
+
+func (u *CheckInUseCase) findReceivedServiceOrder(id uint) (*dto.ServiceOrderDTO, error) {
+	serviceOrderDto, err := u.serviceOrderRepo.GetByID(id)
+	if err != nil {
+		log.Error().Msgf("Error finding service order with id %d: %v", id, err)
+		return nil, err
+	}
+	if serviceOrderDto == nil {
+		return nil, ErrServiceOrderNotFound
+	}
+	if !valueobject.ParseServiceOrderStatus(serviceOrderDto.ServiceOrderStatus.Description).IsRecebida() {
+		return nil, ErrCheckInClosed
+	}
+	return serviceOrderDto, nil
+}
+
+func (u *CheckInUseCase) findCheckIn(ctx context.Context, serviceOrderID uint) (*dto.CheckInDTO, error) {
+	checkInDto, err := u.repo.GetByServiceOrder(ctx, serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error finding check-in of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	if checkInDto == nil {
+		return nil, ErrCheckInNotFound
+	}
+	return checkInDto, nil
+}
+
+// findViewableCheckIn finds the check-in of the order once the viewer is known to be allowed to
+// see it: staff need to view service orders, a customer must own the order
+func (u *CheckInUseCase) findViewableCheckIn(ctx context.Context, serviceOrderID uint, viewerEmail string, viewerRole valueobject.UserType) (*dto.CheckInDTO, error) {
+	serviceOrderDto, err := u.serviceOrderRepo.GetByID(serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error finding service order with id %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	if serviceOrderDto == nil {
+		return nil, ErrServiceOrderNotFound
+	}
+
+	if viewerRole == valueobject.Customer {
+		owner := serviceOrderDto.Customer.User
+		if owner == nil || viewerEmail == "" || !strings.EqualFold(owner.Email, viewerEmail) {
+			return nil, ErrCheckInForbidden
+		}
+	} else if !viewerRole.HasPermission(valueobject.PermissionViewServiceOrders) {
+		return nil, ErrCheckInForbidden
+	}
+
+	return u.findCheckIn(ctx, serviceOrderID)
+}
+
+func (u *CheckInUseCase) findPhoto(ctx context.Context, checkInID, photoID uint) (*dto.CheckInPhotoDTO, error) {
+	photoDto, err := u.repo.GetPhoto(ctx, checkInID, photoID)
+	if err != nil {
+		log.Error().Msgf("Error finding check-in photo %d: %v", photoID, err)
+		return nil, err
+	}
+	if photoDto == nil {
+		return nil, ErrCheckInPhotoNotFound
+	}
+	return photoDto, nil
+}
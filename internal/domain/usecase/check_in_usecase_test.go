@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+// checkInTestPNG is the signature of a PNG file, enough for the content type to be sniffed
+var checkInTestPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newCheckInTestUseCase() (*CheckInUseCase, *mocks.MockCheckInRepository, *mocks.MockServiceOrderRepository, *mocks.MockBlobStore) {
+	repo := new(mocks.MockCheckInRepository)
+	serviceOrderRepo := new(mocks.MockServiceOrderRepository)
+	blobStore := new(mocks.MockBlobStore)
+	return NewCheckInUseCase(repo, serviceOrderRepo, blobStore, 1024), repo, serviceOrderRepo, blobStore
+}
+
+func checkInTestServiceOrder(status valueobject.ServiceOrderStatus) *dto.ServiceOrderDTO {
+	return &dto.ServiceOrderDTO{
+		ID:                 7,
+		CustomerID:         2,
+		ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: status.String()},
+		Customer:           dto.CustomerDTO{ID: 2, User: &dto.UserDTO{Email: "cliente@xpto.com"}},
+	}
+}
+
+func TestCheckInUseCase_SaveCheckIn(t *testing.T) {
+	ctx := context.Background()
+	request := entities.CheckInRequest{
+		Odometer:  45210,
+		FuelLevel: valueobject.FuelHalf,
+		Checklist: []entities.CheckInItem{
+			{Name: "Estepe", Condition: valueobject.ConditionOK},
+			{Name: "Para-choque dianteiro", Condition: valueobject.ConditionDamaged, Notes: "risco no lado esquerdo"},
+		},
+		InspectedBy: "joana@xpto.com",
+	}
+
+	t.Run("creates the check-in of a received order", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, _ := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(nil, nil)
+		repo.On("Save", ctx, mock.MatchedBy(func(c *dto.CheckInDTO) bool {
+			return c.ID == 0 && c.ServiceOrderID == 7 && c.Odometer == 45210 && c.FuelLevel == "1/2" &&
+				len(c.Items) == 2 && c.Items[1].Condition == "AVARIA" && c.InspectedBy == "joana@xpto.com"
+		})).Return(nil)
+
+		checkIn, err := uc.SaveCheckIn(ctx, 7, request)
+
+		require.NoError(t, err)
+		assert.Equal(t, 45210, checkIn.Odometer)
+		assert.Len(t, checkIn.Checklist, 2)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("replaces the checklist of an existing check-in", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, _ := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(&dto.CheckInDTO{
+			ID: 3, ServiceOrderID: 7, Items: []dto.CheckInItemDTO{{ID: 1, Name: "Antigo", Condition: "OK"}},
+		}, nil)
+		repo.On("Save", ctx, mock.MatchedBy(func(c *dto.CheckInDTO) bool {
+			return c.ID == 3 && len(c.Items) == 2 && c.Items[0].Name == "Estepe"
+		})).Return(nil)
+
+		_, err := uc.SaveCheckIn(ctx, 7, request)
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an invalid fuel level or checklist condition", func(t *testing.T) {
+		uc, _, _, _ := newCheckInTestUseCase()
+
+		_, err := uc.SaveCheckIn(ctx, 7, entities.CheckInRequest{Odometer: 10, FuelLevel: "MEIO"})
+		assert.ErrorIs(t, err, ErrInvalidCheckIn)
+
+		_, err = uc.SaveCheckIn(ctx, 7, entities.CheckInRequest{Odometer: 10, FuelLevel: valueobject.FuelFull,
+			Checklist: []entities.CheckInItem{{Name: "Estepe", Condition: "QUEBRADO"}}})
+		assert.ErrorIs(t, err, ErrInvalidCheckIn)
+	})
+
+	t.Run("refuses changes once the order left RECEBIDA", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, _ := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusEmDiagnostico), nil)
+
+		_, err := uc.SaveCheckIn(ctx, 7, request)
+
+		assert.ErrorIs(t, err, ErrCheckInClosed)
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails for an unknown service order", func(t *testing.T) {
+		uc, _, serviceOrderRepo, _ := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(nil, nil)
+
+		_, err := uc.SaveCheckIn(ctx, 7, request)
+
+		assert.ErrorIs(t, err, ErrServiceOrderNotFound)
+	})
+}
+
+func TestCheckInUseCase_GetCheckIn(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("shows the check-in to the customer who owns the order", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, _ := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusEmExecucao), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(&dto.CheckInDTO{ID: 3, ServiceOrderID: 7, Odometer: 100, FuelLevel: "CHEIO"}, nil)
+
+		checkIn, err := uc.GetCheckIn(ctx, 7, "Cliente@xpto.com", valueobject.Customer)
+
+		require.NoError(t, err)
+		assert.Equal(t, valueobject.FuelFull, checkIn.FuelLevel)
+		assert.NotNil(t, checkIn.Photos)
+	})
+
+	t.Run("hides the check-in from other customers", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, _ := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+
+		_, err := uc.GetCheckIn(ctx, 7, "outro@xpto.com", valueobject.Customer)
+
+		assert.ErrorIs(t, err, ErrCheckInForbidden)
+		repo.AssertNotCalled(t, "GetByServiceOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("shows the check-in to staff allowed to view service orders", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, _ := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(nil, nil)
+
+		_, err := uc.GetCheckIn(ctx, 7, "mecanico@xpto.com", valueobject.Mechanic)
+
+		assert.ErrorIs(t, err, ErrCheckInNotFound)
+	})
+}
+
+func TestCheckInUseCase_AddPhoto(t *testing.T) {
+	ctx := context.Background()
+	upload := entities.CheckInPhotoUpload{FileName: `C:\fotos\lateral.png`, Caption: "Lateral", UploadedBy: "joana@xpto.com"}
+
+	t.Run("stores the photo under a random key and records it", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, blobStore := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(&dto.CheckInDTO{ID: 3, ServiceOrderID: 7}, nil)
+		var stored []byte
+		blobStore.On("Put", ctx, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "check-ins/7/") && strings.HasSuffix(key, ".png")
+		}), mock.Anything).Run(func(args mock.Arguments) {
+			stored, _ = io.ReadAll(args.Get(2).(io.Reader))
+		}).Return(nil)
+		repo.On("AddPhoto", ctx, mock.MatchedBy(func(p *dto.CheckInPhotoDTO) bool {
+			return p.CheckInID == 3 && p.ContentType == "image/png" && p.FileName == "lateral.png" && p.Size == int64(len(checkInTestPNG))
+		})).Return(nil)
+
+		photo, err := uc.AddPhoto(ctx, 7, upload, bytes.NewReader(checkInTestPNG))
+
+		require.NoError(t, err)
+		assert.Equal(t, checkInTestPNG, stored)
+		assert.Equal(t, "Lateral", photo.Caption)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects content that is not an image", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, blobStore := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(&dto.CheckInDTO{ID: 3, ServiceOrderID: 7}, nil)
+
+		_, err := uc.AddPhoto(ctx, 7, upload, strings.NewReader("%PDF-1.4 nao e uma foto"))
+
+		assert.ErrorIs(t, err, ErrInvalidCheckInPhotoType)
+		blobStore.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes the stored file when the content exceeds the maximum size", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, blobStore := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(&dto.CheckInDTO{ID: 3, ServiceOrderID: 7}, nil)
+		blobStore.On("Put", ctx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			io.Copy(io.Discard, args.Get(2).(io.Reader))
+		}).Return(nil)
+		blobStore.On("Delete", ctx, mock.Anything).Return(nil)
+		content := append(append([]byte{}, checkInTestPNG...), make([]byte, 2048)...)
+
+		_, err := uc.AddPhoto(ctx, 7, upload, bytes.NewReader(content))
+
+		assert.ErrorIs(t, err, ErrCheckInPhotoTooLarge)
+		blobStore.AssertCalled(t, "Delete", ctx, mock.Anything)
+		repo.AssertNotCalled(t, "AddPhoto", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes the stored file when the photo cannot be recorded", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, blobStore := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(&dto.CheckInDTO{ID: 3, ServiceOrderID: 7}, nil)
+		blobStore.On("Put", ctx, mock.Anything, mock.Anything).Return(nil)
+		blobStore.On("Delete", ctx, mock.Anything).Return(nil)
+		repo.On("AddPhoto", ctx, mock.Anything).Return(errors.New("db down"))
+
+		_, err := uc.AddPhoto(ctx, 7, upload, bytes.NewReader(checkInTestPNG))
+
+		assert.Error(t, err)
+		blobStore.AssertCalled(t, "Delete", ctx, mock.Anything)
+	})
+
+	t.Run("requires the check-in to be recorded first", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, _ := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(nil, nil)
+
+		_, err := uc.AddPhoto(ctx, 7, upload, bytes.NewReader(checkInTestPNG))
+
+		assert.ErrorIs(t, err, ErrCheckInNotFound)
+	})
+
+	t.Run("rejects an upload declared larger than the maximum size", func(t *testing.T) {
+		uc, _, _, _ := newCheckInTestUseCase()
+
+		_, err := uc.AddPhoto(ctx, 7, entities.CheckInPhotoUpload{FileName: "grande.png", Size: 4096}, bytes.NewReader(checkInTestPNG))
+
+		assert.ErrorIs(t, err, ErrCheckInPhotoTooLarge)
+	})
+}
+
+func TestCheckInUseCase_DeletePhoto(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("removes the record and the stored file", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, blobStore := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(&dto.CheckInDTO{ID: 3, ServiceOrderID: 7}, nil)
+		repo.On("GetPhoto", ctx, uint(3), uint(9)).Return(&dto.CheckInPhotoDTO{ID: 9, CheckInID: 3, StorageKey: "check-ins/7/abc.png"}, nil)
+		repo.On("DeletePhoto", ctx, uint(9)).Return(nil)
+		blobStore.On("Delete", ctx, "check-ins/7/abc.png").Return(nil)
+
+		require.NoError(t, uc.DeletePhoto(ctx, 7, 9))
+		blobStore.AssertExpectations(t)
+	})
+
+	t.Run("fails for a photo of another check-in", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, _ := newCheckInTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(checkInTestServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByServiceOrder", ctx, uint(7)).Return(&dto.CheckInDTO{ID: 3, ServiceOrderID: 7}, nil)
+		repo.On("GetPhoto", ctx, uint(3), uint(9)).Return(nil, nil)
+
+		assert.ErrorIs(t, uc.DeletePhoto(ctx, 7, 9), ErrCheckInPhotoNotFound)
+	})
+}
@@ -0,0 +1,424 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	corporateaccount "mecanica_xpto/internal/domain/repository/corporate_account"
+	"mecanica_xpto/internal/domain/repository/customers"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const statementMonthLayout = "2006-01"
+
+var (
+	ErrCorporateAccountNotFound      = errors.New("corporate account not found")
+	ErrCorporateAccountRequiresCNPJ  = errors.New("only company customers may have a corporate account")
+	ErrCorporateContactNotFound      = errors.New("corporate contact not found")
+	ErrInvalidCorporateContactRole   = errors.New("invalid corporate contact role")
+	ErrCorporateStatementNotFound    = errors.New("corporate statement not found")
+	ErrCorporateStatementAlreadyPaid = errors.New("corporate statement is already paid")
+	ErrInvalidStatementMonth         = errors.New("invalid statement month, expected YYYY-MM")
+	ErrStatementMonthNotOver         = errors.New("the statement month is not over yet")
+	ErrCreditLimitExceeded           = errors.New("the corporate account has reached its credit limit")
+	ErrInvalidCreditLimit            = errors.New("the credit limit must be greater than zero")
+	ErrCreditCheckFailed             = errors.New("the credit limit of the corporate account could not be checked")
+	ErrEstimateApproverRequired      = errors.New("the estimate must be approved by an approver of the corporate account")
+	ErrNotEstimateApprover           = errors.New("the contact may not approve estimates of the corporate account")
+)
+
+type ICorporateAccountUseCase interface {
+	GetAccount(ctx context.Context, customerID uint) (*entities.CorporateAccount, error)
+	SaveAccount(ctx context.Context, customerID uint, request entities.CorporateAccountRequest) (*entities.CorporateAccount, error)
+	AddContact(ctx context.Context, customerID uint, request entities.CorporateContactRequest) (*entities.CorporateContact, error)
+	UpdateContact(ctx context.Context, customerID, contactID uint, request entities.CorporateContactRequest) (*entities.CorporateContact, error)
+	RemoveContact(ctx context.Context, customerID, contactID uint) error
+	ListStatements(ctx context.Context, customerID uint) ([]entities.CorporateStatement, error)
+	CloseStatements(ctx context.Context, month string) ([]entities.CorporateStatement, error)
+	GetStatement(ctx context.Context, id uint) (*entities.CorporateStatement, error)
+	PayStatement(ctx context.Context, id uint, method valueobject.PaymentMethod, paidBy string) (*entities.CorporateStatement, error)
+}
+
+type CorporateAccountUseCase struct {
+	repo           corporateaccount.ICorporateAccountRepository
+	customerRepo   customers.ICustomerRepository
+	paymentUseCase IPaymentUseCase
+	now            func() time.Time
+}
+
+var _ ICorporateAccountUseCase = (*CorporateAccountUseCase)(nil)
+
+func NewCorporateAccountUseCase(repo corporateaccount.ICorporateAccountRepository, customerRepo customers.ICustomerRepository, paymentUseCase IPaymentUseCase) *CorporateAccountUseCase {
+	return &CorporateAccountUseCase{
+		repo:           repo,
+		customerRepo:   customerRepo,
+		paymentUseCase: paymentUseCase,
+		now:            time.Now,
+	}
+}
+
+// GetAccount returns the corporate account of the customer with its contacts and the credit
+// still available
+func (u *CorporateAccountUseCase) GetAccount(ctx context.Context, customerID uint) (*entities.CorporateAccount, error) {
+	accountDto, err := u.getAccount(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return u.toAccount(ctx, accountDto)
+}
+
+// SaveAccount opens a corporate account for the company customer, or changes the credit limit of
+// the one it already has. The limit must be greater than zero: a company without credit pays each
+// order and needs no account.
+func (u *CorporateAccountUseCase) SaveAccount(ctx context.Context, customerID uint, request entities.CorporateAccountRequest) (*entities.CorporateAccount, error) {
+	if request.CreditLimit <= 0 {
+		return nil, ErrInvalidCreditLimit
+	}
+
+	customerDto, err := u.customerRepo.GetByID(customerID)
+	if err != nil {
+		log.Error().Msgf("Error finding customer with id %d: %v", customerID, err)
+		return nil, err
+	}
+	if customerDto == nil {
+		return nil, ErrCustomerNotFound
+	}
+	if !valueobject.CpfCnpj(customerDto.CpfCnpj).IsCNPJ() {
+		return nil, ErrCorporateAccountRequiresCNPJ
+	}
+
+	accountDto, err := u.repo.GetByCustomer(ctx, customerID)
+	if err != nil {
+		log.Error().Msgf("Error finding corporate account of customer %d: %v", customerID, err)
+		return nil, err
+	}
+	if accountDto == nil {
+		accountDto = &dto.CorporateAccountDTO{CustomerID: customerID}
+	}
+	accountDto.CreditLimit = request.CreditLimit
+	if err := u.repo.SaveAccount(ctx, accountDto); err != nil {
+		log.Error().Msgf("Error saving corporate account of customer %d: %v", customerID, err)
+		return nil, err
+	}
+
+	accountDto.Customer = customerDto
+	return u.toAccount(ctx, accountDto)
+}
+
+func (u *CorporateAccountUseCase) AddContact(ctx context.Context, customerID uint, request entities.CorporateContactRequest) (*entities.CorporateContact, error) {
+	accountDto, err := u.getAccount(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	contactDto := &dto.CorporateContactDTO{AccountID: accountDto.ID}
+	return u.saveContact(ctx, contactDto, request)
+}
+
+func (u *CorporateAccountUseCase) UpdateContact(ctx context.Context, customerID, contactID uint, request entities.CorporateContactRequest) (*entities.CorporateContact, error) {
+	contactDto, err := u.getContact(ctx, customerID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	return u.saveContact(ctx, contactDto, request)
+}
+
+func (u *CorporateAccountUseCase) RemoveContact(ctx context.Context, customerID, contactID uint) error {
+	if _, err := u.getContact(ctx, customerID, contactID); err != nil {
+		return err
+	}
+	if err := u.repo.DeleteContact(ctx, contactID); err != nil {
+		log.Error().Msgf("Error removing corporate contact %d: %v", contactID, err)
+		return err
+	}
+	return nil
+}
+
+// ListStatements lists the monthly statements of the customer, the latest first
+func (u *CorporateAccountUseCase) ListStatements(ctx context.Context, customerID uint) ([]entities.CorporateStatement, error) {
+	accountDto, err := u.getAccount(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	statementDtos, err := u.repo.ListStatements(ctx, accountDto.ID)
+	if err != nil {
+		log.Error().Msgf("Error listing statements of corporate account %d: %v", accountDto.ID, err)
+		return nil, err
+	}
+	statements := make([]entities.CorporateStatement, 0, len(statementDtos))
+	for i := range statementDtos {
+		statements = append(statements, statementDtos[i].ToDomain())
+	}
+	return statements, nil
+}
+
+// CloseStatements bills the orders delivered to each corporate account up to the end of the month
+// (YYYY-MM, the previous month when empty) and not paid yet into one statement per account. An
+// account already having the statement of the month is left alone, so closing the same month
+// twice is harmless; the orders delivered later are billed in the statement of their own month.
+func (u *CorporateAccountUseCase) CloseStatements(ctx context.Context, month string) ([]entities.CorporateStatement, error) {
+	now := u.now()
+	var start time.Time
+	if month == "" {
+		start = time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+	} else {
+		parsed, err := time.ParseInLocation(statementMonthLayout, month, now.Location())
+		if err != nil {
+			return nil, ErrInvalidStatementMonth
+		}
+		start = parsed
+	}
+	end := start.AddDate(0, 1, 0)
+	if end.After(now) {
+		return nil, ErrStatementMonthNotOver
+	}
+	month = start.Format(statementMonthLayout)
+
+	accounts, err := u.repo.ListAccounts(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing corporate accounts: %v", err)
+		return nil, err
+	}
+
+	statements := make([]entities.CorporateStatement, 0)
+	for _, account := range accounts {
+		closed, err := u.repo.HasStatement(ctx, account.ID, month)
+		if err != nil {
+			log.Error().Msgf("Error checking statement %s of corporate account %d: %v", month, account.ID, err)
+			return nil, err
+		}
+		if closed {
+			continue
+		}
+
+		serviceOrders, err := u.repo.ListUnbilledOrders(ctx, account.CustomerID, end)
+		if err != nil {
+			log.Error().Msgf("Error listing unbilled orders of customer %d: %v", account.CustomerID, err)
+			return nil, err
+		}
+		if len(serviceOrders) == 0 {
+			continue
+		}
+
+		statementDto := &dto.CorporateStatementDTO{
+			AccountID: account.ID,
+			Month:     month,
+			ClosedAt:  now,
+			Items:     make([]dto.CorporateStatementItemDTO, 0, len(serviceOrders)),
+		}
+		for _, serviceOrder := range serviceOrders {
+			statementDto.Items = append(statementDto.Items, dto.CorporateStatementItemDTO{
+				ServiceOrderID: serviceOrder.ID,
+				ServiceOrder:   serviceOrder,
+				Amount:         serviceOrder.Estimate,
+			})
+			statementDto.Total += serviceOrder.Estimate
+		}
+		if err := u.repo.CreateStatement(ctx, statementDto); err != nil {
+			log.Error().Msgf("Error creating statement %s of corporate account %d: %v", month, account.ID, err)
+			return nil, err
+		}
+
+		statementDto.Account = &account
+		statements = append(statements, statementDto.ToDomain())
+	}
+	return statements, nil
+}
+
+func (u *CorporateAccountUseCase) GetStatement(ctx context.Context, id uint) (*entities.CorporateStatement, error) {
+	statementDto, err := u.getStatement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	statement := statementDto.ToDomain()
+	return &statement, nil
+}
+
+// PayStatement records the payment of the statement, paying each of its orders with the given
+// method. The orders paid meanwhile are skipped, so a payment interrupted halfway can be retried.
+func (u *CorporateAccountUseCase) PayStatement(ctx context.Context, id uint, method valueobject.PaymentMethod, paidBy string) (*entities.CorporateStatement, error) {
+	if !method.IsValid() {
+		return nil, ErrInvalidPaymentMethod
+	}
+
+	statementDto, err := u.getStatement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if statementDto.PaidAt != nil {
+		return nil, ErrCorporateStatementAlreadyPaid
+	}
+
+	for _, item := range statementDto.Items {
+		_, err := u.paymentUseCase.CreatePayment(ctx, &entities.Payment{
+			ServiceOrderID: item.ServiceOrderID,
+			Amount:         item.Amount,
+			Method:         method,
+			Operator:       paidBy,
+		})
+		if err != nil && !errors.Is(err, ErrPaymentAlreadyExists) {
+			log.Error().Msgf("Error paying service order %d of statement %d: %v", item.ServiceOrderID, id, err)
+			return nil, err
+		}
+	}
+
+	paidAt := u.now()
+	paid, err := u.repo.MarkStatementPaid(ctx, id, paidBy, paidAt)
+	if err != nil {
+		log.Error().Msgf("Error marking statement %d as paid: %v", id, err)
+		return nil, err
+	}
+	if !paid {
+		return nil, ErrCorporateStatementAlreadyPaid
+	}
+
+	statementDto.PaidAt = &paidAt
+	statementDto.PaidBy = paidBy
+	statement := statementDto.ToDomain()
+	return &statement, nil
+}
+
+func (u *CorporateAccountUseCase) getAccount(ctx context.Context, customerID uint) (*dto.CorporateAccountDTO, error) {
+	accountDto, err := u.repo.GetByCustomer(ctx, customerID)
+	if err != nil {
+		log.Error().Msgf("Error finding corporate account of customer %d: %v", customerID, err)
+		return nil, err
+	}
+	if accountDto == nil {
+		return nil, ErrCorporateAccountNotFound
+	}
+	return accountDto, nil
+}
+
+// getContact finds the contact, which must belong to the account of the customer
+func (u *CorporateAccountUseCase) getContact(ctx context.Context, customerID, contactID uint) (*dto.CorporateContactDTO, error) {
+	accountDto, err := u.getAccount(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	contactDto, err := u.repo.GetContact(ctx, contactID)
+	if err != nil {
+		log.Error().Msgf("Error finding corporate contact %d: %v", contactID, err)
+		return nil, err
+	}
+	if contactDto == nil || contactDto.AccountID != accountDto.ID {
+		return nil, ErrCorporateContactNotFound
+	}
+	return contactDto, nil
+}
+
+func (u *CorporateAccountUseCase) getStatement(ctx context.Context, id uint) (*dto.CorporateStatementDTO, error) {
+	statementDto, err := u.repo.GetStatement(ctx, id)
+	if err != nil {
+		log.Error().Msgf("Error finding corporate statement %d: %v", id, err)
+		return nil, err
+	}
+	if statementDto == nil {
+		return nil, ErrCorporateStatementNotFound
+	}
+	return statementDto, nil
+}
+
+func (u *CorporateAccountUseCase) saveContact(ctx context.Context, contactDto *dto.CorporateContactDTO, request entities.CorporateContactRequest) (*entities.CorporateContact, error) {
+	role := valueobject.ParseCorporateContactRole(request.Role)
+	if !role.IsValid() {
+		return nil, ErrInvalidCorporateContactRole
+	}
+
+	contactDto.Name = request.Name
+	contactDto.Email = request.Email
+	contactDto.Phone = request.Phone
+	contactDto.Role = role.String()
+	contactDto.CanApproveEstimates = request.CanApproveEstimates
+	if err := u.repo.SaveContact(ctx, contactDto); err != nil {
+		log.Error().Msgf("Error saving corporate contact of account %d: %v", contactDto.AccountID, err)
+		return nil, err
+	}
+
+	contact := contactDto.ToDomain()
+	return &contact, nil
+}
+
+func (u *CorporateAccountUseCase) toAccount(ctx context.Context, accountDto *dto.CorporateAccountDTO) (*entities.CorporateAccount, error) {
+	balance, err := u.repo.OpenBalance(ctx, accountDto.CustomerID)
+	if err != nil {
+		log.Error().Msgf("Error computing open balance of customer %d: %v", accountDto.CustomerID, err)
+		return nil, err
+	}
+
+	account := accountDto.ToDomain()
+	account.OpenBalance = balance
+	account.AvailableCredit = max(account.CreditLimit-balance, 0)
+	return &account, nil
+}
+
+// checkCreditLimit refuses an order of a customer whose corporate account would owe more than its
+// credit limit with it. estimate is what the order adds to the open balance, nothing for an order
+// just opened. Customers without a corporate account pay each order and have no limit, and the
+// limit of an account is always greater than zero. Failures reading the account or its balance are
+// wrapped in ErrCreditCheckFailed.
+func checkCreditLimit(ctx context.Context, repo corporateaccount.ICorporateAccountRepository, customerID uint, estimate float64) error {
+	accountDto, err := repo.GetByCustomer(ctx, customerID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCreditCheckFailed, err)
+	}
+	if accountDto == nil {
+		return nil
+	}
+
+	balance, err := repo.OpenBalance(ctx, customerID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCreditCheckFailed, err)
+	}
+	if balance+estimate > accountDto.CreditLimit {
+		return ErrCreditLimitExceeded
+	}
+	return nil
+}
+
+// checkEstimateApprover makes sure the estimates of a company whose corporate account names
+// approvers are approved by one of them
+func checkEstimateApprover(ctx context.Context, repo corporateaccount.ICorporateAccountRepository, customerID uint, approverContactID *uint) error {
+	accountDto, err := repo.GetByCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	hasApprovers := false
+	if accountDto != nil {
+		for _, contact := range accountDto.Contacts {
+			if !contact.CanApproveEstimates {
+				continue
+			}
+			hasApprovers = true
+			if approverContactID != nil && contact.ID == *approverContactID {
+				return nil
+			}
+		}
+	}
+
+	if approverContactID != nil {
+		return ErrNotEstimateApprover
+	}
+	if hasApprovers {
+		return ErrEstimateApproverRequired
+	}
+	return nil
+}
+
+// isBilledMonthly tells whether the orders of the customer are billed in the monthly statement of
+// a corporate account rather than paid on delivery
+func isBilledMonthly(ctx context.Context, repo corporateaccount.ICorporateAccountRepository, customerID uint) (bool, error) {
+	accountDto, err := repo.GetByCustomer(ctx, customerID)
+	if err != nil {
+		return false, err
+	}
+	return accountDto != nil, nil
+}
@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+var corporateNow = time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC)
+
+func newCorporateAccountTestUseCase() (*CorporateAccountUseCase, *mocks.MockCorporateAccountRepository, *MockCustomerRepository, *mocks.MockPaymentUseCase) {
+	repo := new(mocks.MockCorporateAccountRepository)
+	customerRepo := new(MockCustomerRepository)
+	paymentUseCase := new(mocks.MockPaymentUseCase)
+	uc := NewCorporateAccountUseCase(repo, customerRepo, paymentUseCase)
+	uc.now = func() time.Time { return corporateNow }
+	return uc, repo, customerRepo, paymentUseCase
+}
+
+func corporateAccount(contacts ...dto.CorporateContactDTO) *dto.CorporateAccountDTO {
+	return &dto.CorporateAccountDTO{ID: 1, CustomerID: 3, CreditLimit: 5000, Contacts: contacts}
+}
+
+func TestCorporateAccountUseCase_SaveAccount(t *testing.T) {
+	ctx := context.Background()
+	request := entities.CorporateAccountRequest{CreditLimit: 5000}
+
+	t.Run("opens the account of a company", func(t *testing.T) {
+		uc, repo, customerRepo, _ := newCorporateAccountTestUseCase()
+		customerRepo.On("GetByID", uint(3)).Return(&dto.CustomerDTO{ID: 3, FullName: "Transportes Lima", CpfCnpj: "11.222.333/0001-81"}, nil)
+		repo.On("GetByCustomer", ctx, uint(3)).Return(nil, nil)
+		repo.On("SaveAccount", ctx, mock.MatchedBy(func(account *dto.CorporateAccountDTO) bool {
+			return account.CustomerID == 3 && account.CreditLimit == 5000
+		})).Return(nil)
+		repo.On("OpenBalance", ctx, uint(3)).Return(1200.0, nil)
+
+		account, err := uc.SaveAccount(ctx, 3, request)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Transportes Lima", account.CustomerName)
+		assert.Equal(t, 1200.0, account.OpenBalance)
+		assert.Equal(t, 3800.0, account.AvailableCredit)
+	})
+
+	t.Run("refuses a person", func(t *testing.T) {
+		uc, repo, customerRepo, _ := newCorporateAccountTestUseCase()
+		customerRepo.On("GetByID", uint(3)).Return(&dto.CustomerDTO{ID: 3, CpfCnpj: "529.982.247-25"}, nil)
+
+		_, err := uc.SaveAccount(ctx, 3, request)
+
+		assert.ErrorIs(t, err, ErrCorporateAccountRequiresCNPJ)
+		repo.AssertNotCalled(t, "SaveAccount", mock.Anything, mock.Anything)
+	})
+
+	t.Run("refuses a limit of zero", func(t *testing.T) {
+		uc, repo, customerRepo, _ := newCorporateAccountTestUseCase()
+
+		_, err := uc.SaveAccount(ctx, 3, entities.CorporateAccountRequest{CreditLimit: 0})
+
+		assert.ErrorIs(t, err, ErrInvalidCreditLimit)
+		customerRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+		repo.AssertNotCalled(t, "SaveAccount", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCorporateAccountUseCase_Contacts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("adds a contact allowed to approve estimates", func(t *testing.T) {
+		uc, repo, _, _ := newCorporateAccountTestUseCase()
+		repo.On("GetByCustomer", ctx, uint(3)).Return(corporateAccount(), nil)
+		repo.On("SaveContact", ctx, mock.AnythingOfType("*dto.CorporateContactDTO")).Return(nil)
+
+		contact, err := uc.AddContact(ctx, 3, entities.CorporateContactRequest{Name: "Ana", Role: "COMPRAS", CanApproveEstimates: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), contact.AccountID)
+		assert.Equal(t, valueobject.CorporateRolePurchasing, contact.Role)
+		assert.True(t, contact.CanApproveEstimates)
+	})
+
+	t.Run("refuses an unknown role", func(t *testing.T) {
+		uc, repo, _, _ := newCorporateAccountTestUseCase()
+		repo.On("GetByCustomer", ctx, uint(3)).Return(corporateAccount(), nil)
+
+		_, err := uc.AddContact(ctx, 3, entities.CorporateContactRequest{Name: "Ana", Role: "DIRETORA"})
+
+		assert.ErrorIs(t, err, ErrInvalidCorporateContactRole)
+	})
+
+	t.Run("does not remove the contact of another account", func(t *testing.T) {
+		uc, repo, _, _ := newCorporateAccountTestUseCase()
+		repo.On("GetByCustomer", ctx, uint(3)).Return(corporateAccount(), nil)
+		repo.On("GetContact", ctx, uint(9)).Return(&dto.CorporateContactDTO{ID: 9, AccountID: 2}, nil)
+
+		err := uc.RemoveContact(ctx, 3, 9)
+
+		assert.ErrorIs(t, err, ErrCorporateContactNotFound)
+		repo.AssertNotCalled(t, "DeleteContact", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCorporateAccountUseCase_CloseStatements(t *testing.T) {
+	ctx := context.Background()
+	monthEnd := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("bills the unpaid deliveries of the previous month", func(t *testing.T) {
+		uc, repo, _, _ := newCorporateAccountTestUseCase()
+		repo.On("ListAccounts", ctx).Return([]dto.CorporateAccountDTO{*corporateAccount(), {ID: 2, CustomerID: 4}}, nil)
+		repo.On("HasStatement", ctx, uint(1), "2025-10").Return(false, nil)
+		repo.On("HasStatement", ctx, uint(2), "2025-10").Return(true, nil)
+		repo.On("ListUnbilledOrders", ctx, uint(3), monthEnd).Return([]dto.ServiceOrderDTO{
+			{ID: 10, CustomerID: 3, VehicleID: 5, Estimate: 300},
+			{ID: 11, CustomerID: 3, VehicleID: 6, Estimate: 450.5},
+		}, nil)
+		repo.On("CreateStatement", ctx, mock.MatchedBy(func(statement *dto.CorporateStatementDTO) bool {
+			return statement.AccountID == 1 && statement.Month == "2025-10" && statement.Total == 750.5 && len(statement.Items) == 2
+		})).Return(nil)
+
+		statements, err := uc.CloseStatements(ctx, "")
+
+		require.NoError(t, err)
+		require.Len(t, statements, 1)
+		assert.Equal(t, uint(3), statements[0].CustomerID)
+		assert.Equal(t, 750.5, statements[0].Total)
+		repo.AssertNotCalled(t, "ListUnbilledOrders", ctx, uint(4), mock.Anything)
+	})
+
+	t.Run("refuses a month not over yet", func(t *testing.T) {
+		uc, repo, _, _ := newCorporateAccountTestUseCase()
+
+		_, err := uc.CloseStatements(ctx, "2025-11")
+
+		assert.ErrorIs(t, err, ErrStatementMonthNotOver)
+		repo.AssertNotCalled(t, "ListAccounts", mock.Anything)
+	})
+
+	t.Run("refuses an invalid month", func(t *testing.T) {
+		uc, _, _, _ := newCorporateAccountTestUseCase()
+
+		_, err := uc.CloseStatements(ctx, "10/2025")
+
+		assert.ErrorIs(t, err, ErrInvalidStatementMonth)
+	})
+}
+
+func TestCorporateAccountUseCase_PayStatement(t *testing.T) {
+	ctx := context.Background()
+	statement := func() *dto.CorporateStatementDTO {
+		return &dto.CorporateStatementDTO{ID: 20, AccountID: 1, Month: "2025-10", Total: 750.5, Items: []dto.CorporateStatementItemDTO{
+			{ServiceOrderID: 10, Amount: 300},
+			{ServiceOrderID: 11, Amount: 450.5},
+		}}
+	}
+
+	t.Run("pays every order of the statement", func(t *testing.T) {
+		uc, repo, _, paymentUseCase := newCorporateAccountTestUseCase()
+		repo.On("GetStatement", ctx, uint(20)).Return(statement(), nil)
+		paymentUseCase.On("CreatePayment", ctx, &entities.Payment{ServiceOrderID: 10, Amount: 300, Method: valueobject.PaymentBankSlip, Operator: "financeiro@xpto.com"}).
+			Return(&entities.Payment{ID: 1}, nil)
+		paymentUseCase.On("CreatePayment", ctx, &entities.Payment{ServiceOrderID: 11, Amount: 450.5, Method: valueobject.PaymentBankSlip, Operator: "financeiro@xpto.com"}).
+			Return(nil, ErrPaymentAlreadyExists)
+		repo.On("MarkStatementPaid", ctx, uint(20), "financeiro@xpto.com", corporateNow).Return(true, nil)
+
+		paid, err := uc.PayStatement(ctx, 20, valueobject.PaymentBankSlip, "financeiro@xpto.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, corporateNow, *paid.PaidAt)
+		paymentUseCase.AssertExpectations(t)
+	})
+
+	t.Run("refuses a paid statement", func(t *testing.T) {
+		uc, repo, _, paymentUseCase := newCorporateAccountTestUseCase()
+		paidStatement := statement()
+		paidStatement.PaidAt = &corporateNow
+		repo.On("GetStatement", ctx, uint(20)).Return(paidStatement, nil)
+
+		_, err := uc.PayStatement(ctx, 20, valueobject.PaymentBankSlip, "financeiro@xpto.com")
+
+		assert.ErrorIs(t, err, ErrCorporateStatementAlreadyPaid)
+		paymentUseCase.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCheckCreditLimit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("allows customers without a corporate account", func(t *testing.T) {
+		repo := new(mocks.MockCorporateAccountRepository)
+		repo.On("GetByCustomer", ctx, uint(3)).Return(nil, nil)
+
+		assert.NoError(t, checkCreditLimit(ctx, repo, 3, 800))
+	})
+
+	t.Run("refuses an estimate beyond the limit", func(t *testing.T) {
+		repo := new(mocks.MockCorporateAccountRepository)
+		repo.On("GetByCustomer", ctx, uint(3)).Return(corporateAccount(), nil)
+		repo.On("OpenBalance", ctx, uint(3)).Return(4500.0, nil)
+
+		assert.ErrorIs(t, checkCreditLimit(ctx, repo, 3, 500.01), ErrCreditLimitExceeded)
+	})
+
+	t.Run("allows an estimate reaching the limit", func(t *testing.T) {
+		repo := new(mocks.MockCorporateAccountRepository)
+		repo.On("GetByCustomer", ctx, uint(3)).Return(corporateAccount(), nil)
+		repo.On("OpenBalance", ctx, uint(3)).Return(4500.0, nil)
+
+		assert.NoError(t, checkCreditLimit(ctx, repo, 3, 500))
+	})
+
+	t.Run("refuses an account owing more than its limit", func(t *testing.T) {
+		repo := new(mocks.MockCorporateAccountRepository)
+		repo.On("GetByCustomer", ctx, uint(3)).Return(corporateAccount(), nil)
+		repo.On("OpenBalance", ctx, uint(3)).Return(5000.01, nil)
+
+		assert.ErrorIs(t, checkCreditLimit(ctx, repo, 3, 0), ErrCreditLimitExceeded)
+	})
+
+	t.Run("wraps the errors of the repository", func(t *testing.T) {
+		repo := new(mocks.MockCorporateAccountRepository)
+		repo.On("GetByCustomer", ctx, uint(3)).Return(corporateAccount(), nil)
+		repo.On("OpenBalance", ctx, uint(3)).Return(0.0, errors.New("db down"))
+
+		err := checkCreditLimit(ctx, repo, 3, 0)
+
+		assert.ErrorIs(t, err, ErrCreditCheckFailed)
+		assert.ErrorContains(t, err, "db down")
+	})
+}
+
+func TestCheckEstimateApprover(t *testing.T) {
+	ctx := context.Background()
+	approver := uint(7)
+	driver := uint(8)
+	account := corporateAccount(
+		dto.CorporateContactDTO{ID: 7, AccountID: 1, CanApproveEstimates: true},
+		dto.CorporateContactDTO{ID: 8, AccountID: 1},
+	)
+
+	tests := []struct {
+		name              string
+		account           *dto.CorporateAccountDTO
+		approverContactID *uint
+		expectedError     error
+	}{
+		{name: "customer without a corporate account", account: nil, approverContactID: nil, expectedError: nil},
+		{name: "approved by an approver", account: account, approverContactID: &approver, expectedError: nil},
+		{name: "approver missing", account: account, approverContactID: nil, expectedError: ErrEstimateApproverRequired},
+		{name: "contact not allowed to approve", account: account, approverContactID: &driver, expectedError: ErrNotEstimateApprover},
+		{name: "account without approvers", account: corporateAccount(), approverContactID: nil, expectedError: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockCorporateAccountRepository)
+			if tt.account == nil {
+				repo.On("GetByCustomer", ctx, uint(3)).Return(nil, nil)
+			} else {
+				repo.On("GetByCustomer", ctx, uint(3)).Return(tt.account, nil)
+			}
+
+			err := checkEstimateApprover(ctx, repo, 3, tt.approverContactID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
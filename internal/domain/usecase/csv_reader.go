@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// newCSVReader reads a CSV file with the separator of its header line, since the files exported
+// from spreadsheets in Portuguese are separated by semicolons rather than commas
+func newCSVReader(file io.Reader) (*csv.Reader, error) {
+	buffered := bufio.NewReader(file)
+	header, err := buffered.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	header = strings.TrimPrefix(header, "\ufeff")
+
+	reader := csv.NewReader(io.MultiReader(strings.NewReader(header), buffered))
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader, nil
+}
+
+// normaliseCSVHeader writes a column header the way the column maps look it up, e.g. "Código FIPE"
+// as "codigo_fipe"
+func normaliseCSVHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_", "ó", "o", "ô", "o", "ç", "c", "ã", "a").Replace(name)
+}
@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	corporateaccount "mecanica_xpto/internal/domain/repository/corporate_account"
+	customermerge "mecanica_xpto/internal/domain/repository/customer_merge"
+	"mecanica_xpto/internal/domain/repository/customers"
+)
+
+const (
+	// minPhoneDigits leaves out the phones too short to tell a customer apart, e.g. without area code
+	minPhoneDigits = 8
+	// Names at least minFuzzyNameLength letters long are similar up to maxNameDistance typos
+	minFuzzyNameLength = 10
+	maxNameDistance    = 2
+)
+
+var (
+	ErrMergeSameCustomer      = errors.New("a customer cannot be merged into itself")
+	ErrMergeCorporateAccounts = errors.New("both customers have a corporate account")
+	ErrMergedCustomerGone     = errors.New("the duplicate customer was merged or deleted in the meantime")
+)
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+type ICustomerMergeUseCase interface {
+	FindDuplicates(ctx context.Context) ([]entities.CustomerDuplicateGroup, error)
+	MergeCustomers(ctx context.Context, survivorID uint, request entities.CustomerMergeRequest, mergedBy string) (*entities.CustomerMerge, error)
+	ListMerges(ctx context.Context, customerID uint) ([]entities.CustomerMerge, error)
+}
+
+type CustomerMergeUseCase struct {
+	repo          customermerge.ICustomerMergeRepository
+	customerRepo  customers.ICustomerRepository
+	corporateRepo corporateaccount.ICorporateAccountRepository
+	now           func() time.Time
+}
+
+var _ ICustomerMergeUseCase = (*CustomerMergeUseCase)(nil)
+
+func NewCustomerMergeUseCase(repo customermerge.ICustomerMergeRepository, customerRepo customers.ICustomerRepository, corporateRepo corporateaccount.ICorporateAccountRepository) *CustomerMergeUseCase {
+	return &CustomerMergeUseCase{
+		repo:          repo,
+		customerRepo:  customerRepo,
+		corporateRepo: corporateRepo,
+		now:           time.Now,
+	}
+}
+
+// FindDuplicates groups the customers sharing a document or a phone number, or with similar names.
+// A customer may be in several groups; a group with the same customers as a previous one is left
+// out. The customers of a group are listed oldest first.
+func (u *CustomerMergeUseCase) FindDuplicates(ctx context.Context) ([]entities.CustomerDuplicateGroup, error) {
+	customerDtos, err := u.customerRepo.List()
+	if err != nil {
+		log.Error().Msgf("Error listing customers: %v", err)
+		return nil, err
+	}
+	sort.Slice(customerDtos, func(i, j int) bool { return customerDtos[i].ID < customerDtos[j].ID })
+
+	var clusters [][]int
+	var reasons []valueobject.CustomerDuplicateReason
+	add := func(reason valueobject.CustomerDuplicateReason, found [][]int) {
+		clusters = append(clusters, found...)
+		for range found {
+			reasons = append(reasons, reason)
+		}
+	}
+	add(valueobject.DuplicateByDocument, groupByKey(len(customerDtos), func(i int) string {
+		return valueobject.CpfCnpj(customerDtos[i].CpfCnpj).Normalized().String()
+	}))
+	add(valueobject.DuplicateByPhone, groupByKey(len(customerDtos), func(i int) string {
+		return phoneKey(customerDtos[i].PhoneNumber)
+	}))
+	add(valueobject.DuplicateByName, groupBySimilarName(customerDtos))
+
+	groups := make([]entities.CustomerDuplicateGroup, 0, len(clusters))
+	seen := make(map[string]bool)
+	for i, cluster := range clusters {
+		key := fmt.Sprint(cluster)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		group := entities.CustomerDuplicateGroup{Reason: reasons[i], Customers: make([]entities.Customer, 0, len(cluster))}
+		for _, index := range cluster {
+			group.Customers = append(group.Customers, *customerDtos[index].ToDomain())
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// MergeCustomers moves the vehicles, service orders with their payments, appointments, price
+// agreements and corporate account of the duplicate customer onto the survivor, deletes the
+// duplicate and records the merge. Customers who both have a corporate account cannot be merged,
+// since an account has the billing terms agreed with one company.
+func (u *CustomerMergeUseCase) MergeCustomers(ctx context.Context, survivorID uint, request entities.CustomerMergeRequest, mergedBy string) (*entities.CustomerMerge, error) {
+	if request.DuplicateID == survivorID {
+		return nil, ErrMergeSameCustomer
+	}
+
+	survivor, err := u.getCustomer(survivorID)
+	if err != nil {
+		return nil, err
+	}
+	duplicate, err := u.getCustomer(request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	survivorAccount, err := u.corporateRepo.GetByCustomer(ctx, survivor.ID)
+	if err != nil {
+		log.Error().Msgf("Error finding corporate account of customer %d: %v", survivor.ID, err)
+		return nil, err
+	}
+	duplicateAccount, err := u.corporateRepo.GetByCustomer(ctx, duplicate.ID)
+	if err != nil {
+		log.Error().Msgf("Error finding corporate account of customer %d: %v", duplicate.ID, err)
+		return nil, err
+	}
+	if survivorAccount != nil && duplicateAccount != nil {
+		return nil, ErrMergeCorporateAccounts
+	}
+
+	merge := &dto.CustomerMergeDTO{
+		SurvivorID:        survivor.ID,
+		MergedCustomerID:  duplicate.ID,
+		MergedFullName:    duplicate.FullName,
+		MergedDocument:    duplicate.CpfCnpj,
+		MergedPhoneNumber: duplicate.PhoneNumber,
+		MergedBy:          mergedBy,
+		MergedAt:          u.now(),
+	}
+	if duplicate.User != nil {
+		merge.MergedEmail = duplicate.User.Email
+	}
+	if err := u.repo.Merge(ctx, survivor.ID, duplicate, merge); err != nil {
+		if errors.Is(err, customermerge.ErrCustomerGone) {
+			return nil, ErrMergedCustomerGone
+		}
+		log.Error().Msgf("Error merging customer %d into customer %d: %v", duplicate.ID, survivor.ID, err)
+		return nil, err
+	}
+
+	result := merge.ToDomain()
+	return &result, nil
+}
+
+// ListMerges lists the merges into the customer, or all of them when customerID is 0, newest first
+func (u *CustomerMergeUseCase) ListMerges(ctx context.Context, customerID uint) ([]entities.CustomerMerge, error) {
+	merges, err := u.repo.List(ctx, customerID)
+	if err != nil {
+		log.Error().Msgf("Error listing customer merges: %v", err)
+		return nil, err
+	}
+	result := make([]entities.CustomerMerge, 0, len(merges))
+	for i := range merges {
+		result = append(result, merges[i].ToDomain())
+	}
+	return result, nil
+}
+
+func (u *CustomerMergeUseCase) getCustomer(id uint) (*dto.CustomerDTO, error) {
+	customer, err := u.customerRepo.GetByID(id)
+	if err != nil {
+		log.Error().Msgf("Error finding customer with id %d: %v", id, err)
+		return nil, err
+	}
+	if customer == nil || customer.ID == 0 {
+		return nil, ErrCustomerNotFound
+	}
+	return customer, nil
+}
+
+// groupByKey returns the indexes of the customers sharing a non-empty key, in groups of two or more
+func groupByKey(count int, key func(i int) string) [][]int {
+	indexes := make(map[string][]int)
+	var keys []string
+	for i := 0; i < count; i++ {
+		k := key(i)
+		if k == "" {
+			continue
+		}
+		if _, ok := indexes[k]; !ok {
+			keys = append(keys, k)
+		}
+		indexes[k] = append(indexes[k], i)
+	}
+
+	var groups [][]int
+	for _, k := range keys {
+		if len(indexes[k]) > 1 {
+			groups = append(groups, indexes[k])
+		}
+	}
+	return groups
+}
+
+// groupBySimilarName joins the customers with similar names, so that a name similar to two others
+// puts the three in the same group
+func groupBySimilarName(customerDtos []dto.CustomerDTO) [][]int {
+	names := make([]string, len(customerDtos))
+	parent := make([]int, len(customerDtos))
+	for i, customer := range customerDtos {
+		names[i] = foldName(customer.FullName)
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			if similarNames(names[i], names[j]) {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+
+	return groupByKey(len(names), func(i int) string {
+		if names[i] == "" {
+			return ""
+		}
+		return fmt.Sprint(root(i))
+	})
+}
+
+// foldName puts the name in lower case, without accents and with single spaces
+func foldName(name string) string {
+	return strings.Join(strings.Fields(accentReplacer.Replace(strings.ToLower(name))), " ")
+}
+
+func similarNames(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < minFuzzyNameLength || len(rb) < minFuzzyNameLength {
+		return false
+	}
+	if len(ra)-len(rb) > maxNameDistance || len(rb)-len(ra) > maxNameDistance {
+		return false
+	}
+	return levenshtein(ra, rb) <= maxNameDistance
+}
+
+// levenshtein counts the letters to insert, delete or replace to turn a into b
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// phoneKey keeps the digits of the phone number without the country code of Brazil and the
+// leading zero of long distance calls, so that +55 (11) 98765-4321 and 011987654321 match
+func phoneKey(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	key := digits.String()
+	if len(key) >= 12 && strings.HasPrefix(key, "55") {
+		key = key[2:]
+	}
+	key = strings.TrimLeft(key, "0")
+	if len(key) < minPhoneDigits {
+		return ""
+	}
+	return key
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	customermerge "mecanica_xpto/internal/domain/repository/customer_merge"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+var mergeNow = time.Date(2025, 11, 10, 16, 30, 0, 0, time.UTC)
+
+func newCustomerMergeTestUseCase() (*CustomerMergeUseCase, *mocks.MockCustomerMergeRepository, *MockCustomerRepository, *mocks.MockCorporateAccountRepository) {
+	repo := new(mocks.MockCustomerMergeRepository)
+	customerRepo := new(MockCustomerRepository)
+	corporateRepo := new(mocks.MockCorporateAccountRepository)
+	uc := NewCustomerMergeUseCase(repo, customerRepo, corporateRepo)
+	uc.now = func() time.Time { return mergeNow }
+	return uc, repo, customerRepo, corporateRepo
+}
+
+func customerIDs(group entities.CustomerDuplicateGroup) []uint {
+	ids := make([]uint, 0, len(group.Customers))
+	for _, customer := range group.Customers {
+		ids = append(ids, customer.ID)
+	}
+	return ids
+}
+
+func TestCustomerMergeUseCase_FindDuplicates(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("groups the customers by document, phone and similar name", func(t *testing.T) {
+		uc, _, customerRepo, _ := newCustomerMergeTestUseCase()
+		customerRepo.On("List").Return([]dto.CustomerDTO{
+			{ID: 4, FullName: "Maria Souza", CpfCnpj: "529.982.247-25", PhoneNumber: "11 98888-7777"},
+			{ID: 1, FullName: "Maria Souza", CpfCnpj: "52998224725", PhoneNumber: "11987654321"},
+			{ID: 2, FullName: "Pedro Alves", CpfCnpj: "11144477735", PhoneNumber: "+55 (11) 98765-4321"},
+			{ID: 3, FullName: "João Conceição Lima", CpfCnpj: "39053344705", PhoneNumber: "2133334444"},
+			{ID: 5, FullName: "joao  conceicao lina", CpfCnpj: "11222333000181", PhoneNumber: "4133335555"},
+			{ID: 6, FullName: "Ana Lima", CpfCnpj: "86288366757", PhoneNumber: "4133336666"},
+			{ID: 7, FullName: "Ana Lia", CpfCnpj: "71428793860", PhoneNumber: "4133337777"},
+		}, nil)
+
+		groups, err := uc.FindDuplicates(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, groups, 3)
+		assert.Equal(t, valueobject.DuplicateByDocument, groups[0].Reason)
+		assert.Equal(t, []uint{1, 4}, customerIDs(groups[0]))
+		assert.Equal(t, valueobject.DuplicateByPhone, groups[1].Reason)
+		assert.Equal(t, []uint{1, 2}, customerIDs(groups[1]))
+		assert.Equal(t, valueobject.DuplicateByName, groups[2].Reason)
+		assert.Equal(t, []uint{3, 5}, customerIDs(groups[2]))
+	})
+
+	t.Run("finds no duplicates among distinct customers", func(t *testing.T) {
+		uc, _, customerRepo, _ := newCustomerMergeTestUseCase()
+		customerRepo.On("List").Return([]dto.CustomerDTO{
+			{ID: 1, FullName: "Maria Souza", CpfCnpj: "52998224725", PhoneNumber: "11987654321"},
+			{ID: 2, FullName: "Pedro Alves", CpfCnpj: "11144477735", PhoneNumber: "123"},
+			{ID: 3, FullName: "Paulo Alves", CpfCnpj: "39053344705", PhoneNumber: "123"},
+		}, nil)
+
+		groups, err := uc.FindDuplicates(ctx)
+
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+	})
+}
+
+func TestCustomerMergeUseCase_MergeCustomers(t *testing.T) {
+	ctx := context.Background()
+	survivor := &dto.CustomerDTO{ID: 1, FullName: "Maria Souza", CpfCnpj: "52998224725"}
+	duplicate := &dto.CustomerDTO{ID: 4, UserID: 9, FullName: "Maria Sousa", CpfCnpj: "529.982.247-25", PhoneNumber: "11988887777", User: &dto.UserDTO{ID: 9, Email: "maria@mail.com"}}
+	request := entities.CustomerMergeRequest{DuplicateID: 4}
+
+	t.Run("merges the duplicate into the survivor and records it", func(t *testing.T) {
+		uc, repo, customerRepo, corporateRepo := newCustomerMergeTestUseCase()
+		customerRepo.On("GetByID", uint(1)).Return(survivor, nil)
+		customerRepo.On("GetByID", uint(4)).Return(duplicate, nil)
+		corporateRepo.On("GetByCustomer", ctx, uint(1)).Return(nil, nil)
+		corporateRepo.On("GetByCustomer", ctx, uint(4)).Return(&dto.CorporateAccountDTO{ID: 2, CustomerID: 4}, nil)
+		repo.On("Merge", ctx, uint(1), duplicate, mock.MatchedBy(func(merge *dto.CustomerMergeDTO) bool {
+			return merge.SurvivorID == 1 && merge.MergedCustomerID == 4 && merge.MergedBy == "ana@mecanicaxpto.com.br" && merge.MergedAt.Equal(mergeNow)
+		})).Run(func(args mock.Arguments) {
+			merge := args.Get(3).(*dto.CustomerMergeDTO)
+			merge.ID = 12
+			merge.VehiclesMoved = 2
+			merge.ServiceOrdersMoved = 3
+			merge.PaymentsMoved = 4
+		}).Return(nil)
+
+		merge, err := uc.MergeCustomers(ctx, 1, request, "ana@mecanicaxpto.com.br")
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(12), merge.ID)
+		assert.Equal(t, "Maria Sousa", merge.MergedFullName)
+		assert.Equal(t, "529.982.247-25", merge.MergedDocument)
+		assert.Equal(t, "maria@mail.com", merge.MergedEmail)
+		assert.Equal(t, int64(2), merge.VehiclesMoved)
+		assert.Equal(t, int64(3), merge.ServiceOrdersMoved)
+		assert.Equal(t, int64(4), merge.PaymentsMoved)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("refuses to merge a customer into itself", func(t *testing.T) {
+		uc, repo, _, _ := newCustomerMergeTestUseCase()
+
+		_, err := uc.MergeCustomers(ctx, 4, request, "ana@mecanicaxpto.com.br")
+
+		assert.ErrorIs(t, err, ErrMergeSameCustomer)
+		repo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails when the duplicate does not exist", func(t *testing.T) {
+		uc, _, customerRepo, _ := newCustomerMergeTestUseCase()
+		customerRepo.On("GetByID", uint(1)).Return(survivor, nil)
+		customerRepo.On("GetByID", uint(4)).Return(nil, nil)
+
+		_, err := uc.MergeCustomers(ctx, 1, request, "ana@mecanicaxpto.com.br")
+
+		assert.ErrorIs(t, err, ErrCustomerNotFound)
+	})
+
+	t.Run("refuses when both customers have a corporate account", func(t *testing.T) {
+		uc, repo, customerRepo, corporateRepo := newCustomerMergeTestUseCase()
+		customerRepo.On("GetByID", uint(1)).Return(survivor, nil)
+		customerRepo.On("GetByID", uint(4)).Return(duplicate, nil)
+		corporateRepo.On("GetByCustomer", ctx, uint(1)).Return(&dto.CorporateAccountDTO{ID: 1, CustomerID: 1}, nil)
+		corporateRepo.On("GetByCustomer", ctx, uint(4)).Return(&dto.CorporateAccountDTO{ID: 2, CustomerID: 4}, nil)
+
+		_, err := uc.MergeCustomers(ctx, 1, request, "ana@mecanicaxpto.com.br")
+
+		assert.ErrorIs(t, err, ErrMergeCorporateAccounts)
+		repo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails when the duplicate was merged in the meantime", func(t *testing.T) {
+		uc, repo, customerRepo, corporateRepo := newCustomerMergeTestUseCase()
+		customerRepo.On("GetByID", uint(1)).Return(survivor, nil)
+		customerRepo.On("GetByID", uint(4)).Return(duplicate, nil)
+		corporateRepo.On("GetByCustomer", ctx, mock.Anything).Return(nil, nil)
+		repo.On("Merge", ctx, uint(1), duplicate, mock.Anything).Return(customermerge.ErrCustomerGone)
+
+		_, err := uc.MergeCustomers(ctx, 1, request, "ana@mecanicaxpto.com.br")
+
+		assert.ErrorIs(t, err, ErrMergedCustomerGone)
+	})
+
+	t.Run("returns the error of the repository", func(t *testing.T) {
+		uc, repo, customerRepo, corporateRepo := newCustomerMergeTestUseCase()
+		customerRepo.On("GetByID", uint(1)).Return(survivor, nil)
+		customerRepo.On("GetByID", uint(4)).Return(duplicate, nil)
+		corporateRepo.On("GetByCustomer", ctx, mock.Anything).Return(nil, nil)
+		repo.On("Merge", ctx, uint(1), duplicate, mock.Anything).Return(errors.New("db down"))
+
+		_, err := uc.MergeCustomers(ctx, 1, request, "ana@mecanicaxpto.com.br")
+
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestCustomerMergeUseCase_ListMerges(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, _, _ := newCustomerMergeTestUseCase()
+	repo.On("List", ctx, uint(1)).Return([]dto.CustomerMergeDTO{
+		{ID: 12, SurvivorID: 1, MergedCustomerID: 4, MergedFullName: "Maria Sousa", MergedAt: mergeNow},
+	}, nil)
+
+	merges, err := uc.ListMerges(ctx, 1)
+
+	require.NoError(t, err)
+	require.Len(t, merges, 1)
+	assert.Equal(t, uint(4), merges[0].MergedCustomerID)
+	assert.Equal(t, "Maria Sousa", merges[0].MergedFullName)
+}
@@ -48,8 +48,9 @@ func (uc *CustomerUseCase) GetById(id uint) (*entities.Customer, error) {
 	return customerDTO.ToDomain(), nil
 }
 
+// GetByDocument finds the customer with or without the mask in the document
 func (uc *CustomerUseCase) GetByDocument(CpfCnpj string) (*entities.Customer, error) {
-	customerDTO, err := uc.customerRepo.GetByDocument(CpfCnpj)
+	customerDTO, err := uc.customerRepo.GetByDocument(valueobject.CpfCnpj(CpfCnpj).Normalized().String())
 
 	if err != nil {
 		return nil, ErrGeneric
@@ -61,17 +62,29 @@ func (uc *CustomerUseCase) GetByDocument(CpfCnpj string) (*entities.Customer, er
 	return customerDTO.ToDomain(), nil
 }
 
+// CreateCustomer registers the customer with the document stored without its mask; a document
+// already registered is refused
 func (uc *CustomerUseCase) CreateCustomer(customer *entities.Customer) error {
-	if e := customer.CpfCnpj.IsValid(); e != nil {
+	document, e := valueobject.NewCpfCnpj(customer.CpfCnpj.String())
+	if e != nil {
 		return ErrInvalidDocumentFormat
 	}
+	existing, err := uc.customerRepo.GetByDocument(document.String())
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != 0 {
+		return ErrCustomerAlreadyExists
+	}
+	customer.CpfCnpj = document
+
 	userDTO := dto.UserDTO{
 		Email:    customer.Email,
-		UserType: valueobject.ParseUserType("admin"),
+		UserType: valueobject.Customer,
 	}
 	customerDTO := dto.CustomerDTO{
 		User:        &userDTO,
-		CpfCnpj:     customer.CpfCnpj.String(),
+		CpfCnpj:     document.String(),
 		PhoneNumber: customer.PhoneNumber,
 		FullName:    customer.FullName,
 	}
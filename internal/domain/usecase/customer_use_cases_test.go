@@ -77,3 +77,59 @@ func TestListCustomer_Error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, customers)
 }
+
+func TestCreateCustomer_StoresDocumentWithoutMask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockICustomerRepository(ctrl)
+	uc := use_cases.NewCustomerUseCase(mockRepo, nil)
+
+	customer := &entities.Customer{FullName: "Maria Souza", CpfCnpj: "529.982.247-25", PhoneNumber: "11987654321", Email: "maria@mail.com"}
+
+	mockRepo.EXPECT().GetByDocument("52998224725").Return(nil, nil)
+	mockRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(customerDTO *dto.CustomerDTO) error {
+		assert.Equal(t, "52998224725", customerDTO.CpfCnpj)
+		assert.Equal(t, "maria@mail.com", customerDTO.User.Email)
+		return nil
+	})
+
+	err := uc.CreateCustomer(customer)
+	assert.NoError(t, err)
+}
+
+func TestCreateCustomer_DocumentAlreadyRegistered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockICustomerRepository(ctrl)
+	uc := use_cases.NewCustomerUseCase(mockRepo, nil)
+
+	customer := &entities.Customer{FullName: "Oficina Norte", CpfCnpj: "11.222.333/0001-81", PhoneNumber: "1133334444"}
+
+	mockRepo.EXPECT().GetByDocument("11222333000181").Return(&dto.CustomerDTO{ID: 7, CpfCnpj: "11222333000181"}, nil)
+
+	err := uc.CreateCustomer(customer)
+	assert.ErrorIs(t, err, use_cases.ErrCustomerAlreadyExists)
+}
+
+func TestCreateCustomer_InvalidDocument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockICustomerRepository(ctrl)
+	uc := use_cases.NewCustomerUseCase(mockRepo, nil)
+
+	err := uc.CreateCustomer(&entities.Customer{FullName: "Maria Souza", CpfCnpj: "529.982.247-00"})
+	assert.ErrorIs(t, err, use_cases.ErrInvalidDocumentFormat)
+}
+
+func TestGetByDocument_WithMask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockICustomerRepository(ctrl)
+	uc := use_cases.NewCustomerUseCase(mockRepo, nil)
+
+	mockRepo.EXPECT().GetByDocument("52998224725").Return(&dto.CustomerDTO{ID: 3, CpfCnpj: "52998224725"}, nil)
+
+	customer, err := uc.GetByDocument("529.982.247-25")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), customer.ID)
+}
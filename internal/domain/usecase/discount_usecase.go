@@ -0,0 +1,351 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	customerRepo "mecanica_xpto/internal/domain/repository/customers"
+	"mecanica_xpto/internal/domain/repository/discount"
+	"mecanica_xpto/internal/domain/repository/parts_supply"
+	"mecanica_xpto/internal/domain/repository/service"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/internal/domain/repository/users"
+)
+
+var (
+	ErrCouponNotFound              = errors.New("coupon not found")
+	ErrCouponAlreadyExists         = errors.New("coupon already exists")
+	ErrCouponNotValid              = errors.New("coupon is not valid")
+	ErrCouponUsageLimitReached     = errors.New("coupon usage limit reached")
+	ErrInvalidDiscount             = errors.New("invalid discount")
+	ErrInvalidPriceAgreement       = errors.New("price agreement must reference exactly one service or parts supply")
+	ErrDiscountApprovalPending     = errors.New("discount is pending admin approval")
+	ErrDiscountApprovalNotRequired = errors.New("service order has no discount pending approval")
+	ErrDiscountApprovalForbidden   = errors.New("only admins and managers can approve discounts")
+)
+
+type IDiscountUseCase interface {
+	CreateCoupon(ctx context.Context, coupon *entities.Coupon) (entities.Coupon, error)
+	UpdateCoupon(ctx context.Context, coupon *entities.Coupon) error
+	ListCoupons(ctx context.Context) ([]entities.Coupon, error)
+	CreatePriceAgreement(ctx context.Context, agreement *entities.PriceAgreement) (entities.PriceAgreement, error)
+	ListPriceAgreements(ctx context.Context, customerID uint) ([]entities.PriceAgreement, error)
+	DeletePriceAgreement(ctx context.Context, id uint) error
+	PriceEstimate(ctx context.Context, customerID uint, services []entities.Service, partsSupplies []entities.PartsSupply, orderDiscount *entities.Discount, couponCode string) (*entities.EstimateBreakdown, error)
+	ApproveDiscount(ctx context.Context, serviceOrderID uint, approverEmail string) (*entities.ServiceOrder, error)
+}
+
+type DiscountUseCase struct {
+	repo              discount.IDiscountRepository
+	serviceOrderRepo  serviceorder.IServiceOrderRepository
+	userRepo          users.IUserRepository
+	customerRepo      customerRepo.ICustomerRepository
+	serviceRepo       service.IServiceRepo
+	partsSupplyRepo   parts_supply.IPartsSupplyRepo
+	approvalThreshold float64
+}
+
+var _ IDiscountUseCase = (*DiscountUseCase)(nil)
+
+func NewDiscountUseCase(repo discount.IDiscountRepository, serviceOrderRepo serviceorder.IServiceOrderRepository, userRepo users.IUserRepository, customerRepo customerRepo.ICustomerRepository, serviceRepo service.IServiceRepo, partsSupplyRepo parts_supply.IPartsSupplyRepo, approvalThreshold float64) *DiscountUseCase {
+	return &DiscountUseCase{
+		repo:              repo,
+		serviceOrderRepo:  serviceOrderRepo,
+		userRepo:          userRepo,
+		customerRepo:      customerRepo,
+		serviceRepo:       serviceRepo,
+		partsSupplyRepo:   partsSupplyRepo,
+		approvalThreshold: approvalThreshold,
+	}
+}
+
+func (u *DiscountUseCase) CreateCoupon(ctx context.Context, coupon *entities.Coupon) (entities.Coupon, error) {
+	coupon.Code = strings.ToUpper(strings.TrimSpace(coupon.Code))
+	if coupon.Code == "" {
+		return entities.Coupon{}, ErrCouponNotValid
+	}
+	if err := validateDiscount(coupon.DiscountType, coupon.Value); err != nil {
+		return entities.Coupon{}, err
+	}
+
+	existing, err := u.repo.GetCouponByCode(ctx, coupon.Code)
+	if err != nil {
+		return entities.Coupon{}, err
+	}
+	if existing != nil {
+		return entities.Coupon{}, ErrCouponAlreadyExists
+	}
+
+	return u.repo.CreateCoupon(ctx, coupon)
+}
+
+func (u *DiscountUseCase) UpdateCoupon(ctx context.Context, coupon *entities.Coupon) error {
+	coupon.Code = strings.ToUpper(strings.TrimSpace(coupon.Code))
+	existing, err := u.repo.GetCouponByCode(ctx, coupon.Code)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrCouponNotFound
+	}
+	coupon.ID = existing.ID
+	return u.repo.UpdateCoupon(ctx, coupon)
+}
+
+func (u *DiscountUseCase) ListCoupons(ctx context.Context) ([]entities.Coupon, error) {
+	dtos, err := u.repo.ListCoupons(ctx)
+	if err != nil {
+		return nil, err
+	}
+	coupons := make([]entities.Coupon, 0, len(dtos))
+	for _, c := range dtos {
+		coupons = append(coupons, c.ToDomain())
+	}
+	return coupons, nil
+}
+
+func (u *DiscountUseCase) CreatePriceAgreement(ctx context.Context, agreement *entities.PriceAgreement) (entities.PriceAgreement, error) {
+	if (agreement.ServiceID == nil) == (agreement.PartsSupplyID == nil) || agreement.Price <= 0 {
+		return entities.PriceAgreement{}, ErrInvalidPriceAgreement
+	}
+
+	customer, err := u.customerRepo.GetByID(agreement.CustomerID)
+	if err != nil {
+		return entities.PriceAgreement{}, err
+	}
+	if customer == nil {
+		return entities.PriceAgreement{}, ErrCustomerNotFound
+	}
+
+	if agreement.ServiceID != nil {
+		if _, err := getSeviceById(ctx, entities.Service{ID: *agreement.ServiceID}, u.serviceRepo); err != nil {
+			return entities.PriceAgreement{}, err
+		}
+	}
+	if agreement.PartsSupplyID != nil {
+		if _, err := getPartsSupplyByID(ctx, *agreement.PartsSupplyID, u.partsSupplyRepo); err != nil {
+			return entities.PriceAgreement{}, err
+		}
+	}
+
+	return u.repo.CreatePriceAgreement(ctx, agreement)
+}
+
+func (u *DiscountUseCase) ListPriceAgreements(ctx context.Context, customerID uint) ([]entities.PriceAgreement, error) {
+	dtos, err := u.repo.ListPriceAgreementsByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	agreements := make([]entities.PriceAgreement, 0, len(dtos))
+	for _, a := range dtos {
+		agreements = append(agreements, a.ToDomain())
+	}
+	return agreements, nil
+}
+
+func (u *DiscountUseCase) DeletePriceAgreement(ctx context.Context, id uint) error {
+	if id == 0 {
+		return ErrInvalidID
+	}
+	return u.repo.DeletePriceAgreement(ctx, id)
+}
+
+// PriceEstimate prices the services and parts supplies of an estimate.
+// Negotiated prices of the customer replace the catalog price, then line discounts,
+// the order discount and finally the coupon are applied, in that order.
+// Manual discounts (line and order) above the approval threshold require an admin sign-off.
+func (u *DiscountUseCase) PriceEstimate(ctx context.Context, customerID uint, services []entities.Service, partsSupplies []entities.PartsSupply, orderDiscount *entities.Discount, couponCode string) (*entities.EstimateBreakdown, error) {
+	now := time.Now()
+	breakdown := &entities.EstimateBreakdown{}
+
+	agreements, err := u.repo.ListPriceAgreementsByCustomer(ctx, customerID)
+	if err != nil {
+		log.Error().Msgf("error listing price agreements for customer %d: %v", customerID, err)
+		return nil, err
+	}
+	servicePrices := make(map[uint]float64)
+	partsSupplyPrices := make(map[uint]float64)
+	for _, a := range agreements {
+		agreement := a.ToDomain()
+		if !isWithinPeriod(now, agreement.ValidFrom, agreement.ValidUntil) {
+			continue
+		}
+		if agreement.ServiceID != nil {
+			servicePrices[*agreement.ServiceID] = agreement.Price
+		}
+		if agreement.PartsSupplyID != nil {
+			partsSupplyPrices[*agreement.PartsSupplyID] = agreement.Price
+		}
+	}
+
+	for _, s := range services {
+		registered, err := getSeviceById(ctx, s, u.serviceRepo)
+		if err != nil {
+			return nil, err
+		}
+		unitPrice := registered.Price
+		if price, ok := servicePrices[s.ID]; ok {
+			unitPrice = price
+		}
+		line, err := priceLine(unitPrice, 1, s.Discount)
+		if err != nil {
+			return nil, err
+		}
+		line.ServiceID = s.ID
+		line.Category = registered.Category
+		breakdown.Lines = append(breakdown.Lines, line)
+	}
+
+	for _, ps := range partsSupplies {
+		registered, err := getPartsSupplyByID(ctx, ps.ID, u.partsSupplyRepo)
+		if err != nil {
+			return nil, err
+		}
+		unitPrice := registered.Price
+		if price, ok := partsSupplyPrices[ps.ID]; ok {
+			unitPrice = price
+		}
+		quantity := ps.QuantityReserve
+		if ps.QuantityTotal > 0 {
+			quantity = ps.QuantityTotal
+		}
+		line, err := priceLine(unitPrice, quantity, ps.Discount)
+		if err != nil {
+			return nil, err
+		}
+		line.PartsSupplyID = ps.ID
+		line.Category = registered.Category
+		breakdown.Lines = append(breakdown.Lines, line)
+	}
+
+	for _, line := range breakdown.Lines {
+		breakdown.Gross += line.Gross
+		breakdown.LineDiscounts += line.Discount
+	}
+
+	subtotal := breakdown.Gross - breakdown.LineDiscounts
+	if orderDiscount != nil {
+		if err := validateDiscount(orderDiscount.Type, orderDiscount.Value); err != nil {
+			return nil, err
+		}
+		breakdown.OrderDiscount = orderDiscount.Type.Amount(subtotal, orderDiscount.Value)
+		subtotal -= breakdown.OrderDiscount
+	}
+
+	if couponCode != "" {
+		coupon, err := u.getValidCoupon(ctx, couponCode, now)
+		if err != nil {
+			return nil, err
+		}
+		breakdown.CouponID = &coupon.ID
+		breakdown.CouponDiscount = coupon.DiscountType.Amount(subtotal, coupon.Value)
+		subtotal -= breakdown.CouponDiscount
+	}
+
+	breakdown.DiscountTotal = roundCurrency(breakdown.LineDiscounts + breakdown.OrderDiscount + breakdown.CouponDiscount)
+	breakdown.Gross = roundCurrency(breakdown.Gross)
+	breakdown.Net = roundCurrency(math.Max(subtotal, 0))
+
+	manualDiscounts := breakdown.LineDiscounts + breakdown.OrderDiscount
+	if breakdown.Gross > 0 && manualDiscounts/breakdown.Gross*100 > u.approvalThreshold {
+		breakdown.ApprovalRequired = true
+	}
+
+	return breakdown, nil
+}
+
+// ApproveDiscount records the sign-off of discounts above the approval threshold by a user whose
+// role can approve discounts
+func (u *DiscountUseCase) ApproveDiscount(ctx context.Context, serviceOrderID uint, approverEmail string) (*entities.ServiceOrder, error) {
+	approver, err := u.userRepo.GetByEmail(approverEmail)
+	if err != nil || approver == nil || !approver.UserType.HasPermission(valueobject.PermissionApproveDiscounts) {
+		return nil, ErrDiscountApprovalForbidden
+	}
+
+	serviceOrderDto, err := u.serviceOrderRepo.GetByID(serviceOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if serviceOrderDto == nil {
+		return nil, ErrServiceOrderNotFound
+	}
+	if !serviceOrderDto.DiscountPending {
+		return nil, ErrDiscountApprovalNotRequired
+	}
+
+	if err := u.serviceOrderRepo.ApproveDiscount(serviceOrderID, approver.Email, time.Now()); err != nil {
+		log.Error().Msgf("error approving discount of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+
+	updated, err := u.serviceOrderRepo.GetByID(serviceOrderID)
+	if err != nil || updated == nil {
+		return nil, err
+	}
+	return updated.ToDomain(), nil
+}
+
+func (u *DiscountUseCase) getValidCoupon(ctx context.Context, code string, now time.Time) (entities.Coupon, error) {
+	couponDTO, err := u.repo.GetCouponByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return entities.Coupon{}, err
+	}
+	if couponDTO == nil {
+		return entities.Coupon{}, ErrCouponNotFound
+	}
+	coupon := couponDTO.ToDomain()
+	if !coupon.Active || !isWithinPeriod(now, coupon.ValidFrom, coupon.ValidUntil) {
+		return entities.Coupon{}, ErrCouponNotValid
+	}
+	if coupon.MaxUses > 0 && coupon.UsedCount >= coupon.MaxUses {
+		return entities.Coupon{}, ErrCouponUsageLimitReached
+	}
+	return coupon, nil
+}
+
+func priceLine(unitPrice float64, quantity int, discount *entities.Discount) (entities.EstimateLine, error) {
+	line := entities.EstimateLine{
+		Quantity:  quantity,
+		UnitPrice: unitPrice,
+		Gross:     unitPrice * float64(quantity),
+	}
+	if discount != nil {
+		if err := validateDiscount(discount.Type, discount.Value); err != nil {
+			return entities.EstimateLine{}, err
+		}
+		line.Discount = discount.Type.Amount(line.Gross, discount.Value)
+	}
+	line.Net = line.Gross - line.Discount
+	return line, nil
+}
+
+func validateDiscount(discountType valueobject.DiscountType, value float64) error {
+	if !discountType.IsValid() || value <= 0 {
+		return ErrInvalidDiscount
+	}
+	if discountType == valueobject.DiscountPercentage && value > 100 {
+		return ErrInvalidDiscount
+	}
+	return nil
+}
+
+func isWithinPeriod(now time.Time, from, until *time.Time) bool {
+	if from != nil && now.Before(*from) {
+		return false
+	}
+	if until != nil && now.After(*until) {
+		return false
+	}
+	return true
+}
+
+func roundCurrency(v float64) float64 {
+	return math.Round(v*100) / 100
+}
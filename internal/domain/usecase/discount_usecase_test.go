@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+type discountTestDeps struct {
+	repo             *mocks.MockDiscountRepository
+	serviceOrderRepo *mocks.MockServiceOrderRepository
+	userRepo         *mocks.MockUserRepository
+	customerRepo     *MockCustomerRepository
+	serviceRepo      *MockServiceRepository
+	partsSupplyRepo  *MockPartsSupplyRepository
+}
+
+func newDiscountUseCaseForTest(threshold float64) (*DiscountUseCase, discountTestDeps) {
+	deps := discountTestDeps{
+		repo:             new(mocks.MockDiscountRepository),
+		serviceOrderRepo: new(mocks.MockServiceOrderRepository),
+		userRepo:         new(mocks.MockUserRepository),
+		customerRepo:     new(MockCustomerRepository),
+		serviceRepo:      new(MockServiceRepository),
+		partsSupplyRepo:  new(MockPartsSupplyRepository),
+	}
+	u := NewDiscountUseCase(deps.repo, deps.serviceOrderRepo, deps.userRepo, deps.customerRepo, deps.serviceRepo, deps.partsSupplyRepo, threshold)
+	return u, deps
+}
+
+func TestDiscountUseCase_PriceEstimate(t *testing.T) {
+	ctx := context.Background()
+	serviceID := uint(1)
+	past := time.Now().Add(-24 * time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name             string
+		services         []entities.Service
+		partsSupplies    []entities.PartsSupply
+		orderDiscount    *entities.Discount
+		couponCode       string
+		agreements       []dto.PriceAgreementDTO
+		coupon           *dto.CouponDTO
+		expectedNet      float64
+		expectedDiscount float64
+		expectedApproval bool
+		expectedError    error
+	}{
+		{
+			name:          "Success - catalog prices without discounts",
+			services:      []entities.Service{{ID: 1}},
+			partsSupplies: []entities.PartsSupply{{ID: 2, QuantityTotal: 2}},
+			expectedNet:   200,
+		},
+		{
+			name:          "Success - price agreement replaces catalog price",
+			services:      []entities.Service{{ID: 1}},
+			partsSupplies: []entities.PartsSupply{{ID: 2, QuantityTotal: 2}},
+			agreements: []dto.PriceAgreementDTO{
+				{ID: 1, CustomerID: 1, ServiceID: &serviceID, Price: 80, ValidFrom: &past, ValidUntil: &future},
+			},
+			expectedNet: 180,
+		},
+		{
+			name:          "Success - expired price agreement is ignored",
+			services:      []entities.Service{{ID: 1}},
+			partsSupplies: []entities.PartsSupply{{ID: 2, QuantityTotal: 2}},
+			agreements: []dto.PriceAgreementDTO{
+				{ID: 1, CustomerID: 1, ServiceID: &serviceID, Price: 80, ValidUntil: &past},
+			},
+			expectedNet: 200,
+		},
+		{
+			name:             "Success - line and order discounts within threshold",
+			services:         []entities.Service{{ID: 1, Discount: &entities.Discount{Type: valueobject.DiscountFixed, Value: 10}}},
+			partsSupplies:    []entities.PartsSupply{{ID: 2, QuantityTotal: 2}},
+			orderDiscount:    &entities.Discount{Type: valueobject.DiscountPercentage, Value: 5},
+			expectedNet:      180.5,
+			expectedDiscount: 19.5,
+		},
+		{
+			name:             "Success - manual discount above threshold requires approval",
+			services:         []entities.Service{{ID: 1}},
+			partsSupplies:    []entities.PartsSupply{{ID: 2, QuantityTotal: 2}},
+			orderDiscount:    &entities.Discount{Type: valueobject.DiscountPercentage, Value: 15},
+			expectedNet:      170,
+			expectedDiscount: 30,
+			expectedApproval: true,
+		},
+		{
+			name:             "Success - coupon discount does not require approval",
+			services:         []entities.Service{{ID: 1}},
+			partsSupplies:    []entities.PartsSupply{{ID: 2, QuantityTotal: 2}},
+			couponCode:       "promo20",
+			coupon:           &dto.CouponDTO{ID: 7, Code: "PROMO20", DiscountType: "PERCENTAGE", Value: 20, Active: true},
+			expectedNet:      160,
+			expectedDiscount: 40,
+		},
+		{
+			name:          "Error - coupon not found",
+			services:      []entities.Service{{ID: 1}},
+			couponCode:    "UNKNOWN",
+			expectedError: ErrCouponNotFound,
+		},
+		{
+			name:          "Error - inactive coupon",
+			services:      []entities.Service{{ID: 1}},
+			couponCode:    "PROMO20",
+			coupon:        &dto.CouponDTO{ID: 7, Code: "PROMO20", DiscountType: "PERCENTAGE", Value: 20, Active: false},
+			expectedError: ErrCouponNotValid,
+		},
+		{
+			name:          "Error - coupon usage limit reached",
+			services:      []entities.Service{{ID: 1}},
+			couponCode:    "PROMO20",
+			coupon:        &dto.CouponDTO{ID: 7, Code: "PROMO20", DiscountType: "PERCENTAGE", Value: 20, Active: true, MaxUses: 3, UsedCount: 3},
+			expectedError: ErrCouponUsageLimitReached,
+		},
+		{
+			name:          "Error - percentage discount above 100",
+			services:      []entities.Service{{ID: 1}},
+			orderDiscount: &entities.Discount{Type: valueobject.DiscountPercentage, Value: 120},
+			expectedError: ErrInvalidDiscount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, deps := newDiscountUseCaseForTest(10)
+			deps.repo.On("ListPriceAgreementsByCustomer", ctx, uint(1)).Return(tt.agreements, nil)
+			deps.repo.On("GetCouponByCode", ctx, "PROMO20").Return(tt.coupon, nil)
+			deps.repo.On("GetCouponByCode", ctx, "UNKNOWN").Return(nil, nil)
+			deps.serviceRepo.On("GetByID", ctx, uint(1)).Return(entities.Service{ID: 1, Price: 100}, nil)
+			deps.partsSupplyRepo.On("GetByID", ctx, uint(2)).Return(entities.PartsSupply{ID: 2, Price: 50}, nil)
+
+			result, err := u.PriceEstimate(ctx, 1, tt.services, tt.partsSupplies, tt.orderDiscount, tt.couponCode)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedNet, result.Net)
+			assert.Equal(t, tt.expectedDiscount, result.DiscountTotal)
+			assert.Equal(t, tt.expectedApproval, result.ApprovalRequired)
+		})
+	}
+}
+
+func TestDiscountUseCase_CreateCoupon(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - code is normalized", func(t *testing.T) {
+		u, deps := newDiscountUseCaseForTest(10)
+		deps.repo.On("GetCouponByCode", ctx, "WELCOME").Return(nil, nil)
+		deps.repo.On("CreateCoupon", ctx, mock.MatchedBy(func(c *entities.Coupon) bool {
+			return c.Code == "WELCOME"
+		})).Return(entities.Coupon{ID: 1, Code: "WELCOME"}, nil)
+
+		coupon, err := u.CreateCoupon(ctx, &entities.Coupon{Code: " welcome ", DiscountType: valueobject.DiscountFixed, Value: 15})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), coupon.ID)
+		deps.repo.AssertExpectations(t)
+	})
+
+	t.Run("Error - coupon already exists", func(t *testing.T) {
+		u, deps := newDiscountUseCaseForTest(10)
+		deps.repo.On("GetCouponByCode", ctx, "WELCOME").Return(&dto.CouponDTO{ID: 1}, nil)
+
+		_, err := u.CreateCoupon(ctx, &entities.Coupon{Code: "WELCOME", DiscountType: valueobject.DiscountFixed, Value: 15})
+		assert.ErrorIs(t, err, ErrCouponAlreadyExists)
+	})
+
+	t.Run("Error - invalid discount type", func(t *testing.T) {
+		u, _ := newDiscountUseCaseForTest(10)
+
+		_, err := u.CreateCoupon(ctx, &entities.Coupon{Code: "WELCOME", DiscountType: "BOGUS", Value: 15})
+		assert.ErrorIs(t, err, ErrInvalidDiscount)
+	})
+}
+
+func TestDiscountUseCase_CreatePriceAgreement(t *testing.T) {
+	ctx := context.Background()
+	serviceID := uint(1)
+	partsSupplyID := uint(2)
+
+	t.Run("Success", func(t *testing.T) {
+		u, deps := newDiscountUseCaseForTest(10)
+		agreement := &entities.PriceAgreement{CustomerID: 1, ServiceID: &serviceID, Price: 80}
+		deps.customerRepo.On("GetByID", uint(1)).Return(&dto.CustomerDTO{ID: 1}, nil)
+		deps.serviceRepo.On("GetByID", ctx, uint(1)).Return(entities.Service{ID: 1, Price: 100}, nil)
+		deps.repo.On("CreatePriceAgreement", ctx, agreement).Return(entities.PriceAgreement{ID: 1, CustomerID: 1, ServiceID: &serviceID, Price: 80}, nil)
+
+		result, err := u.CreatePriceAgreement(ctx, agreement)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), result.ID)
+	})
+
+	t.Run("Error - both service and parts supply", func(t *testing.T) {
+		u, _ := newDiscountUseCaseForTest(10)
+		_, err := u.CreatePriceAgreement(ctx, &entities.PriceAgreement{CustomerID: 1, ServiceID: &serviceID, PartsSupplyID: &partsSupplyID, Price: 80})
+		assert.ErrorIs(t, err, ErrInvalidPriceAgreement)
+	})
+
+	t.Run("Error - customer not found", func(t *testing.T) {
+		u, deps := newDiscountUseCaseForTest(10)
+		deps.customerRepo.On("GetByID", uint(9)).Return(nil, nil)
+		_, err := u.CreatePriceAgreement(ctx, &entities.PriceAgreement{CustomerID: 9, ServiceID: &serviceID, Price: 80})
+		assert.ErrorIs(t, err, ErrCustomerNotFound)
+	})
+}
+
+func TestDiscountUseCase_ApproveDiscount(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		u, deps := newDiscountUseCaseForTest(10)
+		deps.userRepo.On("GetByEmail", "admin@xpto.com").Return(&dto.UserDTO{Email: "admin@xpto.com", UserType: valueobject.Admin}, nil)
+		deps.serviceOrderRepo.On("GetByID", uint(1)).Return(&dto.ServiceOrderDTO{ID: 1, DiscountPending: true}, nil)
+		deps.serviceOrderRepo.On("ApproveDiscount", uint(1), "admin@xpto.com", mock.AnythingOfType("time.Time")).Return(nil)
+
+		result, err := u.ApproveDiscount(ctx, 1, "admin@xpto.com")
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+		deps.serviceOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error - approver is not admin", func(t *testing.T) {
+		u, deps := newDiscountUseCaseForTest(10)
+		deps.userRepo.On("GetByEmail", "customer@xpto.com").Return(&dto.UserDTO{Email: "customer@xpto.com", UserType: valueobject.Customer}, nil)
+
+		_, err := u.ApproveDiscount(ctx, 1, "customer@xpto.com")
+		assert.ErrorIs(t, err, ErrDiscountApprovalForbidden)
+	})
+
+	t.Run("Error - approver role cannot approve discounts", func(t *testing.T) {
+		u, deps := newDiscountUseCaseForTest(10)
+		deps.userRepo.On("GetByEmail", "recepcao@xpto.com").Return(&dto.UserDTO{Email: "recepcao@xpto.com", UserType: valueobject.Receptionist}, nil)
+
+		_, err := u.ApproveDiscount(ctx, 1, "recepcao@xpto.com")
+		assert.ErrorIs(t, err, ErrDiscountApprovalForbidden)
+	})
+
+	t.Run("Error - unknown approver", func(t *testing.T) {
+		u, deps := newDiscountUseCaseForTest(10)
+		deps.userRepo.On("GetByEmail", "").Return(nil, errors.New("record not found"))
+
+		_, err := u.ApproveDiscount(ctx, 1, "")
+		assert.ErrorIs(t, err, ErrDiscountApprovalForbidden)
+	})
+
+	t.Run("Error - no discount pending", func(t *testing.T) {
+		u, deps := newDiscountUseCaseForTest(10)
+		deps.userRepo.On("GetByEmail", "admin@xpto.com").Return(&dto.UserDTO{Email: "admin@xpto.com", UserType: valueobject.Admin}, nil)
+		deps.serviceOrderRepo.On("GetByID", uint(1)).Return(&dto.ServiceOrderDTO{ID: 1}, nil)
+
+		_, err := u.ApproveDiscount(ctx, 1, "admin@xpto.com")
+		assert.ErrorIs(t, err, ErrDiscountApprovalNotRequired)
+	})
+}
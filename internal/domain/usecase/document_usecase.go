@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/pkg/utils"
+)
+
+var (
+	ErrInvalidDocumentType  = errors.New("invalid document type")
+	ErrEstimateNotAvailable = errors.New("estimate is only available once the service order is awaiting approval")
+	ErrReceiptNotAvailable  = errors.New("receipt is only available once the service order is paid")
+)
+
+const dateLayout = "02/01/2006 15:04"
+
+type IDocumentUseCase interface {
+	GenerateDocument(ctx context.Context, serviceOrderID uint, documentType valueobject.DocumentType) (*entities.RenderedDocument, error)
+}
+
+type DocumentUseCase struct {
+	serviceOrderRepo serviceorder.IServiceOrderRepository
+	renderer         gateway.DocumentRenderer
+	now              func() time.Time
+}
+
+var _ IDocumentUseCase = (*DocumentUseCase)(nil)
+
+func NewDocumentUseCase(serviceOrderRepo serviceorder.IServiceOrderRepository, renderer gateway.DocumentRenderer) *DocumentUseCase {
+	return &DocumentUseCase{
+		serviceOrderRepo: serviceOrderRepo,
+		renderer:         renderer,
+		now:              time.Now,
+	}
+}
+
+// GenerateDocument builds and renders a printable document of a service order:
+// the estimate to be approved by the customer, the service order sheet or the payment receipt
+func (u *DocumentUseCase) GenerateDocument(ctx context.Context, serviceOrderID uint, documentType valueobject.DocumentType) (*entities.RenderedDocument, error) {
+	if !documentType.IsValid() {
+		return nil, ErrInvalidDocumentType
+	}
+
+	serviceOrderDto, err := u.serviceOrderRepo.GetByIDWithItems(serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error finding service order with id %v: %v", serviceOrderID, err)
+		return nil, err
+	}
+	if serviceOrderDto == nil {
+		return nil, ErrServiceOrderNotFound
+	}
+
+	var document entities.Document
+	switch documentType {
+	case valueobject.DocumentEstimate:
+		document, err = u.estimate(serviceOrderDto)
+	case valueobject.DocumentServiceOrder:
+		document, err = u.serviceOrderSheet(serviceOrderDto)
+	case valueobject.DocumentReceipt:
+		document, err = u.receipt(serviceOrderDto)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := u.renderer.Render(document)
+	if err != nil {
+		log.Error().Msgf("Error rendering %s of service order %d: %v", documentType, serviceOrderID, err)
+		return nil, err
+	}
+
+	return &entities.RenderedDocument{
+		FileName:    fmt.Sprintf("%s-%d.%s", documentType, serviceOrderID, u.renderer.Extension()),
+		ContentType: u.renderer.ContentType(),
+		Content:     content,
+	}, nil
+}
+
+func (u *DocumentUseCase) estimate(serviceOrderDto *dto.ServiceOrderDTO) (entities.Document, error) {
+	switch serviceOrderDto.ServiceOrderStatus.ToDomain() {
+	case valueobject.StatusRecebida, valueobject.StatusEmDiagnostico, valueobject.StatusCancelada:
+		return entities.Document{}, ErrEstimateNotAvailable
+	}
+
+	lines, err := u.documentLines(serviceOrderDto)
+	if err != nil {
+		return entities.Document{}, err
+	}
+
+	notes := []string{"Valores com impostos inclusos. Orçamento sujeito à aprovação do cliente."}
+	if serviceOrderDto.DiscountPending {
+		notes = append(notes, "Descontos aguardando aprovação da gerência.")
+	}
+
+	return entities.Document{
+		Type:     valueobject.DocumentEstimate,
+		Title:    "Orçamento",
+		Number:   fmt.Sprintf("OS #%d", serviceOrderDto.ID),
+		IssuedAt: u.now(),
+		Sections: []entities.DocumentSection{u.customerSection(serviceOrderDto), u.vehicleSection(serviceOrderDto)},
+		Lines:    lines,
+		Totals:   u.estimateTotals(serviceOrderDto),
+		Notes:    notes,
+	}, nil
+}
+
+func (u *DocumentUseCase) serviceOrderSheet(serviceOrderDto *dto.ServiceOrderDTO) (entities.Document, error) {
+	lines, err := u.documentLines(serviceOrderDto)
+	if err != nil {
+		return entities.Document{}, err
+	}
+
+	orderSection := entities.DocumentSection{
+		Title: "Ordem de serviço",
+		Fields: []entities.DocumentField{
+			{Label: "Situação", Value: serviceOrderDto.ServiceOrderStatus.ToDomain().String()},
+		},
+	}
+	dates := []struct {
+		label string
+		date  *time.Time
+	}{
+		{"Abertura", serviceOrderDto.CreatedAt},
+		{"Início da execução", serviceOrderDto.StartedExecutionDate},
+		{"Fim da execução", serviceOrderDto.FinalExecutionDate},
+	}
+	for _, d := range dates {
+		if d.date != nil {
+			orderSection.Fields = append(orderSection.Fields, entities.DocumentField{Label: d.label, Value: d.date.Format(dateLayout)})
+		}
+	}
+
+	return entities.Document{
+		Type:     valueobject.DocumentServiceOrder,
+		Title:    "Ordem de Serviço",
+		Number:   fmt.Sprintf("OS #%d", serviceOrderDto.ID),
+		IssuedAt: u.now(),
+		Sections: []entities.DocumentSection{orderSection, u.customerSection(serviceOrderDto), u.vehicleSection(serviceOrderDto)},
+		Lines:    lines,
+		Totals:   u.estimateTotals(serviceOrderDto),
+		Notes:    []string{"Assinatura do cliente: ____________________________________________"},
+	}, nil
+}
+
+func (u *DocumentUseCase) receipt(serviceOrderDto *dto.ServiceOrderDTO) (entities.Document, error) {
+	if serviceOrderDto.Payment == nil {
+		return entities.Document{}, ErrReceiptNotAvailable
+	}
+	payment := serviceOrderDto.Payment
+	customer := serviceOrderDto.Customer.ToDomain()
+
+	return entities.Document{
+		Type:     valueobject.DocumentReceipt,
+		Title:    "Recibo de Pagamento",
+		Number:   fmt.Sprintf("Recibo #%d", payment.ID),
+		IssuedAt: u.now(),
+		Sections: []entities.DocumentSection{
+			u.customerSection(serviceOrderDto),
+			u.vehicleSection(serviceOrderDto),
+			{
+				Title: "Pagamento",
+				Fields: []entities.DocumentField{
+					{Label: "Ordem de serviço", Value: fmt.Sprintf("OS #%d", serviceOrderDto.ID)},
+					{Label: "Data do pagamento", Value: payment.PaymentDate.Format(dateLayout)},
+				},
+			},
+		},
+		Totals: []entities.DocumentField{
+			{Label: "ISS", Value: utils.FormatBRL(payment.ISSAmount)},
+			{Label: "ICMS", Value: utils.FormatBRL(payment.ICMSAmount)},
+			{Label: "Valor pago", Value: utils.FormatBRL(payment.Amount)},
+		},
+		Notes: []string{fmt.Sprintf("Recebemos de %s a importância de %s referente à OS #%d.",
+			customer.FullName, utils.FormatBRL(payment.Amount), serviceOrderDto.ID)},
+	}, nil
+}
+
+func (u *DocumentUseCase) customerSection(serviceOrderDto *dto.ServiceOrderDTO) entities.DocumentSection {
+	customer := serviceOrderDto.Customer.ToDomain()
+	return entities.DocumentSection{
+		Title: "Cliente",
+		Fields: []entities.DocumentField{
+			{Label: "Nome", Value: customer.FullName},
+			{Label: "CPF/CNPJ", Value: customer.CpfCnpj.Mask()},
+			{Label: "Telefone", Value: customer.PhoneNumber},
+		},
+	}
+}
+
+func (u *DocumentUseCase) vehicleSection(serviceOrderDto *dto.ServiceOrderDTO) entities.DocumentSection {
+	vehicle := serviceOrderDto.Vehicle
+	return entities.DocumentSection{
+		Title: "Veículo",
+		Fields: []entities.DocumentField{
+			{Label: "Placa", Value: vehicle.Plate},
+			{Label: "Marca/Modelo", Value: fmt.Sprintf("%s %s", vehicle.Brand, vehicle.Model)},
+			{Label: "Ano", Value: vehicle.Year},
+		},
+	}
+}
+
+// documentLines lists the services and parts supplies of the order at their catalog prices;
+// discounts are shown in the totals
+func (u *DocumentUseCase) documentLines(serviceOrderDto *dto.ServiceOrderDTO) ([]entities.DocumentLine, error) {
+	lines := make([]entities.DocumentLine, 0, len(serviceOrderDto.Services)+len(serviceOrderDto.PartsSupplies))
+	for _, s := range serviceOrderDto.Services {
+		lines = append(lines, entities.DocumentLine{
+			Description: s.Name,
+			Quantity:    1,
+			UnitPrice:   utils.FormatBRL(s.Price),
+			Total:       utils.FormatBRL(s.Price),
+		})
+	}
+	for _, ps := range serviceOrderDto.PartsSupplies {
+		relation, err := u.serviceOrderRepo.GetPartsSupplyServiceOrder(ps.ID, serviceOrderDto.ID)
+		if err != nil {
+			return nil, err
+		}
+		quantity := 1
+		if relation != nil && relation.Quantity > 0 {
+			quantity = relation.Quantity
+		}
+		lines = append(lines, entities.DocumentLine{
+			Description: ps.Name,
+			Quantity:    quantity,
+			UnitPrice:   utils.FormatBRL(ps.Price),
+			Total:       utils.FormatBRL(ps.Price * float64(quantity)),
+		})
+	}
+	return lines, nil
+}
+
+func (u *DocumentUseCase) estimateTotals(serviceOrderDto *dto.ServiceOrderDTO) []entities.DocumentField {
+	gross := serviceOrderDto.GrossEstimate
+	if gross == 0 {
+		gross = serviceOrderDto.Estimate
+	}
+	totals := []entities.DocumentField{{Label: "Subtotal", Value: utils.FormatBRL(gross)}}
+	if serviceOrderDto.DiscountTotal > 0 {
+		label := "Descontos"
+		if serviceOrderDto.Coupon != nil {
+			label = fmt.Sprintf("Descontos (cupom %s)", serviceOrderDto.Coupon.Code)
+		}
+		totals = append(totals, entities.DocumentField{Label: label, Value: utils.FormatBRL(-serviceOrderDto.DiscountTotal)})
+	}
+	if serviceOrderDto.TaxTotal > 0 {
+		totals = append(totals, entities.DocumentField{Label: "Impostos inclusos", Value: utils.FormatBRL(serviceOrderDto.TaxTotal)})
+	}
+	return append(totals, entities.DocumentField{Label: "Total", Value: utils.FormatBRL(serviceOrderDto.Estimate)})
+}
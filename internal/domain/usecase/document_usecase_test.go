@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+func documentServiceOrder(status string) *dto.ServiceOrderDTO {
+	return &dto.ServiceOrderDTO{
+		ID:                 10,
+		Customer:           dto.CustomerDTO{ID: 3, FullName: "Joao da Silva", CpfCnpj: "52998224725", PhoneNumber: "11999999999"},
+		Vehicle:            dto.VehicleDTO{ID: 4, Plate: "ABC1D23", Brand: "Fiat", Model: "Uno", Year: "2015"},
+		ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: status},
+		Services:           []dto.ServiceDTO{{ID: 1, Name: "Troca de oleo", Price: 100}},
+		PartsSupplies:      []dto.PartsSupplyDTO{{ID: 2, Name: "Filtro de oleo", Price: 30}},
+		GrossEstimate:      160,
+		DiscountTotal:      16,
+		Estimate:           144,
+		TaxTotal:           14.22,
+	}
+}
+
+func findField(fields []entities.DocumentField, label string) string {
+	for _, f := range fields {
+		if f.Label == label {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+func TestDocumentUseCase_GenerateDocument(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - estimate", func(t *testing.T) {
+		repo := new(mocks.MockServiceOrderRepository)
+		renderer := new(mocks.MockDocumentRenderer)
+		repo.On("GetByIDWithItems", uint(10)).Return(documentServiceOrder("AGUARDANDO APROVAÇÃO"), nil)
+		repo.On("GetPartsSupplyServiceOrder", uint(2), uint(10)).Return(&dto.PartsSupplyServiceOrderDTO{Quantity: 2}, nil)
+		renderer.On("Render", mock.MatchedBy(func(d entities.Document) bool {
+			return d.Type == valueobject.DocumentEstimate &&
+				findField(d.Sections[0].Fields, "CPF/CNPJ") == "529.982.247-25" &&
+				findField(d.Sections[1].Fields, "Placa") == "ABC1D23" &&
+				len(d.Lines) == 2 && d.Lines[1].Quantity == 2 && d.Lines[1].Total == "R$ 60,00" &&
+				findField(d.Totals, "Descontos") == "-R$ 16,00" &&
+				findField(d.Totals, "Total") == "R$ 144,00"
+		})).Return([]byte("%PDF"), nil)
+		u := NewDocumentUseCase(repo, renderer)
+
+		document, err := u.GenerateDocument(ctx, 10, valueobject.DocumentEstimate)
+		assert.NoError(t, err)
+		assert.Equal(t, "estimate-10.pdf", document.FileName)
+		assert.Equal(t, "application/pdf", document.ContentType)
+		assert.Equal(t, []byte("%PDF"), document.Content)
+	})
+
+	t.Run("Success - receipt", func(t *testing.T) {
+		repo := new(mocks.MockServiceOrderRepository)
+		renderer := new(mocks.MockDocumentRenderer)
+		so := documentServiceOrder("ENTREGUE")
+		so.Payment = &dto.PaymentDTO{ID: 5, PaymentDate: time.Date(2025, 8, 15, 10, 0, 0, 0, time.UTC), Amount: 144, ISSAmount: 4.5, ICMSAmount: 9.72}
+		repo.On("GetByIDWithItems", uint(10)).Return(so, nil)
+		renderer.On("Render", mock.MatchedBy(func(d entities.Document) bool {
+			return d.Type == valueobject.DocumentReceipt && d.Number == "Recibo #5" &&
+				findField(d.Totals, "Valor pago") == "R$ 144,00"
+		})).Return([]byte("%PDF"), nil)
+		u := NewDocumentUseCase(repo, renderer)
+
+		_, err := u.GenerateDocument(ctx, 10, valueobject.DocumentReceipt)
+		assert.NoError(t, err)
+		renderer.AssertExpectations(t)
+	})
+
+	t.Run("Error - estimate not available yet", func(t *testing.T) {
+		repo := new(mocks.MockServiceOrderRepository)
+		repo.On("GetByIDWithItems", uint(10)).Return(documentServiceOrder("EM DIAGNÓSTICO"), nil)
+		u := NewDocumentUseCase(repo, new(mocks.MockDocumentRenderer))
+
+		_, err := u.GenerateDocument(ctx, 10, valueobject.DocumentEstimate)
+		assert.ErrorIs(t, err, ErrEstimateNotAvailable)
+	})
+
+	t.Run("Error - receipt without payment", func(t *testing.T) {
+		repo := new(mocks.MockServiceOrderRepository)
+		repo.On("GetByIDWithItems", uint(10)).Return(documentServiceOrder("FINALIZADA"), nil)
+		u := NewDocumentUseCase(repo, new(mocks.MockDocumentRenderer))
+
+		_, err := u.GenerateDocument(ctx, 10, valueobject.DocumentReceipt)
+		assert.ErrorIs(t, err, ErrReceiptNotAvailable)
+	})
+
+	t.Run("Error - invalid document type", func(t *testing.T) {
+		u := NewDocumentUseCase(new(mocks.MockServiceOrderRepository), new(mocks.MockDocumentRenderer))
+
+		_, err := u.GenerateDocument(ctx, 10, valueobject.ParseDocumentType("invoice"))
+		assert.ErrorIs(t, err, ErrInvalidDocumentType)
+	})
+
+	t.Run("Error - service order not found", func(t *testing.T) {
+		repo := new(mocks.MockServiceOrderRepository)
+		repo.On("GetByIDWithItems", uint(99)).Return(nil, nil)
+		u := NewDocumentUseCase(repo, new(mocks.MockDocumentRenderer))
+
+		_, err := u.GenerateDocument(ctx, 99, valueobject.DocumentServiceOrder)
+		assert.ErrorIs(t, err, ErrServiceOrderNotFound)
+	})
+}
+
+func TestDocumentUseCase_GenerateDocument_AfterEstimate(t *testing.T) {
+	ctx := context.Background()
+	invoiceUseCase := new(mocks.MockInvoiceUseCase)
+	invoiceUseCase.On("IssueInvoices", ctx, uint(1)).Return([]entities.Invoice{}, nil)
+	useCase, serviceOrderRepo, _ := newServiceOrderFlow(func(serviceorder.IServiceOrderRepository, ITaxUseCase) IInvoiceUseCase {
+		return invoiceUseCase
+	})
+	hasItems := func(d entities.Document) bool {
+		return len(d.Lines) == 2 &&
+			d.Lines[0].Description == "Service 1" && d.Lines[0].Total == "R$ 100,00" &&
+			d.Lines[1].Description == "Part 2" && d.Lines[1].Quantity == 2 && d.Lines[1].Total == "R$ 60,00"
+	}
+
+	for i, step := range serviceOrderFlowSteps {
+		_, err := useCase.UpdateServiceOrder(ctx, step.request, step.flow)
+		require.NoError(t, err, step.request.ServiceOrderStatus)
+		if i == 0 {
+			continue
+		}
+
+		renderer := new(mocks.MockDocumentRenderer)
+		renderer.On("Render", mock.MatchedBy(func(d entities.Document) bool {
+			return d.Type != valueobject.DocumentReceipt && hasItems(d)
+		})).Return([]byte("%PDF"), nil)
+		renderer.On("Render", mock.MatchedBy(func(d entities.Document) bool {
+			return d.Type == valueobject.DocumentReceipt && findField(d.Totals, "Valor pago") == "R$ 150,00"
+		})).Return([]byte("%PDF"), nil)
+		u := NewDocumentUseCase(serviceOrderRepo, renderer)
+
+		for _, documentType := range []valueobject.DocumentType{valueobject.DocumentEstimate, valueobject.DocumentServiceOrder, valueobject.DocumentReceipt} {
+			_, err = u.GenerateDocument(ctx, 1, documentType)
+			require.NoError(t, err, "%s of a service order %s", documentType, step.request.ServiceOrderStatus)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/outbox"
+)
+
+const (
+	// eventBatchSize is how many outbox events are delivered on each run
+	eventBatchSize = 100
+	// eventRetryDelay is the wait before delivering a failed event again, doubled on each new failure
+	eventRetryDelay = 30 * time.Second
+	// eventMaxRetryDelay caps the wait, as events are retried until every subscriber handles them
+	eventMaxRetryDelay = time.Hour
+)
+
+// EventHandler reacts to a domain event. It may receive the same event more than once, and
+// returning an error makes the dispatcher deliver the event again later.
+type EventHandler func(ctx context.Context, event entities.DomainEvent) error
+
+type IEventDispatcher interface {
+	Subscribe(eventType valueobject.DomainEventType, handler EventHandler)
+	Dispatch(ctx context.Context) (int, error)
+}
+
+// EventDispatcher delivers the events stored in the outbox to the in-process subscribers
+type EventDispatcher struct {
+	repo     outbox.IOutboxRepository
+	mu       sync.RWMutex
+	handlers map[valueobject.DomainEventType][]EventHandler
+	now      func() time.Time
+}
+
+var _ IEventDispatcher = (*EventDispatcher)(nil)
+
+func NewEventDispatcher(repo outbox.IOutboxRepository) *EventDispatcher {
+	return &EventDispatcher{
+		repo:     repo,
+		handlers: make(map[valueobject.DomainEventType][]EventHandler),
+		now:      time.Now,
+	}
+}
+
+func (d *EventDispatcher) Subscribe(eventType valueobject.DomainEventType, handler EventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventType] = append(d.handlers[eventType], handler)
+}
+
+// Dispatch delivers the due events in the order they happened. An event is only marked as
+// dispatched once all of its subscribers handled it; otherwise every subscriber receives it
+// again on a later run. It returns how many events were dispatched.
+func (d *EventDispatcher) Dispatch(ctx context.Context) (int, error) {
+	now := d.now()
+	events, err := d.repo.ListDue(ctx, now, eventBatchSize)
+	if err != nil {
+		log.Error().Msgf("Error listing outbox events: %v", err)
+		return 0, err
+	}
+
+	dispatched := 0
+	for _, outboxEvent := range events {
+		attempts := outboxEvent.Attempts + 1
+		if err := d.deliver(ctx, outboxEvent.ToDomain()); err != nil {
+			log.Error().Msgf("Error delivering event %d %s (attempt %d): %v", outboxEvent.ID, outboxEvent.EventType, attempts, err)
+			if err := d.repo.MarkFailed(ctx, outboxEvent.ID, attempts, err.Error(), now.Add(eventRetryBackoff(attempts))); err != nil {
+				log.Error().Msgf("Error recording failure of event %d: %v", outboxEvent.ID, err)
+			}
+			continue
+		}
+		if err := d.repo.MarkDispatched(ctx, outboxEvent.ID, attempts, now); err != nil {
+			log.Error().Msgf("Error marking event %d as dispatched: %v", outboxEvent.ID, err)
+			continue
+		}
+		dispatched++
+	}
+	return dispatched, nil
+}
+
+// deliver hands the event to every subscriber, so a failing one does not hold up the others
+func (d *EventDispatcher) deliver(ctx context.Context, event entities.DomainEvent) error {
+	d.mu.RLock()
+	handlers := d.handlers[event.Type]
+	d.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func eventRetryBackoff(attempts int) time.Duration {
+	delay := eventRetryDelay
+	for i := 1; i < attempts && delay < eventMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > eventMaxRetryDelay {
+		return eventMaxRetryDelay
+	}
+	return delay
+}
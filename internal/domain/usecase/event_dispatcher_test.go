@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+func newEventDispatcherTest() (*EventDispatcher, *mocks.MockOutboxRepository, time.Time) {
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	repo := new(mocks.MockOutboxRepository)
+	dispatcher := NewEventDispatcher(repo)
+	dispatcher.now = func() time.Time { return now }
+	return dispatcher, repo, now
+}
+
+func outboxEvent(id uint, eventType valueobject.DomainEventType, attempts int) dto.OutboxEventDTO {
+	return dto.OutboxEventDTO{
+		ID:             id,
+		EventType:      eventType.String(),
+		AggregateType:  entities.AggregateServiceOrder,
+		AggregateID:    7,
+		ServiceOrderID: 7,
+		Payload:        `{"from":"RECEBIDA","to":"EM DIAGNÓSTICO"}`,
+		Attempts:       attempts,
+	}
+}
+
+func TestEventDispatcher_Dispatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivers each event to the subscribers of its type", func(t *testing.T) {
+		dispatcher, repo, now := newEventDispatcherTest()
+		var received []entities.DomainEvent
+		dispatcher.Subscribe(valueobject.EventServiceOrderStatusChanged, func(_ context.Context, event entities.DomainEvent) error {
+			received = append(received, event)
+			return nil
+		})
+		dispatcher.Subscribe(valueobject.EventPaymentReceived, func(context.Context, entities.DomainEvent) error {
+			t.Fatal("payment subscriber must not receive status changes")
+			return nil
+		})
+		repo.On("ListDue", ctx, now, eventBatchSize).Return([]dto.OutboxEventDTO{
+			outboxEvent(1, valueobject.EventServiceOrderStatusChanged, 0),
+			outboxEvent(2, valueobject.EventStockReserved, 0),
+		}, nil)
+		repo.On("MarkDispatched", ctx, uint(1), 1, now).Return(nil)
+		repo.On("MarkDispatched", ctx, uint(2), 1, now).Return(nil)
+
+		dispatched, err := dispatcher.Dispatch(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, dispatched)
+		require.Len(t, received, 1)
+		assert.Equal(t, uint(7), received[0].ServiceOrderID)
+		assert.Equal(t, "EM DIAGNÓSTICO", received[0].Payload["to"])
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("failed events are retried with every subscriber", func(t *testing.T) {
+		dispatcher, repo, now := newEventDispatcherTest()
+		calls := 0
+		dispatcher.Subscribe(valueobject.EventServiceOrderCreated, func(context.Context, entities.DomainEvent) error {
+			calls++
+			return nil
+		})
+		dispatcher.Subscribe(valueobject.EventServiceOrderCreated, func(context.Context, entities.DomainEvent) error {
+			return errors.New("subscriber down")
+		})
+		repo.On("ListDue", ctx, now, eventBatchSize).Return([]dto.OutboxEventDTO{
+			outboxEvent(3, valueobject.EventServiceOrderCreated, 2),
+		}, nil)
+		repo.On("MarkFailed", ctx, uint(3), 3, "subscriber down", now.Add(4*eventRetryDelay)).Return(nil)
+
+		dispatched, err := dispatcher.Dispatch(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, dispatched)
+		assert.Equal(t, 1, calls)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error listing", func(t *testing.T) {
+		dispatcher, repo, now := newEventDispatcherTest()
+		repo.On("ListDue", ctx, now, eventBatchSize).Return(nil, errors.New("db down"))
+
+		_, err := dispatcher.Dispatch(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestEventRetryBackoff(t *testing.T) {
+	assert.Equal(t, eventRetryDelay, eventRetryBackoff(1))
+	assert.Equal(t, 2*eventRetryDelay, eventRetryBackoff(2))
+	assert.Equal(t, eventMaxRetryDelay, eventRetryBackoff(50))
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/financial"
+)
+
+var (
+	ErrCashRegisterAlreadyClosed = errors.New("cash register is already closed for this day")
+	ErrCashClosingNotFound       = errors.New("cash closing not found")
+	ErrCashClosingInFuture       = errors.New("cash register cannot be closed for a future day")
+)
+
+type IFinancialUseCase interface {
+	GetPaymentReport(ctx context.Context, from, to time.Time) (*entities.PaymentReport, error)
+	GetReceivables(ctx context.Context) (*entities.ReceivablesReport, error)
+	GetRevenueReport(ctx context.Context, from, to time.Time) (*entities.RevenueReport, error)
+	CloseCashRegister(ctx context.Context, date time.Time, closedBy, notes string) (*entities.CashClosing, error)
+	GetCashClosing(ctx context.Context, date time.Time) (*entities.CashClosing, error)
+	ListCashClosings(ctx context.Context, from, to time.Time) ([]entities.CashClosing, error)
+}
+
+type FinancialUseCase struct {
+	repo financial.IFinancialRepository
+	now  func() time.Time
+}
+
+var _ IFinancialUseCase = (*FinancialUseCase)(nil)
+
+func NewFinancialUseCase(repo financial.IFinancialRepository) *FinancialUseCase {
+	return &FinancialUseCase{repo: repo, now: time.Now}
+}
+
+// GetPaymentReport totals the payments of the period per day, method and operator
+func (u *FinancialUseCase) GetPaymentReport(ctx context.Context, from, to time.Time) (*entities.PaymentReport, error) {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return nil, ErrInvalidReportPeriod
+	}
+
+	lines, err := u.repo.SummarizePayments(ctx, from, to)
+	if err != nil {
+		log.Error().Msgf("Error summarizing payments: %v", err)
+		return nil, err
+	}
+
+	report := &entities.PaymentReport{
+		From:     from,
+		To:       to,
+		Lines:    lines,
+		ByMethod: make(map[valueobject.PaymentMethod]float64),
+	}
+	for _, line := range lines {
+		report.ByMethod[line.Method] = roundCurrency(report.ByMethod[line.Method] + line.Amount)
+		report.Count += line.Count
+		report.Total += line.Amount
+	}
+	report.Total = roundCurrency(report.Total)
+	return report, nil
+}
+
+func (u *FinancialUseCase) GetReceivables(ctx context.Context) (*entities.ReceivablesReport, error) {
+	receivables, err := u.repo.ListReceivables(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing receivables: %v", err)
+		return nil, err
+	}
+
+	report := &entities.ReceivablesReport{Receivables: receivables}
+	for i := range report.Receivables {
+		r := &report.Receivables[i]
+		r.Outstanding = roundCurrency(r.Estimate - r.Paid)
+		report.Total += r.Outstanding
+	}
+	report.Total = roundCurrency(report.Total)
+	return report, nil
+}
+
+func (u *FinancialUseCase) GetRevenueReport(ctx context.Context, from, to time.Time) (*entities.RevenueReport, error) {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return nil, ErrInvalidReportPeriod
+	}
+
+	report, err := u.repo.SummarizeRevenue(ctx, from, to)
+	if err != nil {
+		log.Error().Msgf("Error summarizing revenue: %v", err)
+		return nil, err
+	}
+	report.Services = roundCurrency(report.Services)
+	report.Parts = roundCurrency(report.Parts)
+	report.Total = roundCurrency(report.Total)
+	report.Unallocated = roundCurrency(report.Total - report.Services - report.Parts)
+	if report.Unallocated < 0 {
+		report.Unallocated = 0
+	}
+	return report, nil
+}
+
+// CloseCashRegister records the totals of a day and locks its payments. A day can only be closed once.
+func (u *FinancialUseCase) CloseCashRegister(ctx context.Context, date time.Time, closedBy, notes string) (*entities.CashClosing, error) {
+	start, end := dayBounds(date)
+	if start.After(u.now()) {
+		return nil, ErrCashClosingInFuture
+	}
+
+	existing, err := u.repo.GetClosingByDate(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrCashRegisterAlreadyClosed
+	}
+
+	lines, err := u.repo.SummarizePayments(ctx, start, end)
+	if err != nil {
+		log.Error().Msgf("Error summarizing payments of %s: %v", start.Format("2006-01-02"), err)
+		return nil, err
+	}
+
+	closing := &entities.CashClosing{
+		Date:     start,
+		ClosedBy: closedBy,
+		Notes:    strings.TrimSpace(notes),
+		Lines:    lines,
+	}
+	for _, line := range lines {
+		closing.PaymentCount += line.Count
+		closing.Total += line.Amount
+	}
+	closing.Total = roundCurrency(closing.Total)
+
+	created, err := u.repo.CreateClosing(ctx, closing, start, end)
+	if err != nil {
+		log.Error().Msgf("Error closing cash register of %s: %v", start.Format("2006-01-02"), err)
+		return nil, err
+	}
+	log.Info().Msgf("Cash register of %s closed by %s: %d payments, total %.2f", start.Format("2006-01-02"), closedBy, created.PaymentCount, created.Total)
+	return &created, nil
+}
+
+// GetCashClosing returns the closing record of a day with the payments it locked
+func (u *FinancialUseCase) GetCashClosing(ctx context.Context, date time.Time) (*entities.CashClosing, error) {
+	start, _ := dayBounds(date)
+	closingDTO, err := u.repo.GetClosingByDate(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	if closingDTO == nil {
+		return nil, ErrCashClosingNotFound
+	}
+
+	payments, err := u.repo.ListPaymentsByClosing(ctx, closingDTO.ID)
+	if err != nil {
+		return nil, err
+	}
+	closing := closingDTO.ToDomain()
+	closing.Payments = make([]entities.Payment, 0, len(payments))
+	for _, p := range payments {
+		closing.Payments = append(closing.Payments, *p.ToDomain())
+	}
+	return &closing, nil
+}
+
+func (u *FinancialUseCase) ListCashClosings(ctx context.Context, from, to time.Time) ([]entities.CashClosing, error) {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return nil, ErrInvalidReportPeriod
+	}
+
+	dtos, err := u.repo.ListClosings(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	closings := make([]entities.CashClosing, 0, len(dtos))
+	for _, c := range dtos {
+		closings = append(closings, c.ToDomain())
+	}
+	return closings, nil
+}
+
+// dayBounds returns the first and the last instant of the day of t
+func dayBounds(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+func TestFinancialUseCase_GetPaymentReport(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("Success - totals per method", func(t *testing.T) {
+		repo := new(mocks.MockFinancialRepository)
+		repo.On("SummarizePayments", ctx, from, to).Return([]entities.PaymentReportLine{
+			{Date: "2025-08-01", Method: valueobject.PaymentPix, Operator: "ana@xpto.com", Count: 2, Amount: 300.1},
+			{Date: "2025-08-01", Method: valueobject.PaymentCash, Operator: "ana@xpto.com", Count: 1, Amount: 50},
+			{Date: "2025-08-02", Method: valueobject.PaymentPix, Operator: "bia@xpto.com", Count: 1, Amount: 99.9},
+		}, nil)
+		u := NewFinancialUseCase(repo)
+
+		report, err := u.GetPaymentReport(ctx, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), report.Count)
+		assert.Equal(t, 450.0, report.Total)
+		assert.Equal(t, 400.0, report.ByMethod[valueobject.PaymentPix])
+		assert.Equal(t, 50.0, report.ByMethod[valueobject.PaymentCash])
+	})
+
+	t.Run("Error - end before start", func(t *testing.T) {
+		u := NewFinancialUseCase(new(mocks.MockFinancialRepository))
+
+		_, err := u.GetPaymentReport(ctx, to, from)
+		assert.ErrorIs(t, err, ErrInvalidReportPeriod)
+	})
+}
+
+func TestFinancialUseCase_GetReceivables(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.MockFinancialRepository)
+	repo.On("ListReceivables", ctx).Return([]entities.Receivable{
+		{ServiceOrderID: 1, Estimate: 200},
+		{ServiceOrderID: 2, Estimate: 150, Paid: 100},
+	}, nil)
+	u := NewFinancialUseCase(repo)
+
+	report, err := u.GetReceivables(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 200.0, report.Receivables[0].Outstanding)
+	assert.Equal(t, 50.0, report.Receivables[1].Outstanding)
+	assert.Equal(t, 250.0, report.Total)
+}
+
+func TestFinancialUseCase_GetRevenueReport(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 31, 23, 59, 59, 0, time.UTC)
+	repo := new(mocks.MockFinancialRepository)
+	repo.On("SummarizeRevenue", ctx, from, to).Return(&entities.RevenueReport{
+		ServiceOrders: 3, Services: 500, Parts: 250, Total: 900,
+	}, nil)
+	u := NewFinancialUseCase(repo)
+
+	report, err := u.GetRevenueReport(ctx, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, 150.0, report.Unallocated)
+}
+
+func TestFinancialUseCase_CloseCashRegister(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 8, 15, 19, 0, 0, 0, time.UTC)
+	start := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	t.Run("Success - totals and payments lock", func(t *testing.T) {
+		repo := new(mocks.MockFinancialRepository)
+		repo.On("GetClosingByDate", ctx, start).Return(nil, nil)
+		repo.On("SummarizePayments", ctx, start, end).Return([]entities.PaymentReportLine{
+			{Method: valueobject.PaymentPix, Operator: "ana@xpto.com", Count: 2, Amount: 300},
+			{Method: valueobject.PaymentCreditCard, Operator: "ana@xpto.com", Count: 1, Amount: 120.5},
+		}, nil)
+		repo.On("CreateClosing", ctx, mock.MatchedBy(func(c *entities.CashClosing) bool {
+			return c.PaymentCount == 3 && c.Total == 420.5 && c.ClosedBy == "caixa@xpto.com" && c.Notes == "sem divergencias"
+		}), start, end).Return(entities.CashClosing{ID: 1, Date: start, PaymentCount: 3, Total: 420.5}, nil)
+		u := NewFinancialUseCase(repo)
+		u.now = func() time.Time { return now }
+
+		closing, err := u.CloseCashRegister(ctx, now, "caixa@xpto.com", " sem divergencias ")
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), closing.ID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Error - already closed", func(t *testing.T) {
+		repo := new(mocks.MockFinancialRepository)
+		repo.On("GetClosingByDate", ctx, start).Return(&dto.CashClosingDTO{ID: 1}, nil)
+		u := NewFinancialUseCase(repo)
+		u.now = func() time.Time { return now }
+
+		_, err := u.CloseCashRegister(ctx, now, "caixa@xpto.com", "")
+		assert.ErrorIs(t, err, ErrCashRegisterAlreadyClosed)
+	})
+
+	t.Run("Error - future day", func(t *testing.T) {
+		u := NewFinancialUseCase(new(mocks.MockFinancialRepository))
+		u.now = func() time.Time { return now }
+
+		_, err := u.CloseCashRegister(ctx, now.AddDate(0, 0, 1), "caixa@xpto.com", "")
+		assert.ErrorIs(t, err, ErrCashClosingInFuture)
+	})
+
+	t.Run("Error - creating closing", func(t *testing.T) {
+		repo := new(mocks.MockFinancialRepository)
+		repo.On("GetClosingByDate", ctx, start).Return(nil, nil)
+		repo.On("SummarizePayments", ctx, start, end).Return([]entities.PaymentReportLine{}, nil)
+		repo.On("CreateClosing", ctx, mock.Anything, start, end).Return(entities.CashClosing{}, errors.New("db error"))
+		u := NewFinancialUseCase(repo)
+		u.now = func() time.Time { return now }
+
+		_, err := u.CloseCashRegister(ctx, now, "caixa@xpto.com", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestFinancialUseCase_GetCashClosing(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success - with locked payments", func(t *testing.T) {
+		repo := new(mocks.MockFinancialRepository)
+		repo.On("GetClosingByDate", ctx, day).Return(&dto.CashClosingDTO{
+			ID: 1, Date: day, PaymentCount: 1, Total: 100,
+			Lines: []dto.CashClosingLineDTO{{Method: "PIX", Count: 1, Amount: 100}},
+		}, nil)
+		repo.On("ListPaymentsByClosing", ctx, uint(1)).Return([]dto.PaymentDTO{{ID: 9, Amount: 100, Method: "PIX"}}, nil)
+		u := NewFinancialUseCase(repo)
+
+		closing, err := u.GetCashClosing(ctx, day.Add(15*time.Hour))
+		assert.NoError(t, err)
+		assert.Len(t, closing.Payments, 1)
+		assert.Equal(t, 100.0, closing.ByMethod[valueobject.PaymentPix])
+		assert.Equal(t, "2025-08-15", closing.Lines[0].Date)
+	})
+
+	t.Run("Error - not found", func(t *testing.T) {
+		repo := new(mocks.MockFinancialRepository)
+		repo.On("GetClosingByDate", ctx, day).Return(nil, nil)
+		u := NewFinancialUseCase(repo)
+
+		_, err := u.GetCashClosing(ctx, day)
+		assert.ErrorIs(t, err, ErrCashClosingNotFound)
+	})
+}
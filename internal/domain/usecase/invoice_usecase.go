@@ -0,0 +1,395 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/invoice"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+)
+
+const (
+	minCancellationReasonLength = 15
+	maxCancellationReasonLength = 255
+)
+
+var (
+	ErrInvoiceNotFound           = errors.New("invoice not found")
+	ErrServiceOrderNotDelivered  = errors.New("service order must be delivered to issue invoices")
+	ErrInvoiceAlreadyIssued      = errors.New("service order invoices were already issued")
+	ErrInvoiceWithoutItems       = errors.New("service order has no services or parts supplies to invoice")
+	ErrCustomerDocumentMissing   = errors.New("customer document is required to issue invoices")
+	ErrInvoiceNotIssued          = errors.New("only issued invoices can be cancelled")
+	ErrInvalidCancellationReason = errors.New("cancellation reason must have between 15 and 255 characters")
+)
+
+type IInvoiceUseCase interface {
+	IssueInvoices(ctx context.Context, serviceOrderID uint) ([]entities.Invoice, error)
+	CancelInvoice(ctx context.Context, id uint, reason string) (*entities.Invoice, error)
+	GetInvoice(ctx context.Context, id uint) (*entities.Invoice, error)
+	ListInvoices(ctx context.Context, serviceOrderID uint) ([]entities.Invoice, error)
+}
+
+type InvoiceUseCase struct {
+	repo             invoice.IInvoiceRepository
+	serviceOrderRepo serviceorder.IServiceOrderRepository
+	taxes            ITaxUseCase
+	encoder          gateway.InvoiceEncoder
+	signer           gateway.InvoiceSigner
+	provider         gateway.InvoiceProvider
+	series           string
+}
+
+var _ IInvoiceUseCase = (*InvoiceUseCase)(nil)
+
+func NewInvoiceUseCase(repo invoice.IInvoiceRepository, serviceOrderRepo serviceorder.IServiceOrderRepository, taxes ITaxUseCase, encoder gateway.InvoiceEncoder, signer gateway.InvoiceSigner, provider gateway.InvoiceProvider, series string) *InvoiceUseCase {
+	return &InvoiceUseCase{
+		repo:             repo,
+		serviceOrderRepo: serviceOrderRepo,
+		taxes:            taxes,
+		encoder:          encoder,
+		signer:           signer,
+		provider:         provider,
+		series:           series,
+	}
+}
+
+// IssueInvoices issues the NFS-e of the services and the NF-e of the parts supplies of a delivered
+// service order. Invoice types already issued (or still pending) are skipped, so rejected
+// invoices can be issued again by calling it once more.
+func (u *InvoiceUseCase) IssueInvoices(ctx context.Context, serviceOrderID uint) ([]entities.Invoice, error) {
+	serviceOrderDto, err := u.serviceOrderRepo.GetByIDWithItems(serviceOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if serviceOrderDto == nil {
+		return nil, ErrServiceOrderNotFound
+	}
+	if !serviceOrderDto.ServiceOrderStatus.ToDomain().IsEntregue() {
+		return nil, ErrServiceOrderNotDelivered
+	}
+	if serviceOrderDto.Customer.CpfCnpj == "" {
+		return nil, ErrCustomerDocumentMissing
+	}
+
+	drafts, err := u.buildInvoices(ctx, serviceOrderDto)
+	if err != nil {
+		return nil, err
+	}
+	if len(drafts) == 0 {
+		return nil, ErrInvoiceWithoutItems
+	}
+
+	existing, err := u.repo.ListByServiceOrderID(ctx, serviceOrderID)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[valueobject.InvoiceType]bool)
+	for _, e := range existing {
+		if valueobject.ParseInvoiceStatus(e.Status).IsActive() {
+			active[valueobject.ParseInvoiceType(e.Type)] = true
+		}
+	}
+
+	var issued []entities.Invoice
+	for _, draft := range drafts {
+		if active[draft.Type] {
+			continue
+		}
+		result, err := u.issue(ctx, draft)
+		if err != nil {
+			return issued, err
+		}
+		issued = append(issued, *result)
+	}
+	if len(issued) == 0 {
+		return nil, ErrInvoiceAlreadyIssued
+	}
+	return issued, nil
+}
+
+func (u *InvoiceUseCase) CancelInvoice(ctx context.Context, id uint, reason string) (*entities.Invoice, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) < minCancellationReasonLength || len(reason) > maxCancellationReasonLength {
+		return nil, ErrInvalidCancellationReason
+	}
+
+	invoiceDto, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoiceDto == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	result := invoiceDto.ToDomain()
+	if !result.Status.IsIssued() {
+		return nil, ErrInvoiceNotIssued
+	}
+
+	cancellation, err := u.provider.Cancel(ctx, result, reason)
+	if err != nil {
+		log.Error().Msgf("error cancelling invoice %d: %v", id, err)
+		return nil, err
+	}
+
+	result.Status = valueobject.InvoiceCancelled
+	result.CancellationReason = reason
+	result.CancellationProtocol = cancellation.Protocol
+	result.CancelledAt = &cancellation.CancelledAt
+	if err := u.repo.Update(ctx, &result); err != nil {
+		log.Error().Msgf("error updating cancelled invoice %d: %v", id, err)
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (u *InvoiceUseCase) GetInvoice(ctx context.Context, id uint) (*entities.Invoice, error) {
+	invoiceDto, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoiceDto == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	result := invoiceDto.ToDomain()
+	return &result, nil
+}
+
+func (u *InvoiceUseCase) ListInvoices(ctx context.Context, serviceOrderID uint) ([]entities.Invoice, error) {
+	dtos, err := u.repo.ListByServiceOrderID(ctx, serviceOrderID)
+	if err != nil {
+		return nil, err
+	}
+	invoices := make([]entities.Invoice, 0, len(dtos))
+	for _, i := range dtos {
+		invoices = append(invoices, i.ToDomain())
+	}
+	return invoices, nil
+}
+
+// issue persists the invoice as pending, then builds, signs and submits its XML.
+// Whatever goes wrong after persisting it marks the invoice as rejected, so it can be issued again.
+func (u *InvoiceUseCase) issue(ctx context.Context, draft entities.Invoice) (*entities.Invoice, error) {
+	created, err := u.repo.Create(ctx, &draft)
+	if err != nil {
+		log.Error().Msgf("error creating %s invoice of service order %d: %v", draft.Type, draft.ServiceOrderID, err)
+		return nil, err
+	}
+
+	xml, err := u.encoder.Encode(created)
+	if err != nil {
+		return u.reject(ctx, &created, fmt.Errorf("error building invoice xml: %w", err))
+	}
+	signed, err := u.signer.Sign(xml)
+	if err != nil {
+		return u.reject(ctx, &created, fmt.Errorf("error signing invoice xml: %w", err))
+	}
+	created.XML = string(signed)
+
+	submission, err := u.provider.Submit(ctx, created, signed)
+	if err != nil {
+		log.Error().Msgf("error submitting invoice %d: %v", created.ID, err)
+		submission = &entities.InvoiceSubmission{RejectionReason: err.Error()}
+	}
+
+	if submission.Accepted {
+		created.Status = valueobject.InvoiceIssued
+		created.Number = submission.Number
+		created.Protocol = submission.Protocol
+		created.IssuedAt = &submission.IssuedAt
+	} else {
+		created.Status = valueobject.InvoiceRejected
+		created.RejectionReason = submission.RejectionReason
+	}
+
+	if err := u.repo.Update(ctx, &created); err != nil {
+		log.Error().Msgf("error updating invoice %d: %v", created.ID, err)
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (u *InvoiceUseCase) reject(ctx context.Context, inv *entities.Invoice, cause error) (*entities.Invoice, error) {
+	log.Error().Msgf("invoice %d rejected: %v", inv.ID, cause)
+	inv.Status = valueobject.InvoiceRejected
+	inv.RejectionReason = cause.Error()
+	if err := u.repo.Update(ctx, inv); err != nil {
+		log.Error().Msgf("error updating invoice %d: %v", inv.ID, err)
+	}
+	return nil, cause
+}
+
+// buildInvoices splits the service order line items into an NFS-e (services) and an NF-e (parts supplies).
+// The amounts come from the tax lines calculated with the estimate, which already account for discounts.
+// The items of the approved additional repairs follow, at the prices they were added at, since their
+// estimates were added to the one of the service order.
+func (u *InvoiceUseCase) buildInvoices(ctx context.Context, serviceOrderDto *dto.ServiceOrderDTO) ([]entities.Invoice, error) {
+	serviceTaxes := make(map[uint]dto.ServiceOrderTaxDTO)
+	partsSupplyTaxes := make(map[uint]dto.ServiceOrderTaxDTO)
+	for _, t := range serviceOrderDto.Taxes {
+		if t.ServiceID != nil {
+			serviceTaxes[*t.ServiceID] = t
+		}
+		if t.PartsSupplyID != nil {
+			partsSupplyTaxes[*t.PartsSupplyID] = t
+		}
+	}
+
+	customer := serviceOrderDto.Customer.ToDomain()
+	newInvoice := func(invoiceType valueobject.InvoiceType) entities.Invoice {
+		return entities.Invoice{
+			ServiceOrderID:   serviceOrderDto.ID,
+			Type:             invoiceType,
+			Status:           valueobject.InvoicePending,
+			Series:           u.series,
+			CustomerName:     customer.FullName,
+			CustomerDocument: customer.CpfCnpj,
+		}
+	}
+
+	var serviceItems []entities.InvoiceItem
+	for _, s := range serviceOrderDto.Services {
+		item := entities.InvoiceItem{
+			Code:        fmt.Sprintf("SRV-%d", s.ID),
+			Description: s.Name,
+			Quantity:    1,
+			Total:       s.Price,
+		}
+		if t, ok := serviceTaxes[s.ID]; ok {
+			item.Total = t.Base
+			item.TaxRate = t.Rate
+			item.TaxAmount = t.Amount
+		}
+		item.UnitPrice = item.Total
+		serviceItems = append(serviceItems, item)
+	}
+
+	var partsSupplyItems []entities.InvoiceItem
+	for _, ps := range serviceOrderDto.PartsSupplies {
+		relation, err := u.serviceOrderRepo.GetPartsSupplyServiceOrder(ps.ID, serviceOrderDto.ID)
+		if err != nil {
+			return nil, err
+		}
+		quantity := 1
+		if relation != nil && relation.Quantity > 0 {
+			quantity = relation.Quantity
+		}
+		item := entities.InvoiceItem{
+			Code:        fmt.Sprintf("PEC-%d", ps.ID),
+			Description: ps.Name,
+			Quantity:    quantity,
+			Total:       roundCurrency(ps.Price * float64(quantity)),
+		}
+		if t, ok := partsSupplyTaxes[ps.ID]; ok {
+			item.Total = t.Base
+			item.TaxRate = t.Rate
+			item.TaxAmount = t.Amount
+		}
+		item.UnitPrice = roundCurrency(item.Total / float64(quantity))
+		partsSupplyItems = append(partsSupplyItems, item)
+	}
+
+	repairServiceItems, repairPartsSupplyItems, err := u.additionalRepairItems(ctx, serviceOrderDto)
+	if err != nil {
+		return nil, err
+	}
+	serviceItems = append(serviceItems, repairServiceItems...)
+	partsSupplyItems = append(partsSupplyItems, repairPartsSupplyItems...)
+
+	var invoices []entities.Invoice
+	if len(serviceItems) > 0 {
+		nfse := newInvoice(valueobject.InvoiceNFSe)
+		addInvoiceItems(&nfse, serviceItems)
+		invoices = append(invoices, nfse)
+	}
+	if len(partsSupplyItems) > 0 {
+		nfe := newInvoice(valueobject.InvoiceNFe)
+		addInvoiceItems(&nfe, partsSupplyItems)
+		invoices = append(invoices, nfe)
+	}
+
+	return invoices, nil
+}
+
+// additionalRepairItems lists the services and the parts supplies of the approved additional
+// repairs of the service order, taxed the same way as the estimate
+func (u *InvoiceUseCase) additionalRepairItems(ctx context.Context, serviceOrderDto *dto.ServiceOrderDTO) ([]entities.InvoiceItem, []entities.InvoiceItem, error) {
+	var pricing entities.EstimateBreakdown
+	var items []entities.InvoiceItem
+	addLine := func(line entities.EstimateLine, item entities.InvoiceItem) {
+		line.Gross = item.Total
+		line.Net = item.Total
+		pricing.Lines = append(pricing.Lines, line)
+		pricing.Gross += item.Total
+		pricing.Net += item.Total
+		items = append(items, item)
+	}
+
+	for i := range serviceOrderDto.AdditionalRepairs {
+		repair := &serviceOrderDto.AdditionalRepairs[i]
+		if !repair.ARStatus.ToDomain().IsAprovada() {
+			continue
+		}
+		for _, s := range repair.Services {
+			price := repair.ServiceUnitPrice(s.ID)
+			addLine(entities.EstimateLine{ServiceID: s.ID, Category: s.Category, Quantity: 1, UnitPrice: price}, entities.InvoiceItem{
+				Code:        fmt.Sprintf("SRV-%d", s.ID),
+				Description: s.Name,
+				Quantity:    1,
+				UnitPrice:   price,
+				Total:       roundCurrency(price),
+			})
+		}
+		for _, ps := range repair.PartsSupplies {
+			quantity := repair.PartsSupplyQuantity(ps.ID)
+			if quantity == 0 {
+				continue
+			}
+			price := repair.PartsSupplyUnitPrice(ps.ID)
+			addLine(entities.EstimateLine{PartsSupplyID: ps.ID, Category: ps.Category, Quantity: quantity, UnitPrice: price}, entities.InvoiceItem{
+				Code:        fmt.Sprintf("PEC-%d", ps.ID),
+				Description: ps.Name,
+				Quantity:    quantity,
+				UnitPrice:   roundCurrency(price),
+				Total:       roundCurrency(price * float64(quantity)),
+			})
+		}
+	}
+	if len(items) == 0 {
+		return nil, nil, nil
+	}
+
+	taxes, err := u.taxes.CalculateTaxes(ctx, pricing)
+	if err != nil {
+		return nil, nil, err
+	}
+	var serviceItems, partsSupplyItems []entities.InvoiceItem
+	for i, line := range taxes.Lines {
+		items[i].TaxRate = line.Rate
+		items[i].TaxAmount = line.Amount
+		if line.ServiceID != 0 {
+			serviceItems = append(serviceItems, items[i])
+		} else {
+			partsSupplyItems = append(partsSupplyItems, items[i])
+		}
+	}
+	return serviceItems, partsSupplyItems, nil
+}
+
+func addInvoiceItems(inv *entities.Invoice, items []entities.InvoiceItem) {
+	for _, item := range items {
+		inv.Items = append(inv.Items, item)
+		inv.Total += item.Total
+		inv.TaxTotal += item.TaxAmount
+	}
+	inv.Total = roundCurrency(inv.Total)
+	inv.TaxTotal = roundCurrency(inv.TaxTotal)
+}
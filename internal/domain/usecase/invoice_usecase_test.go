@@ -0,0 +1,253 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+type invoiceMocks struct {
+	repo             *mocks.MockInvoiceRepository
+	serviceOrderRepo *mocks.MockServiceOrderRepository
+	taxRepo          *mocks.MockTaxRepository
+	encoder          *mocks.MockInvoiceEncoder
+	signer           *mocks.MockInvoiceSigner
+	provider         *mocks.MockInvoiceProvider
+}
+
+func newInvoiceUseCaseWithMocks() (*InvoiceUseCase, invoiceMocks) {
+	m := invoiceMocks{
+		repo:             new(mocks.MockInvoiceRepository),
+		serviceOrderRepo: new(mocks.MockServiceOrderRepository),
+		taxRepo:          new(mocks.MockTaxRepository),
+		encoder:          new(mocks.MockInvoiceEncoder),
+		signer:           new(mocks.MockInvoiceSigner),
+		provider:         new(mocks.MockInvoiceProvider),
+	}
+	return NewInvoiceUseCase(m.repo, m.serviceOrderRepo, NewTaxUseCase(m.taxRepo, 5, 18), m.encoder, m.signer, m.provider, "1"), m
+}
+
+func deliveredServiceOrder() *dto.ServiceOrderDTO {
+	serviceID := uint(1)
+	partsSupplyID := uint(2)
+	return &dto.ServiceOrderDTO{
+		ID:                 10,
+		Customer:           dto.CustomerDTO{ID: 3, FullName: "Joao da Silva", CpfCnpj: "52998224725"},
+		ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: "ENTREGUE"},
+		Services:           []dto.ServiceDTO{{ID: serviceID, Name: "Troca de oleo", Price: 100}},
+		PartsSupplies:      []dto.PartsSupplyDTO{{ID: partsSupplyID, Name: "Filtro de oleo", Price: 30}},
+		Taxes: []dto.ServiceOrderTaxDTO{
+			{TaxType: "ISS", ServiceID: &serviceID, Base: 90, Rate: 5, Amount: 4.5},
+			{TaxType: "ICMS", PartsSupplyID: &partsSupplyID, Base: 54, Rate: 18, Amount: 9.72},
+		},
+	}
+}
+
+func TestInvoiceUseCase_IssueInvoices(t *testing.T) {
+	ctx := context.Background()
+	issuedAt := time.Date(2025, 8, 15, 10, 30, 0, 0, time.UTC)
+
+	t.Run("Success - NFS-e for services and NF-e for parts supplies", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		m.serviceOrderRepo.On("GetByIDWithItems", uint(10)).Return(deliveredServiceOrder(), nil)
+		m.serviceOrderRepo.On("GetPartsSupplyServiceOrder", uint(2), uint(10)).Return(&dto.PartsSupplyServiceOrderDTO{Quantity: 2}, nil)
+		m.repo.On("ListByServiceOrderID", ctx, uint(10)).Return([]dto.InvoiceDTO{}, nil)
+		m.repo.On("Create", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+			return i.Type == valueobject.InvoiceNFSe && i.Total == 90 && i.TaxTotal == 4.5
+		})).Return(entities.Invoice{ID: 1, Type: valueobject.InvoiceNFSe, Status: valueobject.InvoicePending}, nil)
+		m.repo.On("Create", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+			return i.Type == valueobject.InvoiceNFe && i.Items[0].Quantity == 2 && i.Items[0].UnitPrice == 27
+		})).Return(entities.Invoice{ID: 2, Type: valueobject.InvoiceNFe, Status: valueobject.InvoicePending}, nil)
+		m.encoder.On("Encode", mock.Anything).Return([]byte("<xml/>"), nil)
+		m.signer.On("Sign", []byte("<xml/>")).Return([]byte("<signed/>"), nil)
+		m.provider.On("Submit", ctx, mock.Anything, []byte("<signed/>")).
+			Return(&entities.InvoiceSubmission{Accepted: true, Number: "15", Protocol: "P1", IssuedAt: issuedAt}, nil)
+		m.repo.On("Update", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+			return i.Status == valueobject.InvoiceIssued && i.Number == "15" && i.XML == "<signed/>"
+		})).Return(nil)
+
+		invoices, err := u.IssueInvoices(ctx, 10)
+		assert.NoError(t, err)
+		assert.Len(t, invoices, 2)
+		assert.Equal(t, "P1", invoices[0].Protocol)
+		m.repo.AssertNumberOfCalls(t, "Update", 2)
+	})
+
+	t.Run("Success - approved additional repairs are invoiced at the prices of the estimate", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		so := deliveredServiceOrder()
+		so.Estimate = 214
+		so.AdditionalRepairs = []dto.AdditionalRepairDTO{
+			{
+				ID:               7,
+				ServiceOrderID:   10,
+				ARStatus:         dto.AdditionalRepairStatusDTO{Description: "APROVADA"},
+				Estimate:         70,
+				Services:         []dto.ServiceDTO{{ID: 5, Name: "Troca de pastilhas", Price: 45}},
+				ServiceItems:     []dto.ServiceAdditionalRepairDTO{{ServiceID: 5, AdditionalRepairID: 7, UnitPrice: 40}},
+				PartsSupplies:    []dto.PartsSupplyDTO{{ID: 6, Name: "Pastilha de freio", Price: 20}},
+				PartsSupplyItems: []dto.PartsSupplyAdditionalRepairDTO{{PartsSupplyID: 6, AdditionalRepairID: 7, Quantity: 2, UnitPrice: 15}},
+			},
+			{
+				ID:             8,
+				ServiceOrderID: 10,
+				ARStatus:       dto.AdditionalRepairStatusDTO{Description: "REJEITADA"},
+				Estimate:       50,
+				Services:       []dto.ServiceDTO{{ID: 9, Name: "Alinhamento", Price: 50}},
+			},
+		}
+		m.serviceOrderRepo.On("GetByIDWithItems", uint(10)).Return(so, nil)
+		m.serviceOrderRepo.On("GetPartsSupplyServiceOrder", uint(2), uint(10)).Return(&dto.PartsSupplyServiceOrderDTO{Quantity: 2}, nil)
+		m.taxRepo.On("ListRules", ctx).Return([]dto.TaxRuleDTO{}, nil)
+		m.repo.On("ListByServiceOrderID", ctx, uint(10)).Return([]dto.InvoiceDTO{}, nil)
+		var total float64
+		m.repo.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			total += args.Get(1).(*entities.Invoice).Total
+		}).Return(entities.Invoice{ID: 1, Type: valueobject.InvoiceNFSe, Status: valueobject.InvoicePending}, nil)
+		m.encoder.On("Encode", mock.Anything).Return([]byte("<xml/>"), nil)
+		m.signer.On("Sign", mock.Anything).Return([]byte("<signed/>"), nil)
+		m.provider.On("Submit", ctx, mock.Anything, mock.Anything).
+			Return(&entities.InvoiceSubmission{Accepted: true, Number: "15", Protocol: "P1", IssuedAt: issuedAt}, nil)
+		m.repo.On("Update", ctx, mock.Anything).Return(nil)
+
+		_, err := u.IssueInvoices(ctx, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, so.Estimate, total)
+		m.repo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+			return i.Type == valueobject.InvoiceNFSe && len(i.Items) == 2 && i.Total == 130 &&
+				i.Items[1].Code == "SRV-5" && i.Items[1].TaxAmount == 2 && i.TaxTotal == 6.5
+		}))
+		m.repo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+			return i.Type == valueobject.InvoiceNFe && len(i.Items) == 2 && i.Total == 84 &&
+				i.Items[1].Quantity == 2 && i.Items[1].UnitPrice == 15 && i.Items[1].TaxAmount == 5.4
+		}))
+	})
+
+	t.Run("Success - rejected by the provider", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		so := deliveredServiceOrder()
+		so.PartsSupplies = nil
+		m.serviceOrderRepo.On("GetByIDWithItems", uint(10)).Return(so, nil)
+		m.repo.On("ListByServiceOrderID", ctx, uint(10)).Return([]dto.InvoiceDTO{}, nil)
+		m.repo.On("Create", ctx, mock.Anything).Return(entities.Invoice{ID: 1, Type: valueobject.InvoiceNFSe}, nil)
+		m.encoder.On("Encode", mock.Anything).Return([]byte("<xml/>"), nil)
+		m.signer.On("Sign", mock.Anything).Return([]byte("<signed/>"), nil)
+		m.provider.On("Submit", ctx, mock.Anything, mock.Anything).Return(nil, errors.New("timeout"))
+		m.repo.On("Update", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+			return i.Status == valueobject.InvoiceRejected && i.RejectionReason == "timeout"
+		})).Return(nil)
+
+		invoices, err := u.IssueInvoices(ctx, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, valueobject.InvoiceRejected, invoices[0].Status)
+	})
+
+	t.Run("Success - issued types are skipped", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		so := deliveredServiceOrder()
+		so.PartsSupplies = nil
+		m.serviceOrderRepo.On("GetByIDWithItems", uint(10)).Return(so, nil)
+		m.repo.On("ListByServiceOrderID", ctx, uint(10)).Return([]dto.InvoiceDTO{{ID: 1, Type: "NFSE", Status: "EMITIDA"}}, nil)
+
+		_, err := u.IssueInvoices(ctx, 10)
+		assert.ErrorIs(t, err, ErrInvoiceAlreadyIssued)
+		m.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - signing marks the invoice as rejected", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		so := deliveredServiceOrder()
+		so.PartsSupplies = nil
+		m.serviceOrderRepo.On("GetByIDWithItems", uint(10)).Return(so, nil)
+		m.repo.On("ListByServiceOrderID", ctx, uint(10)).Return([]dto.InvoiceDTO{}, nil)
+		m.repo.On("Create", ctx, mock.Anything).Return(entities.Invoice{ID: 1, Type: valueobject.InvoiceNFSe}, nil)
+		m.encoder.On("Encode", mock.Anything).Return([]byte("<xml/>"), nil)
+		m.signer.On("Sign", mock.Anything).Return(nil, errors.New("expired certificate"))
+		m.repo.On("Update", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+			return i.Status == valueobject.InvoiceRejected
+		})).Return(nil)
+
+		_, err := u.IssueInvoices(ctx, 10)
+		assert.Error(t, err)
+		m.provider.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - service order not delivered", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		so := deliveredServiceOrder()
+		so.ServiceOrderStatus.Description = "FINALIZADA"
+		m.serviceOrderRepo.On("GetByIDWithItems", uint(10)).Return(so, nil)
+
+		_, err := u.IssueInvoices(ctx, 10)
+		assert.ErrorIs(t, err, ErrServiceOrderNotDelivered)
+	})
+
+	t.Run("Error - customer without document", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		so := deliveredServiceOrder()
+		so.Customer.CpfCnpj = ""
+		m.serviceOrderRepo.On("GetByIDWithItems", uint(10)).Return(so, nil)
+
+		_, err := u.IssueInvoices(ctx, 10)
+		assert.ErrorIs(t, err, ErrCustomerDocumentMissing)
+	})
+
+	t.Run("Error - service order not found", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		m.serviceOrderRepo.On("GetByIDWithItems", uint(99)).Return(nil, nil)
+
+		_, err := u.IssueInvoices(ctx, 99)
+		assert.ErrorIs(t, err, ErrServiceOrderNotFound)
+	})
+}
+
+func TestInvoiceUseCase_CancelInvoice(t *testing.T) {
+	ctx := context.Background()
+	reason := "servico cobrado em duplicidade"
+
+	t.Run("Success", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		m.repo.On("GetByID", ctx, uint(1)).Return(&dto.InvoiceDTO{ID: 1, Type: "NFSE", Status: "EMITIDA", Number: "15"}, nil)
+		m.provider.On("Cancel", ctx, mock.Anything, reason).
+			Return(&entities.InvoiceCancellation{Protocol: "C1", CancelledAt: time.Now()}, nil)
+		m.repo.On("Update", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+			return i.Status == valueobject.InvoiceCancelled && i.CancellationProtocol == "C1"
+		})).Return(nil)
+
+		invoice, err := u.CancelInvoice(ctx, 1, reason)
+		assert.NoError(t, err)
+		assert.Equal(t, valueobject.InvoiceCancelled, invoice.Status)
+	})
+
+	t.Run("Error - invoice not issued", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		m.repo.On("GetByID", ctx, uint(1)).Return(&dto.InvoiceDTO{ID: 1, Type: "NFSE", Status: "REJEITADA"}, nil)
+
+		_, err := u.CancelInvoice(ctx, 1, reason)
+		assert.ErrorIs(t, err, ErrInvoiceNotIssued)
+	})
+
+	t.Run("Error - reason too short", func(t *testing.T) {
+		u, _ := newInvoiceUseCaseWithMocks()
+
+		_, err := u.CancelInvoice(ctx, 1, "erro")
+		assert.ErrorIs(t, err, ErrInvalidCancellationReason)
+	})
+
+	t.Run("Error - invoice not found", func(t *testing.T) {
+		u, m := newInvoiceUseCaseWithMocks()
+		m.repo.On("GetByID", ctx, uint(9)).Return(nil, nil)
+
+		_, err := u.CancelInvoice(ctx, 9, reason)
+		assert.ErrorIs(t, err, ErrInvoiceNotFound)
+	})
+}
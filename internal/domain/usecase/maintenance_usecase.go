@@ -0,0 +1,383 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/customers"
+	"mecanica_xpto/internal/domain/repository/maintenance"
+	"mecanica_xpto/internal/domain/repository/service"
+	"mecanica_xpto/pkg/utils"
+)
+
+var (
+	ErrMaintenancePlanNotFound = errors.New("maintenance plan not found")
+	ErrInvalidMaintenancePlan  = errors.New("invalid maintenance plan")
+)
+
+type IMaintenanceUseCase interface {
+	GetPlan(ctx context.Context, serviceID uint) (*entities.MaintenancePlan, error)
+	ListPlans(ctx context.Context) ([]entities.MaintenancePlan, error)
+	SavePlan(ctx context.Context, serviceID uint, request entities.MaintenancePlanRequest) (*entities.MaintenancePlan, error)
+	DeletePlan(ctx context.Context, serviceID uint) error
+	GetVehicleMaintenance(ctx context.Context, vehicleID uint) (*entities.VehicleMaintenance, error)
+	SendReminders(ctx context.Context) (int, error)
+}
+
+type MaintenanceUseCase struct {
+	repo           maintenance.IMaintenanceRepository
+	serviceRepo    service.IServiceRepo
+	customerRepo   customers.ICustomerRepository
+	historyUseCase IVehicleHistoryUseCase
+	senders        map[valueobject.NotificationChannel]gateway.NotificationSender
+	leadDays       int
+	leadKm         int
+	now            func() time.Time
+}
+
+var _ IMaintenanceUseCase = (*MaintenanceUseCase)(nil)
+
+func NewMaintenanceUseCase(repo maintenance.IMaintenanceRepository, serviceRepo service.IServiceRepo, customerRepo customers.ICustomerRepository, historyUseCase IVehicleHistoryUseCase, senders []gateway.NotificationSender, cfg utils.MaintenanceConfig) *MaintenanceUseCase {
+	byChannel := make(map[valueobject.NotificationChannel]gateway.NotificationSender, len(senders))
+	for _, sender := range senders {
+		byChannel[sender.Channel()] = sender
+	}
+	return &MaintenanceUseCase{
+		repo:           repo,
+		serviceRepo:    serviceRepo,
+		customerRepo:   customerRepo,
+		historyUseCase: historyUseCase,
+		senders:        byChannel,
+		leadDays:       cfg.LeadDays,
+		leadKm:         cfg.LeadKm,
+		now:            time.Now,
+	}
+}
+
+func (u *MaintenanceUseCase) GetPlan(ctx context.Context, serviceID uint) (*entities.MaintenancePlan, error) {
+	planDto, err := u.repo.GetPlanByService(ctx, serviceID)
+	if err != nil {
+		log.Error().Msgf("Error finding maintenance plan of service %d: %v", serviceID, err)
+		return nil, err
+	}
+	if planDto == nil {
+		return nil, ErrMaintenancePlanNotFound
+	}
+	plan := planDto.ToDomain()
+	return &plan, nil
+}
+
+func (u *MaintenanceUseCase) ListPlans(ctx context.Context) ([]entities.MaintenancePlan, error) {
+	plans, err := u.repo.ListPlans(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing maintenance plans: %v", err)
+		return nil, err
+	}
+	result := make([]entities.MaintenancePlan, 0, len(plans))
+	for i := range plans {
+		result = append(result, plans[i].ToDomain())
+	}
+	return result, nil
+}
+
+// SavePlan creates the maintenance plan of the service or replaces its intervals. At least one
+// interval must be set; a zero interval is not used.
+func (u *MaintenanceUseCase) SavePlan(ctx context.Context, serviceID uint, request entities.MaintenancePlanRequest) (*entities.MaintenancePlan, error) {
+	if request.IntervalKm < 0 || request.IntervalMonths < 0 || (request.IntervalKm == 0 && request.IntervalMonths == 0) {
+		return nil, ErrInvalidMaintenancePlan
+	}
+
+	svc, err := u.serviceRepo.GetByID(ctx, serviceID)
+	if err != nil {
+		log.Error().Msgf("Error finding service with id %d: %v", serviceID, err)
+		return nil, err
+	}
+	if svc.ID == 0 {
+		return nil, ErrServiceNotFound
+	}
+
+	planDto, err := u.repo.GetPlanByService(ctx, serviceID)
+	if err != nil {
+		log.Error().Msgf("Error finding maintenance plan of service %d: %v", serviceID, err)
+		return nil, err
+	}
+	if planDto == nil {
+		planDto = &dto.MaintenancePlanDTO{ServiceID: serviceID}
+	}
+	planDto.Service = dto.ServiceDTO{ID: svc.ID, Name: svc.Name}
+	planDto.IntervalKm = request.IntervalKm
+	planDto.IntervalMonths = request.IntervalMonths
+	if err := u.repo.SavePlan(ctx, planDto); err != nil {
+		log.Error().Msgf("Error saving maintenance plan of service %d: %v", serviceID, err)
+		return nil, err
+	}
+
+	plan := planDto.ToDomain()
+	return &plan, nil
+}
+
+func (u *MaintenanceUseCase) DeletePlan(ctx context.Context, serviceID uint) error {
+	planDto, err := u.repo.GetPlanByService(ctx, serviceID)
+	if err != nil {
+		log.Error().Msgf("Error finding maintenance plan of service %d: %v", serviceID, err)
+		return err
+	}
+	if planDto == nil {
+		return ErrMaintenancePlanNotFound
+	}
+	if err := u.repo.DeletePlan(ctx, planDto.ID); err != nil {
+		log.Error().Msgf("Error deleting maintenance plan of service %d: %v", serviceID, err)
+		return err
+	}
+	return nil
+}
+
+// GetVehicleMaintenance computes, for every maintenance plan, when the vehicle is next due from the
+// last time the service was performed on it
+func (u *MaintenanceUseCase) GetVehicleMaintenance(ctx context.Context, vehicleID uint) (*entities.VehicleMaintenance, error) {
+	plans, err := u.repo.ListPlans(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing maintenance plans: %v", err)
+		return nil, err
+	}
+	history, err := u.historyUseCase.GetVehicleHistory(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	return u.schedule(history, plans), nil
+}
+
+// SendReminders reminds the owners of the vehicles coming due for a maintenance. Each maintenance
+// is reminded of once for every time it was performed; when no channel could deliver the reminder
+// it is tried again on the next run. It returns how many reminders were sent.
+//
+// Reminders are sent right away rather than queued with the service order notifications, since
+// they are not about an order of the customer.
+func (u *MaintenanceUseCase) SendReminders(ctx context.Context) (int, error) {
+	plans, err := u.repo.ListPlans(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing maintenance plans: %v", err)
+		return 0, err
+	}
+	if len(plans) == 0 {
+		return 0, nil
+	}
+	serviceIDs := make([]uint, 0, len(plans))
+	for _, plan := range plans {
+		serviceIDs = append(serviceIDs, plan.ServiceID)
+	}
+	vehicleIDs, err := u.repo.ListVehiclesWithServices(ctx, serviceIDs)
+	if err != nil {
+		log.Error().Msgf("Error listing the vehicles with planned maintenance: %v", err)
+		return 0, err
+	}
+
+	sent := 0
+	for _, vehicleID := range vehicleIDs {
+		history, err := u.historyUseCase.GetVehicleHistory(ctx, vehicleID)
+		if err != nil {
+			log.Error().Msgf("Error finding the history of vehicle %d to remind of maintenance: %v", vehicleID, err)
+			continue
+		}
+		schedule := u.schedule(history, plans)
+
+		var customer *entities.Customer
+		for _, item := range schedule.Items {
+			if !item.Status.IsDue() {
+				continue
+			}
+			if customer == nil {
+				if customer, err = u.findCustomer(schedule.Vehicle.CustomerID); err != nil || customer == nil {
+					log.Error().Msgf("Error finding the owner of vehicle %d to remind of maintenance: %v", vehicleID, err)
+					break
+				}
+			}
+			reminded, err := u.remind(ctx, &schedule.Vehicle, customer, item)
+			if err != nil {
+				log.Error().Msgf("Error reminding of maintenance plan %d of vehicle %d: %v", item.PlanID, vehicleID, err)
+				continue
+			}
+			if reminded {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+func (u *MaintenanceUseCase) findCustomer(customerID uint) (*entities.Customer, error) {
+	customerDto, err := u.customerRepo.GetByID(customerID)
+	if err != nil || customerDto == nil {
+		return nil, err
+	}
+	return customerDto.ToDomain(), nil
+}
+
+// remind sends the reminder of a maintenance on every channel the customer can be reached on,
+// unless it was already sent since the service was last performed
+func (u *MaintenanceUseCase) remind(ctx context.Context, vehicle *entities.Vehicle, customer *entities.Customer, item entities.MaintenanceDue) (bool, error) {
+	serviceOrderID := item.LastPerformed.ServiceOrderID
+	reminded, err := u.repo.HasReminder(ctx, vehicle.ID, item.PlanID, serviceOrderID)
+	if err != nil || reminded {
+		return false, err
+	}
+
+	subject, body, err := maintenanceReminderTemplate.render(notificationData{
+		CustomerName: customer.FullName,
+		Vehicle:      vehicle.Brand + " " + vehicle.Model,
+		Plate:        vehicle.Plate.String(),
+		Service:      item.Service,
+		Due:          describeMaintenanceDue(item),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var channels []string
+	for _, channel := range notificationChannels {
+		sender, ok := u.senders[channel]
+		if !ok {
+			continue
+		}
+		recipient := notificationRecipient(channel, customer)
+		if recipient == "" {
+			continue
+		}
+		message := entities.NotificationMessage{Channel: channel, Recipient: recipient, Subject: subject, Body: body}
+		if err := sender.Send(ctx, message); err != nil {
+			log.Error().Msgf("Error sending %s maintenance reminder of vehicle %d: %v", channel, vehicle.ID, err)
+			continue
+		}
+		channels = append(channels, channel.String())
+	}
+	if len(channels) == 0 {
+		return false, nil
+	}
+
+	err = u.repo.CreateReminder(ctx, &dto.MaintenanceReminderDTO{
+		VehicleID:         vehicle.ID,
+		MaintenancePlanID: item.PlanID,
+		ServiceOrderID:    serviceOrderID,
+		DueDate:           item.NextDueDate,
+		DueOdometer:       item.NextDueOdometer,
+		Channels:          strings.Join(channels, ","),
+		SentAt:            u.now(),
+	})
+	return err == nil, err
+}
+
+func (u *MaintenanceUseCase) schedule(history *entities.VehicleHistory, plans []dto.MaintenancePlanDTO) *entities.VehicleMaintenance {
+	now := u.now()
+	current := history.Summary.LastOdometer
+	result := &entities.VehicleMaintenance{
+		Vehicle:         history.Vehicle,
+		CurrentOdometer: current,
+		Items:           make([]entities.MaintenanceDue, 0, len(plans)),
+	}
+
+	for _, plan := range plans {
+		item := entities.MaintenanceDue{
+			PlanID:         plan.ID,
+			ServiceID:      plan.ServiceID,
+			Service:        plan.Service.Name,
+			IntervalKm:     plan.IntervalKm,
+			IntervalMonths: plan.IntervalMonths,
+			LastPerformed:  lastPerformed(history.Visits, plan.ServiceID),
+			Status:         valueobject.MaintenanceNoHistory,
+		}
+		if last := item.LastPerformed; last != nil {
+			if plan.IntervalMonths > 0 {
+				due := last.Date.AddDate(0, plan.IntervalMonths, 0)
+				item.NextDueDate = &due
+			}
+			if plan.IntervalKm > 0 && last.Odometer != nil {
+				due := *last.Odometer + plan.IntervalKm
+				item.NextDueOdometer = &due
+			}
+			if item.NextDueDate != nil || item.NextDueOdometer != nil {
+				item.Status = u.maintenanceStatus(item, current, now)
+			}
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result
+}
+
+// maintenanceStatus is overdue once the due date or mileage is reached, whichever comes first, and
+// due soon within the configured lead of either
+func (u *MaintenanceUseCase) maintenanceStatus(item entities.MaintenanceDue, currentOdometer *int, now time.Time) valueobject.MaintenanceStatus {
+	status := valueobject.MaintenanceUpToDate
+	if due := item.NextDueDate; due != nil {
+		if !now.Before(*due) {
+			return valueobject.MaintenanceOverdue
+		}
+		if !now.Before(due.AddDate(0, 0, -u.leadDays)) {
+			status = valueobject.MaintenanceDueSoon
+		}
+	}
+	if due := item.NextDueOdometer; due != nil && currentOdometer != nil {
+		if *currentOdometer >= *due {
+			return valueobject.MaintenanceOverdue
+		}
+		if *currentOdometer >= *due-u.leadKm {
+			status = valueobject.MaintenanceDueSoon
+		}
+	}
+	return status
+}
+
+// lastPerformed expects the visits oldest first and only counts finished or delivered orders
+func lastPerformed(visits []entities.VehicleVisit, serviceID uint) *entities.MaintenanceRecord {
+	var record *entities.MaintenanceRecord
+	for i := range visits {
+		visit := &visits[i]
+		if !isWorkDone(visit.Status) {
+			continue
+		}
+		for _, service := range visit.Services {
+			if service.ServiceID != serviceID {
+				continue
+			}
+			date := visit.ReceivedAt
+			if visit.CompletedAt != nil {
+				date = *visit.CompletedAt
+			}
+			record = &entities.MaintenanceRecord{ServiceOrderID: visit.ServiceOrderID, Date: date, Odometer: visit.Odometer, Service: service.Name}
+			break
+		}
+	}
+	return record
+}
+
+// describeMaintenanceDue tells the customer when the maintenance is due, e.g. "até 10/04/2026 ou
+// aos 50.000 km"
+func describeMaintenanceDue(item entities.MaintenanceDue) string {
+	var parts []string
+	if item.NextDueDate != nil {
+		parts = append(parts, "até "+item.NextDueDate.Format("02/01/2006"))
+	}
+	if item.NextDueOdometer != nil {
+		parts = append(parts, "aos "+formatKm(*item.NextDueOdometer)+" km")
+	}
+	return strings.Join(parts, " ou ")
+}
+
+// formatKm writes the mileage with dots between the thousands, as it is read in Brazil
+func formatKm(km int) string {
+	digits := strconv.Itoa(km)
+	var groups []string
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	return strings.Join(append([]string{digits}, groups...), ".")
+}
@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+	"mecanica_xpto/pkg/utils"
+)
+
+var maintenanceNow = time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+
+func newMaintenanceTestUseCase(senders ...gateway.NotificationSender) (*MaintenanceUseCase, *mocks.MockMaintenanceRepository, *MockServiceRepository, *MockCustomerRepository, *mocks.MockVehicleHistoryUseCase) {
+	repo := new(mocks.MockMaintenanceRepository)
+	serviceRepo := new(MockServiceRepository)
+	customerRepo := new(MockCustomerRepository)
+	historyUseCase := new(mocks.MockVehicleHistoryUseCase)
+	uc := NewMaintenanceUseCase(repo, serviceRepo, customerRepo, historyUseCase, senders, utils.MaintenanceConfig{LeadDays: 15, LeadKm: 500})
+	uc.now = func() time.Time { return maintenanceNow }
+	return uc, repo, serviceRepo, customerRepo, historyUseCase
+}
+
+func maintenanceVisit(serviceOrderID uint, status valueobject.ServiceOrderStatus, completedAt time.Time, odometer *int, serviceIDs ...uint) entities.VehicleVisit {
+	visit := entities.VehicleVisit{ServiceOrderID: serviceOrderID, Status: status, ReceivedAt: completedAt.AddDate(0, 0, -1), CompletedAt: &completedAt, Odometer: odometer}
+	for _, serviceID := range serviceIDs {
+		visit.Services = append(visit.Services, entities.VisitService{ServiceID: serviceID, Name: "Serviço"})
+	}
+	return visit
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestMaintenanceUseCase_SavePlan(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates the plan of the service", func(t *testing.T) {
+		uc, repo, serviceRepo, _, _ := newMaintenanceTestUseCase()
+		serviceRepo.On("GetByID", ctx, uint(3)).Return(entities.Service{ID: 3, Name: "Troca de Óleo"}, nil)
+		repo.On("GetPlanByService", ctx, uint(3)).Return(nil, nil)
+		repo.On("SavePlan", ctx, mock.MatchedBy(func(plan *dto.MaintenancePlanDTO) bool {
+			return plan.ID == 0 && plan.ServiceID == 3 && plan.IntervalKm == 10000 && plan.IntervalMonths == 6
+		})).Return(nil)
+
+		plan, err := uc.SavePlan(ctx, 3, entities.MaintenancePlanRequest{IntervalKm: 10000, IntervalMonths: 6})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Troca de Óleo", plan.Service)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("replaces the intervals of an existing plan", func(t *testing.T) {
+		uc, repo, serviceRepo, _, _ := newMaintenanceTestUseCase()
+		serviceRepo.On("GetByID", ctx, uint(3)).Return(entities.Service{ID: 3, Name: "Troca de Óleo"}, nil)
+		repo.On("GetPlanByService", ctx, uint(3)).Return(&dto.MaintenancePlanDTO{ID: 8, ServiceID: 3, IntervalKm: 5000}, nil)
+		repo.On("SavePlan", ctx, mock.MatchedBy(func(plan *dto.MaintenancePlanDTO) bool {
+			return plan.ID == 8 && plan.IntervalKm == 0 && plan.IntervalMonths == 12
+		})).Return(nil)
+
+		_, err := uc.SavePlan(ctx, 3, entities.MaintenancePlanRequest{IntervalMonths: 12})
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a plan without an interval or with a negative one", func(t *testing.T) {
+		uc, _, _, _, _ := newMaintenanceTestUseCase()
+
+		_, err := uc.SavePlan(ctx, 3, entities.MaintenancePlanRequest{})
+		assert.ErrorIs(t, err, ErrInvalidMaintenancePlan)
+
+		_, err = uc.SavePlan(ctx, 3, entities.MaintenancePlanRequest{IntervalKm: -1, IntervalMonths: 6})
+		assert.ErrorIs(t, err, ErrInvalidMaintenancePlan)
+	})
+
+	t.Run("fails when the service does not exist", func(t *testing.T) {
+		uc, _, serviceRepo, _, _ := newMaintenanceTestUseCase()
+		serviceRepo.On("GetByID", ctx, uint(3)).Return(entities.Service{}, nil)
+
+		_, err := uc.SavePlan(ctx, 3, entities.MaintenancePlanRequest{IntervalKm: 10000})
+
+		assert.ErrorIs(t, err, ErrServiceNotFound)
+	})
+}
+
+func TestMaintenanceUseCase_DeletePlan(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes the plan of the service", func(t *testing.T) {
+		uc, repo, _, _, _ := newMaintenanceTestUseCase()
+		repo.On("GetPlanByService", ctx, uint(3)).Return(&dto.MaintenancePlanDTO{ID: 8, ServiceID: 3}, nil)
+		repo.On("DeletePlan", ctx, uint(8)).Return(nil)
+
+		require.NoError(t, uc.DeletePlan(ctx, 3))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("fails when the service has no plan", func(t *testing.T) {
+		uc, repo, _, _, _ := newMaintenanceTestUseCase()
+		repo.On("GetPlanByService", ctx, uint(3)).Return(nil, nil)
+
+		assert.ErrorIs(t, uc.DeletePlan(ctx, 3), ErrMaintenancePlanNotFound)
+	})
+}
+
+func TestMaintenanceUseCase_GetVehicleMaintenance(t *testing.T) {
+	ctx := context.Background()
+	plans := []dto.MaintenancePlanDTO{
+		{ID: 1, ServiceID: 10, Service: dto.ServiceDTO{ID: 10, Name: "Troca de Óleo"}, IntervalKm: 10000, IntervalMonths: 6},
+		{ID: 2, ServiceID: 20, Service: dto.ServiceDTO{ID: 20, Name: "Alinhamento"}, IntervalMonths: 12},
+		{ID: 3, ServiceID: 30, Service: dto.ServiceDTO{ID: 30, Name: "Correia dentada"}, IntervalKm: 60000},
+		{ID: 4, ServiceID: 40, Service: dto.ServiceDTO{ID: 40, Name: "Fluido de freio"}, IntervalMonths: 24},
+	}
+	history := &entities.VehicleHistory{
+		Vehicle: entities.Vehicle{ID: 5, CustomerID: 9},
+		Visits: []entities.VehicleVisit{
+			maintenanceVisit(100, valueobject.StatusEntregue, time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC), intPtr(40000), 10, 30),
+			maintenanceVisit(101, valueobject.StatusFinalizada, time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), intPtr(45000), 20),
+			maintenanceVisit(102, valueobject.StatusEmExecucao, time.Date(2025, 11, 20, 0, 0, 0, 0, time.UTC), intPtr(49700), 10, 40),
+		},
+		Summary: entities.VehicleMaintenanceSummary{LastOdometer: intPtr(49700)},
+	}
+
+	uc, repo, _, _, historyUseCase := newMaintenanceTestUseCase()
+	repo.On("ListPlans", ctx).Return(plans, nil)
+	historyUseCase.On("GetVehicleHistory", ctx, uint(5)).Return(history, nil)
+
+	result, err := uc.GetVehicleMaintenance(ctx, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, 49700, *result.CurrentOdometer)
+	require.Len(t, result.Items, 4)
+
+	oil := result.Items[0]
+	assert.Equal(t, uint(100), oil.LastPerformed.ServiceOrderID, "the order still in execution does not count")
+	assert.Equal(t, time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC), *oil.NextDueDate)
+	assert.Equal(t, 50000, *oil.NextDueOdometer)
+	assert.Equal(t, valueobject.MaintenanceDueSoon, oil.Status, "the mileage is within the lead, though the date is not yet")
+
+	alignment := result.Items[1]
+	assert.Nil(t, alignment.NextDueOdometer)
+	assert.Equal(t, valueobject.MaintenanceUpToDate, alignment.Status)
+
+	timingBelt := result.Items[2]
+	assert.Equal(t, 100000, *timingBelt.NextDueOdometer)
+	assert.Nil(t, timingBelt.NextDueDate)
+	assert.Equal(t, valueobject.MaintenanceUpToDate, timingBelt.Status)
+
+	brakeFluid := result.Items[3]
+	assert.Nil(t, brakeFluid.LastPerformed)
+	assert.Equal(t, valueobject.MaintenanceNoHistory, brakeFluid.Status)
+}
+
+func TestMaintenanceUseCase_SendReminders(t *testing.T) {
+	ctx := context.Background()
+	plans := []dto.MaintenancePlanDTO{
+		{ID: 1, ServiceID: 10, Service: dto.ServiceDTO{ID: 10, Name: "Troca de Óleo"}, IntervalKm: 10000, IntervalMonths: 6},
+		{ID: 2, ServiceID: 20, Service: dto.ServiceDTO{ID: 20, Name: "Alinhamento"}, IntervalMonths: 12},
+	}
+	history := func() *entities.VehicleHistory {
+		return &entities.VehicleHistory{
+			Vehicle: entities.Vehicle{ID: 5, CustomerID: 9, Brand: "Fiat", Model: "Argo", Plate: "ABC1D23"},
+			Visits: []entities.VehicleVisit{
+				maintenanceVisit(100, valueobject.StatusEntregue, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), intPtr(40000), 10, 20),
+			},
+			Summary: entities.VehicleMaintenanceSummary{LastOdometer: intPtr(40000)},
+		}
+	}
+	customer := &dto.CustomerDTO{ID: 9, FullName: "Maria Souza", PhoneNumber: "11999990000", User: &dto.UserDTO{Email: "maria@example.com"}}
+
+	t.Run("reminds the customer of the overdue maintenance on every channel", func(t *testing.T) {
+		email := &stubSender{channel: valueobject.NotificationEmail}
+		sms := &stubSender{channel: valueobject.NotificationSMS}
+		uc, repo, _, customerRepo, historyUseCase := newMaintenanceTestUseCase(email, sms)
+		repo.On("ListPlans", ctx).Return(plans, nil)
+		repo.On("ListVehiclesWithServices", ctx, []uint{10, 20}).Return([]uint{5}, nil)
+		historyUseCase.On("GetVehicleHistory", ctx, uint(5)).Return(history(), nil)
+		customerRepo.On("GetByID", uint(9)).Return(customer, nil)
+		repo.On("HasReminder", ctx, uint(5), uint(1), uint(100)).Return(false, nil)
+		repo.On("CreateReminder", ctx, mock.MatchedBy(func(reminder *dto.MaintenanceReminderDTO) bool {
+			return reminder.MaintenancePlanID == 1 && reminder.ServiceOrderID == 100 && reminder.Channels == "EMAIL,SMS" && reminder.SentAt.Equal(maintenanceNow)
+		})).Return(nil)
+
+		sent, err := uc.SendReminders(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent, "the alignment is not due yet")
+		require.Len(t, email.sent, 1)
+		assert.Equal(t, "maria@example.com", email.sent[0].Recipient)
+		assert.Contains(t, email.sent[0].Body, "Troca de Óleo")
+		assert.Contains(t, email.sent[0].Body, "até 01/11/2025 ou aos 50.000 km")
+		assert.Equal(t, "11999990000", sms.sent[0].Recipient)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("does not remind twice of the same maintenance", func(t *testing.T) {
+		email := &stubSender{channel: valueobject.NotificationEmail}
+		uc, repo, _, customerRepo, historyUseCase := newMaintenanceTestUseCase(email)
+		repo.On("ListPlans", ctx).Return(plans, nil)
+		repo.On("ListVehiclesWithServices", ctx, []uint{10, 20}).Return([]uint{5}, nil)
+		historyUseCase.On("GetVehicleHistory", ctx, uint(5)).Return(history(), nil)
+		customerRepo.On("GetByID", uint(9)).Return(customer, nil)
+		repo.On("HasReminder", ctx, uint(5), uint(1), uint(100)).Return(true, nil)
+
+		sent, err := uc.SendReminders(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+		assert.Empty(t, email.sent)
+		repo.AssertNotCalled(t, "CreateReminder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("leaves the reminder to the next run when no channel delivers it", func(t *testing.T) {
+		email := &stubSender{channel: valueobject.NotificationEmail, err: errors.New("smtp unavailable")}
+		uc, repo, _, customerRepo, historyUseCase := newMaintenanceTestUseCase(email)
+		repo.On("ListPlans", ctx).Return(plans, nil)
+		repo.On("ListVehiclesWithServices", ctx, []uint{10, 20}).Return([]uint{5}, nil)
+		historyUseCase.On("GetVehicleHistory", ctx, uint(5)).Return(history(), nil)
+		customerRepo.On("GetByID", uint(9)).Return(customer, nil)
+		repo.On("HasReminder", ctx, uint(5), uint(1), uint(100)).Return(false, nil)
+
+		sent, err := uc.SendReminders(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+		repo.AssertNotCalled(t, "CreateReminder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("does nothing without maintenance plans", func(t *testing.T) {
+		uc, repo, _, _, _ := newMaintenanceTestUseCase()
+		repo.On("ListPlans", ctx).Return([]dto.MaintenancePlanDTO{}, nil)
+
+		sent, err := uc.SendReminders(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+		repo.AssertNotCalled(t, "ListVehiclesWithServices", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFormatKm(t *testing.T) {
+	assert.Equal(t, "500", formatKm(500))
+	assert.Equal(t, "10.000", formatKm(10000))
+	assert.Equal(t, "1.250.000", formatKm(1250000))
+}
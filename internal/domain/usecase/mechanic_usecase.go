@@ -0,0 +1,444 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/repository/mechanic"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+)
+
+var (
+	ErrMechanicNotFound           = errors.New("mechanic not found")
+	ErrMechanicInactive           = errors.New("mechanic is inactive")
+	ErrInvalidMechanic            = errors.New("mechanic name is required")
+	ErrMechanicAlreadyAssigned    = errors.New("mechanic is already assigned to this service order or service")
+	ErrMechanicAssignmentNotFound = errors.New("mechanic assignment not found")
+	ErrMechanicNotAssigned        = errors.New("mechanic is not assigned to this service")
+	ErrServiceNotInServiceOrder   = errors.New("service is not part of the service order")
+	ErrServiceOrderNotInExecution = errors.New("service order is not in execution")
+	ErrMechanicAlreadyClockedIn   = errors.New("mechanic is already clocked in on a service")
+	ErrMechanicNotClockedIn       = errors.New("mechanic is not clocked in on this service")
+)
+
+type IMechanicUseCase interface {
+	CreateMechanic(ctx context.Context, mechanic entities.Mechanic) (*entities.Mechanic, error)
+	GetMechanic(ctx context.Context, id uint) (*entities.Mechanic, error)
+	ListMechanics(ctx context.Context) ([]entities.Mechanic, error)
+	UpdateMechanic(ctx context.Context, mechanic entities.Mechanic) (*entities.Mechanic, error)
+	DeleteMechanic(ctx context.Context, id uint) error
+	AssignMechanic(ctx context.Context, assignment entities.MechanicAssignment) (*entities.MechanicAssignment, error)
+	UnassignMechanic(ctx context.Context, serviceOrderID, assignmentID uint) error
+	ClockIn(ctx context.Context, serviceOrderID, serviceID, mechanicID uint) (*entities.LabourEntry, error)
+	ClockOut(ctx context.Context, serviceOrderID, serviceID, mechanicID uint) (*entities.LabourEntry, error)
+	GetServiceOrderLabour(ctx context.Context, serviceOrderID uint) (*entities.ServiceOrderLabour, error)
+	GetProductivityReport(ctx context.Context, from, to time.Time) (*entities.ProductivityReport, error)
+}
+
+type MechanicUseCase struct {
+	repo             mechanic.IMechanicRepository
+	serviceOrderRepo serviceorder.IServiceOrderRepository
+	now              func() time.Time
+}
+
+var _ IMechanicUseCase = (*MechanicUseCase)(nil)
+
+func NewMechanicUseCase(repo mechanic.IMechanicRepository, serviceOrderRepo serviceorder.IServiceOrderRepository) *MechanicUseCase {
+	return &MechanicUseCase{
+		repo:             repo,
+		serviceOrderRepo: serviceOrderRepo,
+		now:              time.Now,
+	}
+}
+
+func (u *MechanicUseCase) CreateMechanic(ctx context.Context, mechanic entities.Mechanic) (*entities.Mechanic, error) {
+	if strings.TrimSpace(mechanic.Name) == "" {
+		return nil, ErrInvalidMechanic
+	}
+	mechanicDto := dto.MechanicDTO{
+		Name:        strings.TrimSpace(mechanic.Name),
+		Email:       mechanic.Email,
+		PhoneNumber: mechanic.PhoneNumber,
+		Specialty:   mechanic.Specialty,
+		Active:      true,
+	}
+	if err := u.repo.Create(ctx, &mechanicDto); err != nil {
+		log.Error().Msgf("Error creating mechanic: %v", err)
+		return nil, err
+	}
+	result := mechanicDto.ToDomain()
+	return &result, nil
+}
+
+func (u *MechanicUseCase) GetMechanic(ctx context.Context, id uint) (*entities.Mechanic, error) {
+	mechanicDto, err := u.findMechanic(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := mechanicDto.ToDomain()
+	return &result, nil
+}
+
+func (u *MechanicUseCase) ListMechanics(ctx context.Context) ([]entities.Mechanic, error) {
+	mechanics, err := u.repo.List(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing mechanics: %v", err)
+		return nil, err
+	}
+	result := make([]entities.Mechanic, 0, len(mechanics))
+	for _, mechanic := range mechanics {
+		result = append(result, mechanic.ToDomain())
+	}
+	return result, nil
+}
+
+// UpdateMechanic changes the record of a mechanic; inactive mechanics keep their history but cannot
+// be assigned to new orders
+func (u *MechanicUseCase) UpdateMechanic(ctx context.Context, mechanic entities.Mechanic) (*entities.Mechanic, error) {
+	existing, err := u.findMechanic(ctx, mechanic.ID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(mechanic.Name) == "" {
+		return nil, ErrInvalidMechanic
+	}
+	existing.Name = strings.TrimSpace(mechanic.Name)
+	existing.Email = mechanic.Email
+	existing.PhoneNumber = mechanic.PhoneNumber
+	existing.Specialty = mechanic.Specialty
+	existing.Active = mechanic.Active
+	if err := u.repo.Update(ctx, existing); err != nil {
+		log.Error().Msgf("Error updating mechanic %d: %v", mechanic.ID, err)
+		return nil, err
+	}
+	result := existing.ToDomain()
+	return &result, nil
+}
+
+func (u *MechanicUseCase) DeleteMechanic(ctx context.Context, id uint) error {
+	if _, err := u.findMechanic(ctx, id); err != nil {
+		return err
+	}
+	if err := u.repo.Delete(ctx, id); err != nil {
+		log.Error().Msgf("Error deleting mechanic %d: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// AssignMechanic puts an active mechanic on an open service order, on the whole order or on one of its services
+func (u *MechanicUseCase) AssignMechanic(ctx context.Context, assignment entities.MechanicAssignment) (*entities.MechanicAssignment, error) {
+	serviceOrderDto, err := u.findServiceOrder(assignment.ServiceOrderID)
+	if err != nil {
+		return nil, err
+	}
+	status := serviceOrderDto.ServiceOrderStatus.ToDomain()
+	if status.IsEntregue() || status.IsCancelada() {
+		return nil, ErrServiceOrderClosed
+	}
+	if assignment.ServiceID != nil && findServiceOrderService(serviceOrderDto, *assignment.ServiceID) == nil {
+		return nil, ErrServiceNotInServiceOrder
+	}
+
+	mechanicDto, err := u.findMechanic(ctx, assignment.MechanicID)
+	if err != nil {
+		return nil, err
+	}
+	if !mechanicDto.Active {
+		return nil, ErrMechanicInactive
+	}
+
+	assignments, err := u.repo.ListAssignments(ctx, assignment.ServiceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error listing mechanics of service order %d: %v", assignment.ServiceOrderID, err)
+		return nil, err
+	}
+	for _, existing := range assignments {
+		if existing.MechanicID == assignment.MechanicID && sameService(existing.ServiceID, assignment.ServiceID) {
+			return nil, ErrMechanicAlreadyAssigned
+		}
+	}
+
+	assignmentDto := dto.MechanicAssignmentDTO{
+		ServiceOrderID: assignment.ServiceOrderID,
+		MechanicID:     assignment.MechanicID,
+		ServiceID:      assignment.ServiceID,
+		AssignedBy:     assignment.AssignedBy,
+		AssignedAt:     u.now(),
+	}
+	if err := u.repo.CreateAssignment(ctx, &assignmentDto); err != nil {
+		log.Error().Msgf("Error assigning mechanic %d to service order %d: %v", assignment.MechanicID, assignment.ServiceOrderID, err)
+		return nil, err
+	}
+	assignmentDto.Mechanic = *mechanicDto
+	result := assignmentDto.ToDomain()
+	return &result, nil
+}
+
+func (u *MechanicUseCase) UnassignMechanic(ctx context.Context, serviceOrderID, assignmentID uint) error {
+	assignment, err := u.repo.GetAssignment(ctx, assignmentID)
+	if err != nil {
+		log.Error().Msgf("Error finding mechanic assignment %d: %v", assignmentID, err)
+		return err
+	}
+	if assignment == nil || assignment.ServiceOrderID != serviceOrderID {
+		return ErrMechanicAssignmentNotFound
+	}
+	if err := u.repo.DeleteAssignment(ctx, assignmentID); err != nil {
+		log.Error().Msgf("Error removing mechanic assignment %d: %v", assignmentID, err)
+		return err
+	}
+	return nil
+}
+
+// ClockIn starts the time of a mechanic on a service of an order in execution. The mechanic must be
+// assigned to the order or to the service, and can only be clocked in on one service at a time.
+func (u *MechanicUseCase) ClockIn(ctx context.Context, serviceOrderID, serviceID, mechanicID uint) (*entities.LabourEntry, error) {
+	serviceOrderDto, err := u.findServiceOrder(serviceOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !serviceOrderDto.ServiceOrderStatus.ToDomain().IsEmExecucao() {
+		return nil, ErrServiceOrderNotInExecution
+	}
+	if findServiceOrderService(serviceOrderDto, serviceID) == nil {
+		return nil, ErrServiceNotInServiceOrder
+	}
+
+	assignments, err := u.repo.ListAssignments(ctx, serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error listing mechanics of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	if !isAssigned(assignments, mechanicID, serviceID) {
+		return nil, ErrMechanicNotAssigned
+	}
+
+	open, err := u.repo.GetOpenEntry(ctx, mechanicID)
+	if err != nil {
+		log.Error().Msgf("Error finding open labour entry of mechanic %d: %v", mechanicID, err)
+		return nil, err
+	}
+	if open != nil {
+		return nil, ErrMechanicAlreadyClockedIn
+	}
+
+	entry := dto.LabourEntryDTO{
+		ServiceOrderID: serviceOrderID,
+		ServiceID:      serviceID,
+		MechanicID:     mechanicID,
+		ClockIn:        u.now(),
+	}
+	if err := u.repo.CreateEntry(ctx, &entry); err != nil {
+		log.Error().Msgf("Error clocking in mechanic %d: %v", mechanicID, err)
+		return nil, err
+	}
+	result := entry.ToDomain()
+	return &result, nil
+}
+
+// ClockOut stops the time of a mechanic on the service they are clocked in on
+func (u *MechanicUseCase) ClockOut(ctx context.Context, serviceOrderID, serviceID, mechanicID uint) (*entities.LabourEntry, error) {
+	open, err := u.repo.GetOpenEntry(ctx, mechanicID)
+	if err != nil {
+		log.Error().Msgf("Error finding open labour entry of mechanic %d: %v", mechanicID, err)
+		return nil, err
+	}
+	if open == nil || open.ServiceOrderID != serviceOrderID || open.ServiceID != serviceID {
+		return nil, ErrMechanicNotClockedIn
+	}
+
+	clockOut := u.now()
+	open.ClockOut = &clockOut
+	open.Minutes = int(math.Round(clockOut.Sub(open.ClockIn).Minutes()))
+	if err := u.repo.CloseEntry(ctx, open); err != nil {
+		log.Error().Msgf("Error clocking out mechanic %d: %v", mechanicID, err)
+		return nil, err
+	}
+	result := open.ToDomain()
+	return &result, nil
+}
+
+// GetServiceOrderLabour lists the mechanics of a service order and compares the time spent on each
+// of its services with the standard time of the service. Open entries count up to now.
+func (u *MechanicUseCase) GetServiceOrderLabour(ctx context.Context, serviceOrderID uint) (*entities.ServiceOrderLabour, error) {
+	serviceOrderDto, err := u.findServiceOrder(serviceOrderID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := u.repo.ListAssignments(ctx, serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error listing mechanics of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	entries, err := u.repo.ListEntries(ctx, serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error listing labour entries of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+
+	labour := &entities.ServiceOrderLabour{
+		ServiceOrderID: serviceOrderID,
+		Assignments:    make([]entities.MechanicAssignment, 0, len(assignments)),
+		Tasks:          make([]entities.LabourTask, 0, len(serviceOrderDto.Services)),
+		Entries:        make([]entities.LabourEntry, 0, len(entries)),
+	}
+	for _, assignment := range assignments {
+		labour.Assignments = append(labour.Assignments, assignment.ToDomain())
+	}
+
+	now := u.now()
+	actual := make(map[uint]int)
+	inProgress := make(map[uint]bool)
+	for _, entry := range entries {
+		labour.Entries = append(labour.Entries, entry.ToDomain())
+		if entry.ClockOut == nil {
+			actual[entry.ServiceID] += int(math.Round(now.Sub(entry.ClockIn).Minutes()))
+			inProgress[entry.ServiceID] = true
+			continue
+		}
+		actual[entry.ServiceID] += entry.Minutes
+	}
+	for _, service := range serviceOrderDto.Services {
+		labour.Tasks = append(labour.Tasks, entities.LabourTask{
+			ServiceID:       service.ID,
+			ServiceName:     service.Name,
+			StandardMinutes: service.StandardMinutes,
+			ActualMinutes:   actual[service.ID],
+			VarianceMinutes: actual[service.ID] - service.StandardMinutes,
+			InProgress:      inProgress[service.ID],
+		})
+	}
+	return labour, nil
+}
+
+// GetProductivityReport sums the closed entries clocked in within the period per mechanic. The
+// standard time of a service is credited to the mechanics who worked on it in proportion to the time
+// each one spent, counting only the entries of the period.
+func (u *MechanicUseCase) GetProductivityReport(ctx context.Context, from, to time.Time) (*entities.ProductivityReport, error) {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return nil, ErrInvalidReportPeriod
+	}
+	entries, err := u.repo.ListClosedEntries(ctx, from, to)
+	if err != nil {
+		log.Error().Msgf("Error listing labour entries: %v", err)
+		return nil, err
+	}
+
+	type task struct{ serviceOrderID, serviceID uint }
+	taskMinutes := make(map[task]int)
+	for _, entry := range entries {
+		taskMinutes[task{entry.ServiceOrderID, entry.ServiceID}] += entry.Minutes
+	}
+
+	type mechanicTotals struct {
+		productivity  entities.MechanicProductivity
+		serviceOrders map[uint]bool
+		tasks         map[task]bool
+	}
+	byMechanic := make(map[uint]*mechanicTotals)
+	for _, entry := range entries {
+		totals, ok := byMechanic[entry.MechanicID]
+		if !ok {
+			totals = &mechanicTotals{
+				productivity: entities.MechanicProductivity{
+					MechanicID:   entry.MechanicID,
+					MechanicName: entry.Mechanic.Name,
+				},
+				serviceOrders: make(map[uint]bool),
+				tasks:         make(map[task]bool),
+			}
+			byMechanic[entry.MechanicID] = totals
+		}
+		key := task{entry.ServiceOrderID, entry.ServiceID}
+		totals.serviceOrders[entry.ServiceOrderID] = true
+		totals.tasks[key] = true
+		totals.productivity.WorkedMinutes += entry.Minutes
+		if entry.Service.StandardMinutes > 0 && taskMinutes[key] > 0 {
+			totals.productivity.MeasuredMinutes += entry.Minutes
+			totals.productivity.StandardMinutes += float64(entry.Service.StandardMinutes) * float64(entry.Minutes) / float64(taskMinutes[key])
+		}
+	}
+
+	report := &entities.ProductivityReport{
+		From:      from,
+		To:        to,
+		Mechanics: make([]entities.MechanicProductivity, 0, len(byMechanic)),
+	}
+	for _, totals := range byMechanic {
+		productivity := totals.productivity
+		productivity.ServiceOrders = len(totals.serviceOrders)
+		productivity.Tasks = len(totals.tasks)
+		productivity.StandardMinutes = roundTwoDecimals(productivity.StandardMinutes)
+		if productivity.MeasuredMinutes > 0 {
+			productivity.Efficiency = roundTwoDecimals(productivity.StandardMinutes / float64(productivity.MeasuredMinutes) * 100)
+		}
+		report.Mechanics = append(report.Mechanics, productivity)
+	}
+	sort.Slice(report.Mechanics, func(i, j int) bool {
+		return report.Mechanics[i].MechanicName < report.Mechanics[j].MechanicName
+	})
+	return report, nil
+}
+
+func (u *MechanicUseCase) findMechanic(ctx context.Context, id uint) (*dto.MechanicDTO, error) {
+	mechanicDto, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Error().Msgf("Error finding mechanic %d: %v", id, err)
+		return nil, err
+	}
+	if mechanicDto == nil {
+		return nil, ErrMechanicNotFound
+	}
+	return mechanicDto, nil
+}
+
+func (u *MechanicUseCase) findServiceOrder(id uint) (*dto.ServiceOrderDTO, error) {
+	serviceOrderDto, err := u.serviceOrderRepo.GetByIDWithItems(id)
+	if err != nil {
+		log.Error().Msgf("Error finding service order with id %d: %v", id, err)
+		return nil, err
+	}
+	if serviceOrderDto == nil {
+		return nil, ErrServiceOrderNotFound
+	}
+	return serviceOrderDto, nil
+}
+
+func findServiceOrderService(serviceOrder *dto.ServiceOrderDTO, serviceID uint) *dto.ServiceDTO {
+	for i := range serviceOrder.Services {
+		if serviceOrder.Services[i].ID == serviceID {
+			return &serviceOrder.Services[i]
+		}
+	}
+	return nil
+}
+
+// isAssigned tells whether the mechanic is assigned to the whole order or to the service
+func isAssigned(assignments []dto.MechanicAssignmentDTO, mechanicID, serviceID uint) bool {
+	for _, assignment := range assignments {
+		if assignment.MechanicID == mechanicID && (assignment.ServiceID == nil || *assignment.ServiceID == serviceID) {
+			return true
+		}
+	}
+	return false
+}
+
+func roundTwoDecimals(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func sameService(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
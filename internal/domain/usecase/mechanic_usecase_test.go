@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+var mechanicTestNow = time.Date(2024, 5, 10, 14, 0, 0, 0, time.UTC)
+
+func newMechanicTestUseCase() (*MechanicUseCase, *mocks.MockMechanicRepository, *MockServiceOrderRepository) {
+	repo := new(mocks.MockMechanicRepository)
+	serviceOrderRepo := new(MockServiceOrderRepository)
+	uc := NewMechanicUseCase(repo, serviceOrderRepo)
+	uc.now = func() time.Time { return mechanicTestNow }
+	return uc, repo, serviceOrderRepo
+}
+
+func labourServiceOrder(status valueobject.ServiceOrderStatus) *dto.ServiceOrderDTO {
+	return &dto.ServiceOrderDTO{
+		ID:                 7,
+		ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: status.String()},
+		Services: []dto.ServiceDTO{
+			{ID: 3, Name: "Troca de óleo", StandardMinutes: 30},
+			{ID: 4, Name: "Alinhamento", StandardMinutes: 60},
+		},
+	}
+}
+
+func TestMechanicUseCase_AssignMechanic(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("assigns an active mechanic to a service of the order", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newMechanicTestUseCase()
+		serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusEmExecucao), nil)
+		repo.On("GetByID", ctx, uint(2)).Return(&dto.MechanicDTO{ID: 2, Name: "João", Active: true}, nil)
+		repo.On("ListAssignments", ctx, uint(7)).Return([]dto.MechanicAssignmentDTO{{MechanicID: 2}}, nil)
+		repo.On("CreateAssignment", ctx, mock.MatchedBy(func(a *dto.MechanicAssignmentDTO) bool {
+			return a.MechanicID == 2 && *a.ServiceID == 3 && a.AssignedBy == "chefe@xpto.com" && a.AssignedAt.Equal(mechanicTestNow)
+		})).Return(nil)
+
+		result, err := uc.AssignMechanic(ctx, entities.MechanicAssignment{
+			ServiceOrderID: 7, MechanicID: 2, ServiceID: uintPtr(3), AssignedBy: "chefe@xpto.com",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "João", result.Mechanic.Name)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a duplicated assignment", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newMechanicTestUseCase()
+		serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusEmExecucao), nil)
+		repo.On("GetByID", ctx, uint(2)).Return(&dto.MechanicDTO{ID: 2, Active: true}, nil)
+		repo.On("ListAssignments", ctx, uint(7)).Return([]dto.MechanicAssignmentDTO{{MechanicID: 2}}, nil)
+
+		_, err := uc.AssignMechanic(ctx, entities.MechanicAssignment{ServiceOrderID: 7, MechanicID: 2})
+
+		assert.ErrorIs(t, err, ErrMechanicAlreadyAssigned)
+	})
+
+	t.Run("rejects a service that is not on the order", func(t *testing.T) {
+		uc, _, serviceOrderRepo := newMechanicTestUseCase()
+		serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusEmExecucao), nil)
+
+		_, err := uc.AssignMechanic(ctx, entities.MechanicAssignment{ServiceOrderID: 7, MechanicID: 2, ServiceID: uintPtr(9)})
+
+		assert.ErrorIs(t, err, ErrServiceNotInServiceOrder)
+	})
+
+	t.Run("rejects inactive mechanics", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newMechanicTestUseCase()
+		serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusRecebida), nil)
+		repo.On("GetByID", ctx, uint(2)).Return(&dto.MechanicDTO{ID: 2, Active: false}, nil)
+
+		_, err := uc.AssignMechanic(ctx, entities.MechanicAssignment{ServiceOrderID: 7, MechanicID: 2})
+
+		assert.ErrorIs(t, err, ErrMechanicInactive)
+	})
+
+	t.Run("rejects closed orders", func(t *testing.T) {
+		uc, _, serviceOrderRepo := newMechanicTestUseCase()
+		serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusEntregue), nil)
+
+		_, err := uc.AssignMechanic(ctx, entities.MechanicAssignment{ServiceOrderID: 7, MechanicID: 2})
+
+		assert.ErrorIs(t, err, ErrServiceOrderClosed)
+	})
+}
+
+func TestMechanicUseCase_ClockIn(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("clocks in a mechanic assigned to the whole order", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newMechanicTestUseCase()
+		serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusEmExecucao), nil)
+		repo.On("ListAssignments", ctx, uint(7)).Return([]dto.MechanicAssignmentDTO{{MechanicID: 2}}, nil)
+		repo.On("GetOpenEntry", ctx, uint(2)).Return(nil, nil)
+		repo.On("CreateEntry", ctx, mock.MatchedBy(func(e *dto.LabourEntryDTO) bool {
+			return e.ServiceOrderID == 7 && e.ServiceID == 4 && e.MechanicID == 2 && e.ClockIn.Equal(mechanicTestNow)
+		})).Return(nil)
+
+		entry, err := uc.ClockIn(ctx, 7, 4, 2)
+
+		require.NoError(t, err)
+		assert.Nil(t, entry.ClockOut)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("requires the order to be in execution", func(t *testing.T) {
+		uc, _, serviceOrderRepo := newMechanicTestUseCase()
+		serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusAprovada), nil)
+
+		_, err := uc.ClockIn(ctx, 7, 4, 2)
+
+		assert.ErrorIs(t, err, ErrServiceOrderNotInExecution)
+	})
+
+	t.Run("requires an assignment to the order or the service", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newMechanicTestUseCase()
+		serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusEmExecucao), nil)
+		repo.On("ListAssignments", ctx, uint(7)).Return([]dto.MechanicAssignmentDTO{{MechanicID: 2, ServiceID: uintPtr(3)}}, nil)
+
+		_, err := uc.ClockIn(ctx, 7, 4, 2)
+
+		assert.ErrorIs(t, err, ErrMechanicNotAssigned)
+	})
+
+	t.Run("rejects a mechanic already clocked in", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newMechanicTestUseCase()
+		serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusEmExecucao), nil)
+		repo.On("ListAssignments", ctx, uint(7)).Return([]dto.MechanicAssignmentDTO{{MechanicID: 2, ServiceID: uintPtr(4)}}, nil)
+		repo.On("GetOpenEntry", ctx, uint(2)).Return(&dto.LabourEntryDTO{ID: 1, ServiceOrderID: 8}, nil)
+
+		_, err := uc.ClockIn(ctx, 7, 4, 2)
+
+		assert.ErrorIs(t, err, ErrMechanicAlreadyClockedIn)
+	})
+}
+
+func TestMechanicUseCase_ClockOut(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records the minutes worked", func(t *testing.T) {
+		uc, repo, _ := newMechanicTestUseCase()
+		repo.On("GetOpenEntry", ctx, uint(2)).Return(&dto.LabourEntryDTO{
+			ID: 1, ServiceOrderID: 7, ServiceID: 4, MechanicID: 2, ClockIn: mechanicTestNow.Add(-95*time.Minute - 20*time.Second),
+		}, nil)
+		repo.On("CloseEntry", ctx, mock.MatchedBy(func(e *dto.LabourEntryDTO) bool {
+			return e.ID == 1 && e.Minutes == 95 && e.ClockOut.Equal(mechanicTestNow)
+		})).Return(nil)
+
+		entry, err := uc.ClockOut(ctx, 7, 4, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, 95, entry.Minutes)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a clock-out on another service", func(t *testing.T) {
+		uc, repo, _ := newMechanicTestUseCase()
+		repo.On("GetOpenEntry", ctx, uint(2)).Return(&dto.LabourEntryDTO{ID: 1, ServiceOrderID: 7, ServiceID: 3}, nil)
+
+		_, err := uc.ClockOut(ctx, 7, 4, 2)
+
+		assert.ErrorIs(t, err, ErrMechanicNotClockedIn)
+	})
+}
+
+func TestMechanicUseCase_GetServiceOrderLabour(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, serviceOrderRepo := newMechanicTestUseCase()
+	serviceOrderRepo.On("GetByIDWithItems", uint(7)).Return(labourServiceOrder(valueobject.StatusEmExecucao), nil)
+	repo.On("ListAssignments", ctx, uint(7)).Return([]dto.MechanicAssignmentDTO{{ID: 1, MechanicID: 2}}, nil)
+	clockOut := mechanicTestNow.Add(-time.Hour)
+	repo.On("ListEntries", ctx, uint(7)).Return([]dto.LabourEntryDTO{
+		{ID: 1, ServiceID: 3, MechanicID: 2, ClockIn: clockOut.Add(-40 * time.Minute), ClockOut: &clockOut, Minutes: 40},
+		{ID: 2, ServiceID: 4, MechanicID: 2, ClockIn: mechanicTestNow.Add(-20 * time.Minute)},
+	}, nil)
+
+	labour, err := uc.GetServiceOrderLabour(ctx, 7)
+
+	require.NoError(t, err)
+	require.Len(t, labour.Tasks, 2)
+	assert.Equal(t, entities.LabourTask{ServiceID: 3, ServiceName: "Troca de óleo", StandardMinutes: 30, ActualMinutes: 40, VarianceMinutes: 10}, labour.Tasks[0])
+	assert.Equal(t, entities.LabourTask{ServiceID: 4, ServiceName: "Alinhamento", StandardMinutes: 60, ActualMinutes: 20, VarianceMinutes: -40, InProgress: true}, labour.Tasks[1])
+	assert.Len(t, labour.Entries, 2)
+	assert.Len(t, labour.Assignments, 1)
+}
+
+func TestMechanicUseCase_GetProductivityReport(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("credits the standard time in proportion to the time spent", func(t *testing.T) {
+		uc, repo, _ := newMechanicTestUseCase()
+		oilChange := dto.ServiceDTO{ID: 3, StandardMinutes: 30}
+		alignment := dto.ServiceDTO{ID: 4, StandardMinutes: 60}
+		washing := dto.ServiceDTO{ID: 5}
+		joao := dto.MechanicDTO{ID: 2, Name: "João"}
+		ana := dto.MechanicDTO{ID: 1, Name: "Ana"}
+		repo.On("ListClosedEntries", ctx, from, to).Return([]dto.LabourEntryDTO{
+			{ServiceOrderID: 7, ServiceID: 3, Service: oilChange, MechanicID: 2, Mechanic: joao, Minutes: 25},
+			{ServiceOrderID: 7, ServiceID: 4, Service: alignment, MechanicID: 2, Mechanic: joao, Minutes: 60},
+			{ServiceOrderID: 7, ServiceID: 4, Service: alignment, MechanicID: 1, Mechanic: ana, Minutes: 20},
+			{ServiceOrderID: 8, ServiceID: 5, Service: washing, MechanicID: 1, Mechanic: ana, Minutes: 15},
+		}, nil)
+
+		report, err := uc.GetProductivityReport(ctx, from, to)
+
+		require.NoError(t, err)
+		require.Len(t, report.Mechanics, 2)
+		assert.Equal(t, entities.MechanicProductivity{
+			MechanicID: 1, MechanicName: "Ana", ServiceOrders: 2, Tasks: 2,
+			WorkedMinutes: 35, MeasuredMinutes: 20, StandardMinutes: 15, Efficiency: 75,
+		}, report.Mechanics[0])
+		assert.Equal(t, entities.MechanicProductivity{
+			MechanicID: 2, MechanicName: "João", ServiceOrders: 1, Tasks: 2,
+			WorkedMinutes: 85, MeasuredMinutes: 85, StandardMinutes: 75, Efficiency: 88.24,
+		}, report.Mechanics[1])
+	})
+
+	t.Run("rejects an inverted period", func(t *testing.T) {
+		uc, _, _ := newMechanicTestUseCase()
+
+		_, err := uc.GetProductivityReport(ctx, to, from)
+
+		assert.ErrorIs(t, err, ErrInvalidReportPeriod)
+	})
+}
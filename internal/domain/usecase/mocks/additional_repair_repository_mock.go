@@ -7,6 +7,7 @@ package mocks
 import (
 	dto "mecanica_xpto/internal/domain/model/dto"
 	entities "mecanica_xpto/internal/domain/model/entities"
+	valueobject "mecanica_xpto/internal/domain/model/valueobject"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,20 @@ func (m *MockIAdditionalRepairRepository) EXPECT() *MockIAdditionalRepairReposit
 	return m.recorder
 }
 
+// AddHistory mocks base method.
+func (m *MockIAdditionalRepairRepository) AddHistory(entry *dto.AdditionalRepairHistoryDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddHistory", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddHistory indicates an expected call of AddHistory.
+func (mr *MockIAdditionalRepairRepositoryMockRecorder) AddHistory(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddHistory", reflect.TypeOf((*MockIAdditionalRepairRepository)(nil).AddHistory), entry)
+}
+
 // AddPartSupplyAndService mocks base method.
 func (m *MockIAdditionalRepairRepository) AddPartSupplyAndService(additionalRepair, updatedAdditionalRepair *dto.AdditionalRepairDTO) error {
 	m.ctrl.T.Helper()
@@ -49,32 +64,42 @@ func (mr *MockIAdditionalRepairRepositoryMockRecorder) AddPartSupplyAndService(a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPartSupplyAndService", reflect.TypeOf((*MockIAdditionalRepairRepository)(nil).AddPartSupplyAndService), additionalRepair, updatedAdditionalRepair)
 }
 
-// Create mocks base method.
-func (m *MockIAdditionalRepairRepository) Create(additionalRepair *dto.AdditionalRepairDTO) error {
+// Approve mocks base method.
+func (m *MockIAdditionalRepairRepository) Approve(additionalRepair *dto.AdditionalRepairDTO, events ...entities.DomainEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", additionalRepair)
+	varargs := []interface{}{additionalRepair}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Approve", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Create indicates an expected call of Create.
-func (mr *MockIAdditionalRepairRepositoryMockRecorder) Create(additionalRepair interface{}) *gomock.Call {
+// Approve indicates an expected call of Approve.
+func (mr *MockIAdditionalRepairRepositoryMockRecorder) Approve(additionalRepair interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIAdditionalRepairRepository)(nil).Create), additionalRepair)
+	varargs := append([]interface{}{additionalRepair}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Approve", reflect.TypeOf((*MockIAdditionalRepairRepository)(nil).Approve), varargs...)
 }
 
-// CustomerApprovalStatus mocks base method.
-func (m *MockIAdditionalRepairRepository) CustomerApprovalStatus(id uint, status entities.AdditionalRepairStatusDTO) error {
+// Create mocks base method.
+func (m *MockIAdditionalRepairRepository) Create(additionalRepair *dto.AdditionalRepairDTO, events ...entities.DomainEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CustomerApprovalStatus", id, status)
+	varargs := []interface{}{additionalRepair}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CustomerApprovalStatus indicates an expected call of CustomerApprovalStatus.
-func (mr *MockIAdditionalRepairRepositoryMockRecorder) CustomerApprovalStatus(id, status interface{}) *gomock.Call {
+// Create indicates an expected call of Create.
+func (mr *MockIAdditionalRepairRepositoryMockRecorder) Create(additionalRepair interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomerApprovalStatus", reflect.TypeOf((*MockIAdditionalRepairRepository)(nil).CustomerApprovalStatus), id, status)
+	varargs := append([]interface{}{additionalRepair}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIAdditionalRepairRepository)(nil).Create), varargs...)
 }
 
 // GetByID mocks base method.
@@ -135,3 +160,22 @@ func (mr *MockIAdditionalRepairRepositoryMockRecorder) RemovePartSupplyAndServic
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePartSupplyAndService", reflect.TypeOf((*MockIAdditionalRepairRepository)(nil).RemovePartSupplyAndService), additionalRepair, updatedAdditionalRepair)
 }
+
+// UpdateStatus mocks base method.
+func (m *MockIAdditionalRepairRepository) UpdateStatus(id uint, status valueobject.AdditionalRepairStatus, events ...entities.DomainEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{id, status}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateStatus", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockIAdditionalRepairRepositoryMockRecorder) UpdateStatus(id, status interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{id, status}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockIAdditionalRepairRepository)(nil).UpdateStatus), varargs...)
+}
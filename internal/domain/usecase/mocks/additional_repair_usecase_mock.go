@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Additional Repair UseCase
+type MockAdditionalRepairUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAdditionalRepairUseCase) CreateAdditionalRepair(ctx context.Context, adr entities.AdditionalRepair) error {
+	args := m.Called(ctx, adr)
+	return args.Error(0)
+}
+
+func (m *MockAdditionalRepairUseCase) AddPartSupplyAndService(ctx context.Context, adrId uint, adr entities.AdditionalRepair) error {
+	args := m.Called(ctx, adrId, adr)
+	return args.Error(0)
+}
+
+func (m *MockAdditionalRepairUseCase) RemovePartSupplyAndService(ctx context.Context, adrId uint, adr entities.AdditionalRepair) error {
+	args := m.Called(ctx, adrId, adr)
+	return args.Error(0)
+}
+
+func (m *MockAdditionalRepairUseCase) GetAdditionalRepair(ctx context.Context, additionalRepairId uint) (entities.AdditionalRepair, error) {
+	args := m.Called(ctx, additionalRepairId)
+	return args.Get(0).(entities.AdditionalRepair), args.Error(1)
+}
+
+func (m *MockAdditionalRepairUseCase) SubmitForApproval(ctx context.Context, additionalRepairId uint) error {
+	args := m.Called(ctx, additionalRepairId)
+	return args.Error(0)
+}
+
+func (m *MockAdditionalRepairUseCase) CustomerApprovalStatus(ctx context.Context, additionalRepairId uint, status entities.AdditionalRepairStatusDTO) error {
+	args := m.Called(ctx, additionalRepairId, status)
+	return args.Error(0)
+}
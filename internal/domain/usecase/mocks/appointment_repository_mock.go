@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Appointment Repository
+type MockAppointmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAppointmentRepository) CreateBay(ctx context.Context, bay *dto.BayDTO) error {
+	args := m.Called(ctx, bay)
+	return args.Error(0)
+}
+
+func (m *MockAppointmentRepository) GetBay(ctx context.Context, id uint) (*dto.BayDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BayDTO), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) ListBays(ctx context.Context, activeOnly bool) ([]dto.BayDTO, error) {
+	args := m.Called(ctx, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.BayDTO), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) UpdateBay(ctx context.Context, bay *dto.BayDTO) error {
+	args := m.Called(ctx, bay)
+	return args.Error(0)
+}
+
+func (m *MockAppointmentRepository) Save(ctx context.Context, appointment *dto.AppointmentDTO) (bool, error) {
+	args := m.Called(ctx, appointment)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) GetByID(ctx context.Context, id uint) (*dto.AppointmentDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AppointmentDTO), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) List(ctx context.Context, from, to time.Time) ([]dto.AppointmentDTO, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.AppointmentDTO), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) ListScheduled(ctx context.Context, from, to time.Time) ([]dto.AppointmentDTO, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.AppointmentDTO), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) UpdateStatus(ctx context.Context, id uint, status valueobject.AppointmentStatus, serviceOrderID *uint) error {
+	args := m.Called(ctx, id, status, serviceOrderID)
+	return args.Error(0)
+}
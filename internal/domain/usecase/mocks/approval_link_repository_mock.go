@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Approval Link Repository
+type MockApprovalLinkRepository struct {
+	mock.Mock
+}
+
+func (m *MockApprovalLinkRepository) Create(ctx context.Context, link *dto.ApprovalLinkDTO) error {
+	args := m.Called(ctx, link)
+	return args.Error(0)
+}
+
+func (m *MockApprovalLinkRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*dto.ApprovalLinkDTO, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ApprovalLinkDTO), args.Error(1)
+}
+
+func (m *MockApprovalLinkRepository) ListByServiceOrderID(ctx context.Context, serviceOrderID uint) ([]dto.ApprovalLinkDTO, error) {
+	args := m.Called(ctx, serviceOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.ApprovalLinkDTO), args.Error(1)
+}
+
+func (m *MockApprovalLinkRepository) MarkUsed(ctx context.Context, id uint, decision string, consent entities.ApprovalConsent, decidedAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, decision, consent, decidedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockApprovalLinkRepository) ReleaseUse(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/valueobject"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Attachment Repository
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Create(ctx context.Context, attachment *dto.AttachmentDTO) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) GetByID(ctx context.Context, id uint) (*dto.AttachmentDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AttachmentDTO), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListByOwner(ctx context.Context, ownerType valueobject.AttachmentOwner, ownerID uint) ([]dto.AttachmentDTO, error) {
+	args := m.Called(ctx, ownerType, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.AttachmentDTO), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
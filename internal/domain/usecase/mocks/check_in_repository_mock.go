@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Check-in Repository
+type MockCheckInRepository struct {
+	mock.Mock
+}
+
+func (m *MockCheckInRepository) GetByServiceOrder(ctx context.Context, serviceOrderID uint) (*dto.CheckInDTO, error) {
+	args := m.Called(ctx, serviceOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CheckInDTO), args.Error(1)
+}
+
+func (m *MockCheckInRepository) ListByServiceOrders(ctx context.Context, serviceOrderIDs []uint) ([]dto.CheckInDTO, error) {
+	args := m.Called(ctx, serviceOrderIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.CheckInDTO), args.Error(1)
+}
+
+func (m *MockCheckInRepository) Save(ctx context.Context, checkIn *dto.CheckInDTO) error {
+	args := m.Called(ctx, checkIn)
+	return args.Error(0)
+}
+
+func (m *MockCheckInRepository) AddPhoto(ctx context.Context, photo *dto.CheckInPhotoDTO) error {
+	args := m.Called(ctx, photo)
+	return args.Error(0)
+}
+
+func (m *MockCheckInRepository) GetPhoto(ctx context.Context, checkInID, photoID uint) (*dto.CheckInPhotoDTO, error) {
+	args := m.Called(ctx, checkInID, photoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CheckInPhotoDTO), args.Error(1)
+}
+
+func (m *MockCheckInRepository) DeletePhoto(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
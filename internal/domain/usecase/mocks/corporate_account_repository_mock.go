@@ -0,0 +1,97 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Corporate Account Repository
+type MockCorporateAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockCorporateAccountRepository) GetByCustomer(ctx context.Context, customerID uint) (*dto.CorporateAccountDTO, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CorporateAccountDTO), args.Error(1)
+}
+
+func (m *MockCorporateAccountRepository) ListAccounts(ctx context.Context) ([]dto.CorporateAccountDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.CorporateAccountDTO), args.Error(1)
+}
+
+func (m *MockCorporateAccountRepository) SaveAccount(ctx context.Context, account *dto.CorporateAccountDTO) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockCorporateAccountRepository) GetContact(ctx context.Context, id uint) (*dto.CorporateContactDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CorporateContactDTO), args.Error(1)
+}
+
+func (m *MockCorporateAccountRepository) SaveContact(ctx context.Context, contact *dto.CorporateContactDTO) error {
+	args := m.Called(ctx, contact)
+	return args.Error(0)
+}
+
+func (m *MockCorporateAccountRepository) DeleteContact(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCorporateAccountRepository) OpenBalance(ctx context.Context, customerID uint) (float64, error) {
+	args := m.Called(ctx, customerID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockCorporateAccountRepository) ListUnbilledOrders(ctx context.Context, customerID uint, deliveredBefore time.Time) ([]dto.ServiceOrderDTO, error) {
+	args := m.Called(ctx, customerID, deliveredBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.ServiceOrderDTO), args.Error(1)
+}
+
+func (m *MockCorporateAccountRepository) HasStatement(ctx context.Context, accountID uint, month string) (bool, error) {
+	args := m.Called(ctx, accountID, month)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCorporateAccountRepository) CreateStatement(ctx context.Context, statement *dto.CorporateStatementDTO) error {
+	args := m.Called(ctx, statement)
+	return args.Error(0)
+}
+
+func (m *MockCorporateAccountRepository) GetStatement(ctx context.Context, id uint) (*dto.CorporateStatementDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CorporateStatementDTO), args.Error(1)
+}
+
+func (m *MockCorporateAccountRepository) ListStatements(ctx context.Context, accountID uint) ([]dto.CorporateStatementDTO, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.CorporateStatementDTO), args.Error(1)
+}
+
+func (m *MockCorporateAccountRepository) MarkStatementPaid(ctx context.Context, id uint, paidBy string, paidAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, paidBy, paidAt)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Customer Merge Repository
+type MockCustomerMergeRepository struct {
+	mock.Mock
+}
+
+func (m *MockCustomerMergeRepository) Merge(ctx context.Context, survivorID uint, duplicate *dto.CustomerDTO, merge *dto.CustomerMergeDTO) error {
+	args := m.Called(ctx, survivorID, duplicate, merge)
+	return args.Error(0)
+}
+
+func (m *MockCustomerMergeRepository) List(ctx context.Context, customerID uint) ([]dto.CustomerMergeDTO, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.CustomerMergeDTO), args.Error(1)
+}
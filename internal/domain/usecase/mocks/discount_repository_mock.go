@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Discount Repository
+type MockDiscountRepository struct {
+	mock.Mock
+}
+
+func (m *MockDiscountRepository) CreateCoupon(ctx context.Context, coupon *entities.Coupon) (entities.Coupon, error) {
+	args := m.Called(ctx, coupon)
+	return args.Get(0).(entities.Coupon), args.Error(1)
+}
+
+func (m *MockDiscountRepository) GetCouponByCode(ctx context.Context, code string) (*dto.CouponDTO, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CouponDTO), args.Error(1)
+}
+
+func (m *MockDiscountRepository) ListCoupons(ctx context.Context) ([]dto.CouponDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.CouponDTO), args.Error(1)
+}
+
+func (m *MockDiscountRepository) UpdateCoupon(ctx context.Context, coupon *entities.Coupon) error {
+	args := m.Called(ctx, coupon)
+	return args.Error(0)
+}
+
+func (m *MockDiscountRepository) CreatePriceAgreement(ctx context.Context, agreement *entities.PriceAgreement) (entities.PriceAgreement, error) {
+	args := m.Called(ctx, agreement)
+	return args.Get(0).(entities.PriceAgreement), args.Error(1)
+}
+
+func (m *MockDiscountRepository) ListPriceAgreementsByCustomer(ctx context.Context, customerID uint) ([]dto.PriceAgreementDTO, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.PriceAgreementDTO), args.Error(1)
+}
+
+func (m *MockDiscountRepository) DeletePriceAgreement(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
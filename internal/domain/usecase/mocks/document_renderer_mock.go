@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Document Renderer
+type MockDocumentRenderer struct {
+	mock.Mock
+}
+
+func (m *MockDocumentRenderer) Render(document entities.Document) ([]byte, error) {
+	args := m.Called(document)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockDocumentRenderer) ContentType() string {
+	return "application/pdf"
+}
+
+func (m *MockDocumentRenderer) Extension() string {
+	return "pdf"
+}
@@ -0,0 +1,68 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Financial Repository
+type MockFinancialRepository struct {
+	mock.Mock
+}
+
+func (m *MockFinancialRepository) SummarizePayments(ctx context.Context, from, to time.Time) ([]entities.PaymentReportLine, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.PaymentReportLine), args.Error(1)
+}
+
+func (m *MockFinancialRepository) ListReceivables(ctx context.Context) ([]entities.Receivable, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Receivable), args.Error(1)
+}
+
+func (m *MockFinancialRepository) SummarizeRevenue(ctx context.Context, from, to time.Time) (*entities.RevenueReport, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.RevenueReport), args.Error(1)
+}
+
+func (m *MockFinancialRepository) CreateClosing(ctx context.Context, closing *entities.CashClosing, from, to time.Time) (entities.CashClosing, error) {
+	args := m.Called(ctx, closing, from, to)
+	return args.Get(0).(entities.CashClosing), args.Error(1)
+}
+
+func (m *MockFinancialRepository) GetClosingByDate(ctx context.Context, date time.Time) (*dto.CashClosingDTO, error) {
+	args := m.Called(ctx, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CashClosingDTO), args.Error(1)
+}
+
+func (m *MockFinancialRepository) ListClosings(ctx context.Context, from, to time.Time) ([]dto.CashClosingDTO, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.CashClosingDTO), args.Error(1)
+}
+
+func (m *MockFinancialRepository) ListPaymentsByClosing(ctx context.Context, closingID uint) ([]dto.PaymentDTO, error) {
+	args := m.Called(ctx, closingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.PaymentDTO), args.Error(1)
+}
@@ -0,0 +1,55 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Invoice Encoder
+type MockInvoiceEncoder struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceEncoder) Encode(invoice entities.Invoice) ([]byte, error) {
+	args := m.Called(invoice)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// Mock Invoice Signer
+type MockInvoiceSigner struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceSigner) Sign(xml []byte) ([]byte, error) {
+	args := m.Called(xml)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// Mock Invoice Provider
+type MockInvoiceProvider struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceProvider) Submit(ctx context.Context, invoice entities.Invoice, signedXML []byte) (*entities.InvoiceSubmission, error) {
+	args := m.Called(ctx, invoice, signedXML)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.InvoiceSubmission), args.Error(1)
+}
+
+func (m *MockInvoiceProvider) Cancel(ctx context.Context, invoice entities.Invoice, reason string) (*entities.InvoiceCancellation, error) {
+	args := m.Called(ctx, invoice, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.InvoiceCancellation), args.Error(1)
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Invoice Repository
+type MockInvoiceRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceRepository) Create(ctx context.Context, invoice *entities.Invoice) (entities.Invoice, error) {
+	args := m.Called(ctx, invoice)
+	return args.Get(0).(entities.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceRepository) Update(ctx context.Context, invoice *entities.Invoice) error {
+	args := m.Called(ctx, invoice)
+	return args.Error(0)
+}
+
+func (m *MockInvoiceRepository) GetByID(ctx context.Context, id uint) (*dto.InvoiceDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.InvoiceDTO), args.Error(1)
+}
+
+func (m *MockInvoiceRepository) ListByServiceOrderID(ctx context.Context, serviceOrderID uint) ([]dto.InvoiceDTO, error) {
+	args := m.Called(ctx, serviceOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.InvoiceDTO), args.Error(1)
+}
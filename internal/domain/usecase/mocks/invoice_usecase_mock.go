@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Invoice UseCase
+type MockInvoiceUseCase struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceUseCase) IssueInvoices(ctx context.Context, serviceOrderID uint) ([]entities.Invoice, error) {
+	args := m.Called(ctx, serviceOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceUseCase) CancelInvoice(ctx context.Context, id uint, reason string) (*entities.Invoice, error) {
+	args := m.Called(ctx, id, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceUseCase) GetInvoice(ctx context.Context, id uint) (*entities.Invoice, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceUseCase) ListInvoices(ctx context.Context, serviceOrderID uint) ([]entities.Invoice, error) {
+	args := m.Called(ctx, serviceOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Invoice), args.Error(1)
+}
@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Maintenance Repository
+type MockMaintenanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockMaintenanceRepository) GetPlanByService(ctx context.Context, serviceID uint) (*dto.MaintenancePlanDTO, error) {
+	args := m.Called(ctx, serviceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MaintenancePlanDTO), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) ListPlans(ctx context.Context) ([]dto.MaintenancePlanDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.MaintenancePlanDTO), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) SavePlan(ctx context.Context, plan *dto.MaintenancePlanDTO) error {
+	args := m.Called(ctx, plan)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRepository) DeletePlan(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRepository) ListVehiclesWithServices(ctx context.Context, serviceIDs []uint) ([]uint, error) {
+	args := m.Called(ctx, serviceIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) HasReminder(ctx context.Context, vehicleID, planID, serviceOrderID uint) (bool, error) {
+	args := m.Called(ctx, vehicleID, planID, serviceOrderID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) CreateReminder(ctx context.Context, reminder *dto.MaintenanceReminderDTO) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
@@ -0,0 +1,105 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Mechanic Repository
+type MockMechanicRepository struct {
+	mock.Mock
+}
+
+func (m *MockMechanicRepository) Create(ctx context.Context, mechanic *dto.MechanicDTO) error {
+	args := m.Called(ctx, mechanic)
+	return args.Error(0)
+}
+
+func (m *MockMechanicRepository) GetByID(ctx context.Context, id uint) (*dto.MechanicDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MechanicDTO), args.Error(1)
+}
+
+func (m *MockMechanicRepository) List(ctx context.Context) ([]dto.MechanicDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.MechanicDTO), args.Error(1)
+}
+
+func (m *MockMechanicRepository) Update(ctx context.Context, mechanic *dto.MechanicDTO) error {
+	args := m.Called(ctx, mechanic)
+	return args.Error(0)
+}
+
+func (m *MockMechanicRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMechanicRepository) CreateAssignment(ctx context.Context, assignment *dto.MechanicAssignmentDTO) error {
+	args := m.Called(ctx, assignment)
+	return args.Error(0)
+}
+
+func (m *MockMechanicRepository) GetAssignment(ctx context.Context, id uint) (*dto.MechanicAssignmentDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.MechanicAssignmentDTO), args.Error(1)
+}
+
+func (m *MockMechanicRepository) ListAssignments(ctx context.Context, serviceOrderID uint) ([]dto.MechanicAssignmentDTO, error) {
+	args := m.Called(ctx, serviceOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.MechanicAssignmentDTO), args.Error(1)
+}
+
+func (m *MockMechanicRepository) DeleteAssignment(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMechanicRepository) CreateEntry(ctx context.Context, entry *dto.LabourEntryDTO) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockMechanicRepository) GetOpenEntry(ctx context.Context, mechanicID uint) (*dto.LabourEntryDTO, error) {
+	args := m.Called(ctx, mechanicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LabourEntryDTO), args.Error(1)
+}
+
+func (m *MockMechanicRepository) CloseEntry(ctx context.Context, entry *dto.LabourEntryDTO) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockMechanicRepository) ListEntries(ctx context.Context, serviceOrderID uint) ([]dto.LabourEntryDTO, error) {
+	args := m.Called(ctx, serviceOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.LabourEntryDTO), args.Error(1)
+}
+
+func (m *MockMechanicRepository) ListClosedEntries(ctx context.Context, from, to time.Time) ([]dto.LabourEntryDTO, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.LabourEntryDTO), args.Error(1)
+}
@@ -54,6 +54,11 @@ func (m *MockVehicleRepository) Create(vehicle entities.Vehicle) error {
 	return args.Error(0)
 }
 
+func (m *MockVehicleRepository) CreateBatch(vehicles []entities.Vehicle) error {
+	args := m.Called(vehicles)
+	return args.Error(0)
+}
+
 func (m *MockVehicleRepository) Update(vehicle entities.Vehicle) error {
 	args := m.Called(vehicle)
 	return args.Error(0)
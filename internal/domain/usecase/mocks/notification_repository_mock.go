@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Notification Repository
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, delivery *dto.NotificationDeliveryDTO) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) Update(ctx context.Context, delivery *dto.NotificationDeliveryDTO) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]dto.NotificationDeliveryDTO, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.NotificationDeliveryDTO), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ListByServiceOrderID(ctx context.Context, serviceOrderID uint) ([]dto.NotificationDeliveryDTO, error) {
+	args := m.Called(ctx, serviceOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.NotificationDeliveryDTO), args.Error(1)
+}
@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Notification UseCase
+type MockNotificationUseCase struct {
+	mock.Mock
+}
+
+func (m *MockNotificationUseCase) Notify(ctx context.Context, event entities.NotificationEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockNotificationUseCase) HandleDomainEvent(ctx context.Context, event entities.DomainEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockNotificationUseCase) ProcessPending(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationUseCase) ListDeliveries(ctx context.Context, serviceOrderID uint) ([]entities.NotificationDelivery, error) {
+	args := m.Called(ctx, serviceOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.NotificationDelivery), args.Error(1)
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Outbox Repository
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]dto.OutboxEventDTO, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.OutboxEventDTO), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkDispatched(ctx context.Context, id uint, attempts int, dispatchedAt time.Time) error {
+	args := m.Called(ctx, id, attempts, dispatchedAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id uint, attempts int, lastError string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, attempts, lastError, nextAttemptAt)
+	return args.Error(0)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Payment UseCase
+type MockPaymentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockPaymentUseCase) CreatePayment(ctx context.Context, payment *entities.Payment) (*entities.Payment, error) {
+	args := m.Called(ctx, payment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) GetPaymentByID(ctx context.Context, id uint) (*entities.Payment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) ListPayments(ctx context.Context) ([]entities.Payment, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) UpdatePaymentMethod(ctx context.Context, id uint, method valueobject.PaymentMethod) (*entities.Payment, error) {
+	args := m.Called(ctx, id, method)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Payment), args.Error(1)
+}
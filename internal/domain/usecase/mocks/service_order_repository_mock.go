@@ -4,6 +4,7 @@ import (
 	"mecanica_xpto/internal/domain/model/dto"
 	"mecanica_xpto/internal/domain/model/entities"
 	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -39,6 +40,14 @@ func (m *MockServiceOrderRepository) List() ([]dto.ServiceOrderDTO, error) {
 	return args.Get(0).([]dto.ServiceOrderDTO), args.Error(1)
 }
 
+func (m *MockServiceOrderRepository) ListByVehicle(vehicleID uint) ([]dto.ServiceOrderDTO, error) {
+	args := m.Called(vehicleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.ServiceOrderDTO), args.Error(1)
+}
+
 func (m *MockServiceOrderRepository) GetStatus(status valueobject.ServiceOrderStatus) (*dto.ServiceOrderStatusDTO, error) {
 	args := m.Called(status)
 	if args.Get(0) == nil {
@@ -59,3 +68,16 @@ func (m *MockServiceOrderRepository) UpdateEstimate(id uint, estimate float64) e
 	args := m.Called(id, estimate)
 	return args.Error(0)
 }
+
+func (m *MockServiceOrderRepository) ApproveDiscount(id uint, approvedBy string, approvedAt time.Time) error {
+	args := m.Called(id, approvedBy, approvedAt)
+	return args.Error(0)
+}
+
+func (m *MockServiceOrderRepository) GetByIDWithItems(id uint) (*dto.ServiceOrderDTO, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ServiceOrderDTO), args.Error(1)
+}
@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Staff Repository
+type MockStaffRepository struct {
+	mock.Mock
+}
+
+func (m *MockStaffRepository) Create(ctx context.Context, staff *dto.StaffDTO) error {
+	args := m.Called(ctx, staff)
+	return args.Error(0)
+}
+
+func (m *MockStaffRepository) GetByID(ctx context.Context, id uint) (*dto.StaffDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.StaffDTO), args.Error(1)
+}
+
+func (m *MockStaffRepository) List(ctx context.Context) ([]dto.StaffDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.StaffDTO), args.Error(1)
+}
+
+func (m *MockStaffRepository) Update(ctx context.Context, staff *dto.StaffDTO) error {
+	args := m.Called(ctx, staff)
+	return args.Error(0)
+}
+
+func (m *MockStaffRepository) Delete(ctx context.Context, staff *dto.StaffDTO) error {
+	args := m.Called(ctx, staff)
+	return args.Error(0)
+}
+
+func (m *MockStaffRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
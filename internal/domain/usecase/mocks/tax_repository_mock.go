@@ -0,0 +1,68 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Tax Repository
+type MockTaxRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaxRepository) CreateRule(ctx context.Context, rule *entities.TaxRule) (entities.TaxRule, error) {
+	args := m.Called(ctx, rule)
+	return args.Get(0).(entities.TaxRule), args.Error(1)
+}
+
+func (m *MockTaxRepository) GetRuleByID(ctx context.Context, id uint) (*dto.TaxRuleDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TaxRuleDTO), args.Error(1)
+}
+
+func (m *MockTaxRepository) GetRule(ctx context.Context, taxType valueobject.TaxType, category string) (*dto.TaxRuleDTO, error) {
+	args := m.Called(ctx, taxType, category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TaxRuleDTO), args.Error(1)
+}
+
+func (m *MockTaxRepository) ListRules(ctx context.Context) ([]dto.TaxRuleDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.TaxRuleDTO), args.Error(1)
+}
+
+func (m *MockTaxRepository) UpdateRule(ctx context.Context, rule *entities.TaxRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockTaxRepository) DeleteRule(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTaxRepository) SummarizeByPaymentPeriod(ctx context.Context, from, to time.Time) ([]entities.TaxReportLine, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.TaxReportLine), args.Error(1)
+}
+
+func (m *MockTaxRepository) GetPaidRevenue(ctx context.Context, from, to time.Time) (int64, float64, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).(int64), args.Get(1).(float64), args.Error(2)
+}
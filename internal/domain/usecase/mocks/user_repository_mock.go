@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock User Repository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) GetByID(id uint) (*dto.UserDTO, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserDTO), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByEmail(email string) (*dto.UserDTO, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserDTO), args.Error(1)
+}
+
+func (m *MockUserRepository) Create(user *dto.UserDTO) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Update(user *dto.UserDTO) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List() ([]dto.UserDTO, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.UserDTO), args.Error(1)
+}
@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Vehicle Catalog Repository
+type MockVehicleCatalogRepository struct {
+	mock.Mock
+}
+
+func (m *MockVehicleCatalogRepository) Import(ctx context.Context, models []dto.VehicleModelDTO) error {
+	args := m.Called(ctx, models)
+	return args.Error(0)
+}
+
+func (m *MockVehicleCatalogRepository) Count(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockVehicleCatalogRepository) ListBrands(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockVehicleCatalogRepository) ListModels(ctx context.Context, brand, model string) ([]dto.VehicleModelDTO, error) {
+	args := m.Called(ctx, brand, model)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.VehicleModelDTO), args.Error(1)
+}
+
+func (m *MockVehicleCatalogRepository) FindModel(ctx context.Context, brand, model string, year int) (*dto.VehicleModelDTO, error) {
+	args := m.Called(ctx, brand, model, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.VehicleModelDTO), args.Error(1)
+}
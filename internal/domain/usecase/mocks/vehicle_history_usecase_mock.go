@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Vehicle History UseCase
+type MockVehicleHistoryUseCase struct {
+	mock.Mock
+}
+
+func (m *MockVehicleHistoryUseCase) GetVehicleHistory(ctx context.Context, vehicleID uint) (*entities.VehicleHistory, error) {
+	args := m.Called(ctx, vehicleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.VehicleHistory), args.Error(1)
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Vehicle Ownership Repository
+type MockVehicleOwnershipRepository struct {
+	mock.Mock
+}
+
+func (m *MockVehicleOwnershipRepository) ListByVehicle(ctx context.Context, vehicleID uint) ([]dto.VehicleOwnershipDTO, error) {
+	args := m.Called(ctx, vehicleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.VehicleOwnershipDTO), args.Error(1)
+}
+
+func (m *MockVehicleOwnershipRepository) Transfer(ctx context.Context, vehicle *dto.VehicleDTO, ownership *dto.VehicleOwnershipDTO) error {
+	args := m.Called(ctx, vehicle, ownership)
+	return args.Error(0)
+}
@@ -0,0 +1,87 @@
+package mocks
+
+import (
+	"context"
+	"mecanica_xpto/internal/domain/model/dto"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Webhook Repository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateSubscription(ctx context.Context, subscription *dto.WebhookSubscriptionDTO) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetSubscription(ctx context.Context, id uint) (*dto.WebhookSubscriptionDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.WebhookSubscriptionDTO), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListSubscriptions(ctx context.Context) ([]dto.WebhookSubscriptionDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.WebhookSubscriptionDTO), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListActiveSubscriptions(ctx context.Context) ([]dto.WebhookSubscriptionDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.WebhookSubscriptionDTO), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateSubscription(ctx context.Context, subscription *dto.WebhookSubscriptionDTO) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *dto.WebhookDeliveryDTO) (bool, error) {
+	args := m.Called(ctx, delivery)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDelivery(ctx context.Context, id uint) (*dto.WebhookDeliveryDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.WebhookDeliveryDTO), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *dto.WebhookDeliveryDTO) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]dto.WebhookDeliveryDTO, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.WebhookDeliveryDTO), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint) ([]dto.WebhookDeliveryDTO, error) {
+	args := m.Called(ctx, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.WebhookDeliveryDTO), args.Error(1)
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"bytes"
+	"text/template"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/pkg/utils"
+)
+
+// notificationTemplate is the message of an event: the subject is only used by e-mail, while
+// SMS and WhatsApp send the body alone, so it must make sense on its own
+type notificationTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// notificationData is what the templates can use
+type notificationData struct {
+	CustomerName   string
+	ServiceOrderID uint
+	Vehicle        string
+	Plate          string
+	Estimate       string
+	Description    string
+	// Service and Due describe the maintenance a reminder is about
+	Service string
+	Due     string
+}
+
+func newNotificationTemplate(subject, body string) notificationTemplate {
+	return notificationTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
+
+var serviceOrderStatusTemplates = map[valueobject.ServiceOrderStatus]notificationTemplate{
+	valueobject.StatusRecebida: newNotificationTemplate(
+		"Recebemos seu veículo - OS {{.ServiceOrderID}}",
+		"Olá, {{.CustomerName}}! Recebemos seu {{.Vehicle}} (placa {{.Plate}}) e abrimos a ordem de serviço {{.ServiceOrderID}}. Avisaremos assim que o diagnóstico estiver pronto."),
+	valueobject.StatusAguardandoAprovacao: newNotificationTemplate(
+		"Orçamento aguardando sua aprovação - OS {{.ServiceOrderID}}",
+		"Olá, {{.CustomerName}}! O orçamento da ordem de serviço {{.ServiceOrderID}} do seu {{.Vehicle}} (placa {{.Plate}}) ficou em {{.Estimate}} e aguarda sua aprovação."),
+	valueobject.StatusEmExecucao: newNotificationTemplate(
+		"Serviço em execução - OS {{.ServiceOrderID}}",
+		"Olá, {{.CustomerName}}! Começamos a executar os serviços da ordem de serviço {{.ServiceOrderID}} no seu {{.Vehicle}} (placa {{.Plate}})."),
+	valueobject.StatusFinalizada: newNotificationTemplate(
+		"Seu veículo está pronto - OS {{.ServiceOrderID}}",
+		"Olá, {{.CustomerName}}! Os serviços da ordem de serviço {{.ServiceOrderID}} foram concluídos e seu {{.Vehicle}} (placa {{.Plate}}) já pode ser retirado."),
+	valueobject.StatusEntregue: newNotificationTemplate(
+		"Veículo entregue - OS {{.ServiceOrderID}}",
+		"Olá, {{.CustomerName}}! Seu {{.Vehicle}} (placa {{.Plate}}) foi entregue. Obrigado pela preferência!"),
+	valueobject.StatusCancelada: newNotificationTemplate(
+		"Ordem de serviço cancelada - OS {{.ServiceOrderID}}",
+		"Olá, {{.CustomerName}}! A ordem de serviço {{.ServiceOrderID}} do seu {{.Vehicle}} (placa {{.Plate}}) foi cancelada. Em caso de dúvidas, fale com a nossa equipe."),
+}
+
+var additionalRepairCreatedTemplate = newNotificationTemplate(
+	"Reparo adicional identificado - OS {{.ServiceOrderID}}",
+	"Olá, {{.CustomerName}}! Durante o serviço no seu {{.Vehicle}} (placa {{.Plate}}) identificamos um reparo adicional: {{.Description}}. Valor estimado: {{.Estimate}}. Entraremos em contato para sua aprovação.")
+
+var maintenanceReminderTemplate = newNotificationTemplate(
+	"Hora da manutenção do seu {{.Vehicle}}",
+	"Olá, {{.CustomerName}}! Está chegando a hora de fazer {{.Service}} no seu {{.Vehicle}} (placa {{.Plate}}): {{.Due}}. Fale com a nossa equipe para agendar sua visita.")
+
+// notificationTemplateFor returns the template of an event, or false when customers are not
+// notified about it
+func notificationTemplateFor(event entities.NotificationEvent) (notificationTemplate, bool) {
+	switch event.Type {
+	case entities.NotificationServiceOrderStatusChanged:
+		tmpl, ok := serviceOrderStatusTemplates[event.Status]
+		return tmpl, ok
+	case entities.NotificationAdditionalRepairCreated:
+		return additionalRepairCreatedTemplate, true
+	default:
+		return notificationTemplate{}, false
+	}
+}
+
+func newNotificationData(event entities.NotificationEvent, serviceOrder *entities.ServiceOrder) notificationData {
+	data := notificationData{
+		ServiceOrderID: serviceOrder.ID,
+		Description:    event.Description,
+	}
+	if serviceOrder.Customer != nil {
+		data.CustomerName = serviceOrder.Customer.FullName
+	}
+	if serviceOrder.Vehicle != nil {
+		data.Vehicle = serviceOrder.Vehicle.Brand + " " + serviceOrder.Vehicle.Model
+		data.Plate = serviceOrder.Vehicle.Plate.String()
+	}
+	estimate := serviceOrder.Estimate
+	if event.Type == entities.NotificationAdditionalRepairCreated {
+		estimate = event.Estimate
+	}
+	data.Estimate = utils.FormatBRL(estimate)
+	return data
+}
+
+func (t notificationTemplate) render(data notificationData) (string, string, error) {
+	var subject, body bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/notification"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+)
+
+const (
+	// notificationBatchSize is how many due deliveries are sent on each run
+	notificationBatchSize = 50
+	// notificationRetryDelay is the wait before the first retry, doubled on each new failure
+	notificationRetryDelay = time.Minute
+)
+
+// notificationChannels lists the channels in the order messages are queued on
+var notificationChannels = []valueobject.NotificationChannel{
+	valueobject.NotificationEmail,
+	valueobject.NotificationSMS,
+	valueobject.NotificationWhatsApp,
+}
+
+var ErrNotificationChannelDisabled = errors.New("notification channel is not configured")
+
+type INotificationUseCase interface {
+	Notify(ctx context.Context, event entities.NotificationEvent) error
+	HandleDomainEvent(ctx context.Context, event entities.DomainEvent) error
+	ProcessPending(ctx context.Context) (int, error)
+	ListDeliveries(ctx context.Context, serviceOrderID uint) ([]entities.NotificationDelivery, error)
+}
+
+type NotificationUseCase struct {
+	repo             notification.INotificationRepository
+	serviceOrderRepo serviceorder.IServiceOrderRepository
+	senders          map[valueobject.NotificationChannel]gateway.NotificationSender
+	maxAttempts      int
+	now              func() time.Time
+}
+
+var _ INotificationUseCase = (*NotificationUseCase)(nil)
+
+func NewNotificationUseCase(repo notification.INotificationRepository, serviceOrderRepo serviceorder.IServiceOrderRepository, senders []gateway.NotificationSender, maxAttempts int) *NotificationUseCase {
+	byChannel := make(map[valueobject.NotificationChannel]gateway.NotificationSender, len(senders))
+	for _, sender := range senders {
+		byChannel[sender.Channel()] = sender
+	}
+	return &NotificationUseCase{
+		repo:             repo,
+		serviceOrderRepo: serviceOrderRepo,
+		senders:          byChannel,
+		maxAttempts:      maxAttempts,
+		now:              time.Now,
+	}
+}
+
+// Notify queues the message of an event on every configured channel the customer can be reached
+// on. Messages are sent by ProcessPending, so a slow or failing channel never holds up the
+// service order flow. A message of a domain event is queued once per channel, so the event can
+// be handled again when queueing fails.
+func (u *NotificationUseCase) Notify(ctx context.Context, event entities.NotificationEvent) error {
+	tmpl, ok := notificationTemplateFor(event)
+	if !ok {
+		return nil
+	}
+
+	serviceOrderDto, err := u.serviceOrderRepo.GetByID(event.ServiceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error finding service order %d to notify %s: %v", event.ServiceOrderID, event.Type, err)
+		return err
+	}
+	if serviceOrderDto == nil {
+		log.Error().Msgf("Service order %d to notify %s not found", event.ServiceOrderID, event.Type)
+		return ErrServiceOrderNotFound
+	}
+	serviceOrder := serviceOrderDto.ToDomain()
+
+	subject, body, err := tmpl.render(newNotificationData(event, serviceOrder))
+	if err != nil {
+		log.Error().Msgf("Error rendering %s notification of service order %d: %v", event.Type, event.ServiceOrderID, err)
+		return err
+	}
+
+	now := u.now()
+	for _, channel := range notificationChannels {
+		if _, ok := u.senders[channel]; !ok {
+			continue
+		}
+		recipient := notificationRecipient(channel, serviceOrder.Customer)
+		if recipient == "" {
+			continue
+		}
+		delivery := dto.NotificationDeliveryDTO{
+			EventID:        event.EventID,
+			ServiceOrderID: event.ServiceOrderID,
+			Event:          event.Type,
+			Channel:        channel.String(),
+			Recipient:      recipient,
+			Subject:        subject,
+			Body:           body,
+			Status:         valueobject.NotificationPending.String(),
+			NextAttemptAt:  &now,
+		}
+		if err := u.repo.Create(ctx, &delivery); err != nil {
+			log.Error().Msgf("Error queueing %s notification of service order %d: %v", channel, event.ServiceOrderID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleDomainEvent notifies the customer about the service order events subscribed to on the
+// event dispatcher. An error makes the dispatcher deliver the event again, and the messages
+// already queued for it are not queued twice.
+func (u *NotificationUseCase) HandleDomainEvent(ctx context.Context, event entities.DomainEvent) error {
+	eventID := event.ID
+	switch event.Type {
+	case valueobject.EventServiceOrderCreated:
+		return u.Notify(ctx, entities.NotificationEvent{
+			EventID:        &eventID,
+			Type:           entities.NotificationServiceOrderStatusChanged,
+			ServiceOrderID: event.ServiceOrderID,
+			Status:         valueobject.StatusRecebida,
+		})
+	case valueobject.EventServiceOrderStatusChanged:
+		to, _ := event.Payload["to"].(string)
+		return u.Notify(ctx, entities.NotificationEvent{
+			EventID:        &eventID,
+			Type:           entities.NotificationServiceOrderStatusChanged,
+			ServiceOrderID: event.ServiceOrderID,
+			Status:         valueobject.ParseServiceOrderStatus(to),
+		})
+	}
+	return nil
+}
+
+// ProcessPending sends the deliveries that are due. A failed delivery is retried with a growing
+// delay until maxAttempts is reached, when it is marked as failed. It returns how many were sent.
+func (u *NotificationUseCase) ProcessPending(ctx context.Context) (int, error) {
+	now := u.now()
+	deliveries, err := u.repo.ListDue(ctx, now, notificationBatchSize)
+	if err != nil {
+		log.Error().Msgf("Error listing pending notifications: %v", err)
+		return 0, err
+	}
+
+	sent := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		delivery.Attempts++
+		if err := u.send(ctx, delivery); err != nil {
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= u.maxAttempts {
+				delivery.Status = valueobject.NotificationFailed.String()
+				delivery.NextAttemptAt = nil
+			} else {
+				next := now.Add(notificationRetryDelay << (delivery.Attempts - 1))
+				delivery.NextAttemptAt = &next
+			}
+			log.Error().Msgf("Error sending notification %d (attempt %d): %v", delivery.ID, delivery.Attempts, err)
+		} else {
+			delivery.Status = valueobject.NotificationSent.String()
+			delivery.LastError = ""
+			delivery.NextAttemptAt = nil
+			delivery.SentAt = &now
+			sent++
+		}
+		if err := u.repo.Update(ctx, delivery); err != nil {
+			log.Error().Msgf("Error recording delivery of notification %d: %v", delivery.ID, err)
+		}
+	}
+	return sent, nil
+}
+
+func (u *NotificationUseCase) ListDeliveries(ctx context.Context, serviceOrderID uint) ([]entities.NotificationDelivery, error) {
+	deliveries, err := u.repo.ListByServiceOrderID(ctx, serviceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error listing notifications of service order %d: %v", serviceOrderID, err)
+		return nil, err
+	}
+	result := make([]entities.NotificationDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, delivery.ToDomain())
+	}
+	return result, nil
+}
+
+func (u *NotificationUseCase) send(ctx context.Context, delivery *dto.NotificationDeliveryDTO) error {
+	channel := valueobject.ParseNotificationChannel(delivery.Channel)
+	sender, ok := u.senders[channel]
+	if !ok {
+		return ErrNotificationChannelDisabled
+	}
+	return sender.Send(ctx, entities.NotificationMessage{
+		Channel:   channel,
+		Recipient: delivery.Recipient,
+		Subject:   delivery.Subject,
+		Body:      delivery.Body,
+	})
+}
+
+// notificationRecipient is the address of the customer on a channel, empty when there is none
+func notificationRecipient(channel valueobject.NotificationChannel, customer *entities.Customer) string {
+	if customer == nil {
+		return ""
+	}
+	if channel == valueobject.NotificationEmail {
+		return customer.Email
+	}
+	return customer.PhoneNumber
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+// newNotifierMock accepts any notification, for the tests that do not check them
+func newNotifierMock() *mocks.MockNotificationUseCase {
+	notifier := new(mocks.MockNotificationUseCase)
+	notifier.On("Notify", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return notifier
+}
+
+// stubSender records the messages it sends and fails with err when it is set
+type stubSender struct {
+	channel valueobject.NotificationChannel
+	sent    []entities.NotificationMessage
+	err     error
+}
+
+func (s *stubSender) Channel() valueobject.NotificationChannel { return s.channel }
+
+func (s *stubSender) Send(_ context.Context, message entities.NotificationMessage) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, message)
+	return nil
+}
+
+func notificationServiceOrder() *dto.ServiceOrderDTO {
+	return &dto.ServiceOrderDTO{
+		ID:       7,
+		Estimate: 1500,
+		Customer: dto.CustomerDTO{
+			ID:          1,
+			FullName:    "Maria Souza",
+			PhoneNumber: "11999990000",
+			User:        &dto.UserDTO{ID: 1, Email: "maria@example.com"},
+		},
+		Vehicle: dto.VehicleDTO{ID: 2, Brand: "Fiat", Model: "Argo", Plate: "ABC1D23"},
+	}
+}
+
+func newNotificationTestUseCase(maxAttempts int, senders ...gateway.NotificationSender) (*NotificationUseCase, *mocks.MockNotificationRepository, *MockServiceOrderRepository) {
+	repo := new(mocks.MockNotificationRepository)
+	serviceOrderRepo := new(MockServiceOrderRepository)
+	uc := NewNotificationUseCase(repo, serviceOrderRepo, senders, maxAttempts)
+	uc.now = func() time.Time { return time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC) }
+	return uc, repo, serviceOrderRepo
+}
+
+func TestNotificationUseCase_Notify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queues the message on every configured channel", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newNotificationTestUseCase(3,
+			&stubSender{channel: valueobject.NotificationWhatsApp},
+			&stubSender{channel: valueobject.NotificationEmail})
+		serviceOrderRepo.On("GetByID", uint(7)).Return(notificationServiceOrder(), nil)
+		var queued []dto.NotificationDeliveryDTO
+		repo.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			queued = append(queued, *args.Get(1).(*dto.NotificationDeliveryDTO))
+		}).Return(nil)
+
+		require.NoError(t, uc.Notify(ctx, entities.NotificationEvent{
+			Type:           entities.NotificationServiceOrderStatusChanged,
+			ServiceOrderID: 7,
+			Status:         valueobject.StatusAguardandoAprovacao,
+		}))
+
+		require.Len(t, queued, 2)
+		assert.Equal(t, "EMAIL", queued[0].Channel)
+		assert.Equal(t, "maria@example.com", queued[0].Recipient)
+		assert.Equal(t, "WHATSAPP", queued[1].Channel)
+		assert.Equal(t, "11999990000", queued[1].Recipient)
+		assert.Equal(t, valueobject.NotificationPending.String(), queued[0].Status)
+		assert.Equal(t, "Orçamento aguardando sua aprovação - OS 7", queued[0].Subject)
+		assert.Contains(t, queued[0].Body, "Olá, Maria Souza!")
+		assert.Contains(t, queued[0].Body, "Fiat Argo (placa ABC1D23)")
+		assert.Contains(t, queued[0].Body, "R$ 1.500,00")
+	})
+
+	t.Run("additional repair uses its own estimate", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newNotificationTestUseCase(3, &stubSender{channel: valueobject.NotificationSMS})
+		serviceOrderRepo.On("GetByID", uint(7)).Return(notificationServiceOrder(), nil)
+		var queued *dto.NotificationDeliveryDTO
+		repo.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			queued = args.Get(1).(*dto.NotificationDeliveryDTO)
+		}).Return(nil)
+
+		require.NoError(t, uc.Notify(ctx, entities.NotificationEvent{
+			Type:               entities.NotificationAdditionalRepairCreated,
+			ServiceOrderID:     7,
+			AdditionalRepairID: 3,
+			Description:        "Troca da correia dentada",
+			Estimate:           320,
+		}))
+
+		require.NotNil(t, queued)
+		assert.Contains(t, queued.Body, "Troca da correia dentada")
+		assert.Contains(t, queued.Body, "R$ 320,00")
+	})
+
+	t.Run("skips statuses without a message", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newNotificationTestUseCase(3, &stubSender{channel: valueobject.NotificationEmail})
+
+		require.NoError(t, uc.Notify(ctx, entities.NotificationEvent{
+			Type:           entities.NotificationServiceOrderStatusChanged,
+			ServiceOrderID: 7,
+			Status:         valueobject.StatusEmDiagnostico,
+		}))
+
+		serviceOrderRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("skips channels the customer cannot be reached on", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newNotificationTestUseCase(3, &stubSender{channel: valueobject.NotificationEmail})
+		serviceOrder := notificationServiceOrder()
+		serviceOrder.Customer.User = nil
+		serviceOrderRepo.On("GetByID", uint(7)).Return(serviceOrder, nil)
+
+		require.NoError(t, uc.Notify(ctx, entities.NotificationEvent{
+			Type:           entities.NotificationServiceOrderStatusChanged,
+			ServiceOrderID: 7,
+			Status:         valueobject.StatusFinalizada,
+		}))
+
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns the error of the repository", func(t *testing.T) {
+		uc, repo, serviceOrderRepo := newNotificationTestUseCase(3, &stubSender{channel: valueobject.NotificationEmail})
+		serviceOrderRepo.On("GetByID", uint(7)).Return(notificationServiceOrder(), nil)
+		repo.On("Create", ctx, mock.Anything).Return(errors.New("connection refused"))
+
+		err := uc.Notify(ctx, entities.NotificationEvent{
+			Type:           entities.NotificationServiceOrderStatusChanged,
+			ServiceOrderID: 7,
+			Status:         valueobject.StatusFinalizada,
+		})
+
+		assert.EqualError(t, err, "connection refused")
+	})
+}
+
+func TestNotificationUseCase_ProcessPending(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	pending := func(attempts int) dto.NotificationDeliveryDTO {
+		return dto.NotificationDeliveryDTO{
+			ID:        1,
+			Channel:   "EMAIL",
+			Recipient: "maria@example.com",
+			Subject:   "Assunto",
+			Body:      "Corpo",
+			Status:    valueobject.NotificationPending.String(),
+			Attempts:  attempts,
+		}
+	}
+
+	t.Run("marks the delivery as sent", func(t *testing.T) {
+		sender := &stubSender{channel: valueobject.NotificationEmail}
+		uc, repo, _ := newNotificationTestUseCase(3, sender)
+		repo.On("ListDue", ctx, now, notificationBatchSize).Return([]dto.NotificationDeliveryDTO{pending(0)}, nil)
+		repo.On("Update", ctx, mock.MatchedBy(func(d *dto.NotificationDeliveryDTO) bool {
+			return d.Status == valueobject.NotificationSent.String() && d.Attempts == 1 && d.SentAt != nil && d.NextAttemptAt == nil
+		})).Return(nil)
+
+		sent, err := uc.ProcessPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		require.Len(t, sender.sent, 1)
+		assert.Equal(t, "maria@example.com", sender.sent[0].Recipient)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("schedules a retry with a growing delay", func(t *testing.T) {
+		uc, repo, _ := newNotificationTestUseCase(3, &stubSender{channel: valueobject.NotificationEmail, err: errors.New("smtp offline")})
+		repo.On("ListDue", ctx, now, notificationBatchSize).Return([]dto.NotificationDeliveryDTO{pending(1)}, nil)
+		repo.On("Update", ctx, mock.MatchedBy(func(d *dto.NotificationDeliveryDTO) bool {
+			return d.Status == valueobject.NotificationPending.String() && d.Attempts == 2 &&
+				d.LastError == "smtp offline" && d.NextAttemptAt.Equal(now.Add(2*notificationRetryDelay))
+		})).Return(nil)
+
+		sent, err := uc.ProcessPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		uc, repo, _ := newNotificationTestUseCase(3, &stubSender{channel: valueobject.NotificationEmail, err: errors.New("smtp offline")})
+		repo.On("ListDue", ctx, now, notificationBatchSize).Return([]dto.NotificationDeliveryDTO{pending(2)}, nil)
+		repo.On("Update", ctx, mock.MatchedBy(func(d *dto.NotificationDeliveryDTO) bool {
+			return d.Status == valueobject.NotificationFailed.String() && d.Attempts == 3 && d.NextAttemptAt == nil
+		})).Return(nil)
+
+		_, err := uc.ProcessPending(ctx)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("channel no longer configured", func(t *testing.T) {
+		uc, repo, _ := newNotificationTestUseCase(1)
+		repo.On("ListDue", ctx, now, notificationBatchSize).Return([]dto.NotificationDeliveryDTO{pending(0)}, nil)
+		repo.On("Update", ctx, mock.MatchedBy(func(d *dto.NotificationDeliveryDTO) bool {
+			return d.Status == valueobject.NotificationFailed.String() && d.LastError == ErrNotificationChannelDisabled.Error()
+		})).Return(nil)
+
+		_, err := uc.ProcessPending(ctx)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error listing", func(t *testing.T) {
+		uc, repo, _ := newNotificationTestUseCase(3)
+		repo.On("ListDue", ctx, now, notificationBatchSize).Return(nil, errors.New("db down"))
+
+		_, err := uc.ProcessPending(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestNotificationUseCase_HandleDomainEvent(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, serviceOrderRepo := newNotificationTestUseCase(3, &stubSender{channel: valueobject.NotificationEmail})
+	serviceOrderRepo.On("GetByID", uint(7)).Return(notificationServiceOrder(), nil)
+	var queued []dto.NotificationDeliveryDTO
+	repo.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		queued = append(queued, *args.Get(1).(*dto.NotificationDeliveryDTO))
+	}).Return(nil)
+
+	require.NoError(t, uc.HandleDomainEvent(ctx, entities.DomainEvent{
+		Type:           valueobject.EventServiceOrderCreated,
+		ServiceOrderID: 7,
+	}))
+	require.NoError(t, uc.HandleDomainEvent(ctx, entities.NewServiceOrderStatusChangedEvent(7, valueobject.StatusEmExecucao, valueobject.StatusFinalizada)))
+	require.NoError(t, uc.HandleDomainEvent(ctx, entities.NewPaymentReceivedEvent(7, 100, valueobject.PaymentPix)))
+
+	require.Len(t, queued, 2)
+	assert.Equal(t, "Recebemos seu veículo - OS 7", queued[0].Subject)
+	assert.Equal(t, "Seu veículo está pronto - OS 7", queued[1].Subject)
+}
+
+func TestNotificationUseCase_HandleDomainEvent_Error(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, serviceOrderRepo := newNotificationTestUseCase(3, &stubSender{channel: valueobject.NotificationEmail})
+	serviceOrderRepo.On("GetByID", uint(7)).Return(notificationServiceOrder(), nil)
+	repo.On("Create", ctx, mock.Anything).Return(errors.New("connection refused"))
+	event := entities.NewServiceOrderStatusChangedEvent(7, valueobject.StatusEmExecucao, valueobject.StatusFinalizada)
+	event.ID = 42
+
+	err := uc.HandleDomainEvent(ctx, event)
+
+	assert.EqualError(t, err, "connection refused")
+	repo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(delivery *dto.NotificationDeliveryDTO) bool {
+		return delivery.EventID != nil && *delivery.EventID == 42 && delivery.Channel == "EMAIL"
+	}))
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/financial"
 	"mecanica_xpto/internal/domain/repository/payment"
 	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,29 +17,45 @@ var (
 	ErrorPaymentNotFound         = errors.New("payment not found")
 	ErrPaymentAlreadyExists      = errors.New("payment already exists")
 	ErrPaymentAmountDoesNotMatch = errors.New("payment amount does not match service order estimate")
+	ErrInvalidPaymentMethod      = errors.New("invalid payment method")
+	ErrCashRegisterClosed        = errors.New("cash register is already closed for the day")
+	ErrPaymentLocked             = errors.New("payment belongs to a closed cash register day")
 )
 
 type IPaymentUseCase interface {
 	CreatePayment(ctx context.Context, payment *entities.Payment) (*entities.Payment, error)
 	GetPaymentByID(ctx context.Context, id uint) (*entities.Payment, error)
 	ListPayments(ctx context.Context) ([]entities.Payment, error)
+	UpdatePaymentMethod(ctx context.Context, id uint, method valueobject.PaymentMethod) (*entities.Payment, error)
 }
 
 type PaymentUseCase struct {
 	repo             payment.IPaymentRepo
 	serviceOrderRepo serviceorder.IServiceOrderRepository
+	financialRepo    financial.IFinancialRepository
+	now              func() time.Time
 }
 
 var _ IPaymentUseCase = (*PaymentUseCase)(nil)
 
-func NewPaymentUseCase(repo payment.IPaymentRepo, serviceOrderRepo serviceorder.IServiceOrderRepository) *PaymentUseCase {
+func NewPaymentUseCase(repo payment.IPaymentRepo, serviceOrderRepo serviceorder.IServiceOrderRepository, financialRepo financial.IFinancialRepository) *PaymentUseCase {
 	return &PaymentUseCase{
 		repo:             repo,
 		serviceOrderRepo: serviceOrderRepo,
+		financialRepo:    financialRepo,
+		now:              time.Now,
 	}
 }
 
 func (p *PaymentUseCase) CreatePayment(ctx context.Context, payment *entities.Payment) (*entities.Payment, error) {
+	// Cash is the method of the counter, used when the operator does not pick one
+	if payment.Method == "" {
+		payment.Method = valueobject.PaymentCash
+	}
+	if !payment.Method.IsValid() {
+		return nil, ErrInvalidPaymentMethod
+	}
+
 	serviceOrder, err := p.serviceOrderRepo.GetByID(payment.ServiceOrderID)
 	if err != nil {
 		return nil, err
@@ -49,6 +68,23 @@ func (p *PaymentUseCase) CreatePayment(ctx context.Context, payment *entities.Pa
 		return nil, ErrPaymentAlreadyExists
 	}
 
+	// Taxes are included in the amount, the payment keeps them broken out for bookkeeping
+	payment.ISSAmount = serviceOrder.ISSTotal
+	payment.ICMSAmount = serviceOrder.ICMSTotal
+	payment.TaxTotal = serviceOrder.TaxTotal
+
+	// Payments are received today, which must not be closed yet
+	closing, err := p.financialRepo.GetClosingByDate(ctx, p.now())
+	if err != nil {
+		return nil, err
+	}
+	if closing != nil {
+		return nil, ErrCashRegisterClosed
+	}
+
+	payment.Events = []entities.DomainEvent{
+		entities.NewPaymentReceivedEvent(payment.ServiceOrderID, payment.Amount, payment.Method),
+	}
 	dto, err := p.repo.Create(ctx, payment)
 	if err != nil {
 		return nil, err
@@ -78,3 +114,27 @@ func (p *PaymentUseCase) ListPayments(ctx context.Context) ([]entities.Payment,
 	}
 	return payments, nil
 }
+
+// UpdatePaymentMethod fixes the method a payment was registered with, as long as its day is not closed
+func (p *PaymentUseCase) UpdatePaymentMethod(ctx context.Context, id uint, method valueobject.PaymentMethod) (*entities.Payment, error) {
+	if !method.IsValid() {
+		return nil, ErrInvalidPaymentMethod
+	}
+
+	paymentDTO, err := p.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorPaymentNotFound
+		}
+		return nil, err
+	}
+	if paymentDTO.CashClosingID != nil {
+		return nil, ErrPaymentLocked
+	}
+
+	if err := p.repo.UpdateMethod(ctx, id, method); err != nil {
+		return nil, err
+	}
+	paymentDTO.Method = method.String()
+	return paymentDTO.ToDomain(), nil
+}
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"go.uber.org/mock/gomock"
 	"gorm.io/gorm"
 
 	mocks "mecanica_xpto/internal/domain/mocks"
 	"mecanica_xpto/internal/domain/model/dto"
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
 	serviceordermocks "mecanica_xpto/internal/domain/usecase/mocks"
 )
 
@@ -21,7 +23,9 @@ func TestPaymentUseCase_CreatePayment(t *testing.T) {
 
 	mockPaymentRepo := mocks.NewMockIPaymentRepo(ctrl)
 	mockServiceOrderRepo := &serviceordermocks.MockServiceOrderRepository{}
-	u := NewPaymentUseCase(mockPaymentRepo, mockServiceOrderRepo)
+	mockFinancialRepo := &serviceordermocks.MockFinancialRepository{}
+	mockFinancialRepo.On("GetClosingByDate", ctx, mock.Anything).Return(nil, nil)
+	u := NewPaymentUseCase(mockPaymentRepo, mockServiceOrderRepo, mockFinancialRepo)
 
 	mockServiceOrderDTO := &dto.ServiceOrderDTO{ID: 1, Estimate: 100.0}
 	payment := &entities.Payment{ID: 1, ServiceOrderID: 1, Amount: 100.0}
@@ -38,6 +42,9 @@ func TestPaymentUseCase_CreatePayment(t *testing.T) {
 		if result == nil || result.ID != 1 {
 			t.Fatalf("unexpected result: %+v", result)
 		}
+		if len(payment.Events) != 1 || payment.Events[0].Type != valueobject.EventPaymentReceived {
+			t.Fatalf("expected a payment received event, got %+v", payment.Events)
+		}
 		mockServiceOrderRepo.AssertExpectations(t)
 	})
 
@@ -82,6 +89,26 @@ func TestPaymentUseCase_CreatePayment(t *testing.T) {
 		}
 		mockServiceOrderRepo.AssertExpectations(t)
 	})
+
+	t.Run("invalid method", func(t *testing.T) {
+		badPayment := &entities.Payment{ID: 5, ServiceOrderID: 1, Amount: 100.0, Method: "CHEQUE"}
+		_, err := u.CreatePayment(ctx, badPayment)
+		if !errors.Is(err, ErrInvalidPaymentMethod) {
+			t.Fatalf("expected ErrInvalidPaymentMethod, got %v", err)
+		}
+	})
+
+	t.Run("cash register closed", func(t *testing.T) {
+		closedFinancialRepo := &serviceordermocks.MockFinancialRepository{}
+		closedFinancialRepo.On("GetClosingByDate", ctx, mock.Anything).Return(&dto.CashClosingDTO{ID: 1}, nil)
+		closedUseCase := NewPaymentUseCase(mockPaymentRepo, mockServiceOrderRepo, closedFinancialRepo)
+		mockServiceOrderRepo.On("GetByID", uint(1)).Return(mockServiceOrderDTO, nil)
+		mockPaymentRepo.EXPECT().GetByServiceOrderID(ctx, uint(1)).Return(&dto.PaymentDTO{}, errors.New("not found"))
+		_, err := closedUseCase.CreatePayment(ctx, &entities.Payment{ServiceOrderID: 1, Amount: 100.0, Method: valueobject.PaymentPix})
+		if !errors.Is(err, ErrCashRegisterClosed) {
+			t.Fatalf("expected ErrCashRegisterClosed, got %v", err)
+		}
+	})
 }
 
 func TestPaymentUseCase_GetPaymentByID(t *testing.T) {
@@ -90,7 +117,7 @@ func TestPaymentUseCase_GetPaymentByID(t *testing.T) {
 	ctx := context.Background()
 	mockPaymentRepo := mocks.NewMockIPaymentRepo(ctrl)
 	mockServiceOrderRepo := &serviceordermocks.MockServiceOrderRepository{}
-	u := NewPaymentUseCase(mockPaymentRepo, mockServiceOrderRepo)
+	u := NewPaymentUseCase(mockPaymentRepo, mockServiceOrderRepo, &serviceordermocks.MockFinancialRepository{})
 
 	paymentDTO := &dto.PaymentDTO{ID: 1}
 
@@ -131,7 +158,7 @@ func TestPaymentUseCase_ListPayments(t *testing.T) {
 	ctx := context.Background()
 	mockPaymentRepo := mocks.NewMockIPaymentRepo(ctrl)
 	mockServiceOrderRepo := &serviceordermocks.MockServiceOrderRepository{}
-	u := NewPaymentUseCase(mockPaymentRepo, mockServiceOrderRepo)
+	u := NewPaymentUseCase(mockPaymentRepo, mockServiceOrderRepo, &serviceordermocks.MockFinancialRepository{})
 
 	dtos := []dto.PaymentDTO{{ID: 1}, {ID: 2}}
 
@@ -154,3 +181,48 @@ func TestPaymentUseCase_ListPayments(t *testing.T) {
 		}
 	})
 }
+
+func TestPaymentUseCase_UpdatePaymentMethod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockPaymentRepo := mocks.NewMockIPaymentRepo(ctrl)
+	u := NewPaymentUseCase(mockPaymentRepo, &serviceordermocks.MockServiceOrderRepository{}, &serviceordermocks.MockFinancialRepository{})
+
+	t.Run("success", func(t *testing.T) {
+		mockPaymentRepo.EXPECT().GetByID(ctx, uint(1)).Return(&dto.PaymentDTO{ID: 1, Method: "DINHEIRO"}, nil)
+		mockPaymentRepo.EXPECT().UpdateMethod(ctx, uint(1), valueobject.PaymentPix).Return(nil)
+		result, err := u.UpdatePaymentMethod(ctx, 1, valueobject.PaymentPix)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.Method != valueobject.PaymentPix {
+			t.Fatalf("expected method PIX, got %v", result.Method)
+		}
+	})
+
+	t.Run("payment locked by cash closing", func(t *testing.T) {
+		closingID := uint(7)
+		mockPaymentRepo.EXPECT().GetByID(ctx, uint(2)).Return(&dto.PaymentDTO{ID: 2, CashClosingID: &closingID}, nil)
+		_, err := u.UpdatePaymentMethod(ctx, 2, valueobject.PaymentPix)
+		if !errors.Is(err, ErrPaymentLocked) {
+			t.Fatalf("expected ErrPaymentLocked, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockPaymentRepo.EXPECT().GetByID(ctx, uint(3)).Return(nil, gorm.ErrRecordNotFound)
+		_, err := u.UpdatePaymentMethod(ctx, 3, valueobject.PaymentPix)
+		if !errors.Is(err, ErrorPaymentNotFound) {
+			t.Fatalf("expected ErrorPaymentNotFound, got %v", err)
+		}
+	})
+
+	t.Run("invalid method", func(t *testing.T) {
+		_, err := u.UpdatePaymentMethod(ctx, 1, "CHEQUE")
+		if !errors.Is(err, ErrInvalidPaymentMethod) {
+			t.Fatalf("expected ErrInvalidPaymentMethod, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/internal/domain/repository/users"
+)
+
+// serviceOrderStreamBuffer is how many updates a client can fall behind before new ones are dropped
+const serviceOrderStreamBuffer = 32
+
+// ServiceOrderStreamEventTypes lists the events pushed to the clients following service orders
+var ServiceOrderStreamEventTypes = []valueobject.DomainEventType{
+	valueobject.EventServiceOrderCreated,
+	valueobject.EventServiceOrderStatusChanged,
+	valueobject.EventAdditionalRepairCreated,
+	valueobject.EventAdditionalRepairApproved,
+	valueobject.EventPaymentReceived,
+}
+
+var ErrStreamUserNotFound = errors.New("user of the stream not found")
+
+type IServiceOrderStreamUseCase interface {
+	Subscribe(ctx context.Context, userEmail string, filter entities.ServiceOrderUpdateFilter) (<-chan entities.ServiceOrderUpdate, func(), error)
+	HandleDomainEvent(ctx context.Context, event entities.DomainEvent) error
+}
+
+// streamSubscriber is a connected client. customerUserID is set for customers, who only receive
+// the updates of their own service orders whatever filter they ask for.
+type streamSubscriber struct {
+	filter         entities.ServiceOrderUpdateFilter
+	customerUserID uint
+	updates        chan entities.ServiceOrderUpdate
+}
+
+// ServiceOrderStreamUseCase fans the service order events out to the clients connected to this
+// instance. Events reach it through the event dispatcher, so updates arrive every dispatch interval.
+type ServiceOrderStreamUseCase struct {
+	serviceOrderRepo serviceorder.IServiceOrderRepository
+	userRepo         users.IUserRepository
+	mu               sync.RWMutex
+	subscribers      map[*streamSubscriber]struct{}
+}
+
+var _ IServiceOrderStreamUseCase = (*ServiceOrderStreamUseCase)(nil)
+
+func NewServiceOrderStreamUseCase(serviceOrderRepo serviceorder.IServiceOrderRepository, userRepo users.IUserRepository) *ServiceOrderStreamUseCase {
+	return &ServiceOrderStreamUseCase{
+		serviceOrderRepo: serviceOrderRepo,
+		userRepo:         userRepo,
+		subscribers:      make(map[*streamSubscriber]struct{}),
+	}
+}
+
+// Subscribe registers a client and returns the channel its updates arrive on and the function
+// that unsubscribes it, which must be called when the client disconnects
+func (u *ServiceOrderStreamUseCase) Subscribe(ctx context.Context, userEmail string, filter entities.ServiceOrderUpdateFilter) (<-chan entities.ServiceOrderUpdate, func(), error) {
+	user, err := u.userRepo.GetByEmail(userEmail)
+	if err != nil || user == nil {
+		return nil, nil, ErrStreamUserNotFound
+	}
+
+	subscriber := &streamSubscriber{
+		filter:  filter,
+		updates: make(chan entities.ServiceOrderUpdate, serviceOrderStreamBuffer),
+	}
+	if user.UserType == valueobject.Customer {
+		subscriber.customerUserID = user.ID
+	}
+
+	u.mu.Lock()
+	u.subscribers[subscriber] = struct{}{}
+	u.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			u.mu.Lock()
+			delete(u.subscribers, subscriber)
+			u.mu.Unlock()
+			close(subscriber.updates)
+		})
+	}
+	return subscriber.updates, unsubscribe, nil
+}
+
+// HandleDomainEvent pushes a service order event to the clients whose filter matches it. A client
+// that fell too far behind misses the update instead of holding up the others.
+func (u *ServiceOrderStreamUseCase) HandleDomainEvent(ctx context.Context, event entities.DomainEvent) error {
+	if event.ServiceOrderID == 0 || u.subscriberCount() == 0 {
+		return nil
+	}
+
+	serviceOrderDto, err := u.serviceOrderRepo.GetByID(event.ServiceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error finding service order %d for event %d: %v", event.ServiceOrderID, event.ID, err)
+		return err
+	}
+	if serviceOrderDto == nil {
+		return nil
+	}
+
+	update := entities.ServiceOrderUpdate{
+		EventID:        event.ID,
+		Type:           event.Type,
+		ServiceOrderID: event.ServiceOrderID,
+		CustomerID:     serviceOrderDto.CustomerID,
+		Status:         serviceOrderDto.ServiceOrderStatus.Description,
+		Data:           event.Payload,
+		OccurredAt:     event.OccurredAt,
+		CustomerUserID: serviceOrderDto.Customer.UserID,
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	for subscriber := range u.subscribers {
+		if !subscriber.accepts(update) {
+			continue
+		}
+		select {
+		case subscriber.updates <- update:
+		default:
+			log.Error().Msgf("Service order stream client is behind, dropping event %d", event.ID)
+		}
+	}
+	return nil
+}
+
+func (u *ServiceOrderStreamUseCase) subscriberCount() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return len(u.subscribers)
+}
+
+func (s *streamSubscriber) accepts(update entities.ServiceOrderUpdate) bool {
+	if s.customerUserID != 0 && update.CustomerUserID != s.customerUserID {
+		return false
+	}
+	if s.filter.ServiceOrderID != 0 && update.ServiceOrderID != s.filter.ServiceOrderID {
+		return false
+	}
+	if s.filter.CustomerID != 0 && update.CustomerID != s.filter.CustomerID {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+func newServiceOrderStreamTestUseCase() (*ServiceOrderStreamUseCase, *MockServiceOrderRepository, *mocks.MockUserRepository) {
+	serviceOrderRepo := new(MockServiceOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("GetByEmail", "recepcao@xpto.com").Return(&dto.UserDTO{ID: 1, UserType: valueobject.Admin}, nil)
+	userRepo.On("GetByEmail", "maria@example.com").Return(&dto.UserDTO{ID: 20, UserType: valueobject.Customer}, nil)
+	return NewServiceOrderStreamUseCase(serviceOrderRepo, userRepo), serviceOrderRepo, userRepo
+}
+
+func streamServiceOrder(id, customerID, customerUserID uint) *dto.ServiceOrderDTO {
+	return &dto.ServiceOrderDTO{
+		ID:                 id,
+		CustomerID:         customerID,
+		Customer:           dto.CustomerDTO{ID: customerID, UserID: customerUserID},
+		ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusEmExecucao.String()},
+	}
+}
+
+// received drains the updates already pushed to a client
+func received(updates <-chan entities.ServiceOrderUpdate) []entities.ServiceOrderUpdate {
+	var result []entities.ServiceOrderUpdate
+	for {
+		select {
+		case update := <-updates:
+			result = append(result, update)
+		default:
+			return result
+		}
+	}
+}
+
+func TestServiceOrderStreamUseCase_HandleDomainEvent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pushes updates to the clients whose filter matches", func(t *testing.T) {
+		uc, serviceOrderRepo, _ := newServiceOrderStreamTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(streamServiceOrder(7, 3, 20), nil)
+		serviceOrderRepo.On("GetByID", uint(8)).Return(streamServiceOrder(8, 4, 21), nil)
+
+		board, stopBoard, err := uc.Subscribe(ctx, "recepcao@xpto.com", entities.ServiceOrderUpdateFilter{})
+		require.NoError(t, err)
+		defer stopBoard()
+		order, stopOrder, _ := uc.Subscribe(ctx, "recepcao@xpto.com", entities.ServiceOrderUpdateFilter{ServiceOrderID: 8})
+		defer stopOrder()
+		customer, stopCustomer, _ := uc.Subscribe(ctx, "recepcao@xpto.com", entities.ServiceOrderUpdateFilter{CustomerID: 3})
+		defer stopCustomer()
+
+		require.NoError(t, uc.HandleDomainEvent(ctx, entities.DomainEvent{
+			ID: 1, Type: valueobject.EventServiceOrderStatusChanged, ServiceOrderID: 7,
+			Payload: map[string]interface{}{"to": valueobject.StatusEmExecucao.String()},
+		}))
+		require.NoError(t, uc.HandleDomainEvent(ctx, entities.DomainEvent{
+			ID: 2, Type: valueobject.EventPaymentReceived, ServiceOrderID: 8,
+		}))
+
+		boardUpdates := received(board)
+		require.Len(t, boardUpdates, 2)
+		assert.Equal(t, uint(3), boardUpdates[0].CustomerID)
+		assert.Equal(t, valueobject.StatusEmExecucao.String(), boardUpdates[0].Status)
+		assert.Equal(t, valueobject.StatusEmExecucao.String(), boardUpdates[0].Data["to"])
+
+		orderUpdates := received(order)
+		require.Len(t, orderUpdates, 1)
+		assert.Equal(t, uint(2), orderUpdates[0].EventID)
+
+		customerUpdates := received(customer)
+		require.Len(t, customerUpdates, 1)
+		assert.Equal(t, uint(1), customerUpdates[0].EventID)
+	})
+
+	t.Run("customers only receive their own service orders", func(t *testing.T) {
+		uc, serviceOrderRepo, _ := newServiceOrderStreamTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(streamServiceOrder(7, 3, 20), nil)
+		serviceOrderRepo.On("GetByID", uint(8)).Return(streamServiceOrder(8, 4, 21), nil)
+
+		updates, stop, err := uc.Subscribe(ctx, "maria@example.com", entities.ServiceOrderUpdateFilter{ServiceOrderID: 8})
+		require.NoError(t, err)
+		defer stop()
+		all, stopAll, _ := uc.Subscribe(ctx, "maria@example.com", entities.ServiceOrderUpdateFilter{})
+		defer stopAll()
+
+		require.NoError(t, uc.HandleDomainEvent(ctx, entities.DomainEvent{ID: 1, Type: valueobject.EventAdditionalRepairCreated, ServiceOrderID: 7}))
+		require.NoError(t, uc.HandleDomainEvent(ctx, entities.DomainEvent{ID: 2, Type: valueobject.EventPaymentReceived, ServiceOrderID: 8}))
+
+		assert.Empty(t, received(updates))
+		allUpdates := received(all)
+		require.Len(t, allUpdates, 1)
+		assert.Equal(t, uint(7), allUpdates[0].ServiceOrderID)
+	})
+
+	t.Run("does not load the service order without clients", func(t *testing.T) {
+		uc, serviceOrderRepo, _ := newServiceOrderStreamTestUseCase()
+		_, stop, _ := uc.Subscribe(ctx, "recepcao@xpto.com", entities.ServiceOrderUpdateFilter{})
+		stop()
+
+		require.NoError(t, uc.HandleDomainEvent(ctx, entities.DomainEvent{ID: 1, Type: valueobject.EventPaymentReceived, ServiceOrderID: 7}))
+
+		serviceOrderRepo.AssertNotCalled(t, "GetByID", uint(7))
+	})
+
+	t.Run("drops updates of clients that fell behind", func(t *testing.T) {
+		uc, serviceOrderRepo, _ := newServiceOrderStreamTestUseCase()
+		serviceOrderRepo.On("GetByID", uint(7)).Return(streamServiceOrder(7, 3, 20), nil)
+		updates, stop, _ := uc.Subscribe(ctx, "recepcao@xpto.com", entities.ServiceOrderUpdateFilter{})
+		defer stop()
+
+		for i := 0; i < serviceOrderStreamBuffer+5; i++ {
+			require.NoError(t, uc.HandleDomainEvent(ctx, entities.DomainEvent{ID: uint(i + 1), Type: valueobject.EventPaymentReceived, ServiceOrderID: 7}))
+		}
+
+		assert.Len(t, received(updates), serviceOrderStreamBuffer)
+	})
+}
+
+func TestServiceOrderStreamUseCase_Subscribe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("closes the channel on unsubscribe", func(t *testing.T) {
+		uc, _, _ := newServiceOrderStreamTestUseCase()
+		updates, stop, err := uc.Subscribe(ctx, "recepcao@xpto.com", entities.ServiceOrderUpdateFilter{})
+		require.NoError(t, err)
+
+		stop()
+		stop()
+
+		_, open := <-updates
+		assert.False(t, open)
+		assert.Zero(t, uc.subscriberCount())
+	})
+
+	t.Run("rejects unknown users", func(t *testing.T) {
+		uc, _, userRepo := newServiceOrderStreamTestUseCase()
+		userRepo.On("GetByEmail", "ghost@xpto.com").Return(nil, errors.New("record not found"))
+
+		_, _, err := uc.Subscribe(ctx, "ghost@xpto.com", entities.ServiceOrderUpdateFilter{})
+
+		assert.ErrorIs(t, err, ErrStreamUserNotFound)
+	})
+}
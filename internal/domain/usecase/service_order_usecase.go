@@ -10,10 +10,12 @@ import (
 
 	"mecanica_xpto/internal/domain/model/entities"
 	"mecanica_xpto/internal/domain/model/valueobject"
+	corporateaccount "mecanica_xpto/internal/domain/repository/corporate_account"
 	customerRepo "mecanica_xpto/internal/domain/repository/customers"
 	"mecanica_xpto/internal/domain/repository/service"
 	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
 	"mecanica_xpto/internal/domain/repository/vehicles"
+	"time"
 )
 
 // operation flow
@@ -33,6 +35,7 @@ var (
 	ErrInvalidStatus                      = errors.New("invalid service order status")
 	ErrInsufficientPartsSupply            = errors.New("insufficient parts supply available")
 	ErrInvalidFlow                        = errors.New("invalid flow")
+	ErrAdditionalRepairPending            = errors.New("service order has additional repairs pending customer approval")
 )
 
 type IServiceOrderUseCase interface {
@@ -48,23 +51,32 @@ type ServiceOrderUseCase struct {
 	customerRepo    customerRepo.ICustomerRepository
 	serviceRepo     service.IServiceRepo
 	partsSupplyRepo parts_supply.IPartsSupplyRepo
+	discountUseCase IDiscountUseCase
+	taxUseCase      ITaxUseCase
+	invoiceUseCase  IInvoiceUseCase
+	corporateRepo   corporateaccount.ICorporateAccountRepository
 }
 
 var _ IServiceOrderUseCase = (*ServiceOrderUseCase)(nil)
 
-func NewServiceOrderUseCase(repo serviceorder.IServiceOrderRepository, vehicleRepo vehicles.VehicleRepositoryInterface, customerRepo customerRepo.ICustomerRepository, serviceRepo service.IServiceRepo, partsSupplyRepo parts_supply.IPartsSupplyRepo) *ServiceOrderUseCase {
+func NewServiceOrderUseCase(repo serviceorder.IServiceOrderRepository, vehicleRepo vehicles.VehicleRepositoryInterface, customerRepo customerRepo.ICustomerRepository, serviceRepo service.IServiceRepo, partsSupplyRepo parts_supply.IPartsSupplyRepo, discountUseCase IDiscountUseCase, taxUseCase ITaxUseCase, invoiceUseCase IInvoiceUseCase, corporateRepo corporateaccount.ICorporateAccountRepository) *ServiceOrderUseCase {
 	return &ServiceOrderUseCase{
 		repo:            repo,
 		vehicleRepo:     vehicleRepo,
 		customerRepo:    customerRepo,
 		serviceRepo:     serviceRepo,
 		partsSupplyRepo: partsSupplyRepo,
+		discountUseCase: discountUseCase,
+		taxUseCase:      taxUseCase,
+		invoiceUseCase:  invoiceUseCase,
+		corporateRepo:   corporateRepo,
 	}
 }
 
 // CreateServiceOrder creates a new service order after validating the vehicle and customer.
 // It sets the initial status of the service order to "Recebida".
 // If the vehicle or customer validation fails, it logs the error and returns it.
+// A company whose corporate account owes its whole credit limit cannot open new orders.
 func (u *ServiceOrderUseCase) CreateServiceOrder(ctx context.Context, serviceOrder entities.ServiceOrder) (*entities.ServiceOrder, error) {
 	err := validateVehicle(ctx, serviceOrder, u.vehicleRepo)
 	if err != nil {
@@ -78,10 +90,17 @@ func (u *ServiceOrderUseCase) CreateServiceOrder(ctx context.Context, serviceOrd
 		return nil, err
 	}
 
+	err = checkCreditLimit(ctx, u.corporateRepo, serviceOrder.CustomerID, 0)
+	if err != nil {
+		log.Error().Msgf("Error checking credit limit of customer %d: %v", serviceOrder.CustomerID, err)
+		return nil, err
+	}
+
 	newServiceOrder := entities.ServiceOrder{
 		CustomerID:         serviceOrder.CustomerID,
 		VehicleID:          serviceOrder.VehicleID,
 		ServiceOrderStatus: valueobject.StatusRecebida,
+		Events:             []entities.DomainEvent{entities.NewServiceOrderCreatedEvent(serviceOrder.CustomerID, serviceOrder.VehicleID)},
 	}
 
 	register, err := u.repo.Create(&newServiceOrder)
@@ -117,6 +136,10 @@ func (u *ServiceOrderUseCase) UpdateServiceOrder(ctx context.Context, request en
 		return nil, ErrServiceOrderNotFound
 	}
 
+	var pricing *entities.EstimateBreakdown
+	// Parts supplies reserved by the diagnosis, returned to stock if it cannot be stored
+	var reserved []entities.PartsSupply
+
 	switch flow {
 	case DIAGNOSIS:
 		update, err = ValidateDiagnosis(ctx, &request, serviceOrderDto, update, u.serviceRepo, u.partsSupplyRepo, u.repo)
@@ -124,12 +147,46 @@ func (u *ServiceOrderUseCase) UpdateServiceOrder(ctx context.Context, request en
 			log.Error().Msgf("Error validating diagnosis: %v", err)
 			return nil, err
 		}
+		if update.ServiceOrderStatus.IsAguardandoAprovacao() {
+			reserved = update.PartsSupplies
+			pricing, err = u.applyDiscounts(ctx, &request, serviceOrderDto, update)
+			if err != nil {
+				log.Error().Msgf("Error applying discounts: %v", err)
+				unreserveDiagnosis(ctx, reserved, u.partsSupplyRepo)
+				return nil, err
+			}
+			pricing.Taxes, err = u.taxUseCase.CalculateTaxes(ctx, *pricing)
+			if err != nil {
+				log.Error().Msgf("Error calculating taxes: %v", err)
+				unreserveDiagnosis(ctx, reserved, u.partsSupplyRepo)
+				return nil, err
+			}
+			// The open balance already counts the previous estimate of the order
+			err = checkCreditLimit(ctx, u.corporateRepo, serviceOrderDto.CustomerID, update.Estimate-serviceOrderDto.Estimate)
+			if err != nil {
+				log.Error().Msgf("Error checking credit limit of customer %d: %v", serviceOrderDto.CustomerID, err)
+				unreserveDiagnosis(ctx, reserved, u.partsSupplyRepo)
+				return nil, err
+			}
+		}
 	case ESTIMATE:
+		// Checked before validating, which already releases the stock of an approved estimate
+		approving := serviceOrderDto.ServiceOrderStatus.ToDomain().IsAguardandoAprovacao() && request.ServiceOrderStatus.IsAprovada()
+		if approving {
+			err = checkEstimateApprover(ctx, u.corporateRepo, serviceOrderDto.CustomerID, request.ApproverContactID)
+			if err != nil {
+				log.Error().Msgf("Error checking estimate approver: %v", err)
+				return nil, err
+			}
+		}
 		update, err = ValidateEstimate(ctx, &request, serviceOrderDto, update, u.partsSupplyRepo, u.repo)
 		if err != nil {
 			log.Error().Msgf("Error validating estimate: %v", err)
 			return nil, err
 		}
+		if approving {
+			update.ApproverContactID = request.ApproverContactID
+		}
 	case EXECUTION:
 		update, err = ValidateExecution(ctx, &request, serviceOrderDto, update)
 		if err != nil {
@@ -137,7 +194,13 @@ func (u *ServiceOrderUseCase) UpdateServiceOrder(ctx context.Context, request en
 			return nil, err
 		}
 	case DELIVERY:
-		update, err = ValidateDelivery(ctx, &request, serviceOrderDto, update)
+		var billedMonthly bool
+		billedMonthly, err = isBilledMonthly(ctx, u.corporateRepo, serviceOrderDto.CustomerID)
+		if err != nil {
+			log.Error().Msgf("Error finding corporate account of customer %d: %v", serviceOrderDto.CustomerID, err)
+			return nil, err
+		}
+		update, err = ValidateDelivery(ctx, &request, serviceOrderDto, update, billedMonthly)
 		if err != nil {
 			log.Error().Msgf("Error validating delivery: %v", err)
 			return nil, err
@@ -147,12 +210,34 @@ func (u *ServiceOrderUseCase) UpdateServiceOrder(ctx context.Context, request en
 		return nil, ErrInvalidFlow
 	}
 
+	currentStatus := serviceOrderDto.ServiceOrderStatus.ToDomain()
+	if update.ServiceOrderStatus != "" && update.ServiceOrderStatus != currentStatus {
+		update.Events = append(update.Events,
+			entities.NewServiceOrderStatusChangedEvent(update.ID, currentStatus, update.ServiceOrderStatus))
+	}
+
+	// The coupon is redeemed, and the one it replaces released, with the pricing and the order.
+	// A rejected or cancelled order gives its coupon back.
+	update.Pricing = pricing
+	update.ReleaseCoupon = update.ServiceOrderStatus.IsRejeitada() || update.ServiceOrderStatus.IsCancelada()
 	err = u.repo.Update(update)
 	if err != nil {
 		log.Error().Msgf("Error updating service order: %v", err)
+		unreserveDiagnosis(ctx, reserved, u.partsSupplyRepo)
+		if errors.Is(err, serviceorder.ErrCouponUsedUp) {
+			return nil, ErrCouponUsageLimitReached
+		}
 		return nil, err
 	}
 
+	// Invoices are issued as soon as the vehicle is delivered. A failure must not undo the
+	// delivery: the invoices can be issued again through the invoices endpoint.
+	if flow == DELIVERY && update.ServiceOrderStatus.IsEntregue() {
+		if _, err := u.invoiceUseCase.IssueInvoices(ctx, update.ID); err != nil {
+			log.Error().Msgf("Error issuing invoices of service order %d: %v", update.ID, err)
+		}
+	}
+
 	updatedSODTO, err := u.repo.GetByID(request.ID)
 	if err != nil || updatedSODTO == nil {
 		log.Error().Msgf("Error finding service order with id %v: %v", request.ID, err)
@@ -167,6 +252,34 @@ func (u *ServiceOrderUseCase) UpdateServiceOrder(ctx context.Context, request en
 	return updatedSO, nil
 }
 
+// applyDiscounts prices the diagnosed estimate with the customer's price agreements, the
+// line and order discounts and the coupon. The coupon is redeemed when the pricing is stored.
+func (u *ServiceOrderUseCase) applyDiscounts(ctx context.Context, request *entities.ServiceOrder, serviceOrderDto *dto.ServiceOrderDTO, update *entities.ServiceOrder) (*entities.EstimateBreakdown, error) {
+	pricing, err := u.discountUseCase.PriceEstimate(ctx, serviceOrderDto.CustomerID, update.Services, update.PartsSupplies, request.Discount, request.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range pricing.Lines {
+		if line.Discount == 0 {
+			continue
+		}
+		for i := range update.Services {
+			if line.ServiceID != 0 && update.Services[i].ID == line.ServiceID && update.Services[i].Discount != nil {
+				update.Services[i].Discount.Amount = line.Discount
+			}
+		}
+		for i := range update.PartsSupplies {
+			if line.PartsSupplyID != 0 && update.PartsSupplies[i].ID == line.PartsSupplyID && update.PartsSupplies[i].Discount != nil {
+				update.PartsSupplies[i].Discount.Amount = line.Discount
+			}
+		}
+	}
+
+	update.Estimate = pricing.Net
+	return pricing, nil
+}
+
 // ValidateDiagnosis checks if the service order status is valid for diagnosis.
 // If the status is "Recebida" or "EmDiagnostico" and the request status is "EmDiagnostico",
 // it updates the service order status to "EmDiagnostico".
@@ -220,11 +333,12 @@ func ValidateDiagnosis(ctx context.Context, request *entities.ServiceOrder, serv
 			}
 
 			// Reserve each PartsSupply
-			for _, ps := range request.PartsSupplies {
+			for i, ps := range request.PartsSupplies {
 				// Reserve the parts supply
-				err := reservePartsSupply(ctx, ps, partsSupplyRepo)
+				err := reservePartsSupply(ctx, request.ID, ps, partsSupplyRepo)
 				if err != nil {
 					log.Error().Msgf("Error reserving parts supply: %v", err)
+					unreserveDiagnosis(ctx, request.PartsSupplies[:i], partsSupplyRepo)
 					return nil, err
 				}
 			}
@@ -238,6 +352,7 @@ func ValidateDiagnosis(ctx context.Context, request *entities.ServiceOrder, serv
 		update.Estimate, err = CalculateEstimate(ctx, update.Services, update.PartsSupplies, serviceRepo, partsSupplyRepo)
 		if err != nil {
 			log.Error().Msgf("Error calculating estimate: %v", err)
+			unreserveDiagnosis(ctx, update.PartsSupplies, partsSupplyRepo)
 			return nil, err
 		}
 
@@ -294,6 +409,9 @@ func ValidateEstimate(ctx context.Context, request *entities.ServiceOrder, servi
 	}
 
 	if oldStatus.IsAguardandoAprovacao() && request.ServiceOrderStatus.IsAprovada() {
+		if serviceOrderDto.DiscountPending {
+			return nil, ErrDiscountApprovalPending
+		}
 		update.ServiceOrderStatus = valueobject.StatusAprovada
 		// If the status is "Aprovada", we can subtract the total available quantity of PartsSupplies from the quantity reserve
 		partsSupplies, err := getPartsSuppliesByServiceOrderID(ctx, serviceOrderDto.ID, partsSupplyRepo)
@@ -372,7 +490,9 @@ func ValidateExecution(ctx context.Context, request *entities.ServiceOrder, serv
 	return nil, ErrInvalidTransitionStatusToExecution
 }
 
-func ValidateDelivery(ctx context.Context, request *entities.ServiceOrder, serviceOrderDto *dto.ServiceOrderDTO, update *entities.ServiceOrder) (*entities.ServiceOrder, error) {
+// ValidateDelivery checks the order can be handed over: it must be paid, unless the customer is
+// billed monthly through a corporate account, and have no additional repair pending.
+func ValidateDelivery(ctx context.Context, request *entities.ServiceOrder, serviceOrderDto *dto.ServiceOrderDTO, update *entities.ServiceOrder, billedMonthly bool) (*entities.ServiceOrder, error) {
 	oldStatus := serviceOrderDto.ServiceOrderStatus.ToDomain()
 
 	if !request.ServiceOrderStatus.IsValid() {
@@ -380,11 +500,19 @@ func ValidateDelivery(ctx context.Context, request *entities.ServiceOrder, servi
 	}
 
 	if oldStatus.IsFinalizada() && request.ServiceOrderStatus.IsEntregue() {
-		if serviceOrderDto.Payment == nil {
+		if serviceOrderDto.Payment == nil && !billedMonthly {
 			log.Error().Msg("Payment information is required for delivery")
 			return nil, errors.New("payment information is required for delivery")
 		}
+		for _, ar := range serviceOrderDto.AdditionalRepairs {
+			if ar.ARStatus.ToDomain().IsPending() {
+				log.Error().Msgf("Additional repair %d is still %s", ar.ID, ar.ARStatus.Description)
+				return nil, ErrAdditionalRepairPending
+			}
+		}
 		update.ServiceOrderStatus = valueobject.StatusEntregue
+		deliveredAt := time.Now()
+		update.DeliveredAt = &deliveredAt
 		return update, nil
 	}
 	return nil, ErrInvalidTransitionStatusToDelivery
@@ -467,20 +595,25 @@ func validateQttPartsSupply(ctx context.Context, partsSupply entities.PartsSuppl
 	return nil
 }
 
-func reservePartsSupply(ctx context.Context, partsSupply entities.PartsSupply, partsSupplyRepo parts_supply.IPartsSupplyRepo) error {
+// reservePartsSupply reserves stock for a service order, recording a StockReserved event with it
+func reservePartsSupply(ctx context.Context, serviceOrderID uint, partsSupply entities.PartsSupply, partsSupplyRepo parts_supply.IPartsSupplyRepo) error {
 	current, err := getPartsSupplyByID(ctx, partsSupply.ID, partsSupplyRepo)
 	if err != nil {
 		log.Error().Msgf("error getting parts supply by ID: %v", err)
 		return err
 	}
 
-	if partsSupply.QuantityReserve > 0 {
-		current.QuantityReserve += partsSupply.QuantityReserve
-	} else if partsSupply.QuantityTotal > 0 {
-		current.QuantityReserve += partsSupply.QuantityTotal
-	} else {
+	quantity := partsSupply.QuantityReserve
+	if quantity <= 0 {
+		quantity = partsSupply.QuantityTotal
+	}
+	if quantity <= 0 {
 		return errors.New("no quantity to reserve")
 	}
+	current.QuantityReserve += quantity
+	current.Events = []entities.DomainEvent{
+		entities.NewStockReservedEvent(current.ID, serviceOrderID, quantity, current.QuantityReserve),
+	}
 
 	err = partsSupplyRepo.Update(ctx, current)
 	if err != nil {
@@ -559,6 +692,21 @@ func unreservePartsSupply(ctx context.Context, partsSupply entities.PartsSupply,
 	return nil
 }
 
+// unreserveDiagnosis returns to stock the parts supplies reserved by a diagnosis that failed.
+// Failures are only logged, as the error of the diagnosis is the one returned.
+func unreserveDiagnosis(ctx context.Context, partsSupplies []entities.PartsSupply, partsSupplyRepo parts_supply.IPartsSupplyRepo) {
+	for _, ps := range partsSupplies {
+		quantity := ps.QuantityReserve
+		if quantity <= 0 {
+			quantity = ps.QuantityTotal
+		}
+		err := unreservePartsSupply(ctx, entities.PartsSupply{ID: ps.ID, QuantityReserve: quantity}, partsSupplyRepo)
+		if err != nil {
+			log.Error().Msgf("Error returning parts supply %d of a failed diagnosis to stock: %v", ps.ID, err)
+		}
+	}
+}
+
 func (u *ServiceOrderUseCase) GetServiceOrder(ctx context.Context, serviceOrder entities.ServiceOrder) (*entities.ServiceOrder, error) {
 	serviceOrderDto, err := u.repo.GetByID(serviceOrder.ID)
 	if err != nil {
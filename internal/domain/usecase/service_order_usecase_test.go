@@ -3,14 +3,18 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"mecanica_xpto/internal/domain/model/dto"
 	"mecanica_xpto/internal/domain/model/entities"
 	"mecanica_xpto/internal/domain/model/valueobject"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/internal/domain/usecase/mocks"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -129,6 +133,11 @@ func (m *MockVehicleRepository) Create(vehicle entities.Vehicle) error {
 	return args.Error(0)
 }
 
+func (m *MockVehicleRepository) CreateBatch(vehicles []entities.Vehicle) error {
+	args := m.Called(vehicles)
+	return args.Error(0)
+}
+
 func (m *MockVehicleRepository) Update(vehicle entities.Vehicle) error {
 	args := m.Called(vehicle)
 	if args.Get(0) == nil {
@@ -197,6 +206,19 @@ func (m *MockServiceOrderRepository) UpdateEstimate(id uint, estimate float64) e
 	return args.Error(0)
 }
 
+func (m *MockServiceOrderRepository) GetByIDWithItems(id uint) (*dto.ServiceOrderDTO, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ServiceOrderDTO), args.Error(1)
+}
+
+func (m *MockServiceOrderRepository) ApproveDiscount(id uint, approvedBy string, approvedAt time.Time) error {
+	args := m.Called(id, approvedBy, approvedAt)
+	return args.Error(0)
+}
+
 func (m *MockServiceOrderRepository) GetByName(ctx context.Context, name string) (entities.Service, error) {
 	args := m.Called(ctx, name)
 	if args.Get(0) == nil {
@@ -238,6 +260,14 @@ func (m *MockServiceOrderRepository) List() ([]dto.ServiceOrderDTO, error) {
 	return args.Get(0).([]dto.ServiceOrderDTO), args.Error(1)
 }
 
+func (m *MockServiceOrderRepository) ListByVehicle(vehicleID uint) ([]dto.ServiceOrderDTO, error) {
+	args := m.Called(vehicleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.ServiceOrderDTO), args.Error(1)
+}
+
 func (m *MockServiceOrderRepository) GetStatus(status valueobject.ServiceOrderStatus) (*dto.ServiceOrderStatusDTO, error) {
 	args := m.Called(status)
 	if args.Get(0) == nil {
@@ -309,6 +339,13 @@ func (m *MockPartsSupplyRepository) GetByServiceOrderID(ctx context.Context, ser
 	return args.Get(0).([]entities.PartsSupply), args.Error(1)
 }
 
+// noCorporateAccount is the corporate account repository of customers paying each order
+func noCorporateAccount() *mocks.MockCorporateAccountRepository {
+	corporateRepo := new(mocks.MockCorporateAccountRepository)
+	corporateRepo.On("GetByCustomer", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	return corporateRepo
+}
+
 func TestCreateServiceOrder(t *testing.T) {
 	vehicleRepo := new(MockVehicleRepository)
 	customerRepo := new(MockCustomerRepository)
@@ -316,7 +353,13 @@ func TestCreateServiceOrder(t *testing.T) {
 	serviceRepo := new(MockServiceRepository)
 	partsSupplyRepo := new(MockPartsSupplyRepository)
 
-	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo)
+	discountRepo := new(mocks.MockDiscountRepository)
+	discountUseCase := NewDiscountUseCase(discountRepo, serviceOrderRepo, new(mocks.MockUserRepository), customerRepo, serviceRepo, partsSupplyRepo, 10)
+
+	taxRepo := new(mocks.MockTaxRepository)
+	taxUseCase := NewTaxUseCase(taxRepo, 5, 18)
+
+	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo, discountUseCase, taxUseCase, new(mocks.MockInvoiceUseCase), noCorporateAccount())
 
 	tests := []struct {
 		name          string
@@ -381,6 +424,115 @@ func TestCreateServiceOrder(t *testing.T) {
 	}
 }
 
+func TestCreateServiceOrder_CreditLimit(t *testing.T) {
+	ctx := context.Background()
+	request := entities.ServiceOrder{CustomerID: 3, VehicleID: 3}
+
+	newUseCase := func(balance float64, balanceErr error) (*ServiceOrderUseCase, *MockServiceOrderRepository) {
+		vehicleRepo := new(MockVehicleRepository)
+		customerRepo := new(MockCustomerRepository)
+		serviceOrderRepo := new(MockServiceOrderRepository)
+		corporateRepo := new(mocks.MockCorporateAccountRepository)
+		vehicleRepo.On("FindByID", uint(3)).Return(&dto.VehicleDTO{ID: 3, CustomerID: 3}, nil)
+		customerRepo.On("GetByID", uint(3)).Return(&dto.CustomerDTO{ID: 3}, nil)
+		corporateRepo.On("GetByCustomer", ctx, uint(3)).Return(&dto.CorporateAccountDTO{ID: 1, CustomerID: 3, CreditLimit: 5000}, nil)
+		corporateRepo.On("OpenBalance", ctx, uint(3)).Return(balance, balanceErr)
+		serviceOrderRepo.On("Create", mock.AnythingOfType("*entities.ServiceOrder")).Return(&entities.ServiceOrder{ID: 1, CustomerID: 3, VehicleID: 3}, nil)
+
+		useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, new(MockServiceRepository), new(MockPartsSupplyRepository), nil, nil, new(mocks.MockInvoiceUseCase), corporateRepo)
+		return useCase, serviceOrderRepo
+	}
+
+	t.Run("Success - account below its limit", func(t *testing.T) {
+		useCase, serviceOrderRepo := newUseCase(4999.99, nil)
+
+		_, err := useCase.CreateServiceOrder(ctx, request)
+		assert.NoError(t, err)
+		serviceOrderRepo.AssertCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Success - account at exactly its limit, as the new order owes nothing yet", func(t *testing.T) {
+		useCase, serviceOrderRepo := newUseCase(5000, nil)
+
+		_, err := useCase.CreateServiceOrder(ctx, request)
+		assert.NoError(t, err)
+		serviceOrderRepo.AssertCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Error - account beyond its limit", func(t *testing.T) {
+		useCase, serviceOrderRepo := newUseCase(5000.01, nil)
+
+		_, err := useCase.CreateServiceOrder(ctx, request)
+		assert.ErrorIs(t, err, ErrCreditLimitExceeded)
+		serviceOrderRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Error - balance could not be read", func(t *testing.T) {
+		useCase, serviceOrderRepo := newUseCase(0, errors.New("db down"))
+
+		_, err := useCase.CreateServiceOrder(ctx, request)
+		assert.ErrorIs(t, err, ErrCreditCheckFailed)
+		serviceOrderRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestUpdateServiceOrder_CreditLimit(t *testing.T) {
+	ctx := context.Background()
+	diagnosis := entities.ServiceOrder{
+		ID:                 1,
+		ServiceOrderStatus: valueobject.StatusEmDiagnostico,
+		Services:           []entities.Service{{ID: 1}},
+		PartsSupplies:      []entities.PartsSupply{{ID: 1, QuantityReserve: 2}},
+	}
+
+	newUseCase := func(balance float64) (*ServiceOrderUseCase, *MockServiceOrderRepository, *MockPartsSupplyRepository) {
+		customerRepo := new(MockCustomerRepository)
+		serviceOrderRepo := new(MockServiceOrderRepository)
+		serviceRepo := new(MockServiceRepository)
+		partsSupplyRepo := new(MockPartsSupplyRepository)
+		discountRepo := new(mocks.MockDiscountRepository)
+		taxRepo := new(mocks.MockTaxRepository)
+		corporateRepo := new(mocks.MockCorporateAccountRepository)
+
+		serviceOrderRepo.On("GetByID", uint(1)).Return(&dto.ServiceOrderDTO{
+			ID:                 1,
+			CustomerID:         3,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{ID: 2, Description: StatusEmDiagnostico},
+		}, nil)
+		serviceOrderRepo.On("Update", mock.AnythingOfType("*entities.ServiceOrder")).Return(nil)
+		serviceRepo.On("GetByID", mock.Anything, uint(1)).Return(entities.Service{ID: 1, Price: 100}, nil)
+		partsSupplyRepo.On("GetByID", mock.Anything, uint(1)).Return(entities.PartsSupply{ID: 1, Price: 25, QuantityTotal: 10, QuantityReserve: 2}, nil)
+		partsSupplyRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.PartsSupply")).Return(nil)
+		discountRepo.On("ListPriceAgreementsByCustomer", mock.Anything, uint(3)).Return([]dto.PriceAgreementDTO{}, nil)
+		taxRepo.On("ListRules", mock.Anything).Return([]dto.TaxRuleDTO{}, nil)
+		corporateRepo.On("GetByCustomer", ctx, uint(3)).Return(&dto.CorporateAccountDTO{ID: 1, CustomerID: 3, CreditLimit: 5000}, nil)
+		corporateRepo.On("OpenBalance", ctx, uint(3)).Return(balance, nil)
+
+		discountUseCase := NewDiscountUseCase(discountRepo, serviceOrderRepo, new(mocks.MockUserRepository), customerRepo, serviceRepo, partsSupplyRepo, 50)
+		useCase := NewServiceOrderUseCase(serviceOrderRepo, new(MockVehicleRepository), customerRepo, serviceRepo, partsSupplyRepo, discountUseCase, NewTaxUseCase(taxRepo, 5, 18), new(mocks.MockInvoiceUseCase), corporateRepo)
+		return useCase, serviceOrderRepo, partsSupplyRepo
+	}
+
+	t.Run("Success - the estimate reaches the limit", func(t *testing.T) {
+		useCase, serviceOrderRepo, _ := newUseCase(4850)
+
+		_, err := useCase.UpdateServiceOrder(ctx, diagnosis, DIAGNOSIS)
+		assert.NoError(t, err)
+		serviceOrderRepo.AssertCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Error - the estimate goes beyond the limit, so the reserved parts go back to stock", func(t *testing.T) {
+		useCase, serviceOrderRepo, partsSupplyRepo := newUseCase(4850.01)
+
+		_, err := useCase.UpdateServiceOrder(ctx, diagnosis, DIAGNOSIS)
+		assert.ErrorIs(t, err, ErrCreditLimitExceeded)
+		serviceOrderRepo.AssertNotCalled(t, "Update", mock.Anything)
+		partsSupplyRepo.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(ps *entities.PartsSupply) bool {
+			return ps.ID == 1 && ps.QuantityReserve == 0
+		}))
+	})
+}
+
 func TestUpdateServiceOrder(t *testing.T) {
 	vehicleRepo := new(MockVehicleRepository)
 	customerRepo := new(MockCustomerRepository)
@@ -388,7 +540,13 @@ func TestUpdateServiceOrder(t *testing.T) {
 	serviceRepo := new(MockServiceRepository)
 	partsSupplyRepo := new(MockPartsSupplyRepository)
 
-	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo)
+	discountRepo := new(mocks.MockDiscountRepository)
+	discountUseCase := NewDiscountUseCase(discountRepo, serviceOrderRepo, new(mocks.MockUserRepository), customerRepo, serviceRepo, partsSupplyRepo, 10)
+
+	taxRepo := new(mocks.MockTaxRepository)
+	taxUseCase := NewTaxUseCase(taxRepo, 5, 18)
+
+	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo, discountUseCase, taxUseCase, new(mocks.MockInvoiceUseCase), noCorporateAccount())
 
 	setupMocks := func() {
 		serviceOrderRepo.On("GetByID", uint(1)).Return(&dto.ServiceOrderDTO{
@@ -406,6 +564,8 @@ func TestUpdateServiceOrder(t *testing.T) {
 		}, nil)
 		partsSupplyRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.PartsSupply")).Return(nil)
 		serviceOrderRepo.On("Update", mock.AnythingOfType("*entities.ServiceOrder")).Return(nil)
+		discountRepo.On("ListPriceAgreementsByCustomer", mock.Anything, mock.Anything).Return([]dto.PriceAgreementDTO{}, nil)
+		taxRepo.On("ListRules", mock.Anything).Return([]dto.TaxRuleDTO{}, nil)
 	}
 	tests := []struct {
 		name          string
@@ -463,11 +623,233 @@ func TestUpdateServiceOrder(t *testing.T) {
 			} else {
 				assert.NotNil(t, r)
 				assert.NoError(t, err)
+				serviceOrderRepo.AssertCalled(t, "Update", mock.MatchedBy(func(so *entities.ServiceOrder) bool {
+					return len(so.Events) == 1 &&
+						so.Events[0].Type == valueobject.EventServiceOrderStatusChanged &&
+						so.Events[0].Payload["to"] == valueobject.StatusAguardandoAprovacao.String()
+				}))
 			}
 		})
 	}
 }
 
+func TestUpdateServiceOrder_Coupon(t *testing.T) {
+	ctx := context.Background()
+	diagnosis := entities.ServiceOrder{
+		ID:                 1,
+		ServiceOrderStatus: valueobject.StatusEmDiagnostico,
+		CouponCode:         "PROMO20",
+		Services:           []entities.Service{{ID: 1}},
+		PartsSupplies:      []entities.PartsSupply{{ID: 1, QuantityReserve: 2}},
+	}
+
+	newUseCase := func() (*ServiceOrderUseCase, *MockServiceOrderRepository, *MockPartsSupplyRepository) {
+		customerRepo := new(MockCustomerRepository)
+		serviceOrderRepo := new(MockServiceOrderRepository)
+		serviceRepo := new(MockServiceRepository)
+		partsSupplyRepo := new(MockPartsSupplyRepository)
+		discountRepo := new(mocks.MockDiscountRepository)
+		taxRepo := new(mocks.MockTaxRepository)
+
+		serviceOrderRepo.On("GetByID", uint(1)).Return(&dto.ServiceOrderDTO{
+			ID:                 1,
+			CustomerID:         3,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{ID: 1, Description: StatusRecebida},
+		}, nil)
+		serviceRepo.On("GetByID", mock.Anything, uint(1)).Return(entities.Service{ID: 1, Price: 100}, nil)
+		partsSupplyRepo.On("GetByID", mock.Anything, uint(1)).Return(entities.PartsSupply{ID: 1, Price: 25, QuantityTotal: 10, QuantityReserve: 2}, nil)
+		partsSupplyRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.PartsSupply")).Return(nil)
+		discountRepo.On("ListPriceAgreementsByCustomer", mock.Anything, uint(3)).Return([]dto.PriceAgreementDTO{}, nil)
+		discountRepo.On("GetCouponByCode", mock.Anything, "PROMO20").
+			Return(&dto.CouponDTO{ID: 7, Code: "PROMO20", DiscountType: "PERCENTAGE", Value: 20, Active: true, MaxUses: 3, UsedCount: 2}, nil)
+		taxRepo.On("ListRules", mock.Anything).Return([]dto.TaxRuleDTO{}, nil)
+
+		discountUseCase := NewDiscountUseCase(discountRepo, serviceOrderRepo, new(mocks.MockUserRepository), customerRepo, serviceRepo, partsSupplyRepo, 50)
+		useCase := NewServiceOrderUseCase(serviceOrderRepo, new(MockVehicleRepository), customerRepo, serviceRepo, partsSupplyRepo, discountUseCase, NewTaxUseCase(taxRepo, 5, 18), new(mocks.MockInvoiceUseCase), noCorporateAccount())
+		return useCase, serviceOrderRepo, partsSupplyRepo
+	}
+
+	t.Run("Success - the coupon is stored with the pricing and the order", func(t *testing.T) {
+		useCase, serviceOrderRepo, partsSupplyRepo := newUseCase()
+		serviceOrderRepo.On("Update", mock.AnythingOfType("*entities.ServiceOrder")).Return(nil)
+
+		_, err := useCase.UpdateServiceOrder(ctx, diagnosis, DIAGNOSIS)
+		assert.NoError(t, err)
+		serviceOrderRepo.AssertCalled(t, "Update", mock.MatchedBy(func(so *entities.ServiceOrder) bool {
+			return so.Pricing != nil && so.Pricing.CouponID != nil && *so.Pricing.CouponID == 7 &&
+				so.Pricing.Net == 120 && so.Estimate == 120 && !so.ReleaseCoupon
+		}))
+		partsSupplyRepo.AssertNumberOfCalls(t, "Update", 1)
+	})
+
+	t.Run("Error - the coupon was used up in the meantime, so the reserved parts go back to stock", func(t *testing.T) {
+		useCase, serviceOrderRepo, partsSupplyRepo := newUseCase()
+		serviceOrderRepo.On("Update", mock.AnythingOfType("*entities.ServiceOrder")).Return(serviceorder.ErrCouponUsedUp)
+
+		_, err := useCase.UpdateServiceOrder(ctx, diagnosis, DIAGNOSIS)
+		assert.ErrorIs(t, err, ErrCouponUsageLimitReached)
+		serviceOrderRepo.AssertNumberOfCalls(t, "GetByID", 1)
+		partsSupplyRepo.AssertNumberOfCalls(t, "Update", 2)
+		partsSupplyRepo.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(ps *entities.PartsSupply) bool {
+			return ps.ID == 1 && ps.QuantityReserve == 4
+		}))
+		partsSupplyRepo.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(ps *entities.PartsSupply) bool {
+			return ps.ID == 1 && ps.QuantityReserve == 0
+		}))
+	})
+
+	t.Run("Success - a cancelled order gives its coupon back", func(t *testing.T) {
+		useCase, serviceOrderRepo, _ := newUseCase()
+		serviceOrderRepo.On("Update", mock.AnythingOfType("*entities.ServiceOrder")).Return(nil)
+
+		_, err := useCase.UpdateServiceOrder(ctx, entities.ServiceOrder{ID: 1, ServiceOrderStatus: valueobject.StatusCancelada}, DIAGNOSIS)
+		assert.NoError(t, err)
+		serviceOrderRepo.AssertCalled(t, "Update", mock.MatchedBy(func(so *entities.ServiceOrder) bool {
+			return so.ServiceOrderStatus.IsCancelada() && so.ReleaseCoupon
+		}))
+	})
+
+	t.Run("Success - a rejected estimate gives its coupon back", func(t *testing.T) {
+		serviceOrderRepo := new(MockServiceOrderRepository)
+		partsSupplyRepo := new(MockPartsSupplyRepository)
+		serviceOrderRepo.On("GetByID", uint(1)).Return(&dto.ServiceOrderDTO{
+			ID:                 1,
+			CustomerID:         3,
+			ServiceOrderStatus: dto.ServiceOrderStatusDTO{ID: 3, Description: StatusAguardandoAprovacao},
+		}, nil)
+		serviceOrderRepo.On("Update", mock.AnythingOfType("*entities.ServiceOrder")).Return(nil)
+		partsSupplyRepo.On("GetByServiceOrderID", mock.Anything, uint(1)).Return([]entities.PartsSupply{}, nil)
+		useCase := NewServiceOrderUseCase(serviceOrderRepo, new(MockVehicleRepository), new(MockCustomerRepository), new(MockServiceRepository), partsSupplyRepo, nil, nil, new(mocks.MockInvoiceUseCase), noCorporateAccount())
+
+		_, err := useCase.UpdateServiceOrder(ctx, entities.ServiceOrder{ID: 1, ServiceOrderStatus: valueobject.StatusRejeitada}, ESTIMATE)
+		assert.NoError(t, err)
+		serviceOrderRepo.AssertCalled(t, "Update", mock.MatchedBy(func(so *entities.ServiceOrder) bool {
+			return so.ServiceOrderStatus.IsRejeitada() && so.ReleaseCoupon
+		}))
+	})
+}
+
+// storeServiceOrder applies an update to the stored order the way the repository does: the
+// services and parts supplies are only replaced when the update carries them
+func storeServiceOrder(stored *dto.ServiceOrderDTO, catalog map[uint]float64) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		so := args.Get(0).(*entities.ServiceOrder)
+		stored.ServiceOrderStatus.Description = so.ServiceOrderStatus.String()
+		if so.Services != nil {
+			stored.Services = nil
+			for _, s := range so.Services {
+				stored.Services = append(stored.Services, dto.ServiceDTO{ID: s.ID, Name: fmt.Sprintf("Service %d", s.ID), Price: catalog[s.ID]})
+			}
+		}
+		if so.PartsSupplies != nil {
+			stored.PartsSupplies = nil
+			stored.PartsSupplyItems = nil
+			for _, ps := range so.PartsSupplies {
+				stored.PartsSupplies = append(stored.PartsSupplies, dto.PartsSupplyDTO{ID: ps.ID, Name: fmt.Sprintf("Part %d", ps.ID), Price: catalog[ps.ID]})
+				stored.PartsSupplyItems = append(stored.PartsSupplyItems, dto.PartsSupplyServiceOrderDTO{PartsSupplyID: ps.ID, ServiceOrderID: so.ID, Quantity: ps.QuantityReserve})
+			}
+		}
+		if so.Pricing != nil && so.Pricing.Taxes != nil {
+			stored.Taxes = nil
+			for _, line := range so.Pricing.Taxes.Lines {
+				tax := dto.ServiceOrderTaxDTO{TaxType: line.TaxType.String(), Base: line.Base, Rate: line.Rate, Amount: line.Amount}
+				if line.ServiceID != 0 {
+					serviceID := line.ServiceID
+					tax.ServiceID = &serviceID
+				}
+				if line.PartsSupplyID != 0 {
+					partsSupplyID := line.PartsSupplyID
+					tax.PartsSupplyID = &partsSupplyID
+				}
+				stored.Taxes = append(stored.Taxes, tax)
+			}
+		}
+	}
+}
+
+// serviceOrderFlowSteps take service order 1 from the diagnosis of a discounted service and two
+// units of a parts supply to the delivery
+var serviceOrderFlowSteps = []struct {
+	flow    string
+	request entities.ServiceOrder
+}{
+	{DIAGNOSIS, entities.ServiceOrder{
+		ID:                 1,
+		ServiceOrderStatus: valueobject.StatusEmDiagnostico,
+		Services:           []entities.Service{{ID: 1, Discount: &entities.Discount{Type: valueobject.DiscountFixed, Value: 10}}},
+		PartsSupplies:      []entities.PartsSupply{{ID: 2, QuantityReserve: 2}},
+	}},
+	{ESTIMATE, entities.ServiceOrder{ID: 1, ServiceOrderStatus: valueobject.StatusAprovada}},
+	{EXECUTION, entities.ServiceOrder{ID: 1, ServiceOrderStatus: valueobject.StatusEmExecucao}},
+	{EXECUTION, entities.ServiceOrder{ID: 1, ServiceOrderStatus: valueobject.StatusFinalizada}},
+	{DELIVERY, entities.ServiceOrder{ID: 1, ServiceOrderStatus: valueobject.StatusEntregue}},
+}
+
+// newServiceOrderFlow returns a use case for the serviceOrderFlowSteps on a repository that
+// stores the updates, and the stored order. The order is already paid, so it can be delivered.
+func newServiceOrderFlow(invoiceUseCase func(serviceorder.IServiceOrderRepository, ITaxUseCase) IInvoiceUseCase) (*ServiceOrderUseCase, *MockServiceOrderRepository, *dto.ServiceOrderDTO) {
+	customerRepo := new(MockCustomerRepository)
+	serviceOrderRepo := new(MockServiceOrderRepository)
+	serviceRepo := new(MockServiceRepository)
+	partsSupplyRepo := new(MockPartsSupplyRepository)
+	discountRepo := new(mocks.MockDiscountRepository)
+	taxRepo := new(mocks.MockTaxRepository)
+
+	stored := &dto.ServiceOrderDTO{
+		ID:                 1,
+		CustomerID:         3,
+		Customer:           dto.CustomerDTO{ID: 3, FullName: "Joao da Silva", CpfCnpj: "52998224725"},
+		ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: StatusRecebida},
+		Payment:            &dto.PaymentDTO{ID: 9, ServiceOrderID: 1, Amount: 150},
+	}
+	serviceOrderRepo.On("GetByID", uint(1)).Return(stored, nil)
+	serviceOrderRepo.On("GetByIDWithItems", uint(1)).Return(stored, nil)
+	serviceOrderRepo.On("Update", mock.AnythingOfType("*entities.ServiceOrder")).
+		Run(storeServiceOrder(stored, map[uint]float64{1: 100, 2: 30})).Return(nil)
+	serviceOrderRepo.On("GetPartsSupplyServiceOrder", uint(2), uint(1)).Return(&dto.PartsSupplyServiceOrderDTO{PartsSupplyID: 2, ServiceOrderID: 1, Quantity: 2}, nil)
+	serviceRepo.On("GetByID", mock.Anything, uint(1)).Return(entities.Service{ID: 1, Price: 100}, nil)
+	partsSupplyRepo.On("GetByID", mock.Anything, uint(2)).Return(entities.PartsSupply{ID: 2, Price: 30, QuantityTotal: 10, QuantityReserve: 2}, nil)
+	partsSupplyRepo.On("GetByServiceOrderID", mock.Anything, uint(1)).Return([]entities.PartsSupply{{ID: 2}}, nil)
+	partsSupplyRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.PartsSupply")).Return(nil)
+	discountRepo.On("ListPriceAgreementsByCustomer", mock.Anything, uint(3)).Return([]dto.PriceAgreementDTO{}, nil)
+	taxRepo.On("ListRules", mock.Anything).Return([]dto.TaxRuleDTO{}, nil)
+
+	taxUseCase := NewTaxUseCase(taxRepo, 5, 18)
+	discountUseCase := NewDiscountUseCase(discountRepo, serviceOrderRepo, new(mocks.MockUserRepository), customerRepo, serviceRepo, partsSupplyRepo, 50)
+	useCase := NewServiceOrderUseCase(serviceOrderRepo, new(MockVehicleRepository), customerRepo, serviceRepo, partsSupplyRepo, discountUseCase, taxUseCase, invoiceUseCase(serviceOrderRepo, taxUseCase), noCorporateAccount())
+	return useCase, serviceOrderRepo, stored
+}
+
+func TestUpdateServiceOrder_ItemsAreKeptUntilDelivery(t *testing.T) {
+	ctx := context.Background()
+	invoiceRepo := new(mocks.MockInvoiceRepository)
+	encoder := new(mocks.MockInvoiceEncoder)
+	signer := new(mocks.MockInvoiceSigner)
+	provider := new(mocks.MockInvoiceProvider)
+	invoiceRepo.On("ListByServiceOrderID", ctx, uint(1)).Return([]dto.InvoiceDTO{}, nil)
+	invoiceRepo.On("Create", ctx, mock.Anything).Return(entities.Invoice{ID: 1, Status: valueobject.InvoicePending}, nil)
+	invoiceRepo.On("Update", ctx, mock.Anything).Return(nil)
+	encoder.On("Encode", mock.Anything).Return([]byte("<xml/>"), nil)
+	signer.On("Sign", mock.Anything).Return([]byte("<signed/>"), nil)
+	provider.On("Submit", ctx, mock.Anything, mock.Anything).Return(&entities.InvoiceSubmission{Accepted: true, Number: "1"}, nil)
+
+	useCase, _, stored := newServiceOrderFlow(func(serviceOrderRepo serviceorder.IServiceOrderRepository, taxUseCase ITaxUseCase) IInvoiceUseCase {
+		return NewInvoiceUseCase(invoiceRepo, serviceOrderRepo, taxUseCase, encoder, signer, provider, "1")
+	})
+	for _, step := range serviceOrderFlowSteps {
+		_, err := useCase.UpdateServiceOrder(ctx, step.request, step.flow)
+		require.NoError(t, err, step.request.ServiceOrderStatus)
+	}
+
+	assert.Equal(t, StatusEntregue, stored.ServiceOrderStatus.Description)
+	invoiceRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+		return i.Type == valueobject.InvoiceNFSe && len(i.Items) == 1 && i.Items[0].Code == "SRV-1" && i.Total == 90
+	}))
+	invoiceRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(i *entities.Invoice) bool {
+		return i.Type == valueobject.InvoiceNFe && len(i.Items) == 1 && i.Items[0].Code == "PEC-2" && i.Items[0].Quantity == 2 && i.Total == 60
+	}))
+}
+
 func TestValidateEstimate(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -687,6 +1069,7 @@ func TestValidateDelivery(t *testing.T) {
 		name            string
 		request         *entities.ServiceOrder
 		serviceOrderDTO *dto.ServiceOrderDTO
+		billedMonthly   bool
 		expectedError   error
 	}{
 		{
@@ -722,6 +1105,40 @@ func TestValidateDelivery(t *testing.T) {
 			},
 			expectedError: errors.New("payment information is required for delivery"),
 		},
+		{
+			name: "Success - Billed Monthly Without Payment",
+			request: &entities.ServiceOrder{
+				ID:                 1,
+				ServiceOrderStatus: valueobject.StatusEntregue,
+			},
+			serviceOrderDTO: &dto.ServiceOrderDTO{
+				ID: 1,
+				ServiceOrderStatus: dto.ServiceOrderStatusDTO{
+					Description: string(valueobject.StatusFinalizada),
+				},
+			},
+			billedMonthly: true,
+			expectedError: nil,
+		},
+		{
+			name: "Error - Additional Repair Pending Approval",
+			request: &entities.ServiceOrder{
+				ID:                 1,
+				ServiceOrderStatus: valueobject.StatusEntregue,
+			},
+			serviceOrderDTO: &dto.ServiceOrderDTO{
+				ID: 1,
+				ServiceOrderStatus: dto.ServiceOrderStatusDTO{
+					Description: string(valueobject.StatusFinalizada),
+				},
+				Payment: &dto.PaymentDTO{ID: 1, PaymentDate: time.Now()},
+				AdditionalRepairs: []dto.AdditionalRepairDTO{
+					{ID: 1, ARStatus: dto.AdditionalRepairStatusDTO{Description: string(valueobject.StatusAAprovada)}},
+					{ID: 2, ARStatus: dto.AdditionalRepairStatusDTO{Description: string(valueobject.StatusARAguardandoAprovacao)}},
+				},
+			},
+			expectedError: ErrAdditionalRepairPending,
+		},
 		{
 			name: "Error - Invalid Status Transition",
 			request: &entities.ServiceOrder{
@@ -746,7 +1163,7 @@ func TestValidateDelivery(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			update := &entities.ServiceOrder{}
-			result, err := ValidateDelivery(context.Background(), tt.request, tt.serviceOrderDTO, update)
+			result, err := ValidateDelivery(context.Background(), tt.request, tt.serviceOrderDTO, update, tt.billedMonthly)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -755,6 +1172,7 @@ func TestValidateDelivery(t *testing.T) {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
 				assert.Equal(t, valueobject.StatusEntregue, result.ServiceOrderStatus)
+				assert.NotNil(t, result.DeliveredAt)
 			}
 		})
 	}
@@ -767,7 +1185,13 @@ func TestInvalidServiceOrder(t *testing.T) {
 	serviceRepo := new(MockServiceRepository)
 	partsSupplyRepo := new(MockPartsSupplyRepository)
 
-	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo)
+	discountRepo := new(mocks.MockDiscountRepository)
+	discountUseCase := NewDiscountUseCase(discountRepo, serviceOrderRepo, new(mocks.MockUserRepository), customerRepo, serviceRepo, partsSupplyRepo, 10)
+
+	taxRepo := new(mocks.MockTaxRepository)
+	taxUseCase := NewTaxUseCase(taxRepo, 5, 18)
+
+	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo, discountUseCase, taxUseCase, new(mocks.MockInvoiceUseCase), noCorporateAccount())
 
 	tests := []struct {
 		name          string
@@ -828,7 +1252,13 @@ func TestGetServiceOrder(t *testing.T) {
 	customerRepo := new(MockCustomerRepository)
 	serviceRepo := new(MockServiceRepository)
 	partsSupplyRepo := new(MockPartsSupplyRepository)
-	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo)
+	discountRepo := new(mocks.MockDiscountRepository)
+	discountUseCase := NewDiscountUseCase(discountRepo, serviceOrderRepo, new(mocks.MockUserRepository), customerRepo, serviceRepo, partsSupplyRepo, 10)
+
+	taxRepo := new(mocks.MockTaxRepository)
+	taxUseCase := NewTaxUseCase(taxRepo, 5, 18)
+
+	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo, discountUseCase, taxUseCase, new(mocks.MockInvoiceUseCase), noCorporateAccount())
 
 	ctx := context.Background()
 	validID := uint(1)
@@ -860,7 +1290,13 @@ func TestListServiceOrders(t *testing.T) {
 	customerRepo := new(MockCustomerRepository)
 	serviceRepo := new(MockServiceRepository)
 	partsSupplyRepo := new(MockPartsSupplyRepository)
-	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo)
+	discountRepo := new(mocks.MockDiscountRepository)
+	discountUseCase := NewDiscountUseCase(discountRepo, serviceOrderRepo, new(mocks.MockUserRepository), customerRepo, serviceRepo, partsSupplyRepo, 10)
+
+	taxRepo := new(mocks.MockTaxRepository)
+	taxUseCase := NewTaxUseCase(taxRepo, 5, 18)
+
+	useCase := NewServiceOrderUseCase(serviceOrderRepo, vehicleRepo, customerRepo, serviceRepo, partsSupplyRepo, discountUseCase, taxUseCase, new(mocks.MockInvoiceUseCase), noCorporateAccount())
 
 	ctx := context.Background()
 	serviceOrderDTOs := []dto.ServiceOrderDTO{
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/staff"
+)
+
+var (
+	ErrStaffNotFound        = errors.New("staff member not found")
+	ErrInvalidStaff         = errors.New("staff member needs a full name and a valid e-mail")
+	ErrInvalidStaffRole     = errors.New("role cannot be given to a staff member")
+	ErrInvalidStaffPassword = errors.New("password must have at least 8 characters with upper and lower case letters and digits")
+	ErrStaffEmailInUse      = errors.New("e-mail is already in use")
+	ErrStaffSelfDelete      = errors.New("staff members cannot delete themselves")
+)
+
+type IStaffUseCase interface {
+	CreateStaff(ctx context.Context, input entities.StaffInput) (*entities.Staff, error)
+	GetStaff(ctx context.Context, id uint) (*entities.Staff, error)
+	ListStaff(ctx context.Context) ([]entities.Staff, error)
+	UpdateStaff(ctx context.Context, id uint, input entities.StaffInput) (*entities.Staff, error)
+	DeleteStaff(ctx context.Context, id uint, requestedBy string) error
+	ListRoles() []entities.RolePermissions
+}
+
+type StaffUseCase struct {
+	repo staff.IStaffRepository
+}
+
+var _ IStaffUseCase = (*StaffUseCase)(nil)
+
+func NewStaffUseCase(repo staff.IStaffRepository) *StaffUseCase {
+	return &StaffUseCase{repo: repo}
+}
+
+// CreateStaff registers an employee with a user they log in with. The password is required and
+// the role must be one of the staff roles.
+func (u *StaffUseCase) CreateStaff(ctx context.Context, input entities.StaffInput) (*entities.Staff, error) {
+	input, err := validateStaffInput(input)
+	if err != nil {
+		return nil, err
+	}
+	password, err := valueobject.NewPassword(input.Password)
+	if err != nil {
+		return nil, ErrInvalidStaffPassword
+	}
+	exists, err := u.repo.EmailExists(ctx, input.Email)
+	if err != nil {
+		log.Error().Msgf("Error checking staff e-mail %s: %v", input.Email, err)
+		return nil, err
+	}
+	if exists {
+		return nil, ErrStaffEmailInUse
+	}
+
+	staffDto := dto.StaffDTO{
+		User: &dto.UserDTO{
+			Email:    input.Email,
+			Password: password.String(),
+			UserType: input.Role,
+		},
+		FullName:    input.FullName,
+		PhoneNumber: input.PhoneNumber,
+	}
+	if err := u.repo.Create(ctx, &staffDto); err != nil {
+		log.Error().Msgf("Error creating staff member %s: %v", input.Email, err)
+		return nil, err
+	}
+	result := staffDto.ToDomain()
+	return &result, nil
+}
+
+func (u *StaffUseCase) GetStaff(ctx context.Context, id uint) (*entities.Staff, error) {
+	staffDto, err := u.getStaff(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := staffDto.ToDomain()
+	return &result, nil
+}
+
+func (u *StaffUseCase) ListStaff(ctx context.Context) ([]entities.Staff, error) {
+	staffDtos, err := u.repo.List(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing staff: %v", err)
+		return nil, err
+	}
+	result := make([]entities.Staff, 0, len(staffDtos))
+	for _, staffDto := range staffDtos {
+		result = append(result, staffDto.ToDomain())
+	}
+	return result, nil
+}
+
+// UpdateStaff changes the record, the e-mail and the role of an employee. The role takes effect on
+// their next login, when a new token is issued.
+func (u *StaffUseCase) UpdateStaff(ctx context.Context, id uint, input entities.StaffInput) (*entities.Staff, error) {
+	input, err := validateStaffInput(input)
+	if err != nil {
+		return nil, err
+	}
+	staffDto, err := u.getStaff(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(staffDto.User.Email, input.Email) {
+		exists, err := u.repo.EmailExists(ctx, input.Email)
+		if err != nil {
+			log.Error().Msgf("Error checking staff e-mail %s: %v", input.Email, err)
+			return nil, err
+		}
+		if exists {
+			return nil, ErrStaffEmailInUse
+		}
+	}
+	if input.Password != "" {
+		password, err := valueobject.NewPassword(input.Password)
+		if err != nil {
+			return nil, ErrInvalidStaffPassword
+		}
+		staffDto.User.Password = password.String()
+	}
+	staffDto.User.Email = input.Email
+	staffDto.User.UserType = input.Role
+	staffDto.FullName = input.FullName
+	staffDto.PhoneNumber = input.PhoneNumber
+
+	if err := u.repo.Update(ctx, staffDto); err != nil {
+		log.Error().Msgf("Error updating staff member %d: %v", id, err)
+		return nil, err
+	}
+	result := staffDto.ToDomain()
+	return &result, nil
+}
+
+// DeleteStaff removes an employee and their user. requestedBy is the e-mail of the user deleting
+// them, who cannot remove their own access.
+func (u *StaffUseCase) DeleteStaff(ctx context.Context, id uint, requestedBy string) error {
+	staffDto, err := u.getStaff(ctx, id)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(staffDto.User.Email, requestedBy) {
+		return ErrStaffSelfDelete
+	}
+	if err := u.repo.Delete(ctx, staffDto); err != nil {
+		log.Error().Msgf("Error deleting staff member %d: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// ListRoles returns the staff roles with the permissions each one grants
+func (u *StaffUseCase) ListRoles() []entities.RolePermissions {
+	roles := make([]entities.RolePermissions, 0, len(valueobject.StaffRoles))
+	for _, role := range valueobject.StaffRoles {
+		roles = append(roles, entities.RolePermissions{Role: role, Permissions: role.Permissions()})
+	}
+	return roles
+}
+
+func (u *StaffUseCase) getStaff(ctx context.Context, id uint) (*dto.StaffDTO, error) {
+	staffDto, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Error().Msgf("Error getting staff member %d: %v", id, err)
+		return nil, err
+	}
+	if staffDto == nil || staffDto.User == nil {
+		return nil, ErrStaffNotFound
+	}
+	return staffDto, nil
+}
+
+func validateStaffInput(input entities.StaffInput) (entities.StaffInput, error) {
+	input.FullName = strings.TrimSpace(input.FullName)
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+	if input.FullName == "" || !valueobject.ParseEmail(input.Email).IsValidFormat() {
+		return input, ErrInvalidStaff
+	}
+	input.Role = valueobject.ParseUserType(string(input.Role))
+	if !input.Role.IsStaffRole() {
+		return input, ErrInvalidStaffRole
+	}
+	return input, nil
+}
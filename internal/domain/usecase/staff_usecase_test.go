@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+func TestStaffUseCase_CreateStaff(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates the employee with a hashed password and the role as user type", func(t *testing.T) {
+		repo := new(mocks.MockStaffRepository)
+		uc := NewStaffUseCase(repo)
+		repo.On("EmailExists", ctx, "caixa@xpto.com").Return(false, nil)
+		repo.On("Create", ctx, mock.MatchedBy(func(s *dto.StaffDTO) bool {
+			return s.User.Email == "caixa@xpto.com" &&
+				s.User.UserType == valueobject.Cashier &&
+				valueobject.Password(s.User.Password).Verify("Senha1234")
+		})).Return(nil)
+
+		result, err := uc.CreateStaff(ctx, entities.StaffInput{
+			Email: " Caixa@xpto.com ", Password: "Senha1234", FullName: "Ana Souza", Role: "cashier",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, valueobject.Cashier, result.Role)
+		assert.Contains(t, result.Permissions, valueobject.PermissionManagePayments)
+		assert.NotContains(t, result.Permissions, valueobject.PermissionManageStaff)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("does not give the admin or customer roles", func(t *testing.T) {
+		repo := new(mocks.MockStaffRepository)
+		uc := NewStaffUseCase(repo)
+
+		for _, role := range []valueobject.UserType{valueobject.Admin, valueobject.Customer, "owner"} {
+			_, err := uc.CreateStaff(ctx, entities.StaffInput{
+				Email: "novo@xpto.com", Password: "Senha1234", FullName: "Novo", Role: role,
+			})
+			assert.ErrorIs(t, err, ErrInvalidStaffRole)
+		}
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a weak password", func(t *testing.T) {
+		uc := NewStaffUseCase(new(mocks.MockStaffRepository))
+
+		_, err := uc.CreateStaff(ctx, entities.StaffInput{
+			Email: "novo@xpto.com", Password: "123", FullName: "Novo", Role: valueobject.Mechanic,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidStaffPassword)
+	})
+
+	t.Run("rejects an e-mail already in use", func(t *testing.T) {
+		repo := new(mocks.MockStaffRepository)
+		uc := NewStaffUseCase(repo)
+		repo.On("EmailExists", ctx, "admin@xpto.com").Return(true, nil)
+
+		_, err := uc.CreateStaff(ctx, entities.StaffInput{
+			Email: "admin@xpto.com", Password: "Senha1234", FullName: "Outro", Role: valueobject.Manager,
+		})
+
+		assert.ErrorIs(t, err, ErrStaffEmailInUse)
+	})
+}
+
+func TestStaffUseCase_UpdateStaff(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("changes the role and keeps the password when none is given", func(t *testing.T) {
+		repo := new(mocks.MockStaffRepository)
+		uc := NewStaffUseCase(repo)
+		existing := &dto.StaffDTO{ID: 4, UserID: 9, FullName: "Pedro", User: &dto.UserDTO{
+			ID: 9, Email: "pedro@xpto.com", Password: "hash", UserType: valueobject.Mechanic,
+		}}
+		repo.On("GetByID", ctx, uint(4)).Return(existing, nil)
+		repo.On("Update", ctx, mock.MatchedBy(func(s *dto.StaffDTO) bool {
+			return s.User.UserType == valueobject.StockKeeper && s.User.Password == "hash"
+		})).Return(nil)
+
+		result, err := uc.UpdateStaff(ctx, 4, entities.StaffInput{
+			Email: "pedro@xpto.com", FullName: "Pedro Lima", Role: valueobject.StockKeeper,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Pedro Lima", result.FullName)
+		assert.Equal(t, valueobject.StockKeeper, result.Role)
+		repo.AssertNotCalled(t, "EmailExists", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns not found for an unknown employee", func(t *testing.T) {
+		repo := new(mocks.MockStaffRepository)
+		uc := NewStaffUseCase(repo)
+		repo.On("GetByID", ctx, uint(99)).Return(nil, nil)
+
+		_, err := uc.UpdateStaff(ctx, 99, entities.StaffInput{
+			Email: "x@xpto.com", FullName: "X", Role: valueobject.Cashier,
+		})
+
+		assert.ErrorIs(t, err, ErrStaffNotFound)
+	})
+}
+
+func TestStaffUseCase_DeleteStaff(t *testing.T) {
+	ctx := context.Background()
+	existing := &dto.StaffDTO{ID: 4, UserID: 9, User: &dto.UserDTO{ID: 9, Email: "gerente@xpto.com", UserType: valueobject.Manager}}
+
+	t.Run("does not let an employee delete themselves", func(t *testing.T) {
+		repo := new(mocks.MockStaffRepository)
+		uc := NewStaffUseCase(repo)
+		repo.On("GetByID", ctx, uint(4)).Return(existing, nil)
+
+		err := uc.DeleteStaff(ctx, 4, "gerente@xpto.com")
+
+		assert.ErrorIs(t, err, ErrStaffSelfDelete)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes another employee", func(t *testing.T) {
+		repo := new(mocks.MockStaffRepository)
+		uc := NewStaffUseCase(repo)
+		repo.On("GetByID", ctx, uint(4)).Return(existing, nil)
+		repo.On("Delete", ctx, existing).Return(nil)
+
+		require.NoError(t, uc.DeleteStaff(ctx, 4, "admin@xpto.com"))
+		repo.AssertExpectations(t)
+	})
+}
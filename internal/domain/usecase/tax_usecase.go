@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/tax"
+)
+
+var (
+	ErrTaxRuleNotFound      = errors.New("tax rule not found")
+	ErrTaxRuleAlreadyExists = errors.New("tax rule already exists for this category")
+	ErrInvalidTaxRule       = errors.New("invalid tax rule")
+	ErrInvalidReportPeriod  = errors.New("invalid report period")
+)
+
+type ITaxUseCase interface {
+	CreateRule(ctx context.Context, rule *entities.TaxRule) (entities.TaxRule, error)
+	UpdateRule(ctx context.Context, rule *entities.TaxRule) error
+	ListRules(ctx context.Context) ([]entities.TaxRule, error)
+	DeleteRule(ctx context.Context, id uint) error
+	CalculateTaxes(ctx context.Context, pricing entities.EstimateBreakdown) (*entities.TaxSummary, error)
+	GetTaxReport(ctx context.Context, from, to time.Time) (*entities.TaxReport, error)
+}
+
+type TaxUseCase struct {
+	repo            tax.ITaxRepository
+	defaultISSRate  float64
+	defaultICMSRate float64
+}
+
+var _ ITaxUseCase = (*TaxUseCase)(nil)
+
+func NewTaxUseCase(repo tax.ITaxRepository, defaultISSRate, defaultICMSRate float64) *TaxUseCase {
+	return &TaxUseCase{
+		repo:            repo,
+		defaultISSRate:  defaultISSRate,
+		defaultICMSRate: defaultICMSRate,
+	}
+}
+
+func (u *TaxUseCase) CreateRule(ctx context.Context, rule *entities.TaxRule) (entities.TaxRule, error) {
+	rule.Category = normalizeTaxCategory(rule.Category)
+	if err := validateTaxRule(rule); err != nil {
+		return entities.TaxRule{}, err
+	}
+
+	existing, err := u.repo.GetRule(ctx, rule.TaxType, rule.Category)
+	if err != nil {
+		return entities.TaxRule{}, err
+	}
+	if existing != nil {
+		return entities.TaxRule{}, ErrTaxRuleAlreadyExists
+	}
+
+	return u.repo.CreateRule(ctx, rule)
+}
+
+// UpdateRule changes the rate and description of a rule; its tax type and category are immutable
+func (u *TaxUseCase) UpdateRule(ctx context.Context, rule *entities.TaxRule) error {
+	existing, err := u.repo.GetRuleByID(ctx, rule.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrTaxRuleNotFound
+	}
+	rule.TaxType = valueobject.ParseTaxType(existing.TaxType)
+	rule.Category = existing.Category
+	if err := validateTaxRule(rule); err != nil {
+		return err
+	}
+	return u.repo.UpdateRule(ctx, rule)
+}
+
+func (u *TaxUseCase) ListRules(ctx context.Context) ([]entities.TaxRule, error) {
+	dtos, err := u.repo.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]entities.TaxRule, 0, len(dtos))
+	for _, r := range dtos {
+		rules = append(rules, r.ToDomain())
+	}
+	return rules, nil
+}
+
+func (u *TaxUseCase) DeleteRule(ctx context.Context, id uint) error {
+	if id == 0 {
+		return ErrInvalidID
+	}
+	return u.repo.DeleteRule(ctx, id)
+}
+
+// CalculateTaxes breaks out the ISS of services and the ICMS of parts supplies of a priced estimate.
+// Order and coupon discounts are spread over the lines proportionally, so the tax base of each
+// line is what the customer is actually charged for it. Taxes are included in the prices.
+func (u *TaxUseCase) CalculateTaxes(ctx context.Context, pricing entities.EstimateBreakdown) (*entities.TaxSummary, error) {
+	rules, err := u.repo.ListRules(ctx)
+	if err != nil {
+		log.Error().Msgf("error listing tax rules: %v", err)
+		return nil, err
+	}
+	rates := make(map[valueobject.TaxType]map[string]float64)
+	for _, r := range rules {
+		rule := r.ToDomain()
+		if rates[rule.TaxType] == nil {
+			rates[rule.TaxType] = make(map[string]float64)
+		}
+		rates[rule.TaxType][rule.Category] = rule.Rate
+	}
+
+	var linesNet float64
+	for _, line := range pricing.Lines {
+		linesNet += line.Net
+	}
+	factor := 0.0
+	if linesNet > 0 {
+		factor = pricing.Net / linesNet
+	}
+
+	summary := &entities.TaxSummary{Lines: []entities.TaxLine{}}
+	for _, line := range pricing.Lines {
+		taxType := valueobject.TaxICMS
+		if line.ServiceID != 0 {
+			taxType = valueobject.TaxISS
+		}
+		category := normalizeTaxCategory(line.Category)
+		rate := u.rateFor(rates, taxType, category)
+		base := roundCurrency(line.Net * factor)
+
+		taxLine := entities.TaxLine{
+			TaxType:       taxType,
+			Category:      category,
+			ServiceID:     line.ServiceID,
+			PartsSupplyID: line.PartsSupplyID,
+			Base:          base,
+			Rate:          rate,
+			Amount:        taxType.Amount(base, rate),
+		}
+		summary.Lines = append(summary.Lines, taxLine)
+
+		if taxType == valueobject.TaxISS {
+			summary.ISSTotal += taxLine.Amount
+		} else {
+			summary.ICMSTotal += taxLine.Amount
+		}
+	}
+
+	summary.ISSTotal = roundCurrency(summary.ISSTotal)
+	summary.ICMSTotal = roundCurrency(summary.ICMSTotal)
+	summary.TaxTotal = roundCurrency(summary.ISSTotal + summary.ICMSTotal)
+	return summary, nil
+}
+
+// GetTaxReport summarizes the taxes of the service orders paid between from and to
+func (u *TaxUseCase) GetTaxReport(ctx context.Context, from, to time.Time) (*entities.TaxReport, error) {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return nil, ErrInvalidReportPeriod
+	}
+
+	lines, err := u.repo.SummarizeByPaymentPeriod(ctx, from, to)
+	if err != nil {
+		log.Error().Msgf("error summarizing taxes: %v", err)
+		return nil, err
+	}
+	count, revenue, err := u.repo.GetPaidRevenue(ctx, from, to)
+	if err != nil {
+		log.Error().Msgf("error summarizing revenue: %v", err)
+		return nil, err
+	}
+
+	report := &entities.TaxReport{
+		From:          from,
+		To:            to,
+		ServiceOrders: count,
+		Revenue:       roundCurrency(revenue),
+		Categories:    lines,
+	}
+	for _, line := range lines {
+		switch line.TaxType {
+		case valueobject.TaxISS:
+			report.ISSTotal += line.Amount
+		case valueobject.TaxICMS:
+			report.ICMSTotal += line.Amount
+		}
+	}
+	report.ISSTotal = roundCurrency(report.ISSTotal)
+	report.ICMSTotal = roundCurrency(report.ICMSTotal)
+	report.TaxTotal = roundCurrency(report.ISSTotal + report.ICMSTotal)
+	return report, nil
+}
+
+// rateFor resolves the rate of a category: its own rule, then the rule without category, then the configured default
+func (u *TaxUseCase) rateFor(rates map[valueobject.TaxType]map[string]float64, taxType valueobject.TaxType, category string) float64 {
+	if rate, ok := rates[taxType][category]; ok {
+		return rate
+	}
+	if rate, ok := rates[taxType][""]; ok {
+		return rate
+	}
+	if taxType == valueobject.TaxISS {
+		return u.defaultISSRate
+	}
+	return u.defaultICMSRate
+}
+
+func validateTaxRule(rule *entities.TaxRule) error {
+	if !rule.TaxType.IsValid() || rule.Rate < 0 || rule.Rate > 100 {
+		return ErrInvalidTaxRule
+	}
+	return nil
+}
+
+func normalizeTaxCategory(category string) string {
+	return strings.ToUpper(strings.TrimSpace(category))
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+func TestTaxUseCase_CalculateTaxes(t *testing.T) {
+	ctx := context.Background()
+	rules := []dto.TaxRuleDTO{
+		{ID: 1, TaxType: "ISS", Category: "", Rate: 3},
+		{ID: 2, TaxType: "ISS", Category: "FUNILARIA", Rate: 2},
+		{ID: 3, TaxType: "ICMS", Category: "PNEUS", Rate: 12},
+	}
+
+	tests := []struct {
+		name          string
+		pricing       entities.EstimateBreakdown
+		expectedISS   float64
+		expectedICMS  float64
+		expectedRates []float64
+	}{
+		{
+			name: "Success - category rules, fallback rule and configured default",
+			pricing: entities.EstimateBreakdown{
+				Lines: []entities.EstimateLine{
+					{ServiceID: 1, Category: "funilaria", Net: 200},
+					{ServiceID: 2, Category: "MECANICA", Net: 100},
+					{PartsSupplyID: 3, Category: "PNEUS", Net: 400},
+					{PartsSupplyID: 4, Category: "FILTROS", Net: 100},
+				},
+				Net: 800,
+			},
+			expectedISS:   7,
+			expectedICMS:  66,
+			expectedRates: []float64{2, 3, 12, 18},
+		},
+		{
+			name: "Success - order discounts are spread over the lines",
+			pricing: entities.EstimateBreakdown{
+				Lines: []entities.EstimateLine{
+					{ServiceID: 2, Net: 100},
+					{PartsSupplyID: 4, Net: 100},
+				},
+				Net: 150,
+			},
+			expectedISS:   2.25,
+			expectedICMS:  13.5,
+			expectedRates: []float64{3, 18},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockTaxRepository)
+			repo.On("ListRules", ctx).Return(rules, nil)
+			u := NewTaxUseCase(repo, 5, 18)
+
+			summary, err := u.CalculateTaxes(ctx, tt.pricing)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedISS, summary.ISSTotal)
+			assert.Equal(t, tt.expectedICMS, summary.ICMSTotal)
+			assert.Equal(t, tt.expectedISS+tt.expectedICMS, summary.TaxTotal)
+			for i, rate := range tt.expectedRates {
+				assert.Equal(t, rate, summary.Lines[i].Rate)
+			}
+		})
+	}
+
+	t.Run("Error - listing rules", func(t *testing.T) {
+		repo := new(mocks.MockTaxRepository)
+		repo.On("ListRules", ctx).Return(nil, errors.New("db error"))
+		u := NewTaxUseCase(repo, 5, 18)
+
+		_, err := u.CalculateTaxes(ctx, entities.EstimateBreakdown{})
+		assert.Error(t, err)
+	})
+}
+
+func TestTaxUseCase_CreateRule(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - category is normalized", func(t *testing.T) {
+		repo := new(mocks.MockTaxRepository)
+		repo.On("GetRule", ctx, valueobject.TaxISS, "FUNILARIA").Return(nil, nil)
+		repo.On("CreateRule", ctx, mock.MatchedBy(func(r *entities.TaxRule) bool {
+			return r.Category == "FUNILARIA"
+		})).Return(entities.TaxRule{ID: 1, TaxType: valueobject.TaxISS, Category: "FUNILARIA", Rate: 2}, nil)
+		u := NewTaxUseCase(repo, 5, 18)
+
+		rule, err := u.CreateRule(ctx, &entities.TaxRule{TaxType: valueobject.TaxISS, Category: " funilaria ", Rate: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), rule.ID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Error - rule already exists", func(t *testing.T) {
+		repo := new(mocks.MockTaxRepository)
+		repo.On("GetRule", ctx, valueobject.TaxICMS, "PNEUS").Return(&dto.TaxRuleDTO{ID: 3}, nil)
+		u := NewTaxUseCase(repo, 5, 18)
+
+		_, err := u.CreateRule(ctx, &entities.TaxRule{TaxType: valueobject.TaxICMS, Category: "PNEUS", Rate: 12})
+		assert.ErrorIs(t, err, ErrTaxRuleAlreadyExists)
+	})
+
+	t.Run("Error - invalid rate", func(t *testing.T) {
+		u := NewTaxUseCase(new(mocks.MockTaxRepository), 5, 18)
+
+		_, err := u.CreateRule(ctx, &entities.TaxRule{TaxType: valueobject.TaxICMS, Rate: 120})
+		assert.ErrorIs(t, err, ErrInvalidTaxRule)
+	})
+}
+
+func TestTaxUseCase_UpdateRule(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - tax type and category are kept", func(t *testing.T) {
+		repo := new(mocks.MockTaxRepository)
+		repo.On("GetRuleByID", ctx, uint(1)).Return(&dto.TaxRuleDTO{ID: 1, TaxType: "ISS", Category: "FUNILARIA", Rate: 2}, nil)
+		repo.On("UpdateRule", ctx, mock.MatchedBy(func(r *entities.TaxRule) bool {
+			return r.TaxType == valueobject.TaxISS && r.Category == "FUNILARIA" && r.Rate == 4
+		})).Return(nil)
+		u := NewTaxUseCase(repo, 5, 18)
+
+		err := u.UpdateRule(ctx, &entities.TaxRule{ID: 1, TaxType: valueobject.TaxICMS, Category: "OTHER", Rate: 4})
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Error - rule not found", func(t *testing.T) {
+		repo := new(mocks.MockTaxRepository)
+		repo.On("GetRuleByID", ctx, uint(9)).Return(nil, nil)
+		u := NewTaxUseCase(repo, 5, 18)
+
+		err := u.UpdateRule(ctx, &entities.TaxRule{ID: 9, Rate: 4})
+		assert.ErrorIs(t, err, ErrTaxRuleNotFound)
+	})
+}
+
+func TestTaxUseCase_GetTaxReport(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		repo := new(mocks.MockTaxRepository)
+		repo.On("SummarizeByPaymentPeriod", ctx, from, to).Return([]entities.TaxReportLine{
+			{TaxType: valueobject.TaxICMS, Category: "PNEUS", Base: 400, Amount: 48},
+			{TaxType: valueobject.TaxISS, Category: "", Base: 300, Amount: 15},
+			{TaxType: valueobject.TaxISS, Category: "FUNILARIA", Base: 100, Amount: 2},
+		}, nil)
+		repo.On("GetPaidRevenue", ctx, from, to).Return(int64(3), 800.0, nil)
+		u := NewTaxUseCase(repo, 5, 18)
+
+		report, err := u.GetTaxReport(ctx, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), report.ServiceOrders)
+		assert.Equal(t, 800.0, report.Revenue)
+		assert.Equal(t, 17.0, report.ISSTotal)
+		assert.Equal(t, 48.0, report.ICMSTotal)
+		assert.Equal(t, 65.0, report.TaxTotal)
+	})
+
+	t.Run("Error - end before start", func(t *testing.T) {
+		u := NewTaxUseCase(new(mocks.MockTaxRepository), 5, 18)
+
+		_, err := u.GetTaxReport(ctx, to, from)
+		assert.ErrorIs(t, err, ErrInvalidReportPeriod)
+	})
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/gateway"
+)
+
+// sniffContentType detects the type of an upload from its first bytes rather than trusting the
+// client, and returns a reader that still yields the whole content
+func sniffContentType(content io.Reader) (string, io.Reader, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	return contentType, io.MultiReader(bytes.NewReader(head), content), nil
+}
+
+// newBlobKey builds a random key under the prefix, so the names sent by clients never reach the
+// blob store
+func newBlobKey(prefix, extension string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return prefix + "/" + hex.EncodeToString(random) + "." + extension, nil
+}
+
+// uploadFileName keeps only the base name of the file sent by the client, which may carry the path
+// it had on a Windows machine
+func uploadFileName(name string) string {
+	return path.Base(strings.ReplaceAll(name, "\\", "/"))
+}
+
+// deleteBlob removes a stored file; a failure only leaves an orphan file behind, so it is logged
+func deleteBlob(ctx context.Context, blobStore gateway.BlobStore, key string) {
+	if err := blobStore.Delete(ctx, key); err != nil {
+		log.Error().Msgf("Error deleting blob %s: %v", key, err)
+	}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
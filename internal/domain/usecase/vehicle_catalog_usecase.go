@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	vehiclecatalog "mecanica_xpto/internal/domain/repository/vehicle_catalog"
+)
+
+var (
+	ErrInvalidFipeDump    = errors.New("the FIPE dump must be a CSV file with brand, model and year columns")
+	ErrVehicleBrandNeeded = errors.New("the brand is required to list the models")
+)
+
+// fipeZeroKmYear is the model year the FIPE table gives to the prices of brand new vehicles
+const fipeZeroKmYear = 32000
+
+// fipeColumns maps the headers found in FIPE dumps to the fields of the catalog
+var fipeColumns = map[string]string{
+	"codigo_fipe": "fipe_code",
+	"cod_fipe":    "fipe_code",
+	"fipe_code":   "fipe_code",
+	"codigo":      "fipe_code",
+	"marca":       "brand",
+	"brand":       "brand",
+	"modelo":      "model",
+	"model":       "model",
+	"ano_modelo":  "year",
+	"anomodelo":   "year",
+	"ano":         "year",
+	"year":        "year",
+}
+
+type IVehicleCatalogUseCase interface {
+	ImportFipe(ctx context.Context, dump io.Reader) (*entities.VehicleCatalogImport, error)
+	ListBrands(ctx context.Context) ([]string, error)
+	ListModels(ctx context.Context, brand, model string) ([]entities.VehicleModel, error)
+}
+
+type VehicleCatalogUseCase struct {
+	repo vehiclecatalog.IVehicleCatalogRepository
+	now  func() time.Time
+}
+
+var _ IVehicleCatalogUseCase = (*VehicleCatalogUseCase)(nil)
+
+func NewVehicleCatalogUseCase(repo vehiclecatalog.IVehicleCatalogRepository) *VehicleCatalogUseCase {
+	return &VehicleCatalogUseCase{repo: repo, now: time.Now}
+}
+
+// ImportFipe imports a FIPE dump in CSV, separated by semicolons or commas, into the catalog. The
+// lines that cannot be read are reported and the others are still imported; the repeated model
+// years and the prices of brand new vehicles are skipped.
+func (u *VehicleCatalogUseCase) ImportFipe(ctx context.Context, dump io.Reader) (*entities.VehicleCatalogImport, error) {
+	reader, err := newCSVReader(dump)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrInvalidFipeDump
+	}
+	columns := make(map[string]int)
+	for index, name := range header {
+		if field, ok := fipeColumns[normaliseCSVHeader(name)]; ok {
+			if _, found := columns[field]; !found {
+				columns[field] = index
+			}
+		}
+	}
+	for _, field := range []string{"brand", "model", "year"} {
+		if _, ok := columns[field]; !ok {
+			return nil, ErrInvalidFipeDump
+		}
+	}
+
+	result := &entities.VehicleCatalogImport{}
+	seen := make(map[string]bool)
+	var models []dto.VehicleModelDTO
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			result.Errors = append(result.Errors, entities.VehicleCatalogImportError{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		model, err := u.parseFipeRecord(record, columns)
+		if err != nil {
+			result.Errors = append(result.Errors, entities.VehicleCatalogImportError{Line: line, Message: err.Error()})
+			continue
+		}
+		if model == nil {
+			result.Skipped++
+			continue
+		}
+
+		key := strings.ToLower(model.Brand + "|" + model.Model + "|" + strconv.Itoa(model.Year))
+		if seen[key] {
+			result.Skipped++
+			continue
+		}
+		seen[key] = true
+		models = append(models, *model)
+	}
+
+	if err := u.repo.Import(ctx, models); err != nil {
+		log.Error().Msgf("Error importing %d model years into the vehicle catalog: %v", len(models), err)
+		return nil, err
+	}
+	result.Imported = len(models)
+	return result, nil
+}
+
+func (u *VehicleCatalogUseCase) ListBrands(ctx context.Context) ([]string, error) {
+	brands, err := u.repo.ListBrands(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing the brands of the vehicle catalog: %v", err)
+		return nil, err
+	}
+	if brands == nil {
+		brands = []string{}
+	}
+	return brands, nil
+}
+
+func (u *VehicleCatalogUseCase) ListModels(ctx context.Context, brand, model string) ([]entities.VehicleModel, error) {
+	brand = strings.TrimSpace(brand)
+	if brand == "" {
+		return nil, ErrVehicleBrandNeeded
+	}
+
+	models, err := u.repo.ListModels(ctx, brand, strings.TrimSpace(model))
+	if err != nil {
+		log.Error().Msgf("Error listing the models of brand %s: %v", brand, err)
+		return nil, err
+	}
+	result := make([]entities.VehicleModel, 0, len(models))
+	for i := range models {
+		result = append(result, models[i].ToDomain())
+	}
+	return result, nil
+}
+
+// parseFipeRecord reads a line of the dump; a nil model year without error means the line is skipped
+func (u *VehicleCatalogUseCase) parseFipeRecord(record []string, columns map[string]int) (*dto.VehicleModelDTO, error) {
+	field := func(name string) string {
+		index, ok := columns[name]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	brand, model := field("brand"), field("model")
+	if brand == "" || model == "" {
+		return nil, errors.New("brand and model are required")
+	}
+
+	// the model year may come with the fuel, as in "2020 Gasolina"
+	yearField := strings.Fields(field("year"))
+	if len(yearField) == 0 {
+		return nil, errors.New("year is required")
+	}
+	year, err := strconv.Atoi(yearField[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid year %q", field("year"))
+	}
+	if year == fipeZeroKmYear {
+		return nil, nil
+	}
+	if year < 1900 || year > u.now().Year()+1 {
+		return nil, fmt.Errorf("invalid year %d", year)
+	}
+
+	return &dto.VehicleModelDTO{
+		FipeCode: field("fipe_code"),
+		Brand:    brand,
+		Model:    model,
+		Year:     year,
+	}, nil
+}
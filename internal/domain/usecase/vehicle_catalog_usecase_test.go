@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+func newVehicleCatalogTestUseCase() (*VehicleCatalogUseCase, *mocks.MockVehicleCatalogRepository) {
+	repo := new(mocks.MockVehicleCatalogRepository)
+	uc := NewVehicleCatalogUseCase(repo)
+	uc.now = func() time.Time { return time.Date(2025, 10, 6, 9, 0, 0, 0, time.UTC) }
+	return uc, repo
+}
+
+func TestVehicleCatalogUseCase_ImportFipe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("imports a dump separated by semicolons", func(t *testing.T) {
+		uc, repo := newVehicleCatalogTestUseCase()
+		dump := "\ufeffCódigo FIPE;Marca;Modelo;Ano Modelo;Valor\n" +
+			"014104-3;Honda;Civic Sedan EXL 2.0;2020 Gasolina;R$ 105.000,00\n" +
+			"014104-3;Honda;Civic Sedan EXL 2.0;32000 Gasolina;R$ 150.000,00\n" +
+			"005340-6;VW - VolksWagen;Gol 1.0;2019;R$ 45.000,00\n" +
+			"005340-6;VW - VolksWagen;gol 1.0;2019;R$ 45.000,00\n" +
+			"005340-6;VW - VolksWagen;;2019;R$ 45.000,00\n" +
+			"005340-6;VW - VolksWagen;Gol 1.0;1850;R$ 45.000,00\n"
+		repo.On("Import", ctx, []dto.VehicleModelDTO{
+			{FipeCode: "014104-3", Brand: "Honda", Model: "Civic Sedan EXL 2.0", Year: 2020},
+			{FipeCode: "005340-6", Brand: "VW - VolksWagen", Model: "Gol 1.0", Year: 2019},
+		}).Return(nil)
+
+		result, err := uc.ImportFipe(ctx, strings.NewReader(dump))
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Imported)
+		assert.Equal(t, 2, result.Skipped, "the brand new price and the repeated model year are skipped")
+		assert.Equal(t, []entities.VehicleCatalogImportError{
+			{Line: 6, Message: "brand and model are required"},
+			{Line: 7, Message: "invalid year 1850"},
+		}, result.Errors)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("imports a dump separated by commas", func(t *testing.T) {
+		uc, repo := newVehicleCatalogTestUseCase()
+		dump := "brand,model,year\nFiat,\"Uno Mille 1.0, Fire\",2010\n"
+		repo.On("Import", ctx, []dto.VehicleModelDTO{
+			{Brand: "Fiat", Model: "Uno Mille 1.0, Fire", Year: 2010},
+		}).Return(nil)
+
+		result, err := uc.ImportFipe(ctx, strings.NewReader(dump))
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Imported)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("dump without the year column", func(t *testing.T) {
+		uc, repo := newVehicleCatalogTestUseCase()
+
+		_, err := uc.ImportFipe(ctx, strings.NewReader("marca;modelo\nHonda;Civic\n"))
+
+		assert.Equal(t, ErrInvalidFipeDump, err)
+		repo.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+	})
+}
+
+func TestVehicleCatalogUseCase_ListModels(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists the model years of the brand", func(t *testing.T) {
+		uc, repo := newVehicleCatalogTestUseCase()
+		repo.On("ListModels", ctx, "Honda", "Civic").Return([]dto.VehicleModelDTO{
+			{ID: 1, Brand: "Honda", Model: "Civic", Year: 2019},
+			{ID: 2, Brand: "Honda", Model: "Civic", Year: 2020},
+		}, nil)
+
+		models, err := uc.ListModels(ctx, " Honda ", "Civic")
+
+		require.NoError(t, err)
+		assert.Len(t, models, 2)
+		assert.Equal(t, 2020, models[1].Year)
+	})
+
+	t.Run("brand is required", func(t *testing.T) {
+		uc, _ := newVehicleCatalogTestUseCase()
+
+		_, err := uc.ListModels(ctx, " ", "Civic")
+
+		assert.Equal(t, ErrVehicleBrandNeeded, err)
+	})
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	checkin "mecanica_xpto/internal/domain/repository/check_in"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/internal/domain/repository/vehicles"
+)
+
+// oilChangeKeywords and brakeServiceKeywords recognise the usual maintenance in the names and
+// categories of the services and parts, which are free text in the catalog
+var (
+	oilChangeKeywords    = []string{"óleo", "oleo", "oil"}
+	brakeServiceKeywords = []string{"freio", "pastilha", "brake"}
+)
+
+type IVehicleHistoryUseCase interface {
+	GetVehicleHistory(ctx context.Context, vehicleID uint) (*entities.VehicleHistory, error)
+}
+
+type VehicleHistoryUseCase struct {
+	vehicleRepo      vehicles.VehicleRepositoryInterface
+	serviceOrderRepo serviceorder.IServiceOrderRepository
+	checkInRepo      checkin.ICheckInRepository
+}
+
+var _ IVehicleHistoryUseCase = (*VehicleHistoryUseCase)(nil)
+
+func NewVehicleHistoryUseCase(vehicleRepo vehicles.VehicleRepositoryInterface, serviceOrderRepo serviceorder.IServiceOrderRepository, checkInRepo checkin.ICheckInRepository) *VehicleHistoryUseCase {
+	return &VehicleHistoryUseCase{
+		vehicleRepo:      vehicleRepo,
+		serviceOrderRepo: serviceOrderRepo,
+		checkInRepo:      checkInRepo,
+	}
+}
+
+// GetVehicleHistory lists the visits of the vehicle in chronological order, with the odometer read
+// at each check-in, and summarises the last visit, oil change and brake service
+func (u *VehicleHistoryUseCase) GetVehicleHistory(ctx context.Context, vehicleID uint) (*entities.VehicleHistory, error) {
+	vehicleDto, err := u.vehicleRepo.FindByID(vehicleID)
+	if err != nil {
+		log.Error().Msgf("Error finding vehicle with id %d: %v", vehicleID, err)
+		return nil, err
+	}
+	if vehicleDto == nil {
+		return nil, ErrVehicleNotFound
+	}
+
+	serviceOrders, err := u.serviceOrderRepo.ListByVehicle(vehicleID)
+	if err != nil {
+		log.Error().Msgf("Error listing service orders of vehicle %d: %v", vehicleID, err)
+		return nil, err
+	}
+
+	serviceOrderIDs := make([]uint, 0, len(serviceOrders))
+	for _, serviceOrder := range serviceOrders {
+		serviceOrderIDs = append(serviceOrderIDs, serviceOrder.ID)
+	}
+	checkIns, err := u.checkInRepo.ListByServiceOrders(ctx, serviceOrderIDs)
+	if err != nil {
+		log.Error().Msgf("Error listing check-ins of vehicle %d: %v", vehicleID, err)
+		return nil, err
+	}
+	odometers := make(map[uint]int, len(checkIns))
+	for _, checkIn := range checkIns {
+		odometers[checkIn.ServiceOrderID] = checkIn.Odometer
+	}
+
+	history := &entities.VehicleHistory{
+		Vehicle:          *vehicleDto.ToDomain(),
+		Visits:           make([]entities.VehicleVisit, 0, len(serviceOrders)),
+		OdometerTimeline: []entities.OdometerReading{},
+	}
+	for i := range serviceOrders {
+		visit := buildVehicleVisit(&serviceOrders[i])
+		if odometer, ok := odometers[visit.ServiceOrderID]; ok {
+			visit.Odometer = &odometer
+			history.OdometerTimeline = append(history.OdometerTimeline, entities.OdometerReading{
+				ServiceOrderID: visit.ServiceOrderID,
+				Date:           visit.ReceivedAt,
+				Odometer:       odometer,
+			})
+		}
+		history.Visits = append(history.Visits, visit)
+	}
+	history.Summary = summarizeVisits(history.Visits)
+	return history, nil
+}
+
+// buildVehicleVisit gathers the services and parts of the order and of its approved additional
+// repairs
+func buildVehicleVisit(serviceOrder *dto.ServiceOrderDTO) entities.VehicleVisit {
+	visit := entities.VehicleVisit{
+		ServiceOrderID: serviceOrder.ID,
+		Status:         serviceOrder.ServiceOrderStatus.ToDomain(),
+		CompletedAt:    serviceOrder.FinalExecutionDate,
+		Services:       []entities.VisitService{},
+		Parts:          []entities.VisitPart{},
+		Total:          serviceOrder.Estimate,
+	}
+	if serviceOrder.CreatedAt != nil {
+		visit.ReceivedAt = *serviceOrder.CreatedAt
+	}
+
+	for _, service := range serviceOrder.Services {
+		visit.Services = append(visit.Services, entities.VisitService{ServiceID: service.ID, Name: service.Name, Category: service.Category})
+	}
+	for _, part := range serviceOrder.PartsSupplies {
+		visit.Parts = append(visit.Parts, entities.VisitPart{PartsSupplyID: part.ID, Name: part.Name, Quantity: serviceOrder.PartsSupplyQuantity(part.ID)})
+	}
+
+	for i := range serviceOrder.AdditionalRepairs {
+		additionalRepair := &serviceOrder.AdditionalRepairs[i]
+		if !additionalRepair.ARStatus.ToDomain().IsAprovada() {
+			continue
+		}
+		additionalRepairID := additionalRepair.ID
+		for _, service := range additionalRepair.Services {
+			visit.Services = append(visit.Services, entities.VisitService{
+				ServiceID: service.ID, Name: service.Name, Category: service.Category, AdditionalRepairID: &additionalRepairID,
+			})
+		}
+		for _, part := range additionalRepair.PartsSupplies {
+			visit.Parts = append(visit.Parts, entities.VisitPart{
+				PartsSupplyID: part.ID, Name: part.Name, Quantity: additionalRepair.PartsSupplyQuantity(part.ID), AdditionalRepairID: &additionalRepairID,
+			})
+		}
+	}
+	return visit
+}
+
+// summarizeVisits expects the visits oldest first, so the later matches win
+func summarizeVisits(visits []entities.VehicleVisit) entities.VehicleMaintenanceSummary {
+	summary := entities.VehicleMaintenanceSummary{TotalVisits: len(visits)}
+	for i := range visits {
+		visit := &visits[i]
+		receivedAt := visit.ReceivedAt
+		summary.LastVisitAt = &receivedAt
+		if visit.Odometer != nil {
+			summary.LastOdometer = visit.Odometer
+		}
+
+		if !isWorkDone(visit.Status) {
+			continue
+		}
+		if record := findMaintenance(visit, oilChangeKeywords); record != nil {
+			summary.LastOilChange = record
+		}
+		if record := findMaintenance(visit, brakeServiceKeywords); record != nil {
+			summary.LastBrakeService = record
+		}
+	}
+	return summary
+}
+
+func isWorkDone(status valueobject.ServiceOrderStatus) bool {
+	return status == valueobject.StatusFinalizada || status == valueobject.StatusEntregue
+}
+
+// findMaintenance returns the first service, or else part, of the visit matching the keywords
+func findMaintenance(visit *entities.VehicleVisit, keywords []string) *entities.MaintenanceRecord {
+	date := visit.ReceivedAt
+	if visit.CompletedAt != nil {
+		date = *visit.CompletedAt
+	}
+	record := func(name string) *entities.MaintenanceRecord {
+		return &entities.MaintenanceRecord{ServiceOrderID: visit.ServiceOrderID, Date: date, Odometer: visit.Odometer, Service: name}
+	}
+
+	for _, service := range visit.Services {
+		if containsKeyword(service.Name+" "+service.Category, keywords) {
+			return record(service.Name)
+		}
+	}
+	for _, part := range visit.Parts {
+		if containsKeyword(part.Name, keywords) {
+			return record(part.Name)
+		}
+	}
+	return nil
+}
+
+func containsKeyword(text string, keywords []string) bool {
+	text = strings.ToLower(text)
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
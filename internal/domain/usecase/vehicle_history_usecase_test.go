@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+func newVehicleHistoryTestUseCase() (*VehicleHistoryUseCase, *mocks.MockVehicleRepository, *mocks.MockServiceOrderRepository, *mocks.MockCheckInRepository) {
+	vehicleRepo := mocks.NewMockVehicleRepository()
+	serviceOrderRepo := new(mocks.MockServiceOrderRepository)
+	checkInRepo := new(mocks.MockCheckInRepository)
+	return NewVehicleHistoryUseCase(vehicleRepo, serviceOrderRepo, checkInRepo), vehicleRepo, serviceOrderRepo, checkInRepo
+}
+
+func historyDate(month, day int) *time.Time {
+	date := time.Date(2025, time.Month(month), day, 9, 0, 0, 0, time.UTC)
+	return &date
+}
+
+func TestVehicleHistoryUseCase_GetVehicleHistory(t *testing.T) {
+	ctx := context.Background()
+	finished := dto.ServiceOrderStatusDTO{Description: valueobject.StatusFinalizada.String()}
+	oilChange := dto.ServiceDTO{ID: 1, Name: "Troca de Óleo", Category: "Lubrificação"}
+	brakePads := dto.ServiceDTO{ID: 2, Name: "Substituição de pastilhas", Category: "Freios"}
+	alignment := dto.ServiceDTO{ID: 3, Name: "Alinhamento"}
+
+	serviceOrders := []dto.ServiceOrderDTO{
+		{
+			ID: 10, VehicleID: 5, ServiceOrderStatus: finished, CreatedAt: historyDate(1, 10), FinalExecutionDate: historyDate(1, 11),
+			Estimate: 250, Services: []dto.ServiceDTO{oilChange},
+			PartsSupplies:    []dto.PartsSupplyDTO{{ID: 7, Name: "Filtro de óleo"}},
+			PartsSupplyItems: []dto.PartsSupplyServiceOrderDTO{{PartsSupplyID: 7, ServiceOrderID: 10, Quantity: 1}},
+		},
+		{
+			ID: 11, VehicleID: 5, ServiceOrderStatus: finished, CreatedAt: historyDate(6, 2), FinalExecutionDate: historyDate(6, 3),
+			Estimate: 180, Services: []dto.ServiceDTO{alignment},
+			AdditionalRepairs: []dto.AdditionalRepairDTO{
+				{ID: 30, ARStatus: dto.AdditionalRepairStatusDTO{Description: "APROVADA"}, Services: []dto.ServiceDTO{brakePads},
+					PartsSupplies:    []dto.PartsSupplyDTO{{ID: 8, Name: "Pastilha dianteira"}},
+					PartsSupplyItems: []dto.PartsSupplyAdditionalRepairDTO{{PartsSupplyID: 8, AdditionalRepairID: 30, Quantity: 4}}},
+				{ID: 31, ARStatus: dto.AdditionalRepairStatusDTO{Description: "REJEITADA"}, Services: []dto.ServiceDTO{oilChange}},
+			},
+		},
+		{
+			ID: 12, VehicleID: 5, ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: valueobject.StatusEmExecucao.String()},
+			CreatedAt: historyDate(9, 20), Services: []dto.ServiceDTO{oilChange},
+		},
+	}
+
+	t.Run("lists the visits with their odometer and summarises the maintenance", func(t *testing.T) {
+		uc, vehicleRepo, serviceOrderRepo, checkInRepo := newVehicleHistoryTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(&dto.VehicleDTO{ID: 5, Plate: "ABC1D23"}, nil)
+		serviceOrderRepo.On("ListByVehicle", uint(5)).Return(serviceOrders, nil)
+		checkInRepo.On("ListByServiceOrders", ctx, []uint{10, 11, 12}).Return([]dto.CheckInDTO{
+			{ServiceOrderID: 10, Odometer: 40100},
+			{ServiceOrderID: 12, Odometer: 51230},
+		}, nil)
+
+		history, err := uc.GetVehicleHistory(ctx, 5)
+
+		require.NoError(t, err)
+		require.Len(t, history.Visits, 3)
+		assert.Equal(t, 40100, *history.Visits[0].Odometer)
+		assert.Nil(t, history.Visits[1].Odometer)
+		assert.Equal(t, 1, history.Visits[0].Parts[0].Quantity)
+
+		second := history.Visits[1]
+		require.Len(t, second.Services, 2, "the services of the rejected additional repair are left out")
+		assert.Equal(t, uint(30), *second.Services[1].AdditionalRepairID)
+		assert.Equal(t, 4, second.Parts[0].Quantity)
+
+		require.Len(t, history.OdometerTimeline, 2)
+		assert.Equal(t, 51230, history.OdometerTimeline[1].Odometer)
+
+		summary := history.Summary
+		assert.Equal(t, 3, summary.TotalVisits)
+		assert.Equal(t, *historyDate(9, 20), *summary.LastVisitAt)
+		assert.Equal(t, 51230, *summary.LastOdometer)
+		require.NotNil(t, summary.LastOilChange)
+		assert.Equal(t, uint(10), summary.LastOilChange.ServiceOrderID, "the order still in execution is not work done")
+		assert.Equal(t, *historyDate(1, 11), summary.LastOilChange.Date)
+		assert.Equal(t, 40100, *summary.LastOilChange.Odometer)
+		require.NotNil(t, summary.LastBrakeService)
+		assert.Equal(t, uint(11), summary.LastBrakeService.ServiceOrderID)
+		assert.Equal(t, "Substituição de pastilhas", summary.LastBrakeService.Service)
+	})
+
+	t.Run("keeps the services and parts of a delivered order", func(t *testing.T) {
+		invoiceUseCase := new(mocks.MockInvoiceUseCase)
+		invoiceUseCase.On("IssueInvoices", ctx, uint(1)).Return([]entities.Invoice{}, nil)
+		serviceOrderUseCase, serviceOrderRepo, stored := newServiceOrderFlow(func(serviceorder.IServiceOrderRepository, ITaxUseCase) IInvoiceUseCase {
+			return invoiceUseCase
+		})
+		for _, step := range serviceOrderFlowSteps {
+			_, err := serviceOrderUseCase.UpdateServiceOrder(ctx, step.request, step.flow)
+			require.NoError(t, err, step.request.ServiceOrderStatus)
+		}
+		vehicleRepo := mocks.NewMockVehicleRepository()
+		checkInRepo := new(mocks.MockCheckInRepository)
+		vehicleRepo.On("FindByID", uint(5)).Return(&dto.VehicleDTO{ID: 5}, nil)
+		serviceOrderRepo.On("ListByVehicle", uint(5)).Return([]dto.ServiceOrderDTO{*stored}, nil)
+		checkInRepo.On("ListByServiceOrders", ctx, []uint{1}).Return([]dto.CheckInDTO{}, nil)
+		uc := NewVehicleHistoryUseCase(vehicleRepo, serviceOrderRepo, checkInRepo)
+
+		history, err := uc.GetVehicleHistory(ctx, 5)
+
+		require.NoError(t, err)
+		require.Len(t, history.Visits, 1)
+		visit := history.Visits[0]
+		assert.Equal(t, valueobject.StatusEntregue, visit.Status)
+		require.Len(t, visit.Services, 1)
+		assert.Equal(t, uint(1), visit.Services[0].ServiceID)
+		require.Len(t, visit.Parts, 1)
+		assert.Equal(t, uint(2), visit.Parts[0].PartsSupplyID)
+		assert.Equal(t, 2, visit.Parts[0].Quantity)
+	})
+
+	t.Run("returns an empty history for a vehicle without visits", func(t *testing.T) {
+		uc, vehicleRepo, serviceOrderRepo, checkInRepo := newVehicleHistoryTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(&dto.VehicleDTO{ID: 5}, nil)
+		serviceOrderRepo.On("ListByVehicle", uint(5)).Return([]dto.ServiceOrderDTO{}, nil)
+		checkInRepo.On("ListByServiceOrders", ctx, mock.Anything).Return([]dto.CheckInDTO{}, nil)
+
+		history, err := uc.GetVehicleHistory(ctx, 5)
+
+		require.NoError(t, err)
+		assert.Empty(t, history.Visits)
+		assert.Equal(t, 0, history.Summary.TotalVisits)
+		assert.Nil(t, history.Summary.LastOilChange)
+	})
+
+	t.Run("fails for an unknown vehicle", func(t *testing.T) {
+		uc, vehicleRepo, _, _ := newVehicleHistoryTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(nil, nil)
+
+		_, err := uc.GetVehicleHistory(ctx, 5)
+
+		assert.ErrorIs(t, err, ErrVehicleNotFound)
+	})
+}
@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/customers"
+	vehiclecatalog "mecanica_xpto/internal/domain/repository/vehicle_catalog"
+	"mecanica_xpto/internal/domain/repository/vehicles"
+)
+
+var ErrInvalidVehicleFile = errors.New("the vehicle file must be a CSV file with plate, brand, model, year and customer document columns")
+
+// vehicleFileHeader is the header of the exported file, which the import reads back
+var vehicleFileHeader = []string{"plate", "brand", "model", "year", "customer_document", "vin"}
+
+// vehicleFileColumns maps the headers accepted by the import to the fields of the vehicle
+var vehicleFileColumns = map[string]string{
+	"plate":             "plate",
+	"placa":             "plate",
+	"brand":             "brand",
+	"marca":             "brand",
+	"model":             "model",
+	"modelo":            "model",
+	"year":              "year",
+	"ano":               "year",
+	"ano_modelo":        "year",
+	"customer_document": "customer_document",
+	"document":          "customer_document",
+	"documento":         "customer_document",
+	"cpf_cnpj":          "customer_document",
+	"vin":               "vin",
+	"chassi":            "vin",
+}
+
+type IVehicleImportUseCase interface {
+	ImportVehicles(ctx context.Context, file io.Reader, dryRun bool) (*entities.VehicleImport, error)
+	ExportVehicles(ctx context.Context, w io.Writer, customerDocument string) error
+}
+
+type VehicleImportUseCase struct {
+	vehicleRepo  vehicles.VehicleRepositoryInterface
+	customerRepo customers.ICustomerRepository
+	catalogRepo  vehiclecatalog.IVehicleCatalogRepository
+}
+
+var _ IVehicleImportUseCase = (*VehicleImportUseCase)(nil)
+
+func NewVehicleImportUseCase(vehicleRepo vehicles.VehicleRepositoryInterface, customerRepo customers.ICustomerRepository, catalogRepo vehiclecatalog.IVehicleCatalogRepository) *VehicleImportUseCase {
+	return &VehicleImportUseCase{
+		vehicleRepo:  vehicleRepo,
+		customerRepo: customerRepo,
+		catalogRepo:  catalogRepo,
+	}
+}
+
+// ImportVehicles registers the vehicles of a CSV file, e.g. the fleet of a company, checking each
+// line as a vehicle registered on its own would be. The vehicles are registered only when every
+// line is valid, so the file can be fixed and sent again; a dry run only checks the lines.
+func (u *VehicleImportUseCase) ImportVehicles(ctx context.Context, file io.Reader, dryRun bool) (*entities.VehicleImport, error) {
+	reader, err := newCSVReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrInvalidVehicleFile
+	}
+	columns := make(map[string]int)
+	for index, name := range header {
+		if field, ok := vehicleFileColumns[normaliseCSVHeader(name)]; ok {
+			if _, found := columns[field]; !found {
+				columns[field] = index
+			}
+		}
+	}
+	for _, field := range []string{"plate", "brand", "model", "year", "customer_document"} {
+		if _, ok := columns[field]; !ok {
+			return nil, ErrInvalidVehicleFile
+		}
+	}
+
+	result := &entities.VehicleImport{DryRun: dryRun}
+	plateLines := make(map[valueobject.Plate]int)
+	customersByDocument := make(map[valueobject.CpfCnpj]*dto.CustomerDTO)
+	var vehiclesToCreate []entities.Vehicle
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			result.Total++
+			result.Errors = append(result.Errors, entities.VehicleImportError{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		result.Total++
+
+		field := func(name string) string {
+			index, ok := columns[name]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+		vehicle := entities.Vehicle{
+			Plate: valueobject.ParsePlate(field("plate")),
+			Brand: field("brand"),
+			Model: field("model"),
+			Year:  field("year"),
+			VIN:   valueobject.ParseVIN(field("vin")),
+		}
+
+		problems, err := u.checkVehicleLine(&vehicle, field("customer_document"), line, plateLines, customersByDocument)
+		if err != nil {
+			return nil, err
+		}
+		if len(problems) > 0 {
+			result.Errors = append(result.Errors, entities.VehicleImportError{
+				Line:    line,
+				Plate:   vehicle.Plate.String(),
+				Message: strings.Join(problems, "; "),
+			})
+			continue
+		}
+		vehiclesToCreate = append(vehiclesToCreate, vehicle)
+	}
+	result.Valid = len(vehiclesToCreate)
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+	if err := u.vehicleRepo.CreateBatch(vehiclesToCreate); err != nil {
+		log.Error().Msgf("Error importing %d vehicles: %v", len(vehiclesToCreate), err)
+		return nil, err
+	}
+	result.Imported = len(vehiclesToCreate)
+	return result, nil
+}
+
+// checkVehicleLine checks a line of the file, setting the owner of the vehicle and its brand and
+// model as written in the catalog. It returns what is wrong with the line, or an error when the
+// line could not be checked at all.
+func (u *VehicleImportUseCase) checkVehicleLine(vehicle *entities.Vehicle, document string, line int, plateLines map[valueobject.Plate]int, customersByDocument map[valueobject.CpfCnpj]*dto.CustomerDTO) ([]string, error) {
+	var problems []string
+
+	validPlate := vehicle.Plate.IsValidFormat()
+	if !validPlate {
+		problems = append(problems, MessageInvalidPlateFormat)
+	} else {
+		for _, plate := range vehicle.Plate.Equivalents() {
+			if previous, ok := plateLines[plate]; ok {
+				problems = append(problems, fmt.Sprintf("Plate repeated from line %d", previous))
+				validPlate = false
+				break
+			}
+		}
+		if validPlate {
+			for _, plate := range vehicle.Plate.Equivalents() {
+				plateLines[plate] = line
+			}
+		}
+	}
+
+	customerDocument, documentErr := valueobject.NewCpfCnpj(document)
+	if documentErr != nil {
+		problems = append(problems, "Invalid customer document")
+	}
+	if vehicle.VIN != "" && !vehicle.VIN.IsValid() {
+		problems = append(problems, MessageInvalidVIN)
+	}
+	if vehicle.Brand == "" || vehicle.Model == "" || vehicle.Year == "" {
+		problems = append(problems, "Brand, model and year are required")
+	}
+	if len(problems) > 0 {
+		return problems, nil
+	}
+
+	customer, ok := customersByDocument[customerDocument]
+	if !ok {
+		var err error
+		customer, err = u.customerRepo.GetByDocument(customerDocument.String())
+		if err != nil {
+			log.Error().Msgf("Error finding customer with document %s: %v", customerDocument.Mask(), err)
+			return nil, err
+		}
+		customersByDocument[customerDocument] = customer
+	}
+	if customer == nil || customer.ID == 0 {
+		problems = append(problems, "Customer not found")
+	} else {
+		vehicle.CustomerID = customer.ID
+	}
+
+	existingVehicle, err := u.vehicleRepo.FindByPlate(vehicle.Plate)
+	if err != nil {
+		log.Error().Msgf("Error finding vehicle with plate %s: %v", vehicle.Plate, err)
+		return nil, err
+	}
+	if existingVehicle != nil {
+		problems = append(problems, MessageVehicleAlreadyExists)
+	}
+
+	message, err := matchVehicleCatalog(u.catalogRepo, vehicle)
+	if errors.Is(err, ErrVehicleNotInCatalog) {
+		problems = append(problems, message)
+	} else if err != nil {
+		log.Error().Msgf("Error checking vehicle with plate %s against the catalog: %v", vehicle.Plate, err)
+		return nil, err
+	}
+
+	return problems, nil
+}
+
+// ExportVehicles writes the vehicles in the format read by the import, only those of the customer
+// when a document is given
+func (u *VehicleImportUseCase) ExportVehicles(ctx context.Context, w io.Writer, customerDocument string) error {
+	var vehicleDTOs []dto.VehicleDTO
+	if strings.TrimSpace(customerDocument) == "" {
+		var err error
+		vehicleDTOs, err = u.vehicleRepo.FindAll()
+		if err != nil {
+			log.Error().Msgf("Error listing vehicles: %v", err)
+			return err
+		}
+	} else {
+		document, err := valueobject.NewCpfCnpj(customerDocument)
+		if err != nil {
+			return ErrInvalidDocumentFormat
+		}
+		customer, err := u.customerRepo.GetByDocument(document.String())
+		if err != nil {
+			log.Error().Msgf("Error finding customer with document %s: %v", document.Mask(), err)
+			return err
+		}
+		if customer == nil || customer.ID == 0 {
+			return ErrCustomerNotFound
+		}
+		vehicleDTOs, err = u.vehicleRepo.FindByCustomerID(customer.ID)
+		if err != nil {
+			log.Error().Msgf("Error listing vehicles of customer %d: %v", customer.ID, err)
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(vehicleFileHeader); err != nil {
+		return err
+	}
+	for _, vehicle := range vehicleDTOs {
+		var document string
+		if vehicle.Customer != nil {
+			document = vehicle.Customer.CpfCnpj
+		}
+		if err := writer.Write([]string{vehicle.Plate, vehicle.Brand, vehicle.Model, vehicle.Year, document, vehicle.VIN}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+func newVehicleImportTestUseCase() (*VehicleImportUseCase, *mocks.MockVehicleRepository, *MockCustomerRepository) {
+	vehicleRepo := mocks.NewMockVehicleRepository()
+	customerRepo := new(MockCustomerRepository)
+	return NewVehicleImportUseCase(vehicleRepo, customerRepo, emptyVehicleCatalog()), vehicleRepo, customerRepo
+}
+
+func TestVehicleImportUseCase_ImportVehicles(t *testing.T) {
+	ctx := context.Background()
+	fleet := "placa;marca;modelo;ano;cpf_cnpj\n" +
+		"abc-1234;Fiat;Strada;2022;11.222.333/0001-81\n" +
+		"DEF1G23;Fiat;Toro;2023;11.222.333/0001-81\n"
+
+	t.Run("imports the fleet of a company", func(t *testing.T) {
+		uc, vehicleRepo, customerRepo := newVehicleImportTestUseCase()
+		customerRepo.On("GetByDocument", "11222333000181").Return(&dto.CustomerDTO{ID: 7}, nil).Once()
+		vehicleRepo.On("FindByPlate", mock.Anything).Return(nil, nil)
+		vehicleRepo.On("CreateBatch", []entities.Vehicle{
+			{Plate: "ABC1234", Brand: "Fiat", Model: "Strada", Year: "2022", CustomerID: 7},
+			{Plate: "DEF1G23", Brand: "Fiat", Model: "Toro", Year: "2023", CustomerID: 7},
+		}).Return(nil)
+
+		result, err := uc.ImportVehicles(ctx, strings.NewReader(fleet), false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Total)
+		assert.Equal(t, 2, result.Imported)
+		assert.Empty(t, result.Errors)
+		vehicleRepo.AssertExpectations(t)
+		customerRepo.AssertExpectations(t)
+	})
+
+	t.Run("dry run only checks the lines", func(t *testing.T) {
+		uc, vehicleRepo, customerRepo := newVehicleImportTestUseCase()
+		customerRepo.On("GetByDocument", "11222333000181").Return(&dto.CustomerDTO{ID: 7}, nil)
+		vehicleRepo.On("FindByPlate", mock.Anything).Return(nil, nil)
+
+		result, err := uc.ImportVehicles(ctx, strings.NewReader(fleet), true)
+
+		require.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 2, result.Valid)
+		assert.Equal(t, 0, result.Imported)
+		vehicleRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+	})
+
+	t.Run("reports the invalid lines and imports nothing", func(t *testing.T) {
+		uc, vehicleRepo, customerRepo := newVehicleImportTestUseCase()
+		file := "plate,brand,model,year,customer_document\n" +
+			"ABC1234,Fiat,Strada,2022,52998224725\n" +
+			"ABC1C34,Fiat,Strada,2022,52998224725\n" +
+			"XYZ12,Fiat,Uno,2010,123\n" +
+			"GHI5678,Fiat,Uno,2010,11222333000181\n" +
+			"JKL9012,Fiat,Mobi,2021,52998224725\n"
+		customerRepo.On("GetByDocument", "52998224725").Return(&dto.CustomerDTO{ID: 3}, nil)
+		customerRepo.On("GetByDocument", "11222333000181").Return(nil, nil)
+		vehicleRepo.On("FindByPlate", valueobject.Plate("ABC1234")).Return(nil, nil)
+		vehicleRepo.On("FindByPlate", valueobject.Plate("GHI5678")).Return(nil, nil)
+		vehicleRepo.On("FindByPlate", valueobject.Plate("JKL9012")).Return(&dto.VehicleDTO{ID: 40, Plate: "JKL9012"}, nil)
+
+		result, err := uc.ImportVehicles(ctx, strings.NewReader(file), false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, result.Total)
+		assert.Equal(t, 1, result.Valid)
+		assert.Equal(t, 0, result.Imported)
+		assert.Equal(t, []entities.VehicleImportError{
+			{Line: 3, Plate: "ABC1C34", Message: "Plate repeated from line 2"},
+			{Line: 4, Plate: "XYZ12", Message: "Invalid plate format; Invalid customer document"},
+			{Line: 5, Plate: "GHI5678", Message: "Customer not found"},
+			{Line: 6, Plate: "JKL9012", Message: MessageVehicleAlreadyExists},
+		}, result.Errors)
+		vehicleRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+	})
+
+	t.Run("file without the customer document", func(t *testing.T) {
+		uc, _, _ := newVehicleImportTestUseCase()
+
+		_, err := uc.ImportVehicles(ctx, strings.NewReader("plate,brand,model,year\nABC1234,Fiat,Uno,2010\n"), false)
+
+		assert.Equal(t, ErrInvalidVehicleFile, err)
+	})
+}
+
+func TestVehicleImportUseCase_ExportVehicles(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("exports the vehicles of the customer in the import format", func(t *testing.T) {
+		uc, vehicleRepo, customerRepo := newVehicleImportTestUseCase()
+		customer := &dto.CustomerDTO{ID: 7, CpfCnpj: "11222333000181"}
+		customerRepo.On("GetByDocument", "11222333000181").Return(customer, nil)
+		vehicleRepo.On("FindByCustomerID", uint(7)).Return([]dto.VehicleDTO{
+			{ID: 1, Plate: "ABC1234", Brand: "Fiat", Model: "Strada", Year: "2022", Customer: customer},
+			{ID: 2, Plate: "DEF1G23", Brand: "Fiat", Model: "Toro, Volcano", Year: "2023", Customer: customer, VIN: "1HGCM82633A004352"},
+		}, nil)
+
+		var out bytes.Buffer
+		err := uc.ExportVehicles(ctx, &out, "11.222.333/0001-81")
+
+		require.NoError(t, err)
+		assert.Equal(t, "plate,brand,model,year,customer_document,vin\n"+
+			"ABC1234,Fiat,Strada,2022,11222333000181,\n"+
+			"DEF1G23,Fiat,\"Toro, Volcano\",2023,11222333000181,1HGCM82633A004352\n", out.String())
+	})
+
+	t.Run("customer not found", func(t *testing.T) {
+		uc, _, customerRepo := newVehicleImportTestUseCase()
+		customerRepo.On("GetByDocument", "52998224725").Return(nil, nil)
+
+		err := uc.ExportVehicles(ctx, &bytes.Buffer{}, "529.982.247-25")
+
+		assert.Equal(t, ErrCustomerNotFound, err)
+	})
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/repository/customers"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	vehicleownership "mecanica_xpto/internal/domain/repository/vehicle_ownership"
+	"mecanica_xpto/internal/domain/repository/vehicles"
+)
+
+var (
+	ErrVehicleAlreadyOwned          = errors.New("the vehicle already belongs to the customer")
+	ErrVehicleHasActiveServiceOrder = errors.New("the vehicle has an active service order")
+	ErrVehicleOwnerChanged          = errors.New("the vehicle was transferred in the meantime")
+)
+
+type IVehicleOwnershipUseCase interface {
+	TransferVehicle(ctx context.Context, vehicleID uint, request entities.VehicleTransferRequest, transferredBy string) (*entities.VehicleOwnership, error)
+	ListOwners(ctx context.Context, vehicleID uint) ([]entities.VehicleOwnership, error)
+}
+
+type VehicleOwnershipUseCase struct {
+	repo             vehicleownership.IVehicleOwnershipRepository
+	vehicleRepo      vehicles.VehicleRepositoryInterface
+	customerRepo     customers.ICustomerRepository
+	serviceOrderRepo serviceorder.IServiceOrderRepository
+	now              func() time.Time
+}
+
+var _ IVehicleOwnershipUseCase = (*VehicleOwnershipUseCase)(nil)
+
+func NewVehicleOwnershipUseCase(repo vehicleownership.IVehicleOwnershipRepository, vehicleRepo vehicles.VehicleRepositoryInterface, customerRepo customers.ICustomerRepository, serviceOrderRepo serviceorder.IServiceOrderRepository) *VehicleOwnershipUseCase {
+	return &VehicleOwnershipUseCase{
+		repo:             repo,
+		vehicleRepo:      vehicleRepo,
+		customerRepo:     customerRepo,
+		serviceOrderRepo: serviceOrderRepo,
+		now:              time.Now,
+	}
+}
+
+// TransferVehicle hands the vehicle over to another customer, e.g. when it is sold. It is refused
+// while an order of the vehicle is still open, since that order belongs to the current owner; the
+// past orders stay with the customer they were opened for.
+func (u *VehicleOwnershipUseCase) TransferVehicle(ctx context.Context, vehicleID uint, request entities.VehicleTransferRequest, transferredBy string) (*entities.VehicleOwnership, error) {
+	vehicleDto, err := u.vehicleRepo.FindByID(vehicleID)
+	if err != nil {
+		log.Error().Msgf("Error finding vehicle with id %d: %v", vehicleID, err)
+		return nil, err
+	}
+	if vehicleDto == nil {
+		return nil, ErrVehicleNotFound
+	}
+	if request.CustomerID == vehicleDto.CustomerID {
+		return nil, ErrVehicleAlreadyOwned
+	}
+
+	customerDto, err := u.customerRepo.GetByID(request.CustomerID)
+	if err != nil {
+		log.Error().Msgf("Error finding customer with id %d: %v", request.CustomerID, err)
+		return nil, err
+	}
+	if customerDto == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	serviceOrders, err := u.serviceOrderRepo.ListByVehicle(vehicleID)
+	if err != nil {
+		log.Error().Msgf("Error listing service orders of vehicle %d: %v", vehicleID, err)
+		return nil, err
+	}
+	for _, serviceOrder := range serviceOrders {
+		if !serviceOrder.ServiceOrderStatus.ToDomain().IsClosed() {
+			return nil, ErrVehicleHasActiveServiceOrder
+		}
+	}
+
+	ownership := &dto.VehicleOwnershipDTO{
+		CustomerID:    request.CustomerID,
+		StartedAt:     u.now(),
+		TransferredBy: transferredBy,
+		Notes:         request.Notes,
+	}
+	if err := u.repo.Transfer(ctx, vehicleDto, ownership); err != nil {
+		if errors.Is(err, vehicleownership.ErrOwnerChanged) {
+			return nil, ErrVehicleOwnerChanged
+		}
+		log.Error().Msgf("Error transferring vehicle %d to customer %d: %v", vehicleID, request.CustomerID, err)
+		return nil, err
+	}
+
+	ownership.Customer = customerDto
+	result := ownership.ToDomain()
+	return &result, nil
+}
+
+// ListOwners lists the owners of the vehicle, oldest first. A vehicle never transferred has no
+// ownership recorded, so its current owner is listed from the registration of the vehicle.
+func (u *VehicleOwnershipUseCase) ListOwners(ctx context.Context, vehicleID uint) ([]entities.VehicleOwnership, error) {
+	vehicleDto, err := u.vehicleRepo.FindByID(vehicleID)
+	if err != nil {
+		log.Error().Msgf("Error finding vehicle with id %d: %v", vehicleID, err)
+		return nil, err
+	}
+	if vehicleDto == nil {
+		return nil, ErrVehicleNotFound
+	}
+
+	ownerships, err := u.repo.ListByVehicle(ctx, vehicleID)
+	if err != nil {
+		log.Error().Msgf("Error listing owners of vehicle %d: %v", vehicleID, err)
+		return nil, err
+	}
+
+	result := make([]entities.VehicleOwnership, 0, len(ownerships)+1)
+	for i := range ownerships {
+		result = append(result, ownerships[i].ToDomain())
+	}
+	if len(ownerships) == 0 {
+		current := dto.VehicleOwnershipDTO{
+			VehicleID:  vehicleDto.ID,
+			CustomerID: vehicleDto.CustomerID,
+			Customer:   vehicleDto.Customer,
+			StartedAt:  vehicleDto.CreatedAt,
+		}
+		result = append(result, current.ToDomain())
+	}
+	return result, nil
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	vehicleownership "mecanica_xpto/internal/domain/repository/vehicle_ownership"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+)
+
+var transferNow = time.Date(2025, 10, 2, 14, 0, 0, 0, time.UTC)
+
+func newVehicleOwnershipTestUseCase() (*VehicleOwnershipUseCase, *mocks.MockVehicleOwnershipRepository, *mocks.MockVehicleRepository, *MockCustomerRepository, *mocks.MockServiceOrderRepository) {
+	repo := new(mocks.MockVehicleOwnershipRepository)
+	vehicleRepo := mocks.NewMockVehicleRepository()
+	customerRepo := new(MockCustomerRepository)
+	serviceOrderRepo := new(mocks.MockServiceOrderRepository)
+	uc := NewVehicleOwnershipUseCase(repo, vehicleRepo, customerRepo, serviceOrderRepo)
+	uc.now = func() time.Time { return transferNow }
+	return uc, repo, vehicleRepo, customerRepo, serviceOrderRepo
+}
+
+func ownershipServiceOrder(id uint, status valueobject.ServiceOrderStatus) dto.ServiceOrderDTO {
+	return dto.ServiceOrderDTO{ID: id, VehicleID: 5, CustomerID: 1, ServiceOrderStatus: dto.ServiceOrderStatusDTO{Description: status.String()}}
+}
+
+func TestVehicleOwnershipUseCase_TransferVehicle(t *testing.T) {
+	ctx := context.Background()
+	vehicle := &dto.VehicleDTO{ID: 5, CustomerID: 1, Plate: "ABC1D23"}
+	request := entities.VehicleTransferRequest{CustomerID: 2, Notes: "Vendido"}
+
+	t.Run("transfers the vehicle to the new owner", func(t *testing.T) {
+		uc, repo, vehicleRepo, customerRepo, serviceOrderRepo := newVehicleOwnershipTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(vehicle, nil)
+		customerRepo.On("GetByID", uint(2)).Return(&dto.CustomerDTO{ID: 2, FullName: "João Lima"}, nil)
+		serviceOrderRepo.On("ListByVehicle", uint(5)).Return([]dto.ServiceOrderDTO{
+			ownershipServiceOrder(10, valueobject.StatusEntregue),
+			ownershipServiceOrder(11, valueobject.StatusCancelada),
+		}, nil)
+		repo.On("Transfer", ctx, vehicle, mock.MatchedBy(func(ownership *dto.VehicleOwnershipDTO) bool {
+			return ownership.CustomerID == 2 && ownership.StartedAt.Equal(transferNow) && ownership.TransferredBy == "ana@mecanicaxpto.com.br"
+		})).Return(nil)
+
+		ownership, err := uc.TransferVehicle(ctx, 5, request, "ana@mecanicaxpto.com.br")
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), ownership.CustomerID)
+		assert.Equal(t, "João Lima", ownership.CustomerName)
+		assert.Equal(t, "Vendido", ownership.Notes)
+		assert.Nil(t, ownership.EndedAt)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("refuses while the vehicle has an active service order", func(t *testing.T) {
+		uc, repo, vehicleRepo, customerRepo, serviceOrderRepo := newVehicleOwnershipTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(vehicle, nil)
+		customerRepo.On("GetByID", uint(2)).Return(&dto.CustomerDTO{ID: 2}, nil)
+		serviceOrderRepo.On("ListByVehicle", uint(5)).Return([]dto.ServiceOrderDTO{
+			ownershipServiceOrder(10, valueobject.StatusEntregue),
+			ownershipServiceOrder(12, valueobject.StatusFinalizada),
+		}, nil)
+
+		_, err := uc.TransferVehicle(ctx, 5, request, "ana@mecanicaxpto.com.br")
+
+		assert.ErrorIs(t, err, ErrVehicleHasActiveServiceOrder)
+		repo.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("refuses a transfer to the current owner", func(t *testing.T) {
+		uc, _, vehicleRepo, _, _ := newVehicleOwnershipTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(vehicle, nil)
+
+		_, err := uc.TransferVehicle(ctx, 5, entities.VehicleTransferRequest{CustomerID: 1}, "")
+
+		assert.ErrorIs(t, err, ErrVehicleAlreadyOwned)
+	})
+
+	t.Run("fails when the new owner does not exist", func(t *testing.T) {
+		uc, _, vehicleRepo, customerRepo, _ := newVehicleOwnershipTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(vehicle, nil)
+		customerRepo.On("GetByID", uint(2)).Return(nil, nil)
+
+		_, err := uc.TransferVehicle(ctx, 5, request, "")
+
+		assert.ErrorIs(t, err, ErrCustomerNotFound)
+	})
+
+	t.Run("fails when the vehicle was transferred in the meantime", func(t *testing.T) {
+		uc, repo, vehicleRepo, customerRepo, serviceOrderRepo := newVehicleOwnershipTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(vehicle, nil)
+		customerRepo.On("GetByID", uint(2)).Return(&dto.CustomerDTO{ID: 2}, nil)
+		serviceOrderRepo.On("ListByVehicle", uint(5)).Return([]dto.ServiceOrderDTO{}, nil)
+		repo.On("Transfer", ctx, vehicle, mock.Anything).Return(vehicleownership.ErrOwnerChanged)
+
+		_, err := uc.TransferVehicle(ctx, 5, request, "")
+
+		assert.ErrorIs(t, err, ErrVehicleOwnerChanged)
+	})
+}
+
+func TestVehicleOwnershipUseCase_ListOwners(t *testing.T) {
+	ctx := context.Background()
+	registeredAt := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	vehicle := &dto.VehicleDTO{ID: 5, CustomerID: 2, CreatedAt: registeredAt, Customer: &dto.CustomerDTO{ID: 2, FullName: "João Lima"}}
+
+	t.Run("lists the recorded owners", func(t *testing.T) {
+		uc, repo, vehicleRepo, _, _ := newVehicleOwnershipTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(vehicle, nil)
+		repo.On("ListByVehicle", ctx, uint(5)).Return([]dto.VehicleOwnershipDTO{
+			{ID: 1, VehicleID: 5, CustomerID: 1, StartedAt: registeredAt, EndedAt: &transferNow},
+			{ID: 2, VehicleID: 5, CustomerID: 2, StartedAt: transferNow, Customer: &dto.CustomerDTO{FullName: "João Lima"}},
+		}, nil)
+
+		owners, err := uc.ListOwners(ctx, 5)
+
+		require.NoError(t, err)
+		require.Len(t, owners, 2)
+		assert.Equal(t, transferNow, *owners[0].EndedAt)
+		assert.Equal(t, "João Lima", owners[1].CustomerName)
+	})
+
+	t.Run("lists the current owner of a vehicle never transferred", func(t *testing.T) {
+		uc, repo, vehicleRepo, _, _ := newVehicleOwnershipTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(vehicle, nil)
+		repo.On("ListByVehicle", ctx, uint(5)).Return([]dto.VehicleOwnershipDTO{}, nil)
+
+		owners, err := uc.ListOwners(ctx, 5)
+
+		require.NoError(t, err)
+		require.Len(t, owners, 1)
+		assert.Equal(t, uint(2), owners[0].CustomerID)
+		assert.Equal(t, registeredAt, owners[0].StartedAt)
+		assert.Nil(t, owners[0].EndedAt)
+	})
+
+	t.Run("fails when the vehicle does not exist", func(t *testing.T) {
+		uc, _, vehicleRepo, _, _ := newVehicleOwnershipTestUseCase()
+		vehicleRepo.On("FindByID", uint(5)).Return(nil, nil)
+
+		_, err := uc.ListOwners(ctx, 5)
+
+		assert.ErrorIs(t, err, ErrVehicleNotFound)
+	})
+}
@@ -1,10 +1,15 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"mecanica_xpto/internal/domain/model/dto"
 	"mecanica_xpto/internal/domain/model/entities"
 	"mecanica_xpto/internal/domain/model/valueobject"
+	vehiclecatalog "mecanica_xpto/internal/domain/repository/vehicle_catalog"
 	"mecanica_xpto/internal/domain/repository/vehicles"
+	"strconv"
+	"strings"
 )
 
 var (
@@ -12,6 +17,9 @@ var (
 	ErrInvalidPlateFormat   = errors.New("invalid plate format")
 	ErrVehicleAlreadyExists = errors.New("vehicle already exists")
 	ErrInvalidID            = errors.New("invalid vehicle ID")
+	ErrVehicleOwnerChange   = errors.New("the owner of a vehicle is changed by transferring it")
+	ErrInvalidVIN           = errors.New("invalid VIN")
+	ErrVehicleNotInCatalog  = errors.New("vehicle model not found in the catalog")
 )
 
 var (
@@ -23,6 +31,8 @@ var (
 	MessageErrorUpdatingVehicle       = "Error updating the vehicle"
 	MessageVehicleAlreadyExists       = "vehicle already exists with the same plate number"
 	MessageErrorSearch                = "Error searching existing vehicles"
+	MessageInvalidVIN                 = "Invalid VIN"
+	MessageVehicleNotInCatalog        = "Brand, model and year not found in the vehicle catalog"
 )
 
 type VehicleServiceInterface interface {
@@ -37,11 +47,12 @@ type VehicleServiceInterface interface {
 }
 
 type VehicleService struct {
-	repo vehicles.VehicleRepositoryInterface
+	repo        vehicles.VehicleRepositoryInterface
+	catalogRepo vehiclecatalog.IVehicleCatalogRepository
 }
 
-func NewVehicleService(repo vehicles.VehicleRepositoryInterface) VehicleServiceInterface {
-	return &VehicleService{repo: repo}
+func NewVehicleService(repo vehicles.VehicleRepositoryInterface, catalogRepo vehiclecatalog.IVehicleCatalogRepository) VehicleServiceInterface {
+	return &VehicleService{repo: repo, catalogRepo: catalogRepo}
 }
 
 func (s *VehicleService) GetAllVehicles() ([]entities.Vehicle, error) {
@@ -100,9 +111,17 @@ func (s *VehicleService) GetVehiclesByCustomerID(customerID uint) ([]entities.Ve
 	return vehiclesList, nil
 }
 func (s *VehicleService) CreateVehicle(vehicle entities.Vehicle) (string, error) {
+	vehicle.Plate = valueobject.ParsePlate(vehicle.Plate.String())
+	vehicle.VIN = valueobject.ParseVIN(vehicle.VIN.String())
 	if !vehicle.Plate.IsValidFormat() {
 		return MessageInvalidPlateFormat, ErrInvalidPlateFormat
 	}
+	if vehicle.VIN != "" && !vehicle.VIN.IsValid() {
+		return MessageInvalidVIN, ErrInvalidVIN
+	}
+	if message, err := matchVehicleCatalog(s.catalogRepo, &vehicle); err != nil {
+		return message, err
+	}
 
 	existingVehicle, err := s.repo.FindByPlate(vehicle.Plate)
 	if err != nil {
@@ -119,9 +138,17 @@ func (s *VehicleService) CreateVehicle(vehicle entities.Vehicle) (string, error)
 }
 
 func (s *VehicleService) UpdateVehicle(vehicle entities.Vehicle) (string, error) {
+	vehicle.Plate = valueobject.ParsePlate(vehicle.Plate.String())
+	vehicle.VIN = valueobject.ParseVIN(vehicle.VIN.String())
 	if !vehicle.Plate.IsValidFormat() {
 		return MessageInvalidPlateFormat, ErrInvalidPlateFormat
 	}
+	if vehicle.VIN != "" && !vehicle.VIN.IsValid() {
+		return MessageInvalidVIN, ErrInvalidVIN
+	}
+	if message, err := matchVehicleCatalog(s.catalogRepo, &vehicle); err != nil {
+		return message, err
+	}
 	existingVehicle, err := s.repo.FindByID(vehicle.ID)
 	if err != nil {
 		return MessageErrorSearch, err
@@ -129,6 +156,13 @@ func (s *VehicleService) UpdateVehicle(vehicle entities.Vehicle) (string, error)
 	if existingVehicle == nil {
 		return MessageVehicleNotFound, ErrVehicleNotFound
 	}
+	if message, err := s.checkPlateAvailable(vehicle.Plate, vehicle.ID); err != nil {
+		return message, err
+	}
+	// A request without the VIN keeps the one registered
+	if vehicle.VIN == "" {
+		vehicle.VIN = valueobject.ParseVIN(existingVehicle.VIN)
+	}
 	err = s.repo.Update(vehicle)
 	if err != nil {
 		return MessageErrorUpdatingVehicle, err
@@ -148,10 +182,21 @@ func (s *VehicleService) UpdateVehiclePartial(id uint, updates map[string]interf
 
 	// Update only the fields that were provided
 	if plate, ok := updates["plate"].(string); ok {
-		if !valueobject.ParsePlate(plate).IsValidFormat() {
+		voPlate := valueobject.ParsePlate(plate)
+		if !voPlate.IsValidFormat() {
 			return MessageInvalidPlateFormat, ErrInvalidPlateFormat
 		}
-		existingVehicle.Plate = plate
+		if message, err := s.checkPlateAvailable(voPlate, id); err != nil {
+			return message, err
+		}
+		existingVehicle.Plate = voPlate.String()
+	}
+	if vin, ok := updates["vin"].(string); ok {
+		voVIN := valueobject.ParseVIN(vin)
+		if voVIN != "" && !voVIN.IsValid() {
+			return MessageInvalidVIN, ErrInvalidVIN
+		}
+		existingVehicle.VIN = voVIN.String()
 	}
 	if model, ok := updates["model"].(string); ok {
 		existingVehicle.Model = model
@@ -162,12 +207,18 @@ func (s *VehicleService) UpdateVehiclePartial(id uint, updates map[string]interf
 	if brand, ok := updates["brand"].(string); ok {
 		existingVehicle.Brand = brand
 	}
-	if customerId, ok := updates["customer_id"].(float64); ok {
-		existingVehicle.CustomerID = uint(customerId)
+	if customerId, ok := updates["customer_id"].(float64); ok && uint(customerId) != existingVehicle.CustomerID {
+		return MessageErrorUpdatingVehicle, ErrVehicleOwnerChange
 	}
 
 	// Convert DTO to domain entity and update
-	if err := s.repo.Update(*existingVehicle.ToDomain()); err != nil {
+	vehicle := existingVehicle.ToDomain()
+	if updates["brand"] != nil || updates["model"] != nil || updates["year"] != nil {
+		if message, err := matchVehicleCatalog(s.catalogRepo, vehicle); err != nil {
+			return message, err
+		}
+	}
+	if err := s.repo.Update(*vehicle); err != nil {
 		return "", err
 	}
 
@@ -189,3 +240,45 @@ func (s *VehicleService) DeleteVehicle(id uint) error {
 	err = s.repo.Delete(id)
 	return err
 }
+
+// checkPlateAvailable refuses a plate, in either format, that belongs to another vehicle than the
+// one being updated
+func (s *VehicleService) checkPlateAvailable(plate valueobject.Plate, vehicleID uint) (string, error) {
+	existingVehicle, err := s.repo.FindByPlate(plate)
+	if err != nil {
+		return MessageErrorSearch, err
+	}
+	if existingVehicle != nil && existingVehicle.ID != vehicleID {
+		return MessageVehicleAlreadyExists, ErrVehicleAlreadyExists
+	}
+	return "", nil
+}
+
+// matchVehicleCatalog checks the brand, model and year of the vehicle against the catalog, writing
+// the brand and the model as the catalog does. Any vehicle is accepted while no FIPE dump was
+// imported into the catalog.
+func matchVehicleCatalog(catalogRepo vehiclecatalog.IVehicleCatalogRepository, vehicle *entities.Vehicle) (string, error) {
+	ctx := context.Background()
+
+	var vehicleModel *dto.VehicleModelDTO
+	if year, err := strconv.Atoi(strings.TrimSpace(vehicle.Year)); err == nil {
+		vehicleModel, err = catalogRepo.FindModel(ctx, strings.TrimSpace(vehicle.Brand), strings.TrimSpace(vehicle.Model), year)
+		if err != nil {
+			return MessageErrorSearch, err
+		}
+	}
+	if vehicleModel != nil {
+		vehicle.Brand = vehicleModel.Brand
+		vehicle.Model = vehicleModel.Model
+		return "", nil
+	}
+
+	count, err := catalogRepo.Count(ctx)
+	if err != nil {
+		return MessageErrorSearch, err
+	}
+	if count > 0 {
+		return MessageVehicleNotInCatalog, ErrVehicleNotInCatalog
+	}
+	return "", nil
+}
@@ -17,10 +17,18 @@ var (
 	ErrorDatabase = errors.New("database error")
 )
 
+// emptyVehicleCatalog stands for a catalog no FIPE dump was imported into, which accepts any vehicle
+func emptyVehicleCatalog() *mocks.MockVehicleCatalogRepository {
+	catalog := new(mocks.MockVehicleCatalogRepository)
+	catalog.On("FindModel", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	catalog.On("Count", mock.Anything).Return(int64(0), nil).Maybe()
+	return catalog
+}
+
 func TestGetVehicles(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		mockVehicles := []dto.VehicleDTO{
 			{
@@ -55,7 +63,7 @@ func TestGetVehicles(t *testing.T) {
 
 	t.Run("database error", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		mockRepo.On("FindAll").Return(nil, ErrorDatabase)
 
@@ -70,7 +78,7 @@ func TestGetVehicles(t *testing.T) {
 func TestGetVehicleByID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		mockVehicleDTO := &dto.VehicleDTO{
 			ID:         1,
@@ -94,7 +102,7 @@ func TestGetVehicleByID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		mockRepo.On("FindByID", uint(1)).Return(nil, errors.New("not found"))
 
@@ -109,7 +117,7 @@ func TestGetVehicleByID(t *testing.T) {
 func TestGetVehicleByPlate(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		plate := valueobject.ParsePlate("ABC1234")
 		mockVehicleDTO := &dto.VehicleDTO{
@@ -134,7 +142,7 @@ func TestGetVehicleByPlate(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		plate := valueobject.ParsePlate("XYZ9999")
 		mockRepo.On("FindByPlate", plate).Return(nil, errors.New("not found"))
@@ -150,7 +158,7 @@ func TestGetVehicleByPlate(t *testing.T) {
 func TestGetVehiclesByCustomerID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		mockVehicles := []dto.VehicleDTO{
 			{
@@ -177,7 +185,7 @@ func TestGetVehiclesByCustomerID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		mockRepo.On("FindByCustomerID", uint(999)).Return([]dto.VehicleDTO{}, nil)
 
@@ -192,7 +200,7 @@ func TestGetVehiclesByCustomerID(t *testing.T) {
 func TestCreateVehicle(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		vehicle := entities.Vehicle{
 			Plate: valueobject.ParsePlate("ABC1234"),
@@ -216,7 +224,7 @@ func TestCreateVehicle(t *testing.T) {
 
 	t.Run("error", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		vehicle := entities.Vehicle{
 			Plate: valueobject.ParsePlate("ABC1234"),
@@ -240,7 +248,7 @@ func TestCreateVehicle(t *testing.T) {
 
 	t.Run("invalid plate format", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		vehicle := entities.Vehicle{
 			Plate: valueobject.ParsePlate("ABC123"), // Placa inválida
@@ -259,7 +267,7 @@ func TestCreateVehicle(t *testing.T) {
 
 	t.Run("vehicle already exists", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		vehicle := entities.Vehicle{
 			Plate: valueobject.ParsePlate("ABC1234"),
@@ -285,12 +293,103 @@ func TestCreateVehicle(t *testing.T) {
 		assert.Equal(t, MessageVehicleAlreadyExists, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("plate written with dash and lower case", func(t *testing.T) {
+		mockRepo := new(mocks.MockVehicleRepository)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
+
+		vehicle := entities.Vehicle{
+			Plate: "abc-1c34",
+			Model: "Civic",
+			Brand: "Honda",
+			Year:  "2020",
+		}
+
+		expected := vehicle
+		expected.Plate = "ABC1C34"
+		mockRepo.On("FindByPlate", expected.Plate).Return(nil, nil)
+		mockRepo.On("Create", expected).Return(nil)
+
+		_, err := service.CreateVehicle(vehicle)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid VIN", func(t *testing.T) {
+		mockRepo := new(mocks.MockVehicleRepository)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
+
+		vehicle := entities.Vehicle{
+			Plate: valueobject.ParsePlate("ABC1234"),
+			Model: "Civic",
+			Brand: "Honda",
+			Year:  "2020",
+			VIN:   valueobject.ParseVIN("1HGCM82643A004352"), // dígito verificador incorreto
+		}
+
+		result, err := service.CreateVehicle(vehicle)
+
+		assert.Equal(t, ErrInvalidVIN, err)
+		assert.Equal(t, MessageInvalidVIN, result)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("brand and model written as in the catalog", func(t *testing.T) {
+		mockRepo := new(mocks.MockVehicleRepository)
+		catalog := new(mocks.MockVehicleCatalogRepository)
+		service := NewVehicleService(mockRepo, catalog)
+
+		vehicle := entities.Vehicle{
+			Plate: valueobject.ParsePlate("ABC1234"),
+			Model: "civic ",
+			Brand: "HONDA",
+			Year:  "2020",
+		}
+
+		catalog.On("FindModel", mock.Anything, "HONDA", "civic", 2020).
+			Return(&dto.VehicleModelDTO{ID: 1, Brand: "Honda", Model: "Civic", Year: 2020}, nil)
+		expected := vehicle
+		expected.Brand = "Honda"
+		expected.Model = "Civic"
+		mockRepo.On("FindByPlate", expected.Plate).Return(nil, nil)
+		mockRepo.On("Create", expected).Return(nil)
+
+		_, err := service.CreateVehicle(vehicle)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		catalog.AssertNotCalled(t, "Count", mock.Anything)
+	})
+
+	t.Run("not in the catalog", func(t *testing.T) {
+		mockRepo := new(mocks.MockVehicleRepository)
+		catalog := new(mocks.MockVehicleCatalogRepository)
+		service := NewVehicleService(mockRepo, catalog)
+
+		vehicle := entities.Vehicle{
+			Plate: valueobject.ParsePlate("ABC1234"),
+			Model: "Civic",
+			Brand: "Honda",
+			Year:  "1950",
+		}
+
+		catalog.On("FindModel", mock.Anything, "Honda", "Civic", 1950).Return(nil, nil)
+		catalog.On("Count", mock.Anything).Return(int64(1200), nil)
+
+		result, err := service.CreateVehicle(vehicle)
+
+		assert.Equal(t, ErrVehicleNotInCatalog, err)
+		assert.Equal(t, MessageVehicleNotInCatalog, result)
+		mockRepo.AssertNotCalled(t, "FindByPlate", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
 }
 
 func TestUpdateVehicle(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		vehicle := entities.Vehicle{
 			ID:    1,
@@ -309,6 +408,7 @@ func TestUpdateVehicle(t *testing.T) {
 		}
 
 		mockRepo.On("FindByID", vehicle.ID).Return(mockGet, nil)
+		mockRepo.On("FindByPlate", vehicle.Plate).Return(mockGet, nil)
 		mockRepo.On("Update", vehicle).Return(nil)
 
 		result, err := service.UpdateVehicle(vehicle)
@@ -320,7 +420,7 @@ func TestUpdateVehicle(t *testing.T) {
 
 	t.Run("error", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		vehicle := entities.Vehicle{
 			ID:    1,
@@ -339,6 +439,7 @@ func TestUpdateVehicle(t *testing.T) {
 		}
 
 		mockRepo.On("FindByID", vehicle.ID).Return(mockGet, nil)
+		mockRepo.On("FindByPlate", vehicle.Plate).Return(mockGet, nil)
 		mockRepo.On("Update", vehicle).Return(ErrorDatabase)
 
 		result, err := service.UpdateVehicle(vehicle)
@@ -350,7 +451,7 @@ func TestUpdateVehicle(t *testing.T) {
 
 	t.Run("vehicle not found", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		vehicle := entities.Vehicle{
 			ID:    1,
@@ -372,7 +473,7 @@ func TestUpdateVehicle(t *testing.T) {
 
 	t.Run("invalid plate format", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		vehicle := entities.Vehicle{
 			ID:    1,
@@ -389,12 +490,47 @@ func TestUpdateVehicle(t *testing.T) {
 		assert.Equal(t, MessageInvalidPlateFormat, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("keeps the VIN when the request has none", func(t *testing.T) {
+		mockRepo := new(mocks.MockVehicleRepository)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
+
+		vehicle := entities.Vehicle{ID: 1, Plate: valueobject.ParsePlate("ABC1234"), Model: "Gol", Brand: "Volkswagen", Year: "1997"}
+		mockGet := &dto.VehicleDTO{ID: 1, Plate: "ABC1234", Model: "Gol", Brand: "Volkswagen", Year: "1997", VIN: "9BWZZZ377VT004251"}
+
+		mockRepo.On("FindByID", vehicle.ID).Return(mockGet, nil)
+		mockRepo.On("FindByPlate", vehicle.Plate).Return(mockGet, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(updated entities.Vehicle) bool {
+			return updated.VIN == "9BWZZZ377VT004251"
+		})).Return(nil)
+
+		_, err := service.UpdateVehicle(vehicle)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("plate of another vehicle", func(t *testing.T) {
+		mockRepo := new(mocks.MockVehicleRepository)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
+
+		vehicle := entities.Vehicle{ID: 1, Plate: valueobject.ParsePlate("ABC1C34"), Model: "Civic", Brand: "Honda", Year: "2020"}
+
+		mockRepo.On("FindByID", vehicle.ID).Return(&dto.VehicleDTO{ID: 1, Plate: "XYZ9876"}, nil)
+		mockRepo.On("FindByPlate", vehicle.Plate).Return(&dto.VehicleDTO{ID: 2, Plate: "ABC1234"}, nil)
+
+		result, err := service.UpdateVehicle(vehicle)
+
+		assert.Equal(t, ErrVehicleAlreadyExists, err)
+		assert.Equal(t, MessageVehicleAlreadyExists, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
 }
 
 func TestUpdateVehiclePartial(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		existingVehicle := &dto.VehicleDTO{
 			ID:         1,
@@ -422,7 +558,7 @@ func TestUpdateVehiclePartial(t *testing.T) {
 
 	t.Run("vehicle not found", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		updates := map[string]interface{}{
 			"model": "Civic Updated",
@@ -440,7 +576,7 @@ func TestUpdateVehiclePartial(t *testing.T) {
 
 	t.Run("invalid plate format", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		existingVehicle := &dto.VehicleDTO{
 			ID:         1,
@@ -464,12 +600,57 @@ func TestUpdateVehiclePartial(t *testing.T) {
 		assert.Equal(t, MessageInvalidPlateFormat, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("owner change", func(t *testing.T) {
+		mockRepo := new(mocks.MockVehicleRepository)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
+
+		existingVehicle := &dto.VehicleDTO{
+			ID:         1,
+			Plate:      "ABC1234",
+			Model:      "Civic",
+			Brand:      "Honda",
+			Year:       "2020",
+			CustomerID: 1,
+		}
+
+		updates := map[string]interface{}{
+			"model":       "Civic Updated",
+			"customer_id": float64(2),
+		}
+
+		mockRepo.On("FindByID", uint(1)).Return(existingVehicle, nil)
+
+		_, err := service.UpdateVehiclePartial(1, updates)
+
+		assert.Equal(t, ErrVehicleOwnerChange, err)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("old plate of another vehicle", func(t *testing.T) {
+		mockRepo := new(mocks.MockVehicleRepository)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
+
+		existingVehicle := &dto.VehicleDTO{ID: 1, Plate: "XYZ9876", Model: "Civic", Brand: "Honda", Year: "2020", CustomerID: 1}
+		updates := map[string]interface{}{
+			"plate": "ABC1234",
+		}
+
+		mockRepo.On("FindByID", uint(1)).Return(existingVehicle, nil)
+		mockRepo.On("FindByPlate", valueobject.ParsePlate("ABC1234")).Return(&dto.VehicleDTO{ID: 2, Plate: "ABC1C34"}, nil)
+
+		result, err := service.UpdateVehiclePartial(1, updates)
+
+		assert.Equal(t, ErrVehicleAlreadyExists, err)
+		assert.Equal(t, MessageVehicleAlreadyExists, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
 }
 
 func TestDeleteVehicle(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		mockVehicle := &dto.VehicleDTO{
 			ID:         1,
@@ -491,7 +672,7 @@ func TestDeleteVehicle(t *testing.T) {
 
 	t.Run("invalid id", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		err := service.DeleteVehicle(0)
 
@@ -502,7 +683,7 @@ func TestDeleteVehicle(t *testing.T) {
 
 	t.Run("vehicle not found", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		mockRepo.On("FindByID", uint(1)).Return(nil, nil)
 
@@ -515,7 +696,7 @@ func TestDeleteVehicle(t *testing.T) {
 
 	t.Run("database error", func(t *testing.T) {
 		mockRepo := new(mocks.MockVehicleRepository)
-		service := NewVehicleService(mockRepo)
+		service := NewVehicleService(mockRepo, emptyVehicleCatalog())
 
 		mockVehicle := &dto.VehicleDTO{
 			ID:         1,
@@ -0,0 +1,414 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/repository/customers"
+	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/internal/domain/repository/webhook"
+	"mecanica_xpto/pkg/utils"
+)
+
+const (
+	// webhookBatchSize is how many due deliveries are sent on each run
+	webhookBatchSize = 50
+	// webhookRetryDelay is the wait before the first retry, doubled on each new failure
+	webhookRetryDelay = 30 * time.Second
+	// webhookMaxRetryDelay caps the wait between retries
+	webhookMaxRetryDelay = 6 * time.Hour
+)
+
+// WebhookEventTypes lists the events partners can subscribe to
+var WebhookEventTypes = []valueobject.DomainEventType{
+	valueobject.EventServiceOrderCreated,
+	valueobject.EventServiceOrderStatusChanged,
+	valueobject.EventPaymentReceived,
+}
+
+var (
+	ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL           = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidWebhookEventType     = errors.New("invalid webhook event type")
+	ErrWebhookSubscriptionInactive = errors.New("webhook subscription is inactive or was removed")
+)
+
+type IWebhookUseCase interface {
+	CreateSubscription(ctx context.Context, subscription entities.WebhookSubscription) (*entities.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uint) (*entities.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]entities.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription entities.WebhookSubscription) (*entities.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+	HandleDomainEvent(ctx context.Context, event entities.DomainEvent) error
+	ProcessPending(ctx context.Context) (int, error)
+	ListDeliveries(ctx context.Context, subscriptionID uint) ([]entities.WebhookDelivery, error)
+	Redeliver(ctx context.Context, subscriptionID, deliveryID uint) (*entities.WebhookDelivery, error)
+}
+
+type WebhookUseCase struct {
+	repo             webhook.IWebhookRepository
+	serviceOrderRepo serviceorder.IServiceOrderRepository
+	customerRepo     customers.ICustomerRepository
+	client           gateway.WebhookClient
+	maxAttempts      int
+	now              func() time.Time
+}
+
+var _ IWebhookUseCase = (*WebhookUseCase)(nil)
+
+func NewWebhookUseCase(repo webhook.IWebhookRepository, serviceOrderRepo serviceorder.IServiceOrderRepository, customerRepo customers.ICustomerRepository, client gateway.WebhookClient, maxAttempts int) *WebhookUseCase {
+	return &WebhookUseCase{
+		repo:             repo,
+		serviceOrderRepo: serviceOrderRepo,
+		customerRepo:     customerRepo,
+		client:           client,
+		maxAttempts:      maxAttempts,
+		now:              time.Now,
+	}
+}
+
+// CreateSubscription registers an endpoint. A secret is generated when none is informed and is only
+// returned here, so the subscriber must keep it to check the signatures.
+func (u *WebhookUseCase) CreateSubscription(ctx context.Context, subscription entities.WebhookSubscription) (*entities.WebhookSubscription, error) {
+	subscriptionDto, err := u.validSubscription(subscription)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkCustomer(subscription.CustomerID); err != nil {
+		return nil, err
+	}
+
+	secret := strings.TrimSpace(subscription.Secret)
+	if secret == "" {
+		if secret, err = utils.NewWebhookSecret(); err != nil {
+			log.Error().Msgf("Error generating webhook secret: %v", err)
+			return nil, err
+		}
+	}
+	subscriptionDto.Secret = secret
+	subscriptionDto.Active = true
+
+	if err := u.repo.CreateSubscription(ctx, subscriptionDto); err != nil {
+		log.Error().Msgf("Error creating webhook subscription: %v", err)
+		return nil, err
+	}
+
+	result := subscriptionDto.ToDomain()
+	result.Secret = secret
+	return &result, nil
+}
+
+func (u *WebhookUseCase) GetSubscription(ctx context.Context, id uint) (*entities.WebhookSubscription, error) {
+	subscriptionDto, err := u.findSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := subscriptionDto.ToDomain()
+	return &result, nil
+}
+
+func (u *WebhookUseCase) ListSubscriptions(ctx context.Context) ([]entities.WebhookSubscription, error) {
+	subscriptions, err := u.repo.ListSubscriptions(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing webhook subscriptions: %v", err)
+		return nil, err
+	}
+	result := make([]entities.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, subscription.ToDomain())
+	}
+	return result, nil
+}
+
+// UpdateSubscription changes the url, event types, customer filter and activation of a
+// subscription. The secret cannot be changed; a new subscription must be created instead.
+func (u *WebhookUseCase) UpdateSubscription(ctx context.Context, subscription entities.WebhookSubscription) (*entities.WebhookSubscription, error) {
+	existing, err := u.findSubscription(ctx, subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+	subscriptionDto, err := u.validSubscription(subscription)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkCustomer(subscription.CustomerID); err != nil {
+		return nil, err
+	}
+
+	subscriptionDto.ID = existing.ID
+	subscriptionDto.Active = subscription.Active
+	subscriptionDto.CreatedAt = existing.CreatedAt
+	if err := u.repo.UpdateSubscription(ctx, subscriptionDto); err != nil {
+		log.Error().Msgf("Error updating webhook subscription %d: %v", subscription.ID, err)
+		return nil, err
+	}
+	result := subscriptionDto.ToDomain()
+	return &result, nil
+}
+
+// DeleteSubscription removes a subscription. Its pending deliveries are dropped on the next run.
+func (u *WebhookUseCase) DeleteSubscription(ctx context.Context, id uint) error {
+	if _, err := u.findSubscription(ctx, id); err != nil {
+		return err
+	}
+	if err := u.repo.DeleteSubscription(ctx, id); err != nil {
+		log.Error().Msgf("Error deleting webhook subscription %d: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// HandleDomainEvent queues a delivery of a service order or payment event to every active
+// subscription that accepts its type and customer. Deliveries are unique per event and
+// subscription, so an event handled twice is only delivered once.
+func (u *WebhookUseCase) HandleDomainEvent(ctx context.Context, event entities.DomainEvent) error {
+	if !isWebhookEventType(event.Type) || event.ServiceOrderID == 0 {
+		return nil
+	}
+
+	subscriptions, err := u.repo.ListActiveSubscriptions(ctx)
+	if err != nil {
+		log.Error().Msgf("Error listing webhook subscriptions for event %d: %v", event.ID, err)
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	serviceOrderDto, err := u.serviceOrderRepo.GetByID(event.ServiceOrderID)
+	if err != nil {
+		log.Error().Msgf("Error finding service order %d for event %d: %v", event.ServiceOrderID, event.ID, err)
+		return err
+	}
+	if serviceOrderDto == nil {
+		log.Error().Msgf("Service order %d of event %d not found, skipping webhooks", event.ServiceOrderID, event.ID)
+		return nil
+	}
+
+	payload, err := json.Marshal(entities.WebhookPayload{
+		EventID:        event.ID,
+		Type:           event.Type,
+		OccurredAt:     event.OccurredAt,
+		ServiceOrderID: event.ServiceOrderID,
+		CustomerID:     serviceOrderDto.CustomerID,
+		VehicleID:      serviceOrderDto.VehicleID,
+		Data:           event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := u.now()
+	var errs []error
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !subscription.Accepts(event.Type) {
+			continue
+		}
+		if subscription.CustomerID != nil && *subscription.CustomerID != serviceOrderDto.CustomerID {
+			continue
+		}
+		delivery := dto.WebhookDeliveryDTO{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type.String(),
+			Payload:        string(payload),
+			Status:         valueobject.WebhookPending.String(),
+			NextAttemptAt:  &now,
+		}
+		if _, err := u.repo.CreateDelivery(ctx, &delivery); err != nil {
+			log.Error().Msgf("Error queueing event %d for webhook subscription %d: %v", event.ID, subscription.ID, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ProcessPending sends the deliveries that are due. Any 2xx answer counts as delivered; otherwise
+// the delivery is retried with an exponential backoff until maxAttempts is reached, when it is
+// marked as failed. It returns how many were delivered.
+func (u *WebhookUseCase) ProcessPending(ctx context.Context) (int, error) {
+	now := u.now()
+	deliveries, err := u.repo.ListDueDeliveries(ctx, now, webhookBatchSize)
+	if err != nil {
+		log.Error().Msgf("Error listing pending webhook deliveries: %v", err)
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if delivery.Subscription.ID == 0 || !delivery.Subscription.Active {
+			delivery.Status = valueobject.WebhookFailed.String()
+			delivery.LastError = ErrWebhookSubscriptionInactive.Error()
+			delivery.NextAttemptAt = nil
+		} else {
+			delivery.Attempts++
+			status, err := u.send(ctx, delivery, now)
+			delivery.ResponseStatus = status
+			if err == nil && (status < 200 || status > 299) {
+				err = fmt.Errorf("subscriber answered with status %d", status)
+			}
+			if err != nil {
+				delivery.LastError = err.Error()
+				if delivery.Attempts >= u.maxAttempts {
+					delivery.Status = valueobject.WebhookFailed.String()
+					delivery.NextAttemptAt = nil
+				} else {
+					next := now.Add(webhookBackoff(delivery.Attempts))
+					delivery.NextAttemptAt = &next
+				}
+				log.Error().Msgf("Error delivering webhook %d (attempt %d): %v", delivery.ID, delivery.Attempts, err)
+			} else {
+				delivery.Status = valueobject.WebhookDelivered.String()
+				delivery.LastError = ""
+				delivery.NextAttemptAt = nil
+				delivery.DeliveredAt = &now
+				delivered++
+			}
+		}
+		if err := u.repo.UpdateDelivery(ctx, delivery); err != nil {
+			log.Error().Msgf("Error recording webhook delivery %d: %v", delivery.ID, err)
+		}
+	}
+	return delivered, nil
+}
+
+// ListDeliveries lists the delivery history of a subscription, newest first
+func (u *WebhookUseCase) ListDeliveries(ctx context.Context, subscriptionID uint) ([]entities.WebhookDelivery, error) {
+	if _, err := u.findSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	deliveries, err := u.repo.ListDeliveries(ctx, subscriptionID)
+	if err != nil {
+		log.Error().Msgf("Error listing deliveries of webhook subscription %d: %v", subscriptionID, err)
+		return nil, err
+	}
+	result := make([]entities.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, delivery.ToDomain())
+	}
+	return result, nil
+}
+
+// Redeliver queues a delivery to be sent again on the next run, with a fresh set of attempts,
+// whatever its current status
+func (u *WebhookUseCase) Redeliver(ctx context.Context, subscriptionID, deliveryID uint) (*entities.WebhookDelivery, error) {
+	if _, err := u.findSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	delivery, err := u.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		log.Error().Msgf("Error finding webhook delivery %d: %v", deliveryID, err)
+		return nil, err
+	}
+	if delivery == nil || delivery.SubscriptionID != subscriptionID {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	now := u.now()
+	delivery.Status = valueobject.WebhookPending.String()
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	delivery.NextAttemptAt = &now
+	if err := u.repo.UpdateDelivery(ctx, delivery); err != nil {
+		log.Error().Msgf("Error queueing redelivery of webhook %d: %v", deliveryID, err)
+		return nil, err
+	}
+	result := delivery.ToDomain()
+	return &result, nil
+}
+
+func (u *WebhookUseCase) send(ctx context.Context, delivery *dto.WebhookDeliveryDTO, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	return u.client.Post(ctx, delivery.Subscription.URL, map[string]string{
+		"Content-Type":        "application/json",
+		"X-Webhook-Event":     delivery.EventType,
+		"X-Webhook-Delivery":  strconv.FormatUint(uint64(delivery.ID), 10),
+		"X-Webhook-Timestamp": strconv.FormatInt(now.Unix(), 10),
+		"X-Webhook-Signature": utils.SignWebhook(delivery.Subscription.Secret, now, body),
+	}, body)
+}
+
+func (u *WebhookUseCase) findSubscription(ctx context.Context, id uint) (*dto.WebhookSubscriptionDTO, error) {
+	subscription, err := u.repo.GetSubscription(ctx, id)
+	if err != nil {
+		log.Error().Msgf("Error finding webhook subscription %d: %v", id, err)
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, ErrWebhookSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+// validSubscription checks the url and event types and converts them to be stored
+func (u *WebhookUseCase) validSubscription(subscription entities.WebhookSubscription) (*dto.WebhookSubscriptionDTO, error) {
+	target, err := url.Parse(strings.TrimSpace(subscription.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		if !isWebhookEventType(eventType) {
+			return nil, ErrInvalidWebhookEventType
+		}
+		eventTypes = append(eventTypes, eventType.String())
+	}
+
+	return &dto.WebhookSubscriptionDTO{
+		URL:        target.String(),
+		EventTypes: strings.Join(eventTypes, ","),
+		CustomerID: subscription.CustomerID,
+	}, nil
+}
+
+func (u *WebhookUseCase) checkCustomer(customerID *uint) error {
+	if customerID == nil {
+		return nil
+	}
+	customer, err := u.customerRepo.GetByID(*customerID)
+	if err != nil {
+		log.Error().Msgf("Error finding customer %d: %v", *customerID, err)
+		return err
+	}
+	if customer == nil {
+		return ErrCustomerNotFound
+	}
+	return nil
+}
+
+func isWebhookEventType(eventType valueobject.DomainEventType) bool {
+	for _, accepted := range WebhookEventTypes {
+		if eventType == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookBackoff is the wait after a failed attempt: 30s, 1m, 2m, ... up to webhookMaxRetryDelay
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxRetryDelay {
+			return webhookMaxRetryDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,331 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase/mocks"
+	"mecanica_xpto/pkg/utils"
+)
+
+// stubWebhookClient answers every post with status, or fails with err when it is set
+type stubWebhookClient struct {
+	status  int
+	err     error
+	url     string
+	headers map[string]string
+	body    []byte
+	calls   int
+}
+
+func (s *stubWebhookClient) Post(_ context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	s.calls++
+	s.url, s.headers, s.body = url, headers, body
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.status, nil
+}
+
+var webhookTestNow = time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+
+func newWebhookTestUseCase(client *stubWebhookClient) (*WebhookUseCase, *mocks.MockWebhookRepository, *MockServiceOrderRepository, *MockCustomerRepository) {
+	repo := new(mocks.MockWebhookRepository)
+	serviceOrderRepo := new(MockServiceOrderRepository)
+	customerRepo := new(MockCustomerRepository)
+	uc := NewWebhookUseCase(repo, serviceOrderRepo, customerRepo, client, 3)
+	uc.now = func() time.Time { return webhookTestNow }
+	return uc, repo, serviceOrderRepo, customerRepo
+}
+
+func uintPtr(v uint) *uint { return &v }
+
+func TestWebhookUseCase_CreateSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("generates a secret and returns it only on creation", func(t *testing.T) {
+		uc, repo, _, customerRepo := newWebhookTestUseCase(&stubWebhookClient{})
+		customerRepo.On("GetByID", uint(4)).Return(&dto.CustomerDTO{ID: 4}, nil)
+		var stored *dto.WebhookSubscriptionDTO
+		repo.On("CreateSubscription", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*dto.WebhookSubscriptionDTO)
+			stored.ID = 1
+		}).Return(nil)
+
+		result, err := uc.CreateSubscription(ctx, entities.WebhookSubscription{
+			URL:        "https://fleet.example.com/hooks",
+			EventTypes: []valueobject.DomainEventType{valueobject.EventServiceOrderStatusChanged, valueobject.EventPaymentReceived},
+			CustomerID: uintPtr(4),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), result.ID)
+		assert.True(t, result.Active)
+		assert.NotEmpty(t, result.Secret)
+		assert.Equal(t, stored.Secret, result.Secret)
+		assert.Equal(t, "SERVICE_ORDER_STATUS_CHANGED,PAYMENT_RECEIVED", stored.EventTypes)
+		assert.Empty(t, stored.ToDomain().Secret)
+	})
+
+	t.Run("keeps the informed secret", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{})
+		repo.On("CreateSubscription", ctx, mock.Anything).Return(nil)
+
+		result, err := uc.CreateSubscription(ctx, entities.WebhookSubscription{URL: "http://partner.example.com/hook", Secret: "segredo"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "segredo", result.Secret)
+		assert.Empty(t, result.EventTypes)
+	})
+
+	t.Run("rejects invalid urls", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{})
+
+		for _, url := range []string{"", "ftp://example.com", "/hooks", "https://"} {
+			_, err := uc.CreateSubscription(ctx, entities.WebhookSubscription{URL: url})
+			assert.ErrorIs(t, err, ErrInvalidWebhookURL, url)
+		}
+		repo.AssertNotCalled(t, "CreateSubscription", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects events that are not sent to webhooks", func(t *testing.T) {
+		uc, _, _, _ := newWebhookTestUseCase(&stubWebhookClient{})
+
+		_, err := uc.CreateSubscription(ctx, entities.WebhookSubscription{
+			URL:        "https://fleet.example.com/hooks",
+			EventTypes: []valueobject.DomainEventType{valueobject.EventStockReserved},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidWebhookEventType)
+	})
+
+	t.Run("rejects an unknown customer", func(t *testing.T) {
+		uc, _, _, customerRepo := newWebhookTestUseCase(&stubWebhookClient{})
+		customerRepo.On("GetByID", uint(9)).Return(nil, nil)
+
+		_, err := uc.CreateSubscription(ctx, entities.WebhookSubscription{URL: "https://fleet.example.com/hooks", CustomerID: uintPtr(9)})
+
+		assert.ErrorIs(t, err, ErrCustomerNotFound)
+	})
+}
+
+func TestWebhookUseCase_UpdateSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("updates the subscription keeping its secret", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{})
+		repo.On("GetSubscription", ctx, uint(1)).Return(&dto.WebhookSubscriptionDTO{ID: 1, URL: "https://old.example.com", Secret: "segredo", Active: true}, nil)
+		repo.On("UpdateSubscription", ctx, mock.MatchedBy(func(s *dto.WebhookSubscriptionDTO) bool {
+			return s.ID == 1 && s.URL == "https://new.example.com" && !s.Active && s.Secret == ""
+		})).Return(nil)
+
+		result, err := uc.UpdateSubscription(ctx, entities.WebhookSubscription{ID: 1, URL: "https://new.example.com", Active: false})
+
+		require.NoError(t, err)
+		assert.False(t, result.Active)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("returns not found", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{})
+		repo.On("GetSubscription", ctx, uint(2)).Return(nil, nil)
+
+		_, err := uc.UpdateSubscription(ctx, entities.WebhookSubscription{ID: 2, URL: "https://new.example.com"})
+
+		assert.ErrorIs(t, err, ErrWebhookSubscriptionNotFound)
+	})
+}
+
+func TestWebhookUseCase_HandleDomainEvent(t *testing.T) {
+	ctx := context.Background()
+	event := entities.DomainEvent{
+		ID:             30,
+		Type:           valueobject.EventServiceOrderStatusChanged,
+		ServiceOrderID: 7,
+		Payload:        map[string]interface{}{"from": "RECEBIDA", "to": "EM DIAGNÓSTICO"},
+		OccurredAt:     webhookTestNow.Add(-time.Minute),
+	}
+
+	t.Run("queues the event for the matching subscriptions", func(t *testing.T) {
+		uc, repo, serviceOrderRepo, _ := newWebhookTestUseCase(&stubWebhookClient{})
+		repo.On("ListActiveSubscriptions", ctx).Return([]dto.WebhookSubscriptionDTO{
+			{ID: 1},
+			{ID: 2, EventTypes: "PAYMENT_RECEIVED"},
+			{ID: 3, CustomerID: uintPtr(1), EventTypes: "SERVICE_ORDER_STATUS_CHANGED"},
+			{ID: 4, CustomerID: uintPtr(99)},
+		}, nil)
+		serviceOrderRepo.On("GetByID", uint(7)).Return(&dto.ServiceOrderDTO{ID: 7, CustomerID: 1, VehicleID: 2}, nil)
+		var queued []dto.WebhookDeliveryDTO
+		repo.On("CreateDelivery", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			queued = append(queued, *args.Get(1).(*dto.WebhookDeliveryDTO))
+		}).Return(true, nil)
+
+		require.NoError(t, uc.HandleDomainEvent(ctx, event))
+
+		require.Len(t, queued, 2)
+		assert.Equal(t, uint(1), queued[0].SubscriptionID)
+		assert.Equal(t, uint(3), queued[1].SubscriptionID)
+		assert.Equal(t, valueobject.WebhookPending.String(), queued[0].Status)
+		assert.Equal(t, webhookTestNow, *queued[0].NextAttemptAt)
+
+		var payload entities.WebhookPayload
+		require.NoError(t, json.Unmarshal([]byte(queued[0].Payload), &payload))
+		assert.Equal(t, uint(30), payload.EventID)
+		assert.Equal(t, valueobject.EventServiceOrderStatusChanged, payload.Type)
+		assert.Equal(t, uint(1), payload.CustomerID)
+		assert.Equal(t, uint(2), payload.VehicleID)
+		assert.Equal(t, "EM DIAGNÓSTICO", payload.Data["to"])
+	})
+
+	t.Run("ignores events that are not sent to webhooks", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{})
+
+		err := uc.HandleDomainEvent(ctx, entities.DomainEvent{ID: 31, Type: valueobject.EventStockReserved, ServiceOrderID: 7})
+
+		require.NoError(t, err)
+		repo.AssertNotCalled(t, "ListActiveSubscriptions", mock.Anything)
+	})
+
+	t.Run("returns the error so the event is retried", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{})
+		repo.On("ListActiveSubscriptions", ctx).Return(nil, errors.New("db down"))
+
+		assert.Error(t, uc.HandleDomainEvent(ctx, event))
+	})
+}
+
+func TestWebhookUseCase_ProcessPending(t *testing.T) {
+	ctx := context.Background()
+	due := func() []dto.WebhookDeliveryDTO {
+		return []dto.WebhookDeliveryDTO{{
+			ID:             5,
+			SubscriptionID: 1,
+			Subscription:   dto.WebhookSubscriptionDTO{ID: 1, URL: "https://fleet.example.com/hooks", Secret: "segredo", Active: true},
+			EventID:        30,
+			EventType:      "PAYMENT_RECEIVED",
+			Payload:        `{"event_id":30}`,
+			Status:         valueobject.WebhookPending.String(),
+			Attempts:       1,
+		}}
+	}
+
+	t.Run("sends the signed payload and marks it delivered", func(t *testing.T) {
+		client := &stubWebhookClient{status: http.StatusOK}
+		uc, repo, _, _ := newWebhookTestUseCase(client)
+		repo.On("ListDueDeliveries", ctx, webhookTestNow, webhookBatchSize).Return(due(), nil)
+		repo.On("UpdateDelivery", ctx, mock.MatchedBy(func(d *dto.WebhookDeliveryDTO) bool {
+			return d.Status == valueobject.WebhookDelivered.String() && d.Attempts == 2 &&
+				d.ResponseStatus == http.StatusOK && d.DeliveredAt != nil && d.NextAttemptAt == nil
+		})).Return(nil)
+
+		delivered, err := uc.ProcessPending(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+		assert.Equal(t, "https://fleet.example.com/hooks", client.url)
+		assert.Equal(t, `{"event_id":30}`, string(client.body))
+		assert.Equal(t, "PAYMENT_RECEIVED", client.headers["X-Webhook-Event"])
+		assert.Equal(t, "5", client.headers["X-Webhook-Delivery"])
+		assert.Equal(t, utils.SignWebhook("segredo", webhookTestNow, client.body), client.headers["X-Webhook-Signature"])
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("retries with exponential backoff on an error status", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{status: http.StatusServiceUnavailable})
+		repo.On("ListDueDeliveries", ctx, webhookTestNow, webhookBatchSize).Return(due(), nil)
+		repo.On("UpdateDelivery", ctx, mock.MatchedBy(func(d *dto.WebhookDeliveryDTO) bool {
+			return d.Status == valueobject.WebhookPending.String() && d.Attempts == 2 &&
+				d.ResponseStatus == http.StatusServiceUnavailable &&
+				d.NextAttemptAt.Equal(webhookTestNow.Add(time.Minute)) && d.LastError != ""
+		})).Return(nil)
+
+		delivered, err := uc.ProcessPending(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("marks it failed after the last attempt", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{err: errors.New("connection refused")})
+		deliveries := due()
+		deliveries[0].Attempts = 2
+		repo.On("ListDueDeliveries", ctx, webhookTestNow, webhookBatchSize).Return(deliveries, nil)
+		repo.On("UpdateDelivery", ctx, mock.MatchedBy(func(d *dto.WebhookDeliveryDTO) bool {
+			return d.Status == valueobject.WebhookFailed.String() && d.Attempts == 3 &&
+				d.NextAttemptAt == nil && d.LastError == "connection refused"
+		})).Return(nil)
+
+		_, err := uc.ProcessPending(ctx)
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("drops deliveries of inactive subscriptions without sending", func(t *testing.T) {
+		client := &stubWebhookClient{status: http.StatusOK}
+		uc, repo, _, _ := newWebhookTestUseCase(client)
+		deliveries := due()
+		deliveries[0].Subscription.Active = false
+		repo.On("ListDueDeliveries", ctx, webhookTestNow, webhookBatchSize).Return(deliveries, nil)
+		repo.On("UpdateDelivery", ctx, mock.MatchedBy(func(d *dto.WebhookDeliveryDTO) bool {
+			return d.Status == valueobject.WebhookFailed.String()
+		})).Return(nil)
+
+		_, err := uc.ProcessPending(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, client.calls)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestWebhookUseCase_Redeliver(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queues a failed delivery again", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{})
+		repo.On("GetSubscription", ctx, uint(1)).Return(&dto.WebhookSubscriptionDTO{ID: 1}, nil)
+		repo.On("GetDelivery", ctx, uint(5)).Return(&dto.WebhookDeliveryDTO{
+			ID: 5, SubscriptionID: 1, Status: valueobject.WebhookFailed.String(), Attempts: 3, LastError: "timeout",
+		}, nil)
+		repo.On("UpdateDelivery", ctx, mock.MatchedBy(func(d *dto.WebhookDeliveryDTO) bool {
+			return d.Status == valueobject.WebhookPending.String() && d.Attempts == 0 &&
+				d.LastError == "" && d.NextAttemptAt.Equal(webhookTestNow)
+		})).Return(nil)
+
+		result, err := uc.Redeliver(ctx, 1, 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, valueobject.WebhookPending, result.Status)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("does not find deliveries of another subscription", func(t *testing.T) {
+		uc, repo, _, _ := newWebhookTestUseCase(&stubWebhookClient{})
+		repo.On("GetSubscription", ctx, uint(1)).Return(&dto.WebhookSubscriptionDTO{ID: 1}, nil)
+		repo.On("GetDelivery", ctx, uint(5)).Return(&dto.WebhookDeliveryDTO{ID: 5, SubscriptionID: 2}, nil)
+
+		_, err := uc.Redeliver(ctx, 1, 5)
+
+		assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+	})
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookBackoff(1))
+	assert.Equal(t, time.Minute, webhookBackoff(2))
+	assert.Equal(t, 4*time.Minute, webhookBackoff(4))
+	assert.Equal(t, webhookMaxRetryDelay, webhookBackoff(20))
+}
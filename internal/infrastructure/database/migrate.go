@@ -3,6 +3,9 @@ package database
 import (
 	"fmt"
 	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/valueobject"
+
+	"gorm.io/gorm"
 )
 
 func Migrate() {
@@ -19,13 +22,78 @@ func Migrate() {
 		&dto.AdditionalRepairDTO{},
 		&dto.PartsSupplyServiceOrderDTO{},
 		&dto.AdditionalRepairStatusDTO{},
+		&dto.PartsSupplyAdditionalRepairDTO{},
+		&dto.ServiceAdditionalRepairDTO{},
+		&dto.AdditionalRepairHistoryDTO{},
 		&dto.UserTypeDTO{},
 		&dto.ServiceServiceOrderDTO{},
 		&dto.PaymentDTO{},
+		&dto.CouponDTO{},
+		&dto.PriceAgreementDTO{},
+		&dto.TaxRuleDTO{},
+		&dto.ServiceOrderTaxDTO{},
+		&dto.InvoiceDTO{},
+		&dto.InvoiceItemDTO{},
+		&dto.CashClosingDTO{},
+		&dto.CashClosingLineDTO{},
+		&dto.ApprovalLinkDTO{},
+		&dto.NotificationDeliveryDTO{},
+		&dto.OutboxEventDTO{},
+		&dto.WebhookSubscriptionDTO{},
+		&dto.WebhookDeliveryDTO{},
+		&dto.MechanicDTO{},
+		&dto.MechanicAssignmentDTO{},
+		&dto.LabourEntryDTO{},
+		&dto.StaffDTO{},
+		&dto.BayDTO{},
+		&dto.AppointmentDTO{},
+		&dto.CheckInDTO{},
+		&dto.CheckInItemDTO{},
+		&dto.CheckInPhotoDTO{},
+		&dto.AttachmentDTO{},
+		&dto.MaintenancePlanDTO{},
+		&dto.MaintenanceReminderDTO{},
+		&dto.VehicleOwnershipDTO{},
+		&dto.VehicleModelDTO{},
+		&dto.CorporateAccountDTO{},
+		&dto.CorporateContactDTO{},
+		&dto.CorporateStatementDTO{},
+		&dto.CorporateStatementItemDTO{},
+		&dto.CustomerMergeDTO{},
 	)
 	if err != nil {
 		panic("Failed to migrate database: " + err.Error())
 	}
+	uniqueCustomerDocuments(db)
+	customerUserTypes(db)
 
 	fmt.Println("Database migrated successfully")
 }
+
+// customerUserTypes gives the customer user type to the users of the customers registered while
+// they were still created as admins, who would otherwise be granted every permission.
+func customerUserTypes(db *gorm.DB) {
+	if err := demoteCustomerAdmins(db).Error; err != nil {
+		panic("Failed to update the user type of customers: " + err.Error())
+	}
+}
+
+func demoteCustomerAdmins(db *gorm.DB) *gorm.DB {
+	return db.Model(&dto.UserDTO{}).
+		Where("user_type = ? AND id IN (?)", valueobject.Admin, db.Model(&dto.CustomerDTO{}).Select("user_id")).
+		Update("user_type", valueobject.Customer)
+}
+
+// uniqueCustomerDocuments removes the mask of the documents typed before they were normalised and
+// makes them unique. While customers registered twice are not merged the index cannot be created,
+// so the migration goes on and warns about it.
+func uniqueCustomerDocuments(db *gorm.DB) {
+	err := db.Exec("UPDATE tb_customer SET cpf_cnpj = regexp_replace(cpf_cnpj, '[^0-9]', '', 'g') WHERE cpf_cnpj ~ '[^0-9]'").Error
+	if err != nil {
+		panic("Failed to normalise customer documents: " + err.Error())
+	}
+	err = db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_customer_cpf_cnpj ON tb_customer (cpf_cnpj)").Error
+	if err != nil {
+		fmt.Println("Customer documents are not unique yet, merge the duplicates listed in GET /customers/duplicates: " + err.Error())
+	}
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestDemoteCustomerAdmins(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+
+	result := demoteCustomerAdmins(db)
+	require.NoError(t, result.Error)
+	stmt := result.Statement
+
+	assert.Equal(t,
+		`UPDATE "user_dtos" SET "user_type"=$1,"updated_at"=$2 WHERE (user_type = $3 AND id IN (SELECT "user_id" FROM "tb_customer")) AND "user_dtos"."deleted_at" IS NULL`,
+		stmt.SQL.String())
+	require.Len(t, stmt.Vars, 3)
+	assert.EqualValues(t, "customer", stmt.Vars[0])
+	assert.EqualValues(t, "admin", stmt.Vars[2])
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"mecanica_xpto/internal/domain/model/dto"
+	"mecanica_xpto/internal/domain/model/valueobject"
 )
 
 const (
@@ -17,30 +18,56 @@ func Seed() {
 	var countUsers int64
 	db.Model(&dto.UserDTO{}).Count(&countUsers)
 	if countUsers == 0 {
-		users := []dto.UserDTO{
-			{
-				Email:    "admin@xpto.com",
-				Password: defaultPassword,
-				UserType: defaultUserType,
-			},
+		admin := dto.UserDTO{
+			Email:    "admin@xpto.com",
+			Password: defaultPassword,
+			UserType: defaultUserType,
+		}
+		staff := []dto.StaffDTO{
 			{
-				Email:    "joao@xpto.com",
-				Password: defaultPassword,
-				UserType: defaultUserType,
+				User: &dto.UserDTO{
+					Email:    "joao@xpto.com",
+					Password: defaultPassword,
+					UserType: valueobject.Manager,
+				},
+				FullName: "João",
 			},
 			{
-				Email:    "joana@xpto.com",
-				Password: defaultPassword,
-				UserType: defaultUserType,
+				User: &dto.UserDTO{
+					Email:    "joana@xpto.com",
+					Password: defaultPassword,
+					UserType: valueobject.Receptionist,
+				},
+				FullName: "Joana",
 			},
 		}
 
-		if err := db.Create(&users).Error; err != nil {
+		if err := db.Create(&admin).Error; err != nil {
 			fmt.Println("Erro ao criar usuários:", err)
 			return
 		}
+		if err := db.Create(&staff).Error; err != nil {
+			fmt.Println("Erro ao criar funcionários:", err)
+			return
+		}
 		fmt.Println("Seeded users successfully")
 	} else {
 		fmt.Println("Users already seeded")
 	}
+
+	// Seed additional_repair_status_dtos
+	arStatuses := []valueobject.AdditionalRepairStatus{
+		valueobject.StatusARAberta,
+		valueobject.StatusARAguardandoAprovacao,
+		valueobject.StatusAAprovada,
+		valueobject.StatusARRejeitada,
+	}
+	for _, status := range arStatuses {
+		arStatus := dto.AdditionalRepairStatusDTO{Description: status.String()}
+		if err := db.Where(&arStatus).FirstOrCreate(&arStatus).Error; err != nil {
+			fmt.Println("Erro ao criar status de reparo adicional:", err)
+			return
+		}
+	}
+	fmt.Println("Seeded additional repair statuses successfully")
 }
@@ -0,0 +1,346 @@
+// Package fiscal implements the gateways used to issue electronic invoices:
+// the XML layouts of NFS-e and NF-e, the XMLDSig signer and the providers invoices are submitted to.
+package fiscal
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+const (
+	nfseNamespace = "http://www.abrasf.org.br/nfse.xsd"
+	nfeNamespace  = "http://www.portalfiscal.inf.br/nfe"
+	nfeVersion    = "4.00"
+	nfeModel      = "55"
+)
+
+var ErrUnsupportedInvoiceType = errors.New("unsupported invoice type")
+
+// XMLEncoder builds the NFS-e in the ABRASF national layout and the NF-e in the SEFAZ 4.00 layout.
+// Only the groups required by a workshop selling services and parts to final consumers are filled.
+type XMLEncoder struct {
+	issuer entities.InvoiceIssuer
+	now    func() time.Time
+}
+
+var _ gateway.InvoiceEncoder = (*XMLEncoder)(nil)
+
+func NewXMLEncoder(issuer entities.InvoiceIssuer) *XMLEncoder {
+	return &XMLEncoder{issuer: issuer, now: time.Now}
+}
+
+func (e *XMLEncoder) Encode(invoice entities.Invoice) ([]byte, error) {
+	var document any
+	switch invoice.Type {
+	case valueobject.InvoiceNFSe:
+		document = e.nfse(invoice)
+	case valueobject.InvoiceNFe:
+		document = e.nfe(invoice)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedInvoiceType, invoice.Type)
+	}
+
+	body, err := xml.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+type nfseEnvelope struct {
+	XMLName xml.Name `xml:"GerarNfseEnvio"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Rps     nfseRps  `xml:"Rps"`
+}
+
+type nfseRps struct {
+	Declaration nfseDeclaration `xml:"InfDeclaracaoPrestacaoServico"`
+}
+
+type nfseDeclaration struct {
+	ID             string       `xml:"Id,attr"`
+	Identification nfseRpsID    `xml:"Rps>IdentificacaoRps"`
+	IssueDate      string       `xml:"Rps>DataEmissao"`
+	RpsStatus      int          `xml:"Rps>Status"`
+	Competence     string       `xml:"Competencia"`
+	Service        nfseService  `xml:"Servico"`
+	Provider       nfseProvider `xml:"Prestador"`
+	Taker          nfseTaker    `xml:"TomadorServico"`
+}
+
+type nfseRpsID struct {
+	Number uint   `xml:"Numero"`
+	Series string `xml:"Serie"`
+	Type   int    `xml:"Tipo"`
+}
+
+type nfseService struct {
+	ServicesValue string `xml:"Valores>ValorServicos"`
+	ISSValue      string `xml:"Valores>ValorIss"`
+	Rate          string `xml:"Valores>Aliquota"`
+	ISSWithheld   int    `xml:"IssRetido"`
+	Description   string `xml:"Discriminacao"`
+	CityCode      string `xml:"CodigoMunicipio"`
+}
+
+type nfseProvider struct {
+	CNPJ                  string `xml:"CpfCnpj>Cnpj"`
+	MunicipalRegistration string `xml:"InscricaoMunicipal,omitempty"`
+}
+
+type nfseTaker struct {
+	CPF  string `xml:"IdentificacaoTomador>CpfCnpj>Cpf,omitempty"`
+	CNPJ string `xml:"IdentificacaoTomador>CpfCnpj>Cnpj,omitempty"`
+	Name string `xml:"RazaoSocial"`
+}
+
+func (e *XMLEncoder) nfse(invoice entities.Invoice) nfseEnvelope {
+	issuedAt := e.now()
+
+	descriptions := make([]string, 0, len(invoice.Items))
+	for _, item := range invoice.Items {
+		descriptions = append(descriptions, fmt.Sprintf("%s - %s: R$ %s", item.Code, item.Description, money(item.Total)))
+	}
+
+	taker := nfseTaker{Name: invoice.CustomerName}
+	if document := invoice.CustomerDocument.String(); len(document) == 11 {
+		taker.CPF = document
+	} else {
+		taker.CNPJ = document
+	}
+
+	return nfseEnvelope{
+		Xmlns: nfseNamespace,
+		Rps: nfseRps{Declaration: nfseDeclaration{
+			ID:             fmt.Sprintf("rps%d", invoice.ID),
+			Identification: nfseRpsID{Number: invoice.ID, Series: invoice.Series, Type: 1},
+			IssueDate:      issuedAt.Format("2006-01-02T15:04:05"),
+			RpsStatus:      1,
+			Competence:     issuedAt.Format("2006-01-02"),
+			Service: nfseService{
+				ServicesValue: money(invoice.Total),
+				ISSValue:      money(invoice.TaxTotal),
+				Rate:          rate(effectiveRate(invoice.TaxTotal, invoice.Total)),
+				ISSWithheld:   2,
+				Description:   strings.Join(descriptions, "; "),
+				CityCode:      e.issuer.CityCode,
+			},
+			Provider: nfseProvider{CNPJ: e.issuer.CNPJ, MunicipalRegistration: e.issuer.MunicipalRegistration},
+			Taker:    taker,
+		}},
+	}
+}
+
+type nfeDocument struct {
+	XMLName xml.Name `xml:"NFe"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Info    nfeInfo  `xml:"infNFe"`
+}
+
+type nfeInfo struct {
+	ID       string      `xml:"Id,attr"`
+	Version  string      `xml:"versao,attr"`
+	Ide      nfeIde      `xml:"ide"`
+	Issuer   nfeIssuer   `xml:"emit"`
+	Receiver nfeReceiver `xml:"dest"`
+	Details  []nfeDetail `xml:"det"`
+	Total    nfeTotal    `xml:"total>ICMSTot"`
+}
+
+type nfeIde struct {
+	StateCode   string `xml:"cUF"`
+	RandomCode  string `xml:"cNF"`
+	Operation   string `xml:"natOp"`
+	Model       string `xml:"mod"`
+	Series      string `xml:"serie"`
+	Number      uint   `xml:"nNF"`
+	IssueDate   string `xml:"dhEmi"`
+	Type        int    `xml:"tpNF"`
+	CityCode    string `xml:"cMunFG"`
+	Emission    int    `xml:"tpEmis"`
+	CheckDigit  string `xml:"cDV"`
+	Environment int    `xml:"tpAmb"`
+	Consumer    int    `xml:"indFinal"`
+}
+
+type nfeIssuer struct {
+	CNPJ              string `xml:"CNPJ"`
+	Name              string `xml:"xNome"`
+	State             string `xml:"enderEmit>UF"`
+	StateRegistration string `xml:"IE"`
+	TaxRegime         int    `xml:"CRT"`
+}
+
+type nfeReceiver struct {
+	CPF  string `xml:"CPF,omitempty"`
+	CNPJ string `xml:"CNPJ,omitempty"`
+	Name string `xml:"xNome"`
+}
+
+type nfeDetail struct {
+	Item    int        `xml:"nItem,attr"`
+	Product nfeProduct `xml:"prod"`
+	ICMS    nfeICMS    `xml:"imposto>ICMS>ICMS00"`
+}
+
+type nfeProduct struct {
+	Code        string `xml:"cProd"`
+	Description string `xml:"xProd"`
+	CFOP        string `xml:"CFOP"`
+	Unit        string `xml:"uCom"`
+	Quantity    string `xml:"qCom"`
+	UnitPrice   string `xml:"vUnCom"`
+	Total       string `xml:"vProd"`
+}
+
+type nfeICMS struct {
+	Origin int    `xml:"orig"`
+	CST    string `xml:"CST"`
+	Base   string `xml:"vBC"`
+	Rate   string `xml:"pICMS"`
+	Amount string `xml:"vICMS"`
+}
+
+type nfeTotal struct {
+	Base     string `xml:"vBC"`
+	ICMS     string `xml:"vICMS"`
+	Products string `xml:"vProd"`
+	Total    string `xml:"vNF"`
+}
+
+func (e *XMLEncoder) nfe(invoice entities.Invoice) nfeDocument {
+	issuedAt := e.now()
+	stateCode := e.stateCode()
+	randomCode := fmt.Sprintf("%08d", invoice.ID%100000000)
+	key := accessKey(stateCode, issuedAt, e.issuer.CNPJ, invoice.Series, invoice.ID, randomCode)
+
+	receiver := nfeReceiver{Name: invoice.CustomerName}
+	if document := invoice.CustomerDocument.String(); len(document) == 11 {
+		receiver.CPF = document
+	} else {
+		receiver.CNPJ = document
+	}
+
+	details := make([]nfeDetail, 0, len(invoice.Items))
+	for i, item := range invoice.Items {
+		details = append(details, nfeDetail{
+			Item: i + 1,
+			Product: nfeProduct{
+				Code:        item.Code,
+				Description: item.Description,
+				CFOP:        "5102",
+				Unit:        "UN",
+				Quantity:    fmt.Sprintf("%d.0000", item.Quantity),
+				UnitPrice:   money(item.UnitPrice),
+				Total:       money(item.Total),
+			},
+			ICMS: nfeICMS{
+				CST:    "00",
+				Base:   money(item.Total),
+				Rate:   rate(item.TaxRate),
+				Amount: money(item.TaxAmount),
+			},
+		})
+	}
+
+	return nfeDocument{
+		Xmlns: nfeNamespace,
+		Info: nfeInfo{
+			ID:      "NFe" + key,
+			Version: nfeVersion,
+			Ide: nfeIde{
+				StateCode:   stateCode,
+				RandomCode:  randomCode,
+				Operation:   "VENDA DE MERCADORIA",
+				Model:       nfeModel,
+				Series:      invoice.Series,
+				Number:      invoice.ID,
+				IssueDate:   issuedAt.Format(time.RFC3339),
+				Type:        1,
+				CityCode:    e.issuer.CityCode,
+				Emission:    1,
+				CheckDigit:  key[len(key)-1:],
+				Environment: 2,
+				Consumer:    1,
+			},
+			Issuer: nfeIssuer{
+				CNPJ:              e.issuer.CNPJ,
+				Name:              e.issuer.Name,
+				State:             e.issuer.State,
+				StateRegistration: e.issuer.StateRegistration,
+				TaxRegime:         3,
+			},
+			Receiver: receiver,
+			Details:  details,
+			Total: nfeTotal{
+				Base:     money(invoice.Total),
+				ICMS:     money(invoice.TaxTotal),
+				Products: money(invoice.Total),
+				Total:    money(invoice.Total),
+			},
+		},
+	}
+}
+
+// stateCode is the IBGE code of the state, which prefixes the IBGE code of every city
+func (e *XMLEncoder) stateCode() string {
+	if len(e.issuer.CityCode) < 2 {
+		return "00"
+	}
+	return e.issuer.CityCode[:2]
+}
+
+// accessKey builds the 44 digits NF-e access key, whose last digit is a modulo 11 check digit
+func accessKey(stateCode string, issuedAt time.Time, cnpj, series string, number uint, randomCode string) string {
+	var seriesNumber uint
+	fmt.Sscanf(series, "%d", &seriesNumber)
+
+	key := fmt.Sprintf("%s%s%s%s%03d%09d%d%s",
+		leftPad(stateCode, 2), issuedAt.Format("0601"), leftPad(cnpj, 14), nfeModel, seriesNumber%1000, number%1000000000, 1, randomCode)
+	return key + checkDigit(key)
+}
+
+func checkDigit(key string) string {
+	sum, weight := 0, 2
+	for i := len(key) - 1; i >= 0; i-- {
+		sum += int(key[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	digit := 11 - sum%11
+	if digit >= 10 {
+		digit = 0
+	}
+	return fmt.Sprintf("%d", digit)
+}
+
+func leftPad(v string, size int) string {
+	if len(v) >= size {
+		return v[len(v)-size:]
+	}
+	return strings.Repeat("0", size-len(v)) + v
+}
+
+func effectiveRate(tax, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return tax / base * 100
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+func rate(v float64) string {
+	return fmt.Sprintf("%.4f", v)
+}
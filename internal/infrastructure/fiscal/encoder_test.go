@@ -0,0 +1,86 @@
+package fiscal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+var testIssuer = entities.InvoiceIssuer{
+	CNPJ:                  "11222333000181",
+	Name:                  "Mecanica XPTO",
+	MunicipalRegistration: "12345678",
+	StateRegistration:     "123456789012",
+	CityCode:              "3550308",
+	State:                 "SP",
+}
+
+func testEncoder() *XMLEncoder {
+	encoder := NewXMLEncoder(testIssuer)
+	encoder.now = func() time.Time { return time.Date(2025, 8, 15, 10, 30, 0, 0, time.UTC) }
+	return encoder
+}
+
+func testInvoice(invoiceType valueobject.InvoiceType) entities.Invoice {
+	return entities.Invoice{
+		ID:               7,
+		Type:             invoiceType,
+		Series:           "1",
+		CustomerName:     "Joao da Silva",
+		CustomerDocument: valueobject.CpfCnpj("52998224725"),
+		Items: []entities.InvoiceItem{
+			{Code: "SRV-1", Description: "Troca de oleo", Quantity: 2, UnitPrice: 50, Total: 100, TaxRate: 18, TaxAmount: 18},
+		},
+		Total:    100,
+		TaxTotal: 18,
+	}
+}
+
+func TestXMLEncoder_EncodeNFSe(t *testing.T) {
+	xml, err := testEncoder().Encode(testInvoice(valueobject.InvoiceNFSe))
+	assert.NoError(t, err)
+
+	document := string(xml)
+	assert.Contains(t, document, `<GerarNfseEnvio xmlns="http://www.abrasf.org.br/nfse.xsd">`)
+	assert.Contains(t, document, `<InfDeclaracaoPrestacaoServico Id="rps7">`)
+	assert.Contains(t, document, `<ValorServicos>100.00</ValorServicos>`)
+	assert.Contains(t, document, `<ValorIss>18.00</ValorIss>`)
+	assert.Contains(t, document, `<Cpf>52998224725</Cpf>`)
+	assert.Contains(t, document, `<Cnpj>11222333000181</Cnpj>`)
+}
+
+func TestXMLEncoder_EncodeNFe(t *testing.T) {
+	xml, err := testEncoder().Encode(testInvoice(valueobject.InvoiceNFe))
+	assert.NoError(t, err)
+
+	document := string(xml)
+	assert.Contains(t, document, `<NFe xmlns="http://www.portalfiscal.inf.br/nfe">`)
+	assert.Contains(t, document, `<infNFe Id="NFe`)
+	assert.Contains(t, document, `<det nItem="1">`)
+	assert.Contains(t, document, `<qCom>2.0000</qCom>`)
+	assert.Contains(t, document, `<vICMS>18.00</vICMS>`)
+	assert.Contains(t, document, `<CPF>52998224725</CPF>`)
+
+	start := strings.Index(document, `Id="NFe`) + len(`Id="NFe`)
+	key := document[start : start+44]
+	assert.Equal(t, "35", key[:2])
+	assert.Equal(t, "2508", key[2:6])
+	assert.Equal(t, testIssuer.CNPJ, key[6:20])
+	assert.Equal(t, "55", key[20:22])
+	assert.Equal(t, checkDigit(key[:43]), key[43:])
+}
+
+func TestXMLEncoder_EncodeUnsupportedType(t *testing.T) {
+	_, err := testEncoder().Encode(testInvoice(valueobject.InvoiceType("NFCE")))
+	assert.ErrorIs(t, err, ErrUnsupportedInvoiceType)
+}
+
+func TestCheckDigit(t *testing.T) {
+	// access key from the NF-e technical manual
+	assert.Equal(t, "5", checkDigit("5206043300991100250655012000000780026730161"))
+}
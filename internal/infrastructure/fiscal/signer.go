@@ -0,0 +1,230 @@
+package fiscal
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"time"
+
+	"mecanica_xpto/internal/domain/gateway"
+)
+
+const (
+	dsigNamespace   = "http://www.w3.org/2000/09/xmldsig#"
+	c14nAlgorithm   = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+	rsaSHA256       = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	sha256Algorithm = "http://www.w3.org/2001/04/xmlenc#sha256"
+	envelopedSig    = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+)
+
+var (
+	ErrReferenceNotFound = errors.New("xml has no element with an Id attribute to sign")
+	ErrSignatureNotFound = errors.New("xml is not signed")
+	ErrInvalidSignature  = errors.New("xml signature is invalid")
+	ErrInvalidKey        = errors.New("certificate key must be an RSA private key")
+
+	referencePattern    = regexp.MustCompile(`<(\w+)[^>]*\sId="([^"]+)"`)
+	namespacePattern    = regexp.MustCompile(`xmlns="([^"]+)"`)
+	signaturePattern    = regexp.MustCompile(`(?s)<Signature xmlns="` + regexp.QuoteMeta(dsigNamespace) + `">(<SignedInfo>.*</SignedInfo>)<SignatureValue>([^<]+)</SignatureValue>.*?<X509Certificate>([^<]+)</X509Certificate>.*?</Signature>`)
+	digestPattern       = regexp.MustCompile(`<Reference URI="#([^"]+)">.*<DigestValue>([^<]+)</DigestValue>`)
+	signedInfoTagPrefix = []byte("<SignedInfo>")
+)
+
+// RSASigner signs invoices with an enveloped XMLDSig (RSA-SHA256), as required by the NF-e
+// and ABRASF NFS-e layouts. The element carrying the Id attribute is signed and the Signature
+// is placed right after it. Canonicalization only adds the inherited default namespace to the
+// signed element, which is enough for the XML produced by XMLEncoder.
+type RSASigner struct {
+	key     *rsa.PrivateKey
+	certDER []byte
+}
+
+var _ gateway.InvoiceSigner = (*RSASigner)(nil)
+
+func NewRSASigner(key *rsa.PrivateKey, certDER []byte) *RSASigner {
+	return &RSASigner{key: key, certDER: certDER}
+}
+
+// LoadRSASigner reads the A1 certificate of the issuer and its private key from PEM files
+func LoadRSASigner(certFile, keyFile string) (*RSASigner, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading certificate: %w", err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading certificate key: %w", err)
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, errors.New("certificate is not PEM encoded")
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, errors.New("certificate key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes); err == nil {
+		return NewRSASigner(key, certBlock.Bytes), nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing certificate key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	return NewRSASigner(key, certBlock.Bytes), nil
+}
+
+// NewSelfSignedSigner generates a throwaway certificate, only suitable for development and for the stub provider
+func NewSelfSignedSigner(commonName string) (*RSASigner, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return NewRSASigner(key, certDER), nil
+}
+
+func (s *RSASigner) Sign(xml []byte) ([]byte, error) {
+	id, element, end, err := referencedElement(xml)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(element)
+	signedInfo := fmt.Sprintf(`<SignedInfo>`+
+		`<CanonicalizationMethod Algorithm="%s"></CanonicalizationMethod>`+
+		`<SignatureMethod Algorithm="%s"></SignatureMethod>`+
+		`<Reference URI="#%s"><Transforms>`+
+		`<Transform Algorithm="%s"></Transform><Transform Algorithm="%s"></Transform>`+
+		`</Transforms><DigestMethod Algorithm="%s"></DigestMethod>`+
+		`<DigestValue>%s</DigestValue></Reference></SignedInfo>`,
+		c14nAlgorithm, rsaSHA256, id, envelopedSig, c14nAlgorithm, sha256Algorithm,
+		base64.StdEncoding.EncodeToString(digest[:]))
+
+	hashed := sha256.Sum256(canonicalSignedInfo([]byte(signedInfo)))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return nil, err
+	}
+
+	signatureXML := fmt.Sprintf(`<Signature xmlns="%s">%s<SignatureValue>%s</SignatureValue>`+
+		`<KeyInfo><X509Data><X509Certificate>%s</X509Certificate></X509Data></KeyInfo></Signature>`,
+		dsigNamespace, signedInfo, base64.StdEncoding.EncodeToString(signature),
+		base64.StdEncoding.EncodeToString(s.certDER))
+
+	signed := make([]byte, 0, len(xml)+len(signatureXML))
+	signed = append(signed, xml[:end]...)
+	signed = append(signed, signatureXML...)
+	signed = append(signed, xml[end:]...)
+	return signed, nil
+}
+
+// Verify checks the enveloped signature of a signed XML against the certificate it carries
+func Verify(xml []byte) error {
+	match := signaturePattern.FindSubmatchIndex(xml)
+	if match == nil {
+		return ErrSignatureNotFound
+	}
+	signedInfo := xml[match[2]:match[3]]
+	signatureValue, err := base64.StdEncoding.DecodeString(string(xml[match[4]:match[5]]))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	certDER, err := base64.StdEncoding.DecodeString(string(xml[match[6]:match[7]]))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	hashed := sha256.Sum256(canonicalSignedInfo(signedInfo))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signatureValue); err != nil {
+		return ErrInvalidSignature
+	}
+
+	reference := digestPattern.FindSubmatch(signedInfo)
+	if reference == nil {
+		return ErrInvalidSignature
+	}
+	unsigned := append(append([]byte{}, xml[:match[0]]...), xml[match[1]:]...)
+	id, element, _, err := referencedElement(unsigned)
+	if err != nil || id != string(reference[1]) {
+		return ErrInvalidSignature
+	}
+	digest := sha256.Sum256(element)
+	if base64.StdEncoding.EncodeToString(digest[:]) != string(reference[2]) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// referencedElement finds the element carrying the Id attribute and returns its id, its canonical
+// form (with the inherited default namespace declared) and the offset right after its closing tag
+func referencedElement(xml []byte) (string, []byte, int, error) {
+	match := referencePattern.FindSubmatchIndex(xml)
+	if match == nil {
+		return "", nil, 0, ErrReferenceNotFound
+	}
+	start := match[0]
+	tag := string(xml[match[2]:match[3]])
+	id := string(xml[match[4]:match[5]])
+
+	closing := []byte("</" + tag + ">")
+	offset := bytes.Index(xml[start:], closing)
+	if offset < 0 {
+		return "", nil, 0, ErrReferenceNotFound
+	}
+	end := start + offset + len(closing)
+	element := xml[start:end]
+
+	openTagEnd := bytes.IndexByte(element, '>')
+	if !namespacePattern.Match(element[:openTagEnd]) {
+		if ns := namespacePattern.FindSubmatch(xml[:start]); ns != nil {
+			declaration := []byte(` xmlns="` + string(ns[1]) + `"`)
+			canonical := make([]byte, 0, len(element)+len(declaration))
+			canonical = append(canonical, element[:1+len(tag)]...)
+			canonical = append(canonical, declaration...)
+			canonical = append(canonical, element[1+len(tag):]...)
+			element = canonical
+		}
+	}
+	return id, element, end, nil
+}
+
+// canonicalSignedInfo declares the XMLDSig namespace SignedInfo inherits from Signature
+func canonicalSignedInfo(signedInfo []byte) []byte {
+	declaration := []byte(`<SignedInfo xmlns="` + dsigNamespace + `">`)
+	return append(declaration, signedInfo[len(signedInfoTagPrefix):]...)
+}
@@ -0,0 +1,56 @@
+package fiscal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+func TestRSASigner_SignAndVerify(t *testing.T) {
+	signer, err := NewSelfSignedSigner("Mecanica XPTO")
+	require.NoError(t, err)
+
+	for _, invoiceType := range []valueobject.InvoiceType{valueobject.InvoiceNFSe, valueobject.InvoiceNFe} {
+		t.Run(invoiceType.String(), func(t *testing.T) {
+			xml, err := testEncoder().Encode(testInvoice(invoiceType))
+			require.NoError(t, err)
+
+			signed, err := signer.Sign(xml)
+			assert.NoError(t, err)
+			assert.Contains(t, string(signed), `<Signature xmlns="http://www.w3.org/2000/09/xmldsig#">`)
+			assert.NoError(t, wellFormed(signed))
+			assert.NoError(t, Verify(signed))
+		})
+	}
+}
+
+func TestVerify_TamperedXML(t *testing.T) {
+	signer, err := NewSelfSignedSigner("Mecanica XPTO")
+	require.NoError(t, err)
+	xml, err := testEncoder().Encode(testInvoice(valueobject.InvoiceNFe))
+	require.NoError(t, err)
+	signed, err := signer.Sign(xml)
+	require.NoError(t, err)
+
+	tampered := strings.Replace(string(signed), "<vNF>100.00</vNF>", "<vNF>10.00</vNF>", 1)
+	assert.ErrorIs(t, Verify([]byte(tampered)), ErrInvalidSignature)
+}
+
+func TestVerify_Unsigned(t *testing.T) {
+	xml, err := testEncoder().Encode(testInvoice(valueobject.InvoiceNFSe))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, Verify(xml), ErrSignatureNotFound)
+}
+
+func TestRSASigner_SignWithoutReference(t *testing.T) {
+	signer, err := NewSelfSignedSigner("Mecanica XPTO")
+	require.NoError(t, err)
+
+	_, err = signer.Sign([]byte(`<root><child/></root>`))
+	assert.ErrorIs(t, err, ErrReferenceNotFound)
+}
@@ -0,0 +1,95 @@
+package fiscal
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+const ProviderStub = "stub"
+
+var (
+	ErrUnknownProvider      = errors.New("unknown invoice provider")
+	ErrInvoiceWithoutNumber = errors.New("invoice has no number to cancel")
+)
+
+// NewProvider returns the invoice provider configured by name
+func NewProvider(name string) (gateway.InvoiceProvider, error) {
+	switch name {
+	case ProviderStub:
+		return NewStubProvider(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+}
+
+// StubProvider simulates the tax authorities locally: it validates the signed XML and
+// authorizes it with sequential numbers per invoice type. No invoice is really issued.
+type StubProvider struct {
+	mu        sync.Mutex
+	sequences map[valueobject.InvoiceType]int
+	now       func() time.Time
+}
+
+var _ gateway.InvoiceProvider = (*StubProvider)(nil)
+
+func NewStubProvider() *StubProvider {
+	return &StubProvider{
+		sequences: make(map[valueobject.InvoiceType]int),
+		now:       time.Now,
+	}
+}
+
+func (p *StubProvider) Submit(_ context.Context, invoice entities.Invoice, signedXML []byte) (*entities.InvoiceSubmission, error) {
+	if err := wellFormed(signedXML); err != nil {
+		return &entities.InvoiceSubmission{RejectionReason: fmt.Sprintf("malformed xml: %v", err)}, nil
+	}
+	if err := Verify(signedXML); err != nil {
+		return &entities.InvoiceSubmission{RejectionReason: err.Error()}, nil
+	}
+
+	p.mu.Lock()
+	p.sequences[invoice.Type]++
+	number := p.sequences[invoice.Type]
+	p.mu.Unlock()
+
+	issuedAt := p.now()
+	return &entities.InvoiceSubmission{
+		Accepted: true,
+		Number:   fmt.Sprintf("%d", number),
+		Protocol: fmt.Sprintf("%s%d%06d", invoice.Type, issuedAt.Unix(), number),
+		IssuedAt: issuedAt,
+	}, nil
+}
+
+func (p *StubProvider) Cancel(_ context.Context, invoice entities.Invoice, _ string) (*entities.InvoiceCancellation, error) {
+	if invoice.Number == "" {
+		return nil, ErrInvoiceWithoutNumber
+	}
+	cancelledAt := p.now()
+	return &entities.InvoiceCancellation{
+		Protocol:    fmt.Sprintf("CANC%s%d", invoice.Type, cancelledAt.Unix()),
+		CancelledAt: cancelledAt,
+	}, nil
+}
+
+func wellFormed(document []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
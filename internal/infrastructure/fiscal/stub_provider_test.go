@@ -0,0 +1,71 @@
+package fiscal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+func TestStubProvider_Submit(t *testing.T) {
+	ctx := context.Background()
+	signer, err := NewSelfSignedSigner("Mecanica XPTO")
+	require.NoError(t, err)
+	provider := NewStubProvider()
+	invoice := testInvoice(valueobject.InvoiceNFe)
+	xml, err := testEncoder().Encode(invoice)
+	require.NoError(t, err)
+	signed, err := signer.Sign(xml)
+	require.NoError(t, err)
+
+	t.Run("Success - sequential numbers", func(t *testing.T) {
+		first, err := provider.Submit(ctx, invoice, signed)
+		assert.NoError(t, err)
+		assert.True(t, first.Accepted)
+		assert.Equal(t, "1", first.Number)
+		assert.NotEmpty(t, first.Protocol)
+
+		second, err := provider.Submit(ctx, invoice, signed)
+		assert.NoError(t, err)
+		assert.Equal(t, "2", second.Number)
+	})
+
+	t.Run("Rejected - unsigned xml", func(t *testing.T) {
+		submission, err := provider.Submit(ctx, invoice, xml)
+		assert.NoError(t, err)
+		assert.False(t, submission.Accepted)
+		assert.NotEmpty(t, submission.RejectionReason)
+	})
+
+	t.Run("Rejected - malformed xml", func(t *testing.T) {
+		submission, err := provider.Submit(ctx, invoice, signed[:len(signed)-10])
+		assert.NoError(t, err)
+		assert.False(t, submission.Accepted)
+	})
+}
+
+func TestStubProvider_Cancel(t *testing.T) {
+	ctx := context.Background()
+	provider := NewStubProvider()
+	invoice := testInvoice(valueobject.InvoiceNFSe)
+
+	_, err := provider.Cancel(ctx, invoice, "servico nao realizado")
+	assert.ErrorIs(t, err, ErrInvoiceWithoutNumber)
+
+	invoice.Number = "1"
+	cancellation, err := provider.Cancel(ctx, invoice, "servico nao realizado")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cancellation.Protocol)
+}
+
+func TestNewProvider(t *testing.T) {
+	provider, err := NewProvider(ProviderStub)
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+
+	_, err = NewProvider("sefaz-sp")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
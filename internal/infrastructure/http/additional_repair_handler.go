@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"mecanica_xpto/internal/domain/model/entities"
 	"mecanica_xpto/internal/domain/usecase"
 	"mecanica_xpto/pkg"
@@ -24,6 +25,37 @@ func NewAdditionalRepairHandler(useCase usecase.IAdditionalRepairUseCase) *Addit
 	}
 }
 
+func mapAdditionalRepairError(err error, message string) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrAdditionalRepairNotFound):
+		return pkg.NewDomainErrorSimple("ADDITIONAL_REPAIR_NOT_FOUND", "Additional repair not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrServiceOrderNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_FOUND", "Service order not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrServiceOrderClosed):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_CLOSED", "Service order no longer accepts additional repairs", http.StatusConflict)
+	case errors.Is(err, usecase.ErrServiceOrderAlreadyPaid):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_ALREADY_PAID", "Service order is already paid", http.StatusConflict)
+	case errors.Is(err, usecase.ErrStatusNotPermitted):
+		return pkg.NewDomainErrorSimple("ADDITIONAL_REPAIR_STATUS_NOT_PERMITTED", "Operation not permitted in the current additional repair status", http.StatusConflict)
+	case errors.Is(err, usecase.ErrAdditionalRepairEmpty):
+		return pkg.NewDomainErrorSimple("ADDITIONAL_REPAIR_EMPTY", "Additional repair has no services or parts supplies", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidApprovalStatus):
+		return pkg.NewDomainErrorSimple("INVALID_APPROVAL_STATUS", "Approval status must be APPROVED or DENIED", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidPartsQuantity):
+		return pkg.NewDomainErrorSimple("INVALID_PARTS_SUPPLY_QUANTITY", "Parts supply quantity must be positive", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrNothingToRemove):
+		return pkg.NewDomainErrorSimple("NOTHING_TO_REMOVE", "No services or parts supplies to remove", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrItemNotInRepair):
+		return pkg.NewDomainErrorSimple("ITEM_NOT_IN_ADDITIONAL_REPAIR", "Service or parts supply is not part of the additional repair", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrPartsSupplyNotFound), errors.Is(err, usecase.ErrServiceNotFound):
+		return pkg.NewDomainErrorSimple("ITEM_NOT_FOUND", "Service or parts supply not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInsufficientPartsSupply):
+		return pkg.NewDomainErrorSimple("INSUFFICIENT_PARTS_SUPPLY", "Insufficient parts supply available", http.StatusConflict)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", message, err, http.StatusInternalServerError)
+	}
+}
+
 // GetAdditionalRepair godoc
 // @Summary Get additional repair by ID
 // @Description Retrieve an additional repair by its ID
@@ -43,11 +75,13 @@ func (h *AdditionalRepairHandler) GetAdditionalRepair(g *gin.Context) {
 	if err != nil {
 		appErr := pkg.NewDomainErrorSimple("INVALID_ID", "Invalid additional repair ID", http.StatusBadRequest)
 		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
 	}
 
 	foundAdr, err := h.additionalRepairUseCase.GetAdditionalRepair(g.Request.Context(), uint(id))
 	if err != nil {
-		g.JSON(500, gin.H{"error": "Failed to get additional repair"})
+		appErr := mapAdditionalRepairError(err, "Failed to get additional repair")
+		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
 		return
 	}
 
@@ -56,7 +90,7 @@ func (h *AdditionalRepairHandler) GetAdditionalRepair(g *gin.Context) {
 
 // CreateAdditionalRepair godoc
 // @Summary Create a new additional repair
-// @Description Create a new additional repair record
+// @Description Create a new additional repair record. The quantity of each parts supply is read from quantity_reserve (one unit when not set) and must be available in stock.
 // @Tags Additional Repairs
 // @Security Bearer
 // @Accept json
@@ -75,16 +109,17 @@ func (h *AdditionalRepairHandler) CreateAdditionalRepair(g *gin.Context) {
 
 	err := h.additionalRepairUseCase.CreateAdditionalRepair(g.Request.Context(), adr)
 	if err != nil {
-		g.JSON(500, gin.H{"error": "Failed to create service order"})
+		appErr := mapAdditionalRepairError(err, "Failed to create additional repair")
+		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
 		return
 	}
 
-	g.JSON(201, gin.H{"message": "Service order created successfully"})
+	g.JSON(201, gin.H{"message": "Additional repair created successfully"})
 }
 
 // AddPartSupplyAndService godoc
 // @Summary Add parts supply and service to additional repair
-// @Description Add parts supply and service to an existing additional repair
+// @Description Add parts supply and service to an open additional repair. Adding a parts supply already in the additional repair increases its quantity.
 // @Tags Additional Repairs
 // @Security Bearer
 // @Accept json
@@ -101,6 +136,7 @@ func (h *AdditionalRepairHandler) AddPartSupplyAndService(g *gin.Context) {
 	if err != nil {
 		appErr := pkg.NewDomainErrorSimple("INVALID_ID", "Invalid additional repair ID", http.StatusBadRequest)
 		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
 	}
 	var adr entities.AdditionalRepair
 	if err := g.ShouldBindJSON(&adr); err != nil {
@@ -110,7 +146,8 @@ func (h *AdditionalRepairHandler) AddPartSupplyAndService(g *gin.Context) {
 
 	err = h.additionalRepairUseCase.AddPartSupplyAndService(g.Request.Context(), uint(id), adr)
 	if err != nil {
-		g.JSON(500, gin.H{"error": "Failed to update additional repair"})
+		appErr := mapAdditionalRepairError(err, "Failed to update additional repair")
+		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
 		return
 	}
 
@@ -119,7 +156,7 @@ func (h *AdditionalRepairHandler) AddPartSupplyAndService(g *gin.Context) {
 
 // RemovePartSupplyAndService godoc
 // @Summary Remove parts supply and service from additional repair
-// @Description Remove parts supply and service from an existing additional repair
+// @Description Remove parts supply and service from an additional repair that is ABERTA or AGUARDANDO_APROVACAO. The quantity of each parts supply to remove is read from quantity_reserve (all units when not set); the estimate is reduced accordingly and reserved units are returned to stock.
 // @Tags Additional Repairs
 // @Security Bearer
 // @Accept json
@@ -128,14 +165,17 @@ func (h *AdditionalRepairHandler) AddPartSupplyAndService(g *gin.Context) {
 // @Param repair body entities.AdditionalRepair true "Parts Supply and Service Information"
 // @Success 201 {object} map[string]string
 // @Failure 400 {object} pkg.AppError
+// @Failure 404 {object} pkg.AppError
+// @Failure 409 {object} pkg.AppError
 // @Failure 500 {object} pkg.AppError
-// @Router /additional-repairs/{id}/remove [delete]
+// @Router /additional-repairs/{id}/remove [patch]
 func (h *AdditionalRepairHandler) RemovePartSupplyAndService(g *gin.Context) {
 	idStr := g.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
 		appErr := pkg.NewDomainErrorSimple("INVALID_ID", "Invalid customer ID", http.StatusBadRequest)
 		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
 	}
 	var adr entities.AdditionalRepair
 	if err := g.ShouldBindJSON(&adr); err != nil {
@@ -145,16 +185,47 @@ func (h *AdditionalRepairHandler) RemovePartSupplyAndService(g *gin.Context) {
 
 	err = h.additionalRepairUseCase.RemovePartSupplyAndService(g.Request.Context(), uint(id), adr)
 	if err != nil {
-		g.JSON(500, gin.H{"error": "Failed to update additional repair"})
+		appErr := mapAdditionalRepairError(err, "Failed to update additional repair")
+		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
 		return
 	}
 
 	g.JSON(201, gin.H{"message": "Additional repair updated successfully"})
 }
 
+// SubmitForApproval godoc
+// @Summary Submit additional repair for customer approval
+// @Description Move an open (ABERTA) additional repair to AGUARDANDO_APROVACAO, reserving its parts supplies
+// @Tags Additional Repairs
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Additional Repair ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} pkg.AppError
+// @Failure 404 {object} pkg.AppError
+// @Failure 409 {object} pkg.AppError
+// @Failure 500 {object} pkg.AppError
+// @Router /additional-repairs/{id}/submit [patch]
+func (h *AdditionalRepairHandler) SubmitForApproval(g *gin.Context) {
+	id, err := strconv.ParseUint(g.Param("id"), 10, 64)
+	if err != nil {
+		appErr := pkg.NewDomainErrorSimple("INVALID_ID", "Invalid additional repair ID", http.StatusBadRequest)
+		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	if err := h.additionalRepairUseCase.SubmitForApproval(g.Request.Context(), uint(id)); err != nil {
+		appErr := mapAdditionalRepairError(err, "Failed to submit additional repair")
+		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	g.JSON(http.StatusOK, gin.H{"message": "Additional repair submitted for approval"})
+}
+
 // CustomerApproval godoc
 // @Summary Update customer approval status for additional repair
-// @Description Update the customer approval status of an additional repair
+// @Description Record the customer decision on an additional repair awaiting approval. Only approved additional repairs are added to the service order estimate; an additional repair cannot be approved once the service order is paid.
 // @Tags Additional Repairs
 // @Security Bearer
 // @Accept json
@@ -163,14 +234,17 @@ func (h *AdditionalRepairHandler) RemovePartSupplyAndService(g *gin.Context) {
 // @Param status body entities.AdditionalRepairStatusDTO true "Approval Status Information"
 // @Success 201 {object} map[string]string
 // @Failure 400 {object} pkg.AppError
+// @Failure 404 {object} pkg.AppError
+// @Failure 409 {object} pkg.AppError
 // @Failure 500 {object} pkg.AppError
-// @Router /additional-repairs/{id}/customer_approval [post]
+// @Router /additional-repairs/{id}/customer_approval [patch]
 func (h *AdditionalRepairHandler) CustomerApproval(g *gin.Context) {
 	idStr := g.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
 		appErr := pkg.NewDomainErrorSimple("INVALID_ID", "Invalid customer ID", http.StatusBadRequest)
 		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
 	}
 	var adr entities.AdditionalRepairStatusDTO
 	if err := g.ShouldBindJSON(&adr); err != nil {
@@ -180,7 +254,8 @@ func (h *AdditionalRepairHandler) CustomerApproval(g *gin.Context) {
 
 	err = h.additionalRepairUseCase.CustomerApprovalStatus(g.Request.Context(), uint(id), adr)
 	if err != nil {
-		g.JSON(500, gin.H{"error": "Failed to update additional repair"})
+		appErr := mapAdditionalRepairError(err, "Failed to update additional repair")
+		g.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
 		return
 	}
 
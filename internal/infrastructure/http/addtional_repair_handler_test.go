@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
 	handler "mecanica_xpto/internal/infrastructure/http"
 	"mecanica_xpto/internal/infrastructure/http/mocks"
 
@@ -23,6 +24,7 @@ func setupADRRouter(h *handler.AdditionalRepairHandler) *gin.Engine {
 	r.GET("/additional-repair/:id", h.GetAdditionalRepair)
 	r.POST("/additional-repair/:id/part", h.AddPartSupplyAndService)
 	r.DELETE("/additional-repair/:id/part", h.RemovePartSupplyAndService)
+	r.PATCH("/additional-repair/:id/submit", h.SubmitForApproval)
 	r.POST("/additional-repair/:id/approval", h.CustomerApproval)
 	return r
 }
@@ -174,6 +176,24 @@ func TestRemovePartSupplyAndService_Error(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestRemovePartSupplyAndService_ItemNotInRepair(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockUC := mocks.NewMockIAdditionalRepairUseCase(ctrl)
+	h := handler.NewAdditionalRepairHandler(mockUC)
+	r := setupADRRouter(h)
+
+	adr := entities.AdditionalRepair{ID: 1}
+	mockUC.EXPECT().RemovePartSupplyAndService(gomock.Any(), uint(1), adr).Return(usecase.ErrItemNotInRepair)
+
+	body, _ := json.Marshal(adr)
+	req, _ := http.NewRequest("DELETE", "/additional-repair/1/part", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestCustomerApproval_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -209,3 +229,53 @@ func TestCustomerApproval_Error(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestSubmitForApproval_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockUC := mocks.NewMockIAdditionalRepairUseCase(ctrl)
+	h := handler.NewAdditionalRepairHandler(mockUC)
+	r := setupADRRouter(h)
+
+	mockUC.EXPECT().SubmitForApproval(gomock.Any(), uint(1)).Return(nil)
+
+	req, _ := http.NewRequest("PATCH", "/additional-repair/1/submit", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSubmitForApproval_StatusNotPermitted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockUC := mocks.NewMockIAdditionalRepairUseCase(ctrl)
+	h := handler.NewAdditionalRepairHandler(mockUC)
+	r := setupADRRouter(h)
+
+	mockUC.EXPECT().SubmitForApproval(gomock.Any(), uint(1)).Return(usecase.ErrStatusNotPermitted)
+
+	req, _ := http.NewRequest("PATCH", "/additional-repair/1/submit", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestCustomerApproval_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockUC := mocks.NewMockIAdditionalRepairUseCase(ctrl)
+	h := handler.NewAdditionalRepairHandler(mockUC)
+	r := setupADRRouter(h)
+
+	dto := entities.AdditionalRepairStatusDTO{ApprovalStatus: "APPROVED"}
+	mockUC.EXPECT().CustomerApprovalStatus(gomock.Any(), uint(1), dto).Return(usecase.ErrAdditionalRepairNotFound)
+
+	body, _ := json.Marshal(dto)
+	req, _ := http.NewRequest("POST", "/additional-repair/1/approval", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
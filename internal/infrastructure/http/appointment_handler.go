@@ -0,0 +1,402 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidAppointmentID     = pkg.NewDomainErrorSimple("INVALID_APPOINTMENT_ID", "Invalid appointment ID", http.StatusBadRequest)
+	errInvalidBayID             = pkg.NewDomainErrorSimple("INVALID_BAY_ID", "Invalid bay ID", http.StatusBadRequest)
+	errInvalidAppointmentInput  = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data", http.StatusBadRequest)
+	errInvalidAvailabilityQuery = pkg.NewDomainErrorSimple("INVALID_AVAILABILITY_QUERY",
+		"Query param from must be an RFC 3339 date-time, service_ids a comma separated list of IDs and limit a number", http.StatusBadRequest)
+)
+
+// AppointmentHandler handles the workshop bays and the appointments booked on them
+// @title Appointment API
+// @version 1.0
+// @description API for scheduling customer visits on the workshop bays
+type AppointmentHandler struct {
+	usecase usecase.IAppointmentUseCase
+}
+
+func NewAppointmentHandler(usecase usecase.IAppointmentUseCase) *AppointmentHandler {
+	return &AppointmentHandler{usecase: usecase}
+}
+
+func mapAppointmentError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrBayNotFound):
+		return pkg.NewDomainErrorSimple("BAY_NOT_FOUND", "Bay not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidBay):
+		return pkg.NewDomainErrorSimple("INVALID_BAY", "Bay name is required", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrBayInactive):
+		return pkg.NewDomainErrorSimple("BAY_INACTIVE", "Bay is inactive", http.StatusConflict)
+	case errors.Is(err, usecase.ErrAppointmentNotFound):
+		return pkg.NewDomainErrorSimple("APPOINTMENT_NOT_FOUND", "Appointment not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrAppointmentNotScheduled):
+		return pkg.NewDomainErrorSimple("APPOINTMENT_NOT_SCHEDULED", "Appointment was already converted or cancelled", http.StatusConflict)
+	case errors.Is(err, usecase.ErrAppointmentInPast):
+		return pkg.NewDomainErrorSimple("APPOINTMENT_IN_PAST", "Appointment must start in the future", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrAppointmentOutsideHours):
+		return pkg.NewDomainErrorSimple("APPOINTMENT_OUTSIDE_HOURS", "Appointment must fit within the working hours of a working day", http.StatusUnprocessableEntity)
+	case errors.Is(err, usecase.ErrAppointmentConflict):
+		return pkg.NewDomainErrorSimple("APPOINTMENT_CONFLICT", "Bay is already booked in this interval", http.StatusConflict)
+	case errors.Is(err, usecase.ErrNoBayAvailable):
+		return pkg.NewDomainErrorSimple("NO_BAY_AVAILABLE", "No bay is available in this interval", http.StatusConflict)
+	case errors.Is(err, usecase.ErrVehicleNotOwnedByCustomer):
+		return pkg.NewDomainErrorSimple("VEHICLE_NOT_OWNED_BY_CUSTOMER", "Vehicle does not belong to the customer", http.StatusUnprocessableEntity)
+	case errors.Is(err, usecase.ErrCustomerNotFound):
+		return pkg.NewDomainErrorSimple("CUSTOMER_NOT_FOUND", "Customer not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrVehicleNotFound):
+		return pkg.NewDomainErrorSimple("VEHICLE_NOT_FOUND", "Vehicle not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrServiceNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_NOT_FOUND", "Service not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidReportPeriod):
+		return pkg.NewDomainErrorSimple("INVALID_PERIOD", "End date must not be before the start date", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidAvailabilitySearch):
+		return errInvalidAvailabilityQuery
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// CreateBay godoc
+// @Summary Create a bay
+// @Description Register a bay or lift. Each active bay holds one visit at a time.
+// @Tags Appointments
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param bay body entities.Bay true "Bay"
+// @Success 201 {object} entities.Bay
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /bays [post]
+func (h *AppointmentHandler) CreateBay(c *gin.Context) {
+	var input entities.Bay
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidAppointmentInput.HTTPStatus, errInvalidAppointmentInput.ToHTTPError())
+		return
+	}
+
+	bay, err := h.usecase.CreateBay(c.Request.Context(), input)
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, bay)
+}
+
+// ListBays godoc
+// @Summary List bays
+// @Description List the bays, active and inactive
+// @Tags Appointments
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} entities.Bay
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /bays [get]
+func (h *AppointmentHandler) ListBays(c *gin.Context) {
+	bays, err := h.usecase.ListBays(c.Request.Context())
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, bays)
+}
+
+// UpdateBay godoc
+// @Summary Update a bay
+// @Description Rename a bay or take it in and out of service. Visits already booked on an inactive bay are kept.
+// @Tags Appointments
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Bay ID"
+// @Param bay body entities.Bay true "Bay"
+// @Success 200 {object} entities.Bay
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /bays/{id} [put]
+func (h *AppointmentHandler) UpdateBay(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidBayID.HTTPStatus, errInvalidBayID.ToHTTPError())
+		return
+	}
+	var input entities.Bay
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidAppointmentInput.HTTPStatus, errInvalidAppointmentInput.ToHTTPError())
+		return
+	}
+	input.ID = uint(id)
+
+	bay, err := h.usecase.UpdateBay(c.Request.Context(), input)
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, bay)
+}
+
+// CreateAppointment godoc
+// @Summary Book an appointment
+// @Description Book a visit of a customer's vehicle. It lasts the standard time of the desired services, rounded up to whole slots, and takes the given bay or the first one free.
+// @Tags Appointments
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param appointment body entities.AppointmentRequest true "Appointment"
+// @Success 201 {object} entities.Appointment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 422 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /appointments [post]
+func (h *AppointmentHandler) CreateAppointment(c *gin.Context) {
+	var input entities.AppointmentRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidAppointmentInput.HTTPStatus, errInvalidAppointmentInput.ToHTTPError())
+		return
+	}
+	input.CreatedBy = c.GetString(middleware.ContextUserEmail)
+
+	appointment, err := h.usecase.CreateAppointment(c.Request.Context(), input)
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
+
+// ListAppointments godoc
+// @Summary List appointments
+// @Description Get the agenda of the appointments starting within a period, whatever their status
+// @Tags Appointments
+// @Security Bearer
+// @Produce json
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD)"
+// @Success 200 {array} entities.Appointment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /appointments [get]
+func (h *AppointmentHandler) ListAppointments(c *gin.Context) {
+	from, to, ok := parsePeriod(c)
+	if !ok {
+		c.JSON(errInvalidReportPeriod.HTTPStatus, errInvalidReportPeriod.ToHTTPError())
+		return
+	}
+
+	appointments, err := h.usecase.ListAppointments(c.Request.Context(), from, to)
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, appointments)
+}
+
+// FindAvailability godoc
+// @Summary Find free slots
+// @Description Get the next slots in which a visit for the given services fits within the working hours and a bay is free
+// @Tags Appointments
+// @Security Bearer
+// @Produce json
+// @Param from query string false "Search from this date-time (RFC 3339), now by default"
+// @Param service_ids query string false "Comma separated IDs of the desired services"
+// @Param limit query int false "How many slots to return (default 5, at most 50)"
+// @Success 200 {array} entities.AvailableSlot
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /appointments/availability [get]
+func (h *AppointmentHandler) FindAvailability(c *gin.Context) {
+	from := time.Now()
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(errInvalidAvailabilityQuery.HTTPStatus, errInvalidAvailabilityQuery.ToHTTPError())
+			return
+		}
+		from = parsed
+	}
+	var serviceIDs []uint
+	if value := c.Query("service_ids"); value != "" {
+		for _, item := range strings.Split(value, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(item), 10, 32)
+			if err != nil || id == 0 {
+				c.JSON(errInvalidAvailabilityQuery.HTTPStatus, errInvalidAvailabilityQuery.ToHTTPError())
+				return
+			}
+			serviceIDs = append(serviceIDs, uint(id))
+		}
+	}
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(errInvalidAvailabilityQuery.HTTPStatus, errInvalidAvailabilityQuery.ToHTTPError())
+			return
+		}
+		limit = parsed
+	}
+
+	slots, err := h.usecase.FindAvailability(c.Request.Context(), from, serviceIDs, limit)
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
+}
+
+// GetAppointment godoc
+// @Summary Get an appointment
+// @Description Get an appointment by ID
+// @Tags Appointments
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Success 200 {object} entities.Appointment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /appointments/{id} [get]
+func (h *AppointmentHandler) GetAppointment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidAppointmentID.HTTPStatus, errInvalidAppointmentID.ToHTTPError())
+		return
+	}
+
+	appointment, err := h.usecase.GetAppointment(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
+// RescheduleAppointment godoc
+// @Summary Reschedule an appointment
+// @Description Move a scheduled appointment to another start, keeping its length, on the given bay or else on its bay or the first one free
+// @Tags Appointments
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param request body entities.RescheduleRequest true "New slot"
+// @Success 200 {object} entities.Appointment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 422 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /appointments/{id}/reschedule [patch]
+func (h *AppointmentHandler) RescheduleAppointment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidAppointmentID.HTTPStatus, errInvalidAppointmentID.ToHTTPError())
+		return
+	}
+	var input entities.RescheduleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidAppointmentInput.HTTPStatus, errInvalidAppointmentInput.ToHTTPError())
+		return
+	}
+
+	appointment, err := h.usecase.RescheduleAppointment(c.Request.Context(), uint(id), input)
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
+// CancelAppointment godoc
+// @Summary Cancel an appointment
+// @Description Cancel a scheduled appointment, freeing its bay
+// @Tags Appointments
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Success 200 {object} entities.Appointment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /appointments/{id}/cancel [patch]
+func (h *AppointmentHandler) CancelAppointment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidAppointmentID.HTTPStatus, errInvalidAppointmentID.ToHTTPError())
+		return
+	}
+
+	appointment, err := h.usecase.CancelAppointment(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
+// ConvertAppointment godoc
+// @Summary Convert an appointment into a service order
+// @Description Open the service order of a customer who arrived for their appointment. The order starts as RECEBIDA.
+// @Tags Appointments
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Success 201 {object} entities.ServiceOrder
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /appointments/{id}/convert [post]
+func (h *AppointmentHandler) ConvertAppointment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidAppointmentID.HTTPStatus, errInvalidAppointmentID.ToHTTPError())
+		return
+	}
+
+	serviceOrder, err := h.usecase.ConvertAppointment(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapAppointmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, serviceOrder)
+}
@@ -0,0 +1,224 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidAdditionalRepairID = pkg.NewDomainErrorSimple("INVALID_ADDITIONAL_REPAIR_ID", "Invalid additional repair ID", http.StatusBadRequest)
+	errInvalidApprovalDecision   = pkg.NewDomainErrorSimple("INVALID_APPROVAL_DECISION", "Decision must be APPROVED or DENIED", http.StatusBadRequest)
+	errInvalidEstimateLinkInput  = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data, approver_contact_id must be a contact ID", http.StatusBadRequest)
+)
+
+// ApprovalHandler handles the approval links sent to customers, both the authenticated endpoints
+// the workshop uses to create them and the public endpoints the customer opens
+// @title Approval API
+// @version 1.0
+// @description API for customer approvals through signed links in the workshop management system
+type ApprovalHandler struct {
+	usecase usecase.IApprovalLinkUseCase
+}
+
+func NewApprovalHandler(usecase usecase.IApprovalLinkUseCase) *ApprovalHandler {
+	return &ApprovalHandler{usecase: usecase}
+}
+
+func mapApprovalError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrApprovalLinkNotFound):
+		return pkg.NewDomainErrorSimple("APPROVAL_LINK_NOT_FOUND", "Approval link not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrApprovalLinkExpired):
+		return pkg.NewDomainErrorSimple("APPROVAL_LINK_EXPIRED", "Approval link expired", http.StatusGone)
+	case errors.Is(err, usecase.ErrApprovalLinkUsed):
+		return pkg.NewDomainErrorSimple("APPROVAL_LINK_USED", "Approval link was already used", http.StatusConflict)
+	case errors.Is(err, usecase.ErrNotAwaitingApproval),
+		errors.Is(err, usecase.ErrInvalidTransitionStatusToEstimate),
+		errors.Is(err, usecase.ErrStatusNotPermitted):
+		return pkg.NewDomainErrorSimple("NOT_AWAITING_APPROVAL", "Estimate or additional repair is not awaiting customer approval", http.StatusConflict)
+	case errors.Is(err, usecase.ErrServiceOrderAlreadyPaid):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_ALREADY_PAID", "Service order is already paid", http.StatusConflict)
+	case errors.Is(err, usecase.ErrDiscountApprovalPending):
+		return pkg.NewDomainErrorSimple("DISCOUNT_APPROVAL_PENDING", "The discount of the estimate still needs an admin approval", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInsufficientPartsSupply):
+		return pkg.NewDomainErrorSimple("INSUFFICIENT_PARTS_SUPPLY", "Insufficient parts supply available", http.StatusConflict)
+	case errors.Is(err, usecase.ErrEstimateApproverRequired):
+		return pkg.NewDomainErrorSimple("ESTIMATE_APPROVER_REQUIRED", "The estimate must be sent to an approver of the corporate account", http.StatusUnprocessableEntity)
+	case errors.Is(err, usecase.ErrNotEstimateApprover):
+		return pkg.NewDomainErrorSimple("NOT_ESTIMATE_APPROVER", "The contact may not approve estimates of the corporate account", http.StatusUnprocessableEntity)
+	case errors.Is(err, usecase.ErrInvalidApprovalStatus):
+		return errInvalidApprovalDecision
+	case errors.Is(err, usecase.ErrServiceOrderNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_FOUND", "Service order not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrAdditionalRepairNotFound):
+		return pkg.NewDomainErrorSimple("ADDITIONAL_REPAIR_NOT_FOUND", "Additional repair not found", http.StatusNotFound)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// CreateEstimateLink godoc
+// @Summary Create an estimate approval link
+// @Description Create a signed, single-use link for the customer to approve or reject the estimate of a service order awaiting approval. The token is only returned here. The link of a company whose corporate account names estimate approvers must be sent to one of them.
+// @Tags Approvals
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Param request body entities.EstimateLinkRequest false "Approver of the corporate account"
+// @Success 201 {object} entities.ApprovalLink
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 422 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/approval-links [post]
+func (h *ApprovalHandler) CreateEstimateLink(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	// The body is optional, only the links sent to a corporate approver carry one
+	var input entities.EstimateLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(errInvalidEstimateLinkInput.HTTPStatus, errInvalidEstimateLinkInput.ToHTTPError())
+			return
+		}
+	}
+
+	link, err := h.usecase.CreateEstimateLink(c.Request.Context(), uint(id), input.ApproverContactID, c.GetString(middleware.ContextUserEmail))
+	if err != nil {
+		appErr := mapApprovalError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// CreateAdditionalRepairLink godoc
+// @Summary Create an additional repair approval link
+// @Description Create a signed, single-use link for the customer to approve or reject an additional repair awaiting approval. The token is only returned here.
+// @Tags Approvals
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Additional Repair ID"
+// @Success 201 {object} entities.ApprovalLink
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /additional-repair/{id}/approval-links [post]
+func (h *ApprovalHandler) CreateAdditionalRepairLink(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidAdditionalRepairID.HTTPStatus, errInvalidAdditionalRepairID.ToHTTPError())
+		return
+	}
+
+	link, err := h.usecase.CreateAdditionalRepairLink(c.Request.Context(), uint(id), c.GetString(middleware.ContextUserEmail))
+	if err != nil {
+		appErr := mapApprovalError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListApprovalLinks godoc
+// @Summary List the approval links of a service order
+// @Description List the approval links of a service order with the decision, IP address and user agent recorded as proof of consent
+// @Tags Approvals
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Success 200 {array} entities.ApprovalLink
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/approval-links [get]
+func (h *ApprovalHandler) ListApprovalLinks(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	links, err := h.usecase.ListApprovalLinks(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapApprovalError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// GetApprovalRequest godoc
+// @Summary View what an approval link asks the customer to approve
+// @Description Public endpoint opened from the link sent to the customer. Returns the estimate of the service order or the additional repair to decide on.
+// @Tags Approvals
+// @Produce json
+// @Param token path string true "Approval token"
+// @Success 200 {object} entities.ApprovalRequest
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 410 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /public/approvals/{token} [get]
+func (h *ApprovalHandler) GetApprovalRequest(c *gin.Context) {
+	request, err := h.usecase.GetApprovalRequest(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		appErr := mapApprovalError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// Decide godoc
+// @Summary Approve or reject through an approval link
+// @Description Public endpoint recording the customer decision. The link can only be used once; the IP address, user agent and time of the decision are kept as proof of consent.
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Param token path string true "Approval token"
+// @Param decision body entities.ApprovalDecision true "Customer decision"
+// @Success 200 {object} entities.ApprovalLink
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 410 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /public/approvals/{token} [post]
+func (h *ApprovalHandler) Decide(c *gin.Context) {
+	var input entities.ApprovalDecision
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidApprovalDecision.HTTPStatus, errInvalidApprovalDecision.ToHTTPError())
+		return
+	}
+
+	consent := entities.ApprovalConsent{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	link, err := h.usecase.Decide(c.Request.Context(), c.Param("token"), input.Decision, consent)
+	if err != nil {
+		appErr := mapApprovalError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
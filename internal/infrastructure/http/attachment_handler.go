@@ -0,0 +1,351 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	errInvalidAttachmentID   = pkg.NewDomainErrorSimple("INVALID_ATTACHMENT_ID", "Invalid attachment ID", http.StatusBadRequest)
+	errInvalidAttachmentFile = pkg.NewDomainErrorSimple("INVALID_ATTACHMENT", "Form field file with the attached file is required", http.StatusBadRequest)
+	errInvalidOwnerID        = pkg.NewDomainErrorSimple("INVALID_ID", "Invalid ID", http.StatusBadRequest)
+)
+
+// AttachmentHandler handles the files attached as evidence to service orders and additional repairs
+// @title Attachment API
+// @version 1.0
+// @description API for uploading and downloading the files attached to service orders and additional repairs
+type AttachmentHandler struct {
+	usecase       usecase.IAttachmentUseCase
+	maxUploadSize int64
+}
+
+func NewAttachmentHandler(usecase usecase.IAttachmentUseCase, maxUploadSize int64) *AttachmentHandler {
+	return &AttachmentHandler{usecase: usecase, maxUploadSize: maxUploadSize}
+}
+
+func mapAttachmentError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrServiceOrderNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_FOUND", "Service order not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrAdditionalRepairNotFound):
+		return pkg.NewDomainErrorSimple("ADDITIONAL_REPAIR_NOT_FOUND", "Additional repair not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrAttachmentNotFound):
+		return pkg.NewDomainErrorSimple("ATTACHMENT_NOT_FOUND", "Attachment not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrAttachmentTooLarge):
+		return pkg.NewDomainErrorSimple("ATTACHMENT_TOO_LARGE", "File exceeds the maximum upload size", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, usecase.ErrInvalidAttachmentType):
+		return pkg.NewDomainErrorSimple("INVALID_ATTACHMENT_TYPE", "File must be a JPEG, PNG or WebP image, a PDF or a plain text file", http.StatusUnsupportedMediaType)
+	case errors.Is(err, usecase.ErrEmptyAttachment):
+		return pkg.NewDomainErrorSimple("EMPTY_ATTACHMENT", "File is empty", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrAttachmentDownloadInvalid):
+		return pkg.NewDomainErrorSimple("INVALID_DOWNLOAD_LINK", "Invalid download link", http.StatusForbidden)
+	case errors.Is(err, usecase.ErrAttachmentDownloadExpired):
+		return pkg.NewDomainErrorSimple("DOWNLOAD_LINK_EXPIRED", "Download link expired", http.StatusGone)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// UploadServiceOrderAttachment godoc
+// @Summary Attach a file to a service order
+// @Description Upload a JPEG, PNG or WebP image, a PDF or a plain text file, sent as the multipart field file. The SHA-256 of the content is returned as its checksum.
+// @Tags Attachments
+// @Security Bearer
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Service order ID"
+// @Param file formData file true "File"
+// @Success 201 {object} entities.Attachment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 413 {object} pkg.ErrorResponse
+// @Failure 415 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/attachments [post]
+func (h *AttachmentHandler) UploadServiceOrderAttachment(c *gin.Context) {
+	h.upload(c, valueobject.AttachmentOwnerServiceOrder)
+}
+
+// ListServiceOrderAttachments godoc
+// @Summary List the files of a service order
+// @Description List the files attached to a service order, without those of its additional repairs
+// @Tags Attachments
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Service order ID"
+// @Success 200 {array} entities.Attachment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/attachments [get]
+func (h *AttachmentHandler) ListServiceOrderAttachments(c *gin.Context) {
+	h.list(c, valueobject.AttachmentOwnerServiceOrder)
+}
+
+// UploadAdditionalRepairAttachment godoc
+// @Summary Attach a file to an additional repair
+// @Description Upload a JPEG, PNG or WebP image, a PDF or a plain text file, sent as the multipart field file. The SHA-256 of the content is returned as its checksum.
+// @Tags Attachments
+// @Security Bearer
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Additional repair ID"
+// @Param file formData file true "File"
+// @Success 201 {object} entities.Attachment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 413 {object} pkg.ErrorResponse
+// @Failure 415 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /additional-repair/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAdditionalRepairAttachment(c *gin.Context) {
+	h.upload(c, valueobject.AttachmentOwnerAdditionalRepair)
+}
+
+// ListAdditionalRepairAttachments godoc
+// @Summary List the files of an additional repair
+// @Description List the files attached to an additional repair
+// @Tags Attachments
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Additional repair ID"
+// @Success 200 {array} entities.Attachment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /additional-repair/{id}/attachments [get]
+func (h *AttachmentHandler) ListAdditionalRepairAttachments(c *gin.Context) {
+	h.list(c, valueobject.AttachmentOwnerAdditionalRepair)
+}
+
+// GetAttachment godoc
+// @Summary Get an attachment
+// @Description Get the name, type, size and checksum of an attached file
+// @Tags Attachments
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Attachment ID"
+// @Success 200 {object} entities.Attachment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /attachments/{id} [get]
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidAttachmentID.HTTPStatus, errInvalidAttachmentID.ToHTTPError())
+		return
+	}
+
+	attachment, err := h.usecase.Get(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapAttachmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// DownloadAttachment godoc
+// @Summary Download an attachment
+// @Description Stream the content of an attached file
+// @Tags Attachments
+// @Security Bearer
+// @Produce octet-stream
+// @Param id path int true "Attachment ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /attachments/{id}/download [get]
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidAttachmentID.HTTPStatus, errInvalidAttachmentID.ToHTTPError())
+		return
+	}
+
+	attachment, content, err := h.usecase.Open(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapAttachmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+	defer content.Close()
+
+	streamFile(c, "attachment", attachment.FileName, attachment.ContentType, attachment.Size, content)
+}
+
+// CreateDownloadURL godoc
+// @Summary Create a temporary download link
+// @Description Sign a link that downloads the attachment without logging in until it expires (DOWNLOAD_URL_TTL)
+// @Tags Attachments
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Attachment ID"
+// @Success 201 {object} entities.AttachmentDownloadURL
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /attachments/{id}/download-url [post]
+func (h *AttachmentHandler) CreateDownloadURL(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidAttachmentID.HTTPStatus, errInvalidAttachmentID.ToHTTPError())
+		return
+	}
+
+	link, err := h.usecase.CreateDownloadURL(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapAttachmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// DownloadSignedAttachment godoc
+// @Summary Download an attachment from a temporary link
+// @Description Stream the content of an attached file, authorized by the signature of the link instead of a login
+// @Tags Attachments
+// @Produce octet-stream
+// @Param id path int true "Attachment ID"
+// @Param expires query string true "Expiry of the link, in unix seconds"
+// @Param signature query string true "Signature of the link"
+// @Success 200 {file} binary
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 403 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 410 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /public/attachments/{id} [get]
+func (h *AttachmentHandler) DownloadSignedAttachment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidAttachmentID.HTTPStatus, errInvalidAttachmentID.ToHTTPError())
+		return
+	}
+
+	attachment, content, err := h.usecase.OpenSigned(c.Request.Context(), uint(id), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		appErr := mapAttachmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+	defer content.Close()
+
+	streamFile(c, "attachment", attachment.FileName, attachment.ContentType, attachment.Size, content)
+}
+
+// DeleteAttachment godoc
+// @Summary Delete an attachment
+// @Description Remove an attached file
+// @Tags Attachments
+// @Security Bearer
+// @Param id path int true "Attachment ID"
+// @Success 204
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /attachments/{id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidAttachmentID.HTTPStatus, errInvalidAttachmentID.ToHTTPError())
+		return
+	}
+
+	if err := h.usecase.Delete(c.Request.Context(), uint(id)); err != nil {
+		appErr := mapAttachmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AttachmentHandler) upload(c *gin.Context, ownerType valueobject.AttachmentOwner) {
+	ownerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidOwnerID.HTTPStatus, errInvalidOwnerID.ToHTTPError())
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			appErr := mapAttachmentError(usecase.ErrAttachmentTooLarge)
+			c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+			return
+		}
+		c.JSON(errInvalidAttachmentFile.HTTPStatus, errInvalidAttachmentFile.ToHTTPError())
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		appErr := mapAttachmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+	defer file.Close()
+
+	upload := entities.AttachmentUpload{
+		FileName:   fileHeader.Filename,
+		Size:       fileHeader.Size,
+		UploadedBy: c.GetString(middleware.ContextUserEmail),
+	}
+	attachment, err := h.usecase.Upload(c.Request.Context(), ownerType, uint(ownerID), upload, file)
+	if err != nil {
+		appErr := mapAttachmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+func (h *AttachmentHandler) list(c *gin.Context, ownerType valueobject.AttachmentOwner) {
+	ownerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidOwnerID.HTTPStatus, errInvalidOwnerID.ToHTTPError())
+		return
+	}
+
+	attachments, err := h.usecase.List(c.Request.Context(), ownerType, uint(ownerID))
+	if err != nil {
+		appErr := mapAttachmentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// streamFile writes a stored file to the response. The name sent by the client that uploaded it is
+// escaped, so it cannot break out of the Content-Disposition header.
+func streamFile(c *gin.Context, disposition, fileName, contentType string, size int64, content io.Reader) {
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": fileName}))
+	c.Header("Content-Length", strconv.FormatInt(size, 10))
+	c.Header("Content-Type", contentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		log.Error().Msgf("Error streaming %s: %v", fileName, err)
+	}
+}
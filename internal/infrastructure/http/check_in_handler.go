@@ -0,0 +1,268 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room left in the request body for the multipart headers and the other
+// form fields around the photo
+const multipartOverhead = 1 << 20
+
+var (
+	errInvalidCheckInInput = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data", http.StatusBadRequest)
+	errInvalidCheckInPhoto = pkg.NewDomainErrorSimple("INVALID_CHECK_IN_PHOTO", "Form field photo with the image file is required", http.StatusBadRequest)
+	errInvalidPhotoID      = pkg.NewDomainErrorSimple("INVALID_PHOTO_ID", "Invalid photo ID", http.StatusBadRequest)
+)
+
+// CheckInHandler handles the inspection recorded when a vehicle is received
+// @title Check-in API
+// @version 1.0
+// @description API for the check-in inspection of the vehicle of a service order
+type CheckInHandler struct {
+	usecase       usecase.ICheckInUseCase
+	maxUploadSize int64
+}
+
+func NewCheckInHandler(usecase usecase.ICheckInUseCase, maxUploadSize int64) *CheckInHandler {
+	return &CheckInHandler{usecase: usecase, maxUploadSize: maxUploadSize}
+}
+
+func mapCheckInError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrServiceOrderNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_FOUND", "Service order not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrCheckInNotFound):
+		return pkg.NewDomainErrorSimple("CHECK_IN_NOT_FOUND", "Check-in not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrCheckInClosed):
+		return pkg.NewDomainErrorSimple("CHECK_IN_CLOSED", "Check-in can only be changed while the service order is RECEBIDA", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInvalidCheckIn):
+		return pkg.NewDomainErrorSimple("INVALID_CHECK_IN", "Odometer must not be negative and fuel level and checklist conditions must be valid", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrCheckInForbidden):
+		return pkg.NewDomainErrorSimple("FORBIDDEN", "Not allowed to view this check-in", http.StatusForbidden)
+	case errors.Is(err, usecase.ErrCheckInPhotoNotFound):
+		return pkg.NewDomainErrorSimple("CHECK_IN_PHOTO_NOT_FOUND", "Check-in photo not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrCheckInPhotoTooLarge):
+		return pkg.NewDomainErrorSimple("CHECK_IN_PHOTO_TOO_LARGE", "Photo exceeds the maximum upload size", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, usecase.ErrInvalidCheckInPhotoType):
+		return pkg.NewDomainErrorSimple("INVALID_CHECK_IN_PHOTO_TYPE", "Photo must be a JPEG, PNG or WebP image", http.StatusUnsupportedMediaType)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// SaveCheckIn godoc
+// @Summary Record the check-in of a service order
+// @Description Record, or replace, the odometer, fuel level, checklist and notes taken when the vehicle is received. Only accepted while the order is RECEBIDA.
+// @Tags Check-in
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Service order ID"
+// @Param checkIn body entities.CheckInRequest true "Check-in"
+// @Success 200 {object} entities.CheckIn
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/check-in [put]
+func (h *CheckInHandler) SaveCheckIn(c *gin.Context) {
+	serviceOrderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	var input entities.CheckInRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidCheckInInput.HTTPStatus, errInvalidCheckInInput.ToHTTPError())
+		return
+	}
+	input.InspectedBy = c.GetString(middleware.ContextUserEmail)
+
+	checkIn, err := h.usecase.SaveCheckIn(c.Request.Context(), uint(serviceOrderID), input)
+	if err != nil {
+		appErr := mapCheckInError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, checkIn)
+}
+
+// GetCheckIn godoc
+// @Summary Get the check-in of a service order
+// @Description Get the check-in and the list of its photos. Customers can only see the check-in of their own orders.
+// @Tags Check-in
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Service order ID"
+// @Success 200 {object} entities.CheckIn
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 403 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/check-in [get]
+func (h *CheckInHandler) GetCheckIn(c *gin.Context) {
+	serviceOrderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	checkIn, err := h.usecase.GetCheckIn(c.Request.Context(), uint(serviceOrderID), c.GetString(middleware.ContextUserEmail), viewerRole(c))
+	if err != nil {
+		appErr := mapCheckInError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, checkIn)
+}
+
+// AddPhoto godoc
+// @Summary Upload a check-in photo
+// @Description Attach a JPEG, PNG or WebP photo to the check-in of a service order, sent as the multipart field photo
+// @Tags Check-in
+// @Security Bearer
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Service order ID"
+// @Param photo formData file true "Photo"
+// @Param caption formData string false "Caption"
+// @Success 201 {object} entities.CheckInPhoto
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 413 {object} pkg.ErrorResponse
+// @Failure 415 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/check-in/photos [post]
+func (h *CheckInHandler) AddPhoto(c *gin.Context) {
+	serviceOrderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	fileHeader, err := c.FormFile("photo")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			appErr := mapCheckInError(usecase.ErrCheckInPhotoTooLarge)
+			c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+			return
+		}
+		c.JSON(errInvalidCheckInPhoto.HTTPStatus, errInvalidCheckInPhoto.ToHTTPError())
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		appErr := mapCheckInError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+	defer file.Close()
+
+	upload := entities.CheckInPhotoUpload{
+		FileName:   fileHeader.Filename,
+		Caption:    c.PostForm("caption"),
+		Size:       fileHeader.Size,
+		UploadedBy: c.GetString(middleware.ContextUserEmail),
+	}
+	photo, err := h.usecase.AddPhoto(c.Request.Context(), uint(serviceOrderID), upload, file)
+	if err != nil {
+		appErr := mapCheckInError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, photo)
+}
+
+// GetPhoto godoc
+// @Summary Download a check-in photo
+// @Description Stream a photo of the check-in. Customers can only see the photos of their own orders.
+// @Tags Check-in
+// @Security Bearer
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce image/webp
+// @Param id path int true "Service order ID"
+// @Param photoId path int true "Photo ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 403 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/check-in/photos/{photoId} [get]
+func (h *CheckInHandler) GetPhoto(c *gin.Context) {
+	serviceOrderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+	photoID, err := strconv.ParseUint(c.Param("photoId"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidPhotoID.HTTPStatus, errInvalidPhotoID.ToHTTPError())
+		return
+	}
+
+	photo, content, err := h.usecase.OpenPhoto(c.Request.Context(), uint(serviceOrderID), uint(photoID), c.GetString(middleware.ContextUserEmail), viewerRole(c))
+	if err != nil {
+		appErr := mapCheckInError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+	defer content.Close()
+
+	streamFile(c, "inline", photo.FileName, photo.ContentType, photo.Size, content)
+}
+
+// DeletePhoto godoc
+// @Summary Delete a check-in photo
+// @Description Remove a photo of the check-in while the service order is RECEBIDA
+// @Tags Check-in
+// @Security Bearer
+// @Param id path int true "Service order ID"
+// @Param photoId path int true "Photo ID"
+// @Success 204
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/check-in/photos/{photoId} [delete]
+func (h *CheckInHandler) DeletePhoto(c *gin.Context) {
+	serviceOrderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+	photoID, err := strconv.ParseUint(c.Param("photoId"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidPhotoID.HTTPStatus, errInvalidPhotoID.ToHTTPError())
+		return
+	}
+
+	if err := h.usecase.DeletePhoto(c.Request.Context(), uint(serviceOrderID), uint(photoID)); err != nil {
+		appErr := mapCheckInError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// viewerRole is the role of the authenticated user, as carried by the token
+func viewerRole(c *gin.Context) valueobject.UserType {
+	return valueobject.ParseUserType(c.GetString(middleware.ContextUserRole))
+}
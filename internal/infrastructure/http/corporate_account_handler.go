@@ -0,0 +1,368 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidCorporateContactID   = pkg.NewDomainErrorSimple("INVALID_CONTACT_ID", "Invalid contact ID", http.StatusBadRequest)
+	errInvalidCorporateStatementID = pkg.NewDomainErrorSimple("INVALID_STATEMENT_ID", "Invalid statement ID", http.StatusBadRequest)
+	errInvalidCorporateInput       = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data", http.StatusBadRequest)
+)
+
+// CorporateAccountHandler handles the corporate accounts of the company customers, their contacts
+// and their monthly statements
+// @title Corporate Account API
+// @version 1.0
+// @description API for the corporate accounts of the company customers, billed monthly up to a credit limit
+type CorporateAccountHandler struct {
+	usecase usecase.ICorporateAccountUseCase
+}
+
+func NewCorporateAccountHandler(usecase usecase.ICorporateAccountUseCase) *CorporateAccountHandler {
+	return &CorporateAccountHandler{usecase: usecase}
+}
+
+func mapCorporateAccountError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrCustomerNotFound):
+		return pkg.NewDomainErrorSimple("CUSTOMER_NOT_FOUND", "Customer not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrCorporateAccountNotFound):
+		return pkg.NewDomainErrorSimple("CORPORATE_ACCOUNT_NOT_FOUND", "Corporate account not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrCorporateContactNotFound):
+		return pkg.NewDomainErrorSimple("CORPORATE_CONTACT_NOT_FOUND", "Corporate contact not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrCorporateStatementNotFound):
+		return pkg.NewDomainErrorSimple("CORPORATE_STATEMENT_NOT_FOUND", "Corporate statement not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrCorporateAccountRequiresCNPJ):
+		return pkg.NewDomainErrorSimple("CORPORATE_ACCOUNT_REQUIRES_CNPJ", "Only customers with a CNPJ may have a corporate account", http.StatusUnprocessableEntity)
+	case errors.Is(err, usecase.ErrInvalidCreditLimit):
+		return pkg.NewDomainErrorSimple("INVALID_CREDIT_LIMIT", "The credit limit must be greater than zero", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidCorporateContactRole):
+		return pkg.NewDomainErrorSimple("INVALID_CONTACT_ROLE", "Role must be COMPRAS, FINANCEIRO, GESTOR_FROTA or MOTORISTA", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidStatementMonth):
+		return pkg.NewDomainErrorSimple("INVALID_MONTH", "Month must be in the YYYY-MM format", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrStatementMonthNotOver):
+		return pkg.NewDomainErrorSimple("MONTH_NOT_OVER", "The statements of a month can only be closed once it is over", http.StatusUnprocessableEntity)
+	case errors.Is(err, usecase.ErrCorporateStatementAlreadyPaid):
+		return pkg.NewDomainErrorSimple("CORPORATE_STATEMENT_ALREADY_PAID", "The statement is already paid", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInvalidPaymentMethod):
+		return pkg.NewDomainErrorSimple("INVALID_PAYMENT_METHOD", "Invalid payment method", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrPaymentAmountDoesNotMatch):
+		return pkg.NewDomainErrorSimple("PAYMENT_AMOUNT_MISMATCH", "The estimate of a service order of the statement changed after it was billed", http.StatusConflict)
+	case errors.Is(err, usecase.ErrCashRegisterClosed):
+		return pkg.NewDomainErrorSimple("CASH_REGISTER_CLOSED", "The cash register is already closed for the day", http.StatusConflict)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// GetCorporateAccount godoc
+// @Summary Get the corporate account of a customer
+// @Description Get the corporate account of a company customer with its contacts, credit limit, open balance and available credit. The open balance counts the estimates of the orders not paid yet, cancelled and rejected ones aside.
+// @Tags Corporate Accounts
+// @Security Bearer
+// @Produce json
+// @Param customerID path int true "Customer ID"
+// @Success 200 {object} entities.CorporateAccount
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /corporate-accounts/{customerID} [get]
+func (h *CorporateAccountHandler) GetCorporateAccount(c *gin.Context) {
+	customerID, ok := corporateCustomerID(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.usecase.GetAccount(c.Request.Context(), customerID)
+	if err != nil {
+		appErr := mapCorporateAccountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// SaveCorporateAccount godoc
+// @Summary Open or update the corporate account of a customer
+// @Description Open the corporate account of a company customer, or change its credit limit. Only customers with a CNPJ may have one. The orders of a corporate account can be delivered before they are paid and are billed in a monthly statement; no order can be opened once the open balance reaches the credit limit.
+// @Tags Corporate Accounts
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param customerID path int true "Customer ID"
+// @Param account body entities.CorporateAccountRequest true "Credit limit"
+// @Success 200 {object} entities.CorporateAccount
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 422 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /corporate-accounts/{customerID} [put]
+func (h *CorporateAccountHandler) SaveCorporateAccount(c *gin.Context) {
+	customerID, ok := corporateCustomerID(c)
+	if !ok {
+		return
+	}
+
+	var input entities.CorporateAccountRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidCorporateInput.HTTPStatus, errInvalidCorporateInput.ToHTTPError())
+		return
+	}
+
+	account, err := h.usecase.SaveAccount(c.Request.Context(), customerID, input)
+	if err != nil {
+		appErr := mapCorporateAccountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// AddCorporateContact godoc
+// @Summary Add a contact to a corporate account
+// @Description Add a person the workshop deals with on behalf of the company. When any contact may approve estimates, the estimates of the company must be approved by one of them.
+// @Tags Corporate Accounts
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param customerID path int true "Customer ID"
+// @Param contact body entities.CorporateContactRequest true "Contact"
+// @Success 201 {object} entities.CorporateContact
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /corporate-accounts/{customerID}/contacts [post]
+func (h *CorporateAccountHandler) AddCorporateContact(c *gin.Context) {
+	customerID, ok := corporateCustomerID(c)
+	if !ok {
+		return
+	}
+
+	var input entities.CorporateContactRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidCorporateInput.HTTPStatus, errInvalidCorporateInput.ToHTTPError())
+		return
+	}
+
+	contact, err := h.usecase.AddContact(c.Request.Context(), customerID, input)
+	if err != nil {
+		appErr := mapCorporateAccountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, contact)
+}
+
+// UpdateCorporateContact godoc
+// @Summary Update a contact of a corporate account
+// @Description Update the data, role and approval permission of a contact of the corporate account
+// @Tags Corporate Accounts
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param customerID path int true "Customer ID"
+// @Param contactID path int true "Contact ID"
+// @Param contact body entities.CorporateContactRequest true "Contact"
+// @Success 200 {object} entities.CorporateContact
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /corporate-accounts/{customerID}/contacts/{contactID} [put]
+func (h *CorporateAccountHandler) UpdateCorporateContact(c *gin.Context) {
+	customerID, ok := corporateCustomerID(c)
+	if !ok {
+		return
+	}
+	contactID, err := strconv.ParseUint(c.Param("contactID"), 10, 32)
+	if err != nil || contactID == 0 {
+		c.JSON(errInvalidCorporateContactID.HTTPStatus, errInvalidCorporateContactID.ToHTTPError())
+		return
+	}
+
+	var input entities.CorporateContactRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidCorporateInput.HTTPStatus, errInvalidCorporateInput.ToHTTPError())
+		return
+	}
+
+	contact, err := h.usecase.UpdateContact(c.Request.Context(), customerID, uint(contactID), input)
+	if err != nil {
+		appErr := mapCorporateAccountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// RemoveCorporateContact godoc
+// @Summary Remove a contact from a corporate account
+// @Description Remove a contact of the corporate account; the estimates it approved keep its ID
+// @Tags Corporate Accounts
+// @Security Bearer
+// @Param customerID path int true "Customer ID"
+// @Param contactID path int true "Contact ID"
+// @Success 204
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /corporate-accounts/{customerID}/contacts/{contactID} [delete]
+func (h *CorporateAccountHandler) RemoveCorporateContact(c *gin.Context) {
+	customerID, ok := corporateCustomerID(c)
+	if !ok {
+		return
+	}
+	contactID, err := strconv.ParseUint(c.Param("contactID"), 10, 32)
+	if err != nil || contactID == 0 {
+		c.JSON(errInvalidCorporateContactID.HTTPStatus, errInvalidCorporateContactID.ToHTTPError())
+		return
+	}
+
+	if err := h.usecase.RemoveContact(c.Request.Context(), customerID, uint(contactID)); err != nil {
+		appErr := mapCorporateAccountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListCorporateStatements godoc
+// @Summary List the statements of a corporate account
+// @Description List the monthly statements of the corporate account of a customer, the latest month first, without their orders
+// @Tags Corporate Accounts
+// @Security Bearer
+// @Produce json
+// @Param customerID path int true "Customer ID"
+// @Success 200 {array} entities.CorporateStatement
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /corporate-accounts/{customerID}/statements [get]
+func (h *CorporateAccountHandler) ListCorporateStatements(c *gin.Context) {
+	customerID, ok := corporateCustomerID(c)
+	if !ok {
+		return
+	}
+
+	statements, err := h.usecase.ListStatements(c.Request.Context(), customerID)
+	if err != nil {
+		appErr := mapCorporateAccountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, statements)
+}
+
+// CloseCorporateStatements godoc
+// @Summary Close the monthly statements
+// @Description Bill the orders delivered to each corporate account up to the end of the month and not paid yet into one statement per account. The statements are closed daily for the previous month; closing a month again only adds the accounts still missing its statement.
+// @Tags Corporate Accounts
+// @Security Bearer
+// @Produce json
+// @Param month query string false "Month in the YYYY-MM format, the previous month by default"
+// @Success 200 {array} entities.CorporateStatement
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 422 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /corporate-statements/close [post]
+func (h *CorporateAccountHandler) CloseCorporateStatements(c *gin.Context) {
+	statements, err := h.usecase.CloseStatements(c.Request.Context(), c.Query("month"))
+	if err != nil {
+		appErr := mapCorporateAccountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, statements)
+}
+
+// GetCorporateStatement godoc
+// @Summary Get a corporate statement
+// @Description Get a monthly statement with the orders billed in it
+// @Tags Corporate Accounts
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Statement ID"
+// @Success 200 {object} entities.CorporateStatement
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /corporate-statements/{id} [get]
+func (h *CorporateAccountHandler) GetCorporateStatement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidCorporateStatementID.HTTPStatus, errInvalidCorporateStatementID.ToHTTPError())
+		return
+	}
+
+	statement, err := h.usecase.GetStatement(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapCorporateAccountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// PayCorporateStatement godoc
+// @Summary Pay a corporate statement
+// @Description Record the payment of a monthly statement, registering the payment of each of its orders with the given method on today's cash register
+// @Tags Corporate Accounts
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Statement ID"
+// @Param payment body entities.CorporateStatementPaymentRequest true "Payment method"
+// @Success 200 {object} entities.CorporateStatement
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /corporate-statements/{id}/pay [post]
+func (h *CorporateAccountHandler) PayCorporateStatement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidCorporateStatementID.HTTPStatus, errInvalidCorporateStatementID.ToHTTPError())
+		return
+	}
+
+	var input entities.CorporateStatementPaymentRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidCorporateInput.HTTPStatus, errInvalidCorporateInput.ToHTTPError())
+		return
+	}
+
+	statement, err := h.usecase.PayStatement(c.Request.Context(), uint(id), input.Method, c.GetString(middleware.ContextUserEmail))
+	if err != nil {
+		appErr := mapCorporateAccountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// corporateCustomerID reads the customer ID of the path, answering the request when it is invalid
+func corporateCustomerID(c *gin.Context) (uint, bool) {
+	customerID, err := strconv.ParseUint(c.Param("customerID"), 10, 32)
+	if err != nil || customerID == 0 {
+		c.JSON(errInvalidCustomerID.HTTPStatus, errInvalidCustomerID.ToHTTPError())
+		return 0, false
+	}
+	return uint(customerID), true
+}
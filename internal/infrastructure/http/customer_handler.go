@@ -93,7 +93,7 @@ func (h *CustomerHandler) GetFullCustomer(c *gin.Context) {
 
 // CreateCustomer godoc
 // @Summary Create a new customer
-// @Description Creates a new customer record
+// @Description Creates a new customer record. The document is stored without its mask and cannot belong to another customer.
 // @Tags Customers
 // @Security Bearer
 // @Accept json
@@ -101,6 +101,7 @@ func (h *CustomerHandler) GetFullCustomer(c *gin.Context) {
 // @Param vehicle body entities.Customer true "Customer information"
 // @Success 201 {object} entities.Customer
 // @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 409 {object} map[string]string "Document already registered"
 // @Failure 500 {object} map[string]string "error message"
 // @Router /customers [post]
 func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidCustomerMergeInput = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data, duplicate_id is required", http.StatusBadRequest)
+
+// CustomerMergeHandler handles the duplicate customers and their merge
+// @title Customer Merge API
+// @version 1.0
+// @description API to find the customers registered more than once and merge them
+type CustomerMergeHandler struct {
+	usecase usecase.ICustomerMergeUseCase
+}
+
+func NewCustomerMergeHandler(usecase usecase.ICustomerMergeUseCase) *CustomerMergeHandler {
+	return &CustomerMergeHandler{usecase: usecase}
+}
+
+func mapCustomerMergeError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrCustomerNotFound):
+		return pkg.NewDomainErrorSimple("CUSTOMER_NOT_FOUND", "Customer not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrMergeSameCustomer):
+		return pkg.NewDomainErrorSimple("MERGE_SAME_CUSTOMER", "A customer cannot be merged into itself", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrMergeCorporateAccounts):
+		return pkg.NewDomainErrorSimple("MERGE_CORPORATE_ACCOUNTS", "Both customers have a corporate account, close one of them first", http.StatusConflict)
+	case errors.Is(err, usecase.ErrMergedCustomerGone):
+		return pkg.NewDomainErrorSimple("CUSTOMER_ALREADY_MERGED", "The duplicate customer was merged or deleted in the meantime", http.StatusConflict)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// FindDuplicateCustomers godoc
+// @Summary Find duplicate customers
+// @Description List the groups of customers sharing a document or a phone number, or with similar names, oldest customer first. A customer may be in several groups.
+// @Tags Customers
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} entities.CustomerDuplicateGroup
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /customers/duplicates [get]
+func (h *CustomerMergeHandler) FindDuplicateCustomers(c *gin.Context) {
+	groups, err := h.usecase.FindDuplicates(c.Request.Context())
+	if err != nil {
+		appErr := mapCustomerMergeError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// MergeCustomers godoc
+// @Summary Merge a duplicate customer
+// @Description Move the vehicles, service orders with their payments, appointments, price agreements and corporate account of the duplicate onto the customer, then delete the duplicate and its user. The merge is recorded with what the duplicate was registered as.
+// @Tags Customers
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "ID of the customer who is kept"
+// @Param merge body entities.CustomerMergeRequest true "Duplicate customer"
+// @Success 201 {object} entities.CustomerMerge
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /customers/{id}/merge [post]
+func (h *CustomerMergeHandler) MergeCustomers(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || customerID == 0 {
+		c.JSON(errInvalidCustomerID.HTTPStatus, errInvalidCustomerID.ToHTTPError())
+		return
+	}
+
+	var input entities.CustomerMergeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidCustomerMergeInput.HTTPStatus, errInvalidCustomerMergeInput.ToHTTPError())
+		return
+	}
+
+	merge, err := h.usecase.MergeCustomers(c.Request.Context(), uint(customerID), input, c.GetString(middleware.ContextUserEmail))
+	if err != nil {
+		appErr := mapCustomerMergeError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, merge)
+}
+
+// ListCustomerMerges godoc
+// @Summary List the customer merges
+// @Description List the merges of duplicate customers, newest first, optionally only those into a customer
+// @Tags Customers
+// @Security Bearer
+// @Produce json
+// @Param customer_id query int false "ID of the customer who was kept"
+// @Success 200 {array} entities.CustomerMerge
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /customer-merges [get]
+func (h *CustomerMergeHandler) ListCustomerMerges(c *gin.Context) {
+	var customerID uint
+	if value := c.Query("customer_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(errInvalidCustomerID.HTTPStatus, errInvalidCustomerID.ToHTTPError())
+			return
+		}
+		customerID = uint(id)
+	}
+
+	merges, err := h.usecase.ListMerges(c.Request.Context(), customerID)
+	if err != nil {
+		appErr := mapCustomerMergeError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, merges)
+}
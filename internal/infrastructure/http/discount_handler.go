@@ -0,0 +1,260 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidCouponInput         = pkg.NewDomainErrorSimple("INVALID_COUPON_INPUT", "Invalid coupon input", http.StatusBadRequest)
+	errInvalidPriceAgreementInput = pkg.NewDomainErrorSimple("INVALID_PRICE_AGREEMENT_INPUT", "Invalid price agreement input", http.StatusBadRequest)
+	errInvalidPriceAgreementID    = pkg.NewDomainErrorSimple("INVALID_PRICE_AGREEMENT_ID", "Invalid price agreement ID", http.StatusBadRequest)
+	errInvalidServiceOrderID      = pkg.NewDomainErrorSimple("INVALID_SERVICE_ORDER_ID", "Invalid service order ID", http.StatusBadRequest)
+	errInvalidCustomerID          = pkg.NewDomainErrorSimple("INVALID_CUSTOMER_ID", "Invalid customer ID", http.StatusBadRequest)
+)
+
+// DiscountHandler handles HTTP requests for coupons, negotiated prices and discount approvals
+// @title Discount API
+// @version 1.0
+// @description API for managing discounts in the workshop management system
+type DiscountHandler struct {
+	usecase usecase.IDiscountUseCase
+}
+
+func NewDiscountHandler(usecase usecase.IDiscountUseCase) *DiscountHandler {
+	return &DiscountHandler{usecase: usecase}
+}
+
+func mapDiscountError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrCouponNotFound):
+		return pkg.NewDomainErrorSimple("COUPON_NOT_FOUND", "Coupon not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrCouponAlreadyExists):
+		return pkg.NewDomainErrorSimple("COUPON_ALREADY_EXISTS", "Coupon already exists", http.StatusConflict)
+	case errors.Is(err, usecase.ErrCouponNotValid):
+		return pkg.NewDomainErrorSimple("COUPON_NOT_VALID", "Coupon is not valid", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidDiscount):
+		return pkg.NewDomainErrorSimple("INVALID_DISCOUNT", "Invalid discount", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidPriceAgreement):
+		return pkg.NewDomainErrorSimple("INVALID_PRICE_AGREEMENT", "Price agreement must reference exactly one service or parts supply with a positive price", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrCustomerNotFound):
+		return pkg.NewDomainErrorSimple("CUSTOMER_NOT_FOUND", "Customer not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrServiceNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_NOT_FOUND", "Service not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrPartsSupplyNotFound):
+		return pkg.NewDomainErrorSimple("PARTS_SUPPLY_NOT_FOUND", "Parts supply not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrServiceOrderNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_FOUND", "Service order not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrDiscountApprovalNotRequired):
+		return pkg.NewDomainErrorSimple("DISCOUNT_APPROVAL_NOT_REQUIRED", "Service order has no discount pending approval", http.StatusConflict)
+	case errors.Is(err, usecase.ErrDiscountApprovalForbidden):
+		return pkg.NewDomainErrorSimple("DISCOUNT_APPROVAL_FORBIDDEN", "Only admins and managers can approve discounts", http.StatusForbidden)
+	case errors.Is(err, usecase.ErrInvalidID):
+		return pkg.NewDomainErrorSimple("INVALID_ID", "Invalid ID", http.StatusBadRequest)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// CreateCoupon godoc
+// @Summary Create a new coupon
+// @Description Create a reusable coupon code with validity period and usage limit (max_uses 0 means unlimited)
+// @Tags Discounts
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param coupon body entities.Coupon true "Coupon Information"
+// @Success 201 {object} entities.Coupon
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /coupons [post]
+func (h *DiscountHandler) CreateCoupon(c *gin.Context) {
+	var input entities.Coupon
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidCouponInput.HTTPStatus, errInvalidCouponInput.ToHTTPError())
+		return
+	}
+
+	coupon, err := h.usecase.CreateCoupon(c.Request.Context(), &input)
+	if err != nil {
+		appErr := mapDiscountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, coupon)
+}
+
+// UpdateCoupon godoc
+// @Summary Update a coupon
+// @Description Update the description, validity, usage limit or activation of a coupon
+// @Tags Discounts
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param code path string true "Coupon code"
+// @Param coupon body entities.Coupon true "Coupon Information"
+// @Success 204 "No Content"
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /coupons/{code} [put]
+func (h *DiscountHandler) UpdateCoupon(c *gin.Context) {
+	var input entities.Coupon
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidCouponInput.HTTPStatus, errInvalidCouponInput.ToHTTPError())
+		return
+	}
+	input.Code = c.Param("code")
+
+	if err := h.usecase.UpdateCoupon(c.Request.Context(), &input); err != nil {
+		appErr := mapDiscountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListCoupons godoc
+// @Summary List all coupons
+// @Description Get a list of all coupons
+// @Tags Discounts
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} entities.Coupon
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /coupons [get]
+func (h *DiscountHandler) ListCoupons(c *gin.Context) {
+	coupons, err := h.usecase.ListCoupons(c.Request.Context())
+	if err != nil {
+		appErr := mapDiscountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, coupons)
+}
+
+// CreatePriceAgreement godoc
+// @Summary Create a price agreement
+// @Description Create a negotiated unit price of a service or parts supply for a fleet customer
+// @Tags Discounts
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param agreement body entities.PriceAgreement true "Price Agreement Information"
+// @Success 201 {object} entities.PriceAgreement
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /price-agreements [post]
+func (h *DiscountHandler) CreatePriceAgreement(c *gin.Context) {
+	var input entities.PriceAgreement
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidPriceAgreementInput.HTTPStatus, errInvalidPriceAgreementInput.ToHTTPError())
+		return
+	}
+
+	agreement, err := h.usecase.CreatePriceAgreement(c.Request.Context(), &input)
+	if err != nil {
+		appErr := mapDiscountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, agreement)
+}
+
+// ListPriceAgreements godoc
+// @Summary List price agreements of a customer
+// @Description Get the negotiated prices of a customer
+// @Tags Discounts
+// @Security Bearer
+// @Produce json
+// @Param customerID path int true "Customer ID"
+// @Success 200 {array} entities.PriceAgreement
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /price-agreements/customer/{customerID} [get]
+func (h *DiscountHandler) ListPriceAgreements(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("customerID"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidCustomerID.HTTPStatus, errInvalidCustomerID.ToHTTPError())
+		return
+	}
+
+	agreements, err := h.usecase.ListPriceAgreements(c.Request.Context(), uint(customerID))
+	if err != nil {
+		appErr := mapDiscountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, agreements)
+}
+
+// DeletePriceAgreement godoc
+// @Summary Delete a price agreement
+// @Description Delete a negotiated price by its ID
+// @Tags Discounts
+// @Security Bearer
+// @Param id path int true "Price Agreement ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /price-agreements/{id} [delete]
+func (h *DiscountHandler) DeletePriceAgreement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidPriceAgreementID.HTTPStatus, errInvalidPriceAgreementID.ToHTTPError())
+		return
+	}
+
+	if err := h.usecase.DeletePriceAgreement(c.Request.Context(), uint(id)); err != nil {
+		appErr := mapDiscountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ApproveDiscount godoc
+// @Summary Approve the discounts of a service order
+// @Description Admin or manager sign-off of manual discounts above the approval threshold, required before the customer can approve the estimate
+// @Tags Discounts
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Success 200 {object} entities.ServiceOrder
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 403 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/discount-approval [patch]
+func (h *DiscountHandler) ApproveDiscount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	serviceOrder, err := h.usecase.ApproveDiscount(c.Request.Context(), uint(id), c.GetString(middleware.ContextUserEmail))
+	if err != nil {
+		appErr := mapDiscountError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, serviceOrder)
+}
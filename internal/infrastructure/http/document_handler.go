@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DocumentHandler handles HTTP requests for the printable documents of service orders
+// @title Document API
+// @version 1.0
+// @description API for printing estimates, service orders and receipts in the workshop management system
+type DocumentHandler struct {
+	usecase usecase.IDocumentUseCase
+}
+
+func NewDocumentHandler(usecase usecase.IDocumentUseCase) *DocumentHandler {
+	return &DocumentHandler{usecase: usecase}
+}
+
+func mapDocumentError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidDocumentType):
+		return pkg.NewDomainErrorSimple("INVALID_DOCUMENT_TYPE", "Document type must be estimate, service-order or receipt", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrServiceOrderNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_FOUND", "Service order not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrEstimateNotAvailable):
+		return pkg.NewDomainErrorSimple("ESTIMATE_NOT_AVAILABLE", "Estimate is only available once the service order is awaiting approval", http.StatusConflict)
+	case errors.Is(err, usecase.ErrReceiptNotAvailable):
+		return pkg.NewDomainErrorSimple("RECEIPT_NOT_AVAILABLE", "Receipt is only available once the service order is paid", http.StatusConflict)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// GetDocument godoc
+// @Summary Print a service order document
+// @Description Generate the PDF of the estimate (from AGUARDANDO APROVAÇÃO on), the service order sheet or the payment receipt
+// @Tags Documents
+// @Security Bearer
+// @Produce application/pdf
+// @Param id path int true "Service Order ID"
+// @Param type path string true "Document type" Enums(estimate, service-order, receipt)
+// @Success 200 {file} file "PDF document"
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/documents/{type} [get]
+func (h *DocumentHandler) GetDocument(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	document, err := h.usecase.GenerateDocument(c.Request.Context(), uint(id), valueobject.ParseDocumentType(c.Param("type")))
+	if err != nil {
+		appErr := mapDocumentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename=\""+document.FileName+"\"")
+	c.Data(http.StatusOK, document.ContentType, document.Content)
+}
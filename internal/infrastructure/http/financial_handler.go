@@ -0,0 +1,310 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidCashClosingInput = pkg.NewDomainErrorSimple("INVALID_CASH_CLOSING_INPUT", "Invalid cash closing input, date must be in the format YYYY-MM-DD", http.StatusBadRequest)
+	errInvalidCashClosingDate  = pkg.NewDomainErrorSimple("INVALID_CASH_CLOSING_DATE", "Cash closing date must be in the format YYYY-MM-DD", http.StatusBadRequest)
+)
+
+// CloseCashRegisterRequest is the body of a cash register closing. The date defaults to today.
+type CloseCashRegisterRequest struct {
+	Date  string `json:"date,omitempty" example:"2025-08-15"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// FinancialHandler handles HTTP requests for the financial reports and the daily cash closing
+// @title Financial API
+// @version 1.0
+// @description API for financial reports in the workshop management system
+type FinancialHandler struct {
+	usecase usecase.IFinancialUseCase
+}
+
+func NewFinancialHandler(usecase usecase.IFinancialUseCase) *FinancialHandler {
+	return &FinancialHandler{usecase: usecase}
+}
+
+func mapFinancialError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidReportPeriod):
+		return pkg.NewDomainErrorSimple("INVALID_REPORT_PERIOD", "Report end date must not be before its start date", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrCashRegisterAlreadyClosed):
+		return pkg.NewDomainErrorSimple("CASH_REGISTER_ALREADY_CLOSED", "Cash register is already closed for this day", http.StatusConflict)
+	case errors.Is(err, usecase.ErrCashClosingInFuture):
+		return pkg.NewDomainErrorSimple("CASH_CLOSING_IN_FUTURE", "Cash register cannot be closed for a future day", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrCashClosingNotFound):
+		return pkg.NewDomainErrorSimple("CASH_CLOSING_NOT_FOUND", "Cash closing not found", http.StatusNotFound)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// parsePeriod reads the from and to query params; the end date is inclusive
+func parsePeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	from, errFrom := time.ParseInLocation(reportDateLayout, c.Query("from"), time.Local)
+	to, errTo := time.ParseInLocation(reportDateLayout, c.Query("to"), time.Local)
+	if errFrom != nil || errTo != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to.AddDate(0, 0, 1).Add(-time.Nanosecond), true
+}
+
+// GetPaymentReport godoc
+// @Summary Payments report
+// @Description Get the payments received within a period, totaled per day, method and operator
+// @Tags Financial
+// @Security Bearer
+// @Produce json
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD)"
+// @Success 200 {object} entities.PaymentReport
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /reports/payments [get]
+func (h *FinancialHandler) GetPaymentReport(c *gin.Context) {
+	from, to, ok := parsePeriod(c)
+	if !ok {
+		c.JSON(errInvalidReportPeriod.HTTPStatus, errInvalidReportPeriod.ToHTTPError())
+		return
+	}
+
+	report, err := h.usecase.GetPaymentReport(c.Request.Context(), from, to)
+	if err != nil {
+		appErr := mapFinancialError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetReceivables godoc
+// @Summary Outstanding receivables
+// @Description Get the finished (FINALIZADA) service orders that are not fully paid yet
+// @Tags Financial
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} entities.ReceivablesReport
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /reports/receivables [get]
+func (h *FinancialHandler) GetReceivables(c *gin.Context) {
+	report, err := h.usecase.GetReceivables(c.Request.Context())
+	if err != nil {
+		appErr := mapFinancialError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetRevenueReport godoc
+// @Summary Revenue report
+// @Description Get the revenue of the service orders paid within a period, split between services and parts
+// @Tags Financial
+// @Security Bearer
+// @Produce json
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD)"
+// @Success 200 {object} entities.RevenueReport
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /reports/revenue [get]
+func (h *FinancialHandler) GetRevenueReport(c *gin.Context) {
+	from, to, ok := parsePeriod(c)
+	if !ok {
+		c.JSON(errInvalidReportPeriod.HTTPStatus, errInvalidReportPeriod.ToHTTPError())
+		return
+	}
+
+	report, err := h.usecase.GetRevenueReport(c.Request.Context(), from, to)
+	if err != nil {
+		appErr := mapFinancialError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// CloseCashRegister godoc
+// @Summary Close the cash register
+// @Description Record the payment totals of a day and lock its payments from further edits. A day can only be closed once.
+// @Tags Financial
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body CloseCashRegisterRequest false "Day to close (defaults to today) and notes"
+// @Success 201 {object} entities.CashClosing
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /cash-closings [post]
+func (h *FinancialHandler) CloseCashRegister(c *gin.Context) {
+	var input CloseCashRegisterRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(errInvalidCashClosingInput.HTTPStatus, errInvalidCashClosingInput.ToHTTPError())
+			return
+		}
+	}
+
+	date := time.Now()
+	if input.Date != "" {
+		parsed, err := time.ParseInLocation(reportDateLayout, input.Date, time.Local)
+		if err != nil {
+			c.JSON(errInvalidCashClosingInput.HTTPStatus, errInvalidCashClosingInput.ToHTTPError())
+			return
+		}
+		date = parsed
+	}
+
+	closing, err := h.usecase.CloseCashRegister(c.Request.Context(), date, c.GetString(middleware.ContextUserEmail), input.Notes)
+	if err != nil {
+		appErr := mapFinancialError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, closing)
+}
+
+// ListCashClosings godoc
+// @Summary List cash closings
+// @Description Get the cash closings of a period
+// @Tags Financial
+// @Security Bearer
+// @Produce json
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD)"
+// @Success 200 {array} entities.CashClosing
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /cash-closings [get]
+func (h *FinancialHandler) ListCashClosings(c *gin.Context) {
+	from, to, ok := parsePeriod(c)
+	if !ok {
+		c.JSON(errInvalidReportPeriod.HTTPStatus, errInvalidReportPeriod.ToHTTPError())
+		return
+	}
+
+	closings, err := h.usecase.ListCashClosings(c.Request.Context(), from, to)
+	if err != nil {
+		appErr := mapFinancialError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, closings)
+}
+
+// GetCashClosing godoc
+// @Summary Get a cash closing
+// @Description Get the closing record of a day with the payments it locked
+// @Tags Financial
+// @Security Bearer
+// @Produce json
+// @Param date path string true "Closed day (YYYY-MM-DD)"
+// @Success 200 {object} entities.CashClosing
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /cash-closings/{date} [get]
+func (h *FinancialHandler) GetCashClosing(c *gin.Context) {
+	closing, ok := h.getCashClosing(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, closing)
+}
+
+// ExportCashClosing godoc
+// @Summary Export a cash closing
+// @Description Download the closing record of a day as CSV: the locked payments followed by the totals per method and operator
+// @Tags Financial
+// @Security Bearer
+// @Produce text/csv
+// @Param date path string true "Closed day (YYYY-MM-DD)"
+// @Success 200 {file} file "CSV file"
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /cash-closings/{date}/export [get]
+func (h *FinancialHandler) ExportCashClosing(c *gin.Context) {
+	closing, ok := h.getCashClosing(c)
+	if !ok {
+		return
+	}
+
+	content, err := cashClosingCSV(closing)
+	if err != nil {
+		appErr := mapFinancialError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"fechamento-caixa-%s.csv\"", closing.Date.Format(reportDateLayout)))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", content)
+}
+
+func (h *FinancialHandler) getCashClosing(c *gin.Context) (*entities.CashClosing, bool) {
+	date, err := time.ParseInLocation(reportDateLayout, c.Param("date"), time.Local)
+	if err != nil {
+		c.JSON(errInvalidCashClosingDate.HTTPStatus, errInvalidCashClosingDate.ToHTTPError())
+		return nil, false
+	}
+
+	closing, err := h.usecase.GetCashClosing(c.Request.Context(), date)
+	if err != nil {
+		appErr := mapFinancialError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return nil, false
+	}
+	return closing, true
+}
+
+func cashClosingCSV(closing *entities.CashClosing) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+	records := [][]string{
+		{"fechamento", "data", "fechado_por", "fechado_em", "pagamentos", "total"},
+		{strconv.FormatUint(uint64(closing.ID), 10), closing.Date.Format(reportDateLayout), closing.ClosedBy,
+			closing.CreatedAt.Format(time.RFC3339), strconv.FormatInt(closing.PaymentCount, 10), money(closing.Total)},
+		{},
+		{"pagamento", "ordem_servico", "data_pagamento", "metodo", "operador", "valor"},
+	}
+	for _, p := range closing.Payments {
+		records = append(records, []string{strconv.FormatUint(uint64(p.ID), 10), strconv.FormatUint(uint64(p.ServiceOrderID), 10),
+			p.PaymentDate.Format(time.RFC3339), p.Method.String(), p.Operator, money(p.Amount)})
+	}
+	records = append(records, []string{}, []string{"metodo", "operador", "quantidade", "valor"})
+	for _, l := range closing.Lines {
+		records = append(records, []string{l.Method.String(), l.Operator, strconv.FormatInt(l.Count, 10), money(l.Amount)})
+	}
+	if closing.Notes != "" {
+		records = append(records, []string{}, []string{"observacoes", closing.Notes})
+	}
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
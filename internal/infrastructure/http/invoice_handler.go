@@ -0,0 +1,217 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidInvoiceID           = pkg.NewDomainErrorSimple("INVALID_INVOICE_ID", "Invalid invoice ID", http.StatusBadRequest)
+	errInvalidInvoiceCancellation = pkg.NewDomainErrorSimple("INVALID_INVOICE_CANCELLATION_INPUT", "Invalid invoice cancellation input", http.StatusBadRequest)
+)
+
+// CancelInvoiceRequest is the body of an invoice cancellation
+type CancelInvoiceRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// InvoiceHandler handles HTTP requests for the electronic invoices (NFS-e and NF-e) of service orders
+// @title Invoice API
+// @version 1.0
+// @description API for issuing invoices in the workshop management system
+type InvoiceHandler struct {
+	usecase usecase.IInvoiceUseCase
+}
+
+func NewInvoiceHandler(usecase usecase.IInvoiceUseCase) *InvoiceHandler {
+	return &InvoiceHandler{usecase: usecase}
+}
+
+func mapInvoiceError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrInvoiceNotFound):
+		return pkg.NewDomainErrorSimple("INVOICE_NOT_FOUND", "Invoice not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrServiceOrderNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_FOUND", "Service order not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrServiceOrderNotDelivered):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_DELIVERED", "Service order must be delivered to issue invoices", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInvoiceAlreadyIssued):
+		return pkg.NewDomainErrorSimple("INVOICE_ALREADY_ISSUED", "Service order invoices were already issued", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInvoiceWithoutItems):
+		return pkg.NewDomainErrorSimple("INVOICE_WITHOUT_ITEMS", "Service order has no services or parts supplies to invoice", http.StatusUnprocessableEntity)
+	case errors.Is(err, usecase.ErrCustomerDocumentMissing):
+		return pkg.NewDomainErrorSimple("CUSTOMER_DOCUMENT_MISSING", "Customer CPF/CNPJ is required to issue invoices", http.StatusUnprocessableEntity)
+	case errors.Is(err, usecase.ErrInvoiceNotIssued):
+		return pkg.NewDomainErrorSimple("INVOICE_NOT_ISSUED", "Only issued invoices can be cancelled", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInvalidCancellationReason):
+		return pkg.NewDomainErrorSimple("INVALID_CANCELLATION_REASON", "Cancellation reason must have between 15 and 255 characters", http.StatusBadRequest)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// IssueInvoices godoc
+// @Summary Issue the invoices of a service order
+// @Description Issue the NFS-e of the services and the NF-e of the parts supplies of a delivered service order. Invoices already issued are skipped, rejected ones are issued again.
+// @Tags Invoices
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Success 201 {array} entities.Invoice
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 422 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/invoices [post]
+func (h *InvoiceHandler) IssueInvoices(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	invoices, err := h.usecase.IssueInvoices(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapInvoiceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, invoices)
+}
+
+// ListInvoices godoc
+// @Summary List the invoices of a service order
+// @Description Get every invoice of a service order, including rejected and cancelled ones
+// @Tags Invoices
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Success 200 {array} entities.Invoice
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/invoices [get]
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	invoices, err := h.usecase.ListInvoices(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapInvoiceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+// GetInvoice godoc
+// @Summary Get an invoice
+// @Description Get an invoice by its ID
+// @Tags Invoices
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Invoice ID"
+// @Success 200 {object} entities.Invoice
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /invoices/{id} [get]
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidInvoiceID.HTTPStatus, errInvalidInvoiceID.ToHTTPError())
+		return
+	}
+
+	invoice, err := h.usecase.GetInvoice(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapInvoiceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// GetInvoiceXML godoc
+// @Summary Download the XML of an invoice
+// @Description Get the signed XML of an invoice, as submitted to the tax authority
+// @Tags Invoices
+// @Security Bearer
+// @Produce xml
+// @Param id path int true "Invoice ID"
+// @Success 200 {string} string "Signed XML"
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /invoices/{id}/xml [get]
+func (h *InvoiceHandler) GetInvoiceXML(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidInvoiceID.HTTPStatus, errInvalidInvoiceID.ToHTTPError())
+		return
+	}
+
+	invoice, err := h.usecase.GetInvoice(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapInvoiceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+	if invoice.XML == "" {
+		appErr := mapInvoiceError(usecase.ErrInvoiceNotFound)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\""+invoice.Type.String()+"-"+strconv.FormatUint(id, 10)+".xml\"")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", []byte(invoice.XML))
+}
+
+// CancelInvoice godoc
+// @Summary Cancel an invoice
+// @Description Cancel an issued invoice with the tax authority
+// @Tags Invoices
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Invoice ID"
+// @Param request body CancelInvoiceRequest true "Cancellation reason (15 to 255 characters)"
+// @Success 200 {object} entities.Invoice
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /invoices/{id}/cancel [post]
+func (h *InvoiceHandler) CancelInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidInvoiceID.HTTPStatus, errInvalidInvoiceID.ToHTTPError())
+		return
+	}
+
+	var input CancelInvoiceRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidInvoiceCancellation.HTTPStatus, errInvalidInvoiceCancellation.ToHTTPError())
+		return
+	}
+
+	invoice, err := h.usecase.CancelInvoice(c.Request.Context(), uint(id), input.Reason)
+	if err != nil {
+		appErr := mapInvoiceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
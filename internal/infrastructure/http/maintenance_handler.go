@@ -0,0 +1,182 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidMaintenancePlanInput = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data", http.StatusBadRequest)
+
+// MaintenanceHandler handles the preventive maintenance plans of the services and when the
+// vehicles are due for them
+// @title Maintenance API
+// @version 1.0
+// @description API for the preventive maintenance plans and the maintenance due of the vehicles
+type MaintenanceHandler struct {
+	usecase usecase.IMaintenanceUseCase
+}
+
+func NewMaintenanceHandler(usecase usecase.IMaintenanceUseCase) *MaintenanceHandler {
+	return &MaintenanceHandler{usecase: usecase}
+}
+
+func mapMaintenanceError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrMaintenancePlanNotFound):
+		return pkg.NewDomainErrorSimple("MAINTENANCE_PLAN_NOT_FOUND", "Maintenance plan not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidMaintenancePlan):
+		return pkg.NewDomainErrorSimple("INVALID_MAINTENANCE_PLAN", "Interval in kilometres or months is required and must not be negative", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrServiceNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_NOT_FOUND", "Service not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrVehicleNotFound):
+		return pkg.NewDomainErrorSimple("VEHICLE_NOT_FOUND", "Vehicle not found", http.StatusNotFound)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// ListMaintenancePlans godoc
+// @Summary List the maintenance plans
+// @Description List the preventive maintenance plans of the services of the catalog
+// @Tags Maintenance
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} entities.MaintenancePlan
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /maintenance-plans [get]
+func (h *MaintenanceHandler) ListMaintenancePlans(c *gin.Context) {
+	plans, err := h.usecase.ListPlans(c.Request.Context())
+	if err != nil {
+		appErr := mapMaintenanceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// GetMaintenancePlan godoc
+// @Summary Get the maintenance plan of a service
+// @Tags Maintenance
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} entities.MaintenancePlan
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service/{id}/maintenance-plan [get]
+func (h *MaintenanceHandler) GetMaintenancePlan(c *gin.Context) {
+	serviceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidServiceID.HTTPStatus, errInvalidServiceID.ToHTTPError())
+		return
+	}
+
+	plan, err := h.usecase.GetPlan(c.Request.Context(), uint(serviceID))
+	if err != nil {
+		appErr := mapMaintenanceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// SaveMaintenancePlan godoc
+// @Summary Set the maintenance plan of a service
+// @Description Create, or replace, how often the service should be repeated on a vehicle, in kilometres, months or both, whichever comes first
+// @Tags Maintenance
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param plan body entities.MaintenancePlanRequest true "Maintenance plan"
+// @Success 200 {object} entities.MaintenancePlan
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service/{id}/maintenance-plan [put]
+func (h *MaintenanceHandler) SaveMaintenancePlan(c *gin.Context) {
+	serviceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidServiceID.HTTPStatus, errInvalidServiceID.ToHTTPError())
+		return
+	}
+
+	var input entities.MaintenancePlanRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidMaintenancePlanInput.HTTPStatus, errInvalidMaintenancePlanInput.ToHTTPError())
+		return
+	}
+
+	plan, err := h.usecase.SavePlan(c.Request.Context(), uint(serviceID), input)
+	if err != nil {
+		appErr := mapMaintenanceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// DeleteMaintenancePlan godoc
+// @Summary Delete the maintenance plan of a service
+// @Tags Maintenance
+// @Security Bearer
+// @Param id path int true "Service ID"
+// @Success 204
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service/{id}/maintenance-plan [delete]
+func (h *MaintenanceHandler) DeleteMaintenancePlan(c *gin.Context) {
+	serviceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidServiceID.HTTPStatus, errInvalidServiceID.ToHTTPError())
+		return
+	}
+
+	if err := h.usecase.DeletePlan(c.Request.Context(), uint(serviceID)); err != nil {
+		appErr := mapMaintenanceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetVehicleMaintenance godoc
+// @Summary Get the maintenance due of a vehicle
+// @Description For every maintenance plan, the last time the service was performed on the vehicle, the date and mileage it is next due and whether it is up to date, due soon or overdue. The mileage is compared with the last odometer read at a check-in.
+// @Tags Vehicles
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Success 200 {object} entities.VehicleMaintenance
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /vehicles/{id}/maintenance [get]
+func (h *MaintenanceHandler) GetVehicleMaintenance(c *gin.Context) {
+	vehicleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidVehicleID.HTTPStatus, errInvalidVehicleID.ToHTTPError())
+		return
+	}
+
+	maintenance, err := h.usecase.GetVehicleMaintenance(c.Request.Context(), uint(vehicleID))
+	if err != nil {
+		appErr := mapMaintenanceError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, maintenance)
+}
@@ -0,0 +1,421 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidMechanicID           = pkg.NewDomainErrorSimple("INVALID_MECHANIC_ID", "Invalid mechanic ID", http.StatusBadRequest)
+	errInvalidMechanicInput        = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data", http.StatusBadRequest)
+	errInvalidMechanicAssignmentID = pkg.NewDomainErrorSimple("INVALID_MECHANIC_ASSIGNMENT_ID", "Invalid mechanic assignment ID", http.StatusBadRequest)
+	errInvalidLabourServiceID      = pkg.NewDomainErrorSimple("INVALID_SERVICE_ID", "Invalid service ID", http.StatusBadRequest)
+)
+
+// MechanicHandler handles the mechanics, their assignment to service orders and the time they spend on each service
+// @title Mechanic API
+// @version 1.0
+// @description API for mechanics and labour time tracking in the workshop management system
+type MechanicHandler struct {
+	usecase usecase.IMechanicUseCase
+}
+
+func NewMechanicHandler(usecase usecase.IMechanicUseCase) *MechanicHandler {
+	return &MechanicHandler{usecase: usecase}
+}
+
+func mapMechanicError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrMechanicNotFound):
+		return pkg.NewDomainErrorSimple("MECHANIC_NOT_FOUND", "Mechanic not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidMechanic):
+		return pkg.NewDomainErrorSimple("INVALID_MECHANIC", "Mechanic name is required", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrMechanicInactive):
+		return pkg.NewDomainErrorSimple("MECHANIC_INACTIVE", "Mechanic is inactive", http.StatusConflict)
+	case errors.Is(err, usecase.ErrMechanicAlreadyAssigned):
+		return pkg.NewDomainErrorSimple("MECHANIC_ALREADY_ASSIGNED", "Mechanic is already assigned to this service order or service", http.StatusConflict)
+	case errors.Is(err, usecase.ErrMechanicAssignmentNotFound):
+		return pkg.NewDomainErrorSimple("MECHANIC_ASSIGNMENT_NOT_FOUND", "Mechanic assignment not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrMechanicNotAssigned):
+		return pkg.NewDomainErrorSimple("MECHANIC_NOT_ASSIGNED", "Mechanic is not assigned to this service", http.StatusConflict)
+	case errors.Is(err, usecase.ErrServiceNotInServiceOrder):
+		return pkg.NewDomainErrorSimple("SERVICE_NOT_IN_SERVICE_ORDER", "Service is not part of the service order", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrServiceOrderNotInExecution):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_IN_EXECUTION", "Service order is not in execution", http.StatusConflict)
+	case errors.Is(err, usecase.ErrMechanicAlreadyClockedIn):
+		return pkg.NewDomainErrorSimple("MECHANIC_ALREADY_CLOCKED_IN", "Mechanic is already clocked in on a service", http.StatusConflict)
+	case errors.Is(err, usecase.ErrMechanicNotClockedIn):
+		return pkg.NewDomainErrorSimple("MECHANIC_NOT_CLOCKED_IN", "Mechanic is not clocked in on this service", http.StatusConflict)
+	case errors.Is(err, usecase.ErrServiceOrderClosed):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_CLOSED", "Service order is delivered or cancelled", http.StatusConflict)
+	case errors.Is(err, usecase.ErrServiceOrderNotFound):
+		return pkg.NewDomainErrorSimple("SERVICE_ORDER_NOT_FOUND", "Service order not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidReportPeriod):
+		return pkg.NewDomainErrorSimple("INVALID_REPORT_PERIOD", "Report end date must not be before its start date", http.StatusBadRequest)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// CreateMechanic godoc
+// @Summary Create a mechanic
+// @Description Register a mechanic who can be assigned to service orders
+// @Tags Mechanics
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param mechanic body entities.Mechanic true "Mechanic"
+// @Success 201 {object} entities.Mechanic
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /mechanics [post]
+func (h *MechanicHandler) CreateMechanic(c *gin.Context) {
+	var input entities.Mechanic
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidMechanicInput.HTTPStatus, errInvalidMechanicInput.ToHTTPError())
+		return
+	}
+
+	mechanic, err := h.usecase.CreateMechanic(c.Request.Context(), input)
+	if err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, mechanic)
+}
+
+// ListMechanics godoc
+// @Summary List mechanics
+// @Description List the mechanics, active and inactive
+// @Tags Mechanics
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} entities.Mechanic
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /mechanics [get]
+func (h *MechanicHandler) ListMechanics(c *gin.Context) {
+	mechanics, err := h.usecase.ListMechanics(c.Request.Context())
+	if err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, mechanics)
+}
+
+// GetMechanic godoc
+// @Summary Get a mechanic
+// @Description Get a mechanic by ID
+// @Tags Mechanics
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Mechanic ID"
+// @Success 200 {object} entities.Mechanic
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /mechanics/{id} [get]
+func (h *MechanicHandler) GetMechanic(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidMechanicID.HTTPStatus, errInvalidMechanicID.ToHTTPError())
+		return
+	}
+
+	mechanic, err := h.usecase.GetMechanic(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, mechanic)
+}
+
+// UpdateMechanic godoc
+// @Summary Update a mechanic
+// @Description Update the record of a mechanic. Inactive mechanics keep their history but cannot be assigned to service orders.
+// @Tags Mechanics
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Mechanic ID"
+// @Param mechanic body entities.Mechanic true "Mechanic"
+// @Success 200 {object} entities.Mechanic
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /mechanics/{id} [put]
+func (h *MechanicHandler) UpdateMechanic(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidMechanicID.HTTPStatus, errInvalidMechanicID.ToHTTPError())
+		return
+	}
+	var input entities.Mechanic
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidMechanicInput.HTTPStatus, errInvalidMechanicInput.ToHTTPError())
+		return
+	}
+	input.ID = uint(id)
+
+	mechanic, err := h.usecase.UpdateMechanic(c.Request.Context(), input)
+	if err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, mechanic)
+}
+
+// DeleteMechanic godoc
+// @Summary Delete a mechanic
+// @Description Remove a mechanic. Past assignments and labour time are kept.
+// @Tags Mechanics
+// @Security Bearer
+// @Param id path int true "Mechanic ID"
+// @Success 204
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /mechanics/{id} [delete]
+func (h *MechanicHandler) DeleteMechanic(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidMechanicID.HTTPStatus, errInvalidMechanicID.ToHTTPError())
+		return
+	}
+
+	if err := h.usecase.DeleteMechanic(c.Request.Context(), uint(id)); err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AssignMechanic godoc
+// @Summary Assign a mechanic to a service order
+// @Description Put an active mechanic on a service order, on the whole order or, with service_id, on one of its services
+// @Tags Mechanics
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Param assignment body entities.MechanicAssignment true "Assignment"
+// @Success 201 {object} entities.MechanicAssignment
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/mechanics [post]
+func (h *MechanicHandler) AssignMechanic(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+	var input entities.MechanicAssignment
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidMechanicInput.HTTPStatus, errInvalidMechanicInput.ToHTTPError())
+		return
+	}
+	input.ServiceOrderID = uint(id)
+	input.AssignedBy = c.GetString(middleware.ContextUserEmail)
+
+	assignment, err := h.usecase.AssignMechanic(c.Request.Context(), input)
+	if err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// UnassignMechanic godoc
+// @Summary Remove a mechanic from a service order
+// @Description Remove an assignment of a mechanic. The time already clocked is kept.
+// @Tags Mechanics
+// @Security Bearer
+// @Param id path int true "Service Order ID"
+// @Param assignmentId path int true "Assignment ID"
+// @Success 204
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/mechanics/{assignmentId} [delete]
+func (h *MechanicHandler) UnassignMechanic(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+	assignmentID, err := strconv.ParseUint(c.Param("assignmentId"), 10, 32)
+	if err != nil || assignmentID == 0 {
+		c.JSON(errInvalidMechanicAssignmentID.HTTPStatus, errInvalidMechanicAssignmentID.ToHTTPError())
+		return
+	}
+
+	if err := h.usecase.UnassignMechanic(c.Request.Context(), uint(id), uint(assignmentID)); err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ClockIn godoc
+// @Summary Clock in on a service
+// @Description Start the time of a mechanic on a service of a service order in execution. The mechanic must be assigned to the order or to the service and not be clocked in elsewhere.
+// @Tags Mechanics
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Param serviceId path int true "Service ID"
+// @Param request body entities.ClockRequest true "Mechanic"
+// @Success 201 {object} entities.LabourEntry
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/services/{serviceId}/clock-in [post]
+func (h *MechanicHandler) ClockIn(c *gin.Context) {
+	serviceOrderID, serviceID, mechanicID, ok := parseClockRequest(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.usecase.ClockIn(c.Request.Context(), serviceOrderID, serviceID, mechanicID)
+	if err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// ClockOut godoc
+// @Summary Clock out of a service
+// @Description Stop the time of a mechanic on the service they are clocked in on
+// @Tags Mechanics
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Param serviceId path int true "Service ID"
+// @Param request body entities.ClockRequest true "Mechanic"
+// @Success 200 {object} entities.LabourEntry
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/services/{serviceId}/clock-out [post]
+func (h *MechanicHandler) ClockOut(c *gin.Context) {
+	serviceOrderID, serviceID, mechanicID, ok := parseClockRequest(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.usecase.ClockOut(c.Request.Context(), serviceOrderID, serviceID, mechanicID)
+	if err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetServiceOrderLabour godoc
+// @Summary Labour of a service order
+// @Description Get the mechanics assigned to a service order, their clock entries and the time spent on each service against its standard time
+// @Tags Mechanics
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Success 200 {object} entities.ServiceOrderLabour
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/labour [get]
+func (h *MechanicHandler) GetServiceOrderLabour(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	labour, err := h.usecase.GetServiceOrderLabour(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, labour)
+}
+
+// GetProductivityReport godoc
+// @Summary Mechanic productivity report
+// @Description Get the time worked by each mechanic within a period and the standard time credited for it
+// @Tags Mechanics
+// @Security Bearer
+// @Produce json
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD)"
+// @Success 200 {object} entities.ProductivityReport
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /reports/productivity [get]
+func (h *MechanicHandler) GetProductivityReport(c *gin.Context) {
+	from, to, ok := parsePeriod(c)
+	if !ok {
+		c.JSON(errInvalidReportPeriod.HTTPStatus, errInvalidReportPeriod.ToHTTPError())
+		return
+	}
+
+	report, err := h.usecase.GetProductivityReport(c.Request.Context(), from, to)
+	if err != nil {
+		appErr := mapMechanicError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseClockRequest reads the service order and service of the path and the mechanic of the body,
+// answering 400 when any of them is invalid
+func parseClockRequest(c *gin.Context) (uint, uint, uint, bool) {
+	serviceOrderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || serviceOrderID == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return 0, 0, 0, false
+	}
+	serviceID, err := strconv.ParseUint(c.Param("serviceId"), 10, 32)
+	if err != nil || serviceID == 0 {
+		c.JSON(errInvalidLabourServiceID.HTTPStatus, errInvalidLabourServiceID.ToHTTPError())
+		return 0, 0, 0, false
+	}
+	var input entities.ClockRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidMechanicInput.HTTPStatus, errInvalidMechanicInput.ToHTTPError())
+		return 0, 0, 0, false
+	}
+	return uint(serviceOrderID), uint(serviceID), input.MechanicID, true
+}
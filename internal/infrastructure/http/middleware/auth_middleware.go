@@ -4,9 +4,18 @@ import (
 	"net/http"
 	"strings"
 
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// ContextUserEmail is the gin context key holding the e-mail (JWT subject) of the authenticated user
+	ContextUserEmail = "user_email"
+	// ContextUserRole is the gin context key holding the role of the authenticated user
+	ContextUserRole = "user_role"
 )
 
 func AuthMiddleware(jwtService *utils.JWTService) gin.HandlerFunc {
@@ -24,6 +33,29 @@ func AuthMiddleware(jwtService *utils.JWTService) gin.HandlerFunc {
 			return
 		}
 
+		if subject, err := token.Claims.GetSubject(); err == nil {
+			c.Set(ContextUserEmail, subject)
+		}
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			if role, ok := claims["role"].(string); ok {
+				c.Set(ContextUserRole, role)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission lets the request through only when the role of the authenticated user grants
+// the permission. Tokens issued without a role grant nothing.
+func RequirePermission(permission valueobject.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := valueobject.ParseUserType(c.GetString(ContextUserRole))
+		if !role.HasPermission(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+			return
+		}
+
 		c.Next()
 	}
 }
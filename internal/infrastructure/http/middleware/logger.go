@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var queryTokenPattern = regexp.MustCompile(`([?&]` + QueryTokenParam + `=)[^&]*`)
+
+// Logger logs each request in the format of gin.Logger, with the token of the access_token query
+// parameter masked so it never reaches the access logs
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			MaskQueryToken(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// MaskQueryToken replaces the value of the access_token query parameter of a path
+func MaskQueryToken(path string) string {
+	return queryTokenPattern.ReplaceAllString(path, "${1}***")
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskQueryToken(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/v1/service-orders/stream?access_token=eyJhbGciOi.eyJzdWIi.c2ln", "/v1/service-orders/stream?access_token=***"},
+		{"/v1/service-orders/stream?service_order_id=3&access_token=abc&customer_id=1", "/v1/service-orders/stream?service_order_id=3&access_token=***&customer_id=1"},
+		{"/v1/service-orders/stream?my_access_token=abc", "/v1/service-orders/stream?my_access_token=abc"},
+		{"/v1/service-orders/3", "/v1/service-orders/3"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, MaskQueryToken(tt.path))
+	}
+}
+
+func TestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = &out
+	t.Cleanup(func() { gin.DefaultWriter = defaultWriter })
+	r := gin.New()
+	r.Use(Logger(), QueryTokenMiddleware())
+	var authorization string
+	r.GET("/stream", func(c *gin.Context) {
+		authorization = c.GetHeader("Authorization")
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/stream?access_token=secret-jwt", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "Bearer secret-jwt", authorization)
+	assert.Contains(t, out.String(), "/stream?access_token=***")
+	assert.NotContains(t, out.String(), "secret-jwt")
+}
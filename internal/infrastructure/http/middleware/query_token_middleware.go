@@ -0,0 +1,19 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// QueryTokenParam is the query parameter carrying the JWT on endpoints opened by clients that
+// cannot send headers, like the browser EventSource
+const QueryTokenParam = "access_token"
+
+// QueryTokenMiddleware moves the token of the access_token query parameter to the Authorization
+// header when no header was sent, so AuthMiddleware validates it as usual. Use it only on the
+// routes that need it: Logger masks the token, but proxies in front of the API may log URLs too.
+func QueryTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query(QueryTokenParam); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
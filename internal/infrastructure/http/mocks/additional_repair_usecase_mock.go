@@ -77,6 +77,20 @@ func (mr *MockIAdditionalRepairUseCaseMockRecorder) CustomerApprovalStatus(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomerApprovalStatus", reflect.TypeOf((*MockIAdditionalRepairUseCase)(nil).CustomerApprovalStatus), ctx, additionalRepairId, status)
 }
 
+// SubmitForApproval mocks base method.
+func (m *MockIAdditionalRepairUseCase) SubmitForApproval(ctx context.Context, additionalRepairId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitForApproval", ctx, additionalRepairId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubmitForApproval indicates an expected call of SubmitForApproval.
+func (mr *MockIAdditionalRepairUseCaseMockRecorder) SubmitForApproval(ctx, additionalRepairId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitForApproval", reflect.TypeOf((*MockIAdditionalRepairUseCase)(nil).SubmitForApproval), ctx, additionalRepairId)
+}
+
 // GetAdditionalRepair mocks base method.
 func (m *MockIAdditionalRepairUseCase) GetAdditionalRepair(ctx context.Context, additionalRepairId uint) (entities.AdditionalRepair, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler exposes the log of the messages sent to customers
+// @title Notification API
+// @version 1.0
+// @description API for the customer notifications of the workshop management system
+type NotificationHandler struct {
+	usecase usecase.INotificationUseCase
+}
+
+func NewNotificationHandler(usecase usecase.INotificationUseCase) *NotificationHandler {
+	return &NotificationHandler{usecase: usecase}
+}
+
+// ListDeliveries godoc
+// @Summary List the notifications of a service order
+// @Description List the messages sent to the customer about a service order, with the channel, status and attempts of each delivery
+// @Tags Notifications
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Service Order ID"
+// @Success 200 {array} entities.NotificationDelivery
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /service-orders/{id}/notifications [get]
+func (h *NotificationHandler) ListDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+		return
+	}
+
+	deliveries, err := h.usecase.ListDeliveries(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
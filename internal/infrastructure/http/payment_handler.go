@@ -3,7 +3,9 @@ package http
 import (
 	"errors"
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
 	usecase "mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
 	"mecanica_xpto/pkg"
 	"net/http"
 	"strconv"
@@ -16,6 +18,11 @@ var (
 	errInvalidPaymentInput = pkg.NewDomainErrorSimple("INVALID_PAYMENT_INPUT", "Invalid payment input", http.StatusBadRequest)
 )
 
+// UpdatePaymentMethodRequest is the body of a payment method correction
+type UpdatePaymentMethodRequest struct {
+	Method valueobject.PaymentMethod `json:"method" binding:"required"`
+}
+
 // PaymentHandler handles HTTP requests for payment operations
 // @title Payment API
 // @version 1.0
@@ -38,6 +45,12 @@ func mapPaymentError(err error) *pkg.AppError {
 		return pkg.NewDomainErrorSimple("INVALID_ID", "Invalid payment ID", http.StatusBadRequest)
 	case errors.Is(err, usecase.ErrPaymentAlreadyExists):
 		return pkg.NewDomainErrorSimple("PAYMENT_ALREADY_EXISTS", "Payment already exists", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInvalidPaymentMethod):
+		return pkg.NewDomainErrorSimple("INVALID_PAYMENT_METHOD", "Payment method must be DINHEIRO, PIX, CARTAO_CREDITO, CARTAO_DEBITO, BOLETO or TRANSFERENCIA", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrCashRegisterClosed):
+		return pkg.NewDomainErrorSimple("CASH_REGISTER_CLOSED", "Cash register is already closed for the day", http.StatusConflict)
+	case errors.Is(err, usecase.ErrPaymentLocked):
+		return pkg.NewDomainErrorSimple("PAYMENT_LOCKED", "Payment belongs to a closed cash register day", http.StatusConflict)
 	default:
 		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
 	}
@@ -113,6 +126,7 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 		c.JSON(errInvalidPaymentInput.HTTPStatus, errInvalidPaymentInput.ToHTTPError())
 		return
 	}
+	input.Operator = c.GetString(middleware.ContextUserEmail)
 	payment, err := h.usecase.CreatePayment(c.Request.Context(), &input)
 	if err != nil {
 		appErr := mapPaymentError(err)
@@ -122,3 +136,41 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, payment)
 }
+
+// UpdatePaymentMethod godoc
+// @Summary Fix the method of a payment
+// @Description Change the method a payment was registered with. Payments of a closed cash register day are locked.
+// @Tags Payments
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Param request body UpdatePaymentMethodRequest true "Payment method"
+// @Success 200 {object} entities.Payment
+// @Failure 400 {object} pkg.AppError
+// @Failure 404 {object} pkg.AppError
+// @Failure 409 {object} pkg.AppError
+// @Failure 500 {object} pkg.AppError
+// @Router /payments/{id}/method [patch]
+func (h *PaymentHandler) UpdatePaymentMethod(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(errInvalidPaymentID.HTTPStatus, errInvalidPaymentID.ToHTTPError())
+		return
+	}
+
+	var input UpdatePaymentMethodRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidPaymentInput.HTTPStatus, errInvalidPaymentInput.ToHTTPError())
+		return
+	}
+
+	payment, err := h.usecase.UpdatePaymentMethod(c.Request.Context(), uint(id), input.Method)
+	if err != nil {
+		appErr := mapPaymentError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
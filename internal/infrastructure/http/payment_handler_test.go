@@ -13,6 +13,7 @@ import (
 
 	mocks "mecanica_xpto/internal/domain/mocks"
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/domain/usecase"
 )
 
@@ -156,3 +157,40 @@ func TestPaymentHandler_CreatePayment(t *testing.T) {
 		}
 	})
 }
+
+func TestPaymentHandler_UpdatePaymentMethod(t *testing.T) {
+	mockUC, h, r := setupPaymentHandlerTest(t)
+	r.PATCH("/v1/payments/:id/method", h.UpdatePaymentMethod)
+
+	t.Run("success", func(t *testing.T) {
+		mockUC.EXPECT().UpdatePaymentMethod(gomock.Any(), uint(1), valueobject.PaymentPix).
+			Return(&entities.Payment{ID: 1, Method: valueobject.PaymentPix}, nil)
+		body, _ := json.Marshal(UpdatePaymentMethodRequest{Method: valueobject.PaymentPix})
+		req, _ := http.NewRequest(http.MethodPatch, "/v1/payments/1/method", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("missing method", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPatch, "/v1/payments/1/method", bytes.NewBufferString("{}"))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("payment locked", func(t *testing.T) {
+		mockUC.EXPECT().UpdatePaymentMethod(gomock.Any(), uint(2), valueobject.PaymentCash).Return(nil, usecase.ErrPaymentLocked)
+		body, _ := json.Marshal(UpdatePaymentMethodRequest{Method: valueobject.PaymentCash})
+		req, _ := http.NewRequest(http.MethodPatch, "/v1/payments/2/method", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected 409, got %d", w.Code)
+		}
+	})
+}
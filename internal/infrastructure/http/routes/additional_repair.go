@@ -1,18 +1,24 @@
 package routes
 
 import (
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 func addAdditionalRepairRoutes(rg *gin.RouterGroup, additionalRepair *http.AdditionalRepairHandler) {
+	canView := middleware.RequirePermission(valueobject.PermissionViewServiceOrders)
+	canManage := middleware.RequirePermission(valueobject.PermissionManageServiceOrders)
+
 	serviceOrdersRoutes := rg.Group(PathAdditionalRepair)
 	{
-		serviceOrdersRoutes.POST("", additionalRepair.CreateAdditionalRepair)
-		serviceOrdersRoutes.GET("/:id", additionalRepair.GetAdditionalRepair)
-		serviceOrdersRoutes.PATCH("/:id/add", additionalRepair.AddPartSupplyAndService)
-		serviceOrdersRoutes.PATCH("/:id/remove", additionalRepair.RemovePartSupplyAndService)
-		serviceOrdersRoutes.PATCH("/:id/customer_approval", additionalRepair.CustomerApproval)
+		serviceOrdersRoutes.POST("", canManage, additionalRepair.CreateAdditionalRepair)
+		serviceOrdersRoutes.GET("/:id", canView, additionalRepair.GetAdditionalRepair)
+		serviceOrdersRoutes.PATCH("/:id/add", canManage, additionalRepair.AddPartSupplyAndService)
+		serviceOrdersRoutes.PATCH("/:id/remove", canManage, additionalRepair.RemovePartSupplyAndService)
+		serviceOrdersRoutes.PATCH("/:id/submit", canManage, additionalRepair.SubmitForApproval)
+		serviceOrdersRoutes.PATCH("/:id/customer_approval", canManage, additionalRepair.CustomerApproval)
 	}
 }
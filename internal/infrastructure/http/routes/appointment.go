@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addAppointmentRoutes(rg *gin.RouterGroup, appointmentHandler *http.AppointmentHandler) {
+	canManage := middleware.RequirePermission(valueobject.PermissionManageAppointments)
+	canManageSettings := middleware.RequirePermission(valueobject.PermissionManageSettings)
+
+	bays := rg.Group(PathBays)
+	{
+		bays.POST("/", canManageSettings, appointmentHandler.CreateBay)
+		bays.GET("/", canManage, appointmentHandler.ListBays)
+		bays.PUT("/:id", canManageSettings, appointmentHandler.UpdateBay)
+	}
+
+	appointments := rg.Group(PathAppointments, canManage)
+	{
+		appointments.POST("/", appointmentHandler.CreateAppointment)
+		appointments.GET("/", appointmentHandler.ListAppointments)
+		appointments.GET("/availability", appointmentHandler.FindAvailability)
+		appointments.GET("/:id", appointmentHandler.GetAppointment)
+		appointments.PATCH("/:id/reschedule", appointmentHandler.RescheduleAppointment)
+		appointments.PATCH("/:id/cancel", appointmentHandler.CancelAppointment)
+		appointments.POST("/:id/convert", appointmentHandler.ConvertAppointment)
+	}
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addApprovalRoutes(rg *gin.RouterGroup, approvalHandler *http.ApprovalHandler) {
+
+	canView := middleware.RequirePermission(valueobject.PermissionViewServiceOrders)
+	canManage := middleware.RequirePermission(valueobject.PermissionManageServiceOrders)
+
+	rg.POST(PathServiceOrders+"/:id/approval-links", canManage, approvalHandler.CreateEstimateLink)
+	rg.GET(PathServiceOrders+"/:id/approval-links", canView, approvalHandler.ListApprovalLinks)
+	rg.POST(PathAdditionalRepair+"/:id/approval-links", canManage, approvalHandler.CreateAdditionalRepairLink)
+}
+
+// addPublicApprovalRoutes registers the endpoints opened by customers from an approval link,
+// which are authorized by the signed token instead of a login
+func addPublicApprovalRoutes(rg *gin.RouterGroup, approvalHandler *http.ApprovalHandler) {
+	approvalRoutes := rg.Group(PathPublicApprovals)
+	{
+		approvalRoutes.GET("/:token", approvalHandler.GetApprovalRequest)
+		approvalRoutes.POST("/:token", approvalHandler.Decide)
+	}
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addAttachmentRoutes(rg *gin.RouterGroup, attachmentHandler *http.AttachmentHandler) {
+	canView := middleware.RequirePermission(valueobject.PermissionViewServiceOrders)
+	canManage := middleware.RequirePermission(valueobject.PermissionManageServiceOrders)
+
+	rg.POST(PathServiceOrders+"/:id/attachments", canManage, attachmentHandler.UploadServiceOrderAttachment)
+	rg.GET(PathServiceOrders+"/:id/attachments", canView, attachmentHandler.ListServiceOrderAttachments)
+	rg.POST(PathAdditionalRepair+"/:id/attachments", canManage, attachmentHandler.UploadAdditionalRepairAttachment)
+	rg.GET(PathAdditionalRepair+"/:id/attachments", canView, attachmentHandler.ListAdditionalRepairAttachments)
+
+	attachments := rg.Group(PathAttachments)
+	{
+		attachments.GET("/:id", canView, attachmentHandler.GetAttachment)
+		attachments.GET("/:id/download", canView, attachmentHandler.DownloadAttachment)
+		attachments.POST("/:id/download-url", canView, attachmentHandler.CreateDownloadURL)
+		attachments.DELETE("/:id", canManage, attachmentHandler.DeleteAttachment)
+	}
+}
+
+// addPublicAttachmentRoutes registers the temporary download links, which are authorized by their
+// signature instead of a login
+func addPublicAttachmentRoutes(rg *gin.RouterGroup, attachmentHandler *http.AttachmentHandler) {
+	rg.GET(PathPublicAttachments+"/:id", attachmentHandler.DownloadSignedAttachment)
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addCheckInRoutes registers the check-in routes. Reading is open to customers too, so the use case
+// checks who may see each check-in.
+func addCheckInRoutes(rg *gin.RouterGroup, checkInHandler *http.CheckInHandler) {
+	canManage := middleware.RequirePermission(valueobject.PermissionManageServiceOrders)
+
+	rg.PUT(PathServiceOrders+"/:id/check-in", canManage, checkInHandler.SaveCheckIn)
+	rg.GET(PathServiceOrders+"/:id/check-in", checkInHandler.GetCheckIn)
+	rg.POST(PathServiceOrders+"/:id/check-in/photos", canManage, checkInHandler.AddPhoto)
+	rg.GET(PathServiceOrders+"/:id/check-in/photos/:photoId", checkInHandler.GetPhoto)
+	rg.DELETE(PathServiceOrders+"/:id/check-in/photos/:photoId", canManage, checkInHandler.DeletePhoto)
+}
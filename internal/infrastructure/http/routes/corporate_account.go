@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addCorporateAccountRoutes registers the accounts and contacts for those who manage customers, and
+// the statements for those who handle payments
+func addCorporateAccountRoutes(rg *gin.RouterGroup, corporateAccountHandler *http.CorporateAccountHandler) {
+	canManageCustomers := middleware.RequirePermission(valueobject.PermissionManageCustomers)
+	canManagePayments := middleware.RequirePermission(valueobject.PermissionManagePayments)
+
+	accounts := rg.Group(PathCorporateAccounts)
+	{
+		accounts.GET("/:customerID", canManageCustomers, corporateAccountHandler.GetCorporateAccount)
+		accounts.PUT("/:customerID", canManageCustomers, corporateAccountHandler.SaveCorporateAccount)
+		accounts.POST("/:customerID/contacts", canManageCustomers, corporateAccountHandler.AddCorporateContact)
+		accounts.PUT("/:customerID/contacts/:contactID", canManageCustomers, corporateAccountHandler.UpdateCorporateContact)
+		accounts.DELETE("/:customerID/contacts/:contactID", canManageCustomers, corporateAccountHandler.RemoveCorporateContact)
+		accounts.GET("/:customerID/statements", canManagePayments, corporateAccountHandler.ListCorporateStatements)
+	}
+
+	statements := rg.Group(PathCorporateStatements, canManagePayments)
+	{
+		statements.POST("/close", corporateAccountHandler.CloseCorporateStatements)
+		statements.GET("/:id", corporateAccountHandler.GetCorporateStatement)
+		statements.POST("/:id/pay", corporateAccountHandler.PayCorporateStatement)
+	}
+}
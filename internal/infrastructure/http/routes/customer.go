@@ -2,11 +2,13 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
 )
 
 func addCustomerRoutes(rg *gin.RouterGroup, customerHandler *http.CustomerHandler) {
-	customersRoutes := rg.Group(PathCustomers)
+	customersRoutes := rg.Group(PathCustomers, middleware.RequirePermission(valueobject.PermissionManageCustomers))
 	{
 		customersRoutes.GET("/full/:id", customerHandler.GetFullCustomer)
 		customersRoutes.GET("/:document", customerHandler.GetCustomer)
@@ -16,3 +18,12 @@ func addCustomerRoutes(rg *gin.RouterGroup, customerHandler *http.CustomerHandle
 		customersRoutes.GET("/", customerHandler.ListCustomer)
 	}
 }
+
+func addCustomerMergeRoutes(rg *gin.RouterGroup, customerMergeHandler *http.CustomerMergeHandler) {
+	customersRoutes := rg.Group(PathCustomers, middleware.RequirePermission(valueobject.PermissionManageCustomers))
+	{
+		customersRoutes.GET("/duplicates", customerMergeHandler.FindDuplicateCustomers)
+		customersRoutes.POST("/:id/merge", customerMergeHandler.MergeCustomers)
+	}
+	rg.GET(PathCustomerMerges, middleware.RequirePermission(valueobject.PermissionManageCustomers), customerMergeHandler.ListCustomerMerges)
+}
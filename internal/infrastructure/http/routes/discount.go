@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addDiscountRoutes(rg *gin.RouterGroup, discountHandler *http.DiscountHandler) {
+
+	coupons := rg.Group(PathCoupons, middleware.RequirePermission(valueobject.PermissionManageDiscounts))
+	{
+		coupons.GET("/", discountHandler.ListCoupons)
+		coupons.POST("/", discountHandler.CreateCoupon)
+		coupons.PUT("/:code", discountHandler.UpdateCoupon)
+	}
+
+	priceAgreements := rg.Group(PathPriceAgreements, middleware.RequirePermission(valueobject.PermissionManageDiscounts))
+	{
+		priceAgreements.POST("/", discountHandler.CreatePriceAgreement)
+		priceAgreements.GET("/customer/:customerID", discountHandler.ListPriceAgreements)
+		priceAgreements.DELETE("/:id", discountHandler.DeletePriceAgreement)
+	}
+
+	rg.PATCH(PathServiceOrders+"/:id/discount-approval", middleware.RequirePermission(valueobject.PermissionApproveDiscounts), discountHandler.ApproveDiscount)
+}
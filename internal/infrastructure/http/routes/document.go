@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addDocumentRoutes(rg *gin.RouterGroup, documentHandler *http.DocumentHandler) {
+
+	rg.GET(PathServiceOrders+"/:id/documents/:type", middleware.RequirePermission(valueobject.PermissionViewServiceOrders), documentHandler.GetDocument)
+}
@@ -10,4 +10,23 @@ const (
 	PathServiceOrders = "/service-orders"
 	PathPayments      = "/payments"
 	PathAdditionalRepair = "/additional-repair"
+	PathCoupons          = "/coupons"
+	PathPriceAgreements  = "/price-agreements"
+	PathTaxRules         = "/tax-rules"
+	PathReports          = "/reports"
+	PathInvoices         = "/invoices"
+	PathCashClosings     = "/cash-closings"
+	PathPublicApprovals  = "/public/approvals"
+	PathWebhooks         = "/webhooks"
+	PathMechanics        = "/mechanics"
+	PathStaff            = "/staff"
+	PathBays             = "/bays"
+	PathAppointments     = "/appointments"
+	PathAttachments      = "/attachments"
+	PathPublicAttachments = "/public/attachments"
+	PathMaintenancePlans = "/maintenance-plans"
+	PathVehicleCatalog   = "/vehicle-catalog"
+	PathCorporateAccounts   = "/corporate-accounts"
+	PathCorporateStatements = "/corporate-statements"
+	PathCustomerMerges      = "/customer-merges"
 )
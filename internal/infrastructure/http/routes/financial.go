@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addFinancialRoutes(rg *gin.RouterGroup, financialHandler *http.FinancialHandler) {
+
+	canViewReports := middleware.RequirePermission(valueobject.PermissionViewReports)
+
+	rg.GET(PathReports+"/payments", canViewReports, financialHandler.GetPaymentReport)
+	rg.GET(PathReports+"/receivables", canViewReports, financialHandler.GetReceivables)
+	rg.GET(PathReports+"/revenue", canViewReports, financialHandler.GetRevenueReport)
+
+	cashClosings := rg.Group(PathCashClosings, middleware.RequirePermission(valueobject.PermissionManagePayments))
+	{
+		cashClosings.GET("/", financialHandler.ListCashClosings)
+		cashClosings.POST("/", financialHandler.CloseCashRegister)
+		cashClosings.GET("/:date", financialHandler.GetCashClosing)
+		cashClosings.GET("/:date/export", financialHandler.ExportCashClosing)
+	}
+}
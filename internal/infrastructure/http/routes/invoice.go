@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addInvoiceRoutes(rg *gin.RouterGroup, invoiceHandler *http.InvoiceHandler) {
+
+	canManage := middleware.RequirePermission(valueobject.PermissionManagePayments)
+
+	rg.POST(PathServiceOrders+"/:id/invoices", canManage, invoiceHandler.IssueInvoices)
+	rg.GET(PathServiceOrders+"/:id/invoices", canManage, invoiceHandler.ListInvoices)
+
+	invoices := rg.Group(PathInvoices, canManage)
+	{
+		invoices.GET("/:id", invoiceHandler.GetInvoice)
+		invoices.GET("/:id/xml", invoiceHandler.GetInvoiceXML)
+		invoices.POST("/:id/cancel", invoiceHandler.CancelInvoice)
+	}
+}
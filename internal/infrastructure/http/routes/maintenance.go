@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addMaintenanceRoutes(rg *gin.RouterGroup, maintenanceHandler *http.MaintenanceHandler) {
+	canView := middleware.RequirePermission(valueobject.PermissionViewCatalog)
+	canManage := middleware.RequirePermission(valueobject.PermissionManageCatalog)
+
+	rg.GET(PathMaintenancePlans, canView, maintenanceHandler.ListMaintenancePlans)
+	rg.GET(PathService+"/:id/maintenance-plan", canView, maintenanceHandler.GetMaintenancePlan)
+	rg.PUT(PathService+"/:id/maintenance-plan", canManage, maintenanceHandler.SaveMaintenancePlan)
+	rg.DELETE(PathService+"/:id/maintenance-plan", canManage, maintenanceHandler.DeleteMaintenancePlan)
+
+	rg.GET(PathVehicles+"/:id/maintenance", middleware.RequirePermission(valueobject.PermissionViewServiceOrders), maintenanceHandler.GetVehicleMaintenance)
+}
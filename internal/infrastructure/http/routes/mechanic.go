@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addMechanicRoutes(rg *gin.RouterGroup, mechanicHandler *http.MechanicHandler) {
+
+	canManage := middleware.RequirePermission(valueobject.PermissionManageMechanics)
+	canRecordLabour := middleware.RequirePermission(valueobject.PermissionRecordLabour)
+
+	mechanics := rg.Group(PathMechanics, canManage)
+	{
+		mechanics.POST("/", mechanicHandler.CreateMechanic)
+		mechanics.GET("/", mechanicHandler.ListMechanics)
+		mechanics.GET("/:id", mechanicHandler.GetMechanic)
+		mechanics.PUT("/:id", mechanicHandler.UpdateMechanic)
+		mechanics.DELETE("/:id", mechanicHandler.DeleteMechanic)
+	}
+
+	rg.POST(PathServiceOrders+"/:id/mechanics", canManage, mechanicHandler.AssignMechanic)
+	rg.DELETE(PathServiceOrders+"/:id/mechanics/:assignmentId", canManage, mechanicHandler.UnassignMechanic)
+	rg.POST(PathServiceOrders+"/:id/services/:serviceId/clock-in", canRecordLabour, mechanicHandler.ClockIn)
+	rg.POST(PathServiceOrders+"/:id/services/:serviceId/clock-out", canRecordLabour, mechanicHandler.ClockOut)
+	rg.GET(PathServiceOrders+"/:id/labour", middleware.RequirePermission(valueobject.PermissionViewServiceOrders), mechanicHandler.GetServiceOrderLabour)
+	rg.GET(PathReports+"/productivity", middleware.RequirePermission(valueobject.PermissionViewReports), mechanicHandler.GetProductivityReport)
+}
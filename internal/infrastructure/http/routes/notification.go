@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addNotificationRoutes(rg *gin.RouterGroup, notificationHandler *http.NotificationHandler) {
+
+	rg.GET(PathServiceOrders+"/:id/notifications", middleware.RequirePermission(valueobject.PermissionViewServiceOrders), notificationHandler.ListDeliveries)
+}
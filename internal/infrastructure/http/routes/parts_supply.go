@@ -1,19 +1,24 @@
 package routes
 
 import (
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 func addPartsSupplyRoutes(rg *gin.RouterGroup, partsSupplyHandler *http.PartsSupplyHandler) {
 
+	canView := middleware.RequirePermission(valueobject.PermissionViewCatalog)
+	canManage := middleware.RequirePermission(valueobject.PermissionManageStock)
+
 	partsSupply := rg.Group(PathPartsSupply)
 	{
-		partsSupply.GET("/:id", partsSupplyHandler.GetPartsSupplyByID)
-		partsSupply.GET("/", partsSupplyHandler.ListPartsSupplies)
-		partsSupply.POST("/", partsSupplyHandler.CreatePartsSupply)
-		partsSupply.PUT("/:id", partsSupplyHandler.UpdatePartsSupply)
-		partsSupply.DELETE("/:id", partsSupplyHandler.DeletePartsSupply)
+		partsSupply.GET("/:id", canView, partsSupplyHandler.GetPartsSupplyByID)
+		partsSupply.GET("/", canView, partsSupplyHandler.ListPartsSupplies)
+		partsSupply.POST("/", canManage, partsSupplyHandler.CreatePartsSupply)
+		partsSupply.PUT("/:id", canManage, partsSupplyHandler.UpdatePartsSupply)
+		partsSupply.DELETE("/:id", canManage, partsSupplyHandler.DeletePartsSupply)
 	}
 }
@@ -1,17 +1,20 @@
 package routes
 
 import (
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 func addPaymentRoutes(rg *gin.RouterGroup, paymentHandler *http.PaymentHandler) {
 
-	payments := rg.Group(PathPayments)
+	payments := rg.Group(PathPayments, middleware.RequirePermission(valueobject.PermissionManagePayments))
 	{
 		payments.GET("/:id", paymentHandler.GetPaymentByID)
 		payments.GET("/", paymentHandler.ListPayments)
 		payments.POST("/", paymentHandler.CreatePayment)
+		payments.PATCH("/:id/method", paymentHandler.UpdatePaymentMethod)
 	}
 }
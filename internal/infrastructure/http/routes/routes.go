@@ -1,30 +1,58 @@
 package routes
 
 import (
+	"context"
 	"log"
 	_ "mecanica_xpto/docs" // This will be auto-generated
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/domain/repository/additional_repair"
+	"mecanica_xpto/internal/domain/repository/appointment"
+	"mecanica_xpto/internal/domain/repository/approval"
+	"mecanica_xpto/internal/domain/repository/attachment"
+	checkin "mecanica_xpto/internal/domain/repository/check_in"
+	corporateaccount "mecanica_xpto/internal/domain/repository/corporate_account"
+	customermerge "mecanica_xpto/internal/domain/repository/customer_merge"
 	"mecanica_xpto/internal/domain/repository/customers"
+	"mecanica_xpto/internal/domain/repository/discount"
+	"mecanica_xpto/internal/domain/repository/financial"
+	"mecanica_xpto/internal/domain/repository/invoice"
+	"mecanica_xpto/internal/domain/repository/maintenance"
+	"mecanica_xpto/internal/domain/repository/mechanic"
+	"mecanica_xpto/internal/domain/repository/notification"
+	"mecanica_xpto/internal/domain/repository/outbox"
 	"mecanica_xpto/internal/domain/repository/parts_supply"
 	"mecanica_xpto/internal/domain/repository/payment"
 	"mecanica_xpto/internal/domain/repository/service"
 	serviceorder "mecanica_xpto/internal/domain/repository/service_order"
+	"mecanica_xpto/internal/domain/repository/staff"
+	"mecanica_xpto/internal/domain/repository/tax"
 	"mecanica_xpto/internal/domain/repository/users"
+	vehiclecatalog "mecanica_xpto/internal/domain/repository/vehicle_catalog"
+	vehicleownership "mecanica_xpto/internal/domain/repository/vehicle_ownership"
 	"mecanica_xpto/internal/domain/repository/vehicles"
+	"mecanica_xpto/internal/domain/repository/webhook"
 	"mecanica_xpto/internal/domain/usecase"
 	"mecanica_xpto/internal/infrastructure/database"
+	"mecanica_xpto/internal/infrastructure/fiscal"
 	"mecanica_xpto/internal/infrastructure/http"
 	"mecanica_xpto/internal/infrastructure/http/handlers"
 	"mecanica_xpto/internal/infrastructure/http/middleware"
+	notificationsender "mecanica_xpto/internal/infrastructure/notification"
+	"mecanica_xpto/internal/infrastructure/pdf"
+	"mecanica_xpto/internal/infrastructure/storage"
+	webhookclient "mecanica_xpto/internal/infrastructure/webhook"
 	"mecanica_xpto/pkg/utils"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-var router = gin.Default()
+// The middlewares, including the logger, are set by setMiddlewares
+var router = gin.New()
 
 const PORT = 8080
 
@@ -69,51 +97,323 @@ func getRoutes() {
 	serviceHandler := http.NewServiceHandler(serviceUseCase)
 
 	vehiclesRepository := vehicles.NewVehicleRepository(db)
-	vehiclesUseCase := usecase.NewVehicleService(vehiclesRepository)
+	vehicleCatalogRepository := vehiclecatalog.NewVehicleCatalogRepository(db)
+	vehiclesUseCase := usecase.NewVehicleService(vehiclesRepository, vehicleCatalogRepository)
 	vehicleHandler := http.NewVehicleHandler(vehiclesUseCase)
+	vehicleCatalogHandler := http.NewVehicleCatalogHandler(usecase.NewVehicleCatalogUseCase(vehicleCatalogRepository))
 	customerRepository := customers.NewCustomerRepository(db)
 	customerUseCase := usecase.NewCustomerUseCase(customerRepository, userRepository)
 	customerHandler := http.NewCustomerHandler(customerUseCase)
+	vehicleImportHandler := http.NewVehicleImportHandler(usecase.NewVehicleImportUseCase(
+		vehiclesRepository,
+		customerRepository,
+		vehicleCatalogRepository))
 
 	serviceOrderRepository := serviceorder.NewServiceOrderRepository(db)
+
+	vehicleOwnershipHandler := http.NewVehicleOwnershipHandler(usecase.NewVehicleOwnershipUseCase(
+		vehicleownership.NewVehicleOwnershipRepository(db),
+		vehiclesRepository,
+		customerRepository,
+		serviceOrderRepository))
+
+	pricingCfg := utils.LoadPricingConfig()
+	discountRepository := discount.NewDiscountRepository(db)
+	discountUseCase := usecase.NewDiscountUseCase(
+		discountRepository,
+		serviceOrderRepository,
+		userRepository,
+		customerRepository,
+		serviceRepository,
+		partsSupplyRepository,
+		pricingCfg.DiscountApprovalThreshold)
+	discountHandler := http.NewDiscountHandler(discountUseCase)
+
+	taxCfg := utils.LoadTaxConfig()
+	taxRepository := tax.NewTaxRepository(db)
+	taxUseCase := usecase.NewTaxUseCase(taxRepository, taxCfg.DefaultISSRate, taxCfg.DefaultICMSRate)
+	taxHandler := http.NewTaxHandler(taxUseCase)
+
+	invoiceCfg := utils.LoadInvoiceConfig()
+	invoiceRepository := invoice.NewInvoiceRepository(db)
+	invoiceEncoder := fiscal.NewXMLEncoder(entities.InvoiceIssuer{
+		CNPJ:                  invoiceCfg.IssuerCNPJ,
+		Name:                  invoiceCfg.IssuerName,
+		MunicipalRegistration: invoiceCfg.IssuerMunicipalRegistration,
+		StateRegistration:     invoiceCfg.IssuerStateRegistration,
+		CityCode:              invoiceCfg.IssuerCityCode,
+		State:                 invoiceCfg.IssuerState,
+	})
+	invoiceSigner, err := newInvoiceSigner(invoiceCfg)
+	if err != nil {
+		log.Fatalf("Failed to load the invoice certificate: %v", err)
+	}
+	invoiceProvider, err := fiscal.NewProvider(invoiceCfg.Provider)
+	if err != nil {
+		log.Fatalf("Failed to configure the invoice provider: %v", err)
+	}
+	invoiceUseCase := usecase.NewInvoiceUseCase(
+		invoiceRepository,
+		serviceOrderRepository,
+		taxUseCase,
+		invoiceEncoder,
+		invoiceSigner,
+		invoiceProvider,
+		invoiceCfg.Series)
+	invoiceHandler := http.NewInvoiceHandler(invoiceUseCase)
+
+	notificationCfg := utils.LoadNotificationConfig()
+	notificationSenders, err := notificationsender.NewSenders(notificationCfg)
+	if err != nil {
+		log.Fatalf("Failed to configure the notification senders: %v", err)
+	}
+	notificationUseCase := usecase.NewNotificationUseCase(
+		notification.NewNotificationRepository(db),
+		serviceOrderRepository,
+		notificationSenders,
+		notificationCfg.MaxAttempts)
+	notificationHandler := http.NewNotificationHandler(notificationUseCase)
+	go runNotificationWorker(notificationUseCase, notificationCfg.RetryInterval)
+
+	webhookCfg := utils.LoadWebhookConfig()
+	webhookUseCase := usecase.NewWebhookUseCase(
+		webhook.NewWebhookRepository(db),
+		serviceOrderRepository,
+		customerRepository,
+		webhookclient.NewClient(webhookCfg.Timeout),
+		webhookCfg.MaxAttempts)
+	webhookHandler := http.NewWebhookHandler(webhookUseCase)
+	go runWebhookWorker(webhookUseCase, webhookCfg.RetryInterval)
+
+	eventCfg := utils.LoadEventConfig()
+	eventDispatcher := usecase.NewEventDispatcher(outbox.NewOutboxRepository(db))
+	eventDispatcher.Subscribe(valueobject.EventServiceOrderCreated, notificationUseCase.HandleDomainEvent)
+	eventDispatcher.Subscribe(valueobject.EventServiceOrderStatusChanged, notificationUseCase.HandleDomainEvent)
+	for _, eventType := range usecase.WebhookEventTypes {
+		eventDispatcher.Subscribe(eventType, webhookUseCase.HandleDomainEvent)
+	}
+	serviceOrderStreamUseCase := usecase.NewServiceOrderStreamUseCase(serviceOrderRepository, userRepository)
+	for _, eventType := range usecase.ServiceOrderStreamEventTypes {
+		eventDispatcher.Subscribe(eventType, serviceOrderStreamUseCase.HandleDomainEvent)
+	}
+	serviceOrderStreamHandler := http.NewServiceOrderStreamHandler(serviceOrderStreamUseCase)
+	go runEventDispatcher(eventDispatcher, eventCfg.DispatchInterval)
+
+	corporateAccountRepository := corporateaccount.NewCorporateAccountRepository(db)
 	serviceOrderUsecase := usecase.NewServiceOrderUseCase(
 		serviceOrderRepository,
 		vehiclesRepository,
 		customerRepository,
 		serviceRepository,
-		partsSupplyRepository)
+		partsSupplyRepository,
+		discountUseCase,
+		taxUseCase,
+		invoiceUseCase,
+		corporateAccountRepository)
 	serviceOrderHandler := http.NewServiceOrderHandler(serviceOrderUsecase)
 
+	mechanicUseCase := usecase.NewMechanicUseCase(mechanic.NewMechanicRepository(db), serviceOrderRepository)
+	mechanicHandler := http.NewMechanicHandler(mechanicUseCase)
+
+	appointmentUseCase := usecase.NewAppointmentUseCase(
+		appointment.NewAppointmentRepository(db),
+		customerRepository,
+		vehiclesRepository,
+		serviceRepository,
+		serviceOrderUsecase,
+		*utils.LoadScheduleConfig())
+	appointmentHandler := http.NewAppointmentHandler(appointmentUseCase)
+
+	storageCfg := utils.LoadStorageConfig()
+	blobStore, err := storage.NewBlobStore(storageCfg)
+	if err != nil {
+		log.Fatalf("Failed to configure the blob store: %v", err)
+	}
+	checkInRepository := checkin.NewCheckInRepository(db)
+	checkInUseCase := usecase.NewCheckInUseCase(
+		checkInRepository,
+		serviceOrderRepository,
+		blobStore,
+		storageCfg.MaxUploadSize)
+	checkInHandler := http.NewCheckInHandler(checkInUseCase, storageCfg.MaxUploadSize)
+
+	vehicleHistoryUseCase := usecase.NewVehicleHistoryUseCase(
+		vehiclesRepository,
+		serviceOrderRepository,
+		checkInRepository)
+	vehicleHistoryHandler := http.NewVehicleHistoryHandler(vehicleHistoryUseCase)
+
+	maintenanceCfg := utils.LoadMaintenanceConfig()
+	maintenanceUseCase := usecase.NewMaintenanceUseCase(
+		maintenance.NewMaintenanceRepository(db),
+		serviceRepository,
+		customerRepository,
+		vehicleHistoryUseCase,
+		notificationSenders,
+		*maintenanceCfg)
+	maintenanceHandler := http.NewMaintenanceHandler(maintenanceUseCase)
+	go runMaintenanceReminderWorker(maintenanceUseCase, maintenanceCfg.ReminderInterval)
+
+	staffHandler := http.NewStaffHandler(usecase.NewStaffUseCase(staff.NewStaffRepository(db)))
+
+	documentUseCase := usecase.NewDocumentUseCase(serviceOrderRepository, pdf.NewRenderer())
+	documentHandler := http.NewDocumentHandler(documentUseCase)
+
 	paymentRepository := payment.NewPaymentRepository(db)
-	paymentUseCase := usecase.NewPaymentUseCase(paymentRepository, serviceOrderRepository)
+	financialRepository := financial.NewFinancialRepository(db)
+	paymentUseCase := usecase.NewPaymentUseCase(paymentRepository, serviceOrderRepository, financialRepository)
 	paymentHandler := http.NewPaymentHandler(paymentUseCase)
 
+	corporateCfg := utils.LoadCorporateConfig()
+	corporateAccountUseCase := usecase.NewCorporateAccountUseCase(corporateAccountRepository, customerRepository, paymentUseCase)
+	corporateAccountHandler := http.NewCorporateAccountHandler(corporateAccountUseCase)
+	go runCorporateBillingWorker(corporateAccountUseCase, corporateCfg.BillingInterval)
+
+	customerMergeHandler := http.NewCustomerMergeHandler(usecase.NewCustomerMergeUseCase(
+		customermerge.NewCustomerMergeRepository(db),
+		customerRepository,
+		corporateAccountRepository))
+
+	financialUseCase := usecase.NewFinancialUseCase(financialRepository)
+	financialHandler := http.NewFinancialHandler(financialUseCase)
+
 	additionalRepairRepository := additional_repair.NewAdditionalRepairRepository(db)
 	additionalRepairUsecase := usecase.NewSOAdditionalRepairUseCase(
 		additionalRepairRepository,
 		serviceOrderRepository,
 		serviceRepository,
-		partsSupplyRepository)
+		partsSupplyRepository,
+		notificationUseCase)
 	additionalRepairHandler := http.NewAdditionalRepairHandler(additionalRepairUsecase)
 
+	approvalCfg := utils.LoadApprovalConfig()
+	approvalUseCase := usecase.NewApprovalLinkUseCase(
+		approval.NewApprovalLinkRepository(db),
+		serviceOrderRepository,
+		additionalRepairRepository,
+		corporateAccountRepository,
+		serviceOrderUsecase,
+		additionalRepairUsecase,
+		utils.NewApprovalTokenService(approvalCfg),
+		approvalCfg.LinkTTL,
+		approvalCfg.BaseURL)
+	approvalHandler := http.NewApprovalHandler(approvalUseCase)
+	addPublicApprovalRoutes(v1, approvalHandler)
+
+	attachmentUseCase := usecase.NewAttachmentUseCase(
+		attachment.NewAttachmentRepository(db),
+		serviceOrderRepository,
+		additionalRepairRepository,
+		blobStore,
+		*storageCfg)
+	attachmentHandler := http.NewAttachmentHandler(attachmentUseCase, storageCfg.MaxUploadSize)
+	addPublicAttachmentRoutes(v1, attachmentHandler)
+	addServiceOrderStreamRoutes(v1, serviceOrderStreamHandler, middleware.AuthMiddleware(jwtService))
+
 	// Rotas protegidas
 	authGroup := v1.Group("/")
 	authGroup.Use(middleware.AuthMiddleware(jwtService))
 	addPingRoutes(authGroup)
 	addPartsSupplyRoutes(authGroup, partsSupplyHandler)
 	addVehicleRoutes(authGroup, vehicleHandler)
+	addVehicleOwnershipRoutes(authGroup, vehicleOwnershipHandler)
+	addVehicleImportRoutes(authGroup, vehicleImportHandler)
+	addVehicleCatalogRoutes(authGroup, vehicleCatalogHandler)
 	addServiceRoutes(authGroup, serviceHandler)
 	addCustomerRoutes(authGroup, customerHandler)
+	addCustomerMergeRoutes(authGroup, customerMergeHandler)
+	addCorporateAccountRoutes(authGroup, corporateAccountHandler)
 	addServiceOrderRoutes(authGroup, serviceOrderHandler)
 	addPaymentRoutes(authGroup, paymentHandler)
 	addAdditionalRepairRoutes(authGroup, additionalRepairHandler)
+	addDiscountRoutes(authGroup, discountHandler)
+	addTaxRoutes(authGroup, taxHandler)
+	addInvoiceRoutes(authGroup, invoiceHandler)
+	addDocumentRoutes(authGroup, documentHandler)
+	addFinancialRoutes(authGroup, financialHandler)
+	addApprovalRoutes(authGroup, approvalHandler)
+	addNotificationRoutes(authGroup, notificationHandler)
+	addWebhookRoutes(authGroup, webhookHandler)
+	addMechanicRoutes(authGroup, mechanicHandler)
+	addStaffRoutes(authGroup, staffHandler)
+	addAppointmentRoutes(authGroup, appointmentHandler)
+	addCheckInRoutes(authGroup, checkInHandler)
+	addVehicleHistoryRoutes(authGroup, vehicleHistoryHandler)
+	addAttachmentRoutes(authGroup, attachmentHandler)
+	addMaintenanceRoutes(authGroup, maintenanceHandler)
+}
+
+// runEventDispatcher delivers the domain events stored in the outbox to their subscribers on every tick
+func runEventDispatcher(dispatcher usecase.IEventDispatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := dispatcher.Dispatch(context.Background()); err != nil {
+			log.Printf("Failed to dispatch domain events: %v", err)
+		}
+	}
+}
+
+// runNotificationWorker sends the queued customer notifications, retrying the failed ones, on every tick
+func runNotificationWorker(notificationUseCase usecase.INotificationUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := notificationUseCase.ProcessPending(context.Background()); err != nil {
+			log.Printf("Failed to send pending notifications: %v", err)
+		}
+	}
+}
+
+// runWebhookWorker sends the queued webhook deliveries, retrying the failed ones, on every tick
+func runWebhookWorker(webhookUseCase usecase.IWebhookUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := webhookUseCase.ProcessPending(context.Background()); err != nil {
+			log.Printf("Failed to send pending webhooks: %v", err)
+		}
+	}
+}
+
+// runMaintenanceReminderWorker reminds the customers of the vehicles coming due for maintenance on every tick
+func runMaintenanceReminderWorker(maintenanceUseCase usecase.IMaintenanceUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := maintenanceUseCase.SendReminders(context.Background()); err != nil {
+			log.Printf("Failed to send maintenance reminders: %v", err)
+		}
+	}
+}
+
+// runCorporateBillingWorker closes the statements of the previous month on every tick; the accounts
+// already having theirs are skipped, so only the first tick of a month bills anything
+func runCorporateBillingWorker(corporateAccountUseCase usecase.ICorporateAccountUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := corporateAccountUseCase.CloseStatements(context.Background(), ""); err != nil {
+			log.Printf("Failed to close the corporate statements: %v", err)
+		}
+	}
+}
+
+// newInvoiceSigner loads the certificate of the issuer, falling back to a self-signed one when none is configured
+func newInvoiceSigner(cfg *utils.InvoiceConfig) (*fiscal.RSASigner, error) {
+	if cfg.CertFile == "" {
+		log.Printf("INVOICE_CERT_FILE not set: signing invoices with a self-signed certificate")
+		return fiscal.NewSelfSignedSigner(cfg.IssuerName)
+	}
+	return fiscal.LoadRSASigner(cfg.CertFile, cfg.KeyFile)
 }
 
 func setMiddlewares() {
 
 	middleware.SetTrustedProxies(router)
 
-	router.Use(gin.Logger())
+	// Masks the token that the Server-Sent Events stream receives in the query string
+	router.Use(middleware.Logger())
 	router.Use(gin.Recovery())
 	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
 		log.Printf("Recovered from panic: %v", recovered)
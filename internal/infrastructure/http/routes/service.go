@@ -1,19 +1,24 @@
 package routes
 
 import (
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 func addServiceRoutes(rg *gin.RouterGroup, serviceHandler *http.ServiceHandler) {
 
+	canView := middleware.RequirePermission(valueobject.PermissionViewCatalog)
+	canManage := middleware.RequirePermission(valueobject.PermissionManageCatalog)
+
 	service := rg.Group(PathService)
 	{
-		service.GET("/:id", serviceHandler.GetServiceByID)
-		service.GET("/", serviceHandler.ListServices)
-		service.POST("/", serviceHandler.CreateService)
-		service.PUT("/:id", serviceHandler.UpdateService)
-		service.DELETE("/:id", serviceHandler.DeleteService)
+		service.GET("/:id", canView, serviceHandler.GetServiceByID)
+		service.GET("/", canView, serviceHandler.ListServices)
+		service.POST("/", canManage, serviceHandler.CreateService)
+		service.PUT("/:id", canManage, serviceHandler.UpdateService)
+		service.DELETE("/:id", canManage, serviceHandler.DeleteService)
 	}
 }
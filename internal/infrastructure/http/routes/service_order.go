@@ -1,20 +1,25 @@
 package routes
 
 import (
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 func addServiceOrderRoutes(rg *gin.RouterGroup, serviceOrderHandler *http.ServiceOrderHandler) {
+	canView := middleware.RequirePermission(valueobject.PermissionViewServiceOrders)
+	canManage := middleware.RequirePermission(valueobject.PermissionManageServiceOrders)
+
 	serviceOrdersRoutes := rg.Group(PathServiceOrders)
 	{
-		serviceOrdersRoutes.GET("/:id", serviceOrderHandler.GetServiceOrder)
-		serviceOrdersRoutes.POST("", serviceOrderHandler.CreateServiceOrder)
-		serviceOrdersRoutes.PATCH("/:id/diagnosis", serviceOrderHandler.UpdateServiceOrderDiagnosis)
-		serviceOrdersRoutes.PATCH("/:id/estimate", serviceOrderHandler.UpdateServiceOrderEstimate)
-		serviceOrdersRoutes.PATCH("/:id/execution", serviceOrderHandler.UpdateServiceOrderExecution)
-		serviceOrdersRoutes.PATCH("/:id/delivery", serviceOrderHandler.UpdateServiceOrderDelivery)
-		serviceOrdersRoutes.GET("/", serviceOrderHandler.ListServiceOrders)
+		serviceOrdersRoutes.GET("/:id", canView, serviceOrderHandler.GetServiceOrder)
+		serviceOrdersRoutes.POST("", canManage, serviceOrderHandler.CreateServiceOrder)
+		serviceOrdersRoutes.PATCH("/:id/diagnosis", canManage, serviceOrderHandler.UpdateServiceOrderDiagnosis)
+		serviceOrdersRoutes.PATCH("/:id/estimate", canManage, serviceOrderHandler.UpdateServiceOrderEstimate)
+		serviceOrdersRoutes.PATCH("/:id/execution", canManage, serviceOrderHandler.UpdateServiceOrderExecution)
+		serviceOrdersRoutes.PATCH("/:id/delivery", canManage, serviceOrderHandler.UpdateServiceOrderDelivery)
+		serviceOrdersRoutes.GET("/", canView, serviceOrderHandler.ListServiceOrders)
 	}
 }
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addServiceOrderStreamRoutes registers the stream outside the protected group, as it also accepts
+// the token in the query string for EventSource clients before authenticating it the usual way
+func addServiceOrderStreamRoutes(rg *gin.RouterGroup, streamHandler *http.ServiceOrderStreamHandler, authMiddleware gin.HandlerFunc) {
+
+	rg.GET(PathServiceOrders+"/stream", middleware.QueryTokenMiddleware(), authMiddleware, streamHandler.Stream)
+}
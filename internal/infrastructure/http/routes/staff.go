@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addStaffRoutes(rg *gin.RouterGroup, staffHandler *http.StaffHandler) {
+
+	staff := rg.Group(PathStaff, middleware.RequirePermission(valueobject.PermissionManageStaff))
+	{
+		staff.POST("/", staffHandler.CreateStaff)
+		staff.GET("/", staffHandler.ListStaff)
+		staff.GET("/roles", staffHandler.ListRoles)
+		staff.GET("/:id", staffHandler.GetStaff)
+		staff.PUT("/:id", staffHandler.UpdateStaff)
+		staff.DELETE("/:id", staffHandler.DeleteStaff)
+	}
+}
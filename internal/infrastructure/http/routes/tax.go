@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addTaxRoutes(rg *gin.RouterGroup, taxHandler *http.TaxHandler) {
+
+	taxRules := rg.Group(PathTaxRules, middleware.RequirePermission(valueobject.PermissionManageSettings))
+	{
+		taxRules.GET("/", taxHandler.ListTaxRules)
+		taxRules.POST("/", taxHandler.CreateTaxRule)
+		taxRules.PUT("/:id", taxHandler.UpdateTaxRule)
+		taxRules.DELETE("/:id", taxHandler.DeleteTaxRule)
+	}
+
+	rg.GET(PathReports+"/taxes", middleware.RequirePermission(valueobject.PermissionViewReports), taxHandler.GetTaxReport)
+}
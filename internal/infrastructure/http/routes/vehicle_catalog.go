@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addVehicleCatalogRoutes(rg *gin.RouterGroup, vehicleCatalogHandler *http.VehicleCatalogHandler) {
+	catalog := rg.Group(PathVehicleCatalog)
+	{
+		catalog.GET("/brands", middleware.RequirePermission(valueobject.PermissionViewCatalog), vehicleCatalogHandler.ListVehicleBrands)
+		catalog.GET("/models", middleware.RequirePermission(valueobject.PermissionViewCatalog), vehicleCatalogHandler.ListVehicleModels)
+		catalog.POST("/import", middleware.RequirePermission(valueobject.PermissionManageCatalog), vehicleCatalogHandler.ImportFipeDump)
+	}
+}
@@ -1,13 +1,15 @@
 package routes
 
 import (
+	"mecanica_xpto/internal/domain/model/valueobject"
 	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 func addVehicleRoutes(rg *gin.RouterGroup, vehicleHandler *http.VehicleHandler) {
-	vehicles := rg.Group(PathVehicles)
+	vehicles := rg.Group(PathVehicles, middleware.RequirePermission(valueobject.PermissionManageCustomers))
 	{
 		vehicles.GET("/", vehicleHandler.GetVehicles)
 		vehicles.GET("/customer/:customerID", vehicleHandler.GetVehicleByCustomerID)
@@ -18,3 +20,25 @@ func addVehicleRoutes(rg *gin.RouterGroup, vehicleHandler *http.VehicleHandler)
 		vehicles.DELETE("/:id", vehicleHandler.DeleteVehicle)
 	}
 }
+
+func addVehicleImportRoutes(rg *gin.RouterGroup, vehicleImportHandler *http.VehicleImportHandler) {
+	vehicles := rg.Group(PathVehicles, middleware.RequirePermission(valueobject.PermissionManageCustomers))
+	{
+		vehicles.POST("/import", vehicleImportHandler.ImportVehicles)
+		vehicles.GET("/export", vehicleImportHandler.ExportVehicles)
+	}
+}
+
+func addVehicleOwnershipRoutes(rg *gin.RouterGroup, vehicleOwnershipHandler *http.VehicleOwnershipHandler) {
+	vehicles := rg.Group(PathVehicles, middleware.RequirePermission(valueobject.PermissionManageCustomers))
+	{
+		vehicles.POST("/:id/transfer", vehicleOwnershipHandler.TransferVehicle)
+		vehicles.GET("/:id/owners", vehicleOwnershipHandler.ListVehicleOwners)
+	}
+}
+
+// addVehicleHistoryRoutes registers the history apart from the vehicle routes, since it is read by
+// everyone who works on service orders rather than by those who manage customers
+func addVehicleHistoryRoutes(rg *gin.RouterGroup, vehicleHistoryHandler *http.VehicleHistoryHandler) {
+	rg.GET(PathVehicles+"/:id/history", middleware.RequirePermission(valueobject.PermissionViewServiceOrders), vehicleHistoryHandler.GetVehicleHistory)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/infrastructure/http"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func addWebhookRoutes(rg *gin.RouterGroup, webhookHandler *http.WebhookHandler) {
+
+	webhooks := rg.Group(PathWebhooks, middleware.RequirePermission(valueobject.PermissionManageSettings))
+	{
+		webhooks.POST("/", webhookHandler.CreateSubscription)
+		webhooks.GET("/", webhookHandler.ListSubscriptions)
+		webhooks.GET("/:id", webhookHandler.GetSubscription)
+		webhooks.PUT("/:id", webhookHandler.UpdateSubscription)
+		webhooks.DELETE("/:id", webhookHandler.DeleteSubscription)
+		webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+		webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+	}
+}
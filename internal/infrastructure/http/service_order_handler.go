@@ -45,7 +45,9 @@ func NewServiceOrderHandler(useCase usecase.IServiceOrderUseCase) *ServiceOrderH
 // @Success 201 {object} entities.ServiceOrder
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /service-orders [post]
 func (h *ServiceOrderHandler) CreateServiceOrder(g *gin.Context) {
 	var serviceOrder entities.ServiceOrder
@@ -62,6 +64,14 @@ func (h *ServiceOrderHandler) CreateServiceOrder(g *gin.Context) {
 			g.JSON(404, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, usecase.ErrCreditLimitExceeded) {
+			g.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrCreditCheckFailed) {
+			g.JSON(http.StatusServiceUnavailable, gin.H{"error": usecase.ErrCreditCheckFailed.Error(), "details": err.Error()})
+			return
+		}
 
 		g.JSON(500, gin.H{"error": "Failed to create service order", "details": err.Error()})
 		log.Error().Msgf("Error creating service order: %v", err)
@@ -73,7 +83,7 @@ func (h *ServiceOrderHandler) CreateServiceOrder(g *gin.Context) {
 
 // UpdateServiceOrderDiagnosis godoc
 // @Summary Update service order diagnosis
-// @Description Update the diagnosis information of a service order
+// @Description Update the diagnosis information of a service order. An estimate that takes the open balance of a corporate account beyond its credit limit is refused.
 // @Tags Service Orders
 // @Security Bearer
 // @Accept json
@@ -83,7 +93,9 @@ func (h *ServiceOrderHandler) CreateServiceOrder(g *gin.Context) {
 // @Success 200 {object} entities.ServiceOrder
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /service-orders/{id}/diagnosis [patch]
 func (h *ServiceOrderHandler) UpdateServiceOrderDiagnosis(g *gin.Context) {
 	var serviceOrder entities.ServiceOrder
@@ -111,10 +123,22 @@ func (h *ServiceOrderHandler) UpdateServiceOrderDiagnosis(g *gin.Context) {
 
 		if errors.Is(err, usecase.ErrInvalidTransitionStatusToDiagnosis) ||
 			errors.Is(err, usecase.ErrInvalidStatus) ||
-			errors.Is(err, usecase.ErrInvalidFlow) {
+			errors.Is(err, usecase.ErrInvalidFlow) ||
+			errors.Is(err, usecase.ErrCouponNotFound) ||
+			errors.Is(err, usecase.ErrCouponNotValid) ||
+			errors.Is(err, usecase.ErrCouponUsageLimitReached) ||
+			errors.Is(err, usecase.ErrInvalidDiscount) {
 			g.JSON(400, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, usecase.ErrCreditLimitExceeded) {
+			g.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrCreditCheckFailed) {
+			g.JSON(http.StatusServiceUnavailable, gin.H{"error": usecase.ErrCreditCheckFailed.Error(), "details": err.Error()})
+			return
+		}
 
 		g.JSON(500, gin.H{"error": "Failed to update service order", "details": err.Error()})
 		return
@@ -125,7 +149,7 @@ func (h *ServiceOrderHandler) UpdateServiceOrderDiagnosis(g *gin.Context) {
 
 // UpdateServiceOrderEstimate godoc
 // @Summary Update service order estimate
-// @Description Update the estimate information of a service order
+// @Description Update the estimate information of a service order. The estimate of a company whose corporate account names approvers must be approved with the approver_contact_id of one of them.
 // @Tags Service Orders
 // @Security Bearer
 // @Accept json
@@ -135,6 +159,7 @@ func (h *ServiceOrderHandler) UpdateServiceOrderDiagnosis(g *gin.Context) {
 // @Success 200 {object} entities.ServiceOrder
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /service-orders/{id}/estimate [patch]
 func (h *ServiceOrderHandler) UpdateServiceOrderEstimate(g *gin.Context) {
@@ -162,10 +187,16 @@ func (h *ServiceOrderHandler) UpdateServiceOrderEstimate(g *gin.Context) {
 
 		if errors.Is(err, usecase.ErrInvalidTransitionStatusToEstimate) ||
 			errors.Is(err, usecase.ErrInvalidStatus) ||
-			errors.Is(err, usecase.ErrInvalidFlow) {
+			errors.Is(err, usecase.ErrInvalidFlow) ||
+			errors.Is(err, usecase.ErrDiscountApprovalPending) {
 			g.JSON(400, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, usecase.ErrEstimateApproverRequired) ||
+			errors.Is(err, usecase.ErrNotEstimateApprover) {
+			g.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 
 		g.JSON(500, gin.H{"error": "Failed to update service order", "details": err.Error()})
 		return
@@ -237,6 +268,7 @@ func (h *ServiceOrderHandler) UpdateServiceOrderExecution(g *gin.Context) {
 // @Success 200 {object} entities.ServiceOrder
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /service-orders/{id}/delivery [patch]
 func (h *ServiceOrderHandler) UpdateServiceOrderDelivery(g *gin.Context) {
@@ -268,6 +300,10 @@ func (h *ServiceOrderHandler) UpdateServiceOrderDelivery(g *gin.Context) {
 			g.JSON(400, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, usecase.ErrAdditionalRepairPending) {
+			g.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		g.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service order", "details": err.Error()})
 		return
 	}
@@ -3,12 +3,14 @@ package http
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"mecanica_xpto/internal/domain/mocks"
 	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/mock/gomock"
@@ -56,6 +58,30 @@ func TestCreateServiceOrder(t *testing.T) {
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("expected 500, got %d", w.Code)
 	}
+
+	// Credit limit not checked
+	mockUC.EXPECT().CreateServiceOrder(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: %v", usecase.ErrCreditCheckFailed, errors.New("db down")))
+	req, _ = http.NewRequest("POST", "/os", bytes.NewBufferString(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+}
+
+func TestUpdateServiceOrderDiagnosis_CreditLimit(t *testing.T) {
+	mockUC, h, r := setupServiceOrderHandlerTest(t)
+	r.PATCH("/os/:id/diagnosis", h.UpdateServiceOrderDiagnosis)
+
+	mockUC.EXPECT().UpdateServiceOrder(gomock.Any(), gomock.Any(), DIAGNOSIS).Return(nil, usecase.ErrCreditLimitExceeded)
+	req, _ := http.NewRequest("PATCH", "/os/1/diagnosis", bytes.NewBufferString(`{"service_order_status":"EM DIAGNÓSTICO"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", w.Code)
+	}
 }
 
 func TestGetServiceOrder(t *testing.T) {
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamKeepAliveInterval is how often a comment is sent on idle streams, so proxies keep them open
+const streamKeepAliveInterval = 15 * time.Second
+
+// ServiceOrderStreamHandler pushes service order updates to the reception board and customer pages
+// @title Service Order Stream API
+// @version 1.0
+// @description Server-Sent Events stream of service order updates in the workshop management system
+type ServiceOrderStreamHandler struct {
+	usecase usecase.IServiceOrderStreamUseCase
+}
+
+func NewServiceOrderStreamHandler(usecase usecase.IServiceOrderStreamUseCase) *ServiceOrderStreamHandler {
+	return &ServiceOrderStreamHandler{usecase: usecase}
+}
+
+// Stream godoc
+// @Summary Follow service order updates live
+// @Description Server-Sent Events stream of status changes, new and approved additional repairs and payments. Each update is sent as a "service_order" event with the JSON of the update. Filter by service order or customer; customers only receive their own service orders. Browsers can send the JWT in the access_token query parameter, as EventSource cannot set headers.
+// @Tags Service Orders
+// @Security Bearer
+// @Produce text/event-stream
+// @Param service_order_id query int false "Service Order ID"
+// @Param customer_id query int false "Customer ID"
+// @Param access_token query string false "JWT, when the Authorization header cannot be sent"
+// @Success 200 {object} entities.ServiceOrderUpdate
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 401 {object} pkg.ErrorResponse
+// @Router /service-orders/stream [get]
+func (h *ServiceOrderStreamHandler) Stream(c *gin.Context) {
+	var filter entities.ServiceOrderUpdateFilter
+	if value := c.Query("service_order_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(errInvalidServiceOrderID.HTTPStatus, errInvalidServiceOrderID.ToHTTPError())
+			return
+		}
+		filter.ServiceOrderID = uint(id)
+	}
+	if value := c.Query("customer_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(errInvalidCustomerID.HTTPStatus, errInvalidCustomerID.ToHTTPError())
+			return
+		}
+		filter.CustomerID = uint(id)
+	}
+
+	updates, unsubscribe, err := h.usecase.Subscribe(c.Request.Context(), c.GetString(middleware.ContextUserEmail), filter)
+	if err != nil {
+		appErr := pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+		if errors.Is(err, usecase.ErrStreamUserNotFound) {
+			appErr = pkg.NewDomainErrorSimple("UNAUTHORIZED", "User not found", http.StatusUnauthorized)
+		}
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// disables response buffering on nginx, which would hold the events back
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			c.SSEvent("service_order", update)
+		case <-keepAlive.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+)
+
+// stubStreamUseCase hands out a channel with the given updates, closed so the stream ends
+type stubStreamUseCase struct {
+	updates      []entities.ServiceOrderUpdate
+	err          error
+	filter       entities.ServiceOrderUpdateFilter
+	email        string
+	unsubscribed bool
+}
+
+func (s *stubStreamUseCase) Subscribe(_ context.Context, email string, filter entities.ServiceOrderUpdateFilter) (<-chan entities.ServiceOrderUpdate, func(), error) {
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+	s.email, s.filter = email, filter
+	updates := make(chan entities.ServiceOrderUpdate, len(s.updates))
+	for _, update := range s.updates {
+		updates <- update
+	}
+	close(updates)
+	return updates, func() { s.unsubscribed = true }, nil
+}
+
+func (s *stubStreamUseCase) HandleDomainEvent(context.Context, entities.DomainEvent) error {
+	return nil
+}
+
+func setupServiceOrderStreamHandlerTest(uc usecase.IServiceOrderStreamUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/v1/service-orders/stream", func(c *gin.Context) {
+		c.Set(middleware.ContextUserEmail, "recepcao@xpto.com")
+	}, NewServiceOrderStreamHandler(uc).Stream)
+	return r
+}
+
+func TestServiceOrderStreamHandler_Stream(t *testing.T) {
+	t.Run("streams the updates as server-sent events", func(t *testing.T) {
+		uc := &stubStreamUseCase{updates: []entities.ServiceOrderUpdate{
+			{EventID: 1, Type: valueobject.EventServiceOrderStatusChanged, ServiceOrderID: 7, Status: "EM EXECUÇÃO"},
+		}}
+		r := setupServiceOrderStreamHandlerTest(uc)
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/service-orders/stream?service_order_id=7&customer_id=3", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+			t.Fatalf("expected text/event-stream, got %s", ct)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, "event:service_order") || !strings.Contains(body, `"service_order_id":7`) {
+			t.Fatalf("unexpected stream body: %s", body)
+		}
+		if uc.filter.ServiceOrderID != 7 || uc.filter.CustomerID != 3 || uc.email != "recepcao@xpto.com" {
+			t.Fatalf("unexpected subscription: %+v %s", uc.filter, uc.email)
+		}
+		if !uc.unsubscribed {
+			t.Fatal("expected the client to be unsubscribed")
+		}
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		r := setupServiceOrderStreamHandlerTest(&stubStreamUseCase{})
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/service-orders/stream?customer_id=abc", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		r := setupServiceOrderStreamHandlerTest(&stubStreamUseCase{err: usecase.ErrStreamUserNotFound})
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/service-orders/stream", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidStaffID    = pkg.NewDomainErrorSimple("INVALID_STAFF_ID", "Invalid staff ID", http.StatusBadRequest)
+	errInvalidStaffInput = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data", http.StatusBadRequest)
+)
+
+// StaffHandler handles the employees of the workshop and the roles they can be given
+// @title Staff API
+// @version 1.0
+// @description API for managing the staff users and their roles in the workshop management system
+type StaffHandler struct {
+	usecase usecase.IStaffUseCase
+}
+
+func NewStaffHandler(usecase usecase.IStaffUseCase) *StaffHandler {
+	return &StaffHandler{usecase: usecase}
+}
+
+func mapStaffError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrStaffNotFound):
+		return pkg.NewDomainErrorSimple("STAFF_NOT_FOUND", "Staff member not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidStaff):
+		return pkg.NewDomainErrorSimple("INVALID_STAFF", "Staff member needs a full name and a valid e-mail", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidStaffRole):
+		return pkg.NewDomainErrorSimple("INVALID_STAFF_ROLE", "Role must be one of manager, receptionist, mechanic, stock_keeper or cashier", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidStaffPassword):
+		return pkg.NewDomainErrorSimple("INVALID_PASSWORD", "Password must have at least 8 characters with upper and lower case letters and digits", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrStaffEmailInUse):
+		return pkg.NewDomainErrorSimple("EMAIL_IN_USE", "E-mail is already in use", http.StatusConflict)
+	case errors.Is(err, usecase.ErrStaffSelfDelete):
+		return pkg.NewDomainErrorSimple("STAFF_SELF_DELETE", "Staff members cannot delete themselves", http.StatusConflict)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// CreateStaff godoc
+// @Summary Create a staff member
+// @Description Register an employee with the user they log in with and their role
+// @Tags Staff
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param staff body entities.StaffInput true "Staff member"
+// @Success 201 {object} entities.Staff
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /staff [post]
+func (h *StaffHandler) CreateStaff(c *gin.Context) {
+	var input entities.StaffInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidStaffInput.HTTPStatus, errInvalidStaffInput.ToHTTPError())
+		return
+	}
+
+	staff, err := h.usecase.CreateStaff(c.Request.Context(), input)
+	if err != nil {
+		appErr := mapStaffError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, staff)
+}
+
+// ListStaff godoc
+// @Summary List staff members
+// @Description List the employees with their roles and permissions
+// @Tags Staff
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} entities.Staff
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /staff [get]
+func (h *StaffHandler) ListStaff(c *gin.Context) {
+	staff, err := h.usecase.ListStaff(c.Request.Context())
+	if err != nil {
+		appErr := mapStaffError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// ListRoles godoc
+// @Summary List staff roles
+// @Description List the roles that can be given to an employee and the permissions each one grants
+// @Tags Staff
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} entities.RolePermissions
+// @Failure 403 {object} map[string]string
+// @Router /staff/roles [get]
+func (h *StaffHandler) ListRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, h.usecase.ListRoles())
+}
+
+// GetStaff godoc
+// @Summary Get a staff member
+// @Description Get an employee by ID
+// @Tags Staff
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Success 200 {object} entities.Staff
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /staff/{id} [get]
+func (h *StaffHandler) GetStaff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidStaffID.HTTPStatus, errInvalidStaffID.ToHTTPError())
+		return
+	}
+
+	staff, err := h.usecase.GetStaff(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapStaffError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// UpdateStaff godoc
+// @Summary Update a staff member
+// @Description Update the record, e-mail and role of an employee. An empty password keeps the current one. A new role takes effect on the next login.
+// @Tags Staff
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Param staff body entities.StaffInput true "Staff member"
+// @Success 200 {object} entities.Staff
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /staff/{id} [put]
+func (h *StaffHandler) UpdateStaff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidStaffID.HTTPStatus, errInvalidStaffID.ToHTTPError())
+		return
+	}
+	var input entities.StaffInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidStaffInput.HTTPStatus, errInvalidStaffInput.ToHTTPError())
+		return
+	}
+
+	staff, err := h.usecase.UpdateStaff(c.Request.Context(), uint(id), input)
+	if err != nil {
+		appErr := mapStaffError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// DeleteStaff godoc
+// @Summary Delete a staff member
+// @Description Remove an employee and their user, so they can no longer log in
+// @Tags Staff
+// @Security Bearer
+// @Param id path int true "Staff ID"
+// @Success 204
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /staff/{id} [delete]
+func (h *StaffHandler) DeleteStaff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidStaffID.HTTPStatus, errInvalidStaffID.ToHTTPError())
+		return
+	}
+
+	if err := h.usecase.DeleteStaff(c.Request.Context(), uint(id), c.GetString(middleware.ContextUserEmail)); err != nil {
+		appErr := mapStaffError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
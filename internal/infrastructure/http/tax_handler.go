@@ -0,0 +1,194 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const reportDateLayout = "2006-01-02"
+
+var (
+	errInvalidTaxRuleInput = pkg.NewDomainErrorSimple("INVALID_TAX_RULE_INPUT", "Invalid tax rule input", http.StatusBadRequest)
+	errInvalidTaxRuleID    = pkg.NewDomainErrorSimple("INVALID_TAX_RULE_ID", "Invalid tax rule ID", http.StatusBadRequest)
+	errInvalidReportPeriod = pkg.NewDomainErrorSimple("INVALID_REPORT_PERIOD", "Query params from and to must be dates in the format YYYY-MM-DD", http.StatusBadRequest)
+)
+
+// TaxHandler handles HTTP requests for tax rules and tax reports
+// @title Tax API
+// @version 1.0
+// @description API for managing ISS and ICMS taxes in the workshop management system
+type TaxHandler struct {
+	usecase usecase.ITaxUseCase
+}
+
+func NewTaxHandler(usecase usecase.ITaxUseCase) *TaxHandler {
+	return &TaxHandler{usecase: usecase}
+}
+
+func mapTaxError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrTaxRuleNotFound):
+		return pkg.NewDomainErrorSimple("TAX_RULE_NOT_FOUND", "Tax rule not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrTaxRuleAlreadyExists):
+		return pkg.NewDomainErrorSimple("TAX_RULE_ALREADY_EXISTS", "Tax rule already exists for this category", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInvalidTaxRule):
+		return pkg.NewDomainErrorSimple("INVALID_TAX_RULE", "Tax type must be ISS or ICMS and rate must be between 0 and 100", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidReportPeriod):
+		return errInvalidReportPeriod
+	case errors.Is(err, usecase.ErrInvalidID):
+		return pkg.NewDomainErrorSimple("INVALID_ID", "Invalid ID", http.StatusBadRequest)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// CreateTaxRule godoc
+// @Summary Create a tax rule
+// @Description Create the ISS or ICMS rate of a service or parts supply category. An empty category sets the fallback rate of the tax.
+// @Tags Taxes
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param rule body entities.TaxRule true "Tax Rule Information"
+// @Success 201 {object} entities.TaxRule
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /tax-rules [post]
+func (h *TaxHandler) CreateTaxRule(c *gin.Context) {
+	var input entities.TaxRule
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidTaxRuleInput.HTTPStatus, errInvalidTaxRuleInput.ToHTTPError())
+		return
+	}
+
+	rule, err := h.usecase.CreateRule(c.Request.Context(), &input)
+	if err != nil {
+		appErr := mapTaxError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateTaxRule godoc
+// @Summary Update a tax rule
+// @Description Update the rate and description of a tax rule
+// @Tags Taxes
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Tax Rule ID"
+// @Param rule body entities.TaxRule true "Tax Rule Information"
+// @Success 204 "No Content"
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /tax-rules/{id} [put]
+func (h *TaxHandler) UpdateTaxRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidTaxRuleID.HTTPStatus, errInvalidTaxRuleID.ToHTTPError())
+		return
+	}
+
+	var input entities.TaxRule
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidTaxRuleInput.HTTPStatus, errInvalidTaxRuleInput.ToHTTPError())
+		return
+	}
+	input.ID = uint(id)
+
+	if err := h.usecase.UpdateRule(c.Request.Context(), &input); err != nil {
+		appErr := mapTaxError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListTaxRules godoc
+// @Summary List all tax rules
+// @Description Get a list of all ISS and ICMS rules
+// @Tags Taxes
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} entities.TaxRule
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /tax-rules [get]
+func (h *TaxHandler) ListTaxRules(c *gin.Context) {
+	rules, err := h.usecase.ListRules(c.Request.Context())
+	if err != nil {
+		appErr := mapTaxError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// DeleteTaxRule godoc
+// @Summary Delete a tax rule
+// @Description Delete a tax rule by its ID
+// @Tags Taxes
+// @Security Bearer
+// @Param id path int true "Tax Rule ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /tax-rules/{id} [delete]
+func (h *TaxHandler) DeleteTaxRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidTaxRuleID.HTTPStatus, errInvalidTaxRuleID.ToHTTPError())
+		return
+	}
+
+	if err := h.usecase.DeleteRule(c.Request.Context(), uint(id)); err != nil {
+		appErr := mapTaxError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTaxReport godoc
+// @Summary Tax report
+// @Description Get the ISS and ICMS totals, by category, of the service orders paid within a period
+// @Tags Taxes
+// @Security Bearer
+// @Produce json
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD)"
+// @Success 200 {object} entities.TaxReport
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /reports/taxes [get]
+func (h *TaxHandler) GetTaxReport(c *gin.Context) {
+	from, errFrom := time.Parse(reportDateLayout, c.Query("from"))
+	to, errTo := time.Parse(reportDateLayout, c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(errInvalidReportPeriod.HTTPStatus, errInvalidReportPeriod.ToHTTPError())
+		return
+	}
+
+	// the end date is inclusive
+	report, err := h.usecase.GetTaxReport(c.Request.Context(), from, to.Add(24*time.Hour-time.Nanosecond))
+	if err != nil {
+		appErr := mapTaxError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
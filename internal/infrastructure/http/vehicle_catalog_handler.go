@@ -0,0 +1,127 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/pkg"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFipeDumpSize is well above a full FIPE dump, which has tens of thousands of model years
+const maxFipeDumpSize = 50 << 20
+
+var (
+	errInvalidFipeDumpFile = pkg.NewDomainErrorSimple("INVALID_FILE", "The FIPE dump must be sent in the file field", http.StatusBadRequest)
+	errFipeDumpTooLarge    = pkg.NewDomainErrorSimple("FILE_TOO_LARGE", "The FIPE dump exceeds the maximum size", http.StatusRequestEntityTooLarge)
+)
+
+// VehicleCatalogHandler handles the catalog of vehicle brands, models and years
+// @title Vehicle Catalog API
+// @version 1.0
+// @description API for the catalog of vehicle brands, models and years imported from the FIPE table
+type VehicleCatalogHandler struct {
+	usecase usecase.IVehicleCatalogUseCase
+}
+
+func NewVehicleCatalogHandler(usecase usecase.IVehicleCatalogUseCase) *VehicleCatalogHandler {
+	return &VehicleCatalogHandler{usecase: usecase}
+}
+
+func mapVehicleCatalogError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidFipeDump):
+		return pkg.NewDomainErrorSimple("INVALID_FIPE_DUMP", "The FIPE dump must be a CSV file with brand, model and year columns", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrVehicleBrandNeeded):
+		return pkg.NewDomainErrorSimple("BRAND_REQUIRED", "The brand is required to list the models", http.StatusBadRequest)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// ImportFipeDump godoc
+// @Summary Import a FIPE dump into the vehicle catalog
+// @Description Import the brands, models and years of a FIPE dump in CSV, separated by semicolons or commas, with the columns marca/brand, modelo/model and ano_modelo/year, and optionally codigo_fipe. Model years already in the catalog are kept; the lines that cannot be read are reported.
+// @Tags Vehicle Catalog
+// @Security Bearer
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "FIPE dump"
+// @Success 200 {object} entities.VehicleCatalogImport
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 413 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /vehicle-catalog/import [post]
+func (h *VehicleCatalogHandler) ImportFipeDump(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFipeDumpSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(errFipeDumpTooLarge.HTTPStatus, errFipeDumpTooLarge.ToHTTPError())
+			return
+		}
+		c.JSON(errInvalidFipeDumpFile.HTTPStatus, errInvalidFipeDumpFile.ToHTTPError())
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		appErr := mapVehicleCatalogError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+	defer file.Close()
+
+	result, err := h.usecase.ImportFipe(c.Request.Context(), file)
+	if err != nil {
+		appErr := mapVehicleCatalogError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListVehicleBrands godoc
+// @Summary List the vehicle brands
+// @Description List the brands of the vehicle catalog
+// @Tags Vehicle Catalog
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} string
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /vehicle-catalog/brands [get]
+func (h *VehicleCatalogHandler) ListVehicleBrands(c *gin.Context) {
+	brands, err := h.usecase.ListBrands(c.Request.Context())
+	if err != nil {
+		appErr := mapVehicleCatalogError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, brands)
+}
+
+// ListVehicleModels godoc
+// @Summary List the vehicle models
+// @Description List the model years of a brand of the vehicle catalog, narrowed down to a model when one is given
+// @Tags Vehicle Catalog
+// @Security Bearer
+// @Produce json
+// @Param brand query string true "Brand"
+// @Param model query string false "Model"
+// @Success 200 {array} entities.VehicleModel
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /vehicle-catalog/models [get]
+func (h *VehicleCatalogHandler) ListVehicleModels(c *gin.Context) {
+	models, err := h.usecase.ListModels(c.Request.Context(), c.Query("brand"), c.Query("model"))
+	if err != nil {
+		appErr := mapVehicleCatalogError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, models)
+}
@@ -42,6 +42,12 @@ func mapVehicleError(err error) *pkg.AppError {
 		return pkg.NewDomainErrorSimple("VEHICLE_EXISTS", "Vehicle already exists", http.StatusConflict)
 	case errors.Is(err, usecase.ErrInvalidID):
 		return pkg.NewDomainErrorSimple("INVALID_ID", "Invalid vehicle ID", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrVehicleOwnerChange):
+		return pkg.NewDomainErrorSimple("VEHICLE_OWNER_CHANGE", "The owner of a vehicle is changed by transferring it", http.StatusConflict)
+	case errors.Is(err, usecase.ErrInvalidVIN):
+		return pkg.NewDomainErrorSimple("INVALID_VIN", "Invalid VIN", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrVehicleNotInCatalog):
+		return pkg.NewDomainErrorSimple("VEHICLE_NOT_IN_CATALOG", "Brand, model and year not found in the vehicle catalog", http.StatusUnprocessableEntity)
 	default:
 		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
 	}
@@ -164,8 +170,9 @@ func (v VehicleHandler) GetVehicleByPlate(c *gin.Context) {
 // @Produce json
 // @Param vehicle body entities.Vehicle true "Vehicle information"
 // @Success 201 {object} pkg.ErrorResponse{message=string} "Vehicle created successfully"
-// @Failure 400 {object} pkg.ErrorResponse "Invalid input data or plate format"
-// @Failure 409 {object} pkg.ErrorResponse "Vehicle already exists"
+// @Failure 400 {object} pkg.ErrorResponse "Invalid input data, plate format or VIN"
+// @Failure 409 {object} pkg.ErrorResponse "Vehicle already exists, with the same plate in the old or the Mercosul format"
+// @Failure 422 {object} pkg.ErrorResponse "Brand, model and year not found in the vehicle catalog"
 // @Failure 500 {object} pkg.ErrorResponse "Internal server error"
 // @Router /vehicles [post]
 func (v VehicleHandler) CreateVehicle(c *gin.Context) {
@@ -194,8 +201,10 @@ func (v VehicleHandler) CreateVehicle(c *gin.Context) {
 // @Param id path int true "Vehicle ID"
 // @Param updates body map[string]interface{} true "Fields to update"
 // @Success 200 {object} pkg.ErrorResponse{message=string} "Vehicle updated successfully"
-// @Failure 400 {object} pkg.ErrorResponse "Invalid input data, ID format or plate format"
+// @Failure 400 {object} pkg.ErrorResponse "Invalid input data, ID format, plate format or VIN"
 // @Failure 404 {object} pkg.ErrorResponse "Vehicle not found"
+// @Failure 409 {object} pkg.ErrorResponse "A different customer_id was sent; the vehicle must be transferred instead"
+// @Failure 422 {object} pkg.ErrorResponse "Brand, model and year not found in the vehicle catalog"
 // @Failure 500 {object} pkg.ErrorResponse "Internal server error"
 // @Router /vehicles/{id} [patch]
 func (v VehicleHandler) UpdateVehicle(c *gin.Context) {
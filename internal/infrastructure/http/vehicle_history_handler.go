@@ -0,0 +1,50 @@
+package http
+
+import (
+	"mecanica_xpto/internal/domain/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VehicleHistoryHandler handles the maintenance history of the vehicles
+// @title Vehicle History API
+// @version 1.0
+// @description API for the maintenance history and odometer timeline of the vehicles
+type VehicleHistoryHandler struct {
+	usecase usecase.IVehicleHistoryUseCase
+}
+
+func NewVehicleHistoryHandler(usecase usecase.IVehicleHistoryUseCase) *VehicleHistoryHandler {
+	return &VehicleHistoryHandler{usecase: usecase}
+}
+
+// GetVehicleHistory godoc
+// @Summary Get the maintenance history of a vehicle
+// @Description List the service orders of the vehicle in chronological order, with the services performed, the parts replaced and the odometer read at each check-in, and summarise the last visit, oil change and brake service
+// @Tags Vehicles
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Success 200 {object} entities.VehicleHistory
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /vehicles/{id}/history [get]
+func (h *VehicleHistoryHandler) GetVehicleHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidVehicleID.HTTPStatus, errInvalidVehicleID.ToHTTPError())
+		return
+	}
+
+	history, err := h.usecase.GetVehicleHistory(c.Request.Context(), uint(id))
+	if err != nil {
+		appErr := mapVehicleError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
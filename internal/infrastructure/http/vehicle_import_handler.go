@@ -0,0 +1,124 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxVehicleFileSize is well above the fleet of any customer
+const maxVehicleFileSize = 5 << 20
+
+var (
+	errInvalidVehicleFileField = pkg.NewDomainErrorSimple("INVALID_FILE", "The vehicles must be sent in the file field", http.StatusBadRequest)
+	errVehicleFileTooLarge     = pkg.NewDomainErrorSimple("FILE_TOO_LARGE", "The vehicle file exceeds the maximum size", http.StatusRequestEntityTooLarge)
+	errInvalidDryRun           = pkg.NewDomainErrorSimple("INVALID_DRY_RUN", "dry_run must be true or false", http.StatusBadRequest)
+)
+
+// VehicleImportHandler handles the import and export of vehicles in CSV
+// @title Vehicle Import API
+// @version 1.0
+// @description API for registering many vehicles at once from a CSV file, and exporting them in the same format
+type VehicleImportHandler struct {
+	usecase usecase.IVehicleImportUseCase
+}
+
+func NewVehicleImportHandler(usecase usecase.IVehicleImportUseCase) *VehicleImportHandler {
+	return &VehicleImportHandler{usecase: usecase}
+}
+
+func mapVehicleImportError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidVehicleFile):
+		return pkg.NewDomainErrorSimple("INVALID_VEHICLE_FILE", "The vehicle file must be a CSV file with plate, brand, model, year and customer_document columns", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidDocumentFormat):
+		return pkg.NewDomainErrorSimple("INVALID_DOCUMENT", "Invalid customer document", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrCustomerNotFound):
+		return pkg.NewDomainErrorSimple("CUSTOMER_NOT_FOUND", "Customer not found", http.StatusNotFound)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// ImportVehicles godoc
+// @Summary Import vehicles from a CSV file
+// @Description Register the vehicles of a CSV file, separated by commas or semicolons, with the columns plate, brand, model, year and customer_document, and optionally vin. Each line is checked as a vehicle registered on its own and the invalid ones are reported; the vehicles are only registered when every line is valid. With dry_run the lines are only checked.
+// @Tags Vehicles
+// @Security Bearer
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Vehicles"
+// @Param dry_run query bool false "Only check the lines"
+// @Success 200 {object} entities.VehicleImport
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 413 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /vehicles/import [post]
+func (h *VehicleImportHandler) ImportVehicles(c *gin.Context) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(errInvalidDryRun.HTTPStatus, errInvalidDryRun.ToHTTPError())
+			return
+		}
+		dryRun = parsed
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxVehicleFileSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(errVehicleFileTooLarge.HTTPStatus, errVehicleFileTooLarge.ToHTTPError())
+			return
+		}
+		c.JSON(errInvalidVehicleFileField.HTTPStatus, errInvalidVehicleFileField.ToHTTPError())
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		appErr := mapVehicleImportError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+	defer file.Close()
+
+	result, err := h.usecase.ImportVehicles(c.Request.Context(), file, dryRun)
+	if err != nil {
+		appErr := mapVehicleImportError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportVehicles godoc
+// @Summary Export vehicles as CSV
+// @Description Download the vehicles in the format read by the import, only those of a customer when their document is given
+// @Tags Vehicles
+// @Security Bearer
+// @Produce text/csv
+// @Param customer_document query string false "CPF or CNPJ of the customer"
+// @Success 200 {file} file "CSV file"
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /vehicles/export [get]
+func (h *VehicleImportHandler) ExportVehicles(c *gin.Context) {
+	var content bytes.Buffer
+	if err := h.usecase.ExportVehicles(c.Request.Context(), &content, c.Query("customer_document")); err != nil {
+		appErr := mapVehicleImportError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\"veiculos.csv\"")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", content.Bytes())
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/internal/infrastructure/http/middleware"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidVehicleTransferInput = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data, customer_id is required", http.StatusBadRequest)
+
+// VehicleOwnershipHandler handles the transfer of the vehicles between customers
+// @title Vehicle Ownership API
+// @version 1.0
+// @description API for the transfer of the vehicles and the history of their owners
+type VehicleOwnershipHandler struct {
+	usecase usecase.IVehicleOwnershipUseCase
+}
+
+func NewVehicleOwnershipHandler(usecase usecase.IVehicleOwnershipUseCase) *VehicleOwnershipHandler {
+	return &VehicleOwnershipHandler{usecase: usecase}
+}
+
+func mapVehicleOwnershipError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrVehicleNotFound):
+		return pkg.NewDomainErrorSimple("VEHICLE_NOT_FOUND", "Vehicle not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrCustomerNotFound):
+		return pkg.NewDomainErrorSimple("CUSTOMER_NOT_FOUND", "Customer not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrVehicleAlreadyOwned):
+		return pkg.NewDomainErrorSimple("VEHICLE_ALREADY_OWNED", "The vehicle already belongs to the customer", http.StatusConflict)
+	case errors.Is(err, usecase.ErrVehicleHasActiveServiceOrder):
+		return pkg.NewDomainErrorSimple("VEHICLE_HAS_ACTIVE_SERVICE_ORDER", "The vehicle cannot be transferred while it has an active service order", http.StatusConflict)
+	case errors.Is(err, usecase.ErrVehicleOwnerChanged):
+		return pkg.NewDomainErrorSimple("VEHICLE_OWNER_CHANGED", "The vehicle was transferred in the meantime, try again", http.StatusConflict)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// TransferVehicle godoc
+// @Summary Transfer a vehicle to another customer
+// @Description Hand the vehicle over to another customer, e.g. when it is sold, ending the ownership of the current owner. Refused while the vehicle has a service order that is not delivered, cancelled or rejected. Past service orders stay with the customer they were opened for.
+// @Tags Vehicles
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Param transfer body entities.VehicleTransferRequest true "New owner"
+// @Success 201 {object} entities.VehicleOwnership
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 409 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /vehicles/{id}/transfer [post]
+func (h *VehicleOwnershipHandler) TransferVehicle(c *gin.Context) {
+	vehicleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidVehicleID.HTTPStatus, errInvalidVehicleID.ToHTTPError())
+		return
+	}
+
+	var input entities.VehicleTransferRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidVehicleTransferInput.HTTPStatus, errInvalidVehicleTransferInput.ToHTTPError())
+		return
+	}
+
+	ownership, err := h.usecase.TransferVehicle(c.Request.Context(), uint(vehicleID), input, c.GetString(middleware.ContextUserEmail))
+	if err != nil {
+		appErr := mapVehicleOwnershipError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, ownership)
+}
+
+// ListVehicleOwners godoc
+// @Summary List the owners of a vehicle
+// @Description List the customers who owned the vehicle with the dates of their ownership, oldest first; the current owner has no end date
+// @Tags Vehicles
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Vehicle ID"
+// @Success 200 {array} entities.VehicleOwnership
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /vehicles/{id}/owners [get]
+func (h *VehicleOwnershipHandler) ListVehicleOwners(c *gin.Context) {
+	vehicleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(errInvalidVehicleID.HTTPStatus, errInvalidVehicleID.ToHTTPError())
+		return
+	}
+
+	owners, err := h.usecase.ListOwners(c.Request.Context(), uint(vehicleID))
+	if err != nil {
+		appErr := mapVehicleOwnershipError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, owners)
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"errors"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/usecase"
+	"mecanica_xpto/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidWebhookSubscriptionID = pkg.NewDomainErrorSimple("INVALID_WEBHOOK_SUBSCRIPTION_ID", "Invalid webhook subscription ID", http.StatusBadRequest)
+	errInvalidWebhookDeliveryID     = pkg.NewDomainErrorSimple("INVALID_WEBHOOK_DELIVERY_ID", "Invalid webhook delivery ID", http.StatusBadRequest)
+	errInvalidWebhookInput          = pkg.NewDomainErrorSimple("INVALID_INPUT", "Invalid input data", http.StatusBadRequest)
+)
+
+// WebhookHandler manages the webhook subscriptions of partners and fleet clients and their deliveries
+// @title Webhook API
+// @version 1.0
+// @description API for the outgoing webhooks of the workshop management system
+type WebhookHandler struct {
+	usecase usecase.IWebhookUseCase
+}
+
+func NewWebhookHandler(usecase usecase.IWebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{usecase: usecase}
+}
+
+func mapWebhookError(err error) *pkg.AppError {
+	switch {
+	case errors.Is(err, usecase.ErrWebhookSubscriptionNotFound):
+		return pkg.NewDomainErrorSimple("WEBHOOK_SUBSCRIPTION_NOT_FOUND", "Webhook subscription not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrWebhookDeliveryNotFound):
+		return pkg.NewDomainErrorSimple("WEBHOOK_DELIVERY_NOT_FOUND", "Webhook delivery not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidWebhookURL):
+		return pkg.NewDomainErrorSimple("INVALID_WEBHOOK_URL", "Webhook URL must be an absolute http or https URL", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidWebhookEventType):
+		return pkg.NewDomainErrorSimple("INVALID_WEBHOOK_EVENT_TYPE", "Event types must be SERVICE_ORDER_CREATED, SERVICE_ORDER_STATUS_CHANGED or PAYMENT_RECEIVED", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrCustomerNotFound):
+		return pkg.NewDomainErrorSimple("CUSTOMER_NOT_FOUND", "Customer not found", http.StatusNotFound)
+	default:
+		return pkg.NewDomainError("INTERNAL_ERROR", "An internal error occurred", err, http.StatusInternalServerError)
+	}
+}
+
+// CreateSubscription godoc
+// @Summary Create a webhook subscription
+// @Description Register an endpoint to receive service order and payment events, optionally limited to some event types and to one customer. A secret is generated when none is informed; it is only returned here and signs every delivery in the X-Webhook-Signature header.
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param subscription body entities.WebhookSubscription true "Subscription"
+// @Success 201 {object} entities.WebhookSubscription
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var input entities.WebhookSubscription
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidWebhookInput.HTTPStatus, errInvalidWebhookInput.ToHTTPError())
+		return
+	}
+
+	subscription, err := h.usecase.CreateSubscription(c.Request.Context(), input)
+	if err != nil {
+		appErr := mapWebhookError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// ListSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Description List the webhook subscriptions, without their secrets
+// @Tags Webhooks
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} entities.WebhookSubscription
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.usecase.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		appErr := mapWebhookError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetSubscription godoc
+// @Summary Get a webhook subscription
+// @Description Get a webhook subscription by ID, without its secret
+// @Tags Webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} entities.WebhookSubscription
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	id, ok := webhookSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.usecase.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		appErr := mapWebhookError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateSubscription godoc
+// @Summary Update a webhook subscription
+// @Description Change the URL, event types, customer filter and activation of a subscription. The secret is kept.
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param subscription body entities.WebhookSubscription true "Subscription"
+// @Success 200 {object} entities.WebhookSubscription
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	id, ok := webhookSubscriptionID(c)
+	if !ok {
+		return
+	}
+	var input entities.WebhookSubscription
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(errInvalidWebhookInput.HTTPStatus, errInvalidWebhookInput.ToHTTPError())
+		return
+	}
+	input.ID = id
+
+	subscription, err := h.usecase.UpdateSubscription(c.Request.Context(), input)
+	if err != nil {
+		appErr := mapWebhookError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteSubscription godoc
+// @Summary Delete a webhook subscription
+// @Description Remove a webhook subscription. Its delivery history is kept and pending deliveries are dropped.
+// @Tags Webhooks
+// @Security Bearer
+// @Param id path int true "Subscription ID"
+// @Success 204
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id, ok := webhookSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.usecase.DeleteSubscription(c.Request.Context(), id); err != nil {
+		appErr := mapWebhookError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+// @Summary List the deliveries of a webhook subscription
+// @Description List the events sent to a subscription, newest first, with the status, attempts and last answer of each delivery
+// @Tags Webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {array} entities.WebhookDelivery
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := webhookSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.usecase.ListDeliveries(c.Request.Context(), id)
+	if err != nil {
+		appErr := mapWebhookError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver godoc
+// @Summary Redeliver a webhook
+// @Description Queue a delivery to be sent again, with a fresh set of attempts, whatever its current status
+// @Tags Webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} entities.WebhookDelivery
+// @Failure 400 {object} pkg.ErrorResponse
+// @Failure 404 {object} pkg.ErrorResponse
+// @Failure 500 {object} pkg.ErrorResponse
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := webhookSubscriptionID(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseUint(c.Param("deliveryId"), 10, 32)
+	if err != nil || deliveryID == 0 {
+		c.JSON(errInvalidWebhookDeliveryID.HTTPStatus, errInvalidWebhookDeliveryID.ToHTTPError())
+		return
+	}
+
+	delivery, err := h.usecase.Redeliver(c.Request.Context(), id, uint(deliveryID))
+	if err != nil {
+		appErr := mapWebhookError(err)
+		c.JSON(appErr.HTTPStatus, appErr.ToHTTPError())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// webhookSubscriptionID reads the subscription ID of the path, answering 400 when it is invalid
+func webhookSubscriptionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(errInvalidWebhookSubscriptionID.HTTPStatus, errInvalidWebhookSubscriptionID.ToHTTPError())
+		return 0, false
+	}
+	return uint(id), true
+}
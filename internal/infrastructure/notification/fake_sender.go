@@ -0,0 +1,49 @@
+package notification
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+// FakeSender keeps the messages in memory instead of delivering them, for local runs and tests.
+// When Err is set every send fails with it.
+type FakeSender struct {
+	channel valueobject.NotificationChannel
+	mu      sync.Mutex
+	sent    []entities.NotificationMessage
+	Err     error
+}
+
+var _ gateway.NotificationSender = (*FakeSender)(nil)
+
+func NewFakeSender(channel valueobject.NotificationChannel) *FakeSender {
+	return &FakeSender{channel: channel}
+}
+
+func (s *FakeSender) Channel() valueobject.NotificationChannel {
+	return s.channel
+}
+
+func (s *FakeSender) Send(_ context.Context, message entities.NotificationMessage) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	s.mu.Lock()
+	s.sent = append(s.sent, message)
+	s.mu.Unlock()
+	log.Info().Msgf("Fake %s notification to %s: %s", s.channel, message.Recipient, message.Body)
+	return nil
+}
+
+// Sent returns the messages sent so far
+func (s *FakeSender) Sent() []entities.NotificationMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]entities.NotificationMessage(nil), s.sent...)
+}
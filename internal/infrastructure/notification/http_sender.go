@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+const httpSenderTimeout = 10 * time.Second
+
+// HTTPSender delivers SMS and WhatsApp messages through a provider API that accepts
+// a JSON body with the phone number and the text, authenticated by a bearer token
+type HTTPSender struct {
+	channel valueobject.NotificationChannel
+	url     string
+	token   string
+	client  *http.Client
+}
+
+var _ gateway.NotificationSender = (*HTTPSender)(nil)
+
+type httpSenderRequest struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+func NewHTTPSender(channel valueobject.NotificationChannel, url, token string) *HTTPSender {
+	return &HTTPSender{
+		channel: channel,
+		url:     url,
+		token:   token,
+		client:  &http.Client{Timeout: httpSenderTimeout},
+	}
+}
+
+func (s *HTTPSender) Channel() valueobject.NotificationChannel {
+	return s.channel
+}
+
+func (s *HTTPSender) Send(ctx context.Context, message entities.NotificationMessage) error {
+	payload, err := json.Marshal(httpSenderRequest{To: message.Recipient, Message: message.Body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s provider answered with status %d", s.channel, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/pkg/utils"
+)
+
+const (
+	ProviderFake = "fake"
+	ProviderLive = "live"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown notification provider")
+	ErrUnknownChannel  = errors.New("unknown notification channel")
+)
+
+// NewSenders returns a sender for each channel enabled in the configuration
+func NewSenders(cfg *utils.NotificationConfig) ([]gateway.NotificationSender, error) {
+	if cfg.Provider != ProviderFake && cfg.Provider != ProviderLive {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, cfg.Provider)
+	}
+
+	senders := make([]gateway.NotificationSender, 0, len(cfg.Channels))
+	for _, name := range cfg.Channels {
+		channel := valueobject.ParseNotificationChannel(name)
+		if !channel.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownChannel, name)
+		}
+		if cfg.Provider == ProviderFake {
+			senders = append(senders, NewFakeSender(channel))
+			continue
+		}
+		switch channel {
+		case valueobject.NotificationEmail:
+			senders = append(senders, NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom))
+		case valueobject.NotificationSMS:
+			senders = append(senders, NewHTTPSender(channel, cfg.SMSAPIURL, cfg.SMSAPIToken))
+		case valueobject.NotificationWhatsApp:
+			senders = append(senders, NewHTTPSender(channel, cfg.WhatsAppAPIURL, cfg.WhatsAppAPIToken))
+		}
+	}
+	return senders, nil
+}
@@ -0,0 +1,127 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+	"mecanica_xpto/pkg/utils"
+)
+
+func testMessage(recipient string) entities.NotificationMessage {
+	return entities.NotificationMessage{
+		Recipient: recipient,
+		Subject:   "Seu veículo está pronto - OS 1",
+		Body:      "Olá, Maria! Seu veículo já pode ser retirado.",
+	}
+}
+
+func TestNewSenders(t *testing.T) {
+	t.Run("Fake provider - one sender per channel", func(t *testing.T) {
+		senders, err := NewSenders(&utils.NotificationConfig{Provider: ProviderFake, Channels: []string{"EMAIL", "WHATSAPP"}})
+		require.NoError(t, err)
+		require.Len(t, senders, 2)
+		assert.IsType(t, &FakeSender{}, senders[0])
+		assert.Equal(t, valueobject.NotificationEmail, senders[0].Channel())
+		assert.Equal(t, valueobject.NotificationWhatsApp, senders[1].Channel())
+	})
+
+	t.Run("Live provider", func(t *testing.T) {
+		senders, err := NewSenders(&utils.NotificationConfig{Provider: ProviderLive, Channels: []string{"EMAIL", "SMS"}})
+		require.NoError(t, err)
+		require.Len(t, senders, 2)
+		assert.IsType(t, &SMTPSender{}, senders[0])
+		assert.IsType(t, &HTTPSender{}, senders[1])
+	})
+
+	t.Run("Error - unknown provider", func(t *testing.T) {
+		_, err := NewSenders(&utils.NotificationConfig{Provider: "pombo"})
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+
+	t.Run("Error - unknown channel", func(t *testing.T) {
+		_, err := NewSenders(&utils.NotificationConfig{Provider: ProviderFake, Channels: []string{"TELEGRAM"}})
+		assert.ErrorIs(t, err, ErrUnknownChannel)
+	})
+}
+
+func TestFakeSender(t *testing.T) {
+	sender := NewFakeSender(valueobject.NotificationSMS)
+	require.NoError(t, sender.Send(context.Background(), testMessage("11999999999")))
+	assert.Len(t, sender.Sent(), 1)
+
+	sender.Err = errors.New("offline")
+	assert.Error(t, sender.Send(context.Background(), testMessage("11999999999")))
+	assert.Len(t, sender.Sent(), 1)
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	sender := NewSMTPSender("smtp.example.com", 587, "user", "secret", "Mecanica XPTO <os@example.com>")
+	sender.now = func() time.Time { return time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC) }
+
+	t.Run("Success", func(t *testing.T) {
+		var gotAddr, gotFrom string
+		var gotTo []string
+		var gotMsg []byte
+		sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, msg
+			assert.NotNil(t, a)
+			return nil
+		}
+
+		err := sender.Send(context.Background(), testMessage("maria@example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, "smtp.example.com:587", gotAddr)
+		assert.Equal(t, "os@example.com", gotFrom)
+		assert.Equal(t, []string{"maria@example.com"}, gotTo)
+		msg := string(gotMsg)
+		assert.Contains(t, msg, "Subject: =?utf-8?q?")
+		assert.Contains(t, msg, "Content-Type: text/plain; charset=\"utf-8\"")
+		assert.True(t, strings.HasSuffix(msg, "Olá, Maria! Seu veículo já pode ser retirado.\r\n"))
+	})
+
+	t.Run("Error - invalid recipient", func(t *testing.T) {
+		err := sender.Send(context.Background(), testMessage("nao-e-email"))
+		assert.Error(t, err)
+	})
+}
+
+func TestHTTPSender_Send(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		var got httpSenderRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		sender := NewHTTPSender(valueobject.NotificationWhatsApp, server.URL, "token")
+		err := sender.Send(context.Background(), testMessage("11999999999"))
+		require.NoError(t, err)
+		assert.Equal(t, "11999999999", got.To)
+		assert.Equal(t, "Olá, Maria! Seu veículo já pode ser retirado.", got.Message)
+	})
+
+	t.Run("Error - provider rejects", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		sender := NewHTTPSender(valueobject.NotificationSMS, server.URL, "")
+		err := sender.Send(context.Background(), testMessage("11999999999"))
+		assert.ErrorContains(t, err, "502")
+	})
+}
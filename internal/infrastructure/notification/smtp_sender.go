@@ -0,0 +1,79 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+// SMTPSender delivers e-mails through an SMTP server, authenticating when a username is set
+type SMTPSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	now      func() time.Time
+}
+
+var _ gateway.NotificationSender = (*SMTPSender)(nil)
+
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+		sendMail: smtp.SendMail,
+		now:      time.Now,
+	}
+}
+
+func (s *SMTPSender) Channel() valueobject.NotificationChannel {
+	return valueobject.NotificationEmail
+}
+
+func (s *SMTPSender) Send(_ context.Context, message entities.NotificationMessage) error {
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	to, err := mail.ParseAddress(message.Recipient)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+	return s.sendMail(s.addr, auth, from.Address, []string{to.Address}, s.buildMessage(from, to, message))
+}
+
+// buildMessage writes a plain text UTF-8 e-mail, encoding the subject so accents survive
+func (s *SMTPSender) buildMessage(from, to *mail.Address, message entities.NotificationMessage) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", s.now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(message.Body)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
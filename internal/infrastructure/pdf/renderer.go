@@ -0,0 +1,143 @@
+package pdf
+
+import (
+	"fmt"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/internal/domain/model/entities"
+)
+
+const (
+	marginLeft   = 40.0
+	marginRight  = PageWidth - 40
+	marginTop    = PageHeight - 50
+	marginBottom = 60.0
+
+	titleSize = 16.0
+	bodySize  = 10.0
+	smallSize = 8.0
+	lineGap   = 14.0
+
+	maxDescriptionLength = 60
+
+	columnQuantity  = 360.0
+	columnUnitPrice = 460.0
+)
+
+// Renderer lays out documents on A4 pages: a header with title, number and issue date,
+// the sections as label/value pairs, the line items table, the totals and the notes
+type Renderer struct{}
+
+var _ gateway.DocumentRenderer = (*Renderer)(nil)
+
+func NewRenderer() *Renderer {
+	return &Renderer{}
+}
+
+func (r *Renderer) ContentType() string {
+	return "application/pdf"
+}
+
+func (r *Renderer) Extension() string {
+	return "pdf"
+}
+
+func (r *Renderer) Render(document entities.Document) ([]byte, error) {
+	l := &layout{writer: NewWriter(document.Title), document: document}
+	l.newPage()
+
+	for _, section := range document.Sections {
+		l.ensure(lineGap * float64(len(section.Fields)+2))
+		l.writer.Text(marginLeft, l.y, bodySize+1, true, section.Title)
+		l.y -= lineGap
+		for _, field := range section.Fields {
+			l.writer.Text(marginLeft, l.y, bodySize, true, field.Label+":")
+			l.writer.Text(marginLeft+TextWidth(field.Label+": ", bodySize)+4, l.y, bodySize, false, field.Value)
+			l.y -= lineGap
+		}
+		l.y -= lineGap / 2
+	}
+
+	if len(document.Lines) > 0 {
+		l.tableHeader()
+		for _, line := range document.Lines {
+			if l.ensure(lineGap) {
+				l.tableHeader()
+			}
+			l.writer.Text(marginLeft, l.y, bodySize, false, truncate(line.Description, maxDescriptionLength))
+			l.writer.TextRight(columnQuantity, l.y, bodySize, false, fmt.Sprintf("%d", line.Quantity))
+			l.writer.TextRight(columnUnitPrice, l.y, bodySize, false, line.UnitPrice)
+			l.writer.TextRight(marginRight, l.y, bodySize, false, line.Total)
+			l.y -= lineGap
+		}
+		l.writer.Line(marginLeft, l.y+lineGap-4, marginRight, l.y+lineGap-4)
+		l.y -= lineGap / 2
+	}
+
+	for i, total := range document.Totals {
+		l.ensure(lineGap)
+		last := i == len(document.Totals)-1
+		l.writer.TextRight(columnUnitPrice, l.y, bodySize, last, total.Label)
+		l.writer.TextRight(marginRight, l.y, bodySize, last, total.Value)
+		l.y -= lineGap
+	}
+
+	if len(document.Notes) > 0 {
+		l.y -= lineGap
+		for _, note := range document.Notes {
+			l.ensure(lineGap)
+			l.writer.Text(marginLeft, l.y, smallSize, false, note)
+			l.y -= lineGap - 2
+		}
+	}
+
+	return l.writer.Bytes()
+}
+
+type layout struct {
+	writer   *Writer
+	document entities.Document
+	y        float64
+}
+
+func (l *layout) newPage() {
+	l.writer.AddPage()
+	l.y = marginTop
+
+	l.writer.Text(marginLeft, l.y, titleSize, true, l.document.Title)
+	l.writer.TextRight(marginRight, l.y, bodySize, true, l.document.Number)
+	l.writer.TextRight(marginRight, l.y-lineGap, smallSize, false, "Emitido em "+l.document.IssuedAt.Format("02/01/2006 15:04"))
+	l.y -= lineGap * 2
+	l.writer.Line(marginLeft, l.y, marginRight, l.y)
+	l.y -= lineGap * 1.5
+
+	page := l.writer.PageCount()
+	l.writer.TextRight(marginRight, marginBottom-30, smallSize, false, fmt.Sprintf("Página %d", page))
+}
+
+// ensure starts a new page when there is not enough room left, reporting whether it did
+func (l *layout) ensure(height float64) bool {
+	if l.y-height >= marginBottom {
+		return false
+	}
+	l.newPage()
+	return true
+}
+
+func (l *layout) tableHeader() {
+	l.ensure(lineGap * 3)
+	l.writer.Text(marginLeft, l.y, bodySize, true, "Descrição")
+	l.writer.TextRight(columnQuantity, l.y, bodySize, true, "Qtd")
+	l.writer.TextRight(columnUnitPrice, l.y, bodySize, true, "Valor unit.")
+	l.writer.TextRight(marginRight, l.y, bodySize, true, "Total")
+	l.writer.Line(marginLeft, l.y-4, marginRight, l.y-4)
+	l.y -= lineGap + 2
+}
+
+func truncate(text string, size int) string {
+	runes := []rune(text)
+	if len(runes) <= size {
+		return text
+	}
+	return string(runes[:size-3]) + "..."
+}
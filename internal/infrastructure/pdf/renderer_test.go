@@ -0,0 +1,68 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/model/entities"
+	"mecanica_xpto/internal/domain/model/valueobject"
+)
+
+func testDocument(lines int) entities.Document {
+	document := entities.Document{
+		Type:     valueobject.DocumentEstimate,
+		Title:    "Orçamento",
+		Number:   "OS #42",
+		IssuedAt: time.Date(2025, 8, 15, 10, 30, 0, 0, time.UTC),
+		Sections: []entities.DocumentSection{
+			{Title: "Cliente", Fields: []entities.DocumentField{{Label: "CPF/CNPJ", Value: "529.982.247-25"}}},
+		},
+		Totals: []entities.DocumentField{{Label: "Total", Value: "R$ 150,00"}},
+		Notes:  []string{"Valores com impostos inclusos."},
+	}
+	for i := 0; i < lines; i++ {
+		document.Lines = append(document.Lines, entities.DocumentLine{
+			Description: fmt.Sprintf("Serviço %d", i+1), Quantity: 1, UnitPrice: "R$ 10,00", Total: "R$ 10,00",
+		})
+	}
+	return document
+}
+
+func TestRenderer_Render(t *testing.T) {
+	document, err := NewRenderer().Render(testDocument(2))
+	require.NoError(t, err)
+
+	contents := pageContents(t, document)
+	require.Len(t, contents, 1)
+	page := contents[0]
+	assert.Contains(t, page, "(Or\xe7amento) Tj")
+	assert.Contains(t, page, "(OS #42) Tj")
+	assert.Contains(t, page, "(Emitido em 15/08/2025 10:30) Tj")
+	assert.Contains(t, page, "(529.982.247-25) Tj")
+	assert.Contains(t, page, "(Servi\xe7o 2) Tj")
+	assert.Contains(t, page, "(R$ 150,00) Tj")
+	assert.Contains(t, page, "(Valores com impostos inclusos.) Tj")
+}
+
+func TestRenderer_RenderBreaksPages(t *testing.T) {
+	document, err := NewRenderer().Render(testDocument(120))
+	require.NoError(t, err)
+
+	contents := pageContents(t, document)
+	assert.Greater(t, len(contents), 1)
+	for _, page := range contents {
+		// the table header is repeated on every page holding line items
+		assert.True(t, strings.Contains(page, "(Descri\xe7\xe3o) Tj"))
+	}
+	assert.Contains(t, contents[len(contents)-1], "(Servi\xe7o 120) Tj")
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "curto", truncate("curto", 10))
+	assert.Equal(t, "abcdefg...", truncate("abcdefghijklmnop", 10))
+}
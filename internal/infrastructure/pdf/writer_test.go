@@ -0,0 +1,75 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var objectOffsetPattern = regexp.MustCompile(`(\d{10}) 00000 n `)
+
+// pageContents inflates the content streams of a generated PDF
+func pageContents(t *testing.T, document []byte) []string {
+	t.Helper()
+	var contents []string
+	for _, match := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(document, -1) {
+		reader, err := zlib.NewReader(bytes.NewReader(match[1]))
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		contents = append(contents, string(content))
+	}
+	return contents
+}
+
+func TestWriter_Bytes(t *testing.T) {
+	w := NewWriter("Orçamento")
+	w.Text(40, 800, 12, true, "Orçamento (OS #1)")
+	w.TextRight(555, 780, 10, false, "R$ 10,00")
+	w.Line(40, 770, 555, 770)
+	w.AddPage()
+	w.Text(40, 800, 10, false, "segunda página")
+
+	document, err := w.Bytes()
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(document, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(document, []byte("%%EOF\n")))
+	assert.Contains(t, string(document), "/Count 2")
+
+	// every xref entry must point to the object it indexes
+	for i, match := range objectOffsetPattern.FindAllSubmatch(document, -1) {
+		offset, err := strconv.Atoi(string(match[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(document[offset:], []byte(strconv.Itoa(i+1)+" 0 obj")))
+	}
+
+	contents := pageContents(t, document)
+	require.Len(t, contents, 2)
+	assert.Contains(t, contents[0], "/F2 12.0 Tf")
+	assert.Contains(t, contents[0], "(Or\xe7amento \\(OS #1\\)) Tj")
+	assert.Contains(t, contents[1], "(segunda p\xe1gina) Tj")
+}
+
+func TestWriter_EmptyDocumentHasOnePage(t *testing.T) {
+	document, err := NewWriter("vazio").Bytes()
+	require.NoError(t, err)
+	assert.Contains(t, string(document), "/Count 1")
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.InDelta(t, 5.56, TextWidth("0", 10), 0.001)
+	assert.InDelta(t, 27.8, TextWidth("00000", 10), 0.001)
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\(b\)c\\`, escape(`a(b)c\`))
+	assert.Equal(t, "\xe3o", escape("ão"))
+	assert.Equal(t, "?", escape("€"))
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/pkg/utils"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewLocalStore(root)
+
+	t.Run("stores, opens and deletes a blob", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "check-ins/7/foto.jpg", strings.NewReader("conteudo")))
+		_, err := os.Stat(filepath.Join(root, "check-ins", "7", "foto.jpg"))
+		require.NoError(t, err)
+
+		reader, err := store.Open(ctx, "check-ins/7/foto.jpg")
+		require.NoError(t, err)
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		assert.Equal(t, "conteudo", string(content))
+
+		require.NoError(t, store.Delete(ctx, "check-ins/7/foto.jpg"))
+		_, err = store.Open(ctx, "check-ins/7/foto.jpg")
+		assert.ErrorIs(t, err, gateway.ErrBlobNotFound)
+		assert.NoError(t, store.Delete(ctx, "check-ins/7/foto.jpg"))
+	})
+
+	t.Run("refuses keys escaping the root", func(t *testing.T) {
+		for _, key := range []string{"", "../fora.txt", "check-ins/../../fora.txt"} {
+			assert.ErrorIs(t, store.Put(ctx, key, strings.NewReader("x")), ErrInvalidKey, key)
+		}
+	})
+}
+
+func TestNewBlobStore(t *testing.T) {
+	store, err := NewBlobStore(&utils.StorageConfig{Provider: ProviderLocal, LocalDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.IsType(t, &LocalStore{}, store)
+
+	_, err = NewBlobStore(&utils.StorageConfig{Provider: "s3"})
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"mecanica_xpto/internal/domain/gateway"
+	"mecanica_xpto/pkg/utils"
+)
+
+const ProviderLocal = "local"
+
+var ErrUnknownProvider = errors.New("unknown storage provider")
+
+// NewBlobStore returns the blob store selected in the configuration
+func NewBlobStore(cfg *utils.StorageConfig) (gateway.BlobStore, error) {
+	switch cfg.Provider {
+	case ProviderLocal:
+		return NewLocalStore(cfg.LocalDir), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, cfg.Provider)
+	}
+}
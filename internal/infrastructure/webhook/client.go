@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"mecanica_xpto/internal/domain/gateway"
+)
+
+// Client posts webhook deliveries to the subscribers over HTTP
+type Client struct {
+	client *http.Client
+}
+
+var _ gateway.WebhookClient = (*Client)(nil)
+
+func NewClient(timeout time.Duration) *Client {
+	return &Client{client: &http.Client{Timeout: timeout}}
+}
+
+// Post sends the body and returns the status the subscriber answered with. Any answer is returned
+// without error, as deciding what counts as delivered is up to the caller.
+func (c *Client) Post(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
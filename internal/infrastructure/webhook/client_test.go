@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Post(t *testing.T) {
+	var gotBody, gotSignature, gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotSignature = r.Header.Get("X-Webhook-Signature")
+		gotContentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	status, err := NewClient(time.Second).Post(context.Background(), server.URL, map[string]string{
+		"Content-Type":        "application/json",
+		"X-Webhook-Signature": "sha256=abc",
+	}, []byte(`{"event_id":1}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, `{"event_id":1}`, gotBody)
+	assert.Equal(t, "sha256=abc", gotSignature)
+	assert.Equal(t, "application/json", gotContentType)
+}
+
+func TestClient_Post_ErrorStatusIsReturned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	status, err := NewClient(time.Second).Post(context.Background(), server.URL, nil, []byte(`{}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
+
+func TestClient_Post_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	_, err := NewClient(time.Second).Post(context.Background(), url, nil, []byte(`{}`))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrApprovalTokenInvalid = errors.New("invalid approval token")
+	ErrApprovalTokenExpired = errors.New("approval token expired")
+)
+
+// ApprovalTokenService signs the tokens of the approval links sent to customers. A token is
+// "<nonce>.<expiry>.<signature>": the expiry can be checked without a database lookup, while the
+// nonce makes every token unique so it can be stored (hashed) and used only once.
+type ApprovalTokenService struct {
+	secretKey []byte
+}
+
+func NewApprovalTokenService(cfg *ApprovalConfig) *ApprovalTokenService {
+	return &ApprovalTokenService{secretKey: []byte(cfg.SecretKey)}
+}
+
+func (s *ApprovalTokenService) GenerateToken(expiresAt time.Time) (string, error) {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(nonce) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + s.sign(payload), nil
+}
+
+// ValidateToken checks the signature and the expiry of a token
+func (s *ApprovalTokenService) ValidateToken(token string, now time.Time) error {
+	idx := strings.LastIndex(token, ".")
+	if idx < 0 {
+		return ErrApprovalTokenInvalid
+	}
+	payload, signature := token[:idx], token[idx+1:]
+	if !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return ErrApprovalTokenInvalid
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 2 {
+		return ErrApprovalTokenInvalid
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return ErrApprovalTokenInvalid
+	}
+	if !now.Before(time.Unix(expiresAt, 0)) {
+		return ErrApprovalTokenExpired
+	}
+	return nil
+}
+
+// HashToken is the value stored in place of the token, so a leaked table does not leak usable links
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *ApprovalTokenService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secretKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApprovalTokenService(t *testing.T) {
+	service := NewApprovalTokenService(&ApprovalConfig{SecretKey: "test_secret"})
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	token, err := service.GenerateToken(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if err := service.ValidateToken(token, now); err != nil {
+		t.Errorf("esperado token válido, obtido %v", err)
+	}
+	if err := service.ValidateToken(token, now.Add(2*time.Hour)); !errors.Is(err, ErrApprovalTokenExpired) {
+		t.Errorf("esperado %v, obtido %v", ErrApprovalTokenExpired, err)
+	}
+
+	other := NewApprovalTokenService(&ApprovalConfig{SecretKey: "other_secret"})
+	if err := other.ValidateToken(token, now); !errors.Is(err, ErrApprovalTokenInvalid) {
+		t.Errorf("esperado %v com outro segredo, obtido %v", ErrApprovalTokenInvalid, err)
+	}
+
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + strconv.FormatInt(now.Add(48*time.Hour).Unix(), 10) + "." + parts[2]
+	if err := service.ValidateToken(tampered, now); !errors.Is(err, ErrApprovalTokenInvalid) {
+		t.Errorf("esperado %v para token alterado, obtido %v", ErrApprovalTokenInvalid, err)
+	}
+
+	second, _ := service.GenerateToken(now.Add(time.Hour))
+	if second == token || HashToken(second) == HashToken(token) {
+		t.Errorf("esperado tokens distintos")
+	}
+}
+
+func TestApprovalTokenServiceMalformed(t *testing.T) {
+	service := NewApprovalTokenService(&ApprovalConfig{SecretKey: "test_secret"})
+
+	for _, token := range []string{"", "abc", "a.b", "a.b.c.d"} {
+		if err := service.ValidateToken(token, time.Now()); !errors.Is(err, ErrApprovalTokenInvalid) {
+			t.Errorf("token %q: esperado %v, obtido %v", token, ErrApprovalTokenInvalid, err)
+		}
+	}
+}
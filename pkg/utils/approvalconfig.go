@@ -0,0 +1,20 @@
+package utils
+
+import "time"
+
+type ApprovalConfig struct {
+	// SecretKey signs the approval links sent to customers
+	SecretKey string
+	// LinkTTL is how long an approval link stays valid after it is created
+	LinkTTL time.Duration
+	// BaseURL is the public address the token is appended to when building the link
+	BaseURL string
+}
+
+func LoadApprovalConfig() *ApprovalConfig {
+	return &ApprovalConfig{
+		SecretKey: getEnv("APPROVAL_LINK_SECRET", "default_approval_secret"),
+		LinkTTL:   getEnvAsDuration("APPROVAL_LINK_TTL", time.Hour*72),
+		BaseURL:   getEnv("APPROVAL_LINK_BASE_URL", "http://localhost:8080/v1/public/approvals"),
+	}
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadApprovalConfigDefaults(t *testing.T) {
+	os.Unsetenv("APPROVAL_LINK_SECRET")
+	os.Unsetenv("APPROVAL_LINK_TTL")
+	os.Unsetenv("APPROVAL_LINK_BASE_URL")
+
+	cfg := LoadApprovalConfig()
+
+	if cfg.SecretKey != "default_approval_secret" {
+		t.Errorf("esperado SecretKey = %v, obtido %v", "default_approval_secret", cfg.SecretKey)
+	}
+	if cfg.LinkTTL != 72*time.Hour {
+		t.Errorf("esperado LinkTTL = %v, obtido %v", 72*time.Hour, cfg.LinkTTL)
+	}
+	if cfg.BaseURL != "http://localhost:8080/v1/public/approvals" {
+		t.Errorf("esperado BaseURL = %v, obtido %v", "http://localhost:8080/v1/public/approvals", cfg.BaseURL)
+	}
+}
+
+func TestLoadApprovalConfigFromEnv(t *testing.T) {
+	os.Setenv("APPROVAL_LINK_TTL", "24h")
+	os.Setenv("APPROVAL_LINK_BASE_URL", "https://oficina.example.com/aprovar")
+	defer os.Unsetenv("APPROVAL_LINK_TTL")
+	defer os.Unsetenv("APPROVAL_LINK_BASE_URL")
+
+	cfg := LoadApprovalConfig()
+
+	if cfg.LinkTTL != 24*time.Hour {
+		t.Errorf("esperado LinkTTL = %v, obtido %v", 24*time.Hour, cfg.LinkTTL)
+	}
+	if cfg.BaseURL != "https://oficina.example.com/aprovar" {
+		t.Errorf("esperado BaseURL = %v, obtido %v", "https://oficina.example.com/aprovar", cfg.BaseURL)
+	}
+}
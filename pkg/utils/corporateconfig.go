@@ -0,0 +1,15 @@
+package utils
+
+import "time"
+
+type CorporateConfig struct {
+	// BillingInterval is how often the statements of the month just over are closed for the
+	// corporate accounts still missing one
+	BillingInterval time.Duration
+}
+
+func LoadCorporateConfig() *CorporateConfig {
+	return &CorporateConfig{
+		BillingInterval: getEnvAsDuration("CORPORATE_BILLING_INTERVAL", 24*time.Hour),
+	}
+}
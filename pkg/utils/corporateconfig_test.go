@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadCorporateConfig(t *testing.T) {
+	os.Unsetenv("CORPORATE_BILLING_INTERVAL")
+
+	cfg := LoadCorporateConfig()
+	if cfg.BillingInterval != 24*time.Hour {
+		t.Errorf("esperado BillingInterval = %v, obtido %v", 24*time.Hour, cfg.BillingInterval)
+	}
+
+	os.Setenv("CORPORATE_BILLING_INTERVAL", "12h")
+	defer os.Unsetenv("CORPORATE_BILLING_INTERVAL")
+
+	cfg = LoadCorporateConfig()
+	if cfg.BillingInterval != 12*time.Hour {
+		t.Errorf("esperado BillingInterval = %v, obtido %v", 12*time.Hour, cfg.BillingInterval)
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FormatBRL formats an amount in Brazilian reais, e.g. 1234.5 as "R$ 1.234,50"
+func FormatBRL(v float64) string {
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	cents := int64(math.Round(v * 100))
+	integer := fmt.Sprintf("%d", cents/100)
+
+	var groups []string
+	for len(integer) > 3 {
+		groups = append([]string{integer[len(integer)-3:]}, groups...)
+		integer = integer[:len(integer)-3]
+	}
+	groups = append([]string{integer}, groups...)
+
+	return fmt.Sprintf("%sR$ %s,%02d", sign, strings.Join(groups, "."), cents%100)
+}
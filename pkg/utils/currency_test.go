@@ -0,0 +1,23 @@
+package utils
+
+import "testing"
+
+func TestFormatBRL(t *testing.T) {
+	tests := []struct {
+		input    float64
+		expected string
+	}{
+		{0, "R$ 0,00"},
+		{9.9, "R$ 9,90"},
+		{1234.5, "R$ 1.234,50"},
+		{1234567.891, "R$ 1.234.567,89"},
+		{-15.25, "-R$ 15,25"},
+	}
+
+	for _, test := range tests {
+		got := FormatBRL(test.input)
+		if got != test.expected {
+			t.Errorf("FormatBRL(%v) = %q; want %q", test.input, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDownloadURLSigner(t *testing.T) {
+	signer := NewDownloadURLSigner("test_secret")
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	expires, signature := signer.Sign(42, now.Add(15*time.Minute))
+	if expires != strconv.FormatInt(now.Add(15*time.Minute).Unix(), 10) {
+		t.Errorf("esperado expires em unix, obtido %s", expires)
+	}
+
+	if err := signer.Verify(42, expires, signature, now); err != nil {
+		t.Errorf("esperado link válido, obtido %v", err)
+	}
+	if err := signer.Verify(42, expires, signature, now.Add(time.Hour)); !errors.Is(err, ErrDownloadURLExpired) {
+		t.Errorf("esperado %v, obtido %v", ErrDownloadURLExpired, err)
+	}
+	if err := signer.Verify(43, expires, signature, now); !errors.Is(err, ErrDownloadURLInvalid) {
+		t.Errorf("esperado %v para outro arquivo, obtido %v", ErrDownloadURLInvalid, err)
+	}
+
+	extended := strconv.FormatInt(now.Add(48*time.Hour).Unix(), 10)
+	if err := signer.Verify(42, extended, signature, now); !errors.Is(err, ErrDownloadURLInvalid) {
+		t.Errorf("esperado %v para expiração alterada, obtido %v", ErrDownloadURLInvalid, err)
+	}
+
+	other := NewDownloadURLSigner("other_secret")
+	if err := other.Verify(42, expires, signature, now); !errors.Is(err, ErrDownloadURLInvalid) {
+		t.Errorf("esperado %v com outro segredo, obtido %v", ErrDownloadURLInvalid, err)
+	}
+}
@@ -0,0 +1,14 @@
+package utils
+
+import "time"
+
+type EventConfig struct {
+	// DispatchInterval is how often the outbox is checked for events to deliver
+	DispatchInterval time.Duration
+}
+
+func LoadEventConfig() *EventConfig {
+	return &EventConfig{
+		DispatchInterval: getEnvAsDuration("EVENT_DISPATCH_INTERVAL", 5*time.Second),
+	}
+}
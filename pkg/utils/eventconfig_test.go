@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadEventConfig(t *testing.T) {
+	os.Unsetenv("EVENT_DISPATCH_INTERVAL")
+
+	if cfg := LoadEventConfig(); cfg.DispatchInterval != 5*time.Second {
+		t.Errorf("esperado DispatchInterval = %v, obtido %v", 5*time.Second, cfg.DispatchInterval)
+	}
+
+	os.Setenv("EVENT_DISPATCH_INTERVAL", "1m")
+	defer os.Unsetenv("EVENT_DISPATCH_INTERVAL")
+
+	if cfg := LoadEventConfig(); cfg.DispatchInterval != time.Minute {
+		t.Errorf("esperado DispatchInterval = %v, obtido %v", time.Minute, cfg.DispatchInterval)
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+type InvoiceConfig struct {
+	// Provider selects the gateway invoices are submitted to; "stub" accepts them locally
+	Provider string
+	Series   string
+	// CertFile and KeyFile are the PEM encoded A1 certificate of the issuer.
+	// When empty, invoices are signed with a self-signed certificate, only suitable for development.
+	CertFile                    string
+	KeyFile                     string
+	IssuerCNPJ                  string
+	IssuerName                  string
+	IssuerMunicipalRegistration string
+	IssuerStateRegistration     string
+	IssuerCityCode              string
+	IssuerState                 string
+}
+
+func LoadInvoiceConfig() *InvoiceConfig {
+	return &InvoiceConfig{
+		Provider:                    getEnv("INVOICE_PROVIDER", "stub"),
+		Series:                      getEnv("INVOICE_SERIES", "1"),
+		CertFile:                    getEnv("INVOICE_CERT_FILE", ""),
+		KeyFile:                     getEnv("INVOICE_KEY_FILE", ""),
+		IssuerCNPJ:                  getEnv("INVOICE_ISSUER_CNPJ", ""),
+		IssuerName:                  getEnv("INVOICE_ISSUER_NAME", "Mecanica XPTO"),
+		IssuerMunicipalRegistration: getEnv("INVOICE_ISSUER_MUNICIPAL_REGISTRATION", ""),
+		IssuerStateRegistration:     getEnv("INVOICE_ISSUER_STATE_REGISTRATION", ""),
+		IssuerCityCode:              getEnv("INVOICE_ISSUER_CITY_CODE", "3550308"),
+		IssuerState:                 getEnv("INVOICE_ISSUER_STATE", "SP"),
+	}
+}
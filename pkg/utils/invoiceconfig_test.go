@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadInvoiceConfigDefaults(t *testing.T) {
+	os.Unsetenv("INVOICE_PROVIDER")
+	os.Unsetenv("INVOICE_SERIES")
+	os.Unsetenv("INVOICE_CERT_FILE")
+
+	cfg := LoadInvoiceConfig()
+
+	if cfg.Provider != "stub" {
+		t.Errorf("esperado Provider = %v, obtido %v", "stub", cfg.Provider)
+	}
+	if cfg.Series != "1" {
+		t.Errorf("esperado Series = %v, obtido %v", "1", cfg.Series)
+	}
+	if cfg.CertFile != "" {
+		t.Errorf("esperado CertFile vazio, obtido %v", cfg.CertFile)
+	}
+}
+
+func TestLoadInvoiceConfigFromEnv(t *testing.T) {
+	os.Setenv("INVOICE_ISSUER_CNPJ", "11222333000181")
+	os.Setenv("INVOICE_ISSUER_CITY_CODE", "3304557")
+	defer os.Unsetenv("INVOICE_ISSUER_CNPJ")
+	defer os.Unsetenv("INVOICE_ISSUER_CITY_CODE")
+
+	cfg := LoadInvoiceConfig()
+
+	if cfg.IssuerCNPJ != "11222333000181" {
+		t.Errorf("esperado IssuerCNPJ = %v, obtido %v", "11222333000181", cfg.IssuerCNPJ)
+	}
+	if cfg.IssuerCityCode != "3304557" {
+		t.Errorf("esperado IssuerCityCode = %v, obtido %v", "3304557", cfg.IssuerCityCode)
+	}
+}
@@ -18,9 +18,12 @@ func NewJWTService(cfg *JWTConfig) *JWTService {
 	}
 }
 
-func (j *JWTService) GenerateToken(subject string) (string, error) {
+// GenerateToken issues a token for the user identified by subject, carrying their role so the
+// permissions can be checked without loading the user on every request
+func (j *JWTService) GenerateToken(subject, role string) (string, error) {
 	claims := jwt.MapClaims{
-		"sub": subject,
+		"sub":  subject,
+		"role": role,
 		"exp": time.Now().Add(j.ttl).Unix(),
 		"iat": time.Now().Unix(),
 	}
@@ -48,7 +48,7 @@ func TestJWTService(t *testing.T) {
 
 	// Teste: Gerar e validar token
 	subject := "user123"
-	tokenStr, err := service.GenerateToken(subject)
+	tokenStr, err := service.GenerateToken(subject, "cashier")
 	if err != nil {
 		t.Fatalf("erro ao gerar token: %v", err)
 	}
@@ -72,6 +72,10 @@ func TestJWTService(t *testing.T) {
 		t.Errorf("subject incorreto, esperado %q, obtido %q", subject, claims["sub"])
 	}
 
+	if claims["role"] != "cashier" {
+		t.Errorf("role incorreto, esperado %q, obtido %q", "cashier", claims["role"])
+	}
+
 	// Teste: Token inválido
 	invalidToken := tokenStr + "abc"
 	_, err = service.ValidateToken(invalidToken)
@@ -87,7 +91,7 @@ func TestJWTServiceExpiredToken(t *testing.T) {
 	}
 	service := NewJWTService(cfg)
 
-	tokenStr, err := service.GenerateToken("expired_user")
+	tokenStr, err := service.GenerateToken("expired_user", "admin")
 	if err != nil {
 		t.Fatalf("erro ao gerar token: %v", err)
 	}
@@ -0,0 +1,20 @@
+package utils
+
+import "time"
+
+type MaintenanceConfig struct {
+	// ReminderInterval is how often the vehicles coming due for maintenance are looked for
+	ReminderInterval time.Duration
+	// LeadDays and LeadKm are how long before the due date, or how many kilometres before the due
+	// mileage, a maintenance counts as coming due and the customer is reminded of it
+	LeadDays int
+	LeadKm   int
+}
+
+func LoadMaintenanceConfig() *MaintenanceConfig {
+	return &MaintenanceConfig{
+		ReminderInterval: getEnvAsDuration("MAINTENANCE_REMINDER_INTERVAL", 24*time.Hour),
+		LeadDays:         getEnvAsInt("MAINTENANCE_REMINDER_LEAD_DAYS", 15),
+		LeadKm:           getEnvAsInt("MAINTENANCE_REMINDER_LEAD_KM", 500),
+	}
+}
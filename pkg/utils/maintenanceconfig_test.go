@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadMaintenanceConfig(t *testing.T) {
+	for _, key := range []string{"MAINTENANCE_REMINDER_INTERVAL", "MAINTENANCE_REMINDER_LEAD_DAYS", "MAINTENANCE_REMINDER_LEAD_KM"} {
+		os.Unsetenv(key)
+	}
+
+	cfg := LoadMaintenanceConfig()
+	if cfg.ReminderInterval != 24*time.Hour {
+		t.Errorf("esperado ReminderInterval = %v, obtido %v", 24*time.Hour, cfg.ReminderInterval)
+	}
+	if cfg.LeadDays != 15 {
+		t.Errorf("esperado LeadDays = 15, obtido %d", cfg.LeadDays)
+	}
+	if cfg.LeadKm != 500 {
+		t.Errorf("esperado LeadKm = 500, obtido %d", cfg.LeadKm)
+	}
+
+	os.Setenv("MAINTENANCE_REMINDER_INTERVAL", "6h")
+	os.Setenv("MAINTENANCE_REMINDER_LEAD_KM", "1000")
+	defer os.Unsetenv("MAINTENANCE_REMINDER_INTERVAL")
+	defer os.Unsetenv("MAINTENANCE_REMINDER_LEAD_KM")
+
+	cfg = LoadMaintenanceConfig()
+	if cfg.ReminderInterval != 6*time.Hour {
+		t.Errorf("esperado ReminderInterval = %v, obtido %v", 6*time.Hour, cfg.ReminderInterval)
+	}
+	if cfg.LeadKm != 1000 {
+		t.Errorf("esperado LeadKm = 1000, obtido %d", cfg.LeadKm)
+	}
+}
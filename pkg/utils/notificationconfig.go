@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type NotificationConfig struct {
+	// Provider selects how messages are delivered; "fake" only logs them, "live" uses SMTP and the SMS/WhatsApp APIs
+	Provider string
+	// Channels lists the channels customers are notified on (EMAIL, SMS, WHATSAPP)
+	Channels []string
+	// MaxAttempts is how many times a message is tried before it is marked as failed
+	MaxAttempts int
+	// RetryInterval is how often pending messages are sent
+	RetryInterval time.Duration
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	SMSAPIURL        string
+	SMSAPIToken      string
+	WhatsAppAPIURL   string
+	WhatsAppAPIToken string
+}
+
+func LoadNotificationConfig() *NotificationConfig {
+	return &NotificationConfig{
+		Provider:         getEnv("NOTIFICATION_PROVIDER", "fake"),
+		Channels:         getEnvAsList("NOTIFICATION_CHANNELS", []string{"EMAIL"}),
+		MaxAttempts:      getEnvAsInt("NOTIFICATION_MAX_ATTEMPTS", 5),
+		RetryInterval:    getEnvAsDuration("NOTIFICATION_RETRY_INTERVAL", 30*time.Second),
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:         getEnv("SMTP_FROM", "Mecanica XPTO <nao-responda@mecanicaxpto.com.br>"),
+		SMSAPIURL:        getEnv("SMS_API_URL", ""),
+		SMSAPIToken:      getEnv("SMS_API_TOKEN", ""),
+		WhatsAppAPIURL:   getEnv("WHATSAPP_API_URL", ""),
+		WhatsAppAPIToken: getEnv("WHATSAPP_API_TOKEN", ""),
+	}
+}
+
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		i, err := strconv.Atoi(value)
+		if err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList reads a comma separated list, ignoring blank entries
+func getEnvAsList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return defaultValue
+	}
+	return list
+}
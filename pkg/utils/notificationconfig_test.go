@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadNotificationConfigDefaults(t *testing.T) {
+	os.Unsetenv("NOTIFICATION_PROVIDER")
+	os.Unsetenv("NOTIFICATION_CHANNELS")
+	os.Unsetenv("NOTIFICATION_MAX_ATTEMPTS")
+	os.Unsetenv("NOTIFICATION_RETRY_INTERVAL")
+	os.Unsetenv("SMTP_PORT")
+
+	cfg := LoadNotificationConfig()
+
+	if cfg.Provider != "fake" {
+		t.Errorf("esperado Provider = %v, obtido %v", "fake", cfg.Provider)
+	}
+	if !reflect.DeepEqual(cfg.Channels, []string{"EMAIL"}) {
+		t.Errorf("esperado Channels = %v, obtido %v", []string{"EMAIL"}, cfg.Channels)
+	}
+	if cfg.MaxAttempts != 5 {
+		t.Errorf("esperado MaxAttempts = %v, obtido %v", 5, cfg.MaxAttempts)
+	}
+	if cfg.RetryInterval != 30*time.Second {
+		t.Errorf("esperado RetryInterval = %v, obtido %v", 30*time.Second, cfg.RetryInterval)
+	}
+	if cfg.SMTPPort != 587 {
+		t.Errorf("esperado SMTPPort = %v, obtido %v", 587, cfg.SMTPPort)
+	}
+}
+
+func TestLoadNotificationConfigFromEnv(t *testing.T) {
+	os.Setenv("NOTIFICATION_CHANNELS", "EMAIL, WHATSAPP,")
+	os.Setenv("NOTIFICATION_MAX_ATTEMPTS", "3")
+	os.Setenv("SMTP_PORT", "invalido")
+	defer os.Unsetenv("NOTIFICATION_CHANNELS")
+	defer os.Unsetenv("NOTIFICATION_MAX_ATTEMPTS")
+	defer os.Unsetenv("SMTP_PORT")
+
+	cfg := LoadNotificationConfig()
+
+	if !reflect.DeepEqual(cfg.Channels, []string{"EMAIL", "WHATSAPP"}) {
+		t.Errorf("esperado Channels = %v, obtido %v", []string{"EMAIL", "WHATSAPP"}, cfg.Channels)
+	}
+	if cfg.MaxAttempts != 3 {
+		t.Errorf("esperado MaxAttempts = %v, obtido %v", 3, cfg.MaxAttempts)
+	}
+	if cfg.SMTPPort != 587 {
+		t.Errorf("esperado SMTPPort = %v, obtido %v", 587, cfg.SMTPPort)
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+)
+
+type PricingConfig struct {
+	// DiscountApprovalThreshold is the percentage of the estimate above which manual discounts need an admin sign-off
+	DiscountApprovalThreshold float64
+}
+
+func LoadPricingConfig() *PricingConfig {
+	return &PricingConfig{
+		DiscountApprovalThreshold: getEnvAsFloat("DISCOUNT_APPROVAL_THRESHOLD", 10),
+	}
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		f, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
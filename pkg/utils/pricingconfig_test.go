@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadPricingConfigDefaults(t *testing.T) {
+	os.Unsetenv("DISCOUNT_APPROVAL_THRESHOLD")
+
+	cfg := LoadPricingConfig()
+
+	if cfg.DiscountApprovalThreshold != 10 {
+		t.Errorf("esperado DiscountApprovalThreshold = %v, obtido %v", 10, cfg.DiscountApprovalThreshold)
+	}
+}
+
+func TestLoadPricingConfigFromEnv(t *testing.T) {
+	os.Setenv("DISCOUNT_APPROVAL_THRESHOLD", "15.5")
+	defer os.Unsetenv("DISCOUNT_APPROVAL_THRESHOLD")
+
+	cfg := LoadPricingConfig()
+
+	if cfg.DiscountApprovalThreshold != 15.5 {
+		t.Errorf("esperado DiscountApprovalThreshold = %v, obtido %v", 15.5, cfg.DiscountApprovalThreshold)
+	}
+}
+
+func TestLoadPricingConfigInvalidValue(t *testing.T) {
+	os.Setenv("DISCOUNT_APPROVAL_THRESHOLD", "dez")
+	defer os.Unsetenv("DISCOUNT_APPROVAL_THRESHOLD")
+
+	cfg := LoadPricingConfig()
+
+	if cfg.DiscountApprovalThreshold != 10 {
+		t.Errorf("esperado DiscountApprovalThreshold = %v, obtido %v", 10, cfg.DiscountApprovalThreshold)
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strconv"
+	"time"
+	_ "time/tzdata" // the workshop time zone must load on hosts without a zoneinfo database
+)
+
+type ScheduleConfig struct {
+	// SlotDuration is the granularity of the agenda; visits last a whole number of slots
+	SlotDuration time.Duration
+	// OpeningHour and ClosingHour bound the visits, in the time zone of the workshop
+	OpeningHour int
+	ClosingHour int
+	// WorkingDays are the weekdays the workshop takes visits on
+	WorkingDays []time.Weekday
+	// SearchDays is how far ahead the availability search looks for free slots
+	SearchDays int
+	Location   *time.Location
+}
+
+func LoadScheduleConfig() *ScheduleConfig {
+	location, err := time.LoadLocation(getEnv("SCHEDULE_TIMEZONE", "America/Sao_Paulo"))
+	if err != nil {
+		location = time.Local
+	}
+
+	var workingDays []time.Weekday
+	for _, day := range getEnvAsList("SCHEDULE_WORKING_DAYS", []string{"1", "2", "3", "4", "5", "6"}) {
+		if d, err := strconv.Atoi(day); err == nil && d >= 0 && d <= 6 {
+			workingDays = append(workingDays, time.Weekday(d))
+		}
+	}
+
+	return &ScheduleConfig{
+		SlotDuration: getEnvAsDuration("SCHEDULE_SLOT_DURATION", 30*time.Minute),
+		OpeningHour:  getEnvAsInt("SCHEDULE_OPENING_HOUR", 8),
+		ClosingHour:  getEnvAsInt("SCHEDULE_CLOSING_HOUR", 18),
+		WorkingDays:  workingDays,
+		SearchDays:   getEnvAsInt("SCHEDULE_SEARCH_DAYS", 14),
+		Location:     location,
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadScheduleConfig(t *testing.T) {
+	for _, key := range []string{"SCHEDULE_SLOT_DURATION", "SCHEDULE_OPENING_HOUR", "SCHEDULE_CLOSING_HOUR", "SCHEDULE_WORKING_DAYS", "SCHEDULE_SEARCH_DAYS", "SCHEDULE_TIMEZONE"} {
+		os.Unsetenv(key)
+	}
+
+	cfg := LoadScheduleConfig()
+	if cfg.SlotDuration != 30*time.Minute {
+		t.Errorf("esperado SlotDuration = %v, obtido %v", 30*time.Minute, cfg.SlotDuration)
+	}
+	if cfg.OpeningHour != 8 || cfg.ClosingHour != 18 {
+		t.Errorf("esperado expediente 8-18, obtido %d-%d", cfg.OpeningHour, cfg.ClosingHour)
+	}
+	if len(cfg.WorkingDays) != 6 || cfg.WorkingDays[0] != time.Monday || cfg.WorkingDays[5] != time.Saturday {
+		t.Errorf("esperado segunda a sábado, obtido %v", cfg.WorkingDays)
+	}
+	if cfg.SearchDays != 14 {
+		t.Errorf("esperado SearchDays = 14, obtido %d", cfg.SearchDays)
+	}
+	if cfg.Location.String() != "America/Sao_Paulo" {
+		t.Errorf("esperado fuso America/Sao_Paulo, obtido %s", cfg.Location)
+	}
+
+	os.Setenv("SCHEDULE_SLOT_DURATION", "1h")
+	os.Setenv("SCHEDULE_WORKING_DAYS", "1,3,9")
+	os.Setenv("SCHEDULE_TIMEZONE", "UTC")
+	defer os.Unsetenv("SCHEDULE_SLOT_DURATION")
+	defer os.Unsetenv("SCHEDULE_WORKING_DAYS")
+	defer os.Unsetenv("SCHEDULE_TIMEZONE")
+
+	cfg = LoadScheduleConfig()
+	if cfg.SlotDuration != time.Hour {
+		t.Errorf("esperado SlotDuration = %v, obtido %v", time.Hour, cfg.SlotDuration)
+	}
+	if len(cfg.WorkingDays) != 2 || cfg.WorkingDays[1] != time.Wednesday {
+		t.Errorf("esperado segunda e quarta, obtido %v", cfg.WorkingDays)
+	}
+	if cfg.Location != time.UTC {
+		t.Errorf("esperado fuso UTC, obtido %s", cfg.Location)
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "time"
+
+type StorageConfig struct {
+	// Provider selects the blob store for uploaded files; only "local" is available
+	Provider string
+	// LocalDir is the directory the local provider keeps the files in
+	LocalDir string
+	// MaxUploadSize is the largest file, in bytes, accepted by the upload endpoints
+	MaxUploadSize int64
+	// DownloadURLSecret signs the temporary download links of the stored files
+	DownloadURLSecret string
+	// DownloadURLTTL is how long a temporary download link stays valid
+	DownloadURLTTL time.Duration
+	// DownloadBaseURL is the public address the file ID and signature are appended to
+	DownloadBaseURL string
+}
+
+func LoadStorageConfig() *StorageConfig {
+	return &StorageConfig{
+		Provider:          getEnv("STORAGE_PROVIDER", "local"),
+		LocalDir:          getEnv("STORAGE_LOCAL_DIR", "./data/uploads"),
+		MaxUploadSize:     int64(getEnvAsInt("UPLOAD_MAX_SIZE", 10<<20)),
+		DownloadURLSecret: getEnv("DOWNLOAD_URL_SECRET", "default_download_secret"),
+		DownloadURLTTL:    getEnvAsDuration("DOWNLOAD_URL_TTL", 15*time.Minute),
+		DownloadBaseURL:   getEnv("DOWNLOAD_URL_BASE_URL", "http://localhost:8080/v1/public/attachments"),
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadStorageConfig(t *testing.T) {
+	for _, key := range []string{"STORAGE_PROVIDER", "STORAGE_LOCAL_DIR", "UPLOAD_MAX_SIZE", "DOWNLOAD_URL_TTL", "DOWNLOAD_URL_BASE_URL"} {
+		os.Unsetenv(key)
+	}
+
+	cfg := LoadStorageConfig()
+	if cfg.Provider != "local" {
+		t.Errorf("esperado Provider = local, obtido %s", cfg.Provider)
+	}
+	if cfg.LocalDir != "./data/uploads" {
+		t.Errorf("esperado LocalDir = ./data/uploads, obtido %s", cfg.LocalDir)
+	}
+	if cfg.MaxUploadSize != 10<<20 {
+		t.Errorf("esperado MaxUploadSize = %d, obtido %d", 10<<20, cfg.MaxUploadSize)
+	}
+	if cfg.DownloadURLTTL != 15*time.Minute {
+		t.Errorf("esperado DownloadURLTTL = %v, obtido %v", 15*time.Minute, cfg.DownloadURLTTL)
+	}
+	if cfg.DownloadBaseURL != "http://localhost:8080/v1/public/attachments" {
+		t.Errorf("esperado DownloadBaseURL padrão, obtido %s", cfg.DownloadBaseURL)
+	}
+
+	os.Setenv("STORAGE_LOCAL_DIR", "/var/lib/xpto")
+	os.Setenv("UPLOAD_MAX_SIZE", "2048")
+	defer os.Unsetenv("STORAGE_LOCAL_DIR")
+	defer os.Unsetenv("UPLOAD_MAX_SIZE")
+
+	cfg = LoadStorageConfig()
+	if cfg.LocalDir != "/var/lib/xpto" {
+		t.Errorf("esperado LocalDir = /var/lib/xpto, obtido %s", cfg.LocalDir)
+	}
+	if cfg.MaxUploadSize != 2048 {
+		t.Errorf("esperado MaxUploadSize = 2048, obtido %d", cfg.MaxUploadSize)
+	}
+}
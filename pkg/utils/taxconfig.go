@@ -0,0 +1,15 @@
+package utils
+
+type TaxConfig struct {
+	// DefaultISSRate is the ISS percentage applied to services without a matching tax rule
+	DefaultISSRate float64
+	// DefaultICMSRate is the ICMS percentage applied to parts supplies without a matching tax rule
+	DefaultICMSRate float64
+}
+
+func LoadTaxConfig() *TaxConfig {
+	return &TaxConfig{
+		DefaultISSRate:  getEnvAsFloat("TAX_DEFAULT_ISS_RATE", 5),
+		DefaultICMSRate: getEnvAsFloat("TAX_DEFAULT_ICMS_RATE", 18),
+	}
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadTaxConfigDefaults(t *testing.T) {
+	os.Unsetenv("TAX_DEFAULT_ISS_RATE")
+	os.Unsetenv("TAX_DEFAULT_ICMS_RATE")
+
+	cfg := LoadTaxConfig()
+
+	if cfg.DefaultISSRate != 5 {
+		t.Errorf("esperado DefaultISSRate = %v, obtido %v", 5, cfg.DefaultISSRate)
+	}
+	if cfg.DefaultICMSRate != 18 {
+		t.Errorf("esperado DefaultICMSRate = %v, obtido %v", 18, cfg.DefaultICMSRate)
+	}
+}
+
+func TestLoadTaxConfigFromEnv(t *testing.T) {
+	os.Setenv("TAX_DEFAULT_ISS_RATE", "2")
+	os.Setenv("TAX_DEFAULT_ICMS_RATE", "12")
+	defer os.Unsetenv("TAX_DEFAULT_ISS_RATE")
+	defer os.Unsetenv("TAX_DEFAULT_ICMS_RATE")
+
+	cfg := LoadTaxConfig()
+
+	if cfg.DefaultISSRate != 2 {
+		t.Errorf("esperado DefaultISSRate = %v, obtido %v", 2, cfg.DefaultISSRate)
+	}
+	if cfg.DefaultICMSRate != 12 {
+		t.Errorf("esperado DefaultICMSRate = %v, obtido %v", 12, cfg.DefaultICMSRate)
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignWebhook(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"event_id":1}`)
+
+	// printf '1700000000.{"event_id":1}' | openssl dgst -sha256 -hmac segredo
+	expected := "sha256=29e5cc5899d6dde65f9086efc67e80110e3fef6699a65e4872756793a9a1cffe"
+	signature := SignWebhook("segredo", timestamp, body)
+	if signature != expected {
+		t.Errorf("esperado %s, obtido %s", expected, signature)
+	}
+
+	if SignWebhook("outro", timestamp, body) == signature {
+		t.Errorf("esperado assinatura diferente para outro segredo")
+	}
+	if SignWebhook("segredo", timestamp.Add(time.Second), body) == signature {
+		t.Errorf("esperado assinatura diferente para outro timestamp")
+	}
+	if SignWebhook("segredo", timestamp, []byte(`{"event_id":2}`)) == signature {
+		t.Errorf("esperado assinatura diferente para outro corpo")
+	}
+}
+
+func TestNewWebhookSecret(t *testing.T) {
+	first, err := NewWebhookSecret()
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	second, _ := NewWebhookSecret()
+	if !strings.HasPrefix(first, "whsec_") {
+		t.Errorf("esperado prefixo whsec_, obtido %s", first)
+	}
+	if first == second {
+		t.Errorf("esperado segredos diferentes")
+	}
+}
@@ -0,0 +1,20 @@
+package utils
+
+import "time"
+
+type WebhookConfig struct {
+	// MaxAttempts is how many times a delivery is tried before it is marked as failed
+	MaxAttempts int
+	// RetryInterval is how often the pending deliveries are checked
+	RetryInterval time.Duration
+	// Timeout is how long a subscriber has to answer a delivery
+	Timeout time.Duration
+}
+
+func LoadWebhookConfig() *WebhookConfig {
+	return &WebhookConfig{
+		MaxAttempts:   getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		RetryInterval: getEnvAsDuration("WEBHOOK_RETRY_INTERVAL", 15*time.Second),
+		Timeout:       getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadWebhookConfig(t *testing.T) {
+	os.Unsetenv("WEBHOOK_MAX_ATTEMPTS")
+	os.Unsetenv("WEBHOOK_RETRY_INTERVAL")
+	os.Unsetenv("WEBHOOK_TIMEOUT")
+
+	cfg := LoadWebhookConfig()
+	if cfg.MaxAttempts != 8 {
+		t.Errorf("esperado MaxAttempts = 8, obtido %d", cfg.MaxAttempts)
+	}
+	if cfg.RetryInterval != 15*time.Second {
+		t.Errorf("esperado RetryInterval = %v, obtido %v", 15*time.Second, cfg.RetryInterval)
+	}
+	if cfg.Timeout != 10*time.Second {
+		t.Errorf("esperado Timeout = %v, obtido %v", 10*time.Second, cfg.Timeout)
+	}
+
+	os.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+	os.Setenv("WEBHOOK_RETRY_INTERVAL", "1m")
+	os.Setenv("WEBHOOK_TIMEOUT", "5s")
+	defer os.Unsetenv("WEBHOOK_MAX_ATTEMPTS")
+	defer os.Unsetenv("WEBHOOK_RETRY_INTERVAL")
+	defer os.Unsetenv("WEBHOOK_TIMEOUT")
+
+	cfg = LoadWebhookConfig()
+	if cfg.MaxAttempts != 3 {
+		t.Errorf("esperado MaxAttempts = 3, obtido %d", cfg.MaxAttempts)
+	}
+	if cfg.RetryInterval != time.Minute {
+		t.Errorf("esperado RetryInterval = %v, obtido %v", time.Minute, cfg.RetryInterval)
+	}
+	if cfg.Timeout != 5*time.Second {
+		t.Errorf("esperado Timeout = %v, obtido %v", 5*time.Second, cfg.Timeout)
+	}
+}
@@ -0,0 +1,64 @@
+package validators
+
+import (
+	"errors"
+	"regexp"
+)
+
+var (
+	// regexVINPattern accepts the 17 characters of a VIN, which never uses I, O or Q to avoid
+	// confusing them with 1 and 0
+	regexVINPattern = regexp.MustCompile("^[A-HJ-NPR-Z0-9]{17}$")
+	weightVIN       = []int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+)
+
+// VinIsValid validates a VIN (the chassis number) by its format and its WMI, the first three
+// characters identifying the maker. The check digit in the ninth position is only checked on the
+// VINs of North America, whose WMI starts with 1 to 5: elsewhere, e.g. in Brazil, that position is
+// free for the maker.
+func VinIsValid(v string) error {
+	if !regexVINPattern.MatchString(v) {
+		return errors.New("invalid VIN format")
+	}
+	// The region 0 is not assigned to any country
+	if v[0] == '0' {
+		return errors.New("invalid VIN manufacturer identifier")
+	}
+	if v[0] < '1' || v[0] > '5' {
+		return nil
+	}
+
+	sum := 0
+	for index := 0; index < len(v); index++ {
+		sum += vinValue(v[index]) * weightVIN[index]
+	}
+
+	digit := byte('0' + sum%11)
+	if sum%11 == 10 {
+		digit = 'X'
+	}
+	if v[8] != digit {
+		return errors.New("VIN check digit does not match")
+	}
+
+	return nil
+}
+
+// vinValue transliterates a character of the VIN into the number used to compute the check digit
+func vinValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'A' && c <= 'H':
+		return int(c-'A') + 1
+	case c >= 'J' && c <= 'N':
+		return int(c-'J') + 1
+	case c == 'P':
+		return 7
+	case c == 'R':
+		return 9
+	default:
+		// S to Z
+		return int(c-'S') + 2
+	}
+}
@@ -0,0 +1,88 @@
+package validators
+
+import (
+	"testing"
+)
+
+func TestVinIsValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "VIN válido",
+			input:   "1M8GDM9AXKP042788", // dígito verificador X
+			wantErr: false,
+		},
+		{
+			name:    "VIN válido com dígito numérico",
+			input:   "1HGCM82633A004352",
+			wantErr: false,
+		},
+		{
+			name:    "VIN brasileiro sem dígito verificador",
+			input:   "9BWZZZ377VT004251",
+			wantErr: false,
+		},
+		{
+			name:    "VIN brasileiro com X na nona posição",
+			input:   "9BGRD08X04G117974",
+			wantErr: false,
+		},
+		{
+			name:    "VIN brasileiro com dígito na nona posição",
+			input:   "93HGD17408Z100001",
+			wantErr: false,
+		},
+		{
+			name:    "VIN brasileiro com letra I",
+			input:   "9BWZZZ377VTI04251",
+			wantErr: true,
+			errMsg:  "invalid VIN format",
+		},
+		{
+			name:    "WMI inválido",
+			input:   "0BWZZZ377VT004251",
+			wantErr: true,
+			errMsg:  "invalid VIN manufacturer identifier",
+		},
+		{
+			name:    "Formato inválido (menos caracteres)",
+			input:   "1HGCM82633A00435",
+			wantErr: true,
+			errMsg:  "invalid VIN format",
+		},
+		{
+			name:    "Formato inválido (letra O)",
+			input:   "1HGCM82633AO04352",
+			wantErr: true,
+			errMsg:  "invalid VIN format",
+		},
+		{
+			name:    "Dígito verificador incorreto",
+			input:   "1HGCM82643A004352", // alterado o dígito verificador
+			wantErr: true,
+			errMsg:  "VIN check digit does not match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VinIsValid(tt.input)
+
+			if tt.wantErr && err == nil {
+				t.Errorf("esperava erro, mas retornou nil")
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Errorf("não esperava erro, mas retornou: %v", err)
+			}
+
+			if tt.wantErr && err != nil && err.Error() != tt.errMsg {
+				t.Errorf("mensagem de erro esperada: '%s', mas retornou: '%s'", tt.errMsg, err.Error())
+			}
+		})
+	}
+}